@@ -58,6 +58,8 @@ func (a *Application) Run() {
 		}
 	}
 
+	a.cfg.runDurableEvents()
+
 	// apply adm commands
 	admDir := filepath.Join(a.cfg.DataDir(), "adm/once-after-cfg")
 	slog.Info("checking adm once instructions", "dir", admDir)
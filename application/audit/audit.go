@@ -0,0 +1,141 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+// Package audit records all authorization decisions of subjects in a central, append-only trail. Each entry is
+// chained to its predecessor by a SHA-256 hash, so that a modified, inserted or deleted entry breaks the chain and
+// is detected by [Verify]. Subjects report their decisions by [permission.Report] and the recorder started by
+// [StartRecorder] appends them to an ndb message engine.
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.wdy.de/nago/application/permission"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/pkg/ndb"
+)
+
+// TypeID is the message type of all entries within the audit message engine.
+const TypeID ndb.TypeID = "nago.audit.Entry"
+
+// Entry is a single recorded decision.
+type Entry struct {
+	// Seq is the position within the message engine. It is assigned when written and therefore not part of the
+	// hash.
+	Seq        ndb.Seq       `json:"seq,omitempty"`
+	Time       time.Time     `json:"time"`
+	Subject    user.ID       `json:"subject"`
+	Permission permission.ID `json:"permission"`
+	Namespace  string        `json:"namespace,omitempty"`
+	Instance   string        `json:"instance,omitempty"`
+	Allowed    bool          `json:"allowed"`
+	Reason     string        `json:"reason,omitempty"`
+	// Prev is the hash of the preceding entry and empty for the very first entry.
+	Prev string `json:"prev,omitempty"`
+	// Hash covers all other fields including Prev.
+	Hash string `json:"hash"`
+}
+
+// Resource returns the namespace and instance of the audited resource or an empty string for global permissions.
+func (e Entry) Resource() string {
+	if e.Namespace == "" {
+		return ""
+	}
+
+	if e.Instance == "" {
+		return e.Namespace
+	}
+
+	return e.Namespace + "/" + e.Instance
+}
+
+// computeHash returns the hex encoded SHA-256 of the canonical JSON encoding of the entry without Seq and Hash.
+func computeHash(e Entry) (string, error) {
+	e.Seq = 0
+	e.Hash = ""
+	buf, err := json.Marshal(e)
+	if err != nil {
+		return "", fmt.Errorf("cannot encode audit entry: %w", err)
+	}
+
+	sum := sha256.Sum256(buf)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func decode(msg ndb.Message) (Entry, error) {
+	payload, err := ndb.Decompress(msg.Encoding, msg.Payload, msg.UncompressedLen)
+	if err != nil {
+		return Entry{}, fmt.Errorf("cannot decompress audit entry %d: %w", msg.Seq, err)
+	}
+
+	var e Entry
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return Entry{}, fmt.Errorf("cannot decode audit entry %d: %w", msg.Seq, err)
+	}
+
+	e.Seq = msg.Seq
+	return e, nil
+}
+
+// Filter selects entries. Zero fields match everything.
+type Filter struct {
+	Subject    user.ID
+	Permission permission.ID
+	// Resource matches either the namespace, the instance or the combined form namespace/instance.
+	Resource   string
+	From       time.Time
+	To         time.Time
+	OnlyDenied bool
+}
+
+func (f Filter) matches(e Entry) bool {
+	if f.Subject != "" && e.Subject != f.Subject {
+		return false
+	}
+
+	if f.Permission != "" && e.Permission != f.Permission {
+		return false
+	}
+
+	if f.Resource != "" && e.Namespace != f.Resource && e.Instance != f.Resource && e.Resource() != f.Resource {
+		return false
+	}
+
+	if !f.From.IsZero() && e.Time.Before(f.From) {
+		return false
+	}
+
+	if !f.To.IsZero() && e.Time.After(f.To) {
+		return false
+	}
+
+	return !f.OnlyDenied || !e.Allowed
+}
+
+// Verification is the result of checking the hash chain.
+type Verification struct {
+	// Entries is the number of checked entries.
+	Entries int
+	// BrokenAt is the sequence of the first entry, which does not match its own hash or the hash of its
+	// predecessor. It is zero, if the chain is intact.
+	BrokenAt ndb.Seq
+	// Reason describes, why the chain is broken.
+	Reason string
+}
+
+func (v Verification) Valid() bool {
+	return v.BrokenAt == 0
+}
+
+// short returns an abbreviated hash for messages.
+func short(hash string) string {
+	return hash[:min(len(hash), 12)]
+}
@@ -0,0 +1,16 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package audit
+
+import "go.wdy.de/nago/application/permission"
+
+var (
+	PermFind   = permission.Declare[Find]("nago.audit.find", "Audit-Protokoll anzeigen", "Träger dieser Berechtigung können alle aufgezeichneten Berechtigungsprüfungen aller Nutzer einsehen.")
+	PermExport = permission.Declare[Export]("nago.audit.export", "Audit-Protokoll exportieren", "Träger dieser Berechtigung können das Audit-Protokoll als JSON Lines Datei exportieren.")
+	PermVerify = permission.Declare[Verify]("nago.audit.verify", "Audit-Protokoll prüfen", "Träger dieser Berechtigung können die Integrität der Hash-Kette des Audit-Protokolls prüfen.")
+)
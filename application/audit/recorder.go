@@ -0,0 +1,61 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package audit
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+
+	"go.wdy.de/nago/application/permission"
+)
+
+type RecorderOptions struct {
+	QueueSize int // default is 4096, decisions are dropped while the queue is full
+}
+
+// StartRecorder installs a [permission.SetRecorder], which queues all reported decisions and appends them in order
+// until the context is done. The decisions are written in the background, because subjects audit on hot paths.
+// For the same reason, a full queue never blocks a subject. Instead, the decision is dropped and the number of
+// dropped decisions is logged as soon as the queue accepts decisions again. The hash chain stays intact, thus
+// dropped decisions are only visible in the log.
+func StartRecorder(ctx context.Context, opts RecorderOptions, appendFn Append) {
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 4096
+	}
+
+	var dropped atomic.Int64
+	queue := make(chan permission.Decision, opts.QueueSize)
+	permission.SetRecorder(func(d permission.Decision) {
+		select {
+		case queue <- d:
+		default:
+			dropped.Add(1)
+		}
+	})
+
+	go func() {
+		slog.Info("audit recorder started")
+		for {
+			select {
+			case <-ctx.Done():
+				permission.SetRecorder(nil)
+				slog.Info("audit recorder stopped")
+				return
+			case d := <-queue:
+				if err := appendFn(d); err != nil {
+					slog.Error("audit recorder cannot append decision", "subject", d.Subject, "permission", d.Permission, "err", err)
+				}
+
+				if n := dropped.Swap(0); n > 0 {
+					slog.Error("audit recorder dropped decisions, because the queue was full", "count", n)
+				}
+			}
+		}
+	}()
+}
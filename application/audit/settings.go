@@ -0,0 +1,31 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package audit
+
+import (
+	"github.com/worldiety/enum"
+	"go.wdy.de/nago/application/settings"
+)
+
+var _ = enum.Variant[settings.GlobalSettings, Settings](
+	enum.Rename[Settings]("nago.audit.settings"),
+)
+
+type Settings struct {
+	_ any `title:"Audit-Protokoll" description:"Legt fest, welche Berechtigungsprüfungen im fälschungssicheren Audit-Protokoll aufgezeichnet werden."`
+
+	Disabled      bool `json:"disabled,omitempty" label:"Protokoll deaktivieren" supportingText:"Es werden keine neuen Einträge mehr aufgezeichnet. Bestehende Einträge bleiben erhalten."`
+	RecordAllowed bool `json:"recordAllowed,omitempty" label:"Erlaubte Zugriffe aufzeichnen" supportingText:"Standardmäßig werden nur verweigerte Zugriffe aufgezeichnet. Erlaubte Zugriffe machen den Großteil aller Prüfungen aus und werden unbegrenzt aufbewahrt, weil das Protokoll nicht gekürzt werden kann, ohne die Integritätsprüfung zu brechen."`
+}
+
+func (s Settings) GlobalSettings() bool { return true }
+
+// records returns true, if the decision shall be written.
+func (s Settings) records(allowed bool) bool {
+	return !s.Disabled && (s.RecordAllowed || !allowed)
+}
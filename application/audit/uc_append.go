@@ -0,0 +1,84 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.wdy.de/nago/application/permission"
+	"go.wdy.de/nago/application/settings"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/pkg/ndb"
+)
+
+func NewAppend(mutex *sync.Mutex, msgs Messages, loadGlobal settings.LoadGlobal) Append {
+	var lastHash string
+	var loaded bool
+
+	return func(d permission.Decision) error {
+		if !settings.ReadGlobal[Settings](loadGlobal).records(d.Allowed) {
+			return nil
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		// continue the chain after a restart
+		if !loaded {
+			optMsg, err := msgs.Get(TypeID)
+			if err != nil {
+				return fmt.Errorf("cannot load last audit entry: %w", err)
+			}
+
+			if optMsg.IsSome() {
+				last, err := decode(optMsg.Unwrap())
+				if err != nil {
+					return err
+				}
+
+				lastHash = last.Hash
+			}
+
+			loaded = true
+		}
+
+		e := Entry{
+			Time:       time.Now().UTC(),
+			Subject:    user.ID(d.Subject),
+			Permission: d.Permission,
+			Namespace:  d.Namespace,
+			Instance:   d.Instance,
+			Allowed:    d.Allowed,
+			Reason:     d.Reason,
+			Prev:       lastHash,
+		}
+
+		hash, err := computeHash(e)
+		if err != nil {
+			return err
+		}
+
+		e.Hash = hash
+
+		buf, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("cannot encode audit entry: %w", err)
+		}
+
+		if _, err := msgs.Append(TypeID, ndb.TraceID{}, buf); err != nil {
+			return fmt.Errorf("cannot append audit entry: %w", err)
+		}
+
+		lastHash = e.Hash
+
+		return nil
+	}
+}
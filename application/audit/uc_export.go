@@ -0,0 +1,37 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"go.wdy.de/nago/auth"
+)
+
+func NewExport(msgs Messages) Export {
+	return func(subject auth.Subject, filter Filter, w io.Writer) error {
+		if err := subject.Audit(PermExport); err != nil {
+			return err
+		}
+
+		enc := json.NewEncoder(w)
+		for e, err := range entries(msgs, filter) {
+			if err != nil {
+				return err
+			}
+
+			if err := enc.Encode(e); err != nil {
+				return fmt.Errorf("cannot write audit entry: %w", err)
+			}
+		}
+
+		return nil
+	}
+}
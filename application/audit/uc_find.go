@@ -0,0 +1,88 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package audit
+
+import (
+	"iter"
+	"math"
+	"slices"
+
+	"go.wdy.de/nago/auth"
+	"go.wdy.de/nago/pkg/ndb"
+)
+
+const defaultLimit = 100
+
+func NewFind(msgs Messages) Find {
+	return func(subject auth.Subject, filter Filter, limit int) ([]Entry, error) {
+		if err := subject.Audit(PermFind); err != nil {
+			return nil, err
+		}
+
+		if limit <= 0 {
+			limit = defaultLimit
+		}
+
+		// the log is only readable in chronological order, thus keep the tail
+		var res []Entry
+		for e, err := range entries(msgs, filter) {
+			if err != nil {
+				return nil, err
+			}
+
+			res = append(res, e)
+			if len(res) > limit {
+				res = slices.Delete(res, 0, 1)
+			}
+		}
+
+		slices.Reverse(res)
+
+		return res, nil
+	}
+}
+
+// entries yields all entries matching the filter in chronological order. Deleted entries are skipped.
+func entries(msgs Messages, filter Filter) iter.Seq2[Entry, error] {
+	return func(yield func(Entry, error) bool) {
+		var minSeq ndb.Seq
+		if !filter.From.IsZero() {
+			// the time index is only a hint, the filter below is authoritative
+			if seq, err := msgs.SeqForTime(filter.From.UnixNano()); err == nil {
+				minSeq = seq
+			}
+		}
+
+		for _, msg := range msgs.Replay([]ndb.TypeID{TypeID}, minSeq, math.MaxUint64) {
+			if msg.IsTombstone() {
+				continue
+			}
+
+			if !filter.To.IsZero() && msg.TimeNano > filter.To.UnixNano() {
+				return
+			}
+
+			e, err := decode(msg)
+			if err != nil {
+				if !yield(Entry{}, err) {
+					return
+				}
+
+				continue
+			}
+
+			if !filter.matches(e) {
+				continue
+			}
+
+			if !yield(e, nil) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package audit
+
+import (
+	"fmt"
+	"math"
+
+	"go.wdy.de/nago/auth"
+	"go.wdy.de/nago/pkg/ndb"
+)
+
+func NewVerify(msgs Messages) Verify {
+	return func(subject auth.Subject) (Verification, error) {
+		if err := subject.Audit(PermVerify); err != nil {
+			return Verification{}, err
+		}
+
+		var res Verification
+		var prev string
+		for _, msg := range msgs.Replay([]ndb.TypeID{TypeID}, 0, math.MaxUint64) {
+			// a removed entry has no payload anymore, it is detected by the broken link of its successor
+			if msg.IsTombstone() {
+				continue
+			}
+
+			e, err := decode(msg)
+			if err != nil {
+				return Verification{}, err
+			}
+
+			res.Entries++
+
+			if e.Prev != prev {
+				res.BrokenAt = e.Seq
+				res.Reason = fmt.Sprintf("Der Vorgänger von Eintrag %d passt nicht: erwartet %s, gefunden %s.", e.Seq, short(prev), short(e.Prev))
+				return res, nil
+			}
+
+			hash, err := computeHash(e)
+			if err != nil {
+				return Verification{}, err
+			}
+
+			if hash != e.Hash {
+				res.BrokenAt = e.Seq
+				res.Reason = fmt.Sprintf("Der Inhalt von Eintrag %d wurde verändert.", e.Seq)
+				return res, nil
+			}
+
+			prev = e.Hash
+		}
+
+		return res, nil
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package uiaudit
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.wdy.de/nago/application/audit"
+	"go.wdy.de/nago/application/permission"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/pkg/xtime"
+	"go.wdy.de/nago/presentation/core"
+	"go.wdy.de/nago/presentation/ui"
+	"go.wdy.de/nago/presentation/ui/alert"
+)
+
+const pageLimit = 200
+
+// PageAuditLog shows the latest recorded decisions and allows to filter, export and verify them.
+func PageAuditLog(wnd core.Window, uc audit.UseCases) core.View {
+	if err := wnd.Subject().Audit(audit.PermFind); err != nil {
+		return alert.BannerError(err)
+	}
+
+	subject := core.AutoState[string](wnd)
+	perm := core.AutoState[string](wnd)
+	resource := core.AutoState[string](wnd)
+	from := core.AutoState[xtime.Date](wnd)
+	to := core.AutoState[xtime.Date](wnd)
+	onlyDenied := core.AutoState[bool](wnd)
+
+	filter := func() audit.Filter {
+		f := audit.Filter{
+			Subject:    user.ID(strings.TrimSpace(subject.Get())),
+			Permission: permission.ID(strings.TrimSpace(perm.Get())),
+			Resource:   strings.TrimSpace(resource.Get()),
+			OnlyDenied: onlyDenied.Get(),
+		}
+
+		if !from.Get().IsZero() {
+			f.From = from.Get().Time(wnd.Location())
+		}
+
+		if !to.Get().IsZero() {
+			f.To = to.Get().Time(wnd.Location()).AddDate(0, 0, 1)
+		}
+
+		return f
+	}
+
+	// replaying the log is expensive, thus only search on demand and not on each render
+	entries := core.AutoState[[]audit.Entry](wnd)
+	search := func() {
+		res, err := uc.Find(wnd.Subject(), filter(), pageLimit)
+		if err != nil {
+			alert.ShowBannerError(wnd, err)
+			return
+		}
+
+		entries.Set(res)
+	}
+
+	entries.Init(func() []audit.Entry {
+		res, err := uc.Find(wnd.Subject(), audit.Filter{}, pageLimit)
+		if err != nil {
+			alert.ShowBannerError(wnd, err)
+		}
+
+		return res
+	})
+
+	return ui.VStack(
+		ui.H1("Audit-Protokoll"),
+		ui.TextLayout(
+			ui.Text("Alle Berechtigungsprüfungen werden mit Nutzer, Berechtigung, Ressource und Ergebnis aufgezeichnet. Jeder Eintrag ist über eine Hash-Kette mit seinem Vorgänger verknüpft, sodass nachträgliche Änderungen oder Löschungen bei der Prüfung auffallen."),
+		),
+		ui.HStack(
+			ui.TextField("Nutzer-ID", subject.Get()).InputValue(subject),
+			ui.TextField("Berechtigungs-ID", perm.Get()).InputValue(perm),
+			ui.TextField("Ressource", resource.Get()).InputValue(resource),
+			ui.RangeDatePicker("Zeitraum", from.Get(), from, to.Get(), to),
+			ui.ToggleField("Nur verweigerte", onlyDenied.Get()).InputValue(onlyDenied),
+		).Gap(ui.L8).Wrap(true).Alignment(ui.BottomLeading),
+		ui.HStack(
+			ui.If(wnd.Subject().HasPermission(audit.PermVerify), ui.SecondaryButton(func() {
+				verify(wnd, uc)
+			}).Title("Integrität prüfen")),
+			ui.If(wnd.Subject().HasPermission(audit.PermExport), ui.SecondaryButton(func() {
+				var buf bytes.Buffer
+				if err := uc.Export(wnd.Subject(), filter(), &buf); err != nil {
+					alert.ShowBannerError(wnd, err)
+					return
+				}
+
+				wnd.ExportFiles(core.ExportFileBytes(fmt.Sprintf("audit_%s.jsonl", time.Now().Format("20060102_150405")), buf.Bytes()))
+			}).Title("Exportieren")),
+			ui.PrimaryButton(search).Title("Suchen"),
+		).FullWidth().Alignment(ui.Trailing).Gap(ui.L8),
+		entriesTable(wnd, entries.Get(), subject, search),
+	).Alignment(ui.Leading).FullWidth().Gap(ui.L16)
+}
+
+func verify(wnd core.Window, uc audit.UseCases) {
+	res, err := uc.Verify(wnd.Subject())
+	if err != nil {
+		alert.ShowBannerError(wnd, err)
+		return
+	}
+
+	if !res.Valid() {
+		alert.ShowBannerMessage(wnd, alert.Message{
+			Title:   "Audit-Protokoll manipuliert",
+			Message: res.Reason,
+			Intent:  alert.IntentError,
+		})
+		return
+	}
+
+	alert.ShowBannerMessage(wnd, alert.Message{
+		Title:   "Audit-Protokoll intakt",
+		Message: fmt.Sprintf("Die Hash-Kette aller %d Einträge ist vollständig.", res.Entries),
+		Intent:  alert.IntentOk,
+	})
+}
+
+func entriesTable(wnd core.Window, entries []audit.Entry, subject *core.State[string], search func()) core.View {
+	if len(entries) == 0 {
+		return ui.Text("Keine Einträge gefunden.")
+	}
+
+	displayName, _ := core.FromContext[user.DisplayName](wnd.Context(), "")
+
+	var rows []ui.TTableRow
+	for _, e := range entries {
+		name := string(e.Subject)
+		if displayName != nil {
+			if c := displayName(e.Subject); c.Displayname != "" {
+				name = c.Displayname
+			}
+		}
+
+		result := "erlaubt"
+		if !e.Allowed {
+			result = "verweigert"
+			if e.Reason != "" {
+				result += ": " + e.Reason
+			}
+		}
+
+		rows = append(rows, ui.TableRow(
+			ui.TableCell(ui.Text(e.Time.In(wnd.Location()).Format("02.01.2006 15:04:05"))),
+			ui.TableCell(ui.TertiaryButton(func() {
+				subject.Set(string(e.Subject))
+				search()
+			}).Title(name)),
+			ui.TableCell(ui.Text(permissionName(e.Permission))),
+			ui.TableCell(ui.Text(e.Resource())),
+			ui.TableCell(ui.Text(result)),
+		))
+	}
+
+	return ui.VStack(
+		ui.If(len(entries) == pageLimit, ui.Text(fmt.Sprintf("Es werden nur die neuesten %d Einträge angezeigt. Schränken Sie die Suche ein oder exportieren Sie das Protokoll.", pageLimit))),
+		ui.Table(
+			ui.TableColumn(ui.Text("Zeitpunkt")),
+			ui.TableColumn(ui.Text("Nutzer")),
+			ui.TableColumn(ui.Text("Berechtigung")),
+			ui.TableColumn(ui.Text("Ressource")),
+			ui.TableColumn(ui.Text("Ergebnis")),
+		).Rows(rows...),
+	).Alignment(ui.Leading).FullWidth().Gap(ui.L8)
+}
+
+func permissionName(id permission.ID) string {
+	if p, ok := permission.Find(id); ok {
+		return p.Name
+	}
+
+	return string(id)
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package audit
+
+import (
+	"io"
+	"sync"
+
+	"go.wdy.de/nago/application/permission"
+	"go.wdy.de/nago/application/settings"
+	"go.wdy.de/nago/auth"
+	"go.wdy.de/nago/pkg/ndb"
+)
+
+// Append writes the decision as a new entry at the end of the hash chain, unless disabled by the [Settings].
+// It is not intended to be called directly, see [StartRecorder].
+type Append func(d permission.Decision) error
+
+// Find returns at most limit entries matching the filter, the newest first.
+type Find func(subject auth.Subject, filter Filter, limit int) ([]Entry, error)
+
+// Export writes all entries matching the filter as JSON Lines in chronological order.
+type Export func(subject auth.Subject, filter Filter, w io.Writer) error
+
+// Verify checks the entire hash chain and reports the first entry which has been modified, inserted or whose
+// predecessor has been removed.
+type Verify func(subject auth.Subject) (Verification, error)
+
+type UseCases struct {
+	Append Append
+	Find   Find
+	Export Export
+	Verify Verify
+}
+
+// Messages is the subset of the ndb message capabilities required by the audit log.
+type Messages interface {
+	ndb.History
+	ndb.Retained
+	ndb.TimeLookup
+}
+
+func NewUseCases(msgs Messages, loadGlobal settings.LoadGlobal) UseCases {
+	var mutex sync.Mutex
+
+	return UseCases{
+		Append: NewAppend(&mutex, msgs, loadGlobal),
+		Find:   NewFind(msgs),
+		Export: NewExport(msgs),
+		Verify: NewVerify(msgs),
+	}
+}
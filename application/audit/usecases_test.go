@@ -0,0 +1,263 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"go.wdy.de/nago/application/permission"
+	"go.wdy.de/nago/application/settings"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/pkg/ndb"
+	"go.wdy.de/nago/pkg/ndb/msgstore"
+)
+
+// testSubject implements just enough of the subject for the audit use cases.
+type testSubject struct {
+	user.Subject
+	perms []permission.ID
+}
+
+func (s testSubject) Audit(p permission.ID) error {
+	if !slices.Contains(s.perms, p) {
+		return user.PermissionDeniedErr
+	}
+
+	return nil
+}
+
+var auditor = testSubject{perms: []permission.ID{PermFind, PermExport, PermVerify}}
+
+func openMessages(t *testing.T) ndb.Messages {
+	t.Helper()
+
+	db, err := ndb.Open(t.TempDir(), ndb.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = db.Close() })
+
+	eng, err := db.Engine("audit", ndb.EngineOptions{Kind: msgstore.EngineKind, Config: msgstore.Options{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return eng.(ndb.MessageEngine).Messages()
+}
+
+func newTestUseCases(msgs Messages, cfg Settings) UseCases {
+	return NewUseCases(msgs, func(subject permission.Auditable, t reflect.Type) (settings.GlobalSettings, error) {
+		return cfg, nil
+	})
+}
+
+func record(t *testing.T, uc UseCases, decisions ...permission.Decision) {
+	t.Helper()
+
+	for _, d := range decisions {
+		if err := uc.Append(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+var decisions = []permission.Decision{
+	{Subject: "alice", Permission: "nago.user.find_all", Allowed: true},
+	{Subject: "bob", Permission: "nago.user.delete", Reason: "permission denied"},
+	{Subject: "alice", Permission: "nago.drive.open", Namespace: "nago.drive", Instance: "d1", Allowed: true},
+}
+
+func TestVerify(t *testing.T) {
+	msgs := openMessages(t)
+	uc := newTestUseCases(msgs, Settings{RecordAllowed: true})
+	record(t, uc, decisions...)
+
+	// a restart must continue the existing chain
+	uc = newTestUseCases(msgs, Settings{RecordAllowed: true})
+	record(t, uc, decisions[0])
+
+	res, err := uc.Verify(auditor)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !res.Valid() || res.Entries != 4 {
+		t.Fatalf("expected intact chain of 4 entries, got %+v", res)
+	}
+
+	if _, err := uc.Verify(testSubject{}); !errors.Is(err, user.PermissionDeniedErr) {
+		t.Fatalf("expected permission denied, got %v", err)
+	}
+}
+
+func TestVerifyTampered(t *testing.T) {
+	msgs := openMessages(t)
+	uc := newTestUseCases(msgs, Settings{RecordAllowed: true})
+	record(t, uc, decisions...)
+
+	var bobSeq ndb.Seq
+	_, err := msgs.(ndb.Rewriter).Rewrite(TypeID, func(msg ndb.Message) ([]byte, bool, error) {
+		e, err := decode(msg)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if e.Subject != "bob" {
+			return nil, false, nil
+		}
+
+		bobSeq = msg.Seq
+		e.Allowed = true
+		buf, err := json.Marshal(e)
+		return buf, true, err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := uc.Verify(auditor)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Valid() || res.BrokenAt != bobSeq {
+		t.Fatalf("expected broken chain at %d, got %+v", bobSeq, res)
+	}
+}
+
+func TestVerifyDeleted(t *testing.T) {
+	msgs := openMessages(t)
+	uc := newTestUseCases(msgs, Settings{RecordAllowed: true})
+	record(t, uc, decisions...)
+
+	found, err := uc.Find(auditor, Filter{Subject: "bob"}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := msgs.(ndb.Pruner).DeleteSeq(TypeID, found[0].Seq); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := uc.Verify(auditor)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Valid() {
+		t.Fatalf("expected broken chain after deletion, got %+v", res)
+	}
+}
+
+func TestFindAndExport(t *testing.T) {
+	msgs := openMessages(t)
+	uc := newTestUseCases(msgs, Settings{RecordAllowed: true})
+	record(t, uc, decisions...)
+
+	res, err := uc.Find(auditor, Filter{Subject: "alice"}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res) != 2 || res[0].Permission != "nago.drive.open" {
+		t.Fatalf("expected newest alice entries first, got %+v", res)
+	}
+
+	res, err = uc.Find(auditor, Filter{Resource: "nago.drive/d1"}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res) != 1 || res[0].Instance != "d1" {
+		t.Fatalf("unexpected resource entries: %+v", res)
+	}
+
+	res, err = uc.Find(auditor, Filter{}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res) != 1 || res[0].Permission != "nago.drive.open" {
+		t.Fatalf("expected only the newest entry, got %+v", res)
+	}
+
+	if _, err := uc.Find(testSubject{}, Filter{}, 0); !errors.Is(err, user.PermissionDeniedErr) {
+		t.Fatalf("expected permission denied, got %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := uc.Export(auditor, Filter{OnlyDenied: true}, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	var lines []Entry
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatal(err)
+		}
+
+		lines = append(lines, e)
+	}
+
+	if len(lines) != 1 || lines[0].Subject != "bob" || lines[0].Hash == "" {
+		t.Fatalf("unexpected export: %+v", lines)
+	}
+}
+
+func TestOnlyDeniedByDefault(t *testing.T) {
+	msgs := openMessages(t)
+	uc := newTestUseCases(msgs, Settings{})
+	record(t, uc, decisions...)
+
+	res, err := uc.Find(auditor, Filter{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res) != 1 || res[0].Allowed {
+		t.Fatalf("expected only the denied entry, got %+v", res)
+	}
+}
+
+func TestRecorderDoesNotBlock(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	release := make(chan struct{})
+	defer close(release)
+
+	StartRecorder(ctx, RecorderOptions{QueueSize: 1}, func(d permission.Decision) error {
+		<-release
+		return nil
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 100 {
+			_ = permission.Report(permission.Decision{Subject: "alice", Permission: PermFind}, nil)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("a full queue must not block the auditing subject")
+	}
+}
@@ -11,9 +11,40 @@ import (
 	"time"
 )
 
+// Index describes the complete state of all stores at the time of the backup. This is also true for an
+// incremental backup, which just omits the content of blobs which are already contained in its base chain.
 type Index struct {
+	// ID identifies the backup, so that increments can refer to it. It is empty for backups of older versions.
+	ID        string    `json:"id,omitempty"`
 	CreatedAt time.Time `json:"createdAt"`
-	Stores    []Store   `json:"stores,omitempty"`
+	// BaseID refers to the backup, which this incremental backup is based on. It is empty for full backups.
+	BaseID string `json:"baseId,omitempty"`
+	// Snapshot is true, if all stores have been captured at the same point in time.
+	Snapshot bool    `json:"snapshot,omitempty"`
+	Stores   []Store `json:"stores,omitempty"`
+}
+
+// Incremental returns true, if the index requires its base chain to be restored.
+func (i *Index) Incremental() bool {
+	return i.BaseID != ""
+}
+
+// StoredSize returns the amount of bytes which are actually contained in the archive of this index.
+func (i *Index) StoredSize() int64 {
+	paths := map[string]struct{}{}
+	count := int64(0)
+	for _, store := range i.Stores {
+		for _, blob := range store.Blobs {
+			if _, ok := paths[blob.Path]; ok || blob.Path == "" {
+				continue
+			}
+
+			paths[blob.Path] = struct{}{}
+			count += blob.Size
+		}
+	}
+
+	return count
 }
 
 func (i *Index) Size() int64 {
@@ -55,7 +86,9 @@ type Blob struct {
 	ID     string `json:"id"`
 	Size   int64  `json:"size"`
 	Sha256 string `json:"sha256"`
-	Path   string `json:"path"`
+	// Path denotes the content within the archive. Within an incremental backup, it is empty if the content
+	// is part of the base chain and must be resolved by its Sha256.
+	Path string `json:"path"`
 	/*LastMod   time.Time   `json:"lastMod,omitempty"`
 	CreatedAt time.Time   `json:"createdAt,omitempty"`
 	Mode      fs.FileMode `json:"mode,omitempty"`*/
@@ -16,100 +16,209 @@ import (
 	"fmt"
 	"go.wdy.de/nago/auth"
 	"go.wdy.de/nago/pkg/blob"
+	"go.wdy.de/nago/pkg/data"
 	"io"
+	"iter"
 	"log/slog"
 	"time"
 )
 
+// source is the read-only part of a [blob.Store], which is also provided by a [blob.Snapshot].
+type source interface {
+	List(ctx context.Context, opts blob.ListOptions) iter.Seq2[string, error]
+	blob.Reader
+}
+
 func NewBackup(src blob.Stores) Backup {
-	return func(ctx context.Context, subject auth.Subject, dst io.Writer) (err error) {
-		if err := subject.Audit(PermBackup); err != nil {
+	backup := NewBackupWithOptions(src)
+	return func(ctx context.Context, subject auth.Subject, dst io.Writer) error {
+		stores, err := openStores(src)
+		if err != nil {
 			return err
 		}
 
-		slog.Info("backup", "action", "started", "userID", subject.ID(), "userEMail", subject.Email())
+		// a consistent state is always preferable, if all stores support it
+		_, err = backup(ctx, subject, Options{Snapshot: canSnapshot(stores)}, dst)
+		return err
+	}
+}
 
-		zipWriter := zip.NewWriter(dst)
-		defer func() {
-			if e := zipWriter.Close(); e != nil && err == nil {
-				err = e
-			}
-		}()
+func NewBackupWithOptions(src blob.Stores) BackupWithOptions {
+	return func(ctx context.Context, subject auth.Subject, opts Options, dst io.Writer) (idx Index, err error) {
+		if err := subject.Audit(PermBackup); err != nil {
+			return Index{}, err
+		}
 
-		index := &Index{
-			CreatedAt: time.Now(),
+		slog.Info("backup", "action", "started", "userID", subject.ID(), "userEMail", subject.Email(), "snapshot", opts.Snapshot, "incremental", opts.Base.IsSome())
+
+		stores, err := openStores(src)
+		if err != nil {
+			return Index{}, err
 		}
 
-		fileCounter := 0
-		for fileStoreName, err := range src.All() {
-			if err != nil {
-				return err
+		sources := make([]source, 0, len(stores))
+		if opts.Snapshot {
+			var blobStores []blob.Store
+			for _, s := range stores {
+				blobStores = append(blobStores, s.store)
 			}
 
-			optStat, err := src.Stat(fileStoreName)
+			start := time.Now()
+			snapshots, err := blob.SnapshotAll(blobStores)
 			if err != nil {
-				return err
+				return Index{}, fmt.Errorf("cannot take snapshot: %w", err)
 			}
 
-			if optStat.IsNone() {
-				return fmt.Errorf("file store %s is empty", fileStoreName)
+			defer func() {
+				for _, snapshot := range snapshots {
+					if e := snapshot.Close(); e != nil && err == nil {
+						err = e
+					}
+				}
+			}()
+
+			slog.Info("backup", "action", "snapshot taken", "stores", len(snapshots), "duration", time.Since(start))
+
+			for _, snapshot := range snapshots {
+				sources = append(sources, snapshot)
+			}
+		} else {
+			for _, s := range stores {
+				sources = append(sources, s.store)
 			}
+		}
 
-			stat := optStat.Unwrap()
+		zipWriter := zip.NewWriter(dst)
+		defer func() {
+			if e := zipWriter.Close(); e != nil && err == nil {
+				err = e
+			}
+		}()
 
-			slog.Info("backup", "action", "blob store started", "store", fileStoreName)
+		index := &Index{
+			ID:        data.RandIdent[string](),
+			CreatedAt: time.Now(),
+			Snapshot:  opts.Snapshot,
+		}
 
-			storeIdx := Store{
-				Name: fileStoreName,
+		// the base index describes a complete state, thus all its blobs are available in the base chain
+		var known map[string]string
+		if opts.Base.IsSome() {
+			base := opts.Base.Unwrap()
+			if base.ID == "" {
+				return Index{}, fmt.Errorf("base backup has no id and cannot be used for an incremental backup")
 			}
 
-			switch stat.Type {
-			case blob.FileStore:
-				storeIdx.Stereotype = StereotypeBlob
-			case blob.EntityStore:
-				storeIdx.Stereotype = StereotypeDocument
-			default:
-				return fmt.Errorf("unknown store type: %v", stat.Type)
+			index.BaseID = base.ID
+			known = map[string]string{}
+			for _, store := range base.Stores {
+				for _, b := range store.Blobs {
+					known[b.Sha256] = ""
+				}
 			}
+		}
 
-			store, err := src.Open(fileStoreName, blob.OpenStoreOptions{
-				Type: stat.Type,
-			})
-			if err != nil {
-				return fmt.Errorf("cannot open store %s: %w", fileStoreName, err)
+		fileCounter := 0
+		for i, s := range stores {
+			slog.Info("backup", "action", "blob store started", "store", s.idx.Name)
+
+			storeIdx := s.idx
+			if known != nil {
+				err = addChangedFilesFromStoreToZipStream(&storeIdx, &fileCounter, known, ctx, zipWriter, sources[i])
+			} else {
+				err = addFilesFromStoreToZipStream(&storeIdx, &fileCounter, ctx, zipWriter, sources[i])
 			}
 
-			if err := addFilesFromStoreToZipStream(&storeIdx, &fileCounter, ctx, zipWriter, store); err != nil {
-				return fmt.Errorf("cannot add file store files to zip stream: %w", err)
+			if err != nil {
+				return Index{}, fmt.Errorf("cannot add file store files to zip stream: %w", err)
 			}
 
 			index.Stores = append(index.Stores, storeIdx)
 
-			slog.Info("backup", "action", "store completed", "store", fileStoreName, "blobs", len(storeIdx.Blobs))
+			slog.Info("backup", "action", "store completed", "store", s.idx.Name, "blobs", len(storeIdx.Blobs))
 		}
-		
+
 		// write backup index
 		bufIdx, err := json.Marshal(index)
 		if err != nil {
-			return fmt.Errorf("cannot marshal index: %w", err)
+			return Index{}, fmt.Errorf("cannot marshal index: %w", err)
 		}
 
 		writer, err := zipWriter.Create("index.json")
 		if err != nil {
-			return fmt.Errorf("cannot create index file: %w", err)
+			return Index{}, fmt.Errorf("cannot create index file: %w", err)
 		}
 
 		if _, err := writer.Write(bufIdx); err != nil {
-			return fmt.Errorf("cannot write index: %w", err)
+			return Index{}, fmt.Errorf("cannot write index: %w", err)
 		}
 
-		slog.Info("backup", "action", "complete", "objects", fileCounter, "size", index.Size())
+		slog.Info("backup", "action", "complete", "objects", fileCounter, "size", index.Size(), "storedSize", index.StoredSize())
 
-		return nil
+		return *index, nil
 	}
 }
 
-func addFilesFromStoreToZipStream(idx *Store, fileCounter *int, ctx context.Context, zipWriter *zip.Writer, store blob.Store) error {
+type openedStore struct {
+	idx   Store
+	store blob.Store
+}
+
+func openStores(src blob.Stores) ([]openedStore, error) {
+	var res []openedStore
+	for fileStoreName, err := range src.All() {
+		if err != nil {
+			return nil, err
+		}
+
+		optStat, err := src.Stat(fileStoreName)
+		if err != nil {
+			return nil, err
+		}
+
+		if optStat.IsNone() {
+			return nil, fmt.Errorf("file store %s is empty", fileStoreName)
+		}
+
+		stat := optStat.Unwrap()
+
+		storeIdx := Store{
+			Name: fileStoreName,
+		}
+
+		switch stat.Type {
+		case blob.FileStore:
+			storeIdx.Stereotype = StereotypeBlob
+		case blob.EntityStore:
+			storeIdx.Stereotype = StereotypeDocument
+		default:
+			return nil, fmt.Errorf("unknown store type: %v", stat.Type)
+		}
+
+		store, err := src.Open(fileStoreName, blob.OpenStoreOptions{
+			Type: stat.Type,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("cannot open store %s: %w", fileStoreName, err)
+		}
+
+		res = append(res, openedStore{idx: storeIdx, store: store})
+	}
+
+	return res, nil
+}
+
+func canSnapshot(stores []openedStore) bool {
+	for _, s := range stores {
+		if _, ok := s.store.(blob.Snapshotter); !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+func addFilesFromStoreToZipStream(idx *Store, fileCounter *int, ctx context.Context, zipWriter *zip.Writer, store source) error {
 	for name, err := range store.List(ctx, blob.ListOptions{}) {
 		if err != nil {
 			return fmt.Errorf("cannot list %s: %w", name, err)
@@ -141,6 +250,86 @@ func addFilesFromStoreToZipStream(idx *Store, fileCounter *int, ctx context.Cont
 	return nil
 }
 
+// addChangedFilesFromStoreToZipStream hashes each blob at first and only adds its content, if it is neither part
+// of the base chain nor already contained in this archive. The known map is updated with the archive paths.
+func addChangedFilesFromStoreToZipStream(idx *Store, fileCounter *int, known map[string]string, ctx context.Context, zipWriter *zip.Writer, store source) error {
+	for name, err := range store.List(ctx, blob.ListOptions{}) {
+		if err != nil {
+			return fmt.Errorf("cannot list %s: %w", name, err)
+		}
+
+		hash, size, ok, err := hashBlob(ctx, store, name)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			// may be normal, due to concurrency
+			continue
+		}
+
+		if path, ok := known[hash]; ok {
+			idx.Blobs = append(idx.Blobs, Blob{
+				ID:     name,
+				Size:   size,
+				Sha256: hash,
+				Path:   path,
+			})
+
+			continue
+		}
+
+		optR, err := store.NewReader(ctx, name)
+		if err != nil {
+			return fmt.Errorf("cannot read %s: %w", name, err)
+		}
+
+		if optR.IsNone() {
+			continue
+		}
+
+		*fileCounter++
+
+		reader := optR.Unwrap()
+		if err := addFileToZip(idx, *fileCounter, zipWriter, name, reader); err != nil {
+			_ = reader.Close()
+			return fmt.Errorf("cannot add %s to zip: %w", name, err)
+		}
+
+		if err := reader.Close(); err != nil {
+			return fmt.Errorf("cannot close %s: %w", name, err)
+		}
+
+		// without a snapshot, the content may have changed in between, thus remember what we actually wrote
+		written := idx.Blobs[len(idx.Blobs)-1]
+		known[written.Sha256] = written.Path
+	}
+
+	return nil
+}
+
+func hashBlob(ctx context.Context, store source, name string) (hash string, size int64, ok bool, err error) {
+	optR, err := store.NewReader(ctx, name)
+	if err != nil {
+		return "", 0, false, fmt.Errorf("cannot read %s: %w", name, err)
+	}
+
+	if optR.IsNone() {
+		return "", 0, false, nil
+	}
+
+	reader := optR.Unwrap()
+	defer reader.Close()
+
+	hasher := sha256.New()
+	n, err := io.Copy(hasher, reader)
+	if err != nil {
+		return "", 0, false, fmt.Errorf("cannot hash %s: %w", name, err)
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil)), n, true, nil
+}
+
 func addFileToZip(idx *Store, fileCounter int, zipWriter *zip.Writer, name string, reader io.Reader) error {
 	path := fmt.Sprintf("data/%d", fileCounter)
 	writer, err := zipWriter.Create(path)
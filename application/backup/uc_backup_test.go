@@ -0,0 +1,82 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package backup_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/worldiety/option"
+	"go.wdy.de/nago/application"
+	"go.wdy.de/nago/application/backup"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/pkg/blob"
+)
+
+func TestIncrementalBackup(t *testing.T) {
+	src := option.Must(application.NewLocalStores(t.TempDir()))
+	defer src.Close()
+
+	entities := option.Must(src.Open("entities", blob.OpenStoreOptions{Type: blob.EntityStore}))
+	files := option.Must(src.Open("files", blob.OpenStoreOptions{Type: blob.FileStore}))
+	option.MustZero(blob.Put(entities, "1", []byte("one")))
+	option.MustZero(blob.Put(entities, "2", []byte("two")))
+	option.MustZero(blob.Put(files, "large", bytes.Repeat([]byte("x"), 64*1024)))
+
+	uc := backup.NewUseCases(src, nil, nil)
+	ctx := context.Background()
+
+	var full bytes.Buffer
+	base := option.Must(uc.BackupWithOptions(ctx, user.SU(), backup.Options{Snapshot: true}, &full))
+	if !base.Snapshot || base.Incremental() || base.StoredSize() != 64*1024+6 {
+		t.Fatalf("unexpected full index: %+v", base)
+	}
+
+	option.MustZero(blob.Delete(entities, "1"))
+	option.MustZero(blob.Put(entities, "2", []byte("changed")))
+	option.MustZero(blob.Put(entities, "3", []byte("three")))
+
+	var incr bytes.Buffer
+	idx := option.Must(uc.BackupWithOptions(ctx, user.SU(), backup.Options{Base: option.Some(base), Snapshot: true}, &incr))
+	if idx.BaseID != base.ID || idx.StoredSize() != int64(len("changed")+len("three")) {
+		t.Fatalf("unexpected incremental index: %+v", idx)
+	}
+
+	readIdx := option.Must(uc.ReadIndex(user.SU(), bytes.NewReader(incr.Bytes())))
+	if readIdx.ID != idx.ID {
+		t.Fatalf("unexpected index read: %+v", readIdx)
+	}
+
+	dst := option.Must(application.NewLocalStores(t.TempDir()))
+	defer dst.Close()
+	restore := backup.NewUseCases(dst, nil, nil)
+
+	if err := restore.RestoreChain(ctx, user.SU(), []io.Reader{bytes.NewReader(incr.Bytes())}); err == nil {
+		t.Fatal("expected error for missing base")
+	}
+
+	// the order of the chain is determined by the indices
+	option.MustZero(restore.RestoreChain(ctx, user.SU(), []io.Reader{bytes.NewReader(incr.Bytes()), bytes.NewReader(full.Bytes())}))
+
+	restored := option.Must(dst.Open("entities", blob.OpenStoreOptions{Type: blob.EntityStore}))
+	if keys := option.Must(blob.Keys(restored)); !slices.Equal(keys, []string{"2", "3"}) {
+		t.Fatalf("unexpected restored keys: %v", keys)
+	}
+
+	if v := option.Must(blob.Get(restored, "2")); string(v.Unwrap()) != "changed" {
+		t.Fatalf("unexpected restored value: %s", v.Unwrap())
+	}
+
+	restoredFiles := option.Must(dst.Open("files", blob.OpenStoreOptions{Type: blob.FileStore}))
+	if v := option.Must(blob.Get(restoredFiles, "large")); len(v.Unwrap()) != 64*1024 {
+		t.Fatalf("unexpected restored file size: %d", len(v.Unwrap()))
+	}
+}
@@ -9,6 +9,7 @@ package backup
 
 import (
 	"archive/zip"
+	"bufio"
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
@@ -24,80 +25,102 @@ import (
 )
 
 func NewRestore(dst blob.Stores) Restore {
+	restoreChain := NewRestoreChain(dst)
 	return func(ctx context.Context, subject auth.Subject, src io.Reader) error {
+		return restoreChain(ctx, subject, []io.Reader{src})
+	}
+}
+
+// archive is a received backup zip file.
+type archive struct {
+	index  Index
+	reader *zip.Reader
+	file   *os.File
+}
+
+func (a *archive) Close() error {
+	_ = a.file.Close()
+	return os.Remove(a.file.Name())
+}
+
+func NewRestoreChain(dst blob.Stores) RestoreChain {
+	return func(ctx context.Context, subject auth.Subject, srcs []io.Reader) error {
 		if err := subject.Audit(PermRestore); err != nil {
 			return err
 		}
 
-		slog.Info("restore", "action", "started", "userID", subject.ID(), "userEMail", subject.Email())
+		slog.Info("restore", "action", "started", "userID", subject.ID(), "userEMail", subject.Email(), "archives", len(srcs))
 
-		// for security, we need to consume and unpack the entire zip file at first, otherwise we
-		// may get interrupted in the middle of a restore leaving the system in a broken state, e.g.
-		// without a valid user table. This happens often for large backups when uploaded.
-		tempFilename := filepath.Join(os.TempDir(), data.RandIdent[string]()+".restore.zip")
-		tmpFile, err := os.OpenFile(tempFilename, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
-		if err != nil {
-			return fmt.Errorf("could not create temp file: %w", err)
+		if len(srcs) == 0 {
+			return fmt.Errorf("no backup given")
 		}
 
+		// for security, we need to consume and unpack the entire zip files at first, otherwise we
+		// may get interrupted in the middle of a restore leaving the system in a broken state, e.g.
+		// without a valid user table. This happens often for large backups when uploaded.
+		var archives []*archive
 		defer func() {
-			_ = tmpFile.Close()
-			if err := os.Remove(tempFilename); err != nil {
-				slog.Error("cannot remove temp zip file", "err", err)
+			for _, a := range archives {
+				if err := a.Close(); err != nil {
+					slog.Error("cannot remove temp zip file", "err", err)
+				}
 			}
 		}()
 
-		slog.Info("restore", "action", "receiving zip file")
-		srcSize, err := io.Copy(tmpFile, src)
-		if err != nil {
-			return fmt.Errorf("could not copy to temp file: %w", err)
-		}
-
-		if _, err := tmpFile.Seek(0, io.SeekStart); err != nil {
-			return fmt.Errorf("could not seek temp file: %w", err)
-		}
+		for _, src := range srcs {
+			a, err := receiveArchive(src)
+			if err != nil {
+				return err
+			}
 
-		slog.Info("restore", "action", "copied zip file into temporary file", "file", tempFilename, "size", srcSize)
-		// copy is complete, now let us verify the zip file for any obvious corruptions
-		zipReader, err := zip.NewReader(tmpFile, srcSize)
-		if err != nil {
-			return fmt.Errorf("could not open zip reader: %w", err)
+			archives = append(archives, a)
 		}
 
-		// read index
-		index, err := decodeIndex(zipReader)
+		chain, err := orderChain(archives)
 		if err != nil {
-			return fmt.Errorf("could not decode index: %w", err)
+			return err
 		}
 
 		// validate that we have all files available and that they have not seen bitrot
 		slog.Info("restore", "action", "validation started")
 
-		totalBytes := int64(0)
-		for _, store := range index.Stores {
-			for _, file := range store.Blobs {
-				r, err := zipReader.Open(file.Path)
-				if err != nil {
-					return fmt.Errorf("could not open zip file entry %s: %w", file.Path, err)
-				}
-
-				hasher := sha256.New()
-
-				n, err := io.Copy(hasher, r)
-				if err != nil {
-					return fmt.Errorf("reading zip file entry %s failed: %w", file.Path, err)
-				}
+		type location struct {
+			reader *zip.Reader
+			path   string
+		}
 
-				if n != file.Size {
-					return fmt.Errorf("checking zip file entry %s size failed: expected %d, got %d file size", file.Path, file.Size, n)
+		contents := map[string]location{} // sha256 -> content location within the chain
+		totalBytes := int64(0)
+		for _, a := range chain {
+			validated := map[string]struct{}{}
+			for _, store := range a.index.Stores {
+				for _, file := range store.Blobs {
+					if file.Path == "" {
+						continue
+					}
+
+					if _, ok := validated[file.Path]; ok {
+						continue
+					}
+
+					if err := validateEntry(a.reader, file); err != nil {
+						return err
+					}
+
+					validated[file.Path] = struct{}{}
+					contents[file.Sha256] = location{reader: a.reader, path: file.Path}
+					totalBytes += file.Size
 				}
+			}
+		}
 
-				hash := hex.EncodeToString(hasher.Sum(nil))
-				if hash != file.Sha256 {
-					return fmt.Errorf("invalid checksum %s", file.Path)
+		// the latest index describes the state to restore
+		index := chain[len(chain)-1].index
+		for _, store := range index.Stores {
+			for _, file := range store.Blobs {
+				if _, ok := contents[file.Sha256]; !ok {
+					return fmt.Errorf("content of %s in store %s is missing in the backup chain", file.ID, store.Name)
 				}
-
-				totalBytes += file.Size
 			}
 		}
 
@@ -133,45 +156,208 @@ func NewRestore(dst blob.Stores) Restore {
 			slog.Info("restore", "action", "copy new data", "name", idxStore.Name)
 
 			for _, b := range idxStore.Blobs {
-				cancelWriteCtx, cancelWrite := context.WithCancel(ctx)
-				writer, err := store.NewWriter(cancelWriteCtx, b.ID)
-				if err != nil {
-					cancelWrite()
-					return fmt.Errorf("could not create new blob writer in store %s: %w", idxStore.Name, err)
+				loc := contents[b.Sha256]
+				if err := restoreBlob(ctx, store, b.ID, loc.reader, loc.path); err != nil {
+					return fmt.Errorf("could not restore blob %s into store %s: %w", b.ID, idxStore.Name, err)
 				}
+			}
 
-				reader, err := zipReader.Open(b.Path)
-				if err != nil {
-					cancelWrite()
-					_ = writer.Close()
-					return fmt.Errorf("could not open zip file entry %s: %w", b.Path, err)
-				}
+			slog.Info("restore", "action", "complete", "name", idxStore.Name, "blobs", len(idxStore.Blobs))
+		}
 
-				if _, err := io.Copy(writer, reader); err != nil {
-					cancelWrite()
-					_ = writer.Close()
-					_ = reader.Close()
-					return fmt.Errorf("could not restore blob %s: %w", b.Path, err)
-				}
+		slog.Info("restore", "action", "complete", "totalBytes", totalBytes, "size", index.Size())
 
-				_ = reader.Close()
+		return nil
+	}
+}
 
-				if err := writer.Close(); err != nil {
-					cancelWrite()
-					return fmt.Errorf("could not close writer: %w", err)
-				}
+func NewReadIndex() ReadIndex {
+	return func(subject auth.Subject, src io.Reader) (Index, error) {
+		if err := subject.Audit(PermBackup); err != nil {
+			return Index{}, err
+		}
 
-				// need to free resources, even though writer has closed successfully
-				cancelWrite()
+		// a plain index.json starts with a json object, a zip file with its local file header signature
+		br := bufio.NewReader(src)
+		head, err := br.Peek(1)
+		if err != nil {
+			return Index{}, fmt.Errorf("could not read index: %w", err)
+		}
+
+		if head[0] == '{' {
+			var index Index
+			if err := json.NewDecoder(br).Decode(&index); err != nil {
+				return Index{}, fmt.Errorf("could not decode index: %w", err)
 			}
 
-			slog.Info("restore", "action", "complete", "name", idxStore.Name, "blobs", len(idxStore.Blobs))
+			return index, nil
 		}
 
-		slog.Info("restore", "action", "complete", "totalBytes", totalBytes, "size", index.Size())
+		a, err := receiveArchive(br)
+		if err != nil {
+			return Index{}, err
+		}
 
-		return nil
+		defer a.Close()
+
+		return a.index, nil
+	}
+}
+
+func receiveArchive(src io.Reader) (*archive, error) {
+	tempFilename := filepath.Join(os.TempDir(), data.RandIdent[string]()+".restore.zip")
+	tmpFile, err := os.OpenFile(tempFilename, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("could not create temp file: %w", err)
+	}
+
+	a := &archive{file: tmpFile}
+
+	slog.Info("restore", "action", "receiving zip file")
+	srcSize, err := io.Copy(tmpFile, src)
+	if err != nil {
+		_ = a.Close()
+		return nil, fmt.Errorf("could not copy to temp file: %w", err)
+	}
+
+	slog.Info("restore", "action", "copied zip file into temporary file", "file", tempFilename, "size", srcSize)
+	// copy is complete, now let us verify the zip file for any obvious corruptions
+	a.reader, err = zip.NewReader(tmpFile, srcSize)
+	if err != nil {
+		_ = a.Close()
+		return nil, fmt.Errorf("could not open zip reader: %w", err)
+	}
+
+	a.index, err = decodeIndex(a.reader)
+	if err != nil {
+		_ = a.Close()
+		return nil, fmt.Errorf("could not decode index: %w", err)
+	}
+
+	return a, nil
+}
+
+// orderChain sorts the archives from the full backup to the latest increment, independent of the upload order.
+func orderChain(archives []*archive) ([]*archive, error) {
+	if len(archives) == 1 {
+		if archives[0].index.Incremental() {
+			return nil, fmt.Errorf("incremental backup %s requires its base %s", archives[0].index.ID, archives[0].index.BaseID)
+		}
+
+		return archives, nil
+	}
+
+	byID := map[string]*archive{}
+	referenced := map[string]bool{}
+	for _, a := range archives {
+		if a.index.ID == "" {
+			return nil, fmt.Errorf("backups without id cannot be chained")
+		}
+
+		if _, ok := byID[a.index.ID]; ok {
+			return nil, fmt.Errorf("backup %s has been given multiple times", a.index.ID)
+		}
+
+		byID[a.index.ID] = a
+		referenced[a.index.BaseID] = true
+	}
+
+	var latest *archive
+	for _, a := range archives {
+		if referenced[a.index.ID] {
+			continue
+		}
+
+		if latest != nil {
+			return nil, fmt.Errorf("backups %s and %s do not belong to the same chain", latest.index.ID, a.index.ID)
+		}
+
+		latest = a
+	}
+
+	if latest == nil {
+		return nil, fmt.Errorf("backups are cyclic")
+	}
+
+	var chain []*archive
+	for a := latest; ; {
+		chain = append([]*archive{a}, chain...)
+		if !a.index.Incremental() {
+			break
+		}
+
+		base, ok := byID[a.index.BaseID]
+		if !ok {
+			return nil, fmt.Errorf("base %s of backup %s is missing", a.index.BaseID, a.index.ID)
+		}
+
+		a = base
 	}
+
+	if len(chain) != len(archives) {
+		return nil, fmt.Errorf("backups do not belong to the same chain")
+	}
+
+	return chain, nil
+}
+
+func validateEntry(zipReader *zip.Reader, file Blob) error {
+	r, err := zipReader.Open(file.Path)
+	if err != nil {
+		return fmt.Errorf("could not open zip file entry %s: %w", file.Path, err)
+	}
+
+	defer r.Close()
+
+	hasher := sha256.New()
+
+	n, err := io.Copy(hasher, r)
+	if err != nil {
+		return fmt.Errorf("reading zip file entry %s failed: %w", file.Path, err)
+	}
+
+	if n != file.Size {
+		return fmt.Errorf("checking zip file entry %s size failed: expected %d, got %d file size", file.Path, file.Size, n)
+	}
+
+	hash := hex.EncodeToString(hasher.Sum(nil))
+	if hash != file.Sha256 {
+		return fmt.Errorf("invalid checksum %s", file.Path)
+	}
+
+	return nil
+}
+
+func restoreBlob(ctx context.Context, store blob.Store, key string, zipReader *zip.Reader, path string) error {
+	cancelWriteCtx, cancelWrite := context.WithCancel(ctx)
+	// need to free resources, even though writer has closed successfully
+	defer cancelWrite()
+
+	writer, err := store.NewWriter(cancelWriteCtx, key)
+	if err != nil {
+		return fmt.Errorf("could not create new blob writer: %w", err)
+	}
+
+	reader, err := zipReader.Open(path)
+	if err != nil {
+		cancelWrite()
+		_ = writer.Close()
+		return fmt.Errorf("could not open zip file entry %s: %w", path, err)
+	}
+
+	defer reader.Close()
+
+	if _, err := io.Copy(writer, reader); err != nil {
+		cancelWrite()
+		_ = writer.Close()
+		return fmt.Errorf("could not restore blob %s: %w", path, err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("could not close writer: %w", err)
+	}
+
+	return nil
 }
 
 func decodeIndex(zipReader *zip.Reader) (Index, error) {
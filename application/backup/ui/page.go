@@ -8,6 +8,8 @@
 package uibackup
 
 import (
+	"io"
+
 	"go.wdy.de/nago/application/backup"
 	"go.wdy.de/nago/pkg/std"
 	"go.wdy.de/nago/presentation/core"
@@ -35,7 +37,7 @@ func BackupAndRestorePage(wnd core.Window, uc backup.UseCases) core.View {
 			Body(ui.VStack(
 				ui.Text("Mit dieser Funktion wird ein vollständiges Backup aller Daten erstellt. Das Backup enthält ggf. unverschlüsselte und im Klartext lesbare vertrauliche und personenbezogene Daten. "+
 					"Die Backup-Datei muss entsprechend vertraulich und gemäß der Richtlinien behandelt werden. Dieser Vorgang wird entsprechend im Log mit Ihren Nutzerdaten hinterlegt. "+
-					"Lassen Sie diese Seite geöffnet und stellen Sie sicher, dass mit dem System nicht gearbeitet wird, um einen konsistenten Zustand über alle Store zu erhalten. "+
+					"Alle Stores werden zum gleichen Zeitpunkt erfasst, sodass das System währenddessen weiter genutzt werden kann. Lassen Sie diese Seite geöffnet. "+
 					"Der Download der Zip-Datei startet sofort. Warten Sie ab, bis der Download vollständig ist. "+
 					"\n\n"+
 					"Mit Nago verschlüsselte Stores bleiben verschlüsselt und können ohne den Masterkey nicht wieder hergestellt werden. Dieser Schlüssel ist nicht im Backup enthalten. Zu den standardmäßig verschlüsselten Stores gehören die Sessions und Secrets."),
@@ -49,11 +51,51 @@ func BackupAndRestorePage(wnd core.Window, uc backup.UseCases) core.View {
 				Title("Backup erstellen"),
 		),
 
+		cardlayout.Card("Inkrementelles Backup").
+			Body(ui.VStack(
+				ui.Text("Ein inkrementelles Backup enthält nur die Daten, die sich seit einem vorherigen Backup geändert haben. "+
+					"Dazu wird das vorherige vollständige oder inkrementelle Backup bzw. die darin enthaltene index.json ausgewählt. "+
+					"Für eine Wiederherstellung werden das vollständige Backup und alle darauf aufbauenden inkrementellen Backups benötigt."),
+			)).Footer(
+			ui.PrimaryButton(func() {
+				wnd.ImportFiles(core.ImportFilesOptions{
+					Multiple:         false,
+					MaxBytes:         1024 * 1024 * 1024 * 1024, // 1TiB limit
+					AllowedMimeTypes: []string{"application/zip", "application/json"},
+					OnCompletion: func(files []core.File) {
+						if len(files) != 1 {
+							alert.ShowBannerError(wnd, std.NewLocalizedError("Fehlerhafter Upload", "Exakt eine Datei erwartet."))
+							return
+						}
+
+						reader, err := files[0].Open()
+						if err != nil {
+							alert.ShowBannerError(wnd, err)
+							return
+						}
+
+						defer reader.Close()
+
+						base, err := uc.ReadIndex(wnd.Subject(), reader)
+						if err != nil {
+							alert.ShowBannerError(wnd, err)
+							return
+						}
+
+						wnd.ExportFiles(core.ExportFilesOptions{
+							Files: []core.File{backup.AsIncrementalBackupFile(wnd.Context(), wnd.Subject(), uc.BackupWithOptions, base)},
+						})
+					},
+				})
+			}).Title("Inkrementelles Backup erstellen"),
+		),
+
 		cardlayout.Card("Wiederherstellung").
 			Body(ui.VStack(
 				ui.Text("Mit dieser Funktion wird ein Zustand aus einem Backup wiederhergestellt. Alle vorhandenen Daten werden dabei gelöscht und aus dem Backup neu erzeugt. "+
 					"Blob- oder Bucket-Stores, die nicht Teil des Backups sind, bleiben unverändert. "+
 					"Dieser Vorgang vernichtet Daten und ist nicht reversibel. "+
+					"Für ein inkrementelles Backup müssen das zugehörige vollständige Backup und alle vorherigen inkrementellen Backups gemeinsam ausgewählt werden. "+
 					"Versichern Sie sich, dass die Backup-Datei aus einer vertraulichen Quelle stammt, da ansonsten Dritte Zugriff auf das System erlangen können. "+
 					"Dieser Vorgang wird entsprechend im Log mit Ihren Nutzerdaten hinterlegt. "+
 					"Die Wiederherstellung kann einige Zeit dauern und wird bestätigt. Lassen Sie diese Seite geöffnet. "+
@@ -63,24 +105,28 @@ func BackupAndRestorePage(wnd core.Window, uc backup.UseCases) core.View {
 			ui.PrimaryButton(func() {
 				restoreBtnEnabled.Set(false)
 				wnd.ImportFiles(core.ImportFilesOptions{
-					Multiple:         false,
+					Multiple:         true,
 					MaxBytes:         1024 * 1024 * 1024 * 1024, // 1TiB limit
 					AllowedMimeTypes: []string{"application/zip"},
 					OnCompletion: func(files []core.File) {
-						if len(files) != 1 {
-							alert.ShowBannerError(wnd, std.NewLocalizedError("Fehlerhafter Upload", "Exakt eine Datei erwartet."))
+						if len(files) == 0 {
+							alert.ShowBannerError(wnd, std.NewLocalizedError("Fehlerhafter Upload", "Mindestens eine Datei erwartet."))
 							return
 						}
 
-						reader, err := files[0].Open()
-						if err != nil {
-							alert.ShowBannerError(wnd, err)
-							return
-						}
+						var readers []io.Reader
+						for _, file := range files {
+							reader, err := file.Open()
+							if err != nil {
+								alert.ShowBannerError(wnd, err)
+								return
+							}
 
-						defer reader.Close()
+							defer reader.Close()
+							readers = append(readers, reader)
+						}
 
-						if err := uc.Restore(wnd.Context(), wnd.Subject(), reader); err != nil {
+						if err := uc.RestoreChain(wnd.Context(), wnd.Subject(), readers); err != nil {
 							alert.ShowBannerError(wnd, err)
 							return
 						}
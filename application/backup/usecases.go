@@ -10,6 +10,7 @@ package backup
 import (
 	"context"
 	"fmt"
+	"github.com/worldiety/option"
 	"go.wdy.de/nago/auth"
 	"go.wdy.de/nago/pkg/blob"
 	"go.wdy.de/nago/pkg/blob/crypto"
@@ -23,19 +24,48 @@ type ReplaceMasterKey func(subject auth.Subject, key string) error
 type Backup func(ctx context.Context, subject auth.Subject, dst io.Writer) error
 type Restore func(ctx context.Context, subject auth.Subject, src io.Reader) error
 
+// Options configure a [BackupWithOptions].
+type Options struct {
+	// Base is the index of a previous full or incremental backup, see [ReadIndex]. If set, an incremental backup
+	// is created, which only contains the content of blobs which are not part of the base. Restoring it requires
+	// the entire chain of backups, see [RestoreChain].
+	Base option.Opt[Index]
+
+	// Snapshot captures all stores at the same point in time. Writers are only paused while the snapshots are
+	// taken and not during the entire backup. This fails, if a store does not support snapshots.
+	Snapshot bool
+}
+
+// BackupWithOptions writes a full or incremental backup and returns its index, which can be used as the base
+// of the next incremental backup. [Backup] is a full backup using a snapshot if possible.
+type BackupWithOptions func(ctx context.Context, subject auth.Subject, opts Options, dst io.Writer) (Index, error)
+
+// ReadIndex reads the index of a backup from either the backup zip file or the index.json contained in it.
+type ReadIndex func(subject auth.Subject, src io.Reader) (Index, error)
+
+// RestoreChain restores a full backup together with any number of incremental backups based on it. The order
+// of the sources does not matter. [Restore] is just a chain of a single full backup.
+type RestoreChain func(ctx context.Context, subject auth.Subject, srcs []io.Reader) error
+
 type UseCases struct {
-	Backup           Backup
-	Restore          Restore
-	ExportMasterKey  ExportMasterKey
-	ReplaceMasterKey ReplaceMasterKey
+	Backup            Backup
+	BackupWithOptions BackupWithOptions
+	ReadIndex         ReadIndex
+	Restore           Restore
+	RestoreChain      RestoreChain
+	ExportMasterKey   ExportMasterKey
+	ReplaceMasterKey  ReplaceMasterKey
 }
 
 func NewUseCases(p blob.Stores, getCryptoKey func() crypto.EncryptionKey, setCryptoKey func(crypto.EncryptionKey)) UseCases {
 	return UseCases{
-		Backup:           NewBackup(p),
-		Restore:          NewRestore(p),
-		ExportMasterKey:  NewExportMasterKey(getCryptoKey),
-		ReplaceMasterKey: NewImportMasterKey(setCryptoKey),
+		Backup:            NewBackup(p),
+		BackupWithOptions: NewBackupWithOptions(p),
+		ReadIndex:         NewReadIndex(),
+		Restore:           NewRestore(p),
+		RestoreChain:      NewRestoreChain(p),
+		ExportMasterKey:   NewExportMasterKey(getCryptoKey),
+		ReplaceMasterKey:  NewImportMasterKey(setCryptoKey),
 	}
 }
 
@@ -47,10 +77,24 @@ func AsBackupFile(ctx context.Context, subject auth.Subject, backup Backup) core
 	}
 }
 
+// AsIncrementalBackupFile returns a file, which transfers an incremental backup based on the given index.
+func AsIncrementalBackupFile(ctx context.Context, subject auth.Subject, backup BackupWithOptions, base Index) core.File {
+	return backupFile{
+		backup: func(ctx context.Context, subject auth.Subject, dst io.Writer) error {
+			_, err := backup(ctx, subject, Options{Base: option.Some(base), Snapshot: true}, dst)
+			return err
+		},
+		subject:     subject,
+		ctx:         ctx,
+		incremental: true,
+	}
+}
+
 type backupFile struct {
-	backup  Backup
-	subject auth.Subject
-	ctx     context.Context
+	backup      Backup
+	subject     auth.Subject
+	ctx         context.Context
+	incremental bool
 }
 
 func (b backupFile) Open() (io.ReadCloser, error) {
@@ -58,6 +102,10 @@ func (b backupFile) Open() (io.ReadCloser, error) {
 }
 
 func (b backupFile) Name() string {
+	if b.incremental {
+		return fmt.Sprintf("backup_%s_incremental.zip", time.Now().Format(time.RFC3339))
+	}
+
 	return fmt.Sprintf("backup_%s.zip", time.Now().Format(time.RFC3339))
 }
 
@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"io/fs"
 	"log/slog"
+	"net/netip"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -26,12 +27,15 @@ import (
 	"go.wdy.de/nago/application/admin"
 	"go.wdy.de/nago/application/migration"
 	"go.wdy.de/nago/application/rebac"
+	"go.wdy.de/nago/application/user"
 	"go.wdy.de/nago/auth"
 	"go.wdy.de/nago/pkg/blob"
 	"go.wdy.de/nago/pkg/data/json"
 	"go.wdy.de/nago/pkg/events"
+	"go.wdy.de/nago/pkg/events/durable"
 	"go.wdy.de/nago/pkg/ndb"
 	"go.wdy.de/nago/pkg/sitemap"
+	"go.wdy.de/nago/pkg/xhttp"
 	"go.wdy.de/nago/presentation/core"
 	"go.wdy.de/nago/presentation/proto"
 )
@@ -58,6 +62,7 @@ type Configurator struct {
 	host                       string
 	port                       int
 	scheme                     string
+	trustedProxies             xhttp.TrustedProxies
 	applicationID              core.ApplicationID
 	applicationName            string
 	applicationVersion         string
@@ -66,6 +71,7 @@ type Configurator struct {
 	dataDir                    string
 	factories                  map[proto.RootViewID]func(wnd core.Window) core.View
 	onWindowCreatedObservers   []core.OnWindowCreatedObserver
+	onInteractionObservers     []core.OnWindowInteractionObserver
 	destructors                []func()
 	app                        *core.Application // may be nil
 	rawEndpoint                []rawEndpoint
@@ -85,6 +91,7 @@ type Configurator struct {
 	adminManagementGroups  []func(uid auth.Subject) admin.Group
 	adminManagementMutator func(m *AdminManagement)
 	sessionManagement      *SessionManagement
+	passwordAuthenticators []user.AuthenticateByPassword
 	permissionManagement   *PermissionManagement
 	groupManagement        *GroupManagement
 	imageManagement        *ImageManagement
@@ -93,8 +100,14 @@ type Configurator struct {
 	templateManagement     *TemplateManagement
 	themeManagement        *ThemeManagement
 	tokenManagement        *TokenManagement
+	mfaManagement          *MFAManagement
+	lockoutManagement      *LockoutManagement
+	auditManagement        *AuditManagement
+	oidcManagement         *OIDCManagement
+	oauthManagement        *OAuthManagement
 	decorator              Decorator
 	eventBus               events.EventBus
+	durableBus             *durable.Bus
 	contextPath            atomic.Pointer[string]
 	hasSSL                 bool
 	noFooter               []core.NavigationPath
@@ -227,6 +240,18 @@ var envConfig = []envVarConfig{
 			return nil
 		},
 	},
+	{
+		key:      "NAGO_TRUSTED_PROXIES",
+		required: false,
+		cb: func(env envVarConfig, s string, cfg *Configurator, logger *slog.Logger) error {
+			proxies, err := xhttp.ParseTrustedProxies(s)
+			if err != nil {
+				return fmt.Errorf("invalid value in %s: %w", env.key, err)
+			}
+			cfg.SetTrustedProxies(proxies...)
+			return nil
+		},
+	},
 	{
 		key:      "HOSTNAME",
 		required: false,
@@ -273,6 +298,13 @@ func (c *Configurator) AddOnWindowCreatedObserver(observer core.OnWindowCreatedO
 	return c
 }
 
+// AddOnWindowInteractionObserver registers an observer, which is notified when the user interacts with a window.
+// See [core.OnWindowInteractionObserver] for the throttling.
+func (c *Configurator) AddOnWindowInteractionObserver(observer core.OnWindowInteractionObserver) *Configurator {
+	c.onInteractionObservers = append(c.onInteractionObservers, observer)
+	return c
+}
+
 func (c *Configurator) OnDestroy(f func()) {
 	c.destructors = append(c.destructors, f)
 }
@@ -483,6 +515,15 @@ func (c *Configurator) SetHost(host string) *Configurator {
 	return c
 }
 
+// SetTrustedProxies declares the addresses of the reverse proxies in front of the application. Only their
+// X-Forwarded-For and X-Real-IP headers are used to determine the address of a client, e.g. for the brute-force
+// protection and the allowed networks of API tokens. By default, no proxy is trusted and the address of the
+// direct peer is used. See also the NAGO_TRUSTED_PROXIES environment variable.
+func (c *Configurator) SetTrustedProxies(prefixes ...netip.Prefix) *Configurator {
+	c.trustedProxies = prefixes
+	return c
+}
+
 func (c *Configurator) getPort() int {
 	if c.port != 0 {
 		return c.port
@@ -562,6 +603,10 @@ func (c *Configurator) StandardSystems() error {
 		return err
 	}
 
+	if _, err := c.AuditManagement(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -0,0 +1,117 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.wdy.de/nago/application/mail"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/pkg/events"
+	"go.wdy.de/nago/pkg/events/durable"
+	"go.wdy.de/nago/pkg/ndb"
+	"go.wdy.de/nago/pkg/ndb/msgstore"
+	"go.wdy.de/nago/presentation/core"
+)
+
+// durableEventsRetention bounds the event log of the durable bus. Delivered events are not needed anymore, but
+// are kept for a while to be able to inspect them. Undelivered events are never dropped, see [durable.Bus.Committed].
+const durableEventsRetention = 30 * 24 * time.Hour
+
+// EnableDurableEvents replaces the in-memory [Configurator.EventBus] with a [durable.Bus], which persists events
+// into the "events" message engine of the shared [Configurator.NDB] before they are delivered. The delivery is
+// at-least-once and survives a crash or restart. It is started automatically when the application runs.
+//
+// The mail requests ([mail.SendMailRequested]) and the user lifecycle events [user.Created] and [user.EMailChanged]
+// are durable by default. Declare further types with [durable.Register]. All other events keep their in-memory
+// fire-and-forget semantics.
+//
+// This changes the contract for the subscribers of durable types: they are no longer invoked concurrently, but
+// one after another by a single delivery goroutine. Thus, a slow subscriber delays all others, and a panicking
+// subscriber pauses the delivery of all later durable events until it has handled the failed event or the event
+// is skipped, see [durable.Options.MaxAttempts]. Only the subscribers which have not handled the failed event yet
+// receive it again, but after a restart all of them do, therefore subscribers must be idempotent.
+//
+// Use cases capture the bus when they are created, and subscriptions are bound to the bus at subscription time.
+// Thus, this must be called before any system is enabled, and further types must be registered before anyone
+// subscribes to them.
+func (c *Configurator) EnableDurableEvents() (*durable.Bus, error) {
+	if c.durableBus != nil {
+		return c.durableBus, nil
+	}
+
+	db, err := c.NDB()
+	if err != nil {
+		return nil, err
+	}
+
+	// the bus does not exist yet when the engine is opened, thus the floor keeps everything until then
+	var bus *durable.Bus
+	floor := func() (ndb.Seq, error) {
+		if bus == nil {
+			return 0, nil
+		}
+
+		return bus.Committed()
+	}
+
+	eng, err := db.Engine("events", ndb.EngineOptions{
+		Kind: msgstore.EngineKind,
+		Config: msgstore.Options{
+			Retention: msgstore.RetainAll(msgstore.RetentionPolicy{MaxAge: durableEventsRetention, Floor: floor}),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot open durable events engine: %w", err)
+	}
+
+	msgs, ok := eng.(ndb.MessageEngine)
+	if !ok {
+		return nil, fmt.Errorf("durable events engine is not a message engine: %T", eng)
+	}
+
+	cursors, err := c.EntityStore("nago.events.cursor")
+	if err != nil {
+		return nil, err
+	}
+
+	bus = durable.NewBus(msgs.Messages(), cursors, durable.Options{Fallback: c.eventBus})
+	for _, err := range []error{
+		durable.Register[mail.SendMailRequested](bus, "nago.mail.SendMailRequested"),
+		durable.Register[user.Created](bus, "nago.user.Created"),
+		durable.Register[user.EMailChanged](bus, "nago.user.EMailChanged"),
+	} {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	c.durableBus = bus
+	c.eventBus = bus
+	c.AddContextValue(core.ContextValue[events.EventBus]("", bus))
+
+	return bus, nil
+}
+
+// runDurableEvents starts the delivery of the durable bus, if enabled.
+func (c *Configurator) runDurableEvents() {
+	bus := c.durableBus
+	if bus == nil {
+		return
+	}
+
+	go func() {
+		if err := bus.Run(c.Context()); err != nil && !errors.Is(err, context.Canceled) {
+			slog.Error("durable event bus stopped", "err", err.Error())
+		}
+	}()
+}
@@ -39,6 +39,7 @@ import (
 	"go.wdy.de/nago/logging"
 	"go.wdy.de/nago/pkg/blob/crypto"
 	"go.wdy.de/nago/pkg/std"
+	"go.wdy.de/nago/pkg/xhttp"
 	"go.wdy.de/nago/presentation/core"
 	"go.wdy.de/nago/presentation/core/http/gorilla"
 	"go.wdy.de/nago/presentation/proto"
@@ -172,6 +173,7 @@ func (c *Configurator) newHandler() http.Handler {
 		sessionMgmt.UseCases.Logout,
 	)
 	app2.SetDebug(c.IsDebug())
+	app2.SetOnWindowInteractionObservers(c.onInteractionObservers)
 	app2.AddDestructor(func() {
 		if err := c.stores.Close(); err != nil {
 			slog.Error("cannot close stores", "err", err.Error())
@@ -255,6 +257,9 @@ func (c *Configurator) newHandler() http.Handler {
 	}
 	r.Use(
 		c.loggerMiddleware,
+		func(h http.Handler) http.Handler {
+			return xhttp.WithRemoteIP(c.trustedProxies, h)
+		},
 	)
 
 	r.Mount("/api/nago/v1/instance", http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
@@ -611,7 +616,7 @@ func (c *Configurator) newHandler() http.Handler {
 		}()
 		channel := gorilla.NewWebsocketChannel(conn)
 		scope := app2.Connect(channel, proto.ScopeID(scopeID))
-		_ = scope
+		scope.SetRemoteAddr(xhttp.RemoteIP(r))
 		//defer scope.Destroy() we don't want that, the client cannot recover through a new channel otherwise
 
 		cookie, _ := r.Cookie("wdy-ora-access")
@@ -0,0 +1,135 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package chatbot
+
+import (
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/worldiety/option"
+	"go.wdy.de/nago/application/ai/completion"
+	"go.wdy.de/nago/application/ai/model"
+	"go.wdy.de/nago/application/chatbot/provider"
+	"go.wdy.de/nago/application/chatbot/user"
+)
+
+// Sender is the chat account which caused an event. It is an account of the provider and not a user of this
+// application, thus it grants nothing by itself: a handler must decide on its own, what the sender may do.
+type Sender struct {
+	Provider provider.ID
+	User     user.ID
+	UserName string
+}
+
+func newSender(id provider.ID, evt provider.Event) Sender {
+	return Sender{Provider: id, User: evt.User, UserName: evt.UserName}
+}
+
+// ActionFunc handles the click on a [message.Button].
+type ActionFunc func(sender Sender, evt provider.Event) (provider.Reply, error)
+
+// Agent answers free text messages using an ai model. Each message is answered without any history, thus
+// the agent should fetch the required context through its tools.
+type Agent struct {
+	Completions completion.Completions
+	Model       model.ID
+	System      string
+	MaxTokens   int
+
+	// Tools returns the tools which the agent may call on behalf of the sender. A tool must only reveal what
+	// the sender is allowed to see, because the agent itself runs with the permissions of [BotSubject]. May be
+	// nil.
+	Tools func(sender Sender) []completion.Tool
+
+	// MaxTurns limits the tool calls, see [completion.RunOptions.MaxTurns].
+	MaxTurns int
+}
+
+// Bot holds the commands, button actions and the optional agent, to which inbound events are dispatched.
+// Register them once at startup, e.g. through the Management of the chatbot configuration module. A Bot is
+// safe for concurrent use.
+type Bot struct {
+	mutex    sync.RWMutex
+	commands map[string]Command
+	actions  map[string]ActionFunc
+	agent    option.Opt[Agent]
+}
+
+func NewBot() *Bot {
+	return &Bot{
+		commands: map[string]Command{},
+		actions:  map[string]ActionFunc{},
+	}
+}
+
+// Command registers the command and replaces any other command with the same name. A command named help
+// replaces the generated overview.
+func (b *Bot) Command(cmd Command) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.commands[strings.ToLower(cmd.Name)] = cmd
+}
+
+// Action registers the handler, which receives the clicks on all buttons with the given action name.
+func (b *Bot) Action(name string, fn ActionFunc) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.actions[name] = fn
+}
+
+// Agent answers all free text messages. Without an agent, such messages are only published as
+// [message.Received].
+func (b *Bot) Agent(agent Agent) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.agent = option.Some(agent)
+}
+
+// Commands returns all registered commands sorted by name.
+func (b *Bot) Commands() []Command {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	var res []Command
+	for _, cmd := range b.commands {
+		res = append(res, cmd)
+	}
+
+	slices.SortFunc(res, func(a, b Command) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return res
+}
+
+func (b *Bot) command(name string) (Command, bool) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	cmd, ok := b.commands[strings.ToLower(name)]
+	return cmd, ok
+}
+
+func (b *Bot) action(name string) (ActionFunc, bool) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	fn, ok := b.actions[name]
+	return fn, ok
+}
+
+func (b *Bot) currentAgent() option.Opt[Agent] {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	return b.agent
+}
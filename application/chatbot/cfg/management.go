@@ -14,6 +14,7 @@ import (
 	"go.wdy.de/nago/application"
 	"go.wdy.de/nago/application/admin"
 	"go.wdy.de/nago/application/chatbot"
+	chatbothttp "go.wdy.de/nago/application/chatbot/http"
 	uichatbot "go.wdy.de/nago/application/chatbot/ui"
 	"go.wdy.de/nago/auth"
 	"go.wdy.de/nago/presentation/core"
@@ -29,6 +30,10 @@ var (
 type Management struct {
 	UseCases chatbot.UseCases
 	Pages    uichatbot.Pages
+
+	// Bot receives the commands, button actions and the agent, to which inbound events are dispatched. Events
+	// are dispatched with [chatbot.BotSubject], thus the handlers must check the [chatbot.Sender] themselves.
+	Bot *chatbot.Bot
 }
 
 func Enable(cfg *application.Configurator) (Management, error) {
@@ -42,13 +47,17 @@ func Enable(cfg *application.Configurator) (Management, error) {
 		return Management{}, err
 	}
 
-	ucSMS := chatbot.NewUseCases(cfg.Context(), cfg.EventBus(), secrets.UseCases.FindGroupSecrets)
+	bot := chatbot.NewBot()
+	ucSMS := chatbot.NewUseCases(cfg.Context(), cfg.EventBus(), secrets.UseCases.FindGroupSecrets, bot)
+
+	cfg.HandleFunc(chatbothttp.Endpoint, chatbothttp.NewHandler(ucSMS.FindProviderByID, ucSMS.Dispatch))
 
 	management = Management{
 		UseCases: ucSMS,
 		Pages: uichatbot.Pages{
 			Send: "admin/chatbot/send",
 		},
+		Bot: bot,
 	}
 
 	cfg.RootViewWithDecoration(management.Pages.Send, func(wnd core.Window) core.View {
@@ -71,7 +80,7 @@ func Enable(cfg *application.Configurator) (Management, error) {
 		return grp
 	})
 
-	//	cfg.AddContextValue(core.ContextValue("nago.ai", management))
+	cfg.AddContextValue(core.ContextValue("nago.chatbot", management))
 
 	slog.Info("installed chatbot module")
 	return management, nil
@@ -0,0 +1,461 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package chatbot
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.wdy.de/nago/application/chatbot/provider"
+)
+
+// Param describes a single argument of a [Command]. It is derived from a struct field.
+type Param struct {
+	Name        string
+	Description string
+	Required    bool
+
+	field []int
+	typ   reflect.Type
+}
+
+// Command is a slash command like /deploy. Use [NewCommand] to derive the parameters and the argument parsing
+// from a Go struct.
+type Command struct {
+	// Name is the command without the leading slash.
+	Name        string
+	Description string
+	Params      []Param
+
+	// Invoke parses the raw arguments and calls the handler. Invalid arguments are reported as [UsageError].
+	Invoke func(sender Sender, evt provider.Event, args string) (provider.Reply, error)
+}
+
+// CommandFunc handles a slash command with its parsed arguments.
+type CommandFunc[In any] func(sender Sender, evt provider.Event, in In) (provider.Reply, error)
+
+// UsageError is returned by [Command.Invoke], if the arguments cannot be parsed.
+type UsageError struct {
+	Command string
+	Err     error
+}
+
+func (e *UsageError) Error() string {
+	return fmt.Sprintf("invalid arguments for /%s: %v", e.Command, e.Err)
+}
+
+func (e *UsageError) Unwrap() error {
+	return e.Err
+}
+
+// NewCommand wraps a Go function into a [Command]. The parameters are derived by reflection from the fields of
+// the In struct, just like [completion.NewTool]: the json tag defines the name and the omitempty option or
+// a pointer type makes it optional. An optional `desc`/`description` tag is shown in the usage.
+//
+// Arguments are either given by name as name=value or --name value, or positionally in the order of the
+// fields. Boolean fields are flags like --force. A trailing string field takes the remaining words and a
+// []string field collects all remaining positional arguments. Values may be quoted with " or '.
+//
+// Supported field types are strings, booleans, integers, floats, [time.Duration], []string and pointers
+// thereof. NewCommand panics on other types, because that is a programming error.
+func NewCommand[In any](name, description string, fn CommandFunc[In]) Command {
+	var zeroIn In
+	t := reflect.TypeOf(&zeroIn).Elem()
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Errorf("command %s: input must be a struct but got %v", name, t))
+	}
+
+	params := collectParams(t, nil)
+	for _, p := range params {
+		if !supportedParamType(p.typ) {
+			panic(fmt.Errorf("command %s: unsupported type %v of parameter %s", name, p.typ, p.Name))
+		}
+	}
+
+	cmd := Command{
+		Name:        name,
+		Description: description,
+		Params:      params,
+	}
+
+	cmd.Invoke = func(sender Sender, evt provider.Event, args string) (provider.Reply, error) {
+		var in In
+		if err := parseArgs(reflect.ValueOf(&in).Elem(), params, args); err != nil {
+			return provider.Reply{}, &UsageError{Command: name, Err: err}
+		}
+
+		return fn(sender, evt, in)
+	}
+
+	return cmd
+}
+
+// Usage returns a single line like /deploy <service> [env=<string>] [--force].
+func (c Command) Usage() string {
+	var sb strings.Builder
+	sb.WriteString("/")
+	sb.WriteString(c.Name)
+	for _, p := range c.Params {
+		sb.WriteString(" ")
+		switch {
+		case p.isBool():
+			sb.WriteString("[--" + p.Name + "]")
+		case p.Required:
+			sb.WriteString("<" + p.Name + ">")
+		default:
+			sb.WriteString("[" + p.Name + "=<" + p.typeName() + ">]")
+		}
+	}
+
+	return sb.String()
+}
+
+// Help returns the usage followed by the description of the command and of each described parameter.
+func (c Command) Help() string {
+	var sb strings.Builder
+	sb.WriteString(c.Usage())
+	if c.Description != "" {
+		sb.WriteString("\n")
+		sb.WriteString(c.Description)
+	}
+
+	for _, p := range c.Params {
+		if p.Description == "" {
+			continue
+		}
+
+		fmt.Fprintf(&sb, "\n- %s: %s", p.Name, p.Description)
+	}
+
+	return sb.String()
+}
+
+func (p Param) elem() reflect.Type {
+	if p.typ.Kind() == reflect.Pointer {
+		return p.typ.Elem()
+	}
+
+	return p.typ
+}
+
+func (p Param) isBool() bool {
+	return p.elem().Kind() == reflect.Bool
+}
+
+func (p Param) isList() bool {
+	return p.elem().Kind() == reflect.Slice
+}
+
+func (p Param) typeName() string {
+	t := p.elem()
+	switch {
+	case t == reflect.TypeOf(time.Duration(0)):
+		return "duration"
+	case t.Kind() == reflect.Slice:
+		return "list"
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return "number"
+	case t.Kind() == reflect.String:
+		return "string"
+	default:
+		return "integer"
+	}
+}
+
+func collectParams(t reflect.Type, index []int) []Param {
+	var params []Param
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+
+		name, omitempty, skip := parseJSONField(f)
+		if skip {
+			continue
+		}
+
+		fieldIndex := append(append([]int(nil), index...), i)
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			params = append(params, collectParams(f.Type, fieldIndex)...)
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		desc := f.Tag.Get("desc")
+		if desc == "" {
+			desc = f.Tag.Get("description")
+		}
+
+		p := Param{
+			Name:        name,
+			Description: desc,
+			field:       fieldIndex,
+			typ:         f.Type,
+		}
+
+		p.Required = !omitempty && f.Type.Kind() != reflect.Pointer && !p.isBool()
+		params = append(params, p)
+	}
+
+	return params
+}
+
+func parseJSONField(f reflect.StructField) (name string, omitempty bool, skip bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitempty = true
+		}
+	}
+
+	if name == "" && !f.Anonymous {
+		name = f.Name
+	}
+
+	return name, omitempty, false
+}
+
+func supportedParamType(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.String
+	default:
+		return false
+	}
+}
+
+func parseArgs(in reflect.Value, params []Param, args string) error {
+	tokens, err := splitArgs(args)
+	if err != nil {
+		return err
+	}
+
+	byName := map[string]Param{}
+	for _, p := range params {
+		byName[p.Name] = p
+	}
+
+	set := map[string]bool{}
+	var positional []string
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+
+		if strings.HasPrefix(token, "--") && len(token) > 2 {
+			name, value, hasValue := strings.Cut(token[2:], "=")
+			p, ok := byName[name]
+			if !ok {
+				return fmt.Errorf("unknown argument %s", name)
+			}
+
+			if !hasValue {
+				if p.isBool() {
+					value = "true"
+				} else if i+1 < len(tokens) {
+					i++
+					value = tokens[i]
+				} else {
+					return fmt.Errorf("missing value of %s", name)
+				}
+			}
+
+			if err := setParam(in, p, value); err != nil {
+				return err
+			}
+
+			set[name] = true
+			continue
+		}
+
+		if name, value, ok := strings.Cut(token, "="); ok {
+			if p, known := byName[name]; known {
+				if err := setParam(in, p, value); err != nil {
+					return err
+				}
+
+				set[name] = true
+				continue
+			}
+		}
+
+		positional = append(positional, token)
+	}
+
+	var open []Param
+	for _, p := range params {
+		if !set[p.Name] && !p.isBool() {
+			open = append(open, p)
+		}
+	}
+
+	for i, p := range open {
+		if len(positional) == 0 {
+			break
+		}
+
+		switch {
+		case p.isList():
+			for _, value := range positional {
+				if err := setParam(in, p, value); err != nil {
+					return err
+				}
+			}
+
+			positional = nil
+		case i == len(open)-1 && p.elem().Kind() == reflect.String:
+			if err := setParam(in, p, strings.Join(positional, " ")); err != nil {
+				return err
+			}
+
+			positional = nil
+		default:
+			if err := setParam(in, p, positional[0]); err != nil {
+				return err
+			}
+
+			positional = positional[1:]
+		}
+
+		set[p.Name] = true
+	}
+
+	if len(positional) > 0 {
+		return fmt.Errorf("too many arguments: %s", strings.Join(positional, " "))
+	}
+
+	for _, p := range params {
+		if p.Required && !set[p.Name] {
+			return fmt.Errorf("missing argument %s", p.Name)
+		}
+	}
+
+	return nil
+}
+
+func setParam(in reflect.Value, p Param, value string) error {
+	field := in.FieldByIndex(p.field)
+	if field.Kind() == reflect.Pointer {
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+
+		field = field.Elem()
+	}
+
+	var err error
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		var b bool
+		b, err = strconv.ParseBool(value)
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if field.Type() == reflect.TypeOf(time.Duration(0)) {
+			var d time.Duration
+			d, err = time.ParseDuration(value)
+			field.SetInt(int64(d))
+			break
+		}
+
+		var i int64
+		i, err = strconv.ParseInt(value, 10, field.Type().Bits())
+		field.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		u, err = strconv.ParseUint(value, 10, field.Type().Bits())
+		field.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		f, err = strconv.ParseFloat(value, field.Type().Bits())
+		field.SetFloat(f)
+	case reflect.Slice:
+		field.Set(reflect.Append(field, reflect.ValueOf(value).Convert(field.Type().Elem())))
+	}
+
+	if numErr := (*strconv.NumError)(nil); errors.As(err, &numErr) {
+		err = numErr.Err
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid %s %q: %w", p.Name, value, err)
+	}
+
+	return nil
+}
+
+// splitArgs splits at whitespace, while single or double quoted sections are kept together. A backslash
+// escapes the next character within double quotes and outside of quotes.
+func splitArgs(s string) ([]string, error) {
+	var tokens []string
+	var sb strings.Builder
+	var quote rune
+	inToken := false
+	escaped := false
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			sb.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inToken = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				sb.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inToken = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inToken {
+				tokens = append(tokens, sb.String())
+				sb.Reset()
+				inToken = false
+			}
+		default:
+			sb.WriteRune(r)
+			inToken = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+
+	if inToken {
+		tokens = append(tokens, sb.String())
+	}
+
+	return tokens, nil
+}
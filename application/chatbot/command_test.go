@@ -0,0 +1,91 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package chatbot
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"go.wdy.de/nago/application/chatbot/provider"
+)
+
+type deployArgs struct {
+	Service string        `json:"service" desc:"the service to deploy"`
+	Env     string        `json:"env,omitempty"`
+	Force   bool          `json:"force"`
+	Timeout time.Duration `json:"timeout,omitempty"`
+	Note    string        `json:"note,omitempty"`
+}
+
+func TestNewCommand(t *testing.T) {
+	var got deployArgs
+	cmd := NewCommand("deploy", "Deploys a service.", func(sender Sender, evt provider.Event, in deployArgs) (provider.Reply, error) {
+		got = in
+		return provider.Reply{Text: "ok"}, nil
+	})
+
+	if usage := cmd.Usage(); usage != "/deploy <service> [env=<string>] [--force] [timeout=<duration>] [note=<string>]" {
+		t.Fatalf("unexpected usage: %s", usage)
+	}
+
+	tests := []struct {
+		args string
+		want deployArgs
+	}{
+		{"web", deployArgs{Service: "web"}},
+		{"web prod --force", deployArgs{Service: "web", Env: "prod", Force: true}},
+		{`--timeout 90s service=web "release note" with words`, deployArgs{Service: "web", Timeout: 90 * time.Second, Env: "release note", Note: "with words"}},
+		{`web env=st\ age note='say "hi"'`, deployArgs{Service: "web", Env: "st age", Note: `say "hi"`}},
+	}
+
+	for _, tt := range tests {
+		got = deployArgs{}
+		if _, err := cmd.Invoke(Sender{}, provider.Event{}, tt.args); err != nil {
+			t.Fatalf("%s: %v", tt.args, err)
+		}
+
+		if got != tt.want {
+			t.Fatalf("%s: expected %+v but got %+v", tt.args, tt.want, got)
+		}
+	}
+
+	for _, args := range []string{"", "--force", "web --unknown", "web timeout=soon", `web "open`} {
+		_, err := cmd.Invoke(Sender{}, provider.Event{}, args)
+		var usageErr *UsageError
+		if !errors.As(err, &usageErr) {
+			t.Fatalf("%s: expected usage error but got %v", args, err)
+		}
+	}
+}
+
+func TestNewCommandList(t *testing.T) {
+	type args struct {
+		Count int      `json:"count"`
+		Tags  []string `json:"tags,omitempty"`
+	}
+
+	var got args
+	cmd := NewCommand("tag", "", func(sender Sender, evt provider.Event, in args) (provider.Reply, error) {
+		got = in
+		return provider.Reply{}, nil
+	})
+
+	if _, err := cmd.Invoke(Sender{}, provider.Event{}, "3 a b c"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Count != 3 || !slices.Equal(got.Tags, []string{"a", "b", "c"}) {
+		t.Fatalf("unexpected args: %+v", got)
+	}
+
+	if _, err := cmd.Invoke(Sender{}, provider.Event{}, "three"); err == nil {
+		t.Fatal("expected error for invalid integer")
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+// Package chatbothttp receives the messages, slash commands and button clicks of those providers, which
+// implement [provider.Webhook]. The endpoint is public, thus each provider must authenticate the request itself.
+package chatbothttp
+
+import (
+	"log/slog"
+	"net/http"
+
+	"go.wdy.de/nago/application/chatbot"
+	"go.wdy.de/nago/application/chatbot/provider"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/pkg/xhttp"
+)
+
+const Endpoint = "/api/nago/v1/chatbot/webhook"
+
+func NewHandler(findProvider chatbot.FindProviderByID, dispatch chatbot.Dispatch) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "", http.StatusMethodNotAllowed)
+			return
+		}
+
+		id := provider.ID(r.URL.Query().Get("p"))
+		optProv, err := findProvider(user.SU(), id)
+		if err != nil {
+			slog.Error("failed to find chatbot provider", "id", id, "err", err.Error())
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+
+		if optProv.IsNone() {
+			http.Error(w, "", http.StatusNotFound)
+			return
+		}
+
+		webhook, ok := optProv.Unwrap().(provider.Webhook)
+		if !ok {
+			http.Error(w, "", http.StatusNotFound)
+			return
+		}
+
+		evt, err := webhook.ParseWebhook(r)
+		if err != nil {
+			// do not reveal any details to the public
+			slog.Warn("rejected chatbot webhook request", "id", id, "remote", xhttp.RemoteIP(r), "err", err.Error())
+			http.Error(w, "", http.StatusBadRequest)
+			return
+		}
+
+		reply, err := dispatch(chatbot.BotSubject(), id, evt)
+		if err != nil {
+			slog.Error("failed to dispatch chatbot webhook", "id", id, "kind", evt.Kind, "err", err.Error())
+			reply = provider.Reply{Text: "The request failed.", Ephemeral: true}
+		}
+
+		if err := webhook.WriteReply(w, evt, reply); err != nil {
+			slog.Error("failed to write chatbot webhook reply", "id", id, "err", err.Error())
+		}
+	}
+}
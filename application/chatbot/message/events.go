@@ -7,7 +7,10 @@
 
 package message
 
-import "go.wdy.de/nago/application/chatbot/user"
+import (
+	"go.wdy.de/nago/application/chatbot/channel"
+	"go.wdy.de/nago/application/chatbot/user"
+)
 
 type SendRequested struct {
 	// ProviderHint allows to narrow the wanted provider, e.g. for specific originator signatures or credit accounts.
@@ -22,3 +25,18 @@ type SendRequested struct {
 	// Text is the actual message.
 	Text string `json:"body"`
 }
+
+// Received is published for each inbound message, which is neither a command nor a button click. It is
+// issued regardless of an agent answering it.
+type Received struct {
+	// Provider is the id of the provider, which received the message.
+	Provider string     `json:"provider"`
+	Channel  channel.ID `json:"channel"`
+	Message  ID         `json:"message"`
+
+	// RootID is the message, which started the thread, if any.
+	RootID   ID      `json:"rootID,omitempty"`
+	Sender   user.ID `json:"sender"`
+	UserName string  `json:"userName"`
+	Text     string  `json:"text"`
+}
@@ -14,6 +14,24 @@ import (
 
 type CreateOptions struct {
 	Message string
+
+	// RootID answers within the thread of the given message, if not empty.
+	RootID ID
+
+	// Buttons are rendered below the message. A click is dispatched to the action handler registered with
+	// the name [Button.Action].
+	Buttons []Button
+}
+
+// Button is an interactive element of a posted message.
+type Button struct {
+	// Action is the name of the handler, which receives the click.
+	Action string `json:"action"`
+
+	// Value is passed unchanged to the handler, e.g. to identify the entity the button refers to.
+	Value string `json:"value,omitempty"`
+
+	Label string `json:"label"`
 }
 
 type ID string
@@ -10,6 +10,9 @@ package chatbot
 import "go.wdy.de/nago/application/permission"
 
 var (
-	PermReloadProvider = permission.DeclareReloadAll[ReloadProvider]("nago.chatbot.provider.reload", "Chatbot Provider")
-	PermSend           = permission.DeclareSend[Send]("nago.chatbot.provider.send", "Chatbot Message")
+	PermReloadProvider   = permission.DeclareReloadAll[ReloadProvider]("nago.chatbot.provider.reload", "Chatbot Provider")
+	PermSend             = permission.DeclareSend[Send]("nago.chatbot.provider.send", "Chatbot Message")
+	PermFindProviderByID = permission.DeclareFindByID[FindProviderByID]("nago.chatbot.provider.find_provider_by_id", "Chatbot Provider")
+	PermDispatch         = permission.Declare[Dispatch]("nago.chatbot.provider.dispatch", "Chatbot Ereignisse verarbeiten", "Träger dieser Berechtigung können eingehende Nachrichten, Befehle und Button-Klicks eines Chatbot Providers verarbeiten lassen.")
+	PermAgentComplete    = permission.Declare[Dispatch]("nago.chatbot.agent.complete", "Chatbot Agent ausführen", "Träger dieser Berechtigung können freie Nachrichten eines Chatbot Providers durch den KI-Agenten beantworten lassen.")
 )
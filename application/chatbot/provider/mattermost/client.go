@@ -171,13 +171,12 @@ func (c *Client) UsersMe() (User, error) {
 }
 
 type CreatePostRequest struct {
-	ChannelId string   `json:"channel_id"`
-	Message   string   `json:"message"`
-	RootId    string   `json:"root_id,omitempty"`
-	FileIds   []string `json:"file_ids,omitempty"`
-	Props     *struct {
-	} `json:"props,omitempty"`
-	Metadata *struct {
+	ChannelId string         `json:"channel_id"`
+	Message   string         `json:"message"`
+	RootId    string         `json:"root_id,omitempty"`
+	FileIds   []string       `json:"file_ids,omitempty"`
+	Props     map[string]any `json:"props,omitempty"`
+	Metadata  *struct {
 		Priority struct {
 			Priority     string `json:"priority"`
 			RequestedAck bool   `json:"requested_ack"`
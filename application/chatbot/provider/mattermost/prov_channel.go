@@ -25,6 +25,8 @@ func (m *mattermostChannel) Post(subject auth.Subject, opts message.CreateOption
 	resp, err := m.parent.cl.Post(CreatePostRequest{
 		ChannelId: string(m.id),
 		Message:   opts.Message,
+		RootId:    string(opts.RootID),
+		Props:     m.parent.props(m.id, opts.Buttons),
 	})
 
 	if err != nil {
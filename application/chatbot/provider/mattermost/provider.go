@@ -8,6 +8,7 @@
 package mattermost
 
 import (
+	"context"
 	"errors"
 	"iter"
 
@@ -20,14 +21,30 @@ import (
 
 var _ provider.Provider = (*Provider)(nil)
 
+// Provider posts through the REST API. If enabled by the [Settings], it also subscribes to new posts over a
+// websocket in the background and must therefore be closed, if the settings change.
 type Provider struct {
 	id       provider.ID
 	settings Settings
 	cl       *Client
+	notify   provider.Notify
+	cancel   context.CancelFunc
 }
 
-func NewProvider(id provider.ID, settings Settings) *Provider {
-	return &Provider{cl: NewClient(settings), settings: settings, id: id}
+func NewProvider(id provider.ID, settings Settings, notify provider.Notify) *Provider {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Provider{cl: NewClient(settings), settings: settings, id: id, notify: notify, cancel: cancel}
+	if settings.Websocket && notify != nil {
+		go p.run(ctx)
+	}
+
+	return p
+}
+
+// Close stops the websocket subscription.
+func (p *Provider) Close() error {
+	p.cancel()
+	return nil
 }
 
 func (p *Provider) Create(subject auth.Subject, users ...user.ID) (channel.Channel, error) {
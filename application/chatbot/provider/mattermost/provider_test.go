@@ -0,0 +1,223 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package mattermost
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.wdy.de/nago/application/chatbot/message"
+	"go.wdy.de/nago/application/chatbot/provider"
+)
+
+func testSettings() Settings {
+	return Settings{
+		Name:          "mm",
+		Token:         "api-token",
+		RPS:           10,
+		WebhookTokens: "cmd-token hook-token",
+		CallbackURL:   "https://nago.example/api/nago/v1/chatbot/webhook?p=mm",
+	}
+}
+
+func TestProviderParseWebhook(t *testing.T) {
+	prov := NewProvider("mm", testSettings(), nil)
+
+	form := url.Values{"token": {"cmd-token"}, "command": {"/deploy"}, "text": {"web --force"}, "channel_id": {"c1"}, "user_id": {"u1"}, "user_name": {"tom"}}
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	evt, err := prov.ParseWebhook(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if evt.Kind != provider.EventCommand || evt.Command != "deploy" || evt.Text != "web --force" || evt.Channel != "c1" || evt.UserName != "tom" {
+		t.Fatalf("unexpected event: %+v", evt)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"token": "hook-token", "channel_id": "c1", "user_id": "u1", "post_id": "p1", "text": "bot: how is web?", "trigger_word": "bot:"}`))
+	r.Header.Set("Content-Type", "application/json")
+	evt, err = prov.ParseWebhook(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if evt.Kind != provider.EventMessage || evt.Text != "how is web?" || evt.Message != "p1" {
+		t.Fatalf("unexpected event: %+v", evt)
+	}
+
+	form.Set("token", "wrong")
+	r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if _, err := prov.ParseWebhook(r); err == nil {
+		t.Fatal("expected error for invalid token")
+	}
+}
+
+func TestProviderButtons(t *testing.T) {
+	prov := NewProvider("mm", testSettings(), nil)
+
+	w := httptest.NewRecorder()
+	err := prov.WriteReply(w, provider.Event{Kind: provider.EventCommand, Channel: "c1"}, provider.Reply{
+		Text:    "web is up",
+		Buttons: []message.Button{{Action: "restart", Value: "web", Label: "Restart"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var res struct {
+		ResponseType string `json:"response_type"`
+		Text         string `json:"text"`
+		Props        struct {
+			Attachments []struct {
+				Actions []struct {
+					Name        string `json:"name"`
+					Integration struct {
+						URL     string            `json:"url"`
+						Context map[string]string `json:"context"`
+					} `json:"integration"`
+				} `json:"actions"`
+			} `json:"attachments"`
+		} `json:"props"`
+	}
+
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+
+	if res.ResponseType != "in_channel" || res.Text != "web is up" || len(res.Props.Attachments) != 1 || len(res.Props.Attachments[0].Actions) != 1 {
+		t.Fatalf("unexpected response: %s", w.Body.String())
+	}
+
+	action := res.Props.Attachments[0].Actions[0]
+	if action.Name != "Restart" || action.Integration.URL != testSettings().CallbackURL {
+		t.Fatalf("unexpected action: %+v", action)
+	}
+
+	click := func(channelID string, ctx map[string]string) (provider.Event, error) {
+		buf, _ := json.Marshal(actionRequest{UserId: "u1", UserName: "tom", ChannelId: channelID, PostId: "p1", Context: ctx})
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(string(buf)))
+		r.Header.Set("Content-Type", "application/json")
+		return prov.ParseWebhook(r)
+	}
+
+	evt, err := click("c1", action.Integration.Context)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if evt.Kind != provider.EventAction || evt.Action != "restart" || evt.Value != "web" || evt.Message != "p1" {
+		t.Fatalf("unexpected event: %+v", evt)
+	}
+
+	if _, err := click("c2", action.Integration.Context); err == nil {
+		t.Fatal("expected error for button copied into another channel")
+	}
+
+	action.Integration.Context["value"] = "db"
+	if _, err := click("c1", action.Integration.Context); err == nil {
+		t.Fatal("expected error for modified button value")
+	}
+
+	w = httptest.NewRecorder()
+	if err := prov.WriteReply(w, evt, provider.Reply{Text: "restarting"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if body := strings.TrimSpace(w.Body.String()); body != `{"update":{"message":"restarting","props":{"attachments":[]}}}` {
+		t.Fatalf("unexpected response: %s", body)
+	}
+}
+
+func TestProviderWebsocket(t *testing.T) {
+	posted := make(chan CreatePostRequest, 1)
+	upgrader := websocket.Upgrader{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/users/me", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id": "bot", "username": "nago"}`))
+	})
+
+	mux.HandleFunc("/api/v4/posts", func(w http.ResponseWriter, r *http.Request) {
+		var req CreatePostRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		posted <- req
+		_, _ = w.Write([]byte(`{"id": "p3"}`))
+	})
+
+	mux.HandleFunc("/api/v4/websocket", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer api-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+
+		event := func(channelType, mentions string, post wsPost) {
+			buf, _ := json.Marshal(post)
+			var evt wsEvent
+			evt.Event = "posted"
+			evt.Data.ChannelType = channelType
+			evt.Data.Mentions = mentions
+			evt.Data.SenderName = "@tom"
+			evt.Data.Post = string(buf)
+			_ = conn.WriteJSON(evt)
+		}
+
+		// neither direct nor mentioned, thus ignored
+		event("O", "", wsPost{Id: "p1", ChannelId: "town", UserId: "u1", Message: "hello"})
+		event("O", `["bot"]`, wsPost{Id: "p2", ChannelId: "town", UserId: "u1", Message: "@nago how is web?"})
+
+		_, _, _ = conn.ReadMessage()
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	settings := testSettings()
+	settings.URL = srv.URL
+	settings.Websocket = true
+
+	received := make(chan provider.Event, 2)
+	prov := NewProvider("mm", settings, func(evt provider.Event) (provider.Reply, error) {
+		received <- evt
+		return provider.Reply{Text: "web is up"}, nil
+	})
+
+	defer prov.Close()
+
+	select {
+	case evt := <-received:
+		if evt.Message != "p2" || evt.Text != "how is web?" || evt.UserName != "tom" {
+			t.Fatalf("unexpected event: %+v", evt)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	}
+
+	select {
+	case req := <-posted:
+		if req.ChannelId != "town" || req.RootId != "p2" || req.Message != "web is up" {
+			t.Fatalf("unexpected post: %+v", req)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	}
+}
@@ -8,6 +8,9 @@
 package mattermost
 
 import (
+	"crypto/subtle"
+	"strings"
+
 	"github.com/worldiety/enum"
 	"github.com/worldiety/i18n"
 	"go.wdy.de/nago/application/secret"
@@ -21,11 +24,16 @@ var (
 )
 
 type Settings struct {
-	Name  string   `value:"nago.chatbot.mattermost.settings_title" json:"name"`
-	URL   string   `json:"url"`
-	Token string   `json:"token"`
-	RPS   int      `json:"rps"`
-	_     struct{} `credentialName:"nago.chatbot.mattermost.settings_name" credentialDescription:"nago.chatbot.mattermost.settings_desc" credentialLogo:"https://mattermost.worldiety.net/static/images/favicon/favicon-default-32x32.png"`
+	Name  string `value:"nago.chatbot.mattermost.settings_title" json:"name"`
+	URL   string `json:"url"`
+	Token string `json:"token"`
+	RPS   int    `json:"rps"`
+
+	WebhookTokens string `json:"webhookTokens" style:"secret" label:"Webhook Tokens" supportingText:"Die Tokens der ausgehenden Webhooks und Slash-Befehle, durch Leerzeichen getrennt. Mattermost muss diese an <App-URL>/api/nago/v1/chatbot/webhook?p=<Secret-ID> senden."`
+	CallbackURL   string `json:"callbackURL" label:"Callback URL" supportingText:"Die öffentliche Adresse des Webhooks <App-URL>/api/nago/v1/chatbot/webhook?p=<Secret-ID>, an welche Mattermost die Klicks auf Buttons sendet. Ohne Adresse werden keine Buttons angezeigt."`
+	Websocket     bool   `json:"websocket" label:"Nachrichten abonnieren" supportingText:"Empfängt Direktnachrichten und Erwähnungen des Bots über eine dauerhafte Websocket Verbindung."`
+
+	_ struct{} `credentialName:"nago.chatbot.mattermost.settings_name" credentialDescription:"nago.chatbot.mattermost.settings_desc" credentialLogo:"https://mattermost.worldiety.net/static/images/favicon/favicon-default-32x32.png"`
 }
 
 var _ = enum.Variant[secret.Credentials, Settings](enum.Rename[Settings]("nago.chatbot.mattermost.settings"))
@@ -41,3 +49,15 @@ func (s Settings) Credentials() bool {
 func (s Settings) IsZero() bool {
 	return Settings{} == s
 }
+
+// webhookToken returns true, if the token is one of the configured webhook tokens.
+func (s Settings) webhookToken(token string) bool {
+	valid := false
+	for _, t := range strings.Fields(s.WebhookTokens) {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			valid = true
+		}
+	}
+
+	return valid && token != ""
+}
@@ -0,0 +1,209 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package mattermost
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go.wdy.de/nago/application/chatbot/channel"
+	"go.wdy.de/nago/application/chatbot/message"
+	"go.wdy.de/nago/application/chatbot/provider"
+	"go.wdy.de/nago/application/chatbot/user"
+)
+
+var _ provider.Webhook = (*Provider)(nil)
+
+// maxBody limits the size of the public webhook requests.
+const maxBody = 64 * 1024
+
+// actionRequest is posted by Mattermost, if a user clicks an interactive button.
+type actionRequest struct {
+	UserId    string            `json:"user_id"`
+	UserName  string            `json:"user_name"`
+	ChannelId string            `json:"channel_id"`
+	PostId    string            `json:"post_id"`
+	Context   map[string]string `json:"context"`
+}
+
+// ParseWebhook accepts slash commands, outgoing webhooks and the clicks on buttons. Commands and webhooks are
+// authenticated by their token, the buttons by the signature, which has been added to their context.
+func (p *Provider) ParseWebhook(r *http.Request) (provider.Event, error) {
+	if r.Method != http.MethodPost || r.Body == nil {
+		return provider.Event{}, fmt.Errorf("mattermost webhooks must be posted")
+	}
+
+	buf, err := io.ReadAll(io.LimitReader(r.Body, maxBody))
+	if err != nil {
+		return provider.Event{}, err
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	fields := map[string]string{}
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		r.Body = io.NopCloser(bytes.NewReader(buf))
+		if err := r.ParseForm(); err != nil {
+			return provider.Event{}, err
+		}
+
+		for key, values := range r.PostForm {
+			fields[key] = values[0]
+		}
+	case strings.HasSuffix(mediaType, "json"):
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(buf, &obj); err != nil {
+			return provider.Event{}, fmt.Errorf("invalid json webhook body: %w", err)
+		}
+
+		if _, ok := obj["context"]; ok {
+			return p.parseAction(buf)
+		}
+
+		for key, value := range obj {
+			var str string
+			if json.Unmarshal(value, &str) == nil {
+				fields[key] = str
+			}
+		}
+	default:
+		return provider.Event{}, fmt.Errorf("unsupported content type %q", mediaType)
+	}
+
+	if !p.settings.webhookToken(fields["token"]) {
+		return provider.Event{}, fmt.Errorf("invalid webhook token")
+	}
+
+	evt := provider.Event{
+		Channel:  channel.ID(fields["channel_id"]),
+		User:     user.ID(fields["user_id"]),
+		UserName: fields["user_name"],
+	}
+
+	if cmd, ok := fields["command"]; ok {
+		evt.Kind = provider.EventCommand
+		evt.Command = strings.TrimPrefix(cmd, "/")
+		evt.Text = fields["text"]
+		return evt, nil
+	}
+
+	evt.Kind = provider.EventMessage
+	evt.Message = message.ID(fields["post_id"])
+	evt.Text = strings.TrimSpace(strings.TrimPrefix(fields["text"], fields["trigger_word"]))
+	return evt, nil
+}
+
+func (p *Provider) parseAction(buf []byte) (provider.Event, error) {
+	var req actionRequest
+	if err := json.Unmarshal(buf, &req); err != nil {
+		return provider.Event{}, fmt.Errorf("invalid action request: %w", err)
+	}
+
+	action := req.Context["action"]
+	value := req.Context["value"]
+	if !hmac.Equal([]byte(req.Context["sig"]), []byte(p.sign(req.ChannelId, action, value))) {
+		return provider.Event{}, fmt.Errorf("invalid action signature")
+	}
+
+	return provider.Event{
+		Kind:     provider.EventAction,
+		Channel:  channel.ID(req.ChannelId),
+		User:     user.ID(req.UserId),
+		UserName: req.UserName,
+		Message:  message.ID(req.PostId),
+		Action:   action,
+		Value:    value,
+	}, nil
+}
+
+// WriteReply answers a command with an ephemeral or in-channel response and a webhook with a post. The
+// reply to a button click either shows an ephemeral text or replaces the message, which contained the
+// button.
+func (p *Provider) WriteReply(w http.ResponseWriter, evt provider.Event, reply provider.Reply) error {
+	res := map[string]any{}
+	if !reply.IsZero() {
+		switch evt.Kind {
+		case provider.EventCommand:
+			res["response_type"] = "in_channel"
+			if reply.Ephemeral {
+				res["response_type"] = "ephemeral"
+			}
+
+			res["text"] = reply.Text
+			if props := p.props(evt.Channel, reply.Buttons); props != nil {
+				res["props"] = props
+			}
+		case provider.EventAction:
+			if reply.Ephemeral {
+				res["ephemeral_text"] = reply.Text
+				break
+			}
+
+			// without any attachments, mattermost would keep the clicked buttons
+			props := p.props(evt.Channel, reply.Buttons)
+			if props == nil {
+				props = map[string]any{"attachments": []any{}}
+			}
+
+			res["update"] = map[string]any{"message": reply.Text, "props": props}
+		default:
+			res["text"] = reply.Text
+			if props := p.props(evt.Channel, reply.Buttons); props != nil {
+				res["props"] = props
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(res)
+}
+
+// props renders the buttons as message attachment, whose actions post to the callback url. Without such url,
+// buttons cannot be clicked and are omitted.
+func (p *Provider) props(ch channel.ID, buttons []message.Button) map[string]any {
+	if len(buttons) == 0 || p.settings.CallbackURL == "" {
+		return nil
+	}
+
+	var actions []map[string]any
+	for i, btn := range buttons {
+		actions = append(actions, map[string]any{
+			// mattermost only accepts alphanumeric ids
+			"id":   "a" + strconv.Itoa(i),
+			"name": btn.Label,
+			"type": "button",
+			"integration": map[string]any{
+				"url": p.settings.CallbackURL,
+				"context": map[string]string{
+					"action": btn.Action,
+					"value":  btn.Value,
+					"sig":    p.sign(string(ch), btn.Action, btn.Value),
+				},
+			},
+		})
+	}
+
+	return map[string]any{"attachments": []any{map[string]any{"actions": actions}}}
+}
+
+// sign protects the context of a button, which is visible to all channel members, from being changed or
+// copied into another channel.
+func (p *Provider) sign(ch, action, value string) string {
+	mac := hmac.New(sha256.New, []byte(p.settings.Token))
+	mac.Write([]byte(ch + "\x00" + action + "\x00" + value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,194 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package mattermost
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.wdy.de/nago/application/chatbot/channel"
+	"go.wdy.de/nago/application/chatbot/message"
+	"go.wdy.de/nago/application/chatbot/provider"
+	"go.wdy.de/nago/application/chatbot/user"
+	nagouser "go.wdy.de/nago/application/user"
+)
+
+// reconnectDelay is the pause after a failed or lost websocket connection.
+const reconnectDelay = 30 * time.Second
+
+type wsEvent struct {
+	Event string `json:"event"`
+	Data  struct {
+		ChannelType string `json:"channel_type"`
+		Mentions    string `json:"mentions"`
+		SenderName  string `json:"sender_name"`
+		Post        string `json:"post"`
+	} `json:"data"`
+}
+
+type wsPost struct {
+	Id        string `json:"id"`
+	ChannelId string `json:"channel_id"`
+	UserId    string `json:"user_id"`
+	RootId    string `json:"root_id"`
+	Message   string `json:"message"`
+	Type      string `json:"type"`
+}
+
+func (p *Provider) run(ctx context.Context) {
+	for ctx.Err() == nil {
+		if err := p.listen(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("mattermost websocket failed", "provider", p.id, "err", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+// listen receives the posts of all channels, in which the bot is a member, until the connection is lost.
+// Only direct messages and mentions of the bot are dispatched.
+func (p *Provider) listen(ctx context.Context) error {
+	me, err := p.cl.UsersMe()
+	if err != nil {
+		return fmt.Errorf("cannot get bot user: %w", err)
+	}
+
+	wsURL, err := websocketURL(p.settings.URL)
+	if err != nil {
+		return err
+	}
+
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, wsURL, http.Header{"Authorization": {"Bearer " + p.settings.Token}})
+	if err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.Close()
+		case <-done:
+			_ = conn.Close()
+		}
+	}()
+
+	for {
+		var evt wsEvent
+		if err := conn.ReadJSON(&evt); err != nil {
+			return err
+		}
+
+		if evt.Event != "posted" {
+			continue
+		}
+
+		var post wsPost
+		if err := json.Unmarshal([]byte(evt.Data.Post), &post); err != nil {
+			slog.Error("invalid mattermost post event", "provider", p.id, "err", err.Error())
+			continue
+		}
+
+		if post.UserId == me.Id || post.Type != "" {
+			continue
+		}
+
+		direct := evt.Data.ChannelType == "D"
+		if !direct && !mentioned(evt.Data.Mentions, me.Id) {
+			continue
+		}
+
+		// an agent may take a while and must not block the connection
+		go p.handlePost(post, strings.TrimPrefix(evt.Data.SenderName, "@"), me.Username, direct)
+	}
+}
+
+func (p *Provider) handlePost(post wsPost, sender, botName string, direct bool) {
+	evt := provider.Event{
+		Kind:     provider.EventMessage,
+		Channel:  channel.ID(post.ChannelId),
+		User:     user.ID(post.UserId),
+		UserName: sender,
+		Message:  message.ID(post.Id),
+		RootID:   message.ID(post.RootId),
+		Text:     strings.TrimSpace(strings.ReplaceAll(post.Message, "@"+botName, "")),
+	}
+
+	reply, err := p.notify(evt)
+	if err != nil {
+		slog.Error("failed to dispatch mattermost post", "provider", p.id, "post", post.Id, "err", err.Error())
+		return
+	}
+
+	if reply.IsZero() {
+		return
+	}
+
+	// answer mentions within a thread, to avoid cluttering the channel
+	rootID := evt.RootID
+	if rootID == "" && !direct {
+		rootID = evt.Message
+	}
+
+	_, err = p.Channel(evt.Channel).Post(nagouser.SU(), message.CreateOptions{
+		Message: reply.Text,
+		RootID:  rootID,
+		Buttons: reply.Buttons,
+	})
+
+	if err != nil {
+		slog.Error("failed to post mattermost reply", "provider", p.id, "post", post.Id, "err", err.Error())
+	}
+}
+
+// mentioned parses the JSON encoded list of mentioned user ids.
+func mentioned(mentions string, id string) bool {
+	if mentions == "" {
+		return false
+	}
+
+	var ids []string
+	if err := json.Unmarshal([]byte(mentions), &ids); err != nil {
+		return false
+	}
+
+	return slices.Contains(ids, id)
+}
+
+func websocketURL(base string) (string, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("invalid mattermost url: %w", err)
+	}
+
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	case "http":
+		u.Scheme = "ws"
+	default:
+		return "", fmt.Errorf("invalid mattermost url scheme %q", u.Scheme)
+	}
+
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/api/v4/websocket"
+	return u.String(), nil
+}
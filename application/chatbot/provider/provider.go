@@ -9,6 +9,7 @@ package provider
 
 import (
 	"iter"
+	"net/http"
 
 	"github.com/worldiety/option"
 	"go.wdy.de/nago/application/chatbot/channel"
@@ -40,3 +41,68 @@ type Users interface {
 	All(subject auth.Subject) iter.Seq2[user.User, error]
 	FindByEmail(subject auth.Subject, mail user.Email) (option.Opt[user.User], error)
 }
+
+// EventKind distinguishes the inbound interactions of a chat user.
+type EventKind string
+
+const (
+	// EventMessage is a plain message, e.g. in a direct channel with the bot or mentioning it.
+	EventMessage EventKind = "message"
+
+	// EventCommand is a slash command like /deploy web --force.
+	EventCommand EventKind = "command"
+
+	// EventAction is a click on a [message.Button].
+	EventAction EventKind = "action"
+)
+
+// Event is an inbound interaction, which a provider has received through a webhook or a subscription.
+type Event struct {
+	Kind     EventKind
+	Channel  channel.ID
+	User     user.ID
+	UserName string
+
+	// Message is the received message or, for an action, the message which contains the button.
+	Message message.ID
+
+	// RootID is the message, which started the thread, if any.
+	RootID message.ID
+
+	// Text is the message or the unparsed arguments of a command.
+	Text string
+
+	// Command is the name of the slash command without the leading slash.
+	Command string
+
+	// Action and Value are taken from the clicked [message.Button].
+	Action string
+	Value  string
+}
+
+// Reply is the answer to an [Event]. The zero value does not answer at all.
+type Reply struct {
+	Text string
+
+	// Ephemeral replies are only shown to the user who caused the event, if supported by the provider.
+	Ephemeral bool
+
+	Buttons []message.Button
+}
+
+func (r Reply) IsZero() bool {
+	return r.Text == "" && len(r.Buttons) == 0
+}
+
+// Notify is passed to providers, which receive events asynchronously, e.g. over a websocket subscription.
+// The provider must post a non-zero [Reply] into the channel of the event by itself.
+type Notify func(evt Event) (Reply, error)
+
+// Webhook is optionally implemented by a [Provider], whose platform posts messages, slash commands or button
+// clicks to our public webhook endpoint. The provider must authenticate the request itself.
+type Webhook interface {
+	ParseWebhook(r *http.Request) (Event, error)
+
+	// WriteReply answers the webhook request in the format expected by the platform.
+	WriteReply(w http.ResponseWriter, evt Event, reply Reply) error
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package chatbot
+
+import (
+	"iter"
+	"slices"
+
+	"go.wdy.de/nago/application/group"
+	"go.wdy.de/nago/application/permission"
+	"go.wdy.de/nago/application/rebac"
+	"go.wdy.de/nago/application/role"
+	"go.wdy.de/nago/application/user"
+)
+
+var _ user.Subject = botSubject{}
+
+// botSubject is the identity under which inbound events are processed. Inbound events are caused by
+// arbitrary accounts of the chat provider, thus the bot only holds the permissions to dispatch them and to
+// let its agent answer. Everything else must be decided by the handlers based on the [Sender].
+type botSubject struct {
+	user.Subject
+}
+
+// BotSubject returns the subject to use with [Dispatch] for events received from a provider.
+func BotSubject() user.Subject {
+	return botSubject{Subject: user.SU()}
+}
+
+func (s botSubject) granted(p permission.ID) bool {
+	return p == PermDispatch || p == PermAgentComplete
+}
+
+func (s botSubject) Name() string {
+	return "Chatbot"
+}
+
+func (s botSubject) Email() string {
+	return ""
+}
+
+func (s botSubject) Permissions() iter.Seq[permission.ID] {
+	return slices.Values([]permission.ID{PermDispatch, PermAgentComplete})
+}
+
+func (s botSubject) HasPermission(p permission.ID) bool {
+	return s.granted(p)
+}
+
+func (s botSubject) Audit(p permission.ID) error {
+	if !s.granted(p) {
+		return s.deny(permission.Decision{Permission: p})
+	}
+
+	return nil
+}
+
+func (s botSubject) HasResourcePermission(name rebac.Namespace, id rebac.Instance, p permission.ID) bool {
+	return s.granted(p)
+}
+
+func (s botSubject) AuditResource(name rebac.Namespace, id rebac.Instance, p permission.ID) error {
+	if !s.granted(p) {
+		return s.deny(permission.Decision{Permission: p, Namespace: string(name), Instance: string(id)})
+	}
+
+	return nil
+}
+
+func (s botSubject) deny(d permission.Decision) error {
+	var name = string(d.Permission)
+	if p, ok := permission.Find(d.Permission); ok {
+		name = p.Name
+	}
+
+	d.Subject = s.Name()
+	return permission.Report(d, user.PermissionDeniedError(name))
+}
+
+func (s botSubject) Roles() iter.Seq[role.ID] {
+	return func(yield func(role.ID) bool) {}
+}
+
+func (s botSubject) HasRole(id role.ID) bool {
+	return false
+}
+
+func (s botSubject) Groups() iter.Seq[group.ID] {
+	return func(yield func(group.ID) bool) {}
+}
+
+func (s botSubject) HasGroup(id group.ID) bool {
+	return false
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package chatbot
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.wdy.de/nago/application/ai/completion"
+	"go.wdy.de/nago/application/chatbot/message"
+	"go.wdy.de/nago/application/chatbot/provider"
+	"go.wdy.de/nago/auth"
+	"go.wdy.de/nago/pkg/events"
+)
+
+func NewDispatch(bus events.Bus, bot *Bot) Dispatch {
+	return func(subject auth.Subject, id provider.ID, evt provider.Event) (provider.Reply, error) {
+		if err := subject.Audit(PermDispatch); err != nil {
+			return provider.Reply{}, err
+		}
+
+		sender := newSender(id, evt)
+		switch evt.Kind {
+		case provider.EventCommand:
+			return dispatchCommand(bot, sender, evt)
+		case provider.EventAction:
+			fn, ok := bot.action(evt.Action)
+			if !ok {
+				return provider.Reply{}, fmt.Errorf("no handler for chatbot action %q", evt.Action)
+			}
+
+			return fn(sender, evt)
+		case provider.EventMessage:
+			if strings.TrimSpace(evt.Text) == "" {
+				return provider.Reply{}, nil
+			}
+
+			bus.Publish(message.Received{
+				Provider: string(id),
+				Channel:  evt.Channel,
+				Message:  evt.Message,
+				RootID:   evt.RootID,
+				Sender:   evt.User,
+				UserName: evt.UserName,
+				Text:     evt.Text,
+			})
+
+			optAgent := bot.currentAgent()
+			if optAgent.IsNone() {
+				return provider.Reply{}, nil
+			}
+
+			return runAgent(subject, optAgent.Unwrap(), sender, evt)
+		default:
+			return provider.Reply{}, fmt.Errorf("unknown chatbot event kind %q", evt.Kind)
+		}
+	}
+}
+
+func dispatchCommand(bot *Bot, sender Sender, evt provider.Event) (provider.Reply, error) {
+	cmd, ok := bot.command(evt.Command)
+	if !ok {
+		if strings.EqualFold(evt.Command, "help") {
+			return provider.Reply{Text: help(bot), Ephemeral: true}, nil
+		}
+
+		return provider.Reply{Text: fmt.Sprintf("Unknown command /%s, see /help.", evt.Command), Ephemeral: true}, nil
+	}
+
+	reply, err := cmd.Invoke(sender, evt, evt.Text)
+	if usageErr := (*UsageError)(nil); errors.As(err, &usageErr) {
+		return provider.Reply{Text: usageErr.Err.Error() + "\n\n" + cmd.Help(), Ephemeral: true}, nil
+	}
+
+	return reply, err
+}
+
+func help(bot *Bot) string {
+	var sb strings.Builder
+	sb.WriteString("Available commands:")
+	for _, cmd := range bot.Commands() {
+		sb.WriteString("\n- ")
+		sb.WriteString(cmd.Usage())
+		if cmd.Description != "" {
+			sb.WriteString(": ")
+			sb.WriteString(cmd.Description)
+		}
+	}
+
+	return sb.String()
+}
+
+func runAgent(subject auth.Subject, agent Agent, sender Sender, evt provider.Event) (provider.Reply, error) {
+	if err := subject.Audit(PermAgentComplete); err != nil {
+		return provider.Reply{}, err
+	}
+
+	if agent.Completions == nil {
+		return provider.Reply{}, fmt.Errorf("chatbot agent has no completions")
+	}
+
+	var tools []completion.Tool
+	if agent.Tools != nil {
+		tools = agent.Tools(sender)
+	}
+
+	res, _, err := completion.Run(subject, agent.Completions, completion.RunOptions{
+		Options: completion.Options{
+			Model:     agent.Model,
+			System:    agent.System,
+			MaxTokens: agent.MaxTokens,
+			Messages: []completion.Message{{
+				Role:    completion.User,
+				Content: []completion.Content{completion.Text{Text: evt.Text}},
+			}},
+		},
+		Tools:    tools,
+		MaxTurns: agent.MaxTurns,
+	})
+
+	if err != nil {
+		return provider.Reply{}, fmt.Errorf("chatbot agent failed: %w", err)
+	}
+
+	var sb strings.Builder
+	for _, content := range res.Message.Content {
+		if text, ok := content.(completion.Text); ok {
+			if sb.Len() > 0 {
+				sb.WriteString("\n\n")
+			}
+
+			sb.WriteString(text.Text)
+		}
+	}
+
+	return provider.Reply{Text: sb.String()}, nil
+}
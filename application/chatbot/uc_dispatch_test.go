@@ -0,0 +1,154 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package chatbot
+
+import (
+	"encoding/json"
+	"iter"
+	"strings"
+	"testing"
+
+	"go.wdy.de/nago/application/ai/completion"
+	"go.wdy.de/nago/application/ai/model"
+	"go.wdy.de/nago/application/chatbot/message"
+	"go.wdy.de/nago/application/chatbot/provider"
+	"go.wdy.de/nago/application/group"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/auth"
+	"go.wdy.de/nago/pkg/events/eventstest"
+)
+
+// fakeCompletions calls the first tool once and answers with the tool result afterwards.
+type fakeCompletions struct{}
+
+func (f *fakeCompletions) Models(subject auth.Subject) iter.Seq2[model.Model, error] {
+	return func(yield func(model.Model, error) bool) {}
+}
+
+func (f *fakeCompletions) Complete(subject auth.Subject, opts completion.Options) (completion.Result, error) {
+	last := opts.Messages[len(opts.Messages)-1]
+	for _, content := range last.Content {
+		if res, ok := content.(completion.ToolResult); ok {
+			return completion.Result{
+				Message:    completion.Message{Role: completion.Assistant, Content: []completion.Content{completion.Text{Text: "Status: " + textOf(res.Content)}}},
+				StopReason: completion.StopEndTurn,
+			}, nil
+		}
+	}
+
+	return completion.Result{
+		Message: completion.Message{Role: completion.Assistant, Content: []completion.Content{completion.ToolCall{
+			ID:        "call-1",
+			Name:      opts.Tools[0].Name,
+			Arguments: json.RawMessage(`{"service":"web"}`),
+		}}},
+		StopReason: completion.StopToolUse,
+	}, nil
+}
+
+func (f *fakeCompletions) Stream(subject auth.Subject, opts completion.Options) iter.Seq2[completion.Delta, error] {
+	return func(yield func(completion.Delta, error) bool) {}
+}
+
+func textOf(contents []completion.Content) string {
+	var sb strings.Builder
+	for _, c := range contents {
+		if text, ok := c.(completion.Text); ok {
+			sb.WriteString(text.Text)
+		}
+	}
+
+	return sb.String()
+}
+
+func TestDispatch(t *testing.T) {
+	type statusArgs struct {
+		Service string `json:"service"`
+	}
+
+	bot := NewBot()
+	bot.Command(NewCommand("status", "Shows the status.", func(sender Sender, evt provider.Event, in statusArgs) (provider.Reply, error) {
+		return provider.Reply{Text: in.Service + " is up", Buttons: []message.Button{{Action: "restart", Value: in.Service, Label: "Restart"}}}, nil
+	}))
+
+	bot.Action("restart", func(sender Sender, evt provider.Event) (provider.Reply, error) {
+		return provider.Reply{Text: "restarting " + evt.Value + " for " + sender.UserName + "@" + string(sender.Provider)}, nil
+	})
+
+	bus := &eventstest.Recorder{}
+	dispatch := NewDispatch(bus, bot)
+
+	reply, err := dispatch(BotSubject(), "mm", provider.Event{Kind: provider.EventCommand, Command: "status", Text: "web"})
+	if err != nil || reply.Text != "web is up" || len(reply.Buttons) != 1 {
+		t.Fatalf("unexpected reply: %+v %v", reply, err)
+	}
+
+	reply, err = dispatch(BotSubject(), "mm", provider.Event{Kind: provider.EventCommand, Command: "status"})
+	if err != nil || !reply.Ephemeral || !strings.Contains(reply.Text, "missing argument service") {
+		t.Fatalf("unexpected reply: %+v %v", reply, err)
+	}
+
+	reply, err = dispatch(BotSubject(), "mm", provider.Event{Kind: provider.EventCommand, Command: "help"})
+	if err != nil || !strings.Contains(reply.Text, "/status <service>: Shows the status.") {
+		t.Fatalf("unexpected reply: %+v %v", reply, err)
+	}
+
+	reply, err = dispatch(BotSubject(), "mm", provider.Event{Kind: provider.EventAction, Action: "restart", Value: "web", UserName: "tom"})
+	if err != nil || reply.Text != "restarting web for tom@mm" {
+		t.Fatalf("unexpected reply: %+v %v", reply, err)
+	}
+
+	if _, err := dispatch(BotSubject(), "mm", provider.Event{Kind: provider.EventAction, Action: "unknown"}); err == nil {
+		t.Fatal("expected error for unknown action")
+	}
+
+	// without an agent, messages are only published
+	reply, err = dispatch(BotSubject(), "mm", provider.Event{Kind: provider.EventMessage, Channel: "c", User: "u", Text: "hello"})
+	if err != nil || !reply.IsZero() {
+		t.Fatalf("unexpected reply: %+v %v", reply, err)
+	}
+
+	evts := bus.Events()
+	if len(evts) != 1 || evts[0].(message.Received).Text != "hello" || evts[0].(message.Received).Provider != "mm" {
+		t.Fatalf("unexpected events: %+v", evts)
+	}
+
+	bot.Agent(Agent{
+		Completions: &fakeCompletions{},
+		Model:       "fake-model",
+		Tools: func(sender Sender) []completion.Tool {
+			return []completion.Tool{completion.NewTool("status", "Returns the status of a service.", func(in statusArgs) (string, error) {
+				return in.Service + " is up for " + sender.UserName, nil
+			})}
+		},
+	})
+
+	reply, err = dispatch(BotSubject(), "mm", provider.Event{Kind: provider.EventMessage, UserName: "tom", Text: "how is web?"})
+	if err != nil || reply.Text != `Status: "web is up for tom"` {
+		t.Fatalf("unexpected reply: %+v %v", reply, err)
+	}
+}
+
+func TestBotSubject(t *testing.T) {
+	subject := BotSubject()
+	if err := subject.Audit(PermDispatch); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := subject.Audit(PermAgentComplete); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := subject.Audit(PermSend); err == nil {
+		t.Fatal("expected the bot to be denied anything else")
+	}
+
+	if subject.HasGroup(group.System) || user.IsSU(subject) {
+		t.Fatal("the bot must not be treated as the system user")
+	}
+}
@@ -8,6 +8,7 @@
 package chatbot
 
 import (
+	"io"
 	"log/slog"
 
 	"go.wdy.de/nago/application/chatbot/provider"
@@ -19,12 +20,21 @@ import (
 	"go.wdy.de/nago/pkg/std/concurrent"
 )
 
-func NewReloadProvider(m *concurrent.RWMap[provider.ID, provider.Provider], findSecrets secret.FindGroupSecrets) ReloadProvider {
+func NewReloadProvider(m *concurrent.RWMap[provider.ID, provider.Provider], findSecrets secret.FindGroupSecrets, dispatch Dispatch) ReloadProvider {
 	return func(subject auth.Subject, opts ReloadProviderOptions) error {
 		if err := subject.Audit(PermReloadProvider); err != nil {
 			return err
 		}
 
+		// providers with a websocket subscription keep a connection, which must not leak
+		for id, prov := range m.All() {
+			if closer, ok := prov.(io.Closer); ok {
+				if err := closer.Close(); err != nil {
+					slog.Error("failed to close chatbot provider", "id", id, "err", err.Error())
+				}
+			}
+		}
+
 		m.Clear()
 
 		for sec, err := range findSecrets(user.SU(), group.System) {
@@ -33,10 +43,15 @@ func NewReloadProvider(m *concurrent.RWMap[provider.ID, provider.Provider], find
 				continue
 			}
 
+			id := provider.ID(sec.ID)
+			notify := func(evt provider.Event) (provider.Reply, error) {
+				return dispatch(BotSubject(), id, evt)
+			}
+
 			var prov provider.Provider
 			switch cfg := sec.Credentials.(type) {
 			case mattermost.Settings:
-				prov = mattermost.NewProvider(provider.ID(sec.ID), cfg)
+				prov = mattermost.NewProvider(id, cfg, notify)
 			}
 
 			if prov == nil {
@@ -11,6 +11,7 @@ import (
 	"context"
 	"log/slog"
 
+	"github.com/worldiety/option"
 	"go.wdy.de/nago/application/chatbot/message"
 	"go.wdy.de/nago/application/chatbot/provider"
 	"go.wdy.de/nago/application/secret"
@@ -28,14 +29,24 @@ type SendOptions struct {
 }
 type Send func(subject auth.Subject, post message.SendRequested, opts SendOptions) (message.ID, error)
 
+// FindProviderByID returns the provider, which has been created from the secret with the given id.
+type FindProviderByID func(subject auth.Subject, id provider.ID) (option.Opt[provider.Provider], error)
+
+// Dispatch routes an inbound event of the given provider to the commands, actions or the agent of the [Bot]
+// and returns the reply. Plain messages are published as [message.Received].
+type Dispatch func(subject auth.Subject, id provider.ID, evt provider.Event) (provider.Reply, error)
+
 type UseCases struct {
-	ReloadProvider ReloadProvider
-	Send           Send
+	ReloadProvider   ReloadProvider
+	Send             Send
+	FindProviderByID FindProviderByID
+	Dispatch         Dispatch
 }
 
-func NewUseCases(ctx context.Context, bus events.Bus, findSecrets secret.FindGroupSecrets) UseCases {
+func NewUseCases(ctx context.Context, bus events.Bus, findSecrets secret.FindGroupSecrets, bot *Bot) UseCases {
 	var providers concurrent.RWMap[provider.ID, provider.Provider]
-	fnReload := NewReloadProvider(&providers, findSecrets)
+	dispatchFn := NewDispatch(bus, bot)
+	fnReload := NewReloadProvider(&providers, findSecrets, dispatchFn)
 
 	fnInvokeReload := func() {
 		if err := fnReload(user2.SU(), ReloadProviderOptions{}); err != nil {
@@ -68,7 +79,9 @@ func NewUseCases(ctx context.Context, bus events.Bus, findSecrets secret.FindGro
 	})
 
 	return UseCases{
-		ReloadProvider: fnReload,
-		Send:           sendFn,
+		ReloadProvider:   fnReload,
+		Send:             sendFn,
+		FindProviderByID: NewFindProviderByID(&providers),
+		Dispatch:         dispatchFn,
 	}
 }
@@ -27,7 +27,8 @@ import (
 // sequence is bootstrapped once by reading the highest existing key in reverse.
 // It keeps its own discriminator↔type registry (populated via Register).
 type blobBackend[E Evt[A], A any] struct {
-	store blob.Store
+	store     blob.Store
+	upcasters *Upcasters
 
 	byType        concurrent.RWMap[reflect.Type, Discriminator]
 	byDiscrimentr concurrent.RWMap[Discriminator, reflect.Type]
@@ -49,8 +50,9 @@ type RegisterableBackend[E any] interface {
 // NewBlobBackend returns a [Backend] persisting events into eventStore as JSON
 // envelopes. It performs no permission checks of its own; the handler drives it
 // as the super user.
-func NewBlobBackend[E Evt[A], A any](eventStore blob.Store) RegisterableBackend[E] {
-	return &blobBackend[E, A]{store: eventStore}
+func NewBlobBackend[E Evt[A], A any](eventStore blob.Store, opts ...BackendOption) RegisterableBackend[E] {
+	o := newBackendOptions(opts)
+	return &blobBackend[E, A]{store: eventStore, upcasters: o.upcasters}
 }
 
 // Register associates a Go type with a discriminator for (de)serialization.
@@ -118,10 +120,15 @@ func (b *blobBackend[E, A]) Append(subject auth.Subject, e E) (Envelope[E], erro
 	// blob has no native append time, so we record it ourselves.
 	eventTime := xtime.Now()
 
+	data, err := b.upcasters.stamp(discriminator, payloadBuf)
+	if err != nil {
+		return zero, err
+	}
+
 	env := JsonEnvelope{
 		Discriminator: discriminator,
 		EventTime:     eventTime,
-		Data:          payloadBuf,
+		Data:          data,
 	}
 
 	buf, err := json.Marshal(env)
@@ -193,7 +200,7 @@ func (b *blobBackend[E, A]) load(id SeqID, key SeqKey) (Envelope[E], error) {
 		return zero, err
 	}
 
-	payload, err := jsonEnv.decodeData(&b.byDiscrimentr)
+	payload, data, err := jsonEnv.decodeData(&b.byDiscrimentr, b.upcasters)
 	if err != nil {
 		return zero, err
 	}
@@ -209,6 +216,6 @@ func (b *blobBackend[E, A]) load(id SeqID, key SeqKey) (Envelope[E], error) {
 		Discriminator: jsonEnv.Discriminator,
 		EventTime:     jsonEnv.EventTime,
 		Data:          evt,
-		Raw:           jsonEnv.Data,
+		Raw:           data,
 	}, nil
 }
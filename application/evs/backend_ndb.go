@@ -30,7 +30,8 @@ import (
 // the engine schema-free. The envelope's read-side EventTime is derived from the
 // engine's native append time (msg.TimeNano).
 type ndbBackend[E Evt[A], A any] struct {
-	msgs ndb.Messages
+	msgs      ndb.Messages
+	upcasters *Upcasters
 
 	byType        concurrent.RWMap[reflect.Type, Discriminator]
 	byDiscrimentr concurrent.RWMap[Discriminator, reflect.Type]
@@ -41,11 +42,17 @@ var _ RegisterableBackend[Evt[any]] = (*ndbBackend[Evt[any], any])(nil)
 // NewNDBBackend returns a [Backend] persisting events into an [ndb.Messages]
 // engine. It performs no permission checks of its own; the handler drives it as
 // the super user.
-func NewNDBBackend[E Evt[A], A any](msgs ndb.Messages) RegisterableBackend[E] {
-	return &ndbBackend[E, A]{msgs: msgs}
+func NewNDBBackend[E Evt[A], A any](msgs ndb.Messages, opts ...BackendOption) RegisterableBackend[E] {
+	o := newBackendOptions(opts)
+	return &ndbBackend[E, A]{msgs: msgs, upcasters: o.upcasters}
 }
 
-// Register associates a Go type with a discriminator for (de)serialization.
+// Register associates a Go type with a discriminator for (de)serialization. If
+// the engine keeps a schema registry (see [ndb.Schemas]), the JSON schema of t
+// is registered as well and an incompatible change to a stored type is
+// rejected with an [*ndb.IncompatibleSchemaError]. A change covered by a newly
+// registered upcaster (see [WithUpcasters]) is accepted, because stored events
+// are migrated before they are decoded.
 func (b *ndbBackend[E, A]) Register(t reflect.Type, d Discriminator) error {
 	if err := d.Validate(); err != nil {
 		return err
@@ -62,11 +69,32 @@ func (b *ndbBackend[E, A]) Register(t reflect.Type, d Discriminator) error {
 	if existing, ok := b.byDiscrimentr.Get(d); ok && existing != t {
 		return fmt.Errorf("discriminator %q already registered for %v", d, existing)
 	}
+	if schemas, ok := b.msgs.(ndb.Schemas); ok {
+		// A renamed field or a changed field type would otherwise silently
+		// break the replay of every stored event, so refuse it at startup.
+		if _, err := ndb.RegisterSchema(schemas, ndb.TypeID(d), b.schemaOf(t, d)); err != nil {
+			return fmt.Errorf("cannot register schema of %v: %w", t, err)
+		}
+	}
 	b.byType.Put(t, d)
 	b.byDiscrimentr.Put(d, t)
 	return nil
 }
 
+// schemaOf returns the schema of the stored encoding of t, which carries the
+// version property once upcasters exist for d. The upcaster version doubles as
+// the schema revision.
+func (b *ndbBackend[E, A]) schemaOf(t reflect.Type, d Discriminator) *ndb.Schema {
+	schema := ndb.SchemaOf(t)
+	version := b.upcasters.Version(d)
+	if version > 1 && schema.Type == ndb.SchemaObject && len(schema.Properties) > 0 {
+		schema.Revision = version
+		schema.Properties[VersionKey] = &ndb.Schema{Type: ndb.SchemaInteger}
+	}
+
+	return schema
+}
+
 func (b *ndbBackend[E, A]) Append(subject auth.Subject, e E) (Envelope[E], error) {
 	var zero Envelope[E]
 
@@ -80,7 +108,12 @@ func (b *ndbBackend[E, A]) Append(subject auth.Subject, e E) (Envelope[E], error
 		return zero, fmt.Errorf("event %T cannot be marshalled: %w", e, err)
 	}
 
-	seq, err := b.msgs.Append(ndb.TypeID(discriminator), ndb.NewTraceID(), dataBuf)
+	stamped, err := b.upcasters.stamp(discriminator, dataBuf)
+	if err != nil {
+		return zero, err
+	}
+
+	seq, err := b.msgs.Append(ndb.TypeID(discriminator), ndb.NewTraceID(), stamped)
 	if err != nil {
 		return zero, fmt.Errorf("error appending event: %w", err)
 	}
@@ -153,6 +186,13 @@ func (b *ndbBackend[E, A]) decode(discriminator Discriminator, msg ndb.Message)
 		return zero, fmt.Errorf("unknown discriminator %q", discriminator)
 	}
 
+	// Upcasting returns either a new buffer or the payload itself, so the
+	// clone below still applies.
+	payload, err = b.upcasters.Upcast(discriminator, payload)
+	if err != nil {
+		return zero, err
+	}
+
 	rval := reflect.New(rtype)
 	if err := json.Unmarshal(payload, rval.Interface()); err != nil {
 		return zero, err
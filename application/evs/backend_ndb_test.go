@@ -8,8 +8,14 @@
 package evs_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/worldiety/option"
 	"go.wdy.de/nago/application/evs"
@@ -211,3 +217,114 @@ func TestHandlerNDBS2Compression(t *testing.T) {
 		t.Fatal("replay rebuild over S2 store failed")
 	}
 }
+
+// lastnameRenamed is LastnameUpdated after an incompatible refactor: the json
+// name of the field changed, so every stored event would lose its value.
+type lastnameRenamed struct {
+	Person   PID    `json:"person"`
+	Lastname string `json:"lastname"`
+}
+
+func TestNDBBackendRejectsIncompatibleSchema(t *testing.T) {
+	msgs := openNDBMessages(t)
+
+	backend := evs.NewNDBBackend[Evt, *Person](msgs)
+	if err := backend.Register(reflect.TypeFor[LastnameUpdated](), "LastnameUpdated"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	// a restarted process with the refactored type must refuse to start
+	backend2 := evs.NewNDBBackend[Evt, *Person](msgs)
+	err := backend2.Register(reflect.TypeFor[lastnameRenamed](), "LastnameUpdated")
+	var incompatible *ndb.IncompatibleSchemaError
+	if !errors.As(err, &incompatible) {
+		t.Fatalf("expected incompatible schema error, got %v", err)
+	}
+
+	versions := option.Must(msgs.(ndb.Schemas).Schemas("LastnameUpdated"))
+	if len(versions) != 1 {
+		t.Fatalf("expected the rejected schema not to be stored, got %d versions", len(versions))
+	}
+}
+
+// renameLastname migrates the stored shape of lastnameRenamed into the current
+// LastnameUpdated.
+func renameLastname(raw json.RawMessage) (json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	fields["name"] = fields["lastname"]
+	delete(fields, "lastname")
+	return json.Marshal(fields)
+}
+
+func TestNDBBackendUpcastsAndRewritesLog(t *testing.T) {
+	msgs := openNDBMessagesWith(t, msgstore.Options{ValidateSchemas: true})
+
+	// an older release wrote events in the old shape
+	old := evs.NewNDBBackend[Evt, *Person](msgs)
+	option.MustZero(old.Register(reflect.TypeFor[lastnameRenamed](), "LastnameUpdated"))
+	option.Must(msgs.Append("LastnameUpdated", ndb.NewTraceID(), []byte(`{"person":"1","lastname":"Doe"}`)))
+
+	upcasters := evs.NewUpcasters()
+	option.MustZero(upcasters.Register("LastnameUpdated", 1, renameLastname))
+	if err := upcasters.Register("LastnameUpdated", 1, renameLastname); err == nil {
+		t.Fatal("expected registering version 1 twice to fail")
+	}
+
+	aggID := func(e Evt) (PID, bool) {
+		switch evt := e.(type) {
+		case FirstnameUpdated:
+			return evt.Person, evt.Person != ""
+		case LastnameUpdated:
+			return evt.Person, evt.Person != ""
+		default:
+			return "", false
+		}
+	}
+
+	// the breaking change is accepted, because the upcaster covers it
+	backend := evs.NewNDBBackend[Evt, *Person](msgs, evs.WithUpcasters(upcasters))
+	handler := evs.NewHandler[*Person](backend, aggID, backend.Register)
+	handler.RegisterEvents(FirstnameUpdated{}, LastnameUpdated{})
+
+	if got := option.Must(handler.Aggregate(context.Background(), "1")).lastname; got != "Doe" {
+		t.Fatalf("expected upcasted lastname Doe, got %q", got)
+	}
+
+	// new events carry the current version and pass the schema validation
+	seq := appendPerson(t, backend, LastnameUpdated{Person: "2", Name: "Roe"})
+	stored := option.Must(msgs.Get("LastnameUpdated")).Unwrap()
+	if string(stored.Payload) != `{"_v":2,"person":"2","name":"Roe"}` {
+		t.Fatalf("unexpected stored payload %s", stored.Payload)
+	}
+
+	view := evs.NewProjection[PID, *personView](msgs, evs.ProjectionOptions{Upcasters: upcasters})
+	evs.Project(view,
+		func(e LastnameUpdated) PID { return e.Person },
+		func(s *personView, e LastnameUpdated) { s.Last = e.Name },
+	)
+	defer view.Run()()
+	waitProcessed(t, view, seq, 2*time.Second)
+
+	if v, _ := view.Get("1"); v == nil || v.Last != "Doe" {
+		t.Fatalf("expected upcasted projection row, got %+v", v)
+	}
+
+	stats := option.Must(evs.RewriteNDBLog(msgs, upcasters))
+	if stats.Scanned != 2 || stats.Rewritten != 1 {
+		t.Fatalf("unexpected rewrite stats %+v", stats)
+	}
+
+	for _, msg := range msgs.Replay([]ndb.TypeID{"LastnameUpdated"}, 1, math.MaxUint64) {
+		payload := option.Must(ndb.Decompress(msg.Encoding, msg.Payload, msg.UncompressedLen))
+		if !bytes.HasPrefix(payload, []byte(`{"_v":2,`)) {
+			t.Fatalf("expected a rewritten payload, got %s", payload)
+		}
+	}
+
+	if stats := option.Must(evs.RewriteNDBLog(msgs, upcasters)); stats.Rewritten != 0 {
+		t.Fatalf("expected a second rewrite to be a no-op, got %+v", stats)
+	}
+}
@@ -50,6 +50,10 @@ type Options[Evt any] struct {
 	// Schema maps discriminators to concrete types implementing Evt.
 	Schema map[evs.Discriminator]reflect.Type
 
+	// Upcasters may be nil. If not, stored events are upcasted to their current version before they are decoded.
+	// See also [Upcast].
+	Upcasters *evs.Upcasters
+
 	// DecorateUseCases is invoked before the use cases are passed into all generated and dependent code fragments
 	// thus you can customize, intercept or replace any standard use case here. For example, you can
 	// apply custom validation and return [xerrors.WithFields].
@@ -84,6 +88,20 @@ func Schema[T any, Evt any](d evs.Discriminator) Opt[Evt] {
 	}
 }
 
+// Upcast registers an upcaster which transforms the stored JSON of the given discriminator from version from into
+// from+1. Upcasters of a discriminator must be declared in ascending version order. See also [evs.Upcasters].
+func Upcast[Evt any](d evs.Discriminator, from int, fn evs.Upcast) Opt[Evt] {
+	return func(o *Options[Evt]) {
+		if o.Upcasters == nil {
+			o.Upcasters = evs.NewUpcasters()
+		}
+
+		if err := o.Upcasters.Register(d, from, fn); err != nil {
+			panic(err)
+		}
+	}
+}
+
 // Enable configures an event sourcing module instance. See also [evs.UseCases] and [evs.DeclarePermissions] for details.
 func Enable[Evt any](cfg *application.Configurator, prefix permission.ID, entityName string, opts Options[Evt]) (Module[Evt], error) {
 	mod, ok := core.FromContext[Module[Evt]](cfg.Context(), "")
@@ -108,8 +126,9 @@ func Enable[Evt any](cfg *application.Configurator, prefix permission.ID, entity
 	perms := evs.DeclarePermissions[Evt](prefix, entityName)
 
 	uc := evs.NewUseCases[Evt](perms, eventStore, evs.Options[Evt]{
-		Mutex: opts.Mutex,
-		Bus:   opts.Bus,
+		Mutex:     opts.Mutex,
+		Bus:       opts.Bus,
+		Upcasters: opts.Upcasters,
 	})
 
 	for discriminator, r := range opts.Schema {
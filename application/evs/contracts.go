@@ -52,6 +52,30 @@ type Backend[E any] interface {
 	ReplayAll(subject auth.Subject) iter.Seq2[Envelope[E], error]
 }
 
+// BackendOption configures a backend created by [NewBlobBackend] or
+// [NewNDBBackend].
+type BackendOption func(*backendOptions)
+
+type backendOptions struct {
+	upcasters *Upcasters
+}
+
+// WithUpcasters lets the backend upcast stored events before decoding them and
+// write new events with their current version. See [Upcasters].
+func WithUpcasters(upcasters *Upcasters) BackendOption {
+	return func(o *backendOptions) {
+		o.upcasters = upcasters
+	}
+}
+
+func newBackendOptions(opts []BackendOption) backendOptions {
+	var o backendOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
 // Cmd is a command which also provides the Decide implementation.
 type Cmd[Aggregate, SuperEvt any] interface {
 	Decide(auth.Subject, Aggregate) ([]SuperEvt, error)
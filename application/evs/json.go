@@ -36,16 +36,23 @@ func (e JsonEnvelope) Decode(registry map[Discriminator]reflect.Type) (any, erro
 	return rval.Elem().Interface(), nil
 }
 
-func (e JsonEnvelope) decodeData(registry *concurrent.RWMap[Discriminator, reflect.Type]) (any, error) {
+// decodeData upcasts the data to the current version of its discriminator and
+// decodes it. Besides the decoded value, it returns the upcasted JSON.
+func (e JsonEnvelope) decodeData(registry *concurrent.RWMap[Discriminator, reflect.Type], upcasters *Upcasters) (any, json.RawMessage, error) {
 	rtype, ok := registry.Get(e.Discriminator)
 	if !ok {
-		return nil, fmt.Errorf("unknown type: %s", e.Discriminator)
+		return nil, nil, fmt.Errorf("unknown type: %s", e.Discriminator)
+	}
+
+	data, err := upcasters.Upcast(e.Discriminator, e.Data)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	rval := reflect.New(rtype)
-	if err := json.Unmarshal(e.Data, rval.Interface()); err != nil {
-		return nil, err
+	if err := json.Unmarshal(data, rval.Interface()); err != nil {
+		return nil, nil, err
 	}
 
-	return rval.Elem().Interface(), nil
+	return rval.Elem().Interface(), data, nil
 }
@@ -19,6 +19,7 @@ type Permissions struct {
 	Replay  permission.ID
 	ReadAll permission.ID
 	Delete  permission.ID
+	Rewrite permission.ID
 
 	Prefix     permission.ID
 	EntityName string
@@ -32,6 +33,7 @@ type Permissions struct {
 //   - [Load]: <prefix>.load
 //   - [Replay]: <prefix>.replay
 //   - [All]: <prefix>.all
+//   - [Rewrite]: <prefix>.rewrite
 func DeclarePermissions[Evt any](prefix permission.ID, eventSumTypeName string) Permissions {
 	if !prefix.Valid() {
 		panic(fmt.Errorf("invalid prefix: %s", prefix))
@@ -43,6 +45,7 @@ func DeclarePermissions[Evt any](prefix permission.ID, eventSumTypeName string)
 		Replay:     permission.DeclareReplay[Replay[Evt]](prefix+".replay", eventSumTypeName),
 		ReadAll:    permission.DeclareReplay[ReadAll[Evt]](prefix+".readall", eventSumTypeName),
 		Delete:     permission.DeclareDeleteByID[Delete[Evt]](prefix+".delete", eventSumTypeName),
+		Rewrite:    permission.DeclareUpdate[RewriteLog[Evt]](prefix+".rewrite", eventSumTypeName),
 		Prefix:     prefix,
 		EntityName: eventSumTypeName,
 	}
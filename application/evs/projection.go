@@ -62,9 +62,13 @@ type Source = ndb.Followable
 //
 // # Rebuildability
 //
-// A projection folds exclusively from the log and keeps no persistence of its
-// own, so it can be rebuilt from the source at any time simply by constructing
-// it again and calling Run.
+// A projection folds exclusively from the log, so it can be rebuilt from the
+// source at any time simply by constructing it again and calling Run. For long
+// logs, [ProjectionOptions.Snapshot] optionally persists the folded state
+// together with its watermark, so that Run resumes from the snapshot instead of
+// replaying the whole history. A snapshot is only a cache: it is discarded
+// whenever it does not match the projection anymore, and deleting it just
+// triggers a full rebuild.
 //
 // # Consistency (read-your-write)
 //
@@ -158,6 +162,15 @@ type ProjectionOptions struct {
 	// error is logged via slog at error level. OnError must not block or write
 	// back into the source.
 	OnError func(seq ndb.Seq, typeID ndb.TypeID, err error)
+
+	// Upcasters may be nil. If not, each payload is upcasted to the current
+	// version of its discriminator before it is decoded. Pass the same instance
+	// the writing backend uses (see [WithUpcasters]).
+	Upcasters *Upcasters
+
+	// Snapshot optionally persists the folded state, see [SnapshotOptions]. The
+	// zero value keeps the projection purely resident.
+	Snapshot SnapshotOptions
 }
 
 // Unit is the single fixed key type of a [Singleton]. It is exported (with one
@@ -261,6 +274,12 @@ func Project[K ~string, S cloner.Cloner[S], E interface{ Discriminator() Discrim
 			return
 		}
 
+		payload, err = p.opts.Upcasters.Upcast(disc, payload)
+		if err != nil {
+			p.reportError(msg.Seq, typeID, err)
+			return
+		}
+
 		rv := reflect.New(rtype)
 		if err := json.Unmarshal(payload, rv.Interface()); err != nil {
 			p.reportError(msg.Seq, typeID, fmt.Errorf("decode: %w", err))
@@ -329,17 +348,30 @@ func (p *Projection[K, S]) reportError(seq ndb.Seq, typeID ndb.TypeID, err error
 // error, and unblock/populate once Run has been called and folding proceeds.
 func (p *Projection[K, S]) Run() (stop func()) {
 	p.runOnce.Do(func() {
-		done := make(chan struct{})
-		var closeOnce sync.Once
-		p.stopFn = func() { closeOnce.Do(func() { close(done) }) }
+		// cancelling the context also unblocks a tail which idles on a quiet
+		// live edge, so that a final snapshot is written promptly
+		ctx, cancel := context.WithCancel(context.Background())
+		p.stopFn = cancel
 
-		go p.loop(done)
+		go p.loop(ctx)
 	})
 	return p.stopFn
 }
 
-func (p *Projection[K, S]) loop(done <-chan struct{}) {
-	for typeID, msg := range ndb.Tail(p.src, p.types, ndb.TailOptions{FromSeq: p.opts.FromSeq}) {
+func (p *Projection[K, S]) loop(ctx context.Context) {
+	done := ctx.Done()
+
+	from := p.opts.FromSeq
+	snap := p.newSnapshotter()
+	if snap != nil {
+		if seq := p.restoreSnapshot(snap); seq > 0 {
+			from = max(from, seq+1)
+			p.advance(seq)
+		}
+		defer p.saveSnapshot(snap)
+	}
+
+	for typeID, msg := range ndb.Tail(p.src, p.types, ndb.TailOptions{FromSeq: from, Ctx: ctx}) {
 		select {
 		case <-done:
 			return
@@ -354,6 +386,10 @@ func (p *Projection[K, S]) loop(done <-chan struct{}) {
 		// returns is guaranteed to see the folded state, not merely the arrival.
 		p.advance(msg.Seq)
 
+		if snap != nil {
+			p.processed(snap)
+		}
+
 		select {
 		case <-done:
 			return
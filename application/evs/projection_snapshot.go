@@ -0,0 +1,210 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package evs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strconv"
+	"time"
+
+	"go.wdy.de/nago/pkg/blob"
+	"go.wdy.de/nago/pkg/ndb"
+)
+
+const (
+	// DefaultSnapshotEvery is the zero-value [SnapshotOptions.Every]: write a
+	// snapshot after 10,000 processed events.
+	DefaultSnapshotEvery = 10_000
+	// DefaultSnapshotInterval is the zero-value [SnapshotOptions.Interval]:
+	// write a snapshot at most once per minute.
+	DefaultSnapshotInterval = time.Minute
+)
+
+// SnapshotOptions configures the persistent snapshots of a [Projection]. The
+// zero value disables snapshotting.
+//
+// A snapshot holds the complete folded map[K]S together with the last processed
+// global [ndb.Seq]. On the next [Projection.Run], the projection restores the
+// snapshot and resumes [ndb.Tail] from the following Seq, instead of replaying
+// the whole history. S must therefore round-trip through encoding/json, i.e.
+// all state which must survive a restart lives in exported fields.
+//
+// A snapshot is discarded and the projection is rebuilt from the log whenever
+// its fingerprint changes. The fingerprint covers Version, the registered event
+// types, their upcaster versions (see [Upcasters]) and the JSON schema of S, so
+// a changed state struct invalidates old snapshots automatically. A changed
+// fold function cannot be detected: bump Version in that case.
+type SnapshotOptions struct {
+	// Store receives the snapshots. A nil Store disables snapshotting.
+	Store blob.Store
+
+	// Key is the blob key of the snapshot within Store. Projections sharing a
+	// Store must use distinct keys. Empty selects "projection".
+	Key string
+
+	// Version is the fold version. Change it whenever a fold function changes
+	// its semantics, so that a stale snapshot is not resumed.
+	Version string
+
+	// Every writes a snapshot after this many processed events. 0 selects
+	// [DefaultSnapshotEvery].
+	Every uint64
+
+	// Interval writes a snapshot at most this often, regardless of Every, as
+	// long as at least one event has been processed since the last snapshot. 0
+	// selects [DefaultSnapshotInterval]. A negative value disables time-based
+	// snapshots.
+	Interval time.Duration
+}
+
+// projectionSnapshot is the persisted form of a [Projection].
+type projectionSnapshot[K ~string, S any] struct {
+	Fingerprint string  `json:"fingerprint"`
+	Seq         ndb.Seq `json:"seq"`
+	State       map[K]S `json:"state"`
+}
+
+// snapshotter tracks when the next snapshot is due. It is only used by the
+// tail goroutine.
+type snapshotter struct {
+	opts        SnapshotOptions
+	fingerprint string
+	pending     uint64 // processed events since the last snapshot
+	last        time.Time
+}
+
+func (p *Projection[K, S]) newSnapshotter() *snapshotter {
+	opts := p.opts.Snapshot
+	if opts.Store == nil {
+		return nil
+	}
+
+	if opts.Key == "" {
+		opts.Key = "projection"
+	}
+	if opts.Every == 0 {
+		opts.Every = DefaultSnapshotEvery
+	}
+	if opts.Interval == 0 {
+		opts.Interval = DefaultSnapshotInterval
+	}
+
+	return &snapshotter{
+		opts:        opts,
+		fingerprint: p.fingerprint(opts.Version),
+		last:        time.Now(),
+	}
+}
+
+// fingerprint identifies everything a snapshot depends on, besides the fold
+// functions themselves.
+func (p *Projection[K, S]) fingerprint(version string) string {
+	types := slices.Clone(p.types)
+	slices.Sort(types)
+
+	h := sha256.New()
+	h.Write([]byte(version))
+	for _, t := range types {
+		h.Write([]byte{0})
+		h.Write([]byte(t))
+		h.Write([]byte(strconv.Itoa(p.opts.Upcasters.Version(Discriminator(t)))))
+	}
+
+	schema, _ := json.Marshal(ndb.SchemaOf(reflect.TypeFor[S]()))
+	h.Write([]byte{0})
+	h.Write(schema)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// restoreSnapshot loads the snapshot into the (still empty) state map and
+// returns its Seq. It returns 0 if there is no usable snapshot, in which case
+// the projection is rebuilt from the log.
+func (p *Projection[K, S]) restoreSnapshot(snap *snapshotter) ndb.Seq {
+	optBuf, err := blob.Get(snap.opts.Store, snap.opts.Key)
+	if err != nil {
+		slog.Error("evs: cannot load projection snapshot", "key", snap.opts.Key, "err", err)
+		return 0
+	}
+
+	if optBuf.IsNone() {
+		return 0
+	}
+
+	var stored projectionSnapshot[K, S]
+	if err := json.Unmarshal(optBuf.Unwrap(), &stored); err != nil {
+		slog.Warn("evs: discarding unreadable projection snapshot", "key", snap.opts.Key, "err", err)
+		return 0
+	}
+
+	if stored.Fingerprint != snap.fingerprint {
+		slog.Info("evs: discarding outdated projection snapshot", "key", snap.opts.Key)
+		return 0
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for k, s := range stored.State {
+		cell := &stateCell[S]{live: s}
+		cell.stale.Store(true)
+		p.state[k] = cell
+	}
+
+	return stored.Seq
+}
+
+// processed is called after each processed event and writes a snapshot if one
+// is due.
+func (p *Projection[K, S]) processed(snap *snapshotter) {
+	snap.pending++
+	if snap.pending >= snap.opts.Every || (snap.opts.Interval > 0 && time.Since(snap.last) >= snap.opts.Interval) {
+		p.saveSnapshot(snap)
+	}
+}
+
+// saveSnapshot writes the live state, if anything has been processed since the
+// last snapshot. Failures are logged and retried with the next due snapshot;
+// they never stop the projection.
+func (p *Projection[K, S]) saveSnapshot(snap *snapshotter) {
+	if snap.pending == 0 {
+		return
+	}
+
+	// the tail goroutine is the sole writer, so the read lock yields a
+	// consistent state for the current watermark
+	p.mu.RLock()
+	stored := projectionSnapshot[K, S]{
+		Fingerprint: snap.fingerprint,
+		Seq:         p.ProcessedSeq(),
+		State:       make(map[K]S, len(p.state)),
+	}
+	for k, cell := range p.state {
+		stored.State[k] = cell.live
+	}
+	buf, err := json.Marshal(stored)
+	p.mu.RUnlock()
+
+	snap.last = time.Now()
+	if err != nil {
+		slog.Error("evs: cannot encode projection snapshot", "key", snap.opts.Key, "err", err)
+		return
+	}
+
+	if err := blob.Put(snap.opts.Store, snap.opts.Key, buf); err != nil {
+		slog.Error("evs: cannot save projection snapshot", "key", snap.opts.Key, "err", err)
+		return
+	}
+
+	snap.pending = 0
+}
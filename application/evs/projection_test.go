@@ -9,6 +9,7 @@ package evs_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"slices"
@@ -20,6 +21,8 @@ import (
 	"github.com/worldiety/option"
 	"go.wdy.de/nago/application/evs"
 	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/pkg/blob"
+	"go.wdy.de/nago/pkg/blob/mem"
 	"go.wdy.de/nago/pkg/ndb"
 	"go.wdy.de/nago/pkg/ndb/msgstore"
 )
@@ -520,3 +523,71 @@ func TestProjectionS2Compression(t *testing.T) {
 		t.Fatalf("S2 live fold wrong: %+v", got)
 	}
 }
+
+// TestProjectionSnapshotResume proves that a projection resumes from its
+// persisted snapshot instead of refolding the history, and that a changed fold
+// version discards the snapshot.
+func TestProjectionSnapshotResume(t *testing.T) {
+	msgs := openNDBMessages(t)
+	backend := evs.NewNDBBackend[Evt, *Person](msgs)
+	option.MustZero(backend.Register(reflectTypeOf(FirstnameUpdated{}), "FirstnameUpdated"))
+
+	snapshots := mem.NewBlobStore("snapshots")
+	var folds atomic.Int64
+	newView := func(version string) *evs.Projection[PID, *personView] {
+		view := evs.NewProjection[PID, *personView](msgs, evs.ProjectionOptions{
+			Snapshot: evs.SnapshotOptions{Store: snapshots, Key: "persons", Version: version, Every: 1},
+		})
+		evs.Project(view,
+			func(e FirstnameUpdated) PID { return e.Person },
+			func(s *personView, e FirstnameUpdated) { s.First = e.Firstname; s.Events++; folds.Add(1) },
+		)
+		return view
+	}
+
+	appendPerson(t, backend, FirstnameUpdated{Person: "1", Firstname: "John"})
+	seq := appendPerson(t, backend, FirstnameUpdated{Person: "2", Firstname: "Jane"})
+
+	first := newView("1")
+	stop := first.Run()
+	waitProcessed(t, first, seq, 2*time.Second)
+	stop()
+
+	// the snapshot is written by the tail goroutine right after folding
+	snapshotSeq := func() ndb.Seq {
+		var snap struct{ Seq ndb.Seq }
+		if buf := option.Must(blob.Get(snapshots, "persons")); buf.IsSome() {
+			option.MustZero(json.Unmarshal(buf.Unwrap(), &snap))
+		}
+		return snap.Seq
+	}
+	for deadline := time.Now().Add(2 * time.Second); snapshotSeq() != seq && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+
+	live := appendPerson(t, backend, FirstnameUpdated{Person: "1", Firstname: "Jonathan"})
+
+	folds.Store(0)
+	resumed := newView("1")
+	defer resumed.Run()()
+	waitProcessed(t, resumed, live, 2*time.Second)
+
+	if n := folds.Load(); n != 1 {
+		t.Fatalf("expected only the new event to be folded, got %d folds", n)
+	}
+	if got, _ := resumed.Get("1"); got.First != "Jonathan" || got.Events != 2 {
+		t.Fatalf("unexpected resumed row: %+v", got)
+	}
+	if got, ok := resumed.Get("2"); !ok || got.First != "Jane" {
+		t.Fatalf("expected row 2 from the snapshot, got %+v", got)
+	}
+
+	folds.Store(0)
+	rebuilt := newView("2")
+	defer rebuilt.Run()()
+	waitProcessed(t, rebuilt, live, 2*time.Second)
+
+	if n := folds.Load(); n != 3 {
+		t.Fatalf("expected a changed fold version to rebuild from the log, got %d folds", n)
+	}
+}
@@ -18,7 +18,7 @@ import (
 	"go.wdy.de/nago/pkg/std/concurrent"
 )
 
-func NewLoad[Evt any](perms Permissions, eventStore blob.Store, registry *concurrent.RWMap[Discriminator, reflect.Type]) Load[Evt] {
+func NewLoad[Evt any](perms Permissions, eventStore blob.Store, registry *concurrent.RWMap[Discriminator, reflect.Type], upcasters *Upcasters) Load[Evt] {
 	return func(subject auth.Subject, id SeqID) (option.Opt[Envelope[Evt]], error) {
 		var zero option.Opt[Envelope[Evt]]
 		if !subject.HasPermission(perms.Load) && !subject.HasPermission(perms.ReadAll) {
@@ -44,7 +44,7 @@ func NewLoad[Evt any](perms Permissions, eventStore blob.Store, registry *concur
 			return zero, err
 		}
 
-		payload, err := jsonEnv.decodeData(registry)
+		payload, data, err := jsonEnv.decodeData(registry, upcasters)
 		if err != nil {
 			return zero, err
 		}
@@ -61,7 +61,7 @@ func NewLoad[Evt any](perms Permissions, eventStore blob.Store, registry *concur
 			Discriminator: jsonEnv.Discriminator,
 			EventTime:     jsonEnv.EventTime,
 			Data:          payloadEvt,
-			Raw:           jsonEnv.Data,
+			Raw:           data,
 		}), nil
 	}
 }
@@ -0,0 +1,153 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package evs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"go.wdy.de/nago/auth"
+	"go.wdy.de/nago/pkg/blob"
+	"go.wdy.de/nago/pkg/ndb"
+)
+
+// RewriteStats summarizes a log rewrite.
+type RewriteStats struct {
+	// Scanned is the number of inspected events.
+	Scanned int
+	// Rewritten is the number of events which have been upcasted and stored in
+	// their current version.
+	Rewritten int
+}
+
+// RewriteLog is a one-off maintenance operation which persists the upcasted form
+// of all events written with an outdated version, so that the upcasters (and
+// eventually the old field names) can be removed later on. Sequence ids and
+// event times are preserved. Running it again is a no-op. Each event is read and
+// written while holding [Options.Mutex], thus writers which hold the same mutex
+// are never overwritten.
+type RewriteLog[Evt any] func(subject auth.Subject) (RewriteStats, error)
+
+func NewRewriteLog[Evt any](mutex *sync.Mutex, perms Permissions, eventStore blob.Store, upcasters *Upcasters) RewriteLog[Evt] {
+	return func(subject auth.Subject) (RewriteStats, error) {
+		var stats RewriteStats
+		if err := subject.Audit(perms.Rewrite); err != nil {
+			return stats, err
+		}
+
+		for key, err := range eventStore.List(context.Background(), blob.ListOptions{}) {
+			if err != nil {
+				return stats, err
+			}
+
+			if err := rewriteEvent(mutex, eventStore, upcasters, key, &stats); err != nil {
+				return stats, err
+			}
+		}
+
+		if stats.Rewritten > 0 {
+			slog.Info("evs: rewrote outdated events", "prefix", perms.Prefix, "scanned", stats.Scanned, "rewritten", stats.Rewritten)
+		}
+
+		return stats, nil
+	}
+}
+
+// rewriteEvent upcasts and stores the event of the given key, if it is
+// outdated, and counts it in stats. The lock spans the read and the write, so
+// that a concurrent update of the same key is either seen or happens afterward.
+func rewriteEvent(mutex *sync.Mutex, eventStore blob.Store, upcasters *Upcasters, key string, stats *RewriteStats) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	optBuf, err := blob.Get(eventStore, key)
+	if err != nil {
+		return err
+	}
+
+	if optBuf.IsNone() {
+		return nil
+	}
+
+	stats.Scanned++
+
+	var jsonEnv JsonEnvelope
+	if err := json.Unmarshal(optBuf.Unwrap(), &jsonEnv); err != nil {
+		return fmt.Errorf("cannot decode event %s: %w", key, err)
+	}
+
+	outdated, err := upcasters.outdated(jsonEnv.Discriminator, jsonEnv.Data)
+	if err != nil {
+		return fmt.Errorf("cannot rewrite event %s: %w", key, err)
+	}
+
+	if !outdated {
+		return nil
+	}
+
+	if jsonEnv.Data, err = upcastAndStamp(upcasters, jsonEnv.Discriminator, jsonEnv.Data); err != nil {
+		return fmt.Errorf("cannot rewrite event %s: %w", key, err)
+	}
+
+	buf, err := json.Marshal(jsonEnv)
+	if err != nil {
+		return fmt.Errorf("error marshalling envelope: %w", err)
+	}
+
+	if err := blob.Put(eventStore, key, buf); err != nil {
+		return fmt.Errorf("error storing envelope in store: %w", err)
+	}
+
+	stats.Rewritten++
+	return nil
+}
+
+// RewriteNDBLog is the [RewriteLog] counterpart for events stored through
+// [NewNDBBackend]. It only visits the discriminators with registered upcasters.
+// The engine must implement [ndb.Rewriter].
+func RewriteNDBLog(msgs ndb.Messages, upcasters *Upcasters) (RewriteStats, error) {
+	var stats RewriteStats
+
+	rewriter, ok := msgs.(ndb.Rewriter)
+	if !ok {
+		return stats, fmt.Errorf("message engine %T cannot rewrite messages", msgs)
+	}
+
+	for d := range upcasters.Discriminators() {
+		n, err := rewriter.Rewrite(ndb.TypeID(d), func(msg ndb.Message) ([]byte, bool, error) {
+			stats.Scanned++
+
+			outdated, err := upcasters.outdated(d, msg.Payload)
+			if err != nil || !outdated {
+				return nil, false, err
+			}
+
+			buf, err := upcastAndStamp(upcasters, d, msg.Payload)
+			return buf, err == nil, err
+		})
+
+		stats.Rewritten += n
+		if err != nil {
+			return stats, err
+		}
+	}
+
+	return stats, nil
+}
+
+func upcastAndStamp(upcasters *Upcasters, d Discriminator, raw json.RawMessage) (json.RawMessage, error) {
+	raw, err := upcasters.Upcast(d, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return upcasters.stamp(d, raw)
+}
@@ -21,7 +21,7 @@ import (
 	"go.wdy.de/nago/pkg/xtime"
 )
 
-func NewStore[Evt any](perms Permissions, typeRegistry *concurrent.RWMap[reflect.Type, Discriminator], eventStore blob.Store) Store[Evt] {
+func NewStore[Evt any](perms Permissions, typeRegistry *concurrent.RWMap[reflect.Type, Discriminator], eventStore blob.Store, upcasters *Upcasters) Store[Evt] {
 	var lastId atomic.Int64
 	var once sync.Once
 
@@ -94,6 +94,12 @@ func NewStore[Evt any](perms Permissions, typeRegistry *concurrent.RWMap[reflect
 			Data:          payloadBuf,
 		}
 
+		// events are always written with their current version
+		env.Data, err = upcasters.stamp(discriminator, payloadBuf)
+		if err != nil {
+			return zero, err
+		}
+
 		buf, err := json.Marshal(env)
 		if err != nil {
 			return zero, fmt.Errorf("error marshalling envelope: %w", err)
@@ -0,0 +1,196 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package evs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// VersionKey is the reserved top-level JSON property which carries the version
+// of a stored event. It is only written for event types with at least one
+// registered [Upcast], and a missing key means version 1. Event types must
+// therefore be encoded as JSON objects and must not use this property name
+// themselves.
+const VersionKey = "_v"
+
+// Upcast transforms the JSON encoding of an event from one version into the
+// next one. It works on the raw JSON and not on Go types, so that the old Go
+// type can be removed from the code base once the upcaster exists. The version
+// property is neither passed in nor expected in the result.
+type Upcast func(raw json.RawMessage) (json.RawMessage, error)
+
+// Upcasters keeps a chain of [Upcast] functions per discriminator. Whenever an
+// event is read, its stored version is upcasted step by step to the current
+// version before it is decoded, so that renamed fields or changed field types
+// do not break the replay of events written by an older release. New events
+// are always written with the current version.
+//
+// A nil *Upcasters is valid and performs no transformations at all. Register
+// all chains before the first event is read or written.
+type Upcasters struct {
+	mu     sync.RWMutex
+	chains map[Discriminator][]Upcast
+}
+
+func NewUpcasters() *Upcasters {
+	return &Upcasters{chains: make(map[Discriminator][]Upcast)}
+}
+
+// Register appends fn to the chain of d. fn must transform version from into
+// version from+1, thus from must be the current [Upcasters.Version] of d. This
+// forces upcasters to be registered in ascending order, without gaps.
+func (u *Upcasters) Register(d Discriminator, from int, fn Upcast) error {
+	if err := d.Validate(); err != nil {
+		return err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if current := len(u.chains[d]) + 1; from != current {
+		return fmt.Errorf("cannot register upcaster for %q from version %d: current version is %d", d, from, current)
+	}
+
+	u.chains[d] = append(u.chains[d], fn)
+	return nil
+}
+
+// Version returns the current version of d, which is the version every new
+// event of that discriminator is written with. It is 1 if no upcaster has been
+// registered.
+func (u *Upcasters) Version(d Discriminator) int {
+	return len(u.chain(d)) + 1
+}
+
+// Discriminators returns all discriminators with at least one registered
+// upcaster in ascending order.
+func (u *Upcasters) Discriminators() iter.Seq[Discriminator] {
+	if u == nil {
+		return func(yield func(Discriminator) bool) {}
+	}
+
+	u.mu.RLock()
+	tmp := slices.Sorted(maps.Keys(u.chains))
+	u.mu.RUnlock()
+
+	return slices.Values(tmp)
+}
+
+func (u *Upcasters) chain(d Discriminator) []Upcast {
+	if u == nil {
+		return nil
+	}
+
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	return u.chains[d]
+}
+
+// Upcast returns raw in the current version of d, without the version
+// property. raw is returned as is if it already has the current version and
+// carries no version property. It fails if raw has been written with a newer
+// version than known, e.g. by a newer release sharing the same store.
+func (u *Upcasters) Upcast(d Discriminator, raw json.RawMessage) (json.RawMessage, error) {
+	chain := u.chain(d)
+
+	version, raw, err := splitVersion(raw)
+	if err != nil {
+		return nil, fmt.Errorf("cannot upcast %q: %w", d, err)
+	}
+
+	if version > len(chain)+1 {
+		return nil, fmt.Errorf("cannot upcast %q: stored version %d is newer than the current version %d", d, version, len(chain)+1)
+	}
+
+	for i, fn := range chain[version-1:] {
+		raw, err = fn(raw)
+		if err != nil {
+			return nil, fmt.Errorf("cannot upcast %q from version %d: %w", d, version+i, err)
+		}
+	}
+
+	return raw, nil
+}
+
+// outdated reports whether raw has been written with an older version than the
+// current one and therefore needs to be rewritten.
+func (u *Upcasters) outdated(d Discriminator, raw json.RawMessage) (bool, error) {
+	version, _, err := splitVersion(raw)
+	if err != nil {
+		return false, err
+	}
+
+	return version < u.Version(d), nil
+}
+
+// stamp adds the current version of d to the JSON object raw. Events of
+// discriminators without upcasters are returned unchanged, so that stores which
+// never used upcasting keep their exact encoding.
+func (u *Upcasters) stamp(d Discriminator, raw json.RawMessage) (json.RawMessage, error) {
+	version := u.Version(d)
+	if version == 1 {
+		return raw, nil
+	}
+
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) < 2 || trimmed[0] != '{' {
+		return nil, fmt.Errorf("event %q must be encoded as a JSON object to carry a version", d)
+	}
+
+	buf := make([]byte, 0, len(trimmed)+len(VersionKey)+16)
+	buf = append(buf, `{"`+VersionKey+`":`...)
+	buf = strconv.AppendInt(buf, int64(version), 10)
+
+	rest := bytes.TrimSpace(trimmed[1:])
+	if rest[0] != '}' {
+		buf = append(buf, ',')
+	}
+
+	return append(buf, rest...), nil
+}
+
+// splitVersion returns the version stored in raw and raw without the version
+// property. The common case of an object without a version property is detected
+// without decoding it.
+func splitVersion(raw json.RawMessage) (int, json.RawMessage, error) {
+	if !bytes.Contains(raw, []byte(`"`+VersionKey+`"`)) {
+		return 1, raw, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return 0, nil, fmt.Errorf("invalid event encoding: %w", err)
+	}
+
+	v, ok := fields[VersionKey]
+	if !ok {
+		// the key is only part of some nested value
+		return 1, raw, nil
+	}
+
+	var version int
+	if err := json.Unmarshal(v, &version); err != nil || version < 1 {
+		return 0, nil, fmt.Errorf("invalid event version %s", v)
+	}
+
+	delete(fields, VersionKey)
+	buf, err := json.Marshal(fields)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return version, buf, nil
+}
@@ -113,6 +113,10 @@ type Options[Evt any] struct {
 
 	// Bus may be nil. If not, according mutation events are eventually published.
 	Bus events.Bus
+
+	// Upcasters may be nil. If not, stored events are upcasted to their current
+	// version on load and new events are written with it. See [Upcasters].
+	Upcasters *Upcasters
 }
 
 type UseCases[Evt any] struct {
@@ -124,6 +128,7 @@ type UseCases[Evt any] struct {
 	RegisteredTypes RegisteredTypes[Evt]
 	MakeType        MakeType[Evt]
 	Delete          Delete[Evt]
+	RewriteLog      RewriteLog[Evt]
 }
 
 func NewUseCases[Evt any](perms Permissions, eventStore blob.Store, opts Options[Evt]) UseCases[Evt] {
@@ -134,11 +139,11 @@ func NewUseCases[Evt any](perms Permissions, eventStore blob.Store, opts Options
 		opts.Mutex = &sync.Mutex{}
 	}
 
-	loadFn := NewLoad[Evt](perms, eventStore, &invTypeRegistry)
+	loadFn := NewLoad[Evt](perms, eventStore, &invTypeRegistry, opts.Upcasters)
 	deleteFn := NewDelete(perms, loadFn, eventStore)
 
 	return UseCases[Evt]{
-		Store:           NewStore[Evt](perms, &typeRegistry, eventStore),
+		Store:           NewStore[Evt](perms, &typeRegistry, eventStore, opts.Upcasters),
 		Load:            loadFn,
 		Replay:          NewReplay[Evt](perms, eventStore, loadFn),
 		Register:        NewRegister[Evt](&typeRegistry, &invTypeRegistry),
@@ -146,5 +151,6 @@ func NewUseCases[Evt any](perms Permissions, eventStore blob.Store, opts Options
 		RegisteredTypes: NewRegisteredTypes[Evt](&invTypeRegistry),
 		MakeType:        NewMakeType[Evt](&invTypeRegistry),
 		Delete:          deleteFn,
+		RewriteLog:      NewRewriteLog[Evt](opts.Mutex, perms, eventStore, opts.Upcasters),
 	}
 }
@@ -28,6 +28,7 @@ import (
 // It is strongly recommended to enable only one, as the selection behaviour is undefined.
 //
 // When application.TokenManagement is enabled, bearer authentication can be used to protect API endpoints.
+// With application.OAuthManagement, its AuthenticateSubject additionally accepts OAuth 2.0 access tokens.
 type Management struct {
 	API *hapi.API
 }
@@ -42,6 +42,11 @@ func Put[In any](api *API, op Operation) *EndpointBuilder[In] {
 	return Endpoint[In](api, op)
 }
 
+func Patch[In any](api *API, op Operation) *EndpointBuilder[In] {
+	op.Method = http.MethodPatch
+	return Endpoint[In](api, op)
+}
+
 func Endpoint[In any](api *API, op Operation) *EndpointBuilder[In] {
 	b := &EndpointBuilder[In]{
 		op:  op,
@@ -227,6 +232,10 @@ func (b *EndpointBuilder[In]) register(api *API) {
 
 	op.Security = b.request.security
 	if len(op.Security) > 0 {
+		if op.Responses == nil {
+			op.Responses = map[string]*oas.Response{}
+		}
+
 		op.Responses["401"] = &oas.Response{
 			Description: "The authorization is missing. This is usually a bearer token. It may also indicate a wrong token format.",
 		}
@@ -8,11 +8,14 @@
 package hapi
 
 import (
+	"errors"
 	"fmt"
+	"go.wdy.de/nago/application/lockout"
 	"go.wdy.de/nago/application/token"
 	"go.wdy.de/nago/application/user"
 	"go.wdy.de/nago/auth"
 	"go.wdy.de/nago/pkg/oas/v31"
+	"go.wdy.de/nago/pkg/xhttp"
 	"net/http"
 	"strings"
 )
@@ -113,7 +116,7 @@ func BearerAuth[In any](authenticate token.AuthenticateSubject, fn func(dst *In,
 
 				var subject auth.Subject
 				if authHeader == "" {
-					sub, err := authenticate("")
+					sub, err := authenticate("", "")
 					if err != nil {
 						http.Error(w, "authenticate use case does not support anon call", http.StatusInternalServerError)
 						return nil, err
@@ -129,8 +132,13 @@ func BearerAuth[In any](authenticate token.AuthenticateSubject, fn func(dst *In,
 					}
 
 					tokenStr := strings.TrimPrefix(authHeader, prefix)
-					subj, err := authenticate(token.Plaintext(tokenStr))
+					subj, err := authenticate(xhttp.RemoteIP(r), token.Plaintext(tokenStr))
 					if err != nil {
+						if errors.Is(err, lockout.TooManyAttemptsErr) {
+							http.Error(w, "Too many failed attempts", http.StatusTooManyRequests)
+							return nil, err
+						}
+
 						http.Error(w, "Authorization header missing", http.StatusInternalServerError)
 						return nil, err
 					}
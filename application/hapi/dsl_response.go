@@ -29,3 +29,11 @@ func (r *ResponseBuilder[In]) handle(in In, writer http.ResponseWriter, request
 
 	r.handler(in, writer, request)
 }
+
+// RawResponse provides access to the underlying response writer, e.g. to write custom status codes or content types.
+// Like [RawRequest], nothing you do here will be made visible in the [oas.OpenAPI] which you must modify manually.
+func RawResponse[In any](fn func(in In, writer http.ResponseWriter, request *http.Request)) ResponseOption[In] {
+	return func(doc *oas.OpenAPI, r *ResponseBuilder[In]) {
+		r.handler = fn
+	}
+}
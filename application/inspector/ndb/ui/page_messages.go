@@ -198,7 +198,7 @@ func pruneSelection(selected *core.State[[]selKey], types []ndbinspector.TypeInf
 	}
 }
 
-// streamList shows per-stream statistics and a per-stream delete knife tool. It
+// streamList shows per-stream statistics, the ACL editor and a per-stream delete knife tool. It
 // is informational; selection happens through the type picker above.
 func streamList(wnd core.Window, uc ndbinspector.UseCases, instancePath, engine string, types []ndbinspector.TypeInfo, invalidate *core.State[int]) core.View {
 	if engine == "" {
@@ -240,6 +240,7 @@ func streamStatRow(wnd core.Window, uc ndbinspector.UseCases, instancePath, engi
 			)).Font(ui.Small),
 		).Alignment(ui.Leading),
 		ui.Spacer(),
+		aclButton(wnd, uc, instancePath, engine, ti, invalidate),
 		deleteTypeButton(wnd, uc, instancePath, engine, string(ti.Type), invalidate),
 	).Alignment(ui.Center).
 		BackgroundColor(ui.ColorCardBody).
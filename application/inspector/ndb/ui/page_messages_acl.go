@@ -0,0 +1,111 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package uindbinspector
+
+import (
+	"strings"
+
+	ndbinspector "go.wdy.de/nago/application/inspector/ndb"
+	"go.wdy.de/nago/pkg/ndb"
+	"go.wdy.de/nago/presentation/core"
+	icons "go.wdy.de/nago/presentation/icons/flowbite/outline"
+	"go.wdy.de/nago/presentation/ui"
+	"go.wdy.de/nago/presentation/ui/alert"
+
+	"github.com/worldiety/i18n"
+)
+
+// aclButton opens the ACL editor of a message stream. The icon shows at a
+// glance whether a stream has a declared ACL at all; streams without one are
+// inaccessible through a guarded view.
+func aclButton(wnd core.Window, uc ndbinspector.UseCases, instancePath, engine string, ti ndbinspector.TypeInfo, invalidate *core.State[int]) core.View {
+	prefix := "acl-" + instancePath + "-" + engine + "-" + string(ti.Type)
+	presented := core.StateOf[bool](wnd, prefix)
+
+	// one comma separated principal list per operation
+	fields := make(map[ndb.Operation]*core.State[string], len(ndb.Operations))
+	for _, op := range ndb.Operations {
+		fields[op] = core.StateOf[string](wnd, prefix+"-"+string(op))
+	}
+
+	icon := icons.LockOpen
+	if ti.HasACL {
+		icon = icons.Lock
+	}
+
+	return ui.HStack(
+		aclDialog(wnd, uc, instancePath, engine, ti.Type, fields, presented, invalidate),
+		ui.TertiaryButton(func() {
+			optACL, err := uc.ACL(wnd.Subject(), instancePath, engine, ti.Type)
+			if err != nil {
+				alert.ShowBannerError(wnd, err)
+				return
+			}
+			acl := optACL.UnwrapOr(ndb.ACL{})
+			for _, op := range ndb.Operations {
+				fields[op].Set(joinPrincipals(acl.Principals(op)))
+			}
+			presented.Set(true)
+		}).PreIcon(icon).AccessibilityLabel(StrEditACL.Get(wnd)),
+	)
+}
+
+func aclDialog(wnd core.Window, uc ndbinspector.UseCases, instancePath, engine string, typeID ndb.TypeID, fields map[ndb.Operation]*core.State[string], presented *core.State[bool], invalidate *core.State[int]) core.View {
+	if !presented.Get() {
+		return nil
+	}
+
+	rows := []core.View{
+		ui.Text(StrACLHint.Get(wnd)).Font(ui.Small),
+		ui.Space(ui.L8),
+	}
+	for _, op := range ndb.Operations {
+		rows = append(rows, ui.TextField(string(op), fields[op].Get()).
+			InputValue(fields[op]).
+			FullWidth())
+	}
+
+	return alert.Dialog(
+		StrACLTitle.Get(wnd, i18n.String("type", string(typeID))),
+		ui.VStack(rows...).Alignment(ui.Leading).Gap(ui.L8).FullWidth(),
+		presented,
+		alert.Closeable(),
+		alert.Cancel(nil),
+		alert.Save(func() (close bool) {
+			var acl ndb.ACL
+			for _, op := range ndb.Operations {
+				acl = acl.With(op, splitPrincipals(fields[op].Get()))
+			}
+			if err := uc.SetACL(wnd.Subject(), instancePath, engine, typeID, acl); err != nil {
+				alert.ShowBannerError(wnd, err)
+				return false
+			}
+			invalidate.Set(invalidate.Get() + 1)
+			return true
+		}),
+	)
+}
+
+func joinPrincipals(principals []ndb.Principal) string {
+	tmp := make([]string, 0, len(principals))
+	for _, p := range principals {
+		tmp = append(tmp, string(p))
+	}
+	return strings.Join(tmp, ", ")
+}
+
+func splitPrincipals(s string) []ndb.Principal {
+	var out []ndb.Principal
+	for _, p := range strings.Split(s, ",") {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			out = append(out, ndb.Principal(p))
+		}
+	}
+	return out
+}
@@ -95,6 +95,17 @@ var (
 	StrDeleteStreamBody = i18n.MustVarString("nago.ndbinspector.messages.delete_stream_body",
 		i18n.Values{language.German: "Den gesamten Stream {type} unwiderruflich löschen?", language.English: "Irreversibly delete the whole stream {type}?"})
 
+	// access control lists
+	StrEditACL = i18n.MustString("nago.ndbinspector.messages.edit_acl",
+		i18n.Values{language.German: "Zugriffsliste bearbeiten", language.English: "Edit access control list"})
+	StrACLTitle = i18n.MustVarString("nago.ndbinspector.messages.acl_title",
+		i18n.Values{language.German: "Zugriffsliste von {type}", language.English: "Access control list of {type}"})
+	StrACLHint = i18n.MustString("nago.ndbinspector.messages.acl_hint",
+		i18n.Values{
+			language.German:  "Kommagetrennte Liste je Operation, z.B. user:<id>, token:<id>, group:<id>, role:<id> oder authenticated. Ohne Einträge wird die Zugriffsliste entfernt und jeder geschützte Zugriff verweigert.",
+			language.English: "Comma separated list per operation, e.g. user:<id>, token:<id>, group:<id>, role:<id> or authenticated. Without any entry the list is removed and every guarded access is denied.",
+		})
+
 	// message stat row: "Seq {min}–{max}{pending} · {count} Nachrichten · {segments} Segmente · {size}"
 	StrMsgStatRow = i18n.MustVarString("nago.ndbinspector.messages.stat_row",
 		i18n.Values{
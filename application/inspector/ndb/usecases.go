@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"slices"
 
+	"github.com/worldiety/option"
 	"go.wdy.de/nago/auth"
 	"go.wdy.de/nago/pkg/ndb"
 	"go.wdy.de/nago/pkg/ndb/msgstore"
@@ -60,6 +61,7 @@ type TypeInfo struct {
 	MinSeq     ndb.Seq
 	MaxSeq     ndb.Seq
 	HasPending bool
+	HasACL     bool
 }
 
 func (t TypeInfo) Identity() string { return string(t.Type) }
@@ -190,6 +192,10 @@ func (uc UseCases) Types(subject auth.Subject, instancePath, engine string) ([]T
 	if err != nil {
 		return nil, err
 	}
+	withACL, err := db.ACLTypes()
+	if err != nil {
+		return nil, err
+	}
 	out := make([]TypeInfo, 0, len(types))
 	for _, t := range types {
 		st, err := db.TypeStat(t)
@@ -199,6 +205,7 @@ func (uc UseCases) Types(subject auth.Subject, instancePath, engine string) ([]T
 		out = append(out, TypeInfo{
 			Type: st.Type, Segments: st.Segments, Bytes: st.Bytes,
 			MinSeq: st.MinSeq, MaxSeq: st.MaxSeq, HasPending: st.HasPending,
+			HasACL: slices.Contains(withACL, t),
 		})
 	}
 	return out, nil
@@ -296,6 +303,37 @@ func (uc UseCases) DeleteType(subject auth.Subject, instancePath, engine string,
 	return db.DeleteType(typeID)
 }
 
+// ACL returns the access control list of a message stream, if one is declared.
+func (uc UseCases) ACL(subject auth.Subject, instancePath, engine string, typeID ndb.TypeID) (option.Opt[ndb.ACL], error) {
+	if err := subject.Audit(PermNDBInspector); err != nil {
+		return option.None[ndb.ACL](), err
+	}
+	db, err := uc.messages(instancePath, engine)
+	if err != nil {
+		return option.None[ndb.ACL](), err
+	}
+	return db.ACL(typeID)
+}
+
+// SetACL declares or replaces the access control list of a message stream. An
+// ACL without any principal is removed instead, so the stream falls back to
+// "no ACL declared", which denies every guarded access.
+func (uc UseCases) SetACL(subject auth.Subject, instancePath, engine string, typeID ndb.TypeID, acl ndb.ACL) error {
+	if err := subject.Audit(PermNDBInspector); err != nil {
+		return err
+	}
+	db, err := uc.messages(instancePath, engine)
+	if err != nil {
+		return err
+	}
+	for _, op := range ndb.Operations {
+		if len(acl.Principals(op)) > 0 {
+			return db.SetACL(typeID, acl)
+		}
+	}
+	return db.RemoveACL(typeID)
+}
+
 // RebuildTimeIndex rebuilds the time index of an engine. Knife tool; the caller
 // must ensure no concurrent writes.
 func (uc UseCases) RebuildTimeIndex(subject auth.Subject, instancePath, engine string) error {
@@ -0,0 +1,139 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package cfgldap
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"go.wdy.de/nago/application"
+	"go.wdy.de/nago/application/admin"
+	"go.wdy.de/nago/application/ldap"
+	uildap "go.wdy.de/nago/application/ldap/ui"
+	"go.wdy.de/nago/application/scheduler"
+	cfgscheduler "go.wdy.de/nago/application/scheduler/cfg"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/auth"
+	"go.wdy.de/nago/logging"
+	"go.wdy.de/nago/presentation/core"
+)
+
+// SyncJob is the scheduler, which applies the directory changes periodically.
+const SyncJob scheduler.ID = "nago.ldap.sync"
+
+// Management connects LDAP and Active Directory servers. Each directory is configured as a secret of type
+// [ldap.Settings], which must be shared with the group System. Users log in with their directory password
+// and users, groups and group memberships are synchronized periodically by a background job.
+type Management struct {
+	UseCases ldap.UseCases
+	Pages    uildap.Pages
+}
+
+func Enable(cfg *application.Configurator) (Management, error) {
+	management, ok := core.FromContext[Management](cfg.Context(), "")
+	if ok {
+		return management, nil
+	}
+
+	schedulers, err := cfgscheduler.Enable(cfg)
+	if err != nil {
+		return Management{}, err
+	}
+
+	secrets, err := cfg.SecretManagement()
+	if err != nil {
+		return Management{}, err
+	}
+
+	users, err := cfg.UserManagement()
+	if err != nil {
+		return Management{}, err
+	}
+
+	groups, err := cfg.GroupManagement()
+	if err != nil {
+		return Management{}, err
+	}
+
+	management = Management{
+		UseCases: ldap.NewUseCases(
+			cfg.Context(),
+			secrets.UseCases.FindGroupSecrets,
+			users.UseCases.FindAll,
+			users.UseCases.FindByMail,
+			users.UseCases.MergeSingleSignOnUser,
+			users.UseCases.UpdateAccountStatus,
+			users.UseCases.ListGroups,
+			users.UseCases.UpdateOtherGroups,
+			groups.UseCases.FindAll,
+			groups.UseCases.Upsert,
+			groups.UseCases.Delete,
+		),
+		Pages: uildap.Pages{
+			Sync: "admin/iam/ldap",
+		},
+	}
+
+	cfg.AddPasswordAuthenticator(management.UseCases.AuthenticateByPassword)
+
+	err = schedulers.UseCases.Configure(user.SU(), scheduler.Options{
+		ID:          SyncJob,
+		Name:        "LDAP Synchronisation",
+		Description: "Übernimmt Benutzer, Gruppen und Gruppenmitgliedschaften aus den LDAP Verzeichnissen.",
+		Kind:        scheduler.Schedule,
+		Defaults: scheduler.Settings{
+			StartDelay: time.Minute,
+			PauseTime:  time.Hour,
+		},
+		Runner: func(ctx context.Context) error {
+			plan, err := management.UseCases.PlanSync(user.SU())
+			if err != nil {
+				return err
+			}
+
+			if len(plan.Directories) == 0 {
+				logging.FromContext(ctx).Info("no ldap directories configured")
+				return nil
+			}
+
+			summary := plan.Summary()
+			logging.FromContext(ctx).Info("applying ldap changes", "directories", plan.Directories,
+				"create", summary[ldap.Create], "update", summary[ldap.Update], "enable", summary[ldap.Enable],
+				"disable", summary[ldap.Disable], "delete", summary[ldap.Delete])
+
+			return management.UseCases.ApplySync(user.SU(), plan)
+		},
+	})
+	if err != nil {
+		return Management{}, err
+	}
+
+	cfg.RootViewWithDecoration(management.Pages.Sync, func(wnd core.Window) core.View {
+		return uildap.PageSync(wnd, management.UseCases, schedulers.Pages.SchedulerDashboard, core.Values{"id": string(SyncJob)})
+	})
+
+	cfg.AddAdminCenterGroup(func(subject auth.Subject) admin.Group {
+		return admin.Group{
+			Title: "LDAP",
+			Entries: []admin.Card{
+				{
+					Title:      "Synchronisation",
+					Text:       "Vorschau der Änderungen aus den LDAP Verzeichnissen anzeigen und übernehmen.",
+					Target:     management.Pages.Sync,
+					Permission: ldap.PermSync,
+				},
+			},
+		}
+	})
+
+	cfg.AddContextValue(core.ContextValue("nago.ldap", management))
+
+	slog.Info("installed ldap management")
+	return management, nil
+}
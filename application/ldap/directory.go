@@ -0,0 +1,202 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package ldap
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"iter"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.wdy.de/nago/application/group"
+	"go.wdy.de/nago/application/secret"
+	"go.wdy.de/nago/application/user"
+	ldapclient "go.wdy.de/nago/pkg/ldap"
+)
+
+// pageSize stays below the default MaxPageSize of Active Directory.
+const pageSize = 500
+
+// accountDisable is the flag of the userAccountControl attribute of Active Directory for disabled accounts.
+const accountDisable = 0x2
+
+// directories returns the settings of all directories, which are shared with the system group.
+func directories(findSecrets secret.FindGroupSecrets) iter.Seq2[Settings, error] {
+	return func(yield func(Settings, error) bool) {
+		for sec, err := range findSecrets(user.SU(), group.System) {
+			if err != nil {
+				yield(Settings{}, err)
+				return
+			}
+
+			if cfg, ok := sec.Credentials.(Settings); ok {
+				if !yield(cfg, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// connect opens a connection, which is bound to the service account. Without a bind dn, the connection stays
+// anonymous.
+func connect(ctx context.Context, cfg Settings) (*ldapclient.Conn, error) {
+	tlsConfig, err := cfg.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	conn, err := ldapclient.Dial(ctx, cfg.URL, ldapclient.Options{TLSConfig: tlsConfig, StartTLS: cfg.StartTLS})
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.BindDN != "" {
+		if err := conn.Bind(cfg.BindDN, cfg.BindPassword); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("cannot bind service account: %w", err)
+		}
+	}
+
+	return conn, nil
+}
+
+// stableID returns a printable form of the identifier attribute. The binary identifiers of Active Directory
+// are hex encoded.
+func stableID(e ldapclient.Entry, attr string) string {
+	values := e.Raw(attr)
+	if len(values) == 0 {
+		return ""
+	}
+
+	if strings.EqualFold(attr, "objectGUID") || strings.EqualFold(attr, "objectSid") {
+		return hex.EncodeToString(values[0])
+	}
+
+	return string(values[0])
+}
+
+type directoryUser struct {
+	dn       string // normalized
+	identity user.SingleSignOnUser
+	disabled bool
+}
+
+type directoryGroup struct {
+	group   group.Group
+	members []string // normalized
+}
+
+type snapshot struct {
+	users  []directoryUser
+	groups []directoryGroup
+}
+
+func (s snapshot) groupsOf(usr directoryUser) []group.ID {
+	var res []group.ID
+	for _, g := range s.groups {
+		for _, m := range g.members {
+			if m == usr.dn {
+				res = append(res, g.group.ID)
+				break
+			}
+		}
+	}
+
+	return res
+}
+
+// fetch reads all users and groups from the directory. Note, that Active Directory returns at most 1500 values
+// of a multi-valued attribute, thus the members of larger groups are incomplete, because range retrieval is
+// not implemented.
+func fetch(ctx context.Context, cfg Settings) (snapshot, error) {
+	conn, err := connect(ctx, cfg)
+	if err != nil {
+		return snapshot{}, err
+	}
+
+	defer conn.Close()
+
+	entries, err := conn.Search(ldapclient.SearchRequest{
+		BaseDN:     cfg.BaseDN,
+		Scope:      ldapclient.ScopeWholeSubtree,
+		Filter:     cfg.userFilter(),
+		Attributes: []string{cfg.idAttribute(), cfg.mailAttribute(), cfg.firstnameAttribute(), cfg.lastnameAttribute(), "cn", "userAccountControl"},
+		PageSize:   pageSize,
+	})
+	if err != nil {
+		return snapshot{}, fmt.Errorf("cannot search users: %w", err)
+	}
+
+	var snap snapshot
+	for _, e := range entries {
+		id := stableID(e, cfg.idAttribute())
+		mail := user.NormalizeEmail(user.Email(e.Get(cfg.mailAttribute())))
+		if id == "" || !mail.Valid() {
+			slog.Warn("ignored ldap user without id or valid mail", "directory", cfg.Name, "dn", e.DN)
+			continue
+		}
+
+		uac, _ := strconv.ParseInt(e.Get("userAccountControl"), 10, 64)
+		snap.users = append(snap.users, directoryUser{
+			dn: ldapclient.NormalizeDN(e.DN),
+			identity: user.SingleSignOnUser{
+				ID:        NLSUserID(id),
+				Firstname: e.Get(cfg.firstnameAttribute()),
+				Lastname:  e.Get(cfg.lastnameAttribute()),
+				Name:      e.Get("cn"),
+				Email:     mail,
+			},
+			disabled: uac&accountDisable != 0,
+		})
+	}
+
+	if strings.TrimSpace(cfg.GroupFilter) == "" {
+		return snap, nil
+	}
+
+	entries, err = conn.Search(ldapclient.SearchRequest{
+		BaseDN:     cfg.BaseDN,
+		Scope:      ldapclient.ScopeWholeSubtree,
+		Filter:     cfg.GroupFilter,
+		Attributes: []string{cfg.idAttribute(), cfg.groupNameAttribute(), cfg.memberAttribute(), "description"},
+		PageSize:   pageSize,
+	})
+	if err != nil {
+		return snapshot{}, fmt.Errorf("cannot search groups: %w", err)
+	}
+
+	for _, e := range entries {
+		id := stableID(e, cfg.idAttribute())
+		if id == "" {
+			slog.Warn("ignored ldap group without id", "directory", cfg.Name, "dn", e.DN)
+			continue
+		}
+
+		g := directoryGroup{group: group.Group{
+			ID:          GroupID(id),
+			Name:        or(e.Get(cfg.groupNameAttribute()), e.DN),
+			Description: e.Get("description"),
+		}}
+
+		for _, m := range e.Values(cfg.memberAttribute()) {
+			g.members = append(g.members, ldapclient.NormalizeDN(m))
+		}
+
+		snap.groups = append(snap.groups, g)
+	}
+
+	return snap, nil
+}
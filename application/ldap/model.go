@@ -0,0 +1,111 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package ldap
+
+import (
+	"strings"
+	"time"
+
+	"go.wdy.de/nago/application/group"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/pkg/std"
+)
+
+// idPrefix marks the users and groups, which are owned by a directory. Users keep it as [user.NLSUserID]
+// and groups as part of their [group.ID].
+const idPrefix = "ldap:"
+
+const NoUsersErr NoUsersError = "the directories returned no users, refusing to disable all synchronized users"
+
+type NoUsersError string
+
+func (e NoUsersError) Error() string {
+	return string(e)
+}
+
+var loginErr = std.NewLocalizedError("Login nicht möglich", "Der Nutzer existiert nicht, das Konto ist deaktiviert oder das Passwort ist falsch.").WithError(user.InvalidCredentialsErr)
+
+// NLSUserID returns the external identity of a directory user with the given stable identifier.
+func NLSUserID(id string) user.NLSUserID {
+	return user.NLSUserID(idPrefix + id)
+}
+
+// GroupID returns the local group of a directory group with the given stable identifier.
+func GroupID(id string) group.ID {
+	return group.ID(idPrefix + id)
+}
+
+// Managed returns true, if the user is owned by a directory.
+func Managed(usr user.User) bool {
+	return strings.HasPrefix(string(usr.NLSUserID), idPrefix)
+}
+
+func managedGroup(id group.ID) bool {
+	return strings.HasPrefix(string(id), idPrefix)
+}
+
+type Operation string
+
+const (
+	Create Operation = "create"
+	Update Operation = "update"
+	// Enable updates the user and enables the local account again.
+	Enable  Operation = "enable"
+	Disable Operation = "disable"
+	Delete  Operation = "delete"
+)
+
+// UserChange describes what happens to a single user. An empty operation means, that only the group
+// memberships change.
+type UserChange struct {
+	Operation Operation
+	// User is empty, if the user is created.
+	User      user.ID
+	Identity  user.SingleSignOnUser
+	Directory string
+	// Details are human readable descriptions of the changed fields.
+	Details      []string
+	AddGroups    []group.ID
+	RemoveGroups []group.ID
+}
+
+type GroupChange struct {
+	Operation Operation
+	Group     group.Group
+	Directory string
+}
+
+// Plan is the difference between the directories and the local users and groups. It is the result of a dry
+// run and is applied as is, without asking the directories again.
+type Plan struct {
+	CreatedAt   time.Time
+	Directories []string
+	Groups      []GroupChange
+	Users       []UserChange
+	// GroupNames resolves all synchronized groups, which are referred to by the changes.
+	GroupNames map[group.ID]string
+}
+
+// Empty returns true, if nothing needs to be changed.
+func (p Plan) Empty() bool {
+	return len(p.Groups) == 0 && len(p.Users) == 0
+}
+
+// Summary counts the changes by operation, membership changes are counted per user.
+func (p Plan) Summary() map[Operation]int {
+	res := map[Operation]int{}
+	for _, g := range p.Groups {
+		res[g.Operation]++
+	}
+
+	for _, u := range p.Users {
+		res[u.Operation]++
+	}
+
+	return res
+}
@@ -0,0 +1,14 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package ldap
+
+import "go.wdy.de/nago/application/permission"
+
+var (
+	PermSync = permission.Declare[ApplySync]("nago.ldap.sync", "LDAP Verzeichnis synchronisieren", "Träger dieser Berechtigung können die Änderungen aus angebundenen LDAP Verzeichnissen einsehen und übernehmen.")
+)
@@ -0,0 +1,105 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package ldap
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"strings"
+
+	"github.com/worldiety/enum"
+	"go.wdy.de/nago/application/secret"
+)
+
+var _ = enum.Variant[secret.Credentials, Settings](enum.Rename[Settings]("nago.ldap.settings"))
+
+// Settings configure a single directory. They are kept as [secret.Credentials] and must be shared with the
+// system group to be synchronized and to be used for the password login. Multiple directories may exist
+// side by side, e.g. for several forests.
+type Settings struct {
+	Name               string   `json:"name" value:"Active Directory" label:"Name"`
+	URL                string   `json:"url" label:"Server URL" supportingText:"Z.B. ldaps://dc.example.com oder ldap://ldap.example.com. Ohne Port werden 636 bzw. 389 verwendet."`
+	StartTLS           bool     `json:"startTLS" label:"StartTLS" supportingText:"Eine ldap:// Verbindung vor der Anmeldung auf TLS umstellen. Ohne TLS werden Passwörter im Klartext übertragen."`
+	RootCA             string   `json:"rootCA" lines:"5" label:"CA Zertifikat" supportingText:"PEM-kodiertes Zertifikat der internen CA, falls der Server kein öffentlich vertrauenswürdiges Zertifikat verwendet."`
+	BindDN             string   `json:"bindDN" label:"Bind DN" supportingText:"Das Dienstkonto zum Lesen des Verzeichnisses, z.B. CN=nago,OU=Service,DC=example,DC=com."`
+	BindPassword       string   `json:"bindPassword" style:"secret" label:"Bind Passwort"`
+	BaseDN             string   `json:"baseDN" label:"Base DN" supportingText:"Unterhalb dieses Eintrags wird nach Benutzern und Gruppen gesucht, z.B. DC=example,DC=com."`
+	UserFilter         string   `json:"userFilter" value:"(&(objectClass=person)(mail=*))" label:"Benutzerfilter" supportingText:"Für Active Directory z.B. (&(objectCategory=person)(objectClass=user)(mail=*)). Benutzer ohne E-Mail-Adresse werden übersprungen."`
+	GroupFilter        string   `json:"groupFilter" value:"(objectClass=groupOfNames)" label:"Gruppenfilter" supportingText:"Für Active Directory z.B. (objectClass=group). Leer lassen, um keine Gruppen zu synchronisieren."`
+	IDAttribute        string   `json:"idAttribute" value:"entryUUID" label:"ID Attribut" supportingText:"Ein unveränderliches Attribut, für Active Directory objectGUID."`
+	MailAttribute      string   `json:"mailAttribute" value:"mail" label:"E-Mail Attribut"`
+	FirstnameAttribute string   `json:"firstnameAttribute" value:"givenName" label:"Vorname Attribut"`
+	LastnameAttribute  string   `json:"lastnameAttribute" value:"sn" label:"Nachname Attribut"`
+	GroupNameAttribute string   `json:"groupNameAttribute" value:"cn" label:"Gruppenname Attribut"`
+	MemberAttribute    string   `json:"memberAttribute" value:"member" label:"Mitglieder Attribut" supportingText:"Das Gruppenattribut mit den DNs der Mitglieder, z.B. member oder uniqueMember. Verschachtelte Gruppen werden nicht aufgelöst."`
+	_                  struct{} `credentialName:"LDAP / Active Directory" credentialDescription:"Anmeldung und Synchronisation von Benutzern und Gruppen aus einem LDAP Verzeichnis wie Active Directory oder OpenLDAP." credentialLogo:"https://www.openldap.org/favicon.ico"`
+}
+
+func (s Settings) GetName() string {
+	return s.Name
+}
+
+func (s Settings) Credentials() bool {
+	return true
+}
+
+func (s Settings) IsZero() bool {
+	return s.Name == "" && s.URL == "" && !s.StartTLS && s.RootCA == "" && s.BindDN == "" && s.BindPassword == "" &&
+		s.BaseDN == "" && s.UserFilter == "" && s.GroupFilter == "" && s.IDAttribute == "" && s.MailAttribute == "" &&
+		s.FirstnameAttribute == "" && s.LastnameAttribute == "" && s.GroupNameAttribute == "" && s.MemberAttribute == ""
+}
+
+func (s Settings) userFilter() string {
+	return or(s.UserFilter, "(&(objectClass=person)(mail=*))")
+}
+
+func (s Settings) idAttribute() string {
+	return or(s.IDAttribute, "entryUUID")
+}
+
+func (s Settings) mailAttribute() string {
+	return or(s.MailAttribute, "mail")
+}
+
+func (s Settings) firstnameAttribute() string {
+	return or(s.FirstnameAttribute, "givenName")
+}
+
+func (s Settings) lastnameAttribute() string {
+	return or(s.LastnameAttribute, "sn")
+}
+
+func (s Settings) groupNameAttribute() string {
+	return or(s.GroupNameAttribute, "cn")
+}
+
+func (s Settings) memberAttribute() string {
+	return or(s.MemberAttribute, "member")
+}
+
+func (s Settings) tlsConfig() (*tls.Config, error) {
+	if strings.TrimSpace(s.RootCA) == "" {
+		return nil, nil
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM([]byte(s.RootCA)) {
+		return nil, fmt.Errorf("the CA certificate of %s is not a valid PEM certificate", s.Name)
+	}
+
+	return &tls.Config{RootCAs: pool}, nil
+}
+
+func or(s, def string) string {
+	if s = strings.TrimSpace(s); s != "" {
+		return s
+	}
+
+	return def
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package ldap
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"go.wdy.de/nago/application/group"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/auth"
+)
+
+func NewApplySync(
+	mutex *sync.Mutex,
+	mergeUser user.MergeSingleSignOnUser,
+	updateStatus user.UpdateAccountStatus,
+	listGroups user.ListGroups,
+	updateGroups user.UpdateOtherGroups,
+	upsertGroup group.Upsert,
+	deleteGroup group.Delete,
+) ApplySync {
+	applyUser := func(c UserChange) error {
+		uid := c.User
+		switch c.Operation {
+		case Create, Update, Enable:
+			id, err := mergeUser(c.Identity, nil)
+			if err != nil {
+				return err
+			}
+
+			uid = id
+			if c.Operation == Enable {
+				if err := updateStatus(user.SU(), uid, user.Enabled{}); err != nil {
+					return fmt.Errorf("cannot enable user: %w", err)
+				}
+			}
+		case Disable:
+			if err := updateStatus(user.SU(), uid, user.Disabled{}); err != nil {
+				return fmt.Errorf("cannot disable user: %w", err)
+			}
+		}
+
+		if len(c.AddGroups) == 0 && len(c.RemoveGroups) == 0 {
+			return nil
+		}
+
+		// the memberships are read again, so that groups granted in the meantime are kept
+		var groups []group.ID
+		for gid, err := range listGroups(user.SU(), uid) {
+			if err != nil {
+				return fmt.Errorf("cannot list groups: %w", err)
+			}
+
+			if !slices.Contains(c.RemoveGroups, gid) {
+				groups = append(groups, gid)
+			}
+		}
+
+		for _, gid := range c.AddGroups {
+			if !slices.Contains(groups, gid) {
+				groups = append(groups, gid)
+			}
+		}
+
+		if err := updateGroups(user.SU(), uid, groups); err != nil {
+			return fmt.Errorf("cannot update groups: %w", err)
+		}
+
+		return nil
+	}
+
+	return func(subject auth.Subject, plan Plan) error {
+		if err := subject.Audit(PermSync); err != nil {
+			return err
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		var errs []error
+
+		// groups must exist before users can become members and are deleted after all memberships are gone
+		for _, c := range plan.Groups {
+			if c.Operation == Delete {
+				continue
+			}
+
+			if _, err := upsertGroup(user.SU(), c.Group); err != nil {
+				errs = append(errs, fmt.Errorf("cannot save group %s: %w", c.Group.Name, err))
+			}
+		}
+
+		for _, c := range plan.Users {
+			if err := applyUser(c); err != nil {
+				errs = append(errs, fmt.Errorf("cannot synchronize user %s: %w", c.Identity.Email, err))
+			}
+		}
+
+		for _, c := range plan.Groups {
+			if c.Operation != Delete {
+				continue
+			}
+
+			if err := deleteGroup(user.SU(), c.Group.ID); err != nil {
+				errs = append(errs, fmt.Errorf("cannot delete group %s: %w", c.Group.Name, err))
+			}
+		}
+
+		return errors.Join(errs...)
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package ldap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"go.wdy.de/nago/application/secret"
+	"go.wdy.de/nago/application/user"
+	ldapclient "go.wdy.de/nago/pkg/ldap"
+	"go.wdy.de/nago/pkg/std"
+)
+
+func NewAuthenticateByPassword(ctx context.Context, findSecrets secret.FindGroupSecrets, findUserByMail user.FindByMail) user.AuthenticateByPassword {
+	// bind looks the user up with the service account and binds with the found dn on the same connection. It
+	// returns false, if the directory does not know the user.
+	bind := func(cfg Settings, usr user.User, password user.Password) (bool, error) {
+		conn, err := connect(ctx, cfg)
+		if err != nil {
+			return false, err
+		}
+
+		defer conn.Close()
+
+		entries, err := conn.Search(ldapclient.SearchRequest{
+			BaseDN:     cfg.BaseDN,
+			Scope:      ldapclient.ScopeWholeSubtree,
+			Filter:     fmt.Sprintf("(&%s(%s=%s))", cfg.userFilter(), cfg.mailAttribute(), ldapclient.EscapeFilter(string(usr.Email))),
+			Attributes: []string{cfg.idAttribute()},
+		})
+		if err != nil {
+			return false, fmt.Errorf("cannot search user: %w", err)
+		}
+
+		for _, e := range entries {
+			// the mail address alone is not enough, it must be the same identity as synchronized
+			if NLSUserID(stableID(e, cfg.idAttribute())) != usr.NLSUserID {
+				continue
+			}
+
+			if err := conn.Bind(e.DN, string(password)); err != nil {
+				if ldapclient.IsCode(err, ldapclient.InvalidCredentials) {
+					return true, loginErr
+				}
+
+				return true, fmt.Errorf("cannot bind user: %w", err)
+			}
+
+			return true, nil
+		}
+
+		return false, nil
+	}
+
+	return func(email user.Email, password user.Password) (std.Option[user.User], error) {
+		optUsr, err := findUserByMail(user.SU(), email)
+		if err != nil {
+			return std.None[user.User](), err
+		}
+
+		// not our business, the local password of directory users is always empty
+		if optUsr.IsNone() || !Managed(optUsr.Unwrap()) {
+			return std.None[user.User](), nil
+		}
+
+		usr := optUsr.Unwrap()
+		if !usr.Enabled() || password == "" {
+			return std.None[user.User](), loginErr
+		}
+
+		for cfg, err := range directories(findSecrets) {
+			if err != nil {
+				return std.None[user.User](), fmt.Errorf("cannot find ldap settings: %w", err)
+			}
+
+			found, err := bind(cfg, usr, password)
+			if err != nil {
+				if !errors.Is(err, loginErr) {
+					slog.Error("ldap password authentication failed", "directory", cfg.Name, "user", usr.ID, "err", err.Error())
+				}
+
+				return std.None[user.User](), err
+			}
+
+			if found {
+				return optUsr, nil
+			}
+		}
+
+		return std.None[user.User](), loginErr
+	}
+}
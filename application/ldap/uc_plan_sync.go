@@ -0,0 +1,265 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package ldap
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.wdy.de/nago/application/group"
+	"go.wdy.de/nago/application/secret"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/auth"
+)
+
+func NewPlanSync(ctx context.Context, findSecrets secret.FindGroupSecrets, findAllUsers user.FindAll, listGroups user.ListGroups, findAllGroups group.FindAll) PlanSync {
+	return func(subject auth.Subject) (Plan, error) {
+		if err := subject.Audit(PermSync); err != nil {
+			return Plan{}, err
+		}
+
+		type sourcedUser struct {
+			directoryUser
+			directory string
+			groups    []group.ID
+		}
+
+		plan := Plan{CreatedAt: now(), GroupNames: map[group.ID]string{}}
+		var dirUsers []sourcedUser
+		var dirGroups []GroupChange
+		for cfg, err := range directories(findSecrets) {
+			if err != nil {
+				return Plan{}, fmt.Errorf("cannot find ldap settings: %w", err)
+			}
+
+			snap, err := fetch(ctx, cfg)
+			if err != nil {
+				return Plan{}, fmt.Errorf("cannot read directory %s: %w", cfg.Name, err)
+			}
+
+			plan.Directories = append(plan.Directories, cfg.Name)
+			for _, u := range snap.users {
+				dirUsers = append(dirUsers, sourcedUser{directoryUser: u, directory: cfg.Name, groups: snap.groupsOf(u)})
+			}
+
+			for _, g := range snap.groups {
+				dirGroups = append(dirGroups, GroupChange{Group: g.group, Directory: cfg.Name})
+				plan.GroupNames[g.group.ID] = g.group.Name
+			}
+		}
+
+		// without any directory, the last known state is kept
+		if len(plan.Directories) == 0 {
+			return plan, nil
+		}
+
+		localByNLS := map[user.NLSUserID]user.User{}
+		localByMail := map[user.Email]user.User{}
+		for usr, err := range findAllUsers(user.SU()) {
+			if err != nil {
+				return Plan{}, fmt.Errorf("cannot find users: %w", err)
+			}
+
+			if Managed(usr) {
+				localByNLS[usr.NLSUserID] = usr
+			}
+
+			localByMail[user.NormalizeEmail(usr.Email)] = usr
+		}
+
+		if len(dirUsers) == 0 && len(localByNLS) > 0 {
+			return Plan{}, NoUsersErr
+		}
+
+		localGroups := map[group.ID]group.Group{}
+		for g, err := range findAllGroups(user.SU()) {
+			if err != nil {
+				return Plan{}, fmt.Errorf("cannot find groups: %w", err)
+			}
+
+			if managedGroup(g.ID) {
+				localGroups[g.ID] = g
+				if _, ok := plan.GroupNames[g.ID]; !ok {
+					plan.GroupNames[g.ID] = g.Name
+				}
+			}
+		}
+
+		seenGroups := map[group.ID]bool{}
+		for _, c := range dirGroups {
+			seenGroups[c.Group.ID] = true
+			local, ok := localGroups[c.Group.ID]
+			switch {
+			case !ok:
+				c.Operation = Create
+			case local.Name != c.Group.Name || local.Description != c.Group.Description:
+				c.Operation = Update
+			default:
+				continue
+			}
+
+			plan.Groups = append(plan.Groups, c)
+		}
+
+		for _, g := range sortedBy(localGroups, func(g group.Group) string { return g.Name }) {
+			if !seenGroups[g.ID] {
+				plan.Groups = append(plan.Groups, GroupChange{Operation: Delete, Group: g})
+			}
+		}
+
+		managedGroupsOf := func(uid user.ID) ([]group.ID, error) {
+			var res []group.ID
+			for gid, err := range listGroups(user.SU(), uid) {
+				if err != nil {
+					return nil, fmt.Errorf("cannot list groups of %s: %w", uid, err)
+				}
+
+				if managedGroup(gid) {
+					res = append(res, gid)
+				}
+			}
+
+			return res, nil
+		}
+
+		seenUsers := map[user.NLSUserID]bool{}
+		for _, du := range dirUsers {
+			seenUsers[du.identity.ID] = true
+			change := UserChange{Identity: du.identity, Directory: du.directory}
+
+			local, ok := localByNLS[du.identity.ID]
+			if !ok {
+				local, ok = localByMail[du.identity.Email]
+			}
+
+			var current []group.ID
+			if ok {
+				change.User = local.ID
+				tmp, err := managedGroupsOf(local.ID)
+				if err != nil {
+					return Plan{}, err
+				}
+
+				current = tmp
+			}
+
+			desired := du.groups
+			switch {
+			case !ok && du.disabled:
+				// never create accounts, which cannot be used anyway
+				continue
+			case !ok:
+				change.Operation = Create
+			case du.disabled:
+				desired = nil
+				if local.Enabled() {
+					change.Operation = Disable
+					change.Details = append(change.Details, "im Verzeichnis deaktiviert")
+				}
+			default:
+				change.Details = diffUser(local, du.identity)
+				if !local.Enabled() {
+					change.Operation = Enable
+					change.Details = append(change.Details, "Konto wird aktiviert")
+				} else if len(change.Details) > 0 {
+					change.Operation = Update
+				}
+			}
+
+			change.AddGroups = without(desired, current)
+			change.RemoveGroups = without(current, desired)
+			if change.Operation == "" && len(change.AddGroups) == 0 && len(change.RemoveGroups) == 0 {
+				continue
+			}
+
+			plan.Users = append(plan.Users, change)
+		}
+
+		for _, local := range sortedBy(localByNLS, func(u user.User) string { return string(u.Email) }) {
+			if seenUsers[local.NLSUserID] {
+				continue
+			}
+
+			current, err := managedGroupsOf(local.ID)
+			if err != nil {
+				return Plan{}, err
+			}
+
+			change := UserChange{
+				User: local.ID,
+				Identity: user.SingleSignOnUser{
+					ID:        local.NLSUserID,
+					Firstname: local.Contact.Firstname,
+					Lastname:  local.Contact.Lastname,
+					Email:     local.Email,
+				},
+				Details:      []string{"nicht mehr im Verzeichnis"},
+				RemoveGroups: current,
+			}
+
+			if local.Enabled() {
+				change.Operation = Disable
+			} else if len(current) == 0 {
+				continue
+			}
+
+			plan.Users = append(plan.Users, change)
+		}
+
+		return plan, nil
+	}
+}
+
+// diffUser describes the fields, which the merge will change.
+func diffUser(local user.User, identity user.SingleSignOnUser) []string {
+	var res []string
+	if !Managed(local) {
+		res = append(res, "wird mit dem Verzeichnis verknüpft")
+	}
+
+	if !local.Email.Equals(identity.Email) {
+		res = append(res, fmt.Sprintf("E-Mail: %s → %s", local.Email, identity.Email))
+	}
+
+	if local.Contact.Firstname != identity.FirstName() {
+		res = append(res, fmt.Sprintf("Vorname: %s → %s", local.Contact.Firstname, identity.FirstName()))
+	}
+
+	if local.Contact.Lastname != identity.LastName() {
+		res = append(res, fmt.Sprintf("Nachname: %s → %s", local.Contact.Lastname, identity.LastName()))
+	}
+
+	return res
+}
+
+// without returns all elements of a, which are not contained in b.
+func without[T comparable](a, b []T) []T {
+	var res []T
+	for _, v := range a {
+		if !slices.Contains(b, v) && !slices.Contains(res, v) {
+			res = append(res, v)
+		}
+	}
+
+	return res
+}
+
+func sortedBy[K comparable, V any](m map[K]V, key func(V) string) []V {
+	res := make([]V, 0, len(m))
+	for _, v := range m {
+		res = append(res, v)
+	}
+
+	slices.SortFunc(res, func(a, b V) int {
+		return strings.Compare(key(a), key(b))
+	})
+
+	return res
+}
@@ -0,0 +1,189 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package uildap
+
+import (
+	"fmt"
+	"strings"
+
+	"go.wdy.de/nago/application/group"
+	"go.wdy.de/nago/application/ldap"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/presentation/core"
+	"go.wdy.de/nago/presentation/ui"
+	"go.wdy.de/nago/presentation/ui/alert"
+)
+
+// PageSync shows the changes of a dry run and applies them after a confirmation. The schedule is the page of
+// the background job, which applies the changes periodically without asking.
+func PageSync(wnd core.Window, uc ldap.UseCases, schedule core.NavigationPath, scheduleParams core.Values) core.View {
+	if !wnd.Subject().Valid() {
+		return alert.BannerError(user.InvalidSubjectErr)
+	}
+
+	if err := wnd.Subject().Audit(ldap.PermSync); err != nil {
+		return alert.BannerError(err)
+	}
+
+	plan := core.AutoState[ldap.Plan](wnd)
+	applyPresented := core.AutoState[bool](wnd)
+
+	loadPlan := func() {
+		p, err := uc.PlanSync(wnd.Subject())
+		if err != nil {
+			alert.ShowBannerError(wnd, err)
+			return
+		}
+
+		plan.Set(p)
+	}
+
+	return ui.VStack(
+		ui.H1("LDAP Synchronisation"),
+		ui.TextLayout(
+			ui.Text("Benutzer, Gruppen und Gruppenmitgliedschaften werden aus allen LDAP Verzeichnissen übernommen, deren Zugangsdaten mit der Gruppe System geteilt sind. Die Vorschau liest die Verzeichnisse, ohne etwas zu verändern. Der Hintergrundprozess übernimmt die Änderungen regelmäßig ohne Rückfrage."),
+		),
+		applyDialog(wnd, applyPresented, plan, uc),
+		ui.HStack(
+			ui.SecondaryButton(func() {
+				wnd.Navigation().ForwardTo(schedule, scheduleParams)
+			}).Title("Zeitplan"),
+			ui.SecondaryButton(loadPlan).Title("Vorschau laden"),
+			ui.PrimaryButton(func() {
+				applyPresented.Set(true)
+			}).Title("Änderungen übernehmen").Enabled(!plan.Get().CreatedAt.IsZero() && !plan.Get().Empty()),
+		).FullWidth().Alignment(ui.Trailing).Gap(ui.L8),
+		planView(plan.Get()),
+	).Alignment(ui.Leading).FullWidth().Gap(ui.L16)
+}
+
+func planView(plan ldap.Plan) core.View {
+	switch {
+	case plan.CreatedAt.IsZero():
+		return ui.Text("Noch keine Vorschau geladen")
+	case len(plan.Directories) == 0:
+		return ui.Text("Es sind keine LDAP Verzeichnisse für die Gruppe System freigegeben.")
+	case plan.Empty():
+		return ui.Text("Alle Benutzer und Gruppen sind auf dem Stand von " + strings.Join(plan.Directories, ", ") + ".")
+	}
+
+	var groupRows []ui.TTableRow
+	for _, c := range plan.Groups {
+		groupRows = append(groupRows, ui.TableRow(
+			ui.TableCell(ui.Text(operationStr(c.Operation))),
+			ui.TableCell(ui.Text(c.Group.Name)),
+			ui.TableCell(ui.Text(c.Group.Description)),
+			ui.TableCell(ui.Text(c.Directory)),
+		))
+	}
+
+	var userRows []ui.TTableRow
+	for _, c := range plan.Users {
+		userRows = append(userRows, ui.TableRow(
+			ui.TableCell(ui.Text(operationStr(c.Operation))),
+			ui.TableCell(ui.Text(string(c.Identity.Email))),
+			ui.TableCell(ui.Text(strings.TrimSpace(c.Identity.FirstName()+" "+c.Identity.LastName()))),
+			ui.TableCell(ui.Text(strings.Join(c.Details, "\n"))),
+			ui.TableCell(ui.Text(membershipStr(plan, c))),
+			ui.TableCell(ui.Text(c.Directory)),
+		))
+	}
+
+	summary := plan.Summary()
+	return ui.VStack(
+		ui.Text(fmt.Sprintf("Vorschau vom %s: %d neu, %d geändert, %d aktiviert, %d deaktiviert, %d gelöscht.",
+			plan.CreatedAt.Format("02.01.2006 15:04:05"), summary[ldap.Create], summary[ldap.Update],
+			summary[ldap.Enable], summary[ldap.Disable], summary[ldap.Delete])),
+		ui.IfFunc(len(groupRows) > 0, func() core.View {
+			return ui.VStack(
+				ui.H2("Gruppen"),
+				ui.Table(
+					ui.TableColumn(ui.Text("Aktion")),
+					ui.TableColumn(ui.Text("Name")),
+					ui.TableColumn(ui.Text("Beschreibung")),
+					ui.TableColumn(ui.Text("Verzeichnis")),
+				).Rows(groupRows...),
+			).Alignment(ui.Leading).FullWidth()
+		}),
+		ui.IfFunc(len(userRows) > 0, func() core.View {
+			return ui.VStack(
+				ui.H2("Benutzer"),
+				ui.Table(
+					ui.TableColumn(ui.Text("Aktion")),
+					ui.TableColumn(ui.Text("E-Mail")),
+					ui.TableColumn(ui.Text("Name")),
+					ui.TableColumn(ui.Text("Änderungen")),
+					ui.TableColumn(ui.Text("Gruppen")),
+					ui.TableColumn(ui.Text("Verzeichnis")),
+				).Rows(userRows...),
+			).Alignment(ui.Leading).FullWidth()
+		}),
+	).Alignment(ui.Leading).FullWidth().Gap(ui.L16)
+}
+
+func applyDialog(wnd core.Window, presented *core.State[bool], plan *core.State[ldap.Plan], uc ldap.UseCases) core.View {
+	if !presented.Get() {
+		return nil
+	}
+
+	return alert.Dialog(
+		"Änderungen übernehmen",
+		ui.Text("Sollen die Änderungen der Vorschau jetzt übernommen werden? Deaktivierte Benutzer werden sofort abgemeldet."),
+		presented,
+		alert.Cancel(nil),
+		alert.Apply(func() (close bool) {
+			err := uc.ApplySync(wnd.Subject(), plan.Get())
+			plan.Set(ldap.Plan{})
+			if err != nil {
+				alert.ShowBannerError(wnd, err)
+				return true
+			}
+
+			alert.ShowBannerMessage(wnd, alert.Message{Title: "LDAP Synchronisation", Message: "Die Änderungen wurden übernommen."})
+			return true
+		}),
+	)
+}
+
+func operationStr(op ldap.Operation) string {
+	switch op {
+	case ldap.Create:
+		return "Anlegen"
+	case ldap.Update:
+		return "Aktualisieren"
+	case ldap.Enable:
+		return "Aktivieren"
+	case ldap.Disable:
+		return "Deaktivieren"
+	case ldap.Delete:
+		return "Löschen"
+	default:
+		return "Mitgliedschaften"
+	}
+}
+
+func membershipStr(plan ldap.Plan, c ldap.UserChange) string {
+	name := func(id group.ID) string {
+		if n, ok := plan.GroupNames[id]; ok {
+			return n
+		}
+
+		return string(id)
+	}
+
+	var tmp []string
+	for _, id := range c.AddGroups {
+		tmp = append(tmp, "+ "+name(id))
+	}
+
+	for _, id := range c.RemoveGroups {
+		tmp = append(tmp, "− "+name(id))
+	}
+
+	return strings.Join(tmp, "\n")
+}
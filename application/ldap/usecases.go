@@ -0,0 +1,68 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+// Package ldap connects directories like Active Directory or OpenLDAP. Passwords of directory users are
+// verified by a bind against the directory and users, groups and direct group memberships are synchronized
+// into the local user and group management.
+//
+// The directory is authoritative for everything it owns: directory users are merged like single sign-on
+// users and thus never have a local password, users which disappear from the directory or are disabled
+// there are disabled locally and synchronized groups are deleted, if they disappear. Memberships in
+// groups, which are not owned by a directory, are never touched.
+package ldap
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.wdy.de/nago/application/group"
+	"go.wdy.de/nago/application/secret"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/auth"
+)
+
+// PlanSync reads all directories and returns the changes, which would be applied, without changing anything.
+// If the directories return no users at all, but synchronized users exist, [NoUsersErr] is returned to
+// protect against a misconfigured filter, which would otherwise disable everybody.
+type PlanSync func(subject auth.Subject) (Plan, error)
+
+// ApplySync applies the given plan. It continues on failures of individual changes and returns all of them
+// joined.
+type ApplySync func(subject auth.Subject, plan Plan) error
+
+type UseCases struct {
+	PlanSync  PlanSync
+	ApplySync ApplySync
+	// AuthenticateByPassword verifies the password of directory users by a bind. It returns none and no error
+	// for all other users, see [application.Configurator.AddPasswordAuthenticator].
+	AuthenticateByPassword user.AuthenticateByPassword
+}
+
+func NewUseCases(
+	ctx context.Context,
+	findSecrets secret.FindGroupSecrets,
+	findAllUsers user.FindAll,
+	findUserByMail user.FindByMail,
+	mergeUser user.MergeSingleSignOnUser,
+	updateStatus user.UpdateAccountStatus,
+	listGroups user.ListGroups,
+	updateGroups user.UpdateOtherGroups,
+	findAllGroups group.FindAll,
+	upsertGroup group.Upsert,
+	deleteGroup group.Delete,
+) UseCases {
+	var mutex sync.Mutex
+
+	return UseCases{
+		PlanSync:               NewPlanSync(ctx, findSecrets, findAllUsers, listGroups, findAllGroups),
+		ApplySync:              NewApplySync(&mutex, mergeUser, updateStatus, listGroups, updateGroups, upsertGroup, deleteGroup),
+		AuthenticateByPassword: NewAuthenticateByPassword(ctx, findSecrets, findUserByMail),
+	}
+}
+
+var now = time.Now
@@ -0,0 +1,294 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package ldap
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"slices"
+	"testing"
+
+	"github.com/worldiety/option"
+	"go.wdy.de/nago/application/group"
+	"go.wdy.de/nago/application/permission"
+	"go.wdy.de/nago/application/secret"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/auth"
+	ldapclient "go.wdy.de/nago/pkg/ldap"
+	"go.wdy.de/nago/pkg/ldap/ldaptest"
+)
+
+func entry(dn string, attrs ...string) ldapclient.Entry {
+	e := ldapclient.Entry{DN: dn}
+	for i := 0; i+1 < len(attrs); i += 2 {
+		e.Attributes = append(e.Attributes, ldapclient.Attribute{Name: attrs[i], Values: [][]byte{[]byte(attrs[i+1])}})
+	}
+
+	return e
+}
+
+var (
+	base    = entry("dc=example,dc=org", "objectClass", "domain")
+	service = entry("cn=nago,dc=example,dc=org", "objectClass", "person", "userPassword", "service-secret")
+	alice   = entry("uid=alice,ou=people,dc=example,dc=org", "objectClass", "person", "entryUUID", "u1", "mail", "Alice@example.org", "givenName", "Alice", "sn", "Liddell", "userPassword", "wonderland")
+	bob     = entry("uid=bob,ou=people,dc=example,dc=org", "objectClass", "person", "entryUUID", "u2", "mail", "bob@example.org", "givenName", "Bob", "sn", "Builder")
+	carol   = entry("uid=carol,ou=people,dc=example,dc=org", "objectClass", "person", "entryUUID", "u3", "mail", "carol@example.org", "userAccountControl", "514")
+	staff   = ldapclient.Entry{DN: "cn=staff,ou=groups,dc=example,dc=org", Attributes: []ldapclient.Attribute{
+		{Name: "objectClass", Values: [][]byte{[]byte("groupOfNames")}},
+		{Name: "entryUUID", Values: [][]byte{[]byte("g1")}},
+		{Name: "cn", Values: [][]byte{[]byte("staff")}},
+		{Name: "member", Values: [][]byte{[]byte("UID=alice, OU=people,DC=example,DC=org"), []byte("uid=bob,ou=people,dc=example,dc=org")}},
+	}}
+)
+
+// testEnv keeps the local users and groups in memory.
+type testEnv struct {
+	uc      UseCases
+	users   map[user.ID]user.User
+	groups  map[group.ID]group.Group
+	members map[user.ID][]group.ID
+}
+
+func newTestEnv(t *testing.T, srv *ldaptest.Server) *testEnv {
+	env := &testEnv{
+		users: map[user.ID]user.User{
+			"bob":    {ID: "bob", Email: "bob@example.org", Status: user.Enabled{}},
+			"dave":   {ID: "dave", Email: "dave@example.org", NLSUserID: NLSUserID("u4"), Status: user.Enabled{}},
+			"eve":    {ID: "eve", Email: "eve@example.org", Status: user.Enabled{}},
+			"mallet": {ID: "mallet", Email: "mallet@example.org", NLSUserID: "oidc:https://idp.example#mallet", Status: user.Enabled{}},
+		},
+		groups: map[group.ID]group.Group{
+			"local":       {ID: "local", Name: "local"},
+			GroupID("g9"): {ID: GroupID("g9"), Name: "former"},
+		},
+		members: map[user.ID][]group.ID{
+			"bob":  {"local"},
+			"dave": {GroupID("g9"), "local"},
+		},
+	}
+
+	cfg := Settings{
+		Name:         "corp",
+		URL:          srv.URL(),
+		BindDN:       service.DN,
+		BindPassword: "service-secret",
+		BaseDN:       "dc=example,dc=org",
+		GroupFilter:  "(objectClass=groupOfNames)",
+	}
+
+	findSecrets := func(subject auth.Subject, gid group.ID) iter.Seq2[secret.Secret, error] {
+		return func(yield func(secret.Secret, error) bool) {
+			yield(secret.Secret{ID: "corp", Groups: []group.ID{group.System}, Credentials: cfg}, nil)
+		}
+	}
+
+	findAllUsers := func(subject permission.Auditable) iter.Seq2[user.User, error] {
+		return func(yield func(user.User, error) bool) {
+			for _, u := range env.users {
+				if !yield(u, nil) {
+					return
+				}
+			}
+		}
+	}
+
+	findByMail := func(subject permission.Auditable, email user.Email) (option.Opt[user.User], error) {
+		for _, u := range env.users {
+			if u.Email.Equals(email) {
+				return option.Some(u), nil
+			}
+		}
+
+		return option.None[user.User](), nil
+	}
+
+	merge := func(u user.SingleSignOnUser, avatar []byte) (user.ID, error) {
+		usr := user.User{ID: user.ID(u.Email), Status: user.Enabled{}}
+		for _, other := range env.users {
+			if other.NLSUserID == u.ID || (other.NLSUserID == "" && other.Email.Equals(u.Email)) {
+				usr = other
+			}
+		}
+
+		usr.NLSUserID = u.ID
+		usr.Email = u.Email
+		usr.Contact.Firstname = u.FirstName()
+		usr.Contact.Lastname = u.LastName()
+		env.users[usr.ID] = usr
+		return usr.ID, nil
+	}
+
+	updateStatus := func(subject permission.Auditable, id user.ID, status user.AccountStatus) error {
+		usr := env.users[id]
+		usr.Status = status
+		env.users[id] = usr
+		return nil
+	}
+
+	listGroups := func(subject user.AuditableUser, uid user.ID) iter.Seq2[group.ID, error] {
+		return func(yield func(group.ID, error) bool) {
+			for _, gid := range env.members[uid] {
+				if !yield(gid, nil) {
+					return
+				}
+			}
+		}
+	}
+
+	updateGroups := func(subject user.AuditableUser, id user.ID, groups []group.ID) error {
+		env.members[id] = groups
+		return nil
+	}
+
+	findAllGroups := func(subject permission.Auditable) iter.Seq2[group.Group, error] {
+		return func(yield func(group.Group, error) bool) {
+			for _, g := range env.groups {
+				if !yield(g, nil) {
+					return
+				}
+			}
+		}
+	}
+
+	upsertGroup := func(subject permission.Auditable, g group.Group) (group.ID, error) {
+		env.groups[g.ID] = g
+		return g.ID, nil
+	}
+
+	deleteGroup := func(subject permission.Auditable, id group.ID) error {
+		delete(env.groups, id)
+		return nil
+	}
+
+	env.uc = NewUseCases(context.Background(), findSecrets, findAllUsers, findByMail, merge, updateStatus, listGroups, updateGroups, findAllGroups, upsertGroup, deleteGroup)
+	return env
+}
+
+func newTestServer(t *testing.T) *ldaptest.Server {
+	srv, err := ldaptest.NewServer(base, service, alice, bob, carol, staff)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = srv.Close() })
+	return srv
+}
+
+func findChange(plan Plan, mail user.Email) (UserChange, bool) {
+	for _, c := range plan.Users {
+		if c.Identity.Email.Equals(mail) {
+			return c, true
+		}
+	}
+
+	return UserChange{}, false
+}
+
+func TestSync(t *testing.T) {
+	srv := newTestServer(t)
+	env := newTestEnv(t, srv)
+
+	plan, err := env.uc.PlanSync(user.SU())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c, ok := findChange(plan, "alice@example.org"); !ok || c.Operation != Create || !slices.Equal(c.AddGroups, []group.ID{GroupID("g1")}) {
+		t.Fatalf("expected alice to be created as staff member: %+v", c)
+	}
+
+	if c, ok := findChange(plan, "bob@example.org"); !ok || c.Operation != Update || c.User != "bob" || len(c.AddGroups) != 1 {
+		t.Fatalf("expected the local account of bob to be linked: %+v", c)
+	}
+
+	if c, ok := findChange(plan, "dave@example.org"); !ok || c.Operation != Disable || !slices.Equal(c.RemoveGroups, []group.ID{GroupID("g9")}) {
+		t.Fatalf("expected vanished dave to be disabled: %+v", c)
+	}
+
+	for _, mail := range []user.Email{"carol@example.org", "eve@example.org", "mallet@example.org"} {
+		if _, ok := findChange(plan, mail); ok {
+			t.Fatalf("unexpected change for %s", mail)
+		}
+	}
+
+	if len(plan.Groups) != 2 || plan.Groups[0].Operation != Create || plan.Groups[1].Operation != Delete {
+		t.Fatalf("unexpected group changes: %+v", plan.Groups)
+	}
+
+	// the dry run must not change anything
+	if len(env.users) != 4 || len(env.groups) != 2 {
+		t.Fatal("plan must not apply changes")
+	}
+
+	if err := env.uc.ApplySync(user.SU(), plan); err != nil {
+		t.Fatal(err)
+	}
+
+	if env.users["dave"].Enabled() || !slices.Equal(env.members["dave"], []group.ID{"local"}) {
+		t.Fatal("expected dave to be disabled and removed from synchronized groups only")
+	}
+
+	if !slices.Equal(env.members["bob"], []group.ID{"local", GroupID("g1")}) || env.users["bob"].NLSUserID != NLSUserID("u2") {
+		t.Fatalf("unexpected memberships of bob: %v", env.members["bob"])
+	}
+
+	if _, ok := env.groups[GroupID("g9")]; ok || env.groups[GroupID("g1")].Name != "staff" {
+		t.Fatal("expected synchronized groups")
+	}
+
+	plan, err = env.uc.PlanSync(user.SU())
+	if err != nil || !plan.Empty() {
+		t.Fatalf("expected a stable state: %+v %v", plan, err)
+	}
+
+	// a broken filter must not disable everybody
+	srv.SetEntries(base, service)
+	if _, err := env.uc.PlanSync(user.SU()); !errors.Is(err, NoUsersErr) {
+		t.Fatalf("expected no users error, got %v", err)
+	}
+}
+
+func TestAuthenticateByPassword(t *testing.T) {
+	srv := newTestServer(t)
+	env := newTestEnv(t, srv)
+
+	plan, err := env.uc.PlanSync(user.SU())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := env.uc.ApplySync(user.SU(), plan); err != nil {
+		t.Fatal(err)
+	}
+
+	optUsr, err := env.uc.AuthenticateByPassword("alice@example.org", "wonderland")
+	if err != nil || optUsr.IsNone() || optUsr.Unwrap().NLSUserID != NLSUserID("u1") {
+		t.Fatalf("expected alice to be authenticated: %v", err)
+	}
+
+	if _, err := env.uc.AuthenticateByPassword("alice@example.org", "wrong"); !errors.Is(err, loginErr) {
+		t.Fatalf("expected login error, got %v", err)
+	}
+
+	if _, err := env.uc.AuthenticateByPassword("alice@example.org", ""); !errors.Is(err, loginErr) {
+		t.Fatalf("unauthenticated binds must be refused, got %v", err)
+	}
+
+	// local users are left to the next authenticator
+	if optUsr, err := env.uc.AuthenticateByPassword("eve@example.org", "whatever"); err != nil || optUsr.IsSome() {
+		t.Fatalf("expected none, got %v", err)
+	}
+
+	// another directory entry with the same mail must not be able to take over the account
+	impostor := entry("uid=alice2,ou=people,dc=example,dc=org", "objectClass", "person", "entryUUID", "u5", "mail", "alice@example.org", "userPassword", "impostor")
+	srv.SetEntries(base, service, impostor)
+	if _, err := env.uc.AuthenticateByPassword("alice@example.org", "impostor"); !errors.Is(err, loginErr) {
+		t.Fatalf("expected login error, got %v", err)
+	}
+}
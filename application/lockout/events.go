@@ -0,0 +1,30 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package lockout
+
+import "time"
+
+// Failed is published for each key of a failed attempt.
+type Failed struct {
+	Key      Key
+	Failures int
+	At       time.Time
+}
+
+// Locked is published, when a key reached its limit of failed attempts.
+type Locked struct {
+	Key      Key
+	Failures int
+	Until    time.Time
+}
+
+// Unlocked is published, when a lock has been removed manually. See [Unlock].
+type Unlocked struct {
+	Key Key
+	At  time.Time
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+// Package lockout protects credentials like passwords, API tokens and reset codes against brute-force attacks.
+// Failed attempts are counted per [Key], which is usually a user account or a remote ip address. Each failure
+// of an account doubles the delay until the next attempt is accepted and once a limit is reached, the key is
+// locked for a while. See [Settings] for the defaults.
+package lockout
+
+import (
+	"strings"
+	"time"
+
+	"go.wdy.de/nago/pkg/data"
+	"go.wdy.de/nago/pkg/std"
+)
+
+const TooManyAttemptsErr std.Error = "too many failed attempts"
+
+// Key identifies the subject of the counted attempts. Use [UserKey], [MailKey] or [IPKey] to create one.
+type Key string
+
+// UserKey returns the key of an existing user account.
+func UserKey(id string) Key {
+	return Key("user:" + id)
+}
+
+// MailKey returns the key of a login name, which does not belong to any account. Counting them as well
+// ensures, that an attacker cannot tell existing from unknown accounts.
+func MailKey(mail string) Key {
+	return Key("mail:" + strings.ToLower(strings.TrimSpace(mail)))
+}
+
+// IPKey returns the key of a remote address. If the address is unknown, the zero key is returned, which
+// is ignored by all use cases.
+func IPKey(addr string) Key {
+	if addr == "" {
+		return ""
+	}
+
+	return Key("ip:" + addr)
+}
+
+// IP returns true, if the key represents a remote address.
+func (k Key) IP() bool {
+	return strings.HasPrefix(string(k), "ip:")
+}
+
+// Attempts contains the failed attempts of a single key.
+type Attempts struct {
+	ID          Key       `json:"id"`
+	Failures    int       `json:"failures,omitempty"`
+	LastFailure time.Time `json:"lastFailure,omitzero"`
+	LockedUntil time.Time `json:"lockedUntil,omitzero"`
+}
+
+func (a Attempts) Identity() Key {
+	return a.ID
+}
+
+// Locked returns true, if the limit of failures has been reached and the lock has not yet expired.
+func (a Attempts) Locked(now time.Time) bool {
+	return now.Before(a.LockedUntil)
+}
+
+// current forgets failures which are older than the configured period and resets an expired lock.
+func (a Attempts) current(s Settings, now time.Time) Attempts {
+	if a.Locked(now) {
+		return a
+	}
+
+	if !a.LockedUntil.IsZero() || now.Sub(a.LastFailure) > s.resetAfter() {
+		return Attempts{ID: a.ID}
+	}
+
+	return a
+}
+
+// retryAt returns the earliest time at which the next attempt of the [Attempts.current] state is accepted.
+// The delay doubles with each failure, but remote addresses are only locked, because many users may share
+// a single address.
+func (a Attempts) retryAt(s Settings) time.Time {
+	if !a.LockedUntil.IsZero() {
+		return a.LockedUntil
+	}
+
+	if a.Failures == 0 || a.ID.IP() {
+		return time.Time{}
+	}
+
+	delay := s.delay()
+	for i := 1; i < a.Failures && delay < s.maxDelay(); i++ {
+		delay *= 2
+	}
+
+	return a.LastFailure.Add(min(delay, s.maxDelay()))
+}
+
+type Repository data.Repository[Attempts, Key]
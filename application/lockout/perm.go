@@ -0,0 +1,15 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package lockout
+
+import "go.wdy.de/nago/application/permission"
+
+var (
+	PermFindByKey = permission.Declare[FindByKey]("nago.lockout.find_by_key", "Fehlversuche anzeigen", "Träger dieser Berechtigung können die fehlgeschlagenen Anmeldeversuche und Sperren eines Kontos sehen.")
+	PermUnlock    = permission.Declare[Unlock]("nago.lockout.unlock", "Sperre aufheben", "Träger dieser Berechtigung können ein nach zu vielen Fehlversuchen gesperrtes Konto entsperren.")
+)
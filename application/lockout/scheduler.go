@@ -0,0 +1,43 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package lockout
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+type ScheduleOptions struct {
+	PurgeInterval time.Duration // default is 1 hour, within this interval forgotten attempts are removed
+}
+
+// StartScheduler starts a new scheduler instance, which invokes purge periodically until the context is done.
+func StartScheduler(ctx context.Context, opts ScheduleOptions, purge Purge) {
+	if opts.PurgeInterval == 0 {
+		opts.PurgeInterval = time.Hour
+	}
+
+	go func() {
+		slog.Info("lockout scheduler started")
+		ticker := time.NewTicker(opts.PurgeInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				slog.Info("lockout scheduler stopped")
+				return
+			case <-ticker.C:
+				if err := purge(); err != nil {
+					slog.Error("lockout scheduler cannot purge attempts", "err", err)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package lockout
+
+import (
+	"cmp"
+	"time"
+
+	"github.com/worldiety/enum"
+	"go.wdy.de/nago/application/settings"
+)
+
+var _ = enum.Variant[settings.GlobalSettings, Settings](
+	enum.Rename[Settings]("nago.lockout.settings"),
+)
+
+type Settings struct {
+	_ any `title:"Schutz vor Brute-Force-Angriffen" description:"Fehlgeschlagene Anmeldungen mit Kennwort, zweitem Faktor, API-Token oder Code zum Zurücksetzen des Kennworts werden je Konto und je IP-Adresse gezählt. Gesperrte Konten können in der Nutzerverwaltung entsperrt werden."`
+
+	Disabled bool `section:"Allgemein" json:"disabled" label:"Schutz deaktivieren" supportingText:"Fehlversuche werden weder gezählt noch verzögert. Dies wird nicht empfohlen."`
+
+	AccountLimit int           `section:"Sperre" json:"accountLimit,omitempty" label:"Fehlversuche je Konto" supportingText:"Nach so vielen Fehlversuchen wird das Konto vorübergehend gesperrt. Standard sind 5."`
+	IPLimit      int           `section:"Sperre" json:"ipLimit,omitempty" label:"Fehlversuche je IP-Adresse" supportingText:"Nach so vielen Fehlversuchen werden alle Anmeldungen von dieser IP-Adresse vorübergehend abgewiesen. Standard sind 50."`
+	LockDuration time.Duration `section:"Sperre" json:"lockDuration,omitempty" label:"Dauer der Sperre" supportingText:"Standard sind 15 Minuten."`
+	ResetAfter   time.Duration `section:"Sperre" json:"resetAfter,omitempty" label:"Fehlversuche vergessen nach" supportingText:"Liegt der letzte Fehlversuch länger zurück, beginnt die Zählung von vorn. Standard ist 1 Stunde."`
+
+	Delay    time.Duration `section:"Wartezeit" json:"delay,omitempty" label:"Wartezeit nach einem Fehlversuch" supportingText:"Die Wartezeit eines Kontos verdoppelt sich mit jedem weiteren Fehlversuch. Standard ist 1 Sekunde."`
+	MaxDelay time.Duration `section:"Wartezeit" json:"maxDelay,omitempty" label:"Maximale Wartezeit" supportingText:"Standard ist 1 Minute."`
+}
+
+func (s Settings) GlobalSettings() bool { return true }
+
+func (s Settings) limit(key Key) int {
+	if key.IP() {
+		return cmp.Or(max(s.IPLimit, 0), 50)
+	}
+
+	return cmp.Or(max(s.AccountLimit, 0), 5)
+}
+
+func (s Settings) lockDuration() time.Duration {
+	return cmp.Or(max(s.LockDuration, 0), 15*time.Minute)
+}
+
+func (s Settings) resetAfter() time.Duration {
+	return cmp.Or(max(s.ResetAfter, 0), time.Hour)
+}
+
+func (s Settings) delay() time.Duration {
+	return cmp.Or(max(s.Delay, 0), time.Second)
+}
+
+func (s Settings) maxDelay() time.Duration {
+	return cmp.Or(max(s.MaxDelay, 0), time.Minute)
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package lockout
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"go.wdy.de/nago/application/settings"
+	"go.wdy.de/nago/pkg/std"
+)
+
+var tooManyAttemptsErr = std.NewLocalizedError("Zu viele Fehlversuche", "Aus Sicherheitsgründen ist die Anmeldung vorübergehend nicht möglich. Bitte versuchen Sie es später erneut.").WithError(TooManyAttemptsErr)
+
+func NewCheck(mutex *sync.Mutex, repo Repository, loadGlobal settings.LoadGlobal) Check {
+	return func(keys ...Key) error {
+		cfg := settings.ReadGlobal[Settings](loadGlobal)
+		if cfg.Disabled {
+			return nil
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		now := time.Now()
+		for _, key := range keys {
+			if key == "" {
+				continue
+			}
+
+			attempts, err := load(repo, key)
+			if err != nil {
+				return err
+			}
+
+			if now.Before(attempts.current(cfg, now).retryAt(cfg)) {
+				return tooManyAttemptsErr
+			}
+		}
+
+		return nil
+	}
+}
+
+func load(repo Repository, key Key) (Attempts, error) {
+	optAttempts, err := repo.FindByID(key)
+	if err != nil {
+		return Attempts{}, fmt.Errorf("cannot find attempts: %w", err)
+	}
+
+	if optAttempts.IsNone() {
+		return Attempts{ID: key}, nil
+	}
+
+	return optAttempts.Unwrap(), nil
+}
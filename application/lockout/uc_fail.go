@@ -0,0 +1,70 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package lockout
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.wdy.de/nago/application/settings"
+	"go.wdy.de/nago/pkg/events"
+)
+
+func NewFail(mutex *sync.Mutex, bus events.Bus, repo Repository, loadGlobal settings.LoadGlobal) Fail {
+	return func(keys ...Key) error {
+		cfg := settings.ReadGlobal[Settings](loadGlobal)
+		if cfg.Disabled {
+			return nil
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		now := time.Now()
+		for _, key := range keys {
+			if key == "" {
+				continue
+			}
+
+			attempts, err := load(repo, key)
+			if err != nil {
+				return err
+			}
+
+			attempts = attempts.current(cfg, now)
+			if attempts.Locked(now) {
+				// security note: attempts during a lock must not extend it, otherwise an attacker could lock
+				// out a victim forever
+				continue
+			}
+
+			attempts.Failures++
+			attempts.LastFailure = now
+
+			locked := attempts.Failures >= cfg.limit(key)
+			if locked {
+				attempts.LockedUntil = now.Add(cfg.lockDuration())
+			}
+
+			if err := repo.Save(attempts); err != nil {
+				return fmt.Errorf("cannot save attempts: %w", err)
+			}
+
+			bus.Publish(Failed{Key: key, Failures: attempts.Failures, At: now})
+
+			if locked {
+				slog.Warn("locked after too many failed attempts", "key", key, "failures", attempts.Failures, "until", attempts.LockedUntil)
+				bus.Publish(Locked{Key: key, Failures: attempts.Failures, Until: attempts.LockedUntil})
+			}
+		}
+
+		return nil
+	}
+}
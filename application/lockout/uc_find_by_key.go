@@ -0,0 +1,36 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package lockout
+
+import (
+	"sync"
+	"time"
+
+	"go.wdy.de/nago/application/permission"
+	"go.wdy.de/nago/application/settings"
+)
+
+func NewFindByKey(mutex *sync.Mutex, repo Repository, loadGlobal settings.LoadGlobal) FindByKey {
+	return func(subject permission.Auditable, key Key) (Attempts, error) {
+		if err := subject.Audit(PermFindByKey); err != nil {
+			return Attempts{}, err
+		}
+
+		cfg := settings.ReadGlobal[Settings](loadGlobal)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		attempts, err := load(repo, key)
+		if err != nil {
+			return Attempts{}, err
+		}
+
+		return attempts.current(cfg, time.Now()), nil
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package lockout
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"go.wdy.de/nago/application/settings"
+)
+
+func NewPurge(mutex *sync.Mutex, repo Repository, loadGlobal settings.LoadGlobal) Purge {
+	return func() error {
+		cfg := settings.ReadGlobal[Settings](loadGlobal)
+		now := time.Now()
+
+		// collect first, because we must not modify the repository while iterating
+		var stale []Key
+		for attempts, err := range repo.All() {
+			if err != nil {
+				return fmt.Errorf("cannot iterate attempts: %w", err)
+			}
+
+			if attempts.current(cfg, now).Failures == 0 {
+				stale = append(stale, attempts.ID)
+			}
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		for _, key := range stale {
+			// double-check, the key may have failed again in the meantime
+			optAttempts, err := repo.FindByID(key)
+			if err != nil {
+				return fmt.Errorf("cannot find attempts: %w", err)
+			}
+
+			if optAttempts.IsNone() || optAttempts.Unwrap().current(cfg, now).Failures > 0 {
+				continue
+			}
+
+			if err := repo.DeleteByID(key); err != nil {
+				return fmt.Errorf("cannot delete attempts: %w", err)
+			}
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package lockout
+
+import (
+	"fmt"
+	"sync"
+)
+
+func NewSucceed(mutex *sync.Mutex, repo Repository) Succeed {
+	return func(keys ...Key) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		for _, key := range keys {
+			if key == "" {
+				continue
+			}
+
+			// the lookup avoids a write for each successful login
+			optAttempts, err := repo.FindByID(key)
+			if err != nil {
+				return fmt.Errorf("cannot find attempts: %w", err)
+			}
+
+			if optAttempts.IsNone() {
+				continue
+			}
+
+			if err := repo.DeleteByID(key); err != nil {
+				return fmt.Errorf("cannot delete attempts: %w", err)
+			}
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package lockout
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.wdy.de/nago/application/permission"
+	"go.wdy.de/nago/pkg/events"
+)
+
+func NewUnlock(mutex *sync.Mutex, bus events.Bus, repo Repository) Unlock {
+	return func(subject permission.Auditable, key Key) error {
+		if err := subject.Audit(PermUnlock); err != nil {
+			return err
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if err := repo.DeleteByID(key); err != nil {
+			return fmt.Errorf("cannot delete attempts: %w", err)
+		}
+
+		slog.Info("removed lock", "key", key)
+		bus.Publish(Unlocked{Key: key, At: time.Now()})
+
+		return nil
+	}
+}
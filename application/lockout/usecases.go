@@ -0,0 +1,60 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package lockout
+
+import (
+	"sync"
+
+	"go.wdy.de/nago/application/permission"
+	"go.wdy.de/nago/application/settings"
+	"go.wdy.de/nago/pkg/events"
+)
+
+// Check returns an error wrapping [TooManyAttemptsErr], if any of the given keys is locked or has to wait
+// until its delay is over. Invoke it before the credentials are verified. Zero keys are ignored.
+type Check func(keys ...Key) error
+
+// Fail records a failed attempt for each of the given keys. Zero keys are ignored.
+type Fail func(keys ...Key) error
+
+// Succeed forgets all failed attempts of the given keys. Usually, only the account key is passed, because
+// otherwise an attacker with a single valid account could reset the counter of its remote address.
+type Succeed func(keys ...Key) error
+
+// FindByKey returns the current attempts of the given key. See [PermFindByKey].
+type FindByKey func(subject permission.Auditable, key Key) (Attempts, error)
+
+// Unlock removes the lock and all failed attempts of the given key. See [PermUnlock].
+type Unlock func(subject permission.Auditable, key Key) error
+
+// Purge removes all attempts, which would be forgotten anyway, because they are neither locked nor have failed
+// within [Settings.ResetAfter]. Otherwise, the attempts of unknown mail and remote addresses would grow without
+// bound. See [StartScheduler].
+type Purge func() error
+
+type UseCases struct {
+	Check     Check
+	Fail      Fail
+	Succeed   Succeed
+	FindByKey FindByKey
+	Unlock    Unlock
+	Purge     Purge
+}
+
+func NewUseCases(bus events.Bus, repo Repository, loadGlobal settings.LoadGlobal) UseCases {
+	var mutex sync.Mutex
+
+	return UseCases{
+		Check:     NewCheck(&mutex, repo, loadGlobal),
+		Fail:      NewFail(&mutex, bus, repo, loadGlobal),
+		Succeed:   NewSucceed(&mutex, repo),
+		FindByKey: NewFindByKey(&mutex, repo, loadGlobal),
+		Unlock:    NewUnlock(&mutex, bus, repo),
+		Purge:     NewPurge(&mutex, repo, loadGlobal),
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package lockout
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.wdy.de/nago/application/permission"
+	"go.wdy.de/nago/application/settings"
+	"go.wdy.de/nago/pkg/blob/mem"
+	"go.wdy.de/nago/pkg/data/json"
+	"go.wdy.de/nago/pkg/events"
+)
+
+func newTestUseCases(cfg Settings) (UseCases, events.Bus) {
+	repo := json.NewSloppyJSONRepository[Attempts, Key](mem.NewBlobStore("lockout"))
+	loadGlobal := func(subject permission.Auditable, t reflect.Type) (settings.GlobalSettings, error) {
+		return cfg, nil
+	}
+
+	bus := events.NewEventBus()
+	return NewUseCases(bus, repo, loadGlobal), bus
+}
+
+func TestBackoff(t *testing.T) {
+	uc, _ := newTestUseCases(Settings{Delay: time.Hour})
+	alice := UserKey("alice")
+
+	if err := uc.Check(alice); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := uc.Fail(alice); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := uc.Check(alice); !errors.Is(err, TooManyAttemptsErr) {
+		t.Fatalf("expected backoff, got %v", err)
+	}
+
+	if err := uc.Check(UserKey("bob"), IPKey("")); err != nil {
+		t.Fatalf("other keys must not be affected: %v", err)
+	}
+
+	if err := uc.Succeed(alice); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := uc.Check(alice); err != nil {
+		t.Fatalf("expected reset after success: %v", err)
+	}
+}
+
+func TestLockAndUnlock(t *testing.T) {
+	uc, bus := newTestUseCases(Settings{AccountLimit: 3, Delay: time.Nanosecond})
+	alice := UserKey("alice")
+
+	locked := make(chan Locked, 1)
+	defer bus.Subscribe(func(evt any) {
+		locked <- evt.(Locked)
+	}, events.TypeFor[Locked]())()
+
+	for range 2 {
+		if err := uc.Fail(alice); err != nil {
+			t.Fatal(err)
+		}
+
+		time.Sleep(time.Millisecond)
+		if err := uc.Check(alice); err != nil {
+			t.Fatalf("expected no lock yet: %v", err)
+		}
+	}
+
+	if err := uc.Fail(alice); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(time.Millisecond)
+	if err := uc.Check(alice); !errors.Is(err, TooManyAttemptsErr) {
+		t.Fatalf("expected lock, got %v", err)
+	}
+
+	select {
+	case evt := <-locked:
+		if evt.Key != alice || evt.Failures != 3 {
+			t.Fatalf("unexpected event: %+v", evt)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected locked event")
+	}
+
+	// failures during a lock must not extend it
+	attempts, err := uc.FindByKey(permission.SU(), alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := uc.Fail(alice); err != nil {
+		t.Fatal(err)
+	}
+
+	extended, err := uc.FindByKey(permission.SU(), alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !extended.Locked(time.Now()) || extended != attempts {
+		t.Fatalf("expected unchanged lock: %+v vs %+v", attempts, extended)
+	}
+
+	if err := uc.Unlock(permission.SU(), alice); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := uc.Check(alice); err != nil {
+		t.Fatalf("expected unlocked: %v", err)
+	}
+}
+
+func TestRemoteAddress(t *testing.T) {
+	uc, _ := newTestUseCases(Settings{IPLimit: 2, Delay: time.Hour})
+	ip := IPKey("192.0.2.1")
+
+	if err := uc.Fail(ip); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := uc.Check(ip); err != nil {
+		t.Fatalf("remote addresses must not be delayed: %v", err)
+	}
+
+	if err := uc.Fail(ip); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := uc.Check(ip); !errors.Is(err, TooManyAttemptsErr) {
+		t.Fatalf("expected lock, got %v", err)
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	uc, _ := newTestUseCases(Settings{Disabled: true, AccountLimit: 1})
+	alice := UserKey("alice")
+
+	if err := uc.Fail(alice); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := uc.Check(alice); err != nil {
+		t.Fatalf("expected no lock: %v", err)
+	}
+}
+
+func TestPurge(t *testing.T) {
+	repo := json.NewSloppyJSONRepository[Attempts, Key](mem.NewBlobStore("lockout"))
+	loadGlobal := func(subject permission.Auditable, t reflect.Type) (settings.GlobalSettings, error) {
+		return Settings{AccountLimit: 2, ResetAfter: time.Millisecond, LockDuration: time.Hour}, nil
+	}
+
+	uc := NewUseCases(events.NewEventBus(), repo, loadGlobal)
+	alice := UserKey("alice")
+	unknown := MailKey("unknown@example.com")
+
+	if err := uc.Fail(alice, unknown); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := uc.Fail(alice); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	if err := uc.Purge(); err != nil {
+		t.Fatal(err)
+	}
+
+	if optAttempts, err := repo.FindByID(unknown); err != nil || optAttempts.IsSome() {
+		t.Fatalf("expected forgotten attempts to be purged: %v %v", optAttempts, err)
+	}
+
+	if optAttempts, err := repo.FindByID(alice); err != nil || optAttempts.IsNone() {
+		t.Fatalf("expected lock to be kept: %v %v", optAttempts, err)
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package mail
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dkimHeaders are the header fields which are signed, if present. From is mandatory for DKIM.
+var dkimHeaders = []string{"From", "To", "Cc", "Subject", "Date", "Message-ID", "MIME-Version", "Content-Type", "List-Unsubscribe", "List-Unsubscribe-Post"}
+
+// dkimSigner signs complete RFC 5322 messages using the relaxed/relaxed canonicalization of RFC 6376.
+type dkimSigner struct {
+	domain   string
+	selector string
+	key      crypto.Signer
+}
+
+func newDKIMSigner(domain, selector, privateKey string) (dkimSigner, error) {
+	key, err := parseDKIMKey(privateKey)
+	if err != nil {
+		return dkimSigner{}, err
+	}
+
+	return dkimSigner{domain: domain, selector: selector, key: key}, nil
+}
+
+var pemArmor = regexp.MustCompile(`-----(BEGIN|END)[A-Z ]*-----`)
+
+// parseDKIMKey accepts PKCS#1 and PKCS#8 keys in PEM format. Because the key is entered into a single line
+// secret field, line breaks may have been replaced, thus the armor is just stripped instead of decoded strictly.
+func parseDKIMKey(str string) (crypto.Signer, error) {
+	b64 := strings.Join(strings.Fields(pemArmor.ReplaceAllString(str, "")), "")
+	der, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dkim key encoding: %w", err)
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dkim key: %w", err)
+	}
+
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		return key, nil
+	case ed25519.PrivateKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported dkim key type %T", key)
+	}
+}
+
+// sign returns the DKIM-Signature header line including the trailing CRLF, which must be prepended to the msg.
+func (s dkimSigner) sign(msg []byte, now time.Time) (string, error) {
+	header, body, ok := bytes.Cut(msg, []byte("\r\n\r\n"))
+	if !ok {
+		return "", fmt.Errorf("message has no body")
+	}
+
+	fields := splitHeaderFields(string(header) + "\r\n")
+
+	bodyHash := sha256.Sum256(relaxedBody(body))
+
+	var names []string
+	var signed strings.Builder
+	for _, name := range dkimHeaders {
+		// as recommended by RFC 6376, the last occurrence is signed first
+		for i := len(fields) - 1; i >= 0; i-- {
+			if strings.EqualFold(fields[i].name, name) {
+				names = append(names, strings.ToLower(name))
+				signed.WriteString(relaxedHeader(fields[i].name, fields[i].value))
+				signed.WriteString("\r\n")
+				break
+			}
+		}
+	}
+
+	algorithm := "rsa-sha256"
+	if _, ok := s.key.(ed25519.PrivateKey); ok {
+		algorithm = "ed25519-sha256"
+	}
+
+	value := "v=1; a=" + algorithm + "; c=relaxed/relaxed; d=" + s.domain + "; s=" + s.selector +
+		"; t=" + strconv.FormatInt(now.Unix(), 10) + "; h=" + strings.Join(names, ":") +
+		"; bh=" + base64.StdEncoding.EncodeToString(bodyHash[:]) + "; b="
+
+	// the signature header itself is signed without its trailing CRLF and with an empty b= tag
+	signed.WriteString(relaxedHeader("DKIM-Signature", value))
+	digest := sha256.Sum256([]byte(signed.String()))
+
+	var sig []byte
+	var err error
+	if _, ok := s.key.(ed25519.PrivateKey); ok {
+		sig, err = s.key.Sign(rand.Reader, digest[:], crypto.Hash(0))
+	} else {
+		sig, err = s.key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("cannot sign dkim: %w", err)
+	}
+
+	// whitespace within the b= tag is ignored by verifiers, which allows to fold the long signature
+	b := base64.StdEncoding.EncodeToString(sig)
+	var chunks []string
+	for len(b) > 64 {
+		chunks = append(chunks, b[:64])
+		b = b[64:]
+	}
+
+	chunks = append(chunks, b)
+
+	return foldHeader("DKIM-Signature", value+strings.Join(chunks, " ")) + "\r\n", nil
+}
+
+type headerField struct {
+	name  string
+	value string // unparsed and possibly folded
+}
+
+func splitHeaderFields(header string) []headerField {
+	var res []headerField
+	for _, line := range strings.SplitAfter(header, "\r\n") {
+		if line == "" {
+			continue
+		}
+
+		if (line[0] == ' ' || line[0] == '\t') && len(res) > 0 {
+			res[len(res)-1].value += line
+			continue
+		}
+
+		name, value, _ := strings.Cut(line, ":")
+		res = append(res, headerField{name: name, value: value})
+	}
+
+	for i := range res {
+		res[i].value = strings.TrimSuffix(res[i].value, "\r\n")
+	}
+
+	return res
+}
+
+// relaxedHeader implements the relaxed header canonicalization of RFC 6376 section 3.4.2.
+func relaxedHeader(name, value string) string {
+	value = strings.ReplaceAll(value, "\r\n", "")
+	return strings.ToLower(strings.TrimSpace(name)) + ":" + strings.Join(strings.Fields(value), " ")
+}
+
+// relaxedBody implements the relaxed body canonicalization of RFC 6376 section 3.4.4.
+func relaxedBody(body []byte) []byte {
+	lines := strings.Split(string(body), "\r\n")
+	for i, line := range lines {
+		line = strings.TrimRight(line, " \t")
+		lines[i] = strings.Join(strings.FieldsFunc(line, func(r rune) bool { return r == ' ' || r == '\t' }), " ")
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') {
+			// leading whitespace is reduced to a single space but not removed
+			lines[i] = " " + lines[i]
+		}
+	}
+
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	if len(lines) == 0 {
+		return nil
+	}
+
+	return []byte(strings.Join(lines, "\r\n") + "\r\n")
+}
@@ -0,0 +1,264 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package mail
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"go.wdy.de/nago/application/secret"
+)
+
+// verifyDKIM is an independent minimal verifier for relaxed/relaxed signatures.
+func verifyDKIM(t *testing.T, msg []byte, pub crypto.PublicKey) {
+	t.Helper()
+
+	header, body, _ := bytes.Cut(msg, []byte("\r\n\r\n"))
+	fields := splitHeaderFields(string(header) + "\r\n")
+	if !strings.EqualFold(fields[0].name, "DKIM-Signature") {
+		t.Fatalf("expected signature header first but got %s", fields[0].name)
+	}
+
+	tags := map[string]string{}
+	for _, tag := range strings.Split(fields[0].value, ";") {
+		k, v, _ := strings.Cut(tag, "=")
+		tags[strings.TrimSpace(k)] = strings.Join(strings.Fields(v), "")
+	}
+
+	bh := sha256.Sum256(relaxedBody(body))
+	if tags["bh"] != base64.StdEncoding.EncodeToString(bh[:]) {
+		t.Fatal("body hash mismatch")
+	}
+
+	var signed strings.Builder
+	for _, name := range strings.Split(tags["h"], ":") {
+		for i := len(fields) - 1; i > 0; i-- {
+			if strings.EqualFold(fields[i].name, name) {
+				signed.WriteString(relaxedHeader(fields[i].name, fields[i].value) + "\r\n")
+				break
+			}
+		}
+	}
+
+	withoutB := regexp.MustCompile(`b=[^;]*$`).ReplaceAllString(fields[0].value, "b=")
+	signed.WriteString(relaxedHeader(fields[0].name, withoutB))
+	digest := sha256.Sum256([]byte(signed.String()))
+
+	sig, err := base64.StdEncoding.DecodeString(tags["b"])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig); err != nil {
+			t.Fatal(err)
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(pub, digest[:], sig) {
+			t.Fatal("invalid ed25519 signature")
+		}
+	}
+}
+
+func TestComposeDKIM(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(edKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		pem  string
+		pub  crypto.PublicKey
+	}{
+		{"rsa pkcs1", string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)})), &rsaKey.PublicKey},
+		// line breaks are lost when pasted into a single line field
+		{"ed25519 pkcs8", strings.ReplaceAll(string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8})), "\n", " "), edPub},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := testMail("alice@example.com")
+			m.From = mail.Address{Name: "Nago", Address: "nago@example.com"}
+			m.MessageID = "1234@example.com"
+			m.Unsubscribe = []string{"https://example.com/unsubscribe?id=1234", "mailto:unsubscribe@example.com"}
+			m.UnsubscribeOneClick = true
+
+			msg, err := compose(secret.SMTP{DKIMDomain: "example.com", DKIMSelector: "nago", DKIMPrivateKey: tt.pem}, m, time.Now())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			verifyDKIM(t, msg, tt.pub)
+
+			parsed, err := mail.ReadMessage(bytes.NewReader(msg))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := parsed.Header.Get("List-Unsubscribe-Post"); got != "List-Unsubscribe=One-Click" {
+				t.Fatalf("unexpected List-Unsubscribe-Post: %s", got)
+			}
+		})
+	}
+}
+
+func TestComposeEncodedHeaders(t *testing.T) {
+	m := testMail("alice@example.com")
+	m.From = mail.Address{Name: "Jürgen Müller", Address: "nago@example.com"}
+	m.Subject = "Ihre Bestätigung für die Übermittlung – bitte prüfen Sie alle Angaben sorgfältig, bevor Sie fortfahren"
+
+	msg, err := compose(secret.SMTP{}, m, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header, _, _ := bytes.Cut(msg, []byte("\r\n\r\n"))
+	for _, line := range strings.Split(string(header), "\r\n") {
+		if len(line) > 78 {
+			t.Fatalf("header line not folded: %q", line)
+		}
+
+		for _, r := range line {
+			if r > 127 {
+				t.Fatalf("header line not encoded: %q", line)
+			}
+		}
+	}
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(msg))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if subject != m.Subject {
+		t.Fatalf("unexpected subject: %q", subject)
+	}
+
+	from, err := parsed.Header.AddressList("From")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if from[0].Name != "Jürgen Müller" {
+		t.Fatalf("unexpected from: %v", from[0])
+	}
+}
+
+func TestComposeInline(t *testing.T) {
+	m := testMail("alice@example.com")
+	m.Parts = []Part{
+		NewTextPart("hello"),
+		NewHtmlPart(`<img src="cid:logo">`),
+		NewInlinePart("logo", "logo.png", []byte("\x89PNG\r\n\x1a\n")),
+		NewAttachmentPart("Übersicht.pdf", []byte("%PDF")),
+	}
+
+	msg, err := compose(secret.SMTP{}, m, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(msg))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var types []string
+	var related *multipart.Reader
+	var filename string
+	mixed := multipart.NewReader(parsed.Body, params["boundary"])
+	for {
+		part, err := mixed.NextPart()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		mediaType, params, err := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		types = append(types, mediaType)
+		if mediaType == "multipart/related" {
+			buf, _ := io.ReadAll(part)
+			related = multipart.NewReader(bytes.NewReader(buf), params["boundary"])
+		}
+
+		if part.FileName() != "" {
+			filename = part.FileName()
+		}
+	}
+
+	if strings.Join(types, ",") != "text/plain,multipart/related,application/octet-stream" {
+		t.Fatalf("unexpected structure: %v", types)
+	}
+
+	if filename != "Übersicht.pdf" {
+		t.Fatalf("unexpected filename: %q", filename)
+	}
+
+	var relatedTypes []string
+	for {
+		part, err := related.NextPart()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		mediaType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		relatedTypes = append(relatedTypes, mediaType+" "+part.Header.Get("Content-Id"))
+	}
+
+	if strings.Join(relatedTypes, ",") != "text/html ,image/png <logo>" {
+		t.Fatalf("unexpected related structure: %v", relatedTypes)
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package mail
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"strings"
+
+	"go.wdy.de/nago/pkg/std"
+)
+
+const NotADeliveryReportErr std.Error = "not a delivery status notification"
+
+// UnknownBounceErr is returned by [ProcessBounce], if a delivery status notification does not refer to a
+// mail which has been sent by this application.
+const UnknownBounceErr std.Error = "delivery status notification does not refer to a sent mail"
+
+// Bounce is a single recipient entry of a delivery status notification, see RFC 3464.
+type Bounce struct {
+	Recipient  string // the final recipient address
+	Action     string // failed, delayed, delivered, relayed or expanded
+	Status     string // the enhanced status code like 5.1.1
+	Diagnostic string // the optional reply of the remote server
+
+	// MessageID of the original mail without angle brackets. It is empty, if the report does not include the
+	// original mail or at least its headers.
+	MessageID string
+}
+
+// Permanent returns true, if the delivery has failed and will never succeed.
+func (b Bounce) Permanent() bool {
+	return b.Action == "failed" && !strings.HasPrefix(b.Status, "4")
+}
+
+// ParseDSN reads a raw mail in RFC 5322 format and returns the recipient entries of its delivery status
+// report. If the mail is not a multipart/report of type delivery-status, [NotADeliveryReportErr] is returned.
+// The Message-ID of the original mail is taken from the returned message or headers part of the report.
+func ParseDSN(r io.Reader) ([]Bounce, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read mail: %w", err)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/report" || !strings.EqualFold(params["report-type"], "delivery-status") {
+		return nil, NotADeliveryReportErr
+	}
+
+	var bounces []Bounce
+	var hasStatus bool
+	var messageID string
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := parts.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("cannot read report part: %w", err)
+		}
+
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		switch partType {
+		case "message/delivery-status", "message/global-delivery-status":
+			bounces, err = parseDeliveryStatus(part)
+			if err != nil {
+				return nil, err
+			}
+
+			hasStatus = true
+		case "message/rfc822", "message/global", "text/rfc822-headers", "message/global-headers":
+			// a truncated header is fine, as long as the Message-ID has been read
+			header, _ := textproto.NewReader(bufio.NewReader(part)).ReadMIMEHeader()
+			messageID = strings.Trim(strings.TrimSpace(header.Get("Message-Id")), "<>")
+		}
+	}
+
+	if !hasStatus {
+		return nil, NotADeliveryReportErr
+	}
+
+	for i := range bounces {
+		bounces[i].MessageID = messageID
+	}
+
+	return bounces, nil
+}
+
+// parseDeliveryStatus reads the per-message block followed by the per-recipient blocks, which are all
+// separated by blank lines.
+func parseDeliveryStatus(r io.Reader) ([]Bounce, error) {
+	reader := textproto.NewReader(bufio.NewReader(r))
+
+	var res []Bounce
+	for {
+		header, err := reader.ReadMIMEHeader()
+		if rcpt := header.Get("Final-Recipient"); rcpt != "" {
+			res = append(res, Bounce{
+				Recipient:  fieldValue(rcpt),
+				Action:     strings.ToLower(strings.TrimSpace(header.Get("Action"))),
+				Status:     strings.TrimSpace(header.Get("Status")),
+				Diagnostic: strings.TrimSpace(fieldValue(header.Get("Diagnostic-Code"))),
+			})
+		}
+
+		if errors.Is(err, io.EOF) {
+			return res, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse delivery status: %w", err)
+		}
+	}
+}
+
+// fieldValue removes the type prefix like in "rfc822; alice@example.org".
+func fieldValue(field string) string {
+	if _, value, ok := strings.Cut(field, ";"); ok {
+		return strings.TrimSpace(value)
+	}
+
+	return strings.TrimSpace(field)
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package mail
+
+import (
+	"errors"
+	"net/mail"
+	"strings"
+	"testing"
+
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/pkg/blob/mem"
+	"go.wdy.de/nago/pkg/data/json"
+	"go.wdy.de/nago/pkg/events/eventstest"
+)
+
+const testDSN = "From: Mail Delivery System <MAILER-DAEMON@example.com>\r\n" +
+	"To: nago@example.com\r\n" +
+	"Subject: Undelivered Mail Returned to Sender\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/report; report-type=delivery-status; boundary=\"B0UND\"\r\n" +
+	"\r\n" +
+	"--B0UND\r\n" +
+	"Content-Type: text/plain\r\n" +
+	"\r\n" +
+	"Your message could not be delivered.\r\n" +
+	"--B0UND\r\n" +
+	"Content-Type: message/delivery-status\r\n" +
+	"\r\n" +
+	"Reporting-MTA: dns; mx.example.com\r\n" +
+	"Arrival-Date: Thu, 1 Jan 2026 12:00:00 +0100\r\n" +
+	"\r\n" +
+	"Final-Recipient: rfc822; bob@example.com\r\n" +
+	"Action: failed\r\n" +
+	"Status: 5.1.1\r\n" +
+	"Diagnostic-Code: smtp; 550 5.1.1 user unknown\r\n" +
+	"\r\n" +
+	"Final-Recipient: rfc822; carol@example.com\r\n" +
+	"Action: delayed\r\n" +
+	"Status: 4.4.1\r\n" +
+	"\r\n" +
+	"--B0UND\r\n" +
+	"Content-Type: text/rfc822-headers\r\n" +
+	"\r\n" +
+	"From: nago@example.com\r\n" +
+	"To: bob@example.com, carol@example.com\r\n" +
+	"Message-ID: <abc@nago.example.com>\r\n" +
+	"\r\n" +
+	"--B0UND--\r\n"
+
+func TestParseDSN(t *testing.T) {
+	bounces, err := ParseDSN(strings.NewReader(testDSN))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(bounces) != 2 {
+		t.Fatalf("expected 2 bounces but got %d", len(bounces))
+	}
+
+	bob := bounces[0]
+	if bob.Recipient != "bob@example.com" || bob.Status != "5.1.1" || bob.Diagnostic != "550 5.1.1 user unknown" || !bob.Permanent() || bob.MessageID != "abc@nago.example.com" {
+		t.Fatalf("unexpected bounce: %+v", bob)
+	}
+
+	if carol := bounces[1]; carol.Recipient != "carol@example.com" || carol.Permanent() {
+		t.Fatalf("unexpected bounce: %+v", carol)
+	}
+}
+
+func TestParseDSNNoReport(t *testing.T) {
+	_, err := ParseDSN(strings.NewReader("Subject: hello\r\nContent-Type: text/plain\r\n\r\nhello\r\n"))
+	if !errors.Is(err, NotADeliveryReportErr) {
+		t.Fatalf("expected no report but got %v", err)
+	}
+}
+
+func TestProcessBounce(t *testing.T) {
+	repo := json.NewSloppyJSONRepository[Outgoing, ID](mem.NewBlobStore("mails"))
+	bus := &eventstest.Recorder{}
+	processBounce := NewProcessBounce(bus, repo)
+
+	// without the sent mail, anybody could mark arbitrary addresses as undeliverable
+	if _, err := processBounce(user.SU(), strings.NewReader(testDSN)); !errors.Is(err, UnknownBounceErr) {
+		t.Fatalf("expected unknown bounce but got %v", err)
+	}
+
+	if err := repo.Save(Outgoing{ID: "1", Mail: Mail{MessageID: "abc@nago.example.com", To: []mail.Address{{Address: "alice@example.com"}}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// bob has not been a recipient of the sent mail
+	if _, err := processBounce(user.SU(), strings.NewReader(testDSN)); err != nil || len(bus.Events()) != 0 {
+		t.Fatalf("unexpected result: %v %v", bus.Events(), err)
+	}
+
+	if err := repo.Save(Outgoing{ID: "1", Mail: Mail{MessageID: "abc@nago.example.com", BCC: []mail.Address{{Address: "Bob@example.com"}}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := processBounce(user.SU(), strings.NewReader(testDSN)); err != nil {
+		t.Fatal(err)
+	}
+
+	if evts := bus.Events(); len(evts) != 1 || evts[0].(Undeliverable).Address != "bob@example.com" {
+		t.Fatalf("unexpected events: %v", evts)
+	}
+}
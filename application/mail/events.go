@@ -20,6 +20,11 @@ type SendMailRequested struct {
 	TextBody    string            `json:"textBody,omitempty"`    // If not empty, send this as a text part.
 	HTMLBody    string            `json:"HTMLBody,omitempty"`    // If not empty, send this as a html part.
 	Attachments map[string][]byte `json:"attachments,omitempty"` // If not empty, add these binary data as Attachments.
+	InlineFiles map[string][]byte `json:"inlineFiles,omitempty"` // If not empty, add these files as inline parts, referenced by cid:<name> from the HTMLBody.
+
+	// Unsubscribe contains https or mailto URIs for the List-Unsubscribe header, see [Mail.Unsubscribe].
+	Unsubscribe         []string `json:"unsubscribe,omitempty"`
+	UnsubscribeOneClick bool     `json:"unsubscribeOneClick,omitempty"`
 
 	// SmtpHint allows to narrow the wanted mail server, e.g. for specific mail signatures. It can be empty.
 	// The hint is matched against the [secret.SMTP.Name] or [secret.Secret.ID] of all secrets available to
@@ -27,3 +32,10 @@ type SendMailRequested struct {
 	// If no match was found, the first found mail secret shared with [group.System] is used.
 	SmtpHint string `json:"smtpHint,omitempty"`
 }
+
+// Undeliverable is published, if a mail server has permanently rejected a recipient, either directly while
+// sending or later by a bounce message, see [ProcessBounce].
+type Undeliverable struct {
+	Address string `json:"address,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+}
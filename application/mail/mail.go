@@ -27,6 +27,17 @@ type Mail struct {
 	// [group.System].
 	// If no match was found, the first found mail secret shared with [group.System] is used.
 	SmtpHint string
+
+	// MessageID is the globally unique identifier of the mail without angle brackets. If empty, the scheduler
+	// generates one before the first delivery attempt, using the domain of the sender address.
+	MessageID string
+
+	// Unsubscribe contains https or mailto URIs, which are announced in the List-Unsubscribe header, see RFC 2369.
+	Unsubscribe []string
+
+	// UnsubscribeOneClick announces, that a POST request to the https URI from Unsubscribe unsubscribes
+	// without any further user interaction, see RFC 8058.
+	UnsubscribeOneClick bool
 }
 
 type Status string
@@ -35,7 +46,11 @@ const (
 	StatusUndefined   Status = ""
 	StatusQueued      Status = "queued"
 	StatusSendSuccess Status = "send_success"
-	StatusError       Status = "send_error"
+	// StatusError denotes a failed attempt, which is retried at [Outgoing.NextAttemptAt].
+	StatusError Status = "send_error"
+	// StatusDeadLetter denotes a mail, which has been given up after too many attempts or a permanent
+	// rejection by the server. It is only sent again, if it is re-queued manually, see [Requeue].
+	StatusDeadLetter Status = "dead_letter"
 )
 
 type Outgoing struct {
@@ -43,11 +58,14 @@ type Outgoing struct {
 	Mail       Mail
 	Subject    string `label:"Betreff" disabled:"true"`
 	Receiver   string `label:"Empfänger" disabled:"true"`
-	Status     Status `values:"[\"undefined=Undefiniert\",\"queued=wartet auf Versand\",\"send_success=erfolgreich versendet\",\"send_error=Versandfehler\"]"`
+	Status     Status `values:"[\"undefined=Undefiniert\",\"queued=wartet auf Versand\",\"send_success=erfolgreich versendet\",\"send_error=Versandfehler (Wiederholung geplant)\",\"dead_letter=unzustellbar\"]"`
 	LastError  string `label:"Letzter Fehler"`
 	ServerName string `label:"Versendet über" disabled:"true" table-visible:"true"`
 	QueuedAt   time.Time
 	SendAt     time.Time
+
+	Attempts      int       `label:"Versuche" disabled:"true"`
+	NextAttemptAt time.Time `label:"Nächster Versuch" disabled:"true" table-visible:"false"`
 }
 
 func (o Outgoing) WithIdentity(id ID) Outgoing {
@@ -11,7 +11,10 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"mime"
+	"net/http"
 	"net/mail"
+	"path/filepath"
 	"strings"
 )
 
@@ -80,12 +83,48 @@ func NewHtmlPart(html string) Part {
 	return part
 }
 
+// inline returns true, if the part can be referenced from html using a cid URL, see [NewInlinePart].
+func (p Part) inline() bool {
+	return len(p.Header["Content-ID"]) > 0
+}
+
+func (p Part) html() bool {
+	return len(p.Header["Content-Type"]) > 0 && p.Header["Content-Type"][0] == "text/html"
+}
+
+// NewAttachmentPart creates a regular attachment. A non-ASCII name is encoded as defined by RFC 2231.
 func NewAttachmentPart(name string, data []byte) Part {
 	part := Part{}
-	part.PutHeader("Content-Type", "application/octet-stream")
-	part.PutHeader("Content-Type", "name="+protect(name))
+	part.PutHeader("Content-Type", mime.FormatMediaType("application/octet-stream", map[string]string{"name": protect(name)}))
+	part.PutHeader("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": protect(name)}))
+	part.PutHeader("Content-Transfer-Encoding", "base64")
+	part.Encoded = b64Lines(data)
+
+	return part
+}
+
+// NewInlinePart creates a part, which is referenced from a html part by the given content id, e.g. by
+// <img src="cid:logo">. The content type is derived from the file extension of the name or detected
+// from the data otherwise. Inline parts are sent as multipart/related together with the html parts.
+func NewInlinePart(contentID, name string, data []byte) Part {
+	contentType := mime.TypeByExtension(filepath.Ext(name))
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	part := Part{}
+	part.PutHeader("Content-Type", mime.FormatMediaType(mediaType, map[string]string{"name": protect(name)}))
+	part.PutHeader("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": protect(name)}))
+	part.PutHeader("Content-ID", "<"+protect(contentID)+">")
 	part.PutHeader("Content-Transfer-Encoding", "base64")
+	part.Encoded = b64Lines(data)
 
+	return part
+}
+
+func b64Lines(data []byte) []byte {
 	var tmp bytes.Buffer
 	for _, line := range b64(data) {
 		_, err := tmp.Write([]byte(line))
@@ -99,6 +138,5 @@ func NewAttachmentPart(name string, data []byte) Part {
 
 	}
 
-	part.Encoded = tmp.Bytes()
-	return part
+	return tmp.Bytes()
 }
@@ -12,6 +12,8 @@ import "go.wdy.de/nago/application/permission"
 var (
 	PermSendMail             = permission.Declare[SendMail]("nago.mail.send", "Mail Senden", "Träger dieser Berechtigung können Mails versenden.")
 	PermInitDefaultTemplates = permission.Declare[SendMail]("nago.mail.init_default_templates", "Standard Templates setzen", "Träger dieser Berechtigung können die Standard Mail templates aktivieren.")
+	PermRequeue              = permission.Declare[Requeue]("nago.mail.requeue", "Mail erneut versenden", "Träger dieser Berechtigung können fehlgeschlagene oder unzustellbare Mails erneut in die Warteschlange stellen.")
+	PermProcessBounce        = permission.Declare[ProcessBounce]("nago.mail.process_bounce", "Unzustellbarkeitsberichte verarbeiten", "Träger dieser Berechtigung können Unzustellbarkeitsberichte einlesen, wodurch Empfängeradressen als unzustellbar markiert werden.")
 
 	PermOutgoingFindAll    permission.ID
 	PermOutgoingFindByID   permission.ID
@@ -52,7 +52,7 @@ func split1000(str string) []string {
 func b64(data []byte) []string {
 	str := base64.StdEncoding.EncodeToString(data)
 	stride := 72
-	lines := make([]string, (len(str)/stride)+1)[:]
+	lines := make([]string, 0, (len(str)/stride)+1)
 	for i := 0; i < len(str); i += stride {
 		end := i + stride
 		if end > len(str) {
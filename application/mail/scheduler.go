@@ -9,13 +9,16 @@ package mail
 
 import (
 	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"time"
+
 	"go.wdy.de/nago/application/group"
 	"go.wdy.de/nago/application/secret"
 	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/pkg/events"
 	"go.wdy.de/nago/pkg/std"
-	"log/slog"
-	"slices"
-	"time"
 )
 
 type ScheduleOptions struct {
@@ -23,10 +26,12 @@ type ScheduleOptions struct {
 	KeepMailAfterSuccess time.Duration // default is 24 hours, if negative unlimited
 	KeepMailAfterError   time.Duration // default is 1 year, if negative unlimited
 	WaitBetweenSends     time.Duration // default is 1 Seconds
+	MaxAttempts          int           // default is 8, afterwards a mail becomes a dead letter
+	RetryDelay           time.Duration // default is 1 minute, doubled after each failed attempt
+	MaxRetryDelay        time.Duration // default is 6 hours
 }
 
-// StartScheduler starts a new scheduler instance to process the [Outgoing] mails.
-func StartScheduler(ctx context.Context, opts ScheduleOptions, mails Repository, sysUser user.SysUser, secrets secret.FindGroupSecrets) {
+func (opts ScheduleOptions) withDefaults() ScheduleOptions {
 	if opts.SendInterval == 0 {
 		opts.SendInterval = time.Second * 30
 	}
@@ -43,6 +48,46 @@ func StartScheduler(ctx context.Context, opts ScheduleOptions, mails Repository,
 		opts.WaitBetweenSends = time.Second * 1
 	}
 
+	if opts.MaxAttempts == 0 {
+		opts.MaxAttempts = 8
+	}
+
+	if opts.RetryDelay == 0 {
+		opts.RetryDelay = time.Minute
+	}
+
+	if opts.MaxRetryDelay == 0 {
+		opts.MaxRetryDelay = time.Hour * 6
+	}
+
+	return opts
+}
+
+// backoff returns the delay after the given number of failed attempts.
+func (opts ScheduleOptions) backoff(attempts int) time.Duration {
+	delay := opts.RetryDelay
+	for i := 1; i < attempts && delay < opts.MaxRetryDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, opts.MaxRetryDelay)
+}
+
+// StartScheduler starts a new scheduler instance to process the [Outgoing] mails. Failed mails are retried
+// with an exponential backoff and become dead letters after [ScheduleOptions.MaxAttempts] or if the server
+// rejected them permanently. For permanently rejected recipients, [Undeliverable] is published.
+func StartScheduler(ctx context.Context, opts ScheduleOptions, mails Repository, sysUser user.SysUser, secrets secret.FindGroupSecrets, bus events.Bus) {
+	s := &scheduler{
+		opts:    opts.withDefaults(),
+		mails:   mails,
+		sysUser: sysUser,
+		secrets: secrets,
+		bus:     bus,
+		send:    send,
+		sleep:   time.Sleep,
+		sent:    map[string][]time.Time{},
+	}
+
 	go func() {
 		slog.Info("mail scheduler started")
 		for {
@@ -54,96 +99,159 @@ func StartScheduler(ctx context.Context, opts ScheduleOptions, mails Repository,
 				// continue below
 			}
 
-			time.Sleep(opts.SendInterval)
+			time.Sleep(s.opts.SendInterval)
+			s.process(time.Now())
+		}
+	}()
+}
 
-			if n, err := mails.Count(); err != nil || n == 0 {
-				if err != nil {
-					slog.Error("mail scheduler cannot count mail queue", "err", err)
-					continue
-				}
+type scheduler struct {
+	opts    ScheduleOptions
+	mails   Repository
+	sysUser user.SysUser
+	secrets secret.FindGroupSecrets
+	bus     events.Bus
+	send    func(secret.SMTP, Mail) error
+	sleep   func(time.Duration)
 
-				if n == 0 {
-					// nothing to do, don't need to check for smtp
-					continue
-				}
-			}
+	// sent contains the send times of the last hour per smtp server for the rate limit
+	sent map[string][]time.Time
+}
 
-			// TODO implement per hour/day rate limit, usually 500 mails per day
-			// TODO implement wrong auth detection, otherwise we may try every second with wrong credentials and our IP is likely blocked
-			// TODO implement global settings
+// process runs a single pass over the queue.
+func (s *scheduler) process(now time.Time) {
+	opts := s.opts
+	if n, err := s.mails.Count(); err != nil || n == 0 {
+		if err != nil {
+			slog.Error("mail scheduler cannot count mail queue", "err", err)
+		}
 
-			now := time.Now()
-			var toRemove []ID
-			for outgoing, err := range mails.All() {
-				if err != nil {
-					slog.Error("mail scheduler failed to iterate on outgoing mail repository", "err", err)
-					continue
-				}
+		// nothing to do, don't need to check for smtp
+		return
+	}
 
-				keepSuccessUnlimited := opts.KeepMailAfterSuccess < 0
-				if !keepSuccessUnlimited {
-					if outgoing.Status == StatusSendSuccess && now.Sub(outgoing.QueuedAt) > opts.KeepMailAfterSuccess {
-						toRemove = append(toRemove, outgoing.ID)
-					}
-				}
+	// TODO implement wrong auth detection, otherwise we may try every second with wrong credentials and our IP is likely blocked
+	// TODO implement global settings
 
-				keepErrorUnlimited := opts.KeepMailAfterError < 0
-				if !keepErrorUnlimited {
-					if outgoing.Status == StatusError && now.Sub(outgoing.QueuedAt) > opts.KeepMailAfterError {
-						toRemove = append(toRemove, outgoing.ID)
-					}
-				}
+	var toRemove []ID
+	for outgoing, err := range s.mails.All() {
+		if err != nil {
+			slog.Error("mail scheduler failed to iterate on outgoing mail repository", "err", err)
+			continue
+		}
 
-				// go next, if nothing to do
-				if outgoing.Status == StatusSendSuccess {
-					continue
-				}
+		keepSuccessUnlimited := opts.KeepMailAfterSuccess < 0
+		if !keepSuccessUnlimited {
+			if outgoing.Status == StatusSendSuccess && now.Sub(outgoing.QueuedAt) > opts.KeepMailAfterSuccess {
+				toRemove = append(toRemove, outgoing.ID)
+			}
+		}
 
-				optSmtp, err := pickMailServerCandidate(sysUser, secrets, outgoing.Mail.SmtpHint)
-				if err != nil {
-					slog.Error("mail scheduler failed to pick mail server", "err", err)
-					break
-				}
+		keepErrorUnlimited := opts.KeepMailAfterError < 0
+		if !keepErrorUnlimited {
+			if (outgoing.Status == StatusError || outgoing.Status == StatusDeadLetter) && now.Sub(outgoing.QueuedAt) > opts.KeepMailAfterError {
+				toRemove = append(toRemove, outgoing.ID)
+			}
+		}
 
-				if optSmtp.IsNone() {
-					slog.Error("cannot process mail queue, no smtp credentials available in system group")
-					break
-				}
+		// go next, if nothing to do
+		if outgoing.Status == StatusSendSuccess || outgoing.Status == StatusDeadLetter {
+			continue
+		}
 
-				smtp := optSmtp.Unwrap()
+		if outgoing.Status == StatusError && now.Before(outgoing.NextAttemptAt) {
+			continue
+		}
 
-				outgoing.ServerName = smtp.Name
-				outgoing.SendAt = time.Now()
+		optSmtp, err := pickMailServerCandidate(s.sysUser, s.secrets, outgoing.Mail.SmtpHint)
+		if err != nil {
+			slog.Error("mail scheduler failed to pick mail server", "err", err)
+			break
+		}
 
-				if err := send(smtp, outgoing.Mail); err != nil {
-					slog.Error("mail scheduler failed to send mail", "smtp", smtp.Name, "id", outgoing.ID, "subject", outgoing.Mail.Subject, "err", err)
+		if optSmtp.IsNone() {
+			slog.Error("cannot process mail queue, no smtp credentials available in system group")
+			break
+		}
 
-					outgoing.Status = StatusError
-					outgoing.LastError = err.Error()
-				} else {
-					slog.Info("mail scheduler send mail success", "id", outgoing.ID)
-					outgoing.Status = StatusSendSuccess
-					outgoing.LastError = ""
-				}
+		smtp := optSmtp.Unwrap()
 
-				// this is an anti-spam heuristic
-				time.Sleep(opts.WaitBetweenSends)
+		if !s.allow(smtp, now) {
+			// keep it queued, without counting an attempt
+			continue
+		}
 
-				if err := mails.Save(outgoing); err != nil {
-					slog.Error("failed to save outgoing mail state", "id", outgoing.ID, "subject", outgoing.Mail.Subject, "err", err)
-					continue
-				}
-			}
+		outgoing.ServerName = smtp.Name
+		if outgoing.Mail.MessageID == "" {
+			outgoing.Mail.MessageID = newMessageID(smtp)
+		}
 
-			if len(toRemove) > 0 {
-				if err := mails.DeleteAllByID(slices.Values(toRemove)); err != nil {
-					slog.Error("mail scheduler failed to remove mails", "err", err)
-					continue
-				}
-			}
+		outgoing.SendAt = now
+		outgoing.Attempts++
 
+		if err := s.send(smtp, outgoing.Mail); err != nil {
+			slog.Error("mail scheduler failed to send mail", "smtp", smtp.Name, "id", outgoing.ID, "subject", outgoing.Mail.Subject, "attempt", outgoing.Attempts, "err", err)
+			s.fail(&outgoing, now, err)
+		} else {
+			slog.Info("mail scheduler send mail success", "id", outgoing.ID)
+			outgoing.Status = StatusSendSuccess
+			outgoing.LastError = ""
+			outgoing.NextAttemptAt = time.Time{}
 		}
-	}()
+
+		// this is an anti-spam heuristic
+		s.sleep(opts.WaitBetweenSends)
+
+		if err := s.mails.Save(outgoing); err != nil {
+			slog.Error("failed to save outgoing mail state", "id", outgoing.ID, "subject", outgoing.Mail.Subject, "err", err)
+			continue
+		}
+	}
+
+	if len(toRemove) > 0 {
+		if err := s.mails.DeleteAllByID(slices.Values(toRemove)); err != nil {
+			slog.Error("mail scheduler failed to remove mails", "err", err)
+		}
+	}
+}
+
+// fail either schedules the next attempt or turns the mail into a dead letter.
+func (s *scheduler) fail(outgoing *Outgoing, now time.Time, err error) {
+	outgoing.LastError = err.Error()
+
+	var rejected RecipientRejectedError
+	if errors.As(err, &rejected) && permanent(err) {
+		s.bus.Publish(Undeliverable{Address: rejected.Address, Reason: rejected.Err.Error()})
+	}
+
+	if permanent(err) || outgoing.Attempts >= s.opts.MaxAttempts {
+		outgoing.Status = StatusDeadLetter
+		outgoing.NextAttemptAt = time.Time{}
+		return
+	}
+
+	outgoing.Status = StatusError
+	outgoing.NextAttemptAt = now.Add(s.opts.backoff(outgoing.Attempts))
+}
+
+// allow applies the rate limit of the smtp server and counts the send, if allowed.
+func (s *scheduler) allow(smtp secret.SMTP, now time.Time) bool {
+	if smtp.MaxPerHour <= 0 {
+		return true
+	}
+
+	key := smtp.Name + "@" + smtp.Host
+	window := slices.DeleteFunc(s.sent[key], func(t time.Time) bool {
+		return now.Sub(t) >= time.Hour
+	})
+
+	if len(window) >= smtp.MaxPerHour {
+		s.sent[key] = window
+		return false
+	}
+
+	s.sent[key] = append(window, now)
+	return true
 }
 
 func pickMailServerCandidate(sysUser user.SysUser, secrets secret.FindGroupSecrets, idOrNameHint string) (std.Option[secret.SMTP], error) {
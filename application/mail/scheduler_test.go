@@ -0,0 +1,187 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package mail
+
+import (
+	"errors"
+	"iter"
+	"net/textproto"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"go.wdy.de/nago/application/group"
+	"go.wdy.de/nago/application/secret"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/auth"
+	"go.wdy.de/nago/pkg/blob/mem"
+	"go.wdy.de/nago/pkg/data/json"
+	"go.wdy.de/nago/pkg/events/eventstest"
+)
+
+func newTestScheduler(t *testing.T, smtp secret.SMTP, sendFn func(secret.SMTP, Mail) error, mails ...Outgoing) *scheduler {
+	t.Helper()
+
+	repo := json.NewSloppyJSONRepository[Outgoing, ID](mem.NewBlobStore("mails"))
+	for _, m := range mails {
+		if err := repo.Save(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return &scheduler{
+		opts:    ScheduleOptions{}.withDefaults(),
+		mails:   repo,
+		sysUser: user.SU,
+		secrets: func(subject auth.Subject, gid group.ID) iter.Seq2[secret.Secret, error] {
+			return func(yield func(secret.Secret, error) bool) {
+				yield(secret.Secret{ID: "smtp", Credentials: smtp}, nil)
+			}
+		},
+		bus:   &eventstest.Recorder{},
+		send:  sendFn,
+		sleep: func(time.Duration) {},
+		sent:  map[string][]time.Time{},
+	}
+}
+
+func mustFind(t *testing.T, s *scheduler, id ID) Outgoing {
+	t.Helper()
+
+	optMail, err := s.mails.FindByID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if optMail.IsNone() {
+		t.Fatalf("mail %s not found", id)
+	}
+
+	return optMail.Unwrap()
+}
+
+func TestScheduleOptionsBackoff(t *testing.T) {
+	opts := ScheduleOptions{RetryDelay: time.Minute, MaxRetryDelay: time.Hour}.withDefaults()
+
+	tests := map[int]time.Duration{
+		1:  time.Minute,
+		2:  2 * time.Minute,
+		3:  4 * time.Minute,
+		7:  time.Hour,
+		20: time.Hour,
+	}
+
+	for attempts, want := range tests {
+		if got := opts.backoff(attempts); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
+
+func TestSchedulerRetryAndDeadLetter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	temporary := &textproto.Error{Code: 421, Msg: "try again later"}
+
+	s := newTestScheduler(t, secret.SMTP{Name: "test"}, func(secret.SMTP, Mail) error {
+		return temporary
+	}, Outgoing{ID: "1", Status: StatusQueued, QueuedAt: now})
+	s.opts.MaxAttempts = 2
+
+	s.process(now)
+	m := mustFind(t, s, "1")
+	if m.Status != StatusError || m.Attempts != 1 || !m.NextAttemptAt.Equal(now.Add(time.Minute)) {
+		t.Fatalf("unexpected state after first attempt: %+v", m)
+	}
+
+	// not yet due
+	s.process(now.Add(30 * time.Second))
+	if m := mustFind(t, s, "1"); m.Attempts != 1 {
+		t.Fatalf("expected no further attempt but got %d", m.Attempts)
+	}
+
+	s.process(now.Add(time.Minute))
+	m = mustFind(t, s, "1")
+	if m.Status != StatusDeadLetter || m.Attempts != 2 {
+		t.Fatalf("expected dead letter but got %+v", m)
+	}
+
+	// dead letters are never sent again
+	s.process(now.Add(time.Hour))
+	if m := mustFind(t, s, "1"); m.Attempts != 2 {
+		t.Fatalf("expected no further attempt but got %d", m.Attempts)
+	}
+}
+
+func TestSchedulerPermanentRejection(t *testing.T) {
+	now := time.Now()
+	s := newTestScheduler(t, secret.SMTP{Name: "test"}, func(secret.SMTP, Mail) error {
+		return RecipientRejectedError{Address: "bob@example.com", Err: &textproto.Error{Code: 550, Msg: "5.1.1 user unknown"}}
+	}, Outgoing{ID: "1", Status: StatusQueued, QueuedAt: now})
+
+	s.process(now)
+
+	if m := mustFind(t, s, "1"); m.Status != StatusDeadLetter || m.Attempts != 1 {
+		t.Fatalf("expected immediate dead letter but got %+v", m)
+	}
+
+	evts := s.bus.(*eventstest.Recorder).Events()
+	if len(evts) != 1 {
+		t.Fatalf("expected one event but got %d", len(evts))
+	}
+
+	evt, ok := evts[0].(Undeliverable)
+	if !ok || evt.Address != "bob@example.com" {
+		t.Fatalf("unexpected event: %#v", evts[0])
+	}
+}
+
+func TestSchedulerRateLimit(t *testing.T) {
+	now := time.Now()
+	var sent int
+	s := newTestScheduler(t, secret.SMTP{Name: "test", MaxPerHour: 2}, func(secret.SMTP, Mail) error {
+		sent++
+		return nil
+	},
+		Outgoing{ID: "1", Status: StatusQueued, QueuedAt: now},
+		Outgoing{ID: "2", Status: StatusQueued, QueuedAt: now},
+		Outgoing{ID: "3", Status: StatusQueued, QueuedAt: now},
+	)
+
+	s.process(now)
+	if sent != 2 {
+		t.Fatalf("expected 2 sent mails but got %d", sent)
+	}
+
+	s.process(now.Add(time.Minute))
+	if sent != 2 {
+		t.Fatalf("expected rate limit but got %d sent mails", sent)
+	}
+
+	s.process(now.Add(time.Hour))
+	if sent != 3 {
+		t.Fatalf("expected 3 sent mails but got %d", sent)
+	}
+}
+
+func TestRequeue(t *testing.T) {
+	s := newTestScheduler(t, secret.SMTP{}, nil, Outgoing{ID: "1", Status: StatusDeadLetter, Attempts: 8})
+
+	var mutex sync.Mutex
+	if err := NewRequeue(&mutex, s.mails)(user.SU(), "1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if m := mustFind(t, s, "1"); m.Status != StatusQueued || m.Attempts != 0 {
+		t.Fatalf("unexpected state: %+v", m)
+	}
+
+	if err := NewRequeue(&mutex, s.mails)(user.SU(), "2"); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected not exist but got %v", err)
+	}
+}
@@ -10,25 +10,45 @@ package mail
 import (
 	"bytes"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"mime"
 	"net"
 	"net/mail"
 	"net/smtp"
+	"net/textproto"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"go.wdy.de/nago/application/secret"
+	"go.wdy.de/nago/pkg/data"
 )
 
-func send(credentials secret.SMTP, m Mail) error {
-	// Connect to the SMTP Server
-	servername := credentials.Host + ":" + strconv.Itoa(credentials.Port)
+// RecipientRejectedError is returned, if the server refuses to accept a recipient address.
+type RecipientRejectedError struct {
+	Address string
+	Err     error
+}
 
-	host, _, _ := net.SplitHostPort(servername)
+func (e RecipientRejectedError) Error() string {
+	return fmt.Sprintf("recipient %s rejected: %v", e.Address, e.Err)
+}
 
-	auth := smtp.PlainAuth("", credentials.Username, credentials.Password, host)
+func (e RecipientRejectedError) Unwrap() error {
+	return e.Err
+}
+
+// permanent returns true, if the server has rejected the mail with a 5xx reply code, which means that
+// trying again later will fail in the same way.
+func permanent(err error) bool {
+	var tpErr *textproto.Error
+	return errors.As(err, &tpErr) && tpErr.Code >= 500
+}
+
+func send(credentials secret.SMTP, m Mail) error {
+	host := credentials.Host
 
 	// TLS config
 	tlsconfig := &tls.Config{
@@ -36,6 +56,17 @@ func send(credentials secret.SMTP, m Mail) error {
 		ServerName:         host,
 	}
 
+	return deliver(credentials, m, tlsconfig)
+}
+
+func deliver(credentials secret.SMTP, m Mail, tlsconfig *tls.Config) error {
+	// Connect to the SMTP Server
+	servername := net.JoinHostPort(credentials.Host, strconv.Itoa(credentials.Port))
+
+	host, _, _ := net.SplitHostPort(servername)
+
+	auth := smtp.PlainAuth("", credentials.Username, credentials.Password, host)
+
 	conn, err := net.DialTimeout("tcp", servername, 10*time.Second)
 	if err != nil {
 		return err
@@ -43,9 +74,13 @@ func send(credentials secret.SMTP, m Mail) error {
 
 	c, err := smtp.NewClient(conn, host)
 	if err != nil {
+		conn.Close()
 		return err
 	}
 
+	// closing after quit is a no-op, but otherwise we would leak the connection on any error
+	defer c.Close()
+
 	err = c.StartTLS(tlsconfig)
 	if err != nil {
 		return err
@@ -71,31 +106,72 @@ func send(credentials secret.SMTP, m Mail) error {
 	// add all recipients, which is independent of what is in the actual message
 	for _, adr := range m.To {
 		if err = c.Rcpt(adr.Address); err != nil {
-			return err
+			return RecipientRejectedError{Address: adr.Address, Err: err}
 		}
 	}
 
 	for _, adr := range m.CC {
 		if err = c.Rcpt(adr.Address); err != nil {
-			return err
+			return RecipientRejectedError{Address: adr.Address, Err: err}
 		}
 	}
 
 	for _, adr := range m.BCC {
 		if err = c.Rcpt(adr.Address); err != nil {
-			return err
+			return RecipientRejectedError{Address: adr.Address, Err: err}
 		}
 	}
 
+	msg, err := compose(credentials, m, time.Now())
+	if err != nil {
+		return err
+	}
+
 	// Data
 	w, err := c.Data()
 	if err != nil {
 		return err
 	}
 
+	_, err = w.Write(msg)
+	if err != nil {
+		return err
+	}
+
+	err = w.Close()
+	if err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
+
+// newMessageID creates a unique message id in the domain of the sender, which is either the DKIM domain or
+// the domain part of the sender address.
+func newMessageID(credentials secret.SMTP) string {
+	domain := credentials.DKIMDomain
+	if domain == "" {
+		sender := credentials.SenderAddress
+		if sender == "" {
+			sender = credentials.Username
+		}
+
+		_, domain, _ = strings.Cut(sender, "@")
+	}
+
+	if domain == "" {
+		domain = credentials.Host
+	}
+
+	return string(data.RandIdent[ID]()) + "@" + domain
+}
+
+// compose renders the complete message in RFC 5322 format. Non-ASCII header values are encoded as defined
+// by RFC 2047 and the message is signed, if DKIM has been configured for the given credentials.
+func compose(credentials secret.SMTP, m Mail, now time.Time) ([]byte, error) {
 	to := recipients(m.To).String()
 	if len(to) == 0 {
-		return fmt.Errorf("recipient list is empty")
+		return nil, fmt.Errorf("recipient list is empty")
 	}
 
 	// Setup data
@@ -105,6 +181,23 @@ func send(credentials secret.SMTP, m Mail) error {
 	data.writeHeader("To", to)
 	data.writeHeader("CC", recipients(m.CC).String())
 	data.writeHeader("Subject", mime.QEncoding.Encode("UTF-8", m.Subject))
+	data.writeHeader("Date", now.Format(time.RFC1123Z))
+	if m.MessageID != "" {
+		data.writeHeader("Message-ID", "<"+m.MessageID+">")
+	}
+
+	if len(m.Unsubscribe) > 0 {
+		var uris []string
+		for _, uri := range m.Unsubscribe {
+			uris = append(uris, "<"+uri+">")
+		}
+
+		data.writeHeader("List-Unsubscribe", strings.Join(uris, ", "))
+		if m.UnsubscribeOneClick {
+			data.writeHeader("List-Unsubscribe-Post", "List-Unsubscribe=One-Click")
+		}
+	}
+
 	data.writeHeader("MIME-Version", "1.0")
 	data.writeHeader("Content-Type", "multipart/mixed;  boundary=\""+boundaryMultipartMixed+"\"")
 	data.rf()
@@ -112,13 +205,12 @@ func send(credentials secret.SMTP, m Mail) error {
 	data.rf()
 	data.rf()
 
-	for _, p := range m.Parts {
+	for _, p := range relate(m.Parts) {
 		data.writeLine("--")
 		data.writeLine(boundaryMultipartMixed)
 		data.rf()
-		err = p.write(data.sb)
-		if err != nil {
-			return err
+		if err := p.write(data.sb); err != nil {
+			return nil, err
 		}
 
 		data.rf()
@@ -127,19 +219,58 @@ func send(credentials secret.SMTP, m Mail) error {
 	data.writeLine("--")
 	data.writeLine(boundaryMultipartMixed)
 	data.writeLine("--")
+	data.rf()
+
+	msg := data.sb.Bytes()
+	if credentials.DKIMDomain == "" || credentials.DKIMPrivateKey == "" {
+		return msg, nil
+	}
 
-	//fmt.Println(string(data.sb.Bytes()))
-	_, err = w.Write(data.sb.Bytes())
+	signer, err := newDKIMSigner(credentials.DKIMDomain, credentials.DKIMSelector, credentials.DKIMPrivateKey)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	err = w.Close()
+	signature, err := signer.sign(msg, now)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return c.Quit()
+	return append([]byte(signature), msg...), nil
+}
+
+// relate groups the html parts and all inline parts into a single multipart/related part, so that the html
+// can reference the inline parts by their cid URL. Without inline parts, the parts are returned as is.
+func relate(parts []Part) []Part {
+	if !slices.ContainsFunc(parts, Part.inline) {
+		return parts
+	}
+
+	boundaryMultipartRelated := "------------5C2E0FA1B7D3946E18AB7C03"
+	var related bytes.Buffer
+	var res []Part
+	for _, p := range parts {
+		if !p.inline() && !p.html() {
+			res = append(res, p)
+			continue
+		}
+
+		related.WriteString("--" + boundaryMultipartRelated + "\r\n")
+		if err := p.write(&related); err != nil {
+			panic(fmt.Errorf("unreachable: %w", err))
+		}
+
+		related.WriteString("\r\n")
+	}
+
+	related.WriteString("--" + boundaryMultipartRelated + "--\r\n")
+
+	part := Part{Encoded: related.Bytes()}
+	part.PutHeader("Content-Type", mime.FormatMediaType("multipart/related", map[string]string{"boundary": boundaryMultipartRelated, "type": "text/html"}))
+
+	// the related part replaces the first body part, to keep the order of text and attachments
+	idx := slices.IndexFunc(parts, func(p Part) bool { return p.inline() || p.html() })
+	return slices.Insert(res, idx, part)
 }
 
 type recipients []mail.Address
@@ -162,10 +293,33 @@ type dataWriter struct {
 }
 
 func (d *dataWriter) writeHeader(key string, value string) *dataWriter {
-	d.sb.WriteString(fmt.Sprintf("%s: %s\r\n", protect(key), protect(value)))
+	d.sb.WriteString(foldHeader(protect(key), protect(value)))
+	d.sb.WriteString("\r\n")
 	return d
 }
 
+// foldHeader breaks long header lines at whitespace, so that lines do not exceed 78 characters whenever
+// possible, see RFC 5322 section 2.2.3. The returned field has no trailing CRLF.
+func foldHeader(key, value string) string {
+	var sb strings.Builder
+	sb.WriteString(key)
+	sb.WriteString(":")
+	lineLen := sb.Len()
+	for _, word := range strings.Split(value, " ") {
+		// a long first word is moved to a continuation line, which is also valid right after the colon
+		if lineLen+1+len(word) > 78 && lineLen > 0 {
+			sb.WriteString("\r\n")
+			lineLen = 0
+		}
+
+		sb.WriteString(" ")
+		sb.WriteString(word)
+		lineLen += 1 + len(word)
+	}
+
+	return sb.String()
+}
+
 func (d *dataWriter) rf() *dataWriter {
 	d.sb.WriteString("\r\n")
 	return d
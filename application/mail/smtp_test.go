@@ -0,0 +1,229 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package mail
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.wdy.de/nago/application/secret"
+)
+
+// fakeSMTP is a minimal local smtp server, which understands just enough of the protocol for [deliver].
+type fakeSMTP struct {
+	listener net.Listener
+	tls      *tls.Config
+	rejected map[string]bool
+
+	mutex    sync.Mutex
+	received []string
+}
+
+func newFakeSMTP(t *testing.T, rejected ...string) (*fakeSMTP, *x509.CertPool) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := &fakeSMTP{
+		listener: listener,
+		tls:      &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}},
+		rejected: map[string]bool{},
+	}
+
+	for _, adr := range rejected {
+		srv.rejected[adr] = true
+	}
+
+	t.Cleanup(func() { _ = listener.Close() })
+	go srv.serve()
+
+	return srv, pool
+}
+
+func (s *fakeSMTP) credentials() secret.SMTP {
+	addr := s.listener.Addr().(*net.TCPAddr)
+	return secret.SMTP{
+		Name:     "fake",
+		Host:     "127.0.0.1",
+		Port:     addr.Port,
+		Username: "nago@example.com",
+		Password: "secret",
+	}
+}
+
+func (s *fakeSMTP) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeSMTP) handle(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	tp := textproto.NewConn(conn)
+	_ = tp.PrintfLine("220 localhost ESMTP fake")
+
+	secure := false
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+
+		cmd, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(cmd) {
+		case "EHLO", "HELO":
+			if secure {
+				_ = tp.PrintfLine("250-localhost")
+				_ = tp.PrintfLine("250 AUTH PLAIN")
+			} else {
+				_ = tp.PrintfLine("250-localhost")
+				_ = tp.PrintfLine("250 STARTTLS")
+			}
+		case "STARTTLS":
+			_ = tp.PrintfLine("220 ready to start TLS")
+			tlsConn := tls.Server(conn, s.tls)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+
+			conn = tlsConn
+			tp = textproto.NewConn(tlsConn)
+			secure = true
+		case "AUTH":
+			_ = tp.PrintfLine("235 2.7.0 authentication successful")
+		case "MAIL":
+			_ = tp.PrintfLine("250 2.1.0 ok")
+		case "RCPT":
+			adr := strings.Trim(strings.TrimPrefix(strings.ToUpper(arg), "TO:"), "<>")
+			if s.rejected[strings.ToLower(adr)] {
+				_ = tp.PrintfLine("550 5.1.1 user unknown")
+			} else {
+				_ = tp.PrintfLine("250 2.1.5 ok")
+			}
+		case "DATA":
+			_ = tp.PrintfLine("354 go ahead")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+
+			s.mutex.Lock()
+			s.received = append(s.received, string(data))
+			s.mutex.Unlock()
+			_ = tp.PrintfLine("250 2.0.0 queued")
+		case "QUIT":
+			_ = tp.PrintfLine("221 bye")
+			return
+		default:
+			_ = tp.PrintfLine("502 command not implemented")
+		}
+	}
+}
+
+func testMail(to string) Mail {
+	return Mail{
+		To:      []mail.Address{{Address: to}},
+		Subject: "Hällo",
+		Parts:   []Part{NewTextPart("hello world")},
+	}
+}
+
+func TestDeliver(t *testing.T) {
+	srv, pool := newFakeSMTP(t)
+
+	err := deliver(srv.credentials(), testMail("alice@example.com"), &tls.Config{RootCAs: pool, ServerName: "127.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+
+	if len(srv.received) != 1 {
+		t.Fatalf("expected one mail but got %d", len(srv.received))
+	}
+
+	msg, err := mail.ReadMessage(bufio.NewReader(strings.NewReader(srv.received[0])))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := msg.Header.Get("From"); got != "<nago@example.com>" {
+		t.Fatalf("unexpected sender: %s", got)
+	}
+}
+
+func TestDeliverRecipientRejected(t *testing.T) {
+	srv, pool := newFakeSMTP(t, "bob@example.com")
+
+	err := deliver(srv.credentials(), testMail("bob@example.com"), &tls.Config{RootCAs: pool, ServerName: "127.0.0.1"})
+
+	var rejected RecipientRejectedError
+	if !errors.As(err, &rejected) {
+		t.Fatalf("expected rejected recipient but got %v", err)
+	}
+
+	if rejected.Address != "bob@example.com" {
+		t.Fatalf("unexpected address: %s", rejected.Address)
+	}
+
+	if !permanent(err) {
+		t.Fatal("expected permanent error")
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package mail
+
+import (
+	"io"
+	"log/slog"
+	"net/mail"
+	"slices"
+	"strings"
+
+	"github.com/worldiety/option"
+	"go.wdy.de/nago/auth"
+	"go.wdy.de/nago/pkg/events"
+)
+
+func NewProcessBounce(bus events.Bus, repo Repository) ProcessBounce {
+	return func(subject auth.Subject, r io.Reader) ([]Bounce, error) {
+		if err := subject.Audit(PermProcessBounce); err != nil {
+			return nil, err
+		}
+
+		bounces, err := ParseDSN(r)
+		if err != nil {
+			return nil, err
+		}
+
+		optOutgoing, err := findByMessageID(repo, bounces)
+		if err != nil {
+			return nil, err
+		}
+
+		if optOutgoing.IsNone() {
+			return nil, UnknownBounceErr
+		}
+
+		outgoing := optOutgoing.Unwrap()
+		for _, bounce := range bounces {
+			if !bounce.Permanent() {
+				continue
+			}
+
+			if !outgoing.Mail.sentTo(bounce.Recipient) {
+				slog.Warn("ignored bounce of a foreign recipient", "mail", outgoing.ID, "recipient", bounce.Recipient)
+				continue
+			}
+
+			slog.Info("mail bounced", "mail", outgoing.ID, "recipient", bounce.Recipient, "status", bounce.Status)
+			bus.Publish(Undeliverable{Address: bounce.Recipient, Reason: bounce.Status + " " + bounce.Diagnostic})
+		}
+
+		return bounces, nil
+	}
+}
+
+// findByMessageID returns the outgoing mail, to which the report refers. All bounces of a report share the
+// same original mail.
+func findByMessageID(repo Repository, bounces []Bounce) (option.Opt[Outgoing], error) {
+	if len(bounces) == 0 || bounces[0].MessageID == "" {
+		return option.None[Outgoing](), nil
+	}
+
+	id := bounces[0].MessageID
+	for outgoing, err := range repo.All() {
+		if err != nil {
+			return option.None[Outgoing](), err
+		}
+
+		if outgoing.Mail.MessageID == id {
+			return option.Some(outgoing), nil
+		}
+	}
+
+	return option.None[Outgoing](), nil
+}
+
+func (m Mail) sentTo(addr string) bool {
+	return slices.ContainsFunc(slices.Concat(m.To, m.CC, m.BCC), func(rcpt mail.Address) bool {
+		return strings.EqualFold(rcpt.Address, addr)
+	})
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package mail
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"go.wdy.de/nago/auth"
+)
+
+func NewRequeue(mutex *sync.Mutex, mails Repository) Requeue {
+	return func(subject auth.Subject, id ID) error {
+		if err := subject.Audit(PermRequeue); err != nil {
+			return err
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		optMail, err := mails.FindByID(id)
+		if err != nil {
+			return err
+		}
+
+		if optMail.IsNone() {
+			return os.ErrNotExist
+		}
+
+		outgoing := optMail.Unwrap()
+		if outgoing.Status == StatusSendSuccess {
+			return nil
+		}
+
+		outgoing.Status = StatusQueued
+		outgoing.Attempts = 0
+		outgoing.NextAttemptAt = time.Time{}
+		outgoing.QueuedAt = time.Now()
+
+		return mails.Save(outgoing)
+	}
+}
@@ -11,6 +11,9 @@ import (
 	"go.wdy.de/nago/application/mail"
 	"go.wdy.de/nago/application/rcrud"
 	"go.wdy.de/nago/presentation/core"
+	heroSolid "go.wdy.de/nago/presentation/icons/hero/solid"
+	"go.wdy.de/nago/presentation/ui"
+	"go.wdy.de/nago/presentation/ui/alert"
 	"go.wdy.de/nago/presentation/ui/crud"
 )
 
@@ -30,5 +33,31 @@ func OutgoingQueuePage(wnd core.Window, useCases mail.UseCases) core.View {
 			Upsert:         nil,
 		},
 	)
-	return crud.AutoRootView(crud.AutoRootViewOptions{Title: "Warteschlange Ausgang", CreateDisabled: true}, cruds)(wnd)
+
+	bnd := crud.AutoBinding[mail.Outgoing](crud.AutoBindingOptions{}, wnd, cruds)
+	if wnd.Subject().HasPermission(mail.PermRequeue) {
+		bnd.Add(crud.AggregateActions("Versand", crud.Optional[mail.Outgoing](buttonRequeue(wnd, useCases.Requeue), func(outgoing mail.Outgoing) bool {
+			return outgoing.Status == mail.StatusError || outgoing.Status == mail.StatusDeadLetter
+		})))
+	}
+
+	return ui.VStack(
+		crud.AutoView(crud.AutoViewOptions{Title: "Warteschlange Ausgang", CreateDisabled: true}, bnd, cruds),
+	).FullWidth()
+}
+
+// buttonRequeue puts a failed or dead-lettered mail back into the queue.
+func buttonRequeue(wnd core.Window, requeue mail.Requeue) crud.ElementViewFactory[mail.Outgoing] {
+	return func(state *core.State[mail.Outgoing]) core.View {
+		return ui.TertiaryButton(func() {
+			if err := requeue(wnd.Subject(), state.Get().ID); err != nil {
+				alert.ShowBannerError(wnd, err)
+				return
+			}
+
+			outgoing := state.Get()
+			outgoing.Status = mail.StatusQueued
+			state.Set(outgoing)
+		}).PreIcon(heroSolid.ArrowPath).AccessibilityLabel("Erneut senden")
+	}
 }
@@ -9,8 +9,10 @@ package mail
 
 import (
 	"fmt"
+	"io"
 	"iter"
 	"log/slog"
+	"sync"
 
 	"github.com/worldiety/enum"
 	"go.wdy.de/nago/application/rcrud"
@@ -37,6 +39,15 @@ type DeleteMailByID func(auth.Subject, ID) error
 type FindAllMails func(auth.Subject) iter.Seq2[Outgoing, error]
 type SaveMail func(auth.Subject, Outgoing) (ID, error)
 
+// Requeue resets a failed or dead-lettered mail, so that the scheduler starts over with its attempts.
+type Requeue func(subject auth.Subject, id ID) error
+
+// ProcessBounce parses a raw delivery status notification (bounce mail) and publishes [Undeliverable] for
+// each permanently failed recipient. Anybody can send such a report, thus it is only accepted, if it refers to
+// an [Outgoing] mail by its Message-ID and only the recipients of that mail are considered. Otherwise,
+// [UnknownBounceErr] is returned. See also [ParseDSN].
+type ProcessBounce func(subject auth.Subject, r io.Reader) ([]Bounce, error)
+
 type UseCases struct {
 	Outgoing struct {
 		FindByID   FindMailByID
@@ -46,7 +57,9 @@ type UseCases struct {
 		repository Repository // intentionally not exposed, to avoid that devs can simply destroy invariants
 	}
 
-	SendMail SendMail
+	SendMail      SendMail
+	Requeue       Requeue
+	ProcessBounce ProcessBounce
 }
 
 func NewUseCases(bus events.Bus, outgoingRepo Repository, ensureBuildIn template.EnsureBuildIn, sysUser user.SysUser) (UseCases, error) {
@@ -70,8 +83,12 @@ func NewUseCases(bus events.Bus, outgoingRepo Repository, ensureBuildIn template
 		return UseCases{}, fmt.Errorf("cannot ensure mail template: %w", err)
 	}
 
+	var mutex sync.Mutex
+
 	var uc UseCases
 	uc.SendMail = sendMailFn
+	uc.Requeue = NewRequeue(&mutex, outgoingRepo)
+	uc.ProcessBounce = NewProcessBounce(bus, outgoingRepo)
 
 	uc.Outgoing.DeleteByID = outgoingCrud.DeleteByID
 	uc.Outgoing.FindByID = outgoingCrud.FindByID
@@ -92,6 +109,10 @@ func NewUseCases(bus events.Bus, outgoingRepo Repository, ensureBuildIn template
 			parts = append(parts, NewAttachmentPart(name, buf))
 		}
 
+		for name, buf := range evt.InlineFiles {
+			parts = append(parts, NewInlinePart(name, name, buf))
+		}
+
 		_, err := uc.SendMail(user.SU(), Mail{
 			To:       evt.To,
 			CC:       evt.CC,
@@ -99,6 +120,9 @@ func NewUseCases(bus events.Bus, outgoingRepo Repository, ensureBuildIn template
 			Subject:  evt.Subject,
 			Parts:    parts,
 			SmtpHint: evt.SmtpHint,
+
+			Unsubscribe:         evt.Unsubscribe,
+			UnsubscribeOneClick: evt.UnsubscribeOneClick,
 		})
 
 		if err != nil {
@@ -0,0 +1,95 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package cfgmailbox
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"go.wdy.de/nago/application"
+	cfgdrive "go.wdy.de/nago/application/drive/cfg"
+	"go.wdy.de/nago/application/mailbox"
+	"go.wdy.de/nago/application/scheduler"
+	cfgscheduler "go.wdy.de/nago/application/scheduler/cfg"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/presentation/core"
+)
+
+// PollJob is the scheduler, which polls all mailboxes periodically.
+const PollJob scheduler.ID = "nago.mailbox.poll"
+
+// Management receives mails from IMAP and POP3 mailboxes. Each mailbox is configured as a secret of type
+// [mailbox.Settings], which must be shared with the group System. Subscribe to [mailbox.Received] to process
+// the received mails.
+type Management struct {
+	UseCases mailbox.UseCases
+}
+
+func Enable(cfg *application.Configurator) (Management, error) {
+	management, ok := core.FromContext[Management](cfg.Context(), "")
+	if ok {
+		return management, nil
+	}
+
+	schedulers, err := cfgscheduler.Enable(cfg)
+	if err != nil {
+		return Management{}, err
+	}
+
+	secrets, err := cfg.SecretManagement()
+	if err != nil {
+		return Management{}, err
+	}
+
+	drives, err := cfgdrive.Enable(cfg)
+	if err != nil {
+		return Management{}, err
+	}
+
+	mails, err := cfg.MailManagement()
+	if err != nil {
+		return Management{}, err
+	}
+
+	management = Management{
+		UseCases: mailbox.NewUseCases(
+			cfg.Context(),
+			cfg.EventBus(),
+			secrets.UseCases.FindGroupSecrets,
+			drives.UseCases.OpenDrive,
+			drives.UseCases.MkDir,
+			drives.UseCases.Put,
+			drives.UseCases.Stat,
+			mails.UseCases.Outgoing.FindAll,
+			mails.UseCases.ProcessBounce,
+		),
+	}
+
+	err = schedulers.UseCases.Configure(user.SU(), scheduler.Options{
+		ID:          PollJob,
+		Name:        "Postfächer abrufen",
+		Description: "Ruft neue Nachrichten aus den IMAP und POP3 Postfächern ab und verarbeitet Antworten und Unzustellbarkeitsberichte.",
+		Kind:        scheduler.Schedule,
+		Defaults: scheduler.Settings{
+			StartDelay: time.Minute,
+			PauseTime:  5 * time.Minute,
+		},
+		Runner: func(ctx context.Context) error {
+			return management.UseCases.Poll(user.SU())
+		},
+	})
+	if err != nil {
+		return Management{}, err
+	}
+
+	cfg.AddContextValue(core.ContextValue("nago.mailbox", management))
+
+	slog.Info("installed mailbox management")
+	return management, nil
+}
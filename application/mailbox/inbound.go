@@ -0,0 +1,259 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package mailbox
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	netmail "net/mail"
+	"net/textproto"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.wdy.de/nago/application/drive"
+	"go.wdy.de/nago/application/mail"
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+type ID string
+
+// InboundMail is a received and parsed message.
+type InboundMail struct {
+	ID         ID        `json:"id"`
+	Mailbox    string    `json:"mailbox,omitempty"` // Mailbox is the [Settings.Name] of the polled mailbox.
+	ReceivedAt time.Time `json:"receivedAt"`
+
+	// MessageID, InReplyTo and References are the threading headers without angle brackets.
+	MessageID  string   `json:"messageID,omitempty"`
+	InReplyTo  string   `json:"inReplyTo,omitempty"`
+	References []string `json:"references,omitempty"`
+
+	// Outgoing refers to the [mail.Outgoing] mail, which has been answered by this mail. It is empty, if
+	// the mail is not a reply or the outgoing mail has already been removed from the queue.
+	Outgoing mail.ID `json:"outgoing,omitempty"`
+
+	From    netmail.Address   `json:"from"`
+	To      []netmail.Address `json:"to,omitempty"`
+	CC      []netmail.Address `json:"cc,omitempty"`
+	ReplyTo []netmail.Address `json:"replyTo,omitempty"`
+	Subject string            `json:"subject,omitempty"`
+	Date    time.Time         `json:"date"`
+
+	// Text and HTML contain the first body part of the according type, converted to UTF-8.
+	Text        string       `json:"text,omitempty"`
+	HTML        string       `json:"html,omitempty"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+}
+
+// Attachment is any non-body part, including inline images.
+type Attachment struct {
+	Name        string    `json:"name"`
+	ContentType string    `json:"contentType,omitempty"`
+	ContentID   string    `json:"contentID,omitempty"` // ContentID is referenced by cid: URLs from the html part.
+	Size        int64     `json:"size"`
+	File        drive.FID `json:"file,omitempty"` // File is set, after the attachment has been stored in the drive.
+
+	data []byte
+}
+
+// Data returns the decoded content, which is only available for a freshly parsed mail.
+func (a Attachment) Data() []byte {
+	return a.data
+}
+
+var wordDecoder = &mime.WordDecoder{CharsetReader: charsetReader}
+
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	enc, err := htmlindex.Get(charset)
+	if err != nil {
+		return nil, err
+	}
+
+	return enc.NewDecoder().Reader(input), nil
+}
+
+// ParseMail reads a raw mail in RFC 5322 format. Transfer encodings and charsets are decoded. Header fields,
+// which cannot be parsed, are left empty.
+func ParseMail(r io.Reader) (InboundMail, error) {
+	msg, err := netmail.ReadMessage(r)
+	if err != nil {
+		return InboundMail{}, fmt.Errorf("cannot read mail: %w", err)
+	}
+
+	h := msg.Header
+
+	var m InboundMail
+	m.MessageID = first(messageIDs(h.Get("Message-Id")))
+	m.InReplyTo = first(messageIDs(h.Get("In-Reply-To")))
+	m.References = messageIDs(h.Get("References"))
+	m.From = first(addresses(h, "From"))
+	m.To = addresses(h, "To")
+	m.CC = addresses(h, "Cc")
+	m.ReplyTo = addresses(h, "Reply-To")
+	m.Subject = decodeHeader(h.Get("Subject"))
+	m.Date, _ = h.Date()
+
+	if err := m.walk(textproto.MIMEHeader(h), msg.Body); err != nil {
+		return m, err
+	}
+
+	return m, nil
+}
+
+// walk descends into multipart bodies and collects the leaf parts.
+func (m *InboundMail) walk(header textproto.MIMEHeader, body io.Reader) error {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", nil
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		parts := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := parts.NextRawPart()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			if err != nil {
+				return fmt.Errorf("cannot read mime part: %w", err)
+			}
+
+			if err := m.walk(part.Header, part); err != nil {
+				return err
+			}
+		}
+	}
+
+	data, err := io.ReadAll(decodeTransfer(header.Get("Content-Transfer-Encoding"), body))
+	if err != nil {
+		return fmt.Errorf("cannot decode %s part: %w", mediaType, err)
+	}
+
+	disposition, dispParams, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	name := decodeHeader(dispParams["filename"])
+	if name == "" {
+		name = decodeHeader(params["name"])
+	}
+
+	if disposition != "attachment" && name == "" {
+		switch {
+		case mediaType == "text/plain" && m.Text == "":
+			m.Text = decodeCharset(params["charset"], data)
+			return nil
+		case mediaType == "text/html" && m.HTML == "":
+			m.HTML = decodeCharset(params["charset"], data)
+			return nil
+		}
+	}
+
+	contentID := strings.Trim(header.Get("Content-Id"), "<> ")
+	if name == "" {
+		name = fmt.Sprintf("attachment-%d", len(m.Attachments)+1)
+		if contentID != "" {
+			name = contentID
+		}
+
+		if exts, _ := mime.ExtensionsByType(mediaType); len(exts) > 0 {
+			name += exts[0]
+		}
+	}
+
+	m.Attachments = append(m.Attachments, Attachment{
+		Name:        name,
+		ContentType: mediaType,
+		ContentID:   contentID,
+		Size:        int64(len(data)),
+		data:        data,
+	})
+
+	return nil
+}
+
+func decodeTransfer(encoding string, r io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		// line breaks are ignored by the decoder
+		return base64.NewDecoder(base64.StdEncoding, r)
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	default:
+		return r
+	}
+}
+
+func decodeCharset(charset string, data []byte) string {
+	if charset == "" || strings.EqualFold(charset, "utf-8") || strings.EqualFold(charset, "us-ascii") {
+		return string(data)
+	}
+
+	r, err := charsetReader(charset, strings.NewReader(string(data)))
+	if err != nil {
+		return string(data)
+	}
+
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		return string(data)
+	}
+
+	return string(buf)
+}
+
+func decodeHeader(value string) string {
+	res, err := wordDecoder.DecodeHeader(value)
+	if err != nil {
+		return value
+	}
+
+	return res
+}
+
+func addresses(h netmail.Header, key string) []netmail.Address {
+	if h.Get(key) == "" {
+		return nil
+	}
+
+	list, err := (&netmail.AddressParser{WordDecoder: wordDecoder}).ParseList(h.Get(key))
+	if err != nil {
+		return nil
+	}
+
+	res := make([]netmail.Address, 0, len(list))
+	for _, adr := range list {
+		res = append(res, *adr)
+	}
+
+	return res
+}
+
+var regexMessageID = regexp.MustCompile(`<([^<>\s]+)>`)
+
+func messageIDs(value string) []string {
+	var res []string
+	for _, match := range regexMessageID.FindAllStringSubmatch(value, -1) {
+		res = append(res, match[1])
+	}
+
+	return res
+}
+
+func first[T any](values []T) T {
+	var zero T
+	if len(values) == 0 {
+		return zero
+	}
+
+	return values[0]
+}
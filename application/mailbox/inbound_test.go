@@ -0,0 +1,115 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package mailbox
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+const testReply = "From: =?utf-8?q?J=C3=BCrgen?= <juergen@example.com>\r\n" +
+	"To: nago@example.com\r\n" +
+	"Subject: =?iso-8859-1?q?Re:_Best=E4tigung?=\r\n" +
+	"Date: Thu, 1 Jan 2026 12:00:00 +0100\r\n" +
+	"Message-ID: <reply-1@example.com>\r\n" +
+	"In-Reply-To: <abc@nago.example.com>\r\n" +
+	"References: <root@nago.example.com>\r\n <abc@nago.example.com>\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/mixed; boundary=\"outer\"\r\n" +
+	"\r\n" +
+	"--outer\r\n" +
+	"Content-Type: multipart/related; boundary=\"inner\"\r\n" +
+	"\r\n" +
+	"--inner\r\n" +
+	"Content-Type: multipart/alternative; boundary=\"alt\"\r\n" +
+	"\r\n" +
+	"--alt\r\n" +
+	"Content-Type: text/plain; charset=utf-8\r\n" +
+	"Content-Transfer-Encoding: quoted-printable\r\n" +
+	"\r\n" +
+	"Ich stimme zu. Gr=C3=BC=C3=9Fe\r\n" +
+	"--alt\r\n" +
+	"Content-Type: text/html; charset=iso-8859-1\r\n" +
+	"\r\n" +
+	"<p>Gr\xfc\xdfe <img src=\"cid:logo@example.com\"></p>\r\n" +
+	"--alt--\r\n" +
+	"--inner\r\n" +
+	"Content-Type: image/png\r\n" +
+	"Content-ID: <logo@example.com>\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"iVBORw0K\r\nGgo=\r\n" +
+	"--inner--\r\n" +
+	"--outer\r\n" +
+	"Content-Type: application/pdf\r\n" +
+	"Content-Disposition: attachment; filename*=utf-8''%C3%9Cbersicht.pdf\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"JVBERg==\r\n" +
+	"--outer--\r\n"
+
+func TestParseMail(t *testing.T) {
+	m, err := ParseMail(strings.NewReader(testReply))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if m.From.Name != "Jürgen" || m.From.Address != "juergen@example.com" {
+		t.Fatalf("unexpected from: %v", m.From)
+	}
+
+	if m.Subject != "Re: Bestätigung" {
+		t.Fatalf("unexpected subject: %q", m.Subject)
+	}
+
+	if m.MessageID != "reply-1@example.com" || m.InReplyTo != "abc@nago.example.com" {
+		t.Fatalf("unexpected threading headers: %q %q", m.MessageID, m.InReplyTo)
+	}
+
+	if !slices.Equal(m.References, []string{"root@nago.example.com", "abc@nago.example.com"}) {
+		t.Fatalf("unexpected references: %v", m.References)
+	}
+
+	if m.Date.IsZero() {
+		t.Fatal("expected date")
+	}
+
+	if m.Text != "Ich stimme zu. Grüße" {
+		t.Fatalf("unexpected text: %q", m.Text)
+	}
+
+	if !strings.Contains(m.HTML, "Grüße") {
+		t.Fatalf("unexpected html: %q", m.HTML)
+	}
+
+	if len(m.Attachments) != 2 {
+		t.Fatalf("expected 2 attachments but got %d", len(m.Attachments))
+	}
+
+	logo := m.Attachments[0]
+	if logo.ContentID != "logo@example.com" || logo.ContentType != "image/png" || string(logo.Data()) != "\x89PNG\r\n\x1a\n" {
+		t.Fatalf("unexpected inline part: %+v", logo)
+	}
+
+	pdf := m.Attachments[1]
+	if pdf.Name != "Übersicht.pdf" || string(pdf.Data()) != "%PDF" || pdf.Size != 4 {
+		t.Fatalf("unexpected attachment: %+v", pdf)
+	}
+}
+
+func TestFilename(t *testing.T) {
+	used := []string{"a.txt"}
+	if got := filename("a.txt", used); got != "2-a.txt" {
+		t.Fatalf("unexpected name: %s", got)
+	}
+
+	if got := filename(`x/y:z?.pdf`, nil); got != "x_y_z_.pdf" {
+		t.Fatalf("unexpected name: %s", got)
+	}
+}
@@ -0,0 +1,14 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package mailbox
+
+import "go.wdy.de/nago/application/permission"
+
+var (
+	PermPoll = permission.Declare[Poll]("nago.mailbox.poll", "Postfächer abrufen", "Träger dieser Berechtigung können neue Nachrichten aus den angebundenen IMAP und POP3 Postfächern abrufen und verarbeiten lassen.")
+)
@@ -0,0 +1,176 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package mailbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"net/url"
+
+	"go.wdy.de/nago/application/group"
+	"go.wdy.de/nago/application/secret"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/pkg/imap"
+	"go.wdy.de/nago/pkg/pop3"
+)
+
+// mailboxes returns the settings of all mailboxes, which are shared with the system group.
+func mailboxes(findSecrets secret.FindGroupSecrets) iter.Seq2[Settings, error] {
+	return func(yield func(Settings, error) bool) {
+		for sec, err := range findSecrets(user.SU(), group.System) {
+			if err != nil {
+				yield(Settings{}, err)
+				return
+			}
+
+			if cfg, ok := sec.Credentials.(Settings); ok {
+				if !yield(cfg, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// receive downloads all new messages and passes them to handle. A message is only flagged as seen or
+// deleted, if handle succeeds. Failures of individual messages are joined and do not stop the others.
+func receive(ctx context.Context, cfg Settings, handle func(raw []byte) error) error {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return fmt.Errorf("invalid mailbox url: %w", err)
+	}
+
+	switch u.Scheme {
+	case "imap", "imaps":
+		return receiveIMAP(ctx, cfg, handle)
+	case "pop3", "pop3s":
+		return receivePOP3(ctx, cfg, handle)
+	default:
+		return fmt.Errorf("unsupported mailbox url scheme: %s", u.Scheme)
+	}
+}
+
+func receiveIMAP(ctx context.Context, cfg Settings, handle func(raw []byte) error) error {
+	tlsConfig, err := cfg.tlsConfig()
+	if err != nil {
+		return err
+	}
+
+	conn, err := imap.Dial(ctx, cfg.URL, imap.Options{TLSConfig: tlsConfig, StartTLS: cfg.StartTLS})
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	if err := conn.Login(cfg.Username, cfg.Password); err != nil {
+		return fmt.Errorf("cannot login: %w", err)
+	}
+
+	if err := conn.Select(cfg.folder()); err != nil {
+		return fmt.Errorf("cannot select %s: %w", cfg.folder(), err)
+	}
+
+	uids, err := conn.SearchUnseen()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	var deleted bool
+	for _, uid := range uids {
+		if ctx.Err() != nil {
+			errs = append(errs, ctx.Err())
+			break
+		}
+
+		raw, err := conn.Fetch(uid)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot fetch message %d: %w", uid, err))
+			continue
+		}
+
+		if err := handle(raw); err != nil {
+			errs = append(errs, fmt.Errorf("cannot process message %d: %w", uid, err))
+			continue
+		}
+
+		flags := []string{`\Seen`}
+		if cfg.Delete {
+			flags = append(flags, `\Deleted`)
+			deleted = true
+		}
+
+		if err := conn.AddFlags(uid, flags...); err != nil {
+			errs = append(errs, fmt.Errorf("cannot flag message %d: %w", uid, err))
+		}
+	}
+
+	if deleted {
+		if err := conn.Expunge(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func receivePOP3(ctx context.Context, cfg Settings, handle func(raw []byte) error) error {
+	tlsConfig, err := cfg.tlsConfig()
+	if err != nil {
+		return err
+	}
+
+	conn, err := pop3.Dial(ctx, cfg.URL, pop3.Options{TLSConfig: tlsConfig, StartTLS: cfg.StartTLS})
+	if err != nil {
+		return err
+	}
+
+	if err := conn.Auth(cfg.Username, cfg.Password); err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("cannot login: %w", err)
+	}
+
+	ids, err := conn.List()
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+
+	var errs []error
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			errs = append(errs, ctx.Err())
+			break
+		}
+
+		raw, err := conn.Retr(id)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot retrieve message %d: %w", id, err))
+			continue
+		}
+
+		if err := handle(raw); err != nil {
+			errs = append(errs, fmt.Errorf("cannot process message %d: %w", id, err))
+			continue
+		}
+
+		if err := conn.Dele(id); err != nil {
+			errs = append(errs, fmt.Errorf("cannot delete message %d: %w", id, err))
+		}
+	}
+
+	// the deletions are only committed by a regular quit
+	if err := conn.Quit(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
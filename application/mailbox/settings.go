@@ -0,0 +1,67 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package mailbox
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"strings"
+
+	"github.com/worldiety/enum"
+	"go.wdy.de/nago/application/secret"
+)
+
+var _ = enum.Variant[secret.Credentials, Settings](enum.Rename[Settings]("nago.mailbox.settings"))
+
+// Settings configure a single mailbox. They are kept as [secret.Credentials] and must be shared with the
+// system group to be polled.
+type Settings struct {
+	Name     string   `json:"name" value:"Posteingang" label:"Name"`
+	URL      string   `json:"url" label:"Server URL" supportingText:"Z.B. imaps://imap.example.com oder pop3s://pop.example.com. Ohne Port werden die Standardports verwendet, also 993, 143, 995 bzw. 110."`
+	StartTLS bool     `json:"startTLS" label:"StartTLS" supportingText:"Eine imap:// oder pop3:// Verbindung vor der Anmeldung auf TLS umstellen. Ohne TLS werden Passwörter im Klartext übertragen."`
+	RootCA   string   `json:"rootCA" lines:"5" label:"CA Zertifikat" supportingText:"PEM-kodiertes Zertifikat der internen CA, falls der Server kein öffentlich vertrauenswürdiges Zertifikat verwendet."`
+	Username string   `json:"username" label:"Benutzername"`
+	Password string   `json:"password" style:"secret" label:"Passwort"`
+	Folder   string   `json:"folder" value:"INBOX" label:"Ordner" supportingText:"Nur IMAP: Der Ordner, aus dem ungelesene Nachrichten abgerufen werden."`
+	Delete   bool     `json:"delete" label:"Nach dem Abruf löschen" supportingText:"Nur IMAP: Verarbeitete Nachrichten löschen, anstatt sie als gelesen zu markieren. Bei POP3 werden verarbeitete Nachrichten immer gelöscht."`
+	_        struct{} `credentialName:"IMAP / POP3 Postfach" credentialDescription:"Ein Postfach, aus dem eingehende Nachrichten wie Antworten oder Unzustellbarkeitsberichte regelmäßig abgerufen werden." credentialLogo:"https://www.thunderbird.net/media/img/thunderbird/favicon-196.png"`
+}
+
+func (s Settings) GetName() string {
+	return s.Name
+}
+
+func (s Settings) Credentials() bool {
+	return true
+}
+
+func (s Settings) IsZero() bool {
+	return s == Settings{}
+}
+
+func (s Settings) folder() string {
+	if f := strings.TrimSpace(s.Folder); f != "" {
+		return f
+	}
+
+	return "INBOX"
+}
+
+func (s Settings) tlsConfig() (*tls.Config, error) {
+	if strings.TrimSpace(s.RootCA) == "" {
+		return nil, nil
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM([]byte(s.RootCA)) {
+		return nil, fmt.Errorf("the CA certificate of %s is not a valid PEM certificate", s.Name)
+	}
+
+	return &tls.Config{RootCAs: pool}, nil
+}
@@ -0,0 +1,208 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package mailbox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.wdy.de/nago/application/drive"
+	"go.wdy.de/nago/application/mail"
+	"go.wdy.de/nago/application/secret"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/auth"
+	"go.wdy.de/nago/pkg/data"
+	"go.wdy.de/nago/pkg/events"
+)
+
+func NewPoll(
+	ctx context.Context,
+	mutex *sync.Mutex,
+	bus events.Bus,
+	findSecrets secret.FindGroupSecrets,
+	openDrive drive.OpenDrive,
+	mkDir drive.MkDir,
+	put drive.Put,
+	stat drive.Stat,
+	findAllMails mail.FindAllMails,
+	processBounce mail.ProcessBounce,
+) Poll {
+	return func(subject auth.Subject) error {
+		if err := subject.Audit(PermPoll); err != nil {
+			return err
+		}
+
+		// a slow mailbox must not be polled concurrently by the scheduler and a manual execution
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		var errs []error
+		for cfg, err := range mailboxes(findSecrets) {
+			if err != nil {
+				return err
+			}
+
+			err := receive(ctx, cfg, func(raw []byte) error {
+				bounces, err := processBounce(user.SU(), bytes.NewReader(raw))
+				if err == nil {
+					slog.Info("mailbox received delivery status notification", "mailbox", cfg.Name, "recipients", len(bounces))
+					return nil
+				}
+
+				// a forged or foreign report is kept like any other mail, instead of marking addresses as undeliverable
+				if !errors.Is(err, mail.NotADeliveryReportErr) && !errors.Is(err, mail.UnknownBounceErr) {
+					return err
+				}
+
+				inbound, err := ParseMail(bytes.NewReader(raw))
+				if err != nil {
+					return err
+				}
+
+				inbound.ID = data.RandIdent[ID]()
+				inbound.Mailbox = cfg.Name
+				inbound.ReceivedAt = time.Now()
+
+				inbound.Outgoing, err = correlate(findAllMails, inbound)
+				if err != nil {
+					return err
+				}
+
+				if err := storeAttachments(openDrive, mkDir, put, stat, &inbound); err != nil {
+					return err
+				}
+
+				slog.Info("mailbox received mail", "mailbox", cfg.Name, "id", inbound.ID, "messageID", inbound.MessageID, "outgoing", inbound.Outgoing)
+				bus.Publish(Received{Mail: inbound})
+				return nil
+			})
+
+			if err != nil {
+				errs = append(errs, fmt.Errorf("cannot receive from mailbox %s: %w", cfg.Name, err))
+			}
+		}
+
+		return errors.Join(errs...)
+	}
+}
+
+// correlate finds the outgoing mail, which has been answered. A direct In-Reply-To match wins over the
+// References, which may contain the entire thread.
+func correlate(findAllMails mail.FindAllMails, inbound InboundMail) (mail.ID, error) {
+	if inbound.InReplyTo == "" && len(inbound.References) == 0 {
+		return "", nil
+	}
+
+	var candidate mail.ID
+	for outgoing, err := range findAllMails(user.SU()) {
+		if err != nil {
+			return "", err
+		}
+
+		id := outgoing.Mail.MessageID
+		if id == "" {
+			continue
+		}
+
+		if id == inbound.InReplyTo {
+			return outgoing.ID, nil
+		}
+
+		if candidate == "" && slices.Contains(inbound.References, id) {
+			candidate = outgoing.ID
+		}
+	}
+
+	return candidate, nil
+}
+
+// storeAttachments puts all attachments into a new directory named by the mail id within the [DriveName]
+// drive. The decoded data is released afterwards.
+func storeAttachments(openDrive drive.OpenDrive, mkDir drive.MkDir, put drive.Put, stat drive.Stat, inbound *InboundMail) error {
+	if len(inbound.Attachments) == 0 {
+		return nil
+	}
+
+	root, err := openDrive(user.SU(), drive.OpenDriveOptions{
+		Namespace: drive.NamespaceGlobal,
+		Name:      DriveName,
+		Create:    true,
+		Mode:      0600,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot open mailbox drive: %w", err)
+	}
+
+	dir, err := mkDir(user.SU(), root.Root, string(inbound.ID), drive.MkDirOptions{Mode: 0600})
+	if err != nil {
+		return fmt.Errorf("cannot create attachment directory: %w", err)
+	}
+
+	names := make([]string, len(inbound.Attachments))
+	for i, attachment := range inbound.Attachments {
+		names[i] = filename(attachment.Name, names[:i])
+		if err := put(user.SU(), dir.ID, names[i], bytes.NewReader(attachment.data), drive.PutOptions{OriginalFilename: attachment.Name}); err != nil {
+			return fmt.Errorf("cannot store attachment %s: %w", attachment.Name, err)
+		}
+	}
+
+	optDir, err := stat(user.SU(), dir.ID)
+	if err != nil {
+		return err
+	}
+
+	if optDir.IsNone() {
+		return fmt.Errorf("attachment directory has disappeared: %s: %w", dir.ID, os.ErrNotExist)
+	}
+
+	for i := range inbound.Attachments {
+		optFile, err := optDir.Unwrap().EntryByName(names[i])
+		if err != nil {
+			return err
+		}
+
+		if optFile.IsSome() {
+			inbound.Attachments[i].File = optFile.Unwrap().ID
+		}
+
+		inbound.Attachments[i].data = nil
+	}
+
+	return nil
+}
+
+// filename replaces the characters, which are not allowed by the drive, and makes the name unique.
+func filename(name string, used []string) string {
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || strings.ContainsRune(`<>:"/\|?*`, r) {
+			return '_'
+		}
+
+		return r
+	}, strings.TrimSpace(name))
+
+	if name == "" {
+		name = "attachment"
+	}
+
+	unique := name
+	for i := 2; slices.Contains(used, unique); i++ {
+		unique = strconv.Itoa(i) + "-" + name
+	}
+
+	return unique
+}
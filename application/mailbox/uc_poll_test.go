@@ -0,0 +1,292 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package mailbox
+
+import (
+	"context"
+	"io"
+	"iter"
+	netmail "net/mail"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.wdy.de/nago/application/drive"
+	"go.wdy.de/nago/application/group"
+	"go.wdy.de/nago/application/mail"
+	"go.wdy.de/nago/application/rebac"
+	"go.wdy.de/nago/application/secret"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/auth"
+	"go.wdy.de/nago/pkg/blob/fs"
+	"go.wdy.de/nago/pkg/blob/mem"
+	"go.wdy.de/nago/pkg/data/json"
+	"go.wdy.de/nago/pkg/events"
+	"go.wdy.de/nago/pkg/events/eventstest"
+	"go.wdy.de/nago/pkg/imap/imaptest"
+	"go.wdy.de/nago/pkg/pop3/pop3test"
+	"go.wdy.de/nago/pkg/xslices"
+)
+
+const testDSN = "From: Mail Delivery System <MAILER-DAEMON@example.com>\r\n" +
+	"To: nago@example.com\r\n" +
+	"Subject: Undelivered Mail Returned to Sender\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/report; report-type=delivery-status; boundary=\"B0UND\"\r\n" +
+	"\r\n" +
+	"--B0UND\r\n" +
+	"Content-Type: message/delivery-status\r\n" +
+	"\r\n" +
+	"Reporting-MTA: dns; mx.example.com\r\n" +
+	"\r\n" +
+	"Final-Recipient: rfc822; bob@example.com\r\n" +
+	"Action: failed\r\n" +
+	"Status: 5.1.1\r\n" +
+	"\r\n" +
+	"--B0UND\r\n" +
+	"Content-Type: text/rfc822-headers\r\n" +
+	"\r\n" +
+	"Message-ID: <abc@nago.example.com>\r\n" +
+	"\r\n" +
+	"--B0UND--\r\n"
+
+const testPlainReply = "From: bob@example.com\r\n" +
+	"To: nago@example.com\r\n" +
+	"Subject: Re: Ticket\r\n" +
+	"Message-ID: <reply-2@example.com>\r\n" +
+	"References: <unknown@example.com> <root@nago.example.com>\r\n" +
+	"\r\n" +
+	"thanks\r\n"
+
+func newTestPoll(t *testing.T, bus *eventstest.Recorder, cfg Settings) (Poll, drive.UseCases) {
+	t.Helper()
+
+	repo := drive.Repository(json.NewSloppyJSONRepository[drive.File, drive.FID](mem.NewBlobStore(string(drive.FileNamespace))))
+	globalRoots := drive.NamedRootRepository(json.NewSloppyJSONRepository[drive.NamedRoot, string](mem.NewBlobStore("global")))
+	userRoots := drive.UserRootRepository(json.NewSloppyJSONRepository[drive.UserRoots, user.ID](mem.NewBlobStore("userroots")))
+	blobs, err := fs.NewBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rdb, err := rebac.NewDB(mem.NewBlobStore("rebac"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	drives := drive.NewUseCases(events.NewEventBus(), repo, globalRoots, userRoots, blobs, rdb)
+
+	findSecrets := func(subject auth.Subject, gid group.ID) iter.Seq2[secret.Secret, error] {
+		return xslices.Values2[[]secret.Secret, secret.Secret, error]([]secret.Secret{{ID: "mailbox", Groups: []group.ID{group.System}, Credentials: cfg}})
+	}
+
+	outgoing := []mail.Outgoing{
+		{ID: "other", Mail: mail.Mail{MessageID: "root@nago.example.com"}},
+		{ID: "answered", Mail: mail.Mail{MessageID: "abc@nago.example.com", To: []netmail.Address{{Address: "bob@example.com"}}}},
+	}
+
+	findAllMails := func(subject auth.Subject) iter.Seq2[mail.Outgoing, error] {
+		return xslices.Values2[[]mail.Outgoing, mail.Outgoing, error](outgoing)
+	}
+
+	mails := json.NewSloppyJSONRepository[mail.Outgoing, mail.ID](mem.NewBlobStore("mails"))
+	for _, m := range outgoing {
+		if err := mails.Save(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var mutex sync.Mutex
+	poll := NewPoll(context.Background(), &mutex, bus, findSecrets, drives.OpenDrive, drives.MkDir, drives.Put, drives.Stat, findAllMails, mail.NewProcessBounce(bus, mails))
+
+	return poll, drives
+}
+
+func TestPollIMAP(t *testing.T) {
+	srv, err := imaptest.NewServer("nago", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer srv.Close()
+
+	srv.Deliver([]byte(testPlainReply))
+	srv.Deliver([]byte(testDSN))
+
+	bus := &eventstest.Recorder{}
+	poll, _ := newTestPoll(t, bus, Settings{Name: "support", URL: srv.URL(), Username: "nago", Password: "secret"})
+
+	if err := poll(user.SU()); err != nil {
+		t.Fatal(err)
+	}
+
+	var received []Received
+	var undeliverable []mail.Undeliverable
+	for _, evt := range bus.Events() {
+		switch evt := evt.(type) {
+		case Received:
+			received = append(received, evt)
+		case mail.Undeliverable:
+			undeliverable = append(undeliverable, evt)
+		}
+	}
+
+	if len(received) != 1 || len(undeliverable) != 1 {
+		t.Fatalf("unexpected events: %v", bus.Events())
+	}
+
+	if undeliverable[0].Address != "bob@example.com" {
+		t.Fatalf("unexpected bounce: %v", undeliverable[0])
+	}
+
+	inbound := received[0].Mail
+	if inbound.ID == "" || inbound.Mailbox != "support" || inbound.Outgoing != "other" || inbound.Text != "thanks\r\n" {
+		t.Fatalf("unexpected inbound mail: %+v", inbound)
+	}
+
+	for _, msg := range srv.Messages() {
+		if !slices.Contains(msg.Flags, `\Seen`) {
+			t.Fatalf("message %d has not been flagged as seen: %v", msg.UID, msg.Flags)
+		}
+	}
+
+	// a second poll must not process anything again
+	bus.Reset()
+	if err := poll(user.SU()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(bus.Events()) != 0 {
+		t.Fatalf("unexpected events: %v", bus.Events())
+	}
+}
+
+func TestPollAttachments(t *testing.T) {
+	// the drive detects the mime type using the file command
+	if _, err := exec.LookPath("file"); err != nil {
+		t.Skip("file command not available")
+	}
+
+	srv, err := imaptest.NewServer("nago", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer srv.Close()
+
+	srv.Deliver([]byte(testReply))
+
+	bus := &eventstest.Recorder{}
+	poll, drives := newTestPoll(t, bus, Settings{Name: "support", URL: srv.URL(), Username: "nago", Password: "secret", Delete: true})
+
+	if err := poll(user.SU()); err != nil {
+		t.Fatal(err)
+	}
+
+	evts := bus.Events()
+	if len(evts) != 1 {
+		t.Fatalf("unexpected events: %v", evts)
+	}
+
+	inbound := evts[0].(Received).Mail
+	if inbound.Outgoing != "answered" {
+		t.Fatalf("unexpected correlation: %s", inbound.Outgoing)
+	}
+
+	pdf := inbound.Attachments[1]
+	if pdf.File == "" || pdf.Data() != nil {
+		t.Fatalf("attachment has not been stored: %+v", pdf)
+	}
+
+	optFile, err := drives.Get(user.SU(), pdf.File, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := optFile.Unwrap().Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer reader.Close()
+
+	buf, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(buf) != "%PDF" {
+		t.Fatalf("unexpected attachment content: %q", buf)
+	}
+
+	if len(srv.Messages()) != 0 {
+		t.Fatalf("message has not been expunged")
+	}
+}
+
+func TestPollPOP3(t *testing.T) {
+	srv, err := pop3test.NewServer("nago", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer srv.Close()
+
+	srv.Deliver([]byte(testPlainReply))
+	srv.Deliver([]byte("From: a@example.com\r\nSubject: broken\r\nContent-Type: multipart/mixed\r\n\r\nno boundary\r\n"))
+
+	bus := &eventstest.Recorder{}
+	poll, _ := newTestPoll(t, bus, Settings{Name: "support", URL: srv.URL(), Username: "nago", Password: "secret"})
+
+	if err := poll(user.SU()); err == nil {
+		t.Fatal("expected error for the broken message")
+	}
+
+	if len(bus.Events()) != 1 {
+		t.Fatalf("unexpected events: %v", bus.Events())
+	}
+
+	// only the processed message has been deleted
+	if srv.Len() != 1 {
+		t.Fatalf("expected 1 remaining message but got %d", srv.Len())
+	}
+}
+
+func TestPollForgedBounce(t *testing.T) {
+	// the parts of the report are kept as attachments, whose mime type is detected using the file command
+	if _, err := exec.LookPath("file"); err != nil {
+		t.Skip("file command not available")
+	}
+
+	srv, err := imaptest.NewServer("nago", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer srv.Close()
+
+	srv.Deliver([]byte(strings.Replace(testDSN, "abc@nago.example.com", "forged@example.com", 1)))
+
+	bus := &eventstest.Recorder{}
+	poll, _ := newTestPoll(t, bus, Settings{Name: "support", URL: srv.URL(), Username: "nago", Password: "secret"})
+
+	if err := poll(user.SU()); err != nil {
+		t.Fatal(err)
+	}
+
+	evts := bus.Events()
+	if len(evts) != 1 {
+		t.Fatalf("unexpected events: %v", evts)
+	}
+
+	if _, ok := evts[0].(Received); !ok {
+		t.Fatalf("expected the report to be received as a plain mail but got %v", evts[0])
+	}
+}
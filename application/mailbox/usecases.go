@@ -0,0 +1,62 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+// Package mailbox receives mails by polling IMAP or POP3 mailboxes. Each mailbox is configured as a secret of
+// type [Settings], which must be shared with the system group. Received mails are parsed into an
+// [InboundMail], their attachments are stored in the drive and [Received] is published on the event bus, so
+// that workflows like ticketing or approvals can react on replies.
+//
+// Delivery status notifications are not published but passed to [mail.ProcessBounce], which flags the
+// according addresses as undeliverable.
+package mailbox
+
+import (
+	"context"
+	"sync"
+
+	"go.wdy.de/nago/application/drive"
+	"go.wdy.de/nago/application/mail"
+	"go.wdy.de/nago/application/secret"
+	"go.wdy.de/nago/auth"
+	"go.wdy.de/nago/pkg/events"
+)
+
+// DriveName is the name of the global drive, which contains a directory with the attachments of each
+// [InboundMail].
+const DriveName = "nago.mailbox"
+
+// Received is published for each [InboundMail], which is not a delivery status notification.
+type Received struct {
+	Mail InboundMail `json:"mail"`
+}
+
+// Poll fetches the new messages of all mailboxes. A message is only flagged as seen or deleted, after it has
+// been processed, thus a failing message is fetched again by the next poll. Failures of individual mailboxes
+// or messages are joined and do not stop the others.
+type Poll func(subject auth.Subject) error
+
+type UseCases struct {
+	Poll Poll
+}
+
+func NewUseCases(
+	ctx context.Context,
+	bus events.Bus,
+	findSecrets secret.FindGroupSecrets,
+	openDrive drive.OpenDrive,
+	mkDir drive.MkDir,
+	put drive.Put,
+	stat drive.Stat,
+	findAllMails mail.FindAllMails,
+	processBounce mail.ProcessBounce,
+) UseCases {
+	var mutex sync.Mutex
+
+	return UseCases{
+		Poll: NewPoll(ctx, &mutex, bus, findSecrets, openDrive, mkDir, put, stat, findAllMails, processBounce),
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package application
+
+import (
+	"fmt"
+
+	"go.wdy.de/nago/application/admin"
+	"go.wdy.de/nago/application/audit"
+	uiaudit "go.wdy.de/nago/application/audit/ui"
+	"go.wdy.de/nago/auth"
+	"go.wdy.de/nago/pkg/ndb"
+	"go.wdy.de/nago/pkg/ndb/msgstore"
+	"go.wdy.de/nago/presentation/core"
+	"go.wdy.de/nago/presentation/ui/layout"
+)
+
+// AuditManagement is a nago system(Audit Log).
+// It records the authorization decisions of all users, tokens and OAuth clients in a central, append-only trail:
+// who audited which permission on which resource and whether it was allowed. Each entry is chained to its
+// predecessor by a SHA-256 hash, thus modified, inserted or deleted entries are detected by the verification.
+// The trail is kept in the "audit" message engine of DataDir()/audit.ndb and can be exported as JSON Lines.
+// By default, only denied decisions are recorded. Allowed decisions are opt-in by the global audit.Settings,
+// because they make up most of all decisions.
+//
+// The engine is opened without a msgstore retention policy and thus grows without bound. This is intentional:
+// dropping the oldest segments removes the head of the chain, so that Verify reports the first remaining entry
+// as broken. Export the trail before its storage is reset.
+//
+// UseCases:
+//   - Append: Writes a decision, used by the recorder which receives all permission.Report calls.
+//   - Find, Export: Filter by user, permission, resource and time.
+//   - Verify: Checks the hash chain.
+//
+// Audit Management is part of the [Configurator.StandardSystems].
+type AuditManagement struct {
+	UseCases audit.UseCases
+	Pages    uiaudit.Pages
+}
+
+func (c *Configurator) AuditManagement() (AuditManagement, error) {
+	if c.auditManagement == nil {
+		sets, err := c.SettingsManagement()
+		if err != nil {
+			return AuditManagement{}, fmt.Errorf("cannot get settings management: %w", err)
+		}
+
+		db, err := c.OpenNDB("audit.ndb")
+		if err != nil {
+			return AuditManagement{}, err
+		}
+
+		eng, err := db.Engine("audit", ndb.EngineOptions{
+			Kind:   msgstore.EngineKind,
+			Config: msgstore.Options{},
+		})
+		if err != nil {
+			return AuditManagement{}, fmt.Errorf("cannot open audit engine: %w", err)
+		}
+
+		msgs, ok := eng.(ndb.MessageEngine)
+		if !ok {
+			return AuditManagement{}, fmt.Errorf("audit engine is not a message engine: %T", eng)
+		}
+
+		c.auditManagement = &AuditManagement{
+			UseCases: audit.NewUseCases(msgs.Messages(), sets.UseCases.LoadGlobal),
+			Pages: uiaudit.Pages{
+				AuditLog: "admin/iam/audit",
+			},
+		}
+
+		audit.StartRecorder(c.Context(), audit.RecorderOptions{}, c.auditManagement.UseCases.Append)
+
+		c.RootViewWithDecoration(c.auditManagement.Pages.AuditLog, func(wnd core.Window) core.View {
+			return layout.WithBackButton(wnd, uiaudit.PageAuditLog(wnd, c.auditManagement.UseCases))
+		})
+
+		c.AddAdminCenterGroup(func(subject auth.Subject) admin.Group {
+			if !subject.HasPermission(audit.PermFind) {
+				return admin.Group{}
+			}
+
+			return admin.Group{
+				Title: "Audit",
+				Entries: []admin.Card{
+					{Title: "Audit-Protokoll", Text: "Fälschungssicheres Protokoll aller Berechtigungsprüfungen mit Filter, Integritätsprüfung und Export.", Target: c.auditManagement.Pages.AuditLog},
+				},
+			}
+		})
+	}
+
+	return *c.auditManagement, nil
+}
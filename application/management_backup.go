@@ -26,7 +26,10 @@ import (
 //
 // UseCases:
 //   - Backup: Creates a full backup of the application. Encrypted stores remain encrypted; the master key is not included.
+//   - BackupWithOptions: Creates a full or incremental backup relative to a base index, optionally from a consistent snapshot of all stores.
+//   - ReadIndex: Reads the index of a backup, e.g. to use it as the base of an incremental backup.
 //   - Restore: Restores the application state from a backup file. This overwrites existing data. Encrypted stores require the master key.
+//   - RestoreChain: Restores a full backup together with its incremental backups.
 //   - ExportMasterKey: Returns the current Nago master key, required to decrypt encrypted stores in backups.
 //   - ReplaceMasterKey: Replaces the current master key. Encrypted stores can only be decrypted after restart with the new key.
 type BackupManagement struct {
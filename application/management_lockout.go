@@ -0,0 +1,58 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package application
+
+import (
+	"fmt"
+
+	"go.wdy.de/nago/application/lockout"
+	"go.wdy.de/nago/pkg/data/json"
+)
+
+// LockoutManagement is a nago system(Brute-Force Protection).
+// It counts failed attempts of the password login, of API tokens and of password reset codes per account and
+// per remote address. Each failure of an account doubles the delay until the next attempt is accepted and after
+// too many failures, the account or address is locked for a while. Limits and durations are configured by the
+// global lockout.Settings. Administrators can unlock accounts in the user management.
+//
+// UseCases:
+//   - Check, Fail, Succeed: Used by authenticators around the verification of credentials.
+//   - FindByKey: Returns the failed attempts and the lock of an account or address.
+//   - Unlock: Removes a lock before it expires.
+//   - Purge: Removes forgotten attempts, invoked periodically by a scheduler.
+//
+// Events of type lockout.Failed, lockout.Locked and lockout.Unlocked are published on the event bus.
+// Lockout Management is automatically initialized together with the User Management.
+type LockoutManagement struct {
+	UseCases lockout.UseCases
+}
+
+func (c *Configurator) LockoutManagement() (LockoutManagement, error) {
+	if c.lockoutManagement == nil {
+		sets, err := c.SettingsManagement()
+		if err != nil {
+			return LockoutManagement{}, fmt.Errorf("cannot get settings management: %w", err)
+		}
+
+		store, err := c.EntityStore("nago.iam.lockout")
+		if err != nil {
+			return LockoutManagement{}, fmt.Errorf("cannot get lockout store: %w", err)
+		}
+
+		repo := json.NewSloppyJSONRepository[lockout.Attempts, lockout.Key](store)
+
+		useCases := lockout.NewUseCases(c.EventBus(), repo, sets.UseCases.LoadGlobal)
+		lockout.StartScheduler(c.Context(), lockout.ScheduleOptions{}, useCases.Purge)
+
+		c.lockoutManagement = &LockoutManagement{
+			UseCases: useCases,
+		}
+	}
+
+	return *c.lockoutManagement, nil
+}
@@ -75,7 +75,7 @@ func (c *Configurator) MailManagement() (MailManagement, error) {
 			return MailManagement{}, fmt.Errorf("cannot get template management: %w", err)
 		}
 
-		mail.StartScheduler(c.Context(), mail.ScheduleOptions{}, outgoingMailRepo, c.SysUser, secrets.UseCases.FindGroupSecrets)
+		mail.StartScheduler(c.Context(), mail.ScheduleOptions{}, outgoingMailRepo, c.SysUser, secrets.UseCases.FindGroupSecrets, c.EventBus())
 
 		c.mailManagement.Pages = uimail.Pages{
 			OutgoingMailQueue: "admin/mail/outgoing",
@@ -116,6 +116,18 @@ func (c *Configurator) MailManagement() (MailManagement, error) {
 			}
 		})
 
+		events.SubscribeFor[mail.Undeliverable](c.eventBus, func(evt mail.Undeliverable) {
+			usm, err := c.UserManagement()
+			if err != nil {
+				slog.Error("mail undeliverable but cannot get user management", "err", err)
+				return
+			}
+
+			if err := usm.UseCases.MarkMailUndeliverable(user.SU(), user.Email(evt.Address), evt.Reason); err != nil {
+				slog.Error("cannot mark mail as undeliverable", "err", err)
+			}
+		})
+
 	}
 
 	return *c.mailManagement, nil
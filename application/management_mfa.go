@@ -0,0 +1,100 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package application
+
+import (
+	"fmt"
+
+	"go.wdy.de/nago/application/mfa"
+	uimfa "go.wdy.de/nago/application/mfa/ui"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/pkg/blob/crypto"
+	"go.wdy.de/nago/pkg/data/json"
+	"go.wdy.de/nago/presentation/core"
+	"go.wdy.de/nago/presentation/ui/layout"
+)
+
+// MFAManagement is a nago system(Multi-Factor Authentication).
+// It adds a second factor to the password login of the [SessionManagement].
+//
+// Users enroll an authenticator app (TOTP, RFC 6238) using a QR code or register passkeys and security keys
+// (WebAuthn). With the first factor, recovery codes are issued, which replace a lost device once each.
+// Secrets are kept in an encrypted store.
+//
+// The global mfa.Settings enforce a second factor for all users, for members of specific roles or for
+// specific users. Enforced users without a factor must enroll one during their next login. Logins through
+// single sign-on (NLS or OpenID Connect) are not affected, because the identity provider is in charge.
+//
+// UseCases:
+//   - CheckRequirement: Tells whether a user has enrolled a factor and whether it is enforced.
+//   - GenerateTOTP, ConfirmTOTP, RemoveTOTP: Manage the authenticator app.
+//   - BeginPasskeyRegistration, FinishPasskeyRegistration, RemovePasskey: Manage passkeys.
+//   - BeginPasskeyLogin, VerifyPasskey, VerifyCode: Verify the second factor at login.
+//   - RegenerateRecoveryCodes: Replaces all recovery codes.
+//   - FindMyStatus: Returns the secret-free state of the own factors.
+//   - Reset: Removes all factors of a user, e.g. after the loss of a device.
+//
+// MFA Management is automatically initialized together with the Session Management.
+type MFAManagement struct {
+	UseCases mfa.UseCases
+	Pages    uimfa.Pages
+}
+
+func (c *Configurator) MFAManagement() (MFAManagement, error) {
+	if c.mfaManagement == nil {
+		userMgmt, err := c.UserManagement()
+		if err != nil {
+			return MFAManagement{}, fmt.Errorf("cannot get user management: %w", err)
+		}
+
+		setMgmt, err := c.SettingsManagement()
+		if err != nil {
+			return MFAManagement{}, fmt.Errorf("cannot get settings management: %w", err)
+		}
+
+		plainStore, err := c.EntityStore("nago.iam.mfa")
+		if err != nil {
+			return MFAManagement{}, fmt.Errorf("cannot get mfa store: %w", err)
+		}
+
+		key, err := c.MasterKey()
+		if err != nil {
+			return MFAManagement{}, fmt.Errorf("could not load master key: %w", err)
+		}
+
+		lockouts, err := c.LockoutManagement()
+		if err != nil {
+			return MFAManagement{}, fmt.Errorf("cannot get lockout management: %w", err)
+		}
+
+		repo := json.NewSloppyJSONRepository[mfa.Enrollment, user.ID](crypto.NewBlobStore(plainStore, key))
+
+		c.mfaManagement = &MFAManagement{
+			UseCases: mfa.NewUseCases(
+				c.Name(),
+				repo,
+				setMgmt.UseCases.LoadGlobal,
+				userMgmt.UseCases.SysUser,
+				userMgmt.UseCases.FindByID,
+				userMgmt.UseCases.ListRoles,
+				lockouts.UseCases,
+			),
+			Pages: uimfa.Pages{
+				MyMFA: "account/mfa",
+			},
+		}
+
+		c.RootViewWithDecoration(c.mfaManagement.Pages.MyMFA, func(wnd core.Window) core.View {
+			return layout.WithBackButton(wnd, uimfa.PageMyMFA(wnd, c.mfaManagement.UseCases))
+		})
+
+		c.AddContextValue(core.ContextValue("", c.mfaManagement.Pages))
+	}
+
+	return *c.mfaManagement, nil
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package application
+
+import (
+	"fmt"
+	"strings"
+
+	"go.wdy.de/nago/application/admin"
+	"go.wdy.de/nago/application/oauth"
+	oauthhttp "go.wdy.de/nago/application/oauth/http"
+	uioauth "go.wdy.de/nago/application/oauth/ui"
+	"go.wdy.de/nago/auth"
+	"go.wdy.de/nago/pkg/blob"
+	"go.wdy.de/nago/pkg/blob/crypto"
+	"go.wdy.de/nago/pkg/data/json"
+	"go.wdy.de/nago/presentation/core"
+	"go.wdy.de/nago/presentation/ui/layout"
+)
+
+// OAuthManagement is a nago system(OAuth Management).
+// It lets the Nago instance act as OAuth 2.0 authorization server for its own hapi endpoints. Third-party
+// integrations are registered as clients and receive short living JWT access tokens, either through the
+// authorization code grant (with mandatory PKCE and a consent screen for the logged-in user) or through the
+// client credentials grant. Refresh tokens are rotated on each use. Scopes are permission IDs, which restrict
+// the delegated permissions of a user.
+//
+// The authorization server metadata is served at /.well-known/oauth-authorization-server.
+//
+// To accept the access tokens, use UseCases.AuthenticateSubject instead of the one of the TokenManagement
+// for hapi.BearerAuth. It delegates all other tokens to the TokenManagement, thus static API tokens keep working.
+//
+// UseCases:
+//   - CreateClient, DeleteClient, FindAllClients: Registration of third-party integrations.
+//   - StartAuthorization, FindConsent, Authorize: The authorization code flow.
+//   - Token, Revoke, FindKeySet: The protocol endpoints.
+//   - AuthenticateSubject: Bearer authentication for hapi endpoints.
+type OAuthManagement struct {
+	UseCases oauth.UseCases
+	Pages    uioauth.Pages
+}
+
+func (c *Configurator) OAuthManagement() (OAuthManagement, error) {
+	if c.oauthManagement == nil {
+		userMgmt, err := c.UserManagement()
+		if err != nil {
+			return OAuthManagement{}, fmt.Errorf("cannot get user management: %w", err)
+		}
+
+		tokenMgmt, err := c.TokenManagement()
+		if err != nil {
+			return OAuthManagement{}, fmt.Errorf("cannot get token management: %w", err)
+		}
+
+		if _, err := c.SessionManagement(); err != nil {
+			return OAuthManagement{}, fmt.Errorf("cannot get session management: %w", err)
+		}
+
+		key, err := c.MasterKey()
+		if err != nil {
+			return OAuthManagement{}, fmt.Errorf("could not load master key: %w", err)
+		}
+
+		stores := map[string]blob.Store{}
+		for _, name := range []string{"nago.iam.oauth.client", "nago.iam.oauth.request", "nago.iam.oauth.code", "nago.iam.oauth.refresh_token", "nago.iam.oauth.key"} {
+			plainStore, err := c.EntityStore(name)
+			if err != nil {
+				return OAuthManagement{}, fmt.Errorf("cannot get entity store %s: %w", name, err)
+			}
+
+			stores[name] = crypto.NewBlobStore(plainStore, key)
+		}
+
+		issuer := strings.TrimSuffix(c.ContextPathURI("", nil), "/")
+		pages := uioauth.Pages{
+			Consent: "account/oauth/consent",
+			Clients: "admin/iam/oauth/clients",
+		}
+
+		c.oauthManagement = &OAuthManagement{
+			UseCases: oauth.NewUseCases(
+				c.Context(),
+				issuer,
+				json.NewSloppyJSONRepository[oauth.Client, oauth.ClientID](stores["nago.iam.oauth.client"]),
+				json.NewSloppyJSONRepository[oauth.AuthorizationRequest, string](stores["nago.iam.oauth.request"]),
+				json.NewSloppyJSONRepository[oauth.AuthorizationCode, string](stores["nago.iam.oauth.code"]),
+				json.NewSloppyJSONRepository[oauth.RefreshToken, string](stores["nago.iam.oauth.refresh_token"]),
+				json.NewSloppyJSONRepository[oauth.SigningKey, string](stores["nago.iam.oauth.key"]),
+				userMgmt.UseCases.SubjectFromUser,
+				userMgmt.UseCases.GetAnonUser,
+				tokenMgmt.UseCases.AuthenticateSubject,
+			),
+			Pages: pages,
+		}
+
+		c.HandleFunc(oauthhttp.Endpoint, oauthhttp.NewHandler(c.oauthManagement.UseCases, issuer, string(pages.Consent)))
+		c.HandleFunc(oauthhttp.MetadataEndpoint, oauthhttp.NewMetadataHandler(issuer))
+
+		c.RootView(pages.Consent, func(wnd core.Window) core.View {
+			return uioauth.PageConsent(wnd, pages, c.oauthManagement.UseCases.FindConsent, c.oauthManagement.UseCases.Authorize)
+		})
+
+		c.RootViewWithDecoration(pages.Clients, func(wnd core.Window) core.View {
+			return layout.WithBackButton(wnd, uioauth.PageClients(wnd, c.oauthManagement.UseCases))
+		})
+
+		c.AddAdminCenterGroup(func(subject auth.Subject) admin.Group {
+			if !subject.HasPermission(oauth.PermFindAllClients) {
+				return admin.Group{}
+			}
+
+			return admin.Group{
+				Title: "OAuth",
+				Entries: []admin.Card{
+					{Title: "OAuth Clients", Text: "Drittanwendungen erhalten nach Zustimmung der Benutzer oder über eigene Client Credentials kurzlebige Access Tokens.", Target: pages.Clients},
+				},
+			}
+		})
+
+		c.AddContextValue(core.ContextValue("", c.oauthManagement.Pages))
+	}
+
+	return *c.oauthManagement, nil
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package application
+
+import (
+	"fmt"
+
+	"go.wdy.de/nago/application/oidc"
+	uioidc "go.wdy.de/nago/application/oidc/ui"
+	"go.wdy.de/nago/pkg/blob/crypto"
+	"go.wdy.de/nago/pkg/data/json"
+	"go.wdy.de/nago/presentation/core"
+)
+
+// OIDCManagement is a nago system(OpenID Connect).
+// It lets users log in through any standard OpenID Connect identity provider like Keycloak, Entra ID or
+// Authentik, using the authorization code flow with PKCE. In contrast to the Nago Login Service (NLS), no
+// additional service is required.
+//
+// Each provider is configured as oidc.Settings secret, which must be shared with the system group. The
+// redirect uri to register at the provider is <App-URL>/account/oidc/callback. Users are created or updated
+// with the same merge logic as for the NLS. Values of a groups claim can be mapped to roles and groups, for
+// which the provider becomes authoritative.
+//
+// UseCases:
+//   - FindProviders: Lists the configured providers for the login page.
+//   - StartFlow: Returns the authorization uri for a session.
+//   - Exchange: Validates the response of the provider and authenticates the session.
+//
+// OIDC Management is automatically initialized together with the Session Management.
+type OIDCManagement struct {
+	UseCases oidc.UseCases
+	Pages    uioidc.Pages
+}
+
+func (c *Configurator) OIDCManagement() (OIDCManagement, error) {
+	if c.oidcManagement == nil {
+		sessionMgmt, err := c.SessionManagement()
+		if err != nil {
+			return OIDCManagement{}, fmt.Errorf("cannot get session management: %w", err)
+		}
+
+		userMgmt, err := c.UserManagement()
+		if err != nil {
+			return OIDCManagement{}, fmt.Errorf("cannot get user management: %w", err)
+		}
+
+		secretMgmt, err := c.SecretManagement()
+		if err != nil {
+			return OIDCManagement{}, fmt.Errorf("cannot get secret management: %w", err)
+		}
+
+		plainStore, err := c.EntityStore("nago.iam.oidc.flow")
+		if err != nil {
+			return OIDCManagement{}, fmt.Errorf("cannot get oidc flow store: %w", err)
+		}
+
+		key, err := c.MasterKey()
+		if err != nil {
+			return OIDCManagement{}, fmt.Errorf("could not load master key: %w", err)
+		}
+
+		repo := json.NewSloppyJSONRepository[oidc.Flow, oidc.State](crypto.NewBlobStore(plainStore, key))
+
+		pages := uioidc.Pages{
+			Callback: "account/oidc/callback",
+		}
+
+		c.oidcManagement = &OIDCManagement{
+			UseCases: oidc.NewUseCases(
+				c.Context(),
+				c.ContextPathURI(string(pages.Callback), nil),
+				repo,
+				secretMgmt.UseCases.FindGroupSecrets,
+				userMgmt.UseCases.MergeSingleSignOnUser,
+				sessionMgmt.UseCases.LoginUser,
+				userMgmt.UseCases.SysUser,
+				userMgmt.UseCases.FindByID,
+				userMgmt.UseCases.ListRoles,
+				userMgmt.UseCases.ListGroups,
+				userMgmt.UseCases.UpdateOtherRoles,
+				userMgmt.UseCases.UpdateOtherGroups,
+			),
+			Pages: pages,
+		}
+
+		c.RootView(c.oidcManagement.Pages.Callback, func(wnd core.Window) core.View {
+			return uioidc.PageCallback(wnd, c.oidcManagement.UseCases.Exchange)
+		})
+
+		c.AddContextValue(core.ContextValue("", c.oidcManagement.Pages))
+	}
+
+	return *c.oidcManagement, nil
+}
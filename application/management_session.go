@@ -9,19 +9,26 @@ package application
 
 import (
 	"fmt"
+	"log/slog"
+	"slices"
 
+	mfahttp "go.wdy.de/nago/application/mfa/http"
+	uimfa "go.wdy.de/nago/application/mfa/ui"
 	"go.wdy.de/nago/application/session"
 	uisession "go.wdy.de/nago/application/session/ui"
+	"go.wdy.de/nago/application/user"
 	"go.wdy.de/nago/pkg/blob/crypto"
 	"go.wdy.de/nago/pkg/data/json"
+	"go.wdy.de/nago/pkg/std"
 	"go.wdy.de/nago/presentation/core"
 	"go.wdy.de/nago/presentation/ui/alert"
+	"go.wdy.de/nago/presentation/ui/layout"
 )
 
 // SessionManagement is a nago system(Session Management).
 // It provides functionality for handling user sessions,
 // including login, logout, authentication state, and Single Sign-On (SSO)
-// via the Nago Login Service (NLS).
+// via the Nago Login Service (NLS) or OpenID Connect.
 //
 // A session is identified by a unique cookie-based ID and represents the
 // persistent state of a client. This ID is stable across tabs and device restarts.
@@ -29,8 +36,13 @@ import (
 // Key features include:
 //   - Session lifecycle management (create, find, clear, timeout handling)
 //   - Authentication via email/password or direct user ID
+//   - Password verification by external directories, see [Configurator.AddPasswordAuthenticator]
+//   - Second factor for the password login, see [MFAManagement]
 //   - Single Sign-On support (start, exchange, refresh NLS flows)
+//   - OpenID Connect identity providers, see [OIDCManagement]
 //   - Logout and session invalidation
+//   - Device list with remote logout of single sessions or of a user everywhere
+//   - Idle and absolute session lifetimes, see [session.Settings], enforced by a scheduler
 //   - Tracking of creation and authentication timestamps
 //   - Storing small key-value pairs in session context
 //
@@ -40,6 +52,37 @@ type SessionManagement struct {
 	Pages    uisession.Pages
 }
 
+// AddPasswordAuthenticator installs an additional verification of passwords at the login, e.g. against an
+// external directory. The authenticators are asked in order of installation before the local password of the
+// user is checked. An authenticator must return none and no error for all users it is not responsible for,
+// otherwise its result is final. Authenticators may be added at any time, even after the session management
+// has been initialized. Failed attempts are throttled per account for all authenticators together, thus an
+// authenticator must wrap [user.InvalidCredentialsErr] for a wrong password.
+func (c *Configurator) AddPasswordAuthenticator(authenticate user.AuthenticateByPassword) *Configurator {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.passwordAuthenticators = append(c.passwordAuthenticators, authenticate)
+	return c
+}
+
+func (c *Configurator) authenticateByPassword(local user.AuthenticateByPassword) user.AuthenticateByPassword {
+	return func(email user.Email, password user.Password) (std.Option[user.User], error) {
+		c.mutex.Lock()
+		authenticators := slices.Clone(c.passwordAuthenticators)
+		c.mutex.Unlock()
+
+		for _, authenticate := range authenticators {
+			optUsr, err := authenticate(email, password)
+			if err != nil || optUsr.IsSome() {
+				return optUsr, err
+			}
+		}
+
+		return local(email, password)
+	}
+}
+
 func (c *Configurator) Authentication() (any, error) {
 	return c.SessionManagement()
 }
@@ -88,6 +131,16 @@ func (c *Configurator) SessionManagement() (SessionManagement, error) {
 
 		repoNonces, err := JSONRepository[session.NLSNonceEntry, session.NLSNonce](c, "nago.iam.nls.nonce")
 
+		mfaMgmt, err := c.MFAManagement()
+		if err != nil {
+			return SessionManagement{}, fmt.Errorf("cannot get mfa management: %w", err)
+		}
+
+		lockouts, err := c.LockoutManagement()
+		if err != nil {
+			return SessionManagement{}, fmt.Errorf("cannot get lockout management: %w", err)
+		}
+
 		useCases := session.NewUseCases(
 			c.EventBus(),
 			c.ContextPathURI("", nil),
@@ -95,7 +148,17 @@ func (c *Configurator) SessionManagement() (SessionManagement, error) {
 			userMgmt.UseCases.MergeSingleSignOnUser,
 			repo,
 			repoNonces,
-			userMgmt.UseCases.AuthenticateByPassword,
+			user.NewThrottledAuthenticateByPassword(
+				userMgmt.UseCases.FindByMail,
+				userMgmt.UseCases.SysUser,
+				lockouts.UseCases,
+				c.authenticateByPassword(user.NewAuthenticatesByPassword(userMgmt.UseCases.FindByMail, userMgmt.UseCases.SysUser)),
+			),
+			func(uid user.ID) (bool, error) {
+				req, err := mfaMgmt.UseCases.CheckRequirement(uid)
+				return req.SecondFactorRequired(), err
+			},
+			lockouts.UseCases,
 		)
 
 		c.sessionManagement = &SessionManagement{
@@ -104,6 +167,7 @@ func (c *Configurator) SessionManagement() (SessionManagement, error) {
 				Login:          "account/login",
 				Logout:         "account/logout",
 				Authentication: "account/nls/authentication",
+				MyDevices:      "account/devices",
 			},
 		}
 
@@ -112,6 +176,12 @@ func (c *Configurator) SessionManagement() (SessionManagement, error) {
 			return SessionManagement{}, fmt.Errorf("cannot get settings management: %w", err)
 		}
 
+		// the oidc management requires our use cases, thus it must be initialized after we have been assigned
+		oidcMgmt, err := c.OIDCManagement()
+		if err != nil {
+			return SessionManagement{}, fmt.Errorf("cannot get oidc management: %w", err)
+		}
+
 		c.RootView(c.sessionManagement.Pages.Login, func(wnd core.Window) core.View {
 			return uisession.Login(
 				wnd,
@@ -123,9 +193,39 @@ func (c *Configurator) SessionManagement() (SessionManagement, error) {
 				c.SendVerificationMail,
 				settingsManagement.UseCases.LoadGlobal,
 				userMgmt.Pages.Register,
+				func(wnd core.Window, onSuccess func()) core.View {
+					return uimfa.LoginStep(wnd, mfaMgmt.UseCases, c.sessionManagement.UseCases.PendingSecondFactor, c.sessionManagement.UseCases.LoginSecondFactor, onSuccess)
+				},
+				func() []uisession.ExternalLogin {
+					providers, err := oidcMgmt.UseCases.FindProviders()
+					if err != nil {
+						slog.Error("cannot find oidc providers", "err", err.Error())
+						return nil
+					}
+
+					var res []uisession.ExternalLogin
+					for _, p := range providers {
+						res = append(res, uisession.ExternalLogin{
+							Name: p.Name,
+							Start: func(id session.ID) (string, error) {
+								return oidcMgmt.UseCases.StartFlow(id, p.ID)
+							},
+						})
+					}
+
+					return res
+				},
 			)
 		})
 
+		c.HandleFunc(mfahttp.Endpoint, mfahttp.NewHandler(
+			mfaMgmt.UseCases,
+			settingsManagement.UseCases.LoadGlobal,
+			c.sessionManagement.UseCases.FindSessionByID,
+			c.sessionManagement.UseCases.PendingSecondFactor,
+			c.sessionManagement.UseCases.LoginSecondFactor,
+		))
+
 		c.RootView(c.sessionManagement.Pages.Authentication, func(wnd core.Window) core.View {
 			return uisession.PageNLSAuthentication(wnd, c.sessionManagement.UseCases.ExchangeNLS)
 		})
@@ -134,6 +234,20 @@ func (c *Configurator) SessionManagement() (SessionManagement, error) {
 			return uisession.Logout(wnd, c.sessionManagement.UseCases.Logout)
 		}))
 
+		c.RootViewWithDecoration(c.sessionManagement.Pages.MyDevices, func(wnd core.Window) core.View {
+			return layout.WithBackButton(wnd, uisession.PageMyDevices(wnd, useCases.FindUserSessions, useCases.Revoke, useCases.LogoutUser))
+		})
+
+		session.StartScheduler(c.Context(), session.ScheduleOptions{}, useCases.Expire)
+
+		// windows live long, thus also remember the interactions of the user for the device list
+		c.AddOnWindowInteractionObserver(func(wnd core.Window) {
+			id := wnd.Session().ID()
+			if err := useCases.Seen(id, string(wnd.Info().UserAgent), wnd.Info().RemoteAddr); err != nil {
+				slog.Error("cannot update session last seen", "session", id, "err", err)
+			}
+		})
+
 		c.AddOnWindowCreatedObserver(func(wnd core.Window) {
 			optSession, err := useCases.FindSessionByID(wnd.Session().ID())
 			if err != nil {
@@ -155,6 +269,10 @@ func (c *Configurator) SessionManagement() (SessionManagement, error) {
 
 			usrId := ses.User.Unwrap()
 
+			if err := useCases.Seen(ses.ID, string(wnd.Info().UserAgent), wnd.Info().RemoteAddr); err != nil {
+				slog.Error("cannot update session last seen", "session", ses.ID, "err", err)
+			}
+
 			optSubject, err := c.userManagement.UseCases.SubjectFromUser(wnd.Subject(), usrId)
 			if err != nil {
 				alert.ShowBannerError(wnd, err)
@@ -30,6 +30,8 @@ import (
 // Tokens are used to authenticate requests against REST APIs. They can carry
 // groups, roles, permissions, and licenses, similar to regular users.
 // This enables external applications or services to act as authenticated subjects.
+// Tokens can further be limited to a subset of permissions and to client networks. Their usage is counted
+// and the configured recipients are notified by mail, before a token expires.
 //
 // It is typically used together with cfghapi.Management to secure API endpoints with bearer tokens.
 type TokenManagement struct {
@@ -107,8 +109,20 @@ func (c *Configurator) TokenManagement() (TokenManagement, error) {
 			return TokenManagement{}, fmt.Errorf("cannot declare migration: %w", err)
 		}
 
+		lockouts, err := c.LockoutManagement()
+		if err != nil {
+			return TokenManagement{}, fmt.Errorf("cannot get lockout management: %w", err)
+		}
+
+		sets, err := c.SettingsManagement()
+		if err != nil {
+			return TokenManagement{}, fmt.Errorf("cannot get settings management: %w", err)
+		}
+
 		uc, err := token.NewUseCases(
 			c.Context(),
+			c.EventBus(),
+			sets.UseCases.LoadGlobal,
 			tokenRepo,
 			users.UseCases.SubjectFromUser,
 			groups.UseCases.FindByID,
@@ -116,6 +130,7 @@ func (c *Configurator) TokenManagement() (TokenManagement, error) {
 			users.UseCases.FindByID,
 			users.UseCases.GetAnonUser,
 			rdb,
+			lockouts.UseCases,
 		)
 
 		if err != nil {
@@ -123,6 +138,7 @@ func (c *Configurator) TokenManagement() (TokenManagement, error) {
 		}
 
 		rdb.RegisterResources(uc.Resources)
+		token.StartScheduler(c.Context(), token.ScheduleOptions{}, uc.FlushUsage, uc.NotifyExpiring)
 
 		c.tokenManagement = &TokenManagement{
 			UseCases: uc,
@@ -15,6 +15,7 @@ import (
 	"go.wdy.de/nago/application/migration"
 	"go.wdy.de/nago/application/permission"
 	"go.wdy.de/nago/application/rebac"
+	"go.wdy.de/nago/application/session"
 	"go.wdy.de/nago/application/settings"
 	"go.wdy.de/nago/application/user"
 	uiuser "go.wdy.de/nago/application/user/ui"
@@ -35,6 +36,7 @@ import (
 //   - Assigning roles, groups, and permissions
 //   - Managing user profile data and contact details
 //   - Password management (self-service and administrative)
+//   - Enforcing the password policy, i.e. minimum length, history, maximum age and breached passwords
 //   - Email verification and account activation notifications
 type UserManagement struct {
 	UseCases user.UseCases
@@ -86,6 +88,11 @@ func (c *Configurator) UserManagement() (UserManagement, error) {
 			}
 		})))
 
+		lockouts, err := c.LockoutManagement()
+		if err != nil {
+			return UserManagement{}, fmt.Errorf("cannot get lockout management: %w", err)
+		}
+
 		mg, err := c.Migrations()
 		if err != nil {
 			return UserManagement{}, fmt.Errorf("cannot get migrations: %w", err)
@@ -131,6 +138,7 @@ func (c *Configurator) UserManagement() (UserManagement, error) {
 				roleUseCases.UseCases.FindByID,
 				roleUseCases.UseCases.ListPermissions,
 				images.UseCases.CreateSrcSet,
+				lockouts.UseCases,
 			),
 			Pages: uiuser.Pages{
 				Users:         "admin/accounts",
@@ -144,6 +152,8 @@ func (c *Configurator) UserManagement() (UserManagement, error) {
 
 		rdb.RegisterResources(c.userManagement.UseCases.Resources)
 
+		user.StartScheduler(c.Context(), user.ScheduleOptions{}, c.userManagement.UseCases.ExpirePasswords)
+
 		c.RootViewWithDecoration(c.userManagement.Pages.MyProfile, func(wnd core.Window) core.View {
 			return layout.WithBackButton(wnd, uiuser.ProfilePage(
 				wnd,
@@ -202,11 +212,19 @@ func (c *Configurator) UserManagement() (UserManagement, error) {
 		})
 
 		c.RootViewWithDecoration(c.userManagement.Pages.Users, func(wnd core.Window) core.View {
+			// the session management depends on us, thus it is resolved lazily
+			var logoutUser session.LogoutUser
+			if c.sessionManagement != nil {
+				logoutUser = c.sessionManagement.UseCases.LogoutUser
+			}
+
 			return layout.WithBackButton(wnd, uiuser.PageUsers(wnd,
 				c.userManagement.UseCases,
 				groups.UseCases,
 				roleUseCases.UseCases,
 				permissions.UseCases,
+				lockouts.UseCases,
+				logoutUser,
 			))
 		})
 
@@ -0,0 +1,80 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package mfa
+
+import (
+	"sync"
+	"time"
+
+	"go.wdy.de/nago/pkg/webauthn"
+)
+
+const challengeTimeout = 5 * time.Minute
+
+type challengeKind int
+
+const (
+	challengeRegister challengeKind = iota + 1
+	challengeLogin
+)
+
+type challengeKey struct {
+	uid  string
+	kind challengeKind
+}
+
+type challenge struct {
+	value   webauthn.Bytes
+	expires time.Time
+}
+
+// challenges keeps the last issued WebAuthn challenge per user and ceremony in memory. Challenges are short-lived
+// and a restart just requires the user to try again, thus there is no need to persist them.
+type challenges struct {
+	mutex sync.Mutex
+	m     map[challengeKey]challenge
+}
+
+func newChallenges() *challenges {
+	return &challenges{m: map[challengeKey]challenge{}}
+}
+
+func (c *challenges) issue(uid string, kind challengeKind) (webauthn.Bytes, error) {
+	value, err := webauthn.NewChallenge()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := time.Now()
+	for k, v := range c.m {
+		if now.After(v.expires) {
+			delete(c.m, k)
+		}
+	}
+
+	c.m[challengeKey{uid: uid, kind: kind}] = challenge{value: value, expires: now.Add(challengeTimeout)}
+	return value, nil
+}
+
+// take removes the challenge, so that each one can be answered only once.
+func (c *challenges) take(uid string, kind challengeKind) (webauthn.Bytes, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	key := challengeKey{uid: uid, kind: kind}
+	v, ok := c.m[key]
+	delete(c.m, key)
+	if !ok || time.Now().After(v.expires) {
+		return nil, NoChallengeErr
+	}
+
+	return v.value, nil
+}
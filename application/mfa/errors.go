@@ -0,0 +1,39 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package mfa
+
+const (
+	InvalidCodeErr      InvalidCodeError      = "invalid code"
+	NotEnrolledErr      NotEnrolledError      = "no second factor enrolled"
+	NoChallengeErr      NoChallengeError      = "no pending challenge"
+	DuplicatePasskeyErr DuplicatePasskeyError = "passkey already registered"
+)
+
+type InvalidCodeError string
+
+func (e InvalidCodeError) Error() string {
+	return string(e)
+}
+
+type NotEnrolledError string
+
+func (e NotEnrolledError) Error() string {
+	return string(e)
+}
+
+type NoChallengeError string
+
+func (e NoChallengeError) Error() string {
+	return string(e)
+}
+
+type DuplicatePasskeyError string
+
+func (e DuplicatePasskeyError) Error() string {
+	return string(e)
+}
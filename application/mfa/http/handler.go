@@ -0,0 +1,330 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+// Package mfahttp serves the WebAuthn ceremonies. The browser API navigator.credentials is not reachable from
+// the server-driven UI, thus the UI navigates to a tiny standalone page, which talks to the JSON endpoints of
+// this package and redirects back afterward. All endpoints are bound to the session cookie.
+package mfahttp
+
+import (
+	"encoding/json"
+	"errors"
+	"html/template"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"go.wdy.de/nago/application/mfa"
+	"go.wdy.de/nago/application/session"
+	"go.wdy.de/nago/application/settings"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/pkg/webauthn"
+)
+
+// Endpoint is the path of the ceremony page. The JSON endpoints are located below.
+const Endpoint = "/api/nago/v1/mfa/passkey"
+
+const (
+	ModeRegister = "register"
+	ModeLogin    = "login"
+)
+
+// RegisterURL returns the page to register a new passkey with the given name. Afterward, the browser is
+// redirected to the given path.
+func RegisterURL(name string, redirect string) string {
+	return Endpoint + "?" + url.Values{"mode": {ModeRegister}, "name": {name}, "redirect": {redirect}}.Encode()
+}
+
+// LoginURL returns the page to complete a pending login with a passkey.
+func LoginURL(redirect string) string {
+	return Endpoint + "?" + url.Values{"mode": {ModeLogin}, "redirect": {redirect}}.Encode()
+}
+
+// NewHandler returns the handler for [Endpoint] and all paths below.
+//
+// A logged-in user can always register additional passkeys. A session with a pending login can assert an existing
+// passkey, or register its first one if the settings enforce a second factor and the user has not enrolled yet.
+// In both pending cases, a successful ceremony completes the login.
+func NewHandler(uc mfa.UseCases, loadGlobal settings.LoadGlobal, findSession session.FindByID, pending session.PendingSecondFactor, loginSecondFactor session.LoginSecondFactor) http.HandlerFunc {
+	h := handler{uc: uc, loadGlobal: loadGlobal, findSession: findSession, pending: pending, loginSecondFactor: loginSecondFactor}
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == Endpoint && r.Method == http.MethodGet:
+			h.page(w, r)
+		case r.URL.Path == Endpoint+"/options" && r.Method == http.MethodPost:
+			h.options(w, r)
+		case r.URL.Path == Endpoint+"/verify" && r.Method == http.MethodPost:
+			h.verify(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	}
+}
+
+type handler struct {
+	uc                mfa.UseCases
+	loadGlobal        settings.LoadGlobal
+	findSession       session.FindByID
+	pending           session.PendingSecondFactor
+	loginSecondFactor session.LoginSecondFactor
+}
+
+// ceremony describes, what the session is allowed to do.
+type ceremony struct {
+	sessionID session.ID
+	uid       user.ID
+	// completesLogin is true, if a successful ceremony finishes a pending login.
+	completesLogin bool
+}
+
+func (h handler) ceremony(r *http.Request, mode string) (ceremony, error) {
+	cookie, _ := r.Cookie("wdy-ora-access")
+	if cookie == nil || cookie.Value == "" {
+		return ceremony{}, session.NotLoggedInErr
+	}
+
+	sid := session.ID(cookie.Value)
+	optSession, err := h.findSession(sid)
+	if err != nil {
+		return ceremony{}, err
+	}
+
+	if optSession.IsNone() {
+		return ceremony{}, session.NotLoggedInErr
+	}
+
+	if usr := optSession.Unwrap().User; usr.IsSome() && mode == ModeRegister {
+		return ceremony{sessionID: sid, uid: usr.Unwrap()}, nil
+	}
+
+	optPending, err := h.pending(sid)
+	if err != nil {
+		return ceremony{}, err
+	}
+
+	if optPending.IsNone() {
+		return ceremony{}, session.NotLoggedInErr
+	}
+
+	uid := optPending.Unwrap()
+	if mode == ModeRegister {
+		// security note: a pending user must never add a factor, which would bypass an existing one
+		req, err := h.uc.CheckRequirement(uid)
+		if err != nil {
+			return ceremony{}, err
+		}
+
+		if req.Enrolled() || !req.Enforced {
+			return ceremony{}, user.PermissionDeniedErr
+		}
+	}
+
+	return ceremony{sessionID: sid, uid: uid, completesLogin: true}, nil
+}
+
+// relyingParty uses the configured values and falls back to the host of the request.
+func (h handler) relyingParty(r *http.Request) webauthn.RelyingParty {
+	cfg := settings.ReadGlobal[mfa.Settings](h.loadGlobal)
+
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+
+	hostname := r.Host
+	if host, _, err := net.SplitHostPort(r.Host); err == nil {
+		hostname = host
+	}
+
+	rp := webauthn.RelyingParty{
+		ID:      cfg.RelyingPartyID,
+		Name:    cfg.Issuer,
+		Origins: cfg.Origins,
+	}
+
+	if rp.ID == "" {
+		rp.ID = hostname
+	}
+
+	if rp.Name == "" {
+		rp.Name = rp.ID
+	}
+
+	if len(rp.Origins) == 0 {
+		rp.Origins = []string{scheme + "://" + r.Host}
+	}
+
+	return rp
+}
+
+// sameOrigin rejects cross-site requests. Browsers always send the origin header for fetch POST requests.
+func sameOrigin(r *http.Request, rp webauthn.RelyingParty) bool {
+	origin := r.Header.Get("Origin")
+	return origin == "" || slices.Contains(rp.Origins, origin)
+}
+
+func (h handler) options(w http.ResponseWriter, r *http.Request) {
+	mode := r.URL.Query().Get("mode")
+	rp := h.relyingParty(r)
+	if !sameOrigin(r, rp) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+
+	c, err := h.ceremony(r, mode)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var opts any
+	switch mode {
+	case ModeRegister:
+		opts, err = h.uc.BeginPasskeyRegistration(c.uid, rp)
+	case ModeLogin:
+		opts, err = h.uc.BeginPasskeyLogin(c.uid, rp)
+	default:
+		http.Error(w, "invalid mode", http.StatusBadRequest)
+		return
+	}
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(opts); err != nil {
+		slog.Error("cannot encode passkey options", "err", err.Error())
+	}
+}
+
+func (h handler) verify(w http.ResponseWriter, r *http.Request) {
+	mode := r.URL.Query().Get("mode")
+	rp := h.relyingParty(r)
+	if !sameOrigin(r, rp) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+
+	c, err := h.ceremony(r, mode)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024))
+	var ok bool
+	switch mode {
+	case ModeRegister:
+		var resp webauthn.RegistrationResponse
+		if err := dec.Decode(&resp); err != nil {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+
+		finish := func(uid user.ID) (bool, error) {
+			if err := h.uc.FinishPasskeyRegistration(uid, rp, r.URL.Query().Get("name"), resp); err != nil {
+				return false, err
+			}
+
+			return true, nil
+		}
+
+		if c.completesLogin {
+			ok, err = h.loginSecondFactor(c.sessionID, finish)
+		} else {
+			ok, err = finish(c.uid)
+		}
+	case ModeLogin:
+		var resp webauthn.AssertionResponse
+		if err := dec.Decode(&resp); err != nil {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+
+		ok, err = h.loginSecondFactor(c.sessionID, func(uid user.ID) (bool, error) {
+			return h.uc.VerifyPasskey(uid, rp, resp)
+		})
+	default:
+		http.Error(w, "invalid mode", http.StatusBadRequest)
+		return
+	}
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if !ok {
+		http.Error(w, "passkey rejected", http.StatusUnauthorized)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	var permErr interface{ PermissionDenied() bool }
+	switch {
+	case errors.As(err, &permErr) && permErr.PermissionDenied():
+		http.Error(w, "forbidden", http.StatusForbidden)
+	case errors.Is(err, mfa.NoChallengeErr), errors.Is(err, mfa.NotEnrolledErr), errors.Is(err, mfa.DuplicatePasskeyErr):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case strings.HasPrefix(err.Error(), "webauthn:"):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+	default:
+		slog.Error("passkey ceremony failed", "err", err.Error())
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
+
+// safeRedirect only accepts local absolute paths, to avoid an open redirect.
+func safeRedirect(s string) string {
+	if !strings.HasPrefix(s, "/") || strings.HasPrefix(s, "//") || strings.HasPrefix(s, "/\\") {
+		return "/"
+	}
+
+	return s
+}
+
+func (h handler) page(w http.ResponseWriter, r *http.Request) {
+	mode := r.URL.Query().Get("mode")
+	if mode != ModeRegister && mode != ModeLogin {
+		http.Error(w, "invalid mode", http.StatusBadRequest)
+		return
+	}
+
+	data := pageData{
+		Register: mode == ModeRegister,
+		Mode:     mode,
+		Endpoint: Endpoint,
+		Name:     r.URL.Query().Get("name"),
+		Redirect: safeRedirect(r.URL.Query().Get("redirect")),
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Frame-Options", "DENY")
+	if err := pageTemplate.Execute(w, data); err != nil {
+		slog.Error("cannot render passkey page", "err", err.Error())
+	}
+}
+
+type pageData struct {
+	Register bool
+	Mode     string
+	Endpoint string
+	Name     string
+	Redirect string
+}
+
+var pageTemplate = template.Must(template.New("passkey").Parse(pageHTML))
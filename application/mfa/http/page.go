@@ -0,0 +1,107 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package mfahttp
+
+// pageHTML is intentionally self-contained, because it is shown outside the regular frontend.
+const pageHTML = `<!DOCTYPE html>
+<html lang="de">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Passkey</title>
+<style>
+body{font-family:system-ui,sans-serif;display:flex;min-height:100vh;margin:0;align-items:center;justify-content:center;background:#f4f4f5;color:#18181b}
+main{background:#fff;border-radius:1rem;padding:2rem;max-width:22rem;box-shadow:0 1px 4px rgba(0,0,0,.15);text-align:center}
+button{font-size:1rem;padding:.6rem 1.2rem;border-radius:.5rem;border:none;background:#18181b;color:#fff;cursor:pointer}
+a{display:block;margin-top:1rem;color:#52525b;font-size:.9rem}
+#err{color:#b91c1c;min-height:1.2rem}
+</style>
+</head>
+<body>
+<main>
+<h1>{{if .Register}}Passkey hinzufügen{{else}}Mit Passkey anmelden{{end}}</h1>
+<p>{{if .Register}}Registrieren Sie einen Passkey oder Sicherheitsschlüssel für Ihr Konto.{{else}}Bestätigen Sie die Anmeldung mit Ihrem Passkey oder Sicherheitsschlüssel.{{end}}</p>
+<p id="err"></p>
+<button id="start" type="button">Fortfahren</button>
+<a href="{{.Redirect}}">Abbrechen</a>
+</main>
+<script>
+(function () {
+	const endpoint = {{.Endpoint}};
+	const mode = {{.Mode}};
+	const name = {{.Name}};
+	const redirect = {{.Redirect}};
+
+	function decode(s) {
+		s = s.replace(/-/g, "+").replace(/_/g, "/");
+		s += "=".repeat((4 - s.length % 4) % 4);
+		return Uint8Array.from(atob(s), c => c.charCodeAt(0));
+	}
+
+	function encode(buf) {
+		let s = "";
+		new Uint8Array(buf).forEach(b => s += String.fromCharCode(b));
+		return btoa(s).replace(/\+/g, "-").replace(/\//g, "_").replace(/=+$/, "");
+	}
+
+	async function run() {
+		if (!window.PublicKeyCredential) {
+			throw new Error("Dieser Browser unterstützt keine Passkeys.");
+		}
+
+		const res = await fetch(endpoint + "/options?mode=" + encodeURIComponent(mode), {method: "POST", credentials: "same-origin"});
+		if (!res.ok) {
+			throw new Error(await res.text());
+		}
+
+		const opts = await res.json();
+		opts.challenge = decode(opts.challenge);
+		let body;
+		if (mode === "register") {
+			opts.user.id = decode(opts.user.id);
+			(opts.excludeCredentials || []).forEach(c => c.id = decode(c.id));
+			const cred = await navigator.credentials.create({publicKey: opts});
+			body = {
+				id: encode(cred.rawId),
+				clientDataJSON: encode(cred.response.clientDataJSON),
+				attestationObject: encode(cred.response.attestationObject),
+			};
+		} else {
+			(opts.allowCredentials || []).forEach(c => c.id = decode(c.id));
+			const cred = await navigator.credentials.get({publicKey: opts});
+			body = {
+				id: encode(cred.rawId),
+				clientDataJSON: encode(cred.response.clientDataJSON),
+				authenticatorData: encode(cred.response.authenticatorData),
+				signature: encode(cred.response.signature),
+			};
+		}
+
+		const verify = await fetch(endpoint + "/verify?mode=" + encodeURIComponent(mode) + "&name=" + encodeURIComponent(name), {
+			method: "POST",
+			credentials: "same-origin",
+			headers: {"Content-Type": "application/json"},
+			body: JSON.stringify(body),
+		});
+
+		if (!verify.ok) {
+			throw new Error(await verify.text());
+		}
+
+		window.location.replace(redirect);
+	}
+
+	document.getElementById("start").addEventListener("click", () => {
+		document.getElementById("err").textContent = "";
+		run().catch(e => document.getElementById("err").textContent = e.message || String(e));
+	});
+})();
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,125 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+// Package mfa provides the second factor of the password login. Users can enroll an authenticator app (TOTP)
+// and passkeys or security keys (WebAuthn). Recovery codes are issued, so that a lost device does not lock
+// out a user. Whether a second factor is mandatory is configured per user and per role, see [Settings].
+package mfa
+
+import (
+	"time"
+
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/pkg/data"
+	"go.wdy.de/nago/pkg/webauthn"
+)
+
+// Enrollment contains all second factors of a single user. It contains secrets and must only be stored
+// in an encrypted store.
+type Enrollment struct {
+	ID            user.ID        `json:"id"`
+	TOTP          TOTP           `json:"totp,omitzero"`
+	RecoveryCodes []RecoveryCode `json:"recoveryCodes,omitempty"`
+	Passkeys      []Passkey      `json:"passkeys,omitempty"`
+	// UserHandle is the random WebAuthn user handle, which must not contain personal information.
+	UserHandle webauthn.Bytes `json:"userHandle,omitempty"`
+}
+
+func (e Enrollment) Identity() user.ID {
+	return e.ID
+}
+
+// Enrolled returns true, if at least one second factor has been set up. Recovery codes alone do not count.
+func (e Enrollment) Enrolled() bool {
+	return e.TOTP.Enabled() || len(e.Passkeys) > 0
+}
+
+// RecoveryCodesLeft returns the amount of unused recovery codes.
+func (e Enrollment) RecoveryCodesLeft() int {
+	n := 0
+	for _, code := range e.RecoveryCodes {
+		if code.UsedAt.IsZero() {
+			n++
+		}
+	}
+
+	return n
+}
+
+type TOTP struct {
+	// Secret is the base32 encoded shared secret.
+	Secret    string    `json:"secret,omitempty"`
+	EnabledAt time.Time `json:"enabledAt,omitzero"`
+	// LastCounter is the time step of the last accepted code, which protects against replays.
+	LastCounter uint64 `json:"lastCounter,omitempty"`
+}
+
+func (t TOTP) Enabled() bool {
+	return t.Secret != ""
+}
+
+type RecoveryCode struct {
+	Hash   []byte    `json:"hash"`
+	UsedAt time.Time `json:"usedAt,omitzero"`
+}
+
+type Passkey struct {
+	Name       string              `json:"name,omitempty"`
+	Credential webauthn.Credential `json:"credential"`
+	CreatedAt  time.Time           `json:"createdAt"`
+	LastUsedAt time.Time           `json:"lastUsedAt,omitzero"`
+}
+
+// PasskeyID is the base64url encoded credential id.
+type PasskeyID string
+
+func (p Passkey) ID() PasskeyID {
+	return PasskeyID(p.Credential.ID.String())
+}
+
+type Repository data.Repository[Enrollment, user.ID]
+
+// Requirement describes the second factor state of a user at login time.
+type Requirement struct {
+	// TOTP is true, if the user has set up an authenticator app.
+	TOTP bool
+	// Passkey is true, if the user has registered at least one passkey.
+	Passkey bool
+	// Enforced is true, if the settings require a second factor for the user.
+	Enforced bool
+}
+
+// Enrolled returns true, if the user has set up any second factor.
+func (r Requirement) Enrolled() bool {
+	return r.TOTP || r.Passkey
+}
+
+// SecondFactorRequired returns true, if the password alone is not sufficient. If the factor is enforced but
+// the user has not enrolled yet, the enrollment must happen before the login completes.
+func (r Requirement) SecondFactorRequired() bool {
+	return r.Enrolled() || r.Enforced
+}
+
+// PasskeyInfo is the public part of a [Passkey].
+type PasskeyInfo struct {
+	ID         PasskeyID
+	Name       string
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+}
+
+// Status is the secret-free view of an [Enrollment].
+type Status struct {
+	Requirement       Requirement
+	TOTPEnabledAt     time.Time
+	RecoveryCodesLeft int
+	Passkeys          []PasskeyInfo
+}
+
+func (s Status) TOTPEnabled() bool {
+	return !s.TOTPEnabledAt.IsZero()
+}
@@ -0,0 +1,14 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package mfa
+
+import "go.wdy.de/nago/application/permission"
+
+var (
+	PermReset = permission.Declare[Reset]("nago.mfa.reset", "Zwei-Faktor-Authentifizierung zurücksetzen", "Träger dieser Berechtigung können alle zweiten Faktoren eines Nutzers entfernen, z.B. wenn das Gerät verloren wurde.")
+)
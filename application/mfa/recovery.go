@@ -0,0 +1,91 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package mfa
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"strings"
+
+	"go.wdy.de/nago/application/user"
+)
+
+const (
+	recoveryCodeCount = 10
+	// recoveryAlphabet avoids characters which are easily confused, like 0/o or 1/l.
+	recoveryAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+)
+
+// load returns the enrollment of the user or an empty one.
+func load(repo Repository, uid user.ID) (Enrollment, error) {
+	optEnrollment, err := repo.FindByID(uid)
+	if err != nil {
+		return Enrollment{}, fmt.Errorf("cannot load mfa enrollment: %w", err)
+	}
+
+	if optEnrollment.IsNone() {
+		return Enrollment{ID: uid}, nil
+	}
+
+	return optEnrollment.Unwrap(), nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+func hashRecoveryCode(code string) []byte {
+	sum := sha256.Sum256([]byte(normalizeRecoveryCode(code)))
+	return sum[:]
+}
+
+// newRecoveryCodes returns the plaintext codes in the form xxxxx-xxxxx and their hashes, which are stored.
+func newRecoveryCodes() ([]string, []RecoveryCode, error) {
+	var plain []string
+	var hashed []RecoveryCode
+	buf := make([]byte, 10)
+	for range recoveryCodeCount {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, fmt.Errorf("cannot read random bytes: %w", err)
+		}
+
+		var sb strings.Builder
+		for i, b := range buf {
+			if i == 5 {
+				sb.WriteByte('-')
+			}
+			// the modulo bias is negligible for 31 characters and 50 bits per code
+			sb.WriteByte(recoveryAlphabet[int(b)%len(recoveryAlphabet)])
+		}
+
+		plain = append(plain, sb.String())
+		hashed = append(hashed, RecoveryCode{Hash: hashRecoveryCode(sb.String())})
+	}
+
+	return plain, hashed, nil
+}
+
+// useRecoveryCode marks the matching unused code as used and returns true.
+func (e *Enrollment) useRecoveryCode(code string) bool {
+	hash := hashRecoveryCode(code)
+	for i, rc := range e.RecoveryCodes {
+		if !rc.UsedAt.IsZero() {
+			continue
+		}
+
+		if subtle.ConstantTimeCompare(rc.Hash, hash) == 1 {
+			e.RecoveryCodes[i].UsedAt = now()
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,34 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package mfa
+
+import (
+	"github.com/worldiety/enum"
+	"go.wdy.de/nago/application/role"
+	"go.wdy.de/nago/application/settings"
+	"go.wdy.de/nago/application/user"
+)
+
+var _ = enum.Variant[settings.GlobalSettings, Settings](
+	enum.Rename[Settings]("nago.mfa.settings"),
+)
+
+type Settings struct {
+	_ any `title:"Zwei-Faktor-Authentifizierung" description:"Vorgaben für den zweiten Faktor bei der Anmeldung mit Kennwort. Nutzer können jederzeit selbst eine Authenticator-App oder Passkeys einrichten."`
+
+	RequiredForAll bool      `section:"Pflicht" json:"requiredForAll" label:"Für alle Nutzer erzwingen" supportingText:"Jeder Nutzer muss bei der nächsten Anmeldung einen zweiten Faktor einrichten und verwenden. Anmeldungen über SSO sind davon nicht betroffen."`
+	RequiredRoles  []role.ID `section:"Pflicht" json:"requiredRoles" source:"nago.roles" label:"Für Rollen erzwingen" supportingText:"Mitglieder dieser Rollen müssen einen zweiten Faktor verwenden."`
+	RequiredUsers  []user.ID `section:"Pflicht" json:"requiredUsers" source:"nago.users" label:"Für Nutzer erzwingen" supportingText:"Diese Nutzer müssen einen zweiten Faktor verwenden."`
+
+	Issuer string `section:"Authenticator-App" json:"issuer" label:"Aussteller" supportingText:"Dieser Name wird in der Authenticator-App angezeigt. Wenn leer, wird der Hostname verwendet."`
+
+	RelyingPartyID string   `section:"Passkeys" json:"relyingPartyId" label:"Relying Party ID" supportingText:"Die Domain, an die Passkeys gebunden werden, z.B. example.com. Wenn leer, wird der Hostname der Anfrage verwendet. Eine Änderung macht alle vorhandenen Passkeys ungültig."`
+	Origins        []string `section:"Passkeys" json:"origins" lines:"3" label:"Erlaubte Origins" supportingText:"Jede Zeile enthält einen erlaubten Origin, z.B. https://www.example.com. Wenn leer, wird nur der Origin der Anfrage akzeptiert."`
+}
+
+func (s Settings) GlobalSettings() bool { return true }
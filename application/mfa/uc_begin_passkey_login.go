@@ -0,0 +1,38 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package mfa
+
+import (
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/pkg/webauthn"
+)
+
+func NewBeginPasskeyLogin(repo Repository, challenges *challenges) BeginPasskeyLogin {
+	return func(uid user.ID, rp webauthn.RelyingParty) (webauthn.RequestOptions, error) {
+		enrollment, err := load(repo, uid)
+		if err != nil {
+			return webauthn.RequestOptions{}, err
+		}
+
+		if len(enrollment.Passkeys) == 0 {
+			return webauthn.RequestOptions{}, NotEnrolledErr
+		}
+
+		challenge, err := challenges.issue(string(uid), challengeLogin)
+		if err != nil {
+			return webauthn.RequestOptions{}, err
+		}
+
+		var allow []webauthn.Bytes
+		for _, passkey := range enrollment.Passkeys {
+			allow = append(allow, passkey.Credential.ID)
+		}
+
+		return webauthn.NewRequestOptions(rp, challenge, allow), nil
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package mfa
+
+import (
+	"sync"
+
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/pkg/webauthn"
+)
+
+func NewBeginPasskeyRegistration(mutex *sync.Mutex, repo Repository, challenges *challenges, sysUser user.SysUser, findUserByID user.FindByID) BeginPasskeyRegistration {
+	return func(uid user.ID, rp webauthn.RelyingParty) (webauthn.CreationOptions, error) {
+		optUsr, err := findUserByID(sysUser(), uid)
+		if err != nil {
+			return webauthn.CreationOptions{}, err
+		}
+
+		if optUsr.IsNone() {
+			return webauthn.CreationOptions{}, user.InvalidSubjectErr
+		}
+
+		usr := optUsr.Unwrap()
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		enrollment, err := load(repo, uid)
+		if err != nil {
+			return webauthn.CreationOptions{}, err
+		}
+
+		if len(enrollment.UserHandle) == 0 {
+			// the handle is just random, because the spec forbids personal information like the mail address
+			handle, err := webauthn.NewChallenge()
+			if err != nil {
+				return webauthn.CreationOptions{}, err
+			}
+
+			enrollment.UserHandle = handle
+			if err := repo.Save(enrollment); err != nil {
+				return webauthn.CreationOptions{}, err
+			}
+		}
+
+		challenge, err := challenges.issue(string(uid), challengeRegister)
+		if err != nil {
+			return webauthn.CreationOptions{}, err
+		}
+
+		var exclude []webauthn.Bytes
+		for _, passkey := range enrollment.Passkeys {
+			exclude = append(exclude, passkey.Credential.ID)
+		}
+
+		displayName := usr.String()
+		return webauthn.NewCreationOptions(rp, challenge, enrollment.UserHandle, string(usr.Email), displayName, exclude), nil
+	}
+}
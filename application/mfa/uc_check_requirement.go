@@ -0,0 +1,49 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package mfa
+
+import (
+	"slices"
+
+	"go.wdy.de/nago/application/settings"
+	"go.wdy.de/nago/application/user"
+)
+
+func NewCheckRequirement(repo Repository, loadGlobal settings.LoadGlobal, sysUser user.SysUser, listRoles user.ListRoles) CheckRequirement {
+	return func(uid user.ID) (Requirement, error) {
+		enrollment, err := load(repo, uid)
+		if err != nil {
+			return Requirement{}, err
+		}
+
+		req := Requirement{TOTP: enrollment.TOTP.Enabled(), Passkey: len(enrollment.Passkeys) > 0}
+
+		cfg := settings.ReadGlobal[Settings](loadGlobal)
+		if cfg.RequiredForAll || slices.Contains(cfg.RequiredUsers, uid) {
+			req.Enforced = true
+			return req, nil
+		}
+
+		if len(cfg.RequiredRoles) == 0 {
+			return req, nil
+		}
+
+		for rid, err := range listRoles(sysUser(), uid) {
+			if err != nil {
+				return Requirement{}, err
+			}
+
+			if slices.Contains(cfg.RequiredRoles, rid) {
+				req.Enforced = true
+				break
+			}
+		}
+
+		return req, nil
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package mfa
+
+import (
+	"sync"
+	"time"
+
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/pkg/otp"
+)
+
+// now is replaced by tests.
+var now = time.Now
+
+func NewConfirmTOTP(mutex *sync.Mutex, repo Repository) ConfirmTOTP {
+	return func(uid user.ID, secret string, code string) ([]string, error) {
+		key, err := otp.ParseSecret(secret)
+		if err != nil {
+			return nil, err
+		}
+
+		counter, ok := otp.TOTP{}.Validate(key, code, now())
+		if !ok {
+			return nil, InvalidCodeErr
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		enrollment, err := load(repo, uid)
+		if err != nil {
+			return nil, err
+		}
+
+		enrollment.TOTP = TOTP{
+			Secret:      key.String(),
+			EnabledAt:   now(),
+			LastCounter: counter,
+		}
+
+		var codes []string
+		if enrollment.RecoveryCodesLeft() == 0 {
+			var hashed []RecoveryCode
+			codes, hashed, err = newRecoveryCodes()
+			if err != nil {
+				return nil, err
+			}
+
+			enrollment.RecoveryCodes = hashed
+		}
+
+		if err := repo.Save(enrollment); err != nil {
+			return nil, err
+		}
+
+		return codes, nil
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package mfa
+
+import (
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/auth"
+)
+
+func NewFindMyStatus(repo Repository, checkRequirement CheckRequirement) FindMyStatus {
+	return func(subject auth.Subject) (Status, error) {
+		if !subject.Valid() {
+			return Status{}, user.InvalidSubjectErr
+		}
+
+		enrollment, err := load(repo, subject.ID())
+		if err != nil {
+			return Status{}, err
+		}
+
+		req, err := checkRequirement(subject.ID())
+		if err != nil {
+			return Status{}, err
+		}
+
+		status := Status{
+			Requirement:       req,
+			TOTPEnabledAt:     enrollment.TOTP.EnabledAt,
+			RecoveryCodesLeft: enrollment.RecoveryCodesLeft(),
+		}
+
+		for _, passkey := range enrollment.Passkeys {
+			status.Passkeys = append(status.Passkeys, PasskeyInfo{
+				ID:         passkey.ID(),
+				Name:       passkey.Name,
+				CreatedAt:  passkey.CreatedAt,
+				LastUsedAt: passkey.LastUsedAt,
+			})
+		}
+
+		return status, nil
+	}
+}
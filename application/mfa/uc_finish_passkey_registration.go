@@ -0,0 +1,58 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package mfa
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/pkg/webauthn"
+)
+
+func NewFinishPasskeyRegistration(mutex *sync.Mutex, repo Repository, challenges *challenges) FinishPasskeyRegistration {
+	return func(uid user.ID, rp webauthn.RelyingParty, name string, resp webauthn.RegistrationResponse) error {
+		challenge, err := challenges.take(string(uid), challengeRegister)
+		if err != nil {
+			return err
+		}
+
+		cred, err := webauthn.VerifyRegistration(rp, challenge, resp)
+		if err != nil {
+			return err
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		enrollment, err := load(repo, uid)
+		if err != nil {
+			return err
+		}
+
+		for _, passkey := range enrollment.Passkeys {
+			if bytes.Equal(passkey.Credential.ID, cred.ID) {
+				return DuplicatePasskeyErr
+			}
+		}
+
+		name = strings.TrimSpace(name)
+		if name == "" {
+			name = "Passkey"
+		}
+
+		enrollment.Passkeys = append(enrollment.Passkeys, Passkey{
+			Name:       name,
+			Credential: cred,
+			CreatedAt:  now(),
+		})
+
+		return repo.Save(enrollment)
+	}
+}
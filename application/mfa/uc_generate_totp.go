@@ -0,0 +1,42 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package mfa
+
+import (
+	"go.wdy.de/nago/application/settings"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/pkg/otp"
+)
+
+func NewGenerateTOTP(defaultIssuer string, loadGlobal settings.LoadGlobal, sysUser user.SysUser, findUserByID user.FindByID) GenerateTOTP {
+	return func(uid user.ID) (TOTPSetup, error) {
+		optUsr, err := findUserByID(sysUser(), uid)
+		if err != nil {
+			return TOTPSetup{}, err
+		}
+
+		if optUsr.IsNone() {
+			return TOTPSetup{}, user.InvalidSubjectErr
+		}
+
+		issuer := defaultIssuer
+		if cfg := settings.ReadGlobal[Settings](loadGlobal); cfg.Issuer != "" {
+			issuer = cfg.Issuer
+		}
+
+		secret, err := otp.NewSecret()
+		if err != nil {
+			return TOTPSetup{}, err
+		}
+
+		return TOTPSetup{
+			Secret: secret.String(),
+			URI:    otp.TOTP{}.KeyURI(secret, issuer, string(optUsr.Unwrap().Email)),
+		}, nil
+	}
+}
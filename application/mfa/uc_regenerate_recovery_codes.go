@@ -0,0 +1,47 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package mfa
+
+import (
+	"sync"
+
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/auth"
+)
+
+func NewRegenerateRecoveryCodes(mutex *sync.Mutex, repo Repository) RegenerateRecoveryCodes {
+	return func(subject auth.Subject) ([]string, error) {
+		if !subject.Valid() {
+			return nil, user.InvalidSubjectErr
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		enrollment, err := load(repo, subject.ID())
+		if err != nil {
+			return nil, err
+		}
+
+		if !enrollment.Enrolled() {
+			return nil, NotEnrolledErr
+		}
+
+		codes, hashed, err := newRecoveryCodes()
+		if err != nil {
+			return nil, err
+		}
+
+		enrollment.RecoveryCodes = hashed
+		if err := repo.Save(enrollment); err != nil {
+			return nil, err
+		}
+
+		return codes, nil
+	}
+}
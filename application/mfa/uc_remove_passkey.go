@@ -0,0 +1,38 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package mfa
+
+import (
+	"slices"
+	"sync"
+
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/auth"
+)
+
+func NewRemovePasskey(mutex *sync.Mutex, repo Repository) RemovePasskey {
+	return func(subject auth.Subject, id PasskeyID) error {
+		if !subject.Valid() {
+			return user.InvalidSubjectErr
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		enrollment, err := load(repo, subject.ID())
+		if err != nil {
+			return err
+		}
+
+		enrollment.Passkeys = slices.DeleteFunc(enrollment.Passkeys, func(passkey Passkey) bool {
+			return passkey.ID() == id
+		})
+
+		return repo.Save(enrollment)
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package mfa
+
+import (
+	"sync"
+
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/auth"
+)
+
+func NewRemoveTOTP(mutex *sync.Mutex, repo Repository) RemoveTOTP {
+	return func(subject auth.Subject) error {
+		if !subject.Valid() {
+			return user.InvalidSubjectErr
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		enrollment, err := load(repo, subject.ID())
+		if err != nil {
+			return err
+		}
+
+		enrollment.TOTP = TOTP{}
+		return repo.Save(enrollment)
+	}
+}
@@ -0,0 +1,28 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package mfa
+
+import (
+	"sync"
+
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/auth"
+)
+
+func NewReset(mutex *sync.Mutex, repo Repository) Reset {
+	return func(subject auth.Subject, uid user.ID) error {
+		if err := subject.Audit(PermReset); err != nil {
+			return err
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		return repo.DeleteByID(uid)
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package mfa
+
+import (
+	"strings"
+	"sync"
+
+	"go.wdy.de/nago/application/lockout"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/pkg/otp"
+)
+
+func NewVerifyCode(mutex *sync.Mutex, repo Repository, throttle lockout.UseCases) VerifyCode {
+	return func(uid user.ID, code string) (bool, error) {
+		code = strings.TrimSpace(code)
+		if code == "" {
+			return false, nil
+		}
+
+		// security note: the password has already been verified, thus the codes are the only thing left to guess.
+		// Failures share the account counter, so that a locked account cannot be opened by either factor.
+		key := lockout.UserKey(string(uid))
+		if err := throttle.Check(key); err != nil {
+			return false, err
+		}
+
+		ok, err := verifyCode(mutex, repo, uid, code)
+		if err != nil {
+			return false, err
+		}
+
+		if !ok {
+			return false, throttle.Fail(key)
+		}
+
+		return true, throttle.Succeed(key)
+	}
+}
+
+func verifyCode(mutex *sync.Mutex, repo Repository, uid user.ID, code string) (bool, error) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	enrollment, err := load(repo, uid)
+	if err != nil {
+		return false, err
+	}
+
+	if enrollment.TOTP.Enabled() {
+		key, err := otp.ParseSecret(enrollment.TOTP.Secret)
+		if err != nil {
+			return false, err
+		}
+
+		if counter, ok := (otp.TOTP{}).ValidateAfter(key, code, now(), enrollment.TOTP.LastCounter); ok {
+			enrollment.TOTP.LastCounter = counter
+			return true, repo.Save(enrollment)
+		}
+	}
+
+	if enrollment.useRecoveryCode(code) {
+		return true, repo.Save(enrollment)
+	}
+
+	return false, nil
+}
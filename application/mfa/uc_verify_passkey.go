@@ -0,0 +1,53 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package mfa
+
+import (
+	"bytes"
+	"log/slog"
+	"sync"
+
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/pkg/webauthn"
+)
+
+func NewVerifyPasskey(mutex *sync.Mutex, repo Repository, challenges *challenges) VerifyPasskey {
+	return func(uid user.ID, rp webauthn.RelyingParty, resp webauthn.AssertionResponse) (bool, error) {
+		challenge, err := challenges.take(string(uid), challengeLogin)
+		if err != nil {
+			return false, err
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		enrollment, err := load(repo, uid)
+		if err != nil {
+			return false, err
+		}
+
+		for i, passkey := range enrollment.Passkeys {
+			if !bytes.Equal(passkey.Credential.ID, resp.ID) {
+				continue
+			}
+
+			count, err := webauthn.VerifyAssertion(rp, challenge, passkey.Credential, resp)
+			if err != nil {
+				// security note: a failed assertion is an ordinary wrong second factor and not an internal error
+				slog.Warn("passkey assertion rejected", "user", uid, "passkey", passkey.ID(), "err", err.Error())
+				return false, nil
+			}
+
+			enrollment.Passkeys[i].Credential.SignCount = count
+			enrollment.Passkeys[i].LastUsedAt = now()
+			return true, repo.Save(enrollment)
+		}
+
+		return false, nil
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package uimfa
+
+import (
+	"errors"
+
+	"go.wdy.de/nago/application/mfa"
+	mfahttp "go.wdy.de/nago/application/mfa/http"
+	"go.wdy.de/nago/application/session"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/presentation/core"
+	"go.wdy.de/nago/presentation/ui"
+	"go.wdy.de/nago/presentation/ui/alert"
+)
+
+// LoginStep asks for the second factor of the pending login of the current session. If the settings enforce a
+// second factor but the user has none yet, the user must enroll one first. Afterward, onSuccess is invoked.
+func LoginStep(wnd core.Window, uc mfa.UseCases, pending session.PendingSecondFactor, loginSecondFactor session.LoginSecondFactor, onSuccess func()) core.View {
+	// the codes of a new enrollment are shown after the login has been completed, thus nothing is pending anymore
+	codes := core.AutoState[[]string](wnd)
+	codesPresented := core.AutoState[bool](wnd)
+	if codesPresented.Get() {
+		return recoveryCodesDialog(codes, codesPresented, onSuccess)
+	}
+
+	optUid, err := pending(wnd.Session().ID())
+	if err != nil {
+		return alert.BannerError(err)
+	}
+
+	if optUid.IsNone() {
+		return alert.Banner("Anmeldung abgelaufen", "Bitte melden Sie sich erneut an.").Intent(alert.IntentWarning)
+	}
+
+	req, err := uc.CheckRequirement(optUid.Unwrap())
+	if err != nil {
+		return alert.BannerError(err)
+	}
+
+	if !req.Enrolled() {
+		return enrollStep(wnd, uc, optUid.Unwrap(), loginSecondFactor, onSuccess, func(newCodes []string) {
+			codes.Set(newCodes)
+			codesPresented.Set(true)
+		})
+	}
+
+	code := core.AutoState[string](wnd)
+	codeErr := core.AutoState[string](wnd)
+
+	verify := func() {
+		ok, err := loginSecondFactor(wnd.Session().ID(), func(uid user.ID) (bool, error) {
+			return uc.VerifyCode(uid, code.Get())
+		})
+
+		if err != nil {
+			alert.ShowBannerError(wnd, err)
+			return
+		}
+
+		if !ok {
+			codeErr.Set("Der Code ist ungültig.")
+			return
+		}
+
+		code.Set("")
+		onSuccess()
+	}
+
+	return ui.VStack(
+		ui.Text("Bestätigen Sie die Anmeldung mit Ihrem zweiten Faktor."),
+		ui.IfFunc(req.TOTP, func() core.View {
+			return ui.TextField("Code aus der Authenticator-App", code.Get()).
+				InputValue(code).
+				ErrorText(codeErr.Get()).
+				ID("nago-mfa-code").
+				KeydownEnter(verify).
+				Frame(ui.Frame{}.FullWidth())
+		}),
+		ui.IfFunc(!req.TOTP, func() core.View {
+			return ui.TextField("Wiederherstellungscode", code.Get()).
+				InputValue(code).
+				ErrorText(codeErr.Get()).
+				KeydownEnter(verify).
+				Frame(ui.Frame{}.FullWidth())
+		}),
+		ui.If(req.TOTP, ui.Text("Alternativ können Sie einen Wiederherstellungscode eingeben.").Font(ui.Small)),
+		ui.PrimaryButton(verify).Title("Bestätigen").Frame(ui.Frame{}.FullWidth()),
+		ui.IfFunc(req.Passkey, func() core.View {
+			return ui.SecondaryButton(func() {
+				core.HTTPOpen(wnd.Navigation(), core.URI(mfahttp.LoginURL("/")), "_self")
+			}).Title("Mit Passkey anmelden").Frame(ui.Frame{}.FullWidth())
+		}),
+	).Gap(ui.L8).FullWidth()
+}
+
+func enrollStep(wnd core.Window, uc mfa.UseCases, uid user.ID, loginSecondFactor session.LoginSecondFactor, onSuccess func(), showCodes func([]string)) core.View {
+	setup := core.AutoState[mfa.TOTPSetup](wnd).Init(func() mfa.TOTPSetup {
+		setup, err := uc.GenerateTOTP(uid)
+		if err != nil {
+			alert.ShowBannerError(wnd, err)
+		}
+
+		return setup
+	})
+
+	code := core.AutoState[string](wnd)
+	codeErr := core.AutoState[string](wnd)
+
+	confirm := func() {
+		var newCodes []string
+		ok, err := loginSecondFactor(wnd.Session().ID(), func(uid user.ID) (bool, error) {
+			var err error
+			newCodes, err = uc.ConfirmTOTP(uid, setup.Get().Secret, code.Get())
+			if errors.Is(err, mfa.InvalidCodeErr) {
+				return false, nil
+			}
+
+			return err == nil, err
+		})
+
+		if err != nil {
+			alert.ShowBannerError(wnd, err)
+			return
+		}
+
+		if !ok {
+			codeErr.Set("Der Code ist ungültig.")
+			return
+		}
+
+		// security note: purge the secret from memory
+		setup.Set(mfa.TOTPSetup{})
+		code.Set("")
+		if len(newCodes) == 0 {
+			onSuccess()
+			return
+		}
+
+		showCodes(newCodes)
+	}
+
+	return ui.VStack(
+		alert.Banner("Zweiter Faktor erforderlich", "Für Ihr Konto ist ein zweiter Faktor vorgeschrieben. Richten Sie jetzt eine Authenticator-App oder einen Passkey ein.").Intent(alert.IntentWarning),
+		ui.IfFunc(setup.Get().Secret != "", func() core.View {
+			return totpSetupView(setup.Get(), code, codeErr.Get(), confirm)
+		}),
+		ui.PrimaryButton(confirm).Title("Bestätigen").Frame(ui.Frame{}.FullWidth()).Visible(setup.Get().Secret != ""),
+		ui.SecondaryButton(func() {
+			core.HTTPOpen(wnd.Navigation(), core.URI(mfahttp.RegisterURL("Passkey", "/")), "_self")
+		}).Title("Stattdessen Passkey einrichten").Frame(ui.Frame{}.FullWidth()),
+	).Gap(ui.L8).FullWidth()
+}
@@ -0,0 +1,212 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package uimfa
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.wdy.de/nago/application/mfa"
+	mfahttp "go.wdy.de/nago/application/mfa/http"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/presentation/core"
+	heroOutline "go.wdy.de/nago/presentation/icons/hero/outline"
+	"go.wdy.de/nago/presentation/ui"
+	"go.wdy.de/nago/presentation/ui/alert"
+	"go.wdy.de/nago/presentation/ui/list"
+)
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "nie"
+	}
+
+	return t.Local().Format("02.01.2006 15:04")
+}
+
+// PageMyMFA lets the current user manage the authenticator app, passkeys and recovery codes.
+func PageMyMFA(wnd core.Window, uc mfa.UseCases) core.View {
+	if !wnd.Subject().Valid() {
+		return alert.BannerError(user.InvalidSubjectErr)
+	}
+
+	status, err := uc.FindMyStatus(wnd.Subject())
+	if err != nil {
+		return alert.BannerError(err)
+	}
+
+	codes := core.AutoState[[]string](wnd)
+	codesPresented := core.AutoState[bool](wnd)
+	totpPresented := core.AutoState[bool](wnd)
+	totpSetup := core.AutoState[mfa.TOTPSetup](wnd)
+
+	openTOTP := func() {
+		setup, err := uc.GenerateTOTP(wnd.Subject().ID())
+		if err != nil {
+			alert.ShowBannerError(wnd, err)
+			return
+		}
+
+		totpSetup.Set(setup)
+		totpPresented.Set(true)
+	}
+
+	showCodes := func(newCodes []string) {
+		if len(newCodes) == 0 {
+			return
+		}
+
+		codes.Set(newCodes)
+		codesPresented.Set(true)
+	}
+
+	return ui.VStack(
+		ui.H1("Zwei-Faktor-Authentifizierung"),
+		recoveryCodesDialog(codes, codesPresented, nil),
+		totpDialog(wnd, uc, totpSetup, totpPresented, showCodes),
+		ui.If(status.Requirement.Enforced && !status.Requirement.Enrolled(), alert.Banner("Einrichtung erforderlich", "Für Ihr Konto ist ein zweiter Faktor vorgeschrieben. Richten Sie eine Authenticator-App oder einen Passkey ein.").Intent(alert.IntentWarning)),
+		ui.Text("Zusätzlich zum Kennwort wird bei der Anmeldung ein zweiter Faktor abgefragt, sobald Sie eine Authenticator-App oder einen Passkey eingerichtet haben."),
+
+		ui.H2("Authenticator-App"),
+		totpSection(wnd, uc, status, openTOTP),
+
+		ui.H2("Passkeys"),
+		passkeySection(wnd, uc, status),
+
+		ui.H2("Wiederherstellungscodes"),
+		recoveryCodesSection(wnd, uc, status, showCodes),
+	).Gap(ui.L20).
+		Alignment(ui.Leading).
+		Frame(ui.Frame{Width: ui.L560, MaxWidth: "100%"})
+}
+
+func totpSection(wnd core.Window, uc mfa.UseCases, status mfa.Status, openTOTP func()) core.View {
+	if !status.TOTPEnabled() {
+		return ui.PrimaryButton(openTOTP).Title("Authenticator-App einrichten")
+	}
+
+	return list.List(
+		list.Entry().
+			Headline("Eingerichtet").
+			SupportingText("seit " + formatTime(status.TOTPEnabledAt)).
+			Trailing(ui.HStack(
+				ui.TertiaryButton(openTOTP).Title("Ersetzen"),
+				ui.TertiaryButton(func() {
+					if err := uc.RemoveTOTP(wnd.Subject()); err != nil {
+						alert.ShowBannerError(wnd, err)
+						return
+					}
+
+					wnd.Navigation().Reload()
+				}).Title("Entfernen"),
+			).Gap(ui.L8)),
+	).Frame(ui.Frame{}.FullWidth())
+}
+
+func totpDialog(wnd core.Window, uc mfa.UseCases, setup *core.State[mfa.TOTPSetup], presented *core.State[bool], showCodes func([]string)) core.View {
+	if !presented.Get() {
+		return nil
+	}
+
+	code := core.AutoState[string](wnd)
+	codeErr := core.AutoState[string](wnd)
+
+	confirm := func() {
+		newCodes, err := uc.ConfirmTOTP(wnd.Subject().ID(), setup.Get().Secret, code.Get())
+		if errors.Is(err, mfa.InvalidCodeErr) {
+			codeErr.Set("Der Code ist ungültig.")
+			return
+		}
+
+		if err != nil {
+			alert.ShowBannerError(wnd, err)
+			return
+		}
+
+		// security note: purge the secret from memory
+		setup.Set(mfa.TOTPSetup{})
+		code.Set("")
+		codeErr.Set("")
+		presented.Set(false)
+		showCodes(newCodes)
+	}
+
+	return alert.Dialog("Authenticator-App einrichten", totpSetupView(setup.Get(), code, codeErr.Get(), confirm), presented,
+		alert.Width(ui.L560),
+		alert.Cancel(func() {
+			setup.Set(mfa.TOTPSetup{})
+			code.Set("")
+			codeErr.Set("")
+		}),
+		alert.Custom(func(close func(closeDlg bool)) core.View {
+			return ui.PrimaryButton(confirm).Title("Bestätigen")
+		}),
+	)
+}
+
+func passkeySection(wnd core.Window, uc mfa.UseCases, status mfa.Status) core.View {
+	name := core.AutoState[string](wnd)
+
+	var entries []core.View
+	for _, passkey := range status.Passkeys {
+		entries = append(entries, list.Entry().
+			Headline(passkey.Name).
+			SupportingText(fmt.Sprintf("Hinzugefügt am %s, zuletzt verwendet: %s", formatTime(passkey.CreatedAt), formatTime(passkey.LastUsedAt))).
+			Trailing(ui.TertiaryButton(func() {
+				if err := uc.RemovePasskey(wnd.Subject(), passkey.ID); err != nil {
+					alert.ShowBannerError(wnd, err)
+					return
+				}
+
+				wnd.Navigation().Reload()
+			}).PreIcon(heroOutline.Trash).AccessibilityLabel("Passkey entfernen")))
+	}
+
+	return ui.VStack(
+		ui.IfFunc(len(entries) > 0, func() core.View {
+			return list.List(entries...).Frame(ui.Frame{}.FullWidth())
+		}),
+		ui.HStack(
+			ui.TextField("Bezeichnung", name.Get()).
+				InputValue(name).
+				SupportingText("z.B. Laptop oder Sicherheitsschlüssel").
+				Frame(ui.Frame{}.FullWidth()),
+			ui.SecondaryButton(func() {
+				redirect := "/" + string(wnd.Path())
+				core.HTTPOpen(wnd.Navigation(), core.URI(mfahttp.RegisterURL(name.Get(), redirect)), "_self")
+			}).Title("Passkey hinzufügen"),
+		).Gap(ui.L8).Alignment(ui.Top).FullWidth(),
+	).Gap(ui.L16).FullWidth()
+}
+
+func recoveryCodesSection(wnd core.Window, uc mfa.UseCases, status mfa.Status, showCodes func([]string)) core.View {
+	if !status.Requirement.Enrolled() {
+		return ui.Text("Wiederherstellungscodes werden erstellt, sobald ein zweiter Faktor eingerichtet ist.")
+	}
+
+	text := fmt.Sprintf("%d Codes sind noch unbenutzt.", status.RecoveryCodesLeft)
+	var warning core.View
+	if status.RecoveryCodesLeft == 0 {
+		warning = alert.Banner("Keine Codes", "Ohne Wiederherstellungscodes kann der Verlust des Geräts zur Sperrung des Kontos führen.").Intent(alert.IntentWarning)
+	}
+
+	return ui.VStack(
+		warning,
+		ui.Text(text),
+		ui.SecondaryButton(func() {
+			newCodes, err := uc.RegenerateRecoveryCodes(wnd.Subject())
+			if err != nil {
+				alert.ShowBannerError(wnd, err)
+				return
+			}
+
+			showCodes(newCodes)
+		}).Title("Neue Codes erzeugen"),
+	).Gap(ui.L8).Alignment(ui.Leading)
+}
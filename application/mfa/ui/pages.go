@@ -0,0 +1,16 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package uimfa
+
+import (
+	"go.wdy.de/nago/presentation/core"
+)
+
+type Pages struct {
+	MyMFA core.NavigationPath
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package uimfa
+
+import (
+	"go.wdy.de/nago/application/mfa"
+	"go.wdy.de/nago/presentation/core"
+	"go.wdy.de/nago/presentation/ui"
+	"go.wdy.de/nago/presentation/ui/alert"
+)
+
+// totpSetupView shows the QR code of a new secret together with the input of the first code.
+func totpSetupView(setup mfa.TOTPSetup, code *core.State[string], errText string, onConfirm func()) core.View {
+	return ui.VStack(
+		ui.Text("Scannen Sie den QR-Code mit Ihrer Authenticator-App, z.B. Google Authenticator, Microsoft Authenticator oder 1Password. Alternativ können Sie den Schlüssel manuell eingeben."),
+		ui.QrCode(setup.URI).AccessibilityLabel("QR-Code für die Authenticator-App").Frame(ui.Frame{Width: ui.L200, Height: ui.L200}),
+		ui.Text(setup.Secret).Font(ui.Small),
+		ui.TextField("Code aus der App", code.Get()).
+			InputValue(code).
+			ErrorText(errText).
+			KeydownEnter(onConfirm).
+			Frame(ui.Frame{}.FullWidth()),
+	).Gap(ui.L16).FullWidth()
+}
+
+// recoveryCodesDialog presents the plaintext codes exactly once. The codes are purged from the state, when the
+// dialog is closed.
+func recoveryCodesDialog(codes *core.State[[]string], presented *core.State[bool], onClose func()) core.View {
+	if !presented.Get() {
+		return nil
+	}
+
+	return alert.Dialog("Wiederherstellungscodes", recoveryCodesBody(codes.Get()), presented,
+		alert.Width(ui.L560),
+		alert.Custom(func(close func(closeDlg bool)) core.View {
+			return ui.PrimaryButton(func() {
+				codes.Set(nil)
+				close(true)
+				if onClose != nil {
+					onClose()
+				}
+			}).Title("Codes gesichert")
+		}),
+	)
+}
+
+func recoveryCodesBody(codes []string) core.View {
+	var rows []core.View
+	for _, code := range codes {
+		rows = append(rows, ui.Text(code).Font(ui.Monospace))
+	}
+
+	return ui.VStack(
+		alert.Banner("Wichtig", "Bewahren Sie diese Wiederherstellungscodes sicher auf. Jeder Code kann einmalig statt des zweiten Faktors verwendet werden. Die Codes werden nur jetzt angezeigt.").Intent(alert.IntentWarning),
+		ui.VStack(rows...).Gap(ui.L4),
+	).Gap(ui.L16).FullWidth()
+}
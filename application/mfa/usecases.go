@@ -0,0 +1,106 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package mfa
+
+import (
+	"sync"
+
+	"go.wdy.de/nago/application/lockout"
+	"go.wdy.de/nago/application/settings"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/auth"
+	"go.wdy.de/nago/pkg/webauthn"
+)
+
+// TOTPSetup contains a new secret, which is not stored before [ConfirmTOTP] succeeds.
+type TOTPSetup struct {
+	Secret string
+	// URI is the otpauth key uri, which is usually presented as QR code.
+	URI string
+}
+
+// CheckRequirement determines whether the given user must provide a second factor.
+type CheckRequirement func(uid user.ID) (Requirement, error)
+
+// GenerateTOTP creates a new secret for the given user.
+type GenerateTOTP func(uid user.ID) (TOTPSetup, error)
+
+// ConfirmTOTP enables the secret, if the code is valid. An existing secret is replaced. If the user has no unused
+// recovery codes, new ones are generated and returned, which must be shown exactly once.
+type ConfirmTOTP func(uid user.ID, secret string, code string) (recoveryCodes []string, err error)
+
+// RemoveTOTP disables the authenticator app of the subject.
+type RemoveTOTP func(subject auth.Subject) error
+
+// RegenerateRecoveryCodes replaces all recovery codes of the subject.
+type RegenerateRecoveryCodes func(subject auth.Subject) ([]string, error)
+
+// VerifyCode checks a TOTP or a recovery code. Each code can be used only once. Wrong codes are counted per
+// account, see [lockout.UserKey], and a locked account returns [lockout.TooManyAttemptsErr] even for a valid code.
+type VerifyCode func(uid user.ID, code string) (bool, error)
+
+// BeginPasskeyRegistration issues a challenge for a new passkey of the given user.
+type BeginPasskeyRegistration func(uid user.ID, rp webauthn.RelyingParty) (webauthn.CreationOptions, error)
+
+// FinishPasskeyRegistration verifies the response to the last issued registration challenge and stores the passkey.
+type FinishPasskeyRegistration func(uid user.ID, rp webauthn.RelyingParty, name string, resp webauthn.RegistrationResponse) error
+
+// BeginPasskeyLogin issues a challenge for any passkey of the given user.
+type BeginPasskeyLogin func(uid user.ID, rp webauthn.RelyingParty) (webauthn.RequestOptions, error)
+
+// VerifyPasskey verifies the response to the last issued login challenge.
+type VerifyPasskey func(uid user.ID, rp webauthn.RelyingParty, resp webauthn.AssertionResponse) (bool, error)
+
+// RemovePasskey removes a passkey of the subject.
+type RemovePasskey func(subject auth.Subject, id PasskeyID) error
+
+// FindMyStatus returns the enrolled factors of the subject.
+type FindMyStatus func(subject auth.Subject) (Status, error)
+
+// Reset removes all second factors of the given user, e.g. if the device has been lost. See [PermReset].
+type Reset func(subject auth.Subject, uid user.ID) error
+
+type UseCases struct {
+	CheckRequirement          CheckRequirement
+	GenerateTOTP              GenerateTOTP
+	ConfirmTOTP               ConfirmTOTP
+	RemoveTOTP                RemoveTOTP
+	RegenerateRecoveryCodes   RegenerateRecoveryCodes
+	VerifyCode                VerifyCode
+	BeginPasskeyRegistration  BeginPasskeyRegistration
+	FinishPasskeyRegistration FinishPasskeyRegistration
+	BeginPasskeyLogin         BeginPasskeyLogin
+	VerifyPasskey             VerifyPasskey
+	RemovePasskey             RemovePasskey
+	FindMyStatus              FindMyStatus
+	Reset                     Reset
+}
+
+// NewUseCases creates the mfa use cases. The defaultIssuer is shown in authenticator apps, unless the
+// [Settings] define another one.
+func NewUseCases(defaultIssuer string, repo Repository, loadGlobal settings.LoadGlobal, sysUser user.SysUser, findUserByID user.FindByID, listRoles user.ListRoles, throttle lockout.UseCases) UseCases {
+	var mutex sync.Mutex
+	challenges := newChallenges()
+	checkRequirementFn := NewCheckRequirement(repo, loadGlobal, sysUser, listRoles)
+
+	return UseCases{
+		CheckRequirement:          checkRequirementFn,
+		GenerateTOTP:              NewGenerateTOTP(defaultIssuer, loadGlobal, sysUser, findUserByID),
+		ConfirmTOTP:               NewConfirmTOTP(&mutex, repo),
+		RemoveTOTP:                NewRemoveTOTP(&mutex, repo),
+		RegenerateRecoveryCodes:   NewRegenerateRecoveryCodes(&mutex, repo),
+		VerifyCode:                NewVerifyCode(&mutex, repo, throttle),
+		BeginPasskeyRegistration:  NewBeginPasskeyRegistration(&mutex, repo, challenges, sysUser, findUserByID),
+		FinishPasskeyRegistration: NewFinishPasskeyRegistration(&mutex, repo, challenges),
+		BeginPasskeyLogin:         NewBeginPasskeyLogin(repo, challenges),
+		VerifyPasskey:             NewVerifyPasskey(&mutex, repo, challenges),
+		RemovePasskey:             NewRemovePasskey(&mutex, repo),
+		FindMyStatus:              NewFindMyStatus(repo, checkRequirementFn),
+		Reset:                     NewReset(&mutex, repo),
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package mfa
+
+import (
+	"errors"
+	"iter"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/worldiety/option"
+	"go.wdy.de/nago/application/lockout"
+	"go.wdy.de/nago/application/permission"
+	"go.wdy.de/nago/application/role"
+	"go.wdy.de/nago/application/settings"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/pkg/blob/mem"
+	"go.wdy.de/nago/pkg/data/json"
+	"go.wdy.de/nago/pkg/events"
+	"go.wdy.de/nago/pkg/otp"
+)
+
+// testAccountLimit is the number of wrong codes, after which the account of the test use cases is locked.
+const testAccountLimit = 3
+
+func newTestUseCases(cfg Settings, roles map[user.ID][]role.ID) UseCases {
+	repo := json.NewSloppyJSONRepository[Enrollment, user.ID](mem.NewBlobStore("mfa"))
+	loadGlobal := func(subject permission.Auditable, t reflect.Type) (settings.GlobalSettings, error) {
+		return cfg, nil
+	}
+
+	findByID := func(subject permission.Auditable, id user.ID) (option.Opt[user.User], error) {
+		return option.Some(user.User{ID: id, Email: user.Email(string(id) + "@example.com")}), nil
+	}
+
+	listRoles := func(subject user.AuditableUser, uid user.ID) iter.Seq2[role.ID, error] {
+		return func(yield func(role.ID, error) bool) {
+			for _, rid := range roles[uid] {
+				if !yield(rid, nil) {
+					return
+				}
+			}
+		}
+	}
+
+	attempts := json.NewSloppyJSONRepository[lockout.Attempts, lockout.Key](mem.NewBlobStore("lockout"))
+	throttle := lockout.NewUseCases(events.NewEventBus(), attempts, func(subject permission.Auditable, t reflect.Type) (settings.GlobalSettings, error) {
+		return lockout.Settings{AccountLimit: testAccountLimit, Delay: time.Nanosecond, MaxDelay: time.Nanosecond}, nil
+	})
+
+	return NewUseCases("ACME", repo, loadGlobal, user.SU, findByID, listRoles, throttle)
+}
+
+func TestTOTPAndRecoveryCodes(t *testing.T) {
+	uc := newTestUseCases(Settings{}, nil)
+	uid := user.ID("alice")
+
+	setup := option.Must(uc.GenerateTOTP(uid))
+	key := option.Must(otp.ParseSecret(setup.Secret))
+
+	if _, err := uc.ConfirmTOTP(uid, setup.Secret, "000000"); err != InvalidCodeErr {
+		// an accidental match is possible but extremely unlikely
+		t.Fatalf("expected invalid code, got %v", err)
+	}
+
+	codes := option.Must(uc.ConfirmTOTP(uid, setup.Secret, otp.TOTP{}.Code(key, time.Now())))
+	if len(codes) != recoveryCodeCount {
+		t.Fatalf("expected %d recovery codes, got %d", recoveryCodeCount, len(codes))
+	}
+
+	req := option.Must(uc.CheckRequirement(uid))
+	if !req.TOTP || req.Enforced {
+		t.Fatalf("unexpected requirement: %+v", req)
+	}
+
+	// the code of the confirmation has been consumed, thus the next period is required
+	now = func() time.Time { return time.Now().Add(30 * time.Second) }
+	defer func() { now = time.Now }()
+
+	code := otp.TOTP{}.Code(key, now())
+	if !option.Must(uc.VerifyCode(uid, code)) {
+		t.Fatal("expected code to be accepted")
+	}
+
+	if option.Must(uc.VerifyCode(uid, code)) {
+		t.Fatal("expected replayed code to be rejected")
+	}
+
+	if !option.Must(uc.VerifyCode(uid, " "+codes[0][:5]+codes[0][6:]+" ")) {
+		t.Fatal("expected recovery code to be accepted")
+	}
+
+	if option.Must(uc.VerifyCode(uid, codes[0])) {
+		t.Fatal("expected used recovery code to be rejected")
+	}
+}
+
+func TestCheckRequirement(t *testing.T) {
+	uc := newTestUseCases(Settings{RequiredRoles: []role.ID{"admin"}, RequiredUsers: []user.ID{"bob"}}, map[user.ID][]role.ID{"carol": {"staff", "admin"}})
+
+	tests := map[user.ID]bool{"alice": false, "bob": true, "carol": true}
+	for uid, enforced := range tests {
+		req := option.Must(uc.CheckRequirement(uid))
+		if req.Enforced != enforced || req.SecondFactorRequired() != enforced {
+			t.Errorf("%s: unexpected requirement %+v", uid, req)
+		}
+	}
+}
+
+func TestVerifyCodeLockout(t *testing.T) {
+	uc := newTestUseCases(Settings{}, nil)
+	uid := user.ID("alice")
+
+	setup := option.Must(uc.GenerateTOTP(uid))
+	key := option.Must(otp.ParseSecret(setup.Secret))
+	option.Must(uc.ConfirmTOTP(uid, setup.Secret, otp.TOTP{}.Code(key, time.Now())))
+
+	now = func() time.Time { return time.Now().Add(30 * time.Second) }
+	defer func() { now = time.Now }()
+
+	for range testAccountLimit {
+		time.Sleep(time.Millisecond)
+		if option.Must(uc.VerifyCode(uid, "not-a-code")) {
+			t.Fatal("expected wrong code to be rejected")
+		}
+	}
+
+	// security note: even the correct code must be rejected, otherwise guessing would just continue
+	time.Sleep(time.Millisecond)
+	if ok, err := uc.VerifyCode(uid, otp.TOTP{}.Code(key, now())); ok || !errors.Is(err, lockout.TooManyAttemptsErr) {
+		t.Fatalf("expected lock, got %v %v", ok, err)
+	}
+}
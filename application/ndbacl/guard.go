@@ -0,0 +1,264 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+// Package ndbacl enforces the per-type access control lists of an ndb message
+// engine (see [ndb.ACL]) for an authenticated subject.
+package ndbacl
+
+import (
+	"fmt"
+	"iter"
+	"log/slog"
+	"sync"
+
+	"github.com/worldiety/option"
+	"go.wdy.de/nago/application/group"
+	"go.wdy.de/nago/application/role"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/auth"
+	"go.wdy.de/nago/pkg/ndb"
+)
+
+// A TokenSubject is a subject which authenticates through an API access token
+// instead of a user account. A token subject carries the token id as its id,
+// thus the kind must be known to tell a token principal from a user principal
+// with the same id.
+type TokenSubject interface {
+	IsToken() bool
+}
+
+// Matches reports whether the principal refers to the subject. User principals
+// only match user subjects and token principals only match token subjects (see
+// [TokenSubject]) with the same id, group and role principals match a
+// membership, and [ndb.PrincipalAuthenticated] matches every valid subject.
+func Matches(subject auth.Subject, p ndb.Principal) bool {
+	if subject == nil || !subject.Valid() {
+		return false
+	}
+
+	if p == ndb.PrincipalAuthenticated {
+		return true
+	}
+
+	kind, id := p.Split()
+	if id == "" {
+		return false
+	}
+
+	switch kind {
+	case ndb.PrincipalKindUser:
+		return !isToken(subject) && string(subject.ID()) == id
+	case ndb.PrincipalKindToken:
+		return isToken(subject) && string(subject.ID()) == id
+	case ndb.PrincipalKindGroup:
+		return subject.HasGroup(group.ID(id))
+	case ndb.PrincipalKindRole:
+		return subject.HasRole(role.ID(id))
+	default:
+		return false
+	}
+}
+
+func isToken(subject auth.Subject) bool {
+	ts, ok := subject.(TokenSubject)
+	return ok && ts.IsToken()
+}
+
+// Audit returns nil if the subject may perform op on typeID, otherwise a
+// permission denied error. A type without a declared ACL denies everything,
+// so that unvetted types cannot be written or read by accident. Subjects with
+// [PermBypass] are always allowed.
+func Audit(acls ndb.ACLs, subject auth.Subject, typeID ndb.TypeID, op ndb.Operation) error {
+	if subject == nil || !subject.Valid() {
+		return user.InvalidSubjectErr
+	}
+
+	if subject.HasPermission(PermBypass) {
+		return nil
+	}
+
+	optACL, err := acls.ACL(typeID)
+	if err != nil {
+		return err
+	}
+
+	if optACL.IsNone() || !optACL.Unwrap().Allows(op, func(p ndb.Principal) bool { return Matches(subject, p) }) {
+		return deniedError(typeID, op)
+	}
+
+	return nil
+}
+
+func deniedError(typeID ndb.TypeID, op ndb.Operation) error {
+	return user.PermissionDeniedError(fmt.Sprintf("ndb %s on %s", op, typeID))
+}
+
+// Guard returns a view on msgs which enforces the ACLs from acls for the given
+// subject on every call. Denied writes and point reads return a permission
+// denied error. Because a Replay iterator cannot report an error, denied types
+// are silently left out of a Replay or Subscribe and only logged.
+//
+// Decisions are cached per type for the lifetime of the returned view, thus
+// create a guard per request or window instead of keeping it forever. The view
+// does not own msgs: its Close is a no-op.
+func Guard(msgs ndb.Messages, acls ndb.ACLs, subject auth.Subject) ndb.Messages {
+	return &guard{
+		msgs:    msgs,
+		acls:    acls,
+		subject: subject,
+		cache:   map[decision]error{},
+	}
+}
+
+type decision struct {
+	typeID ndb.TypeID
+	op     ndb.Operation
+}
+
+type guard struct {
+	msgs    ndb.Messages
+	acls    ndb.ACLs
+	subject auth.Subject
+
+	mutex sync.Mutex
+	cache map[decision]error
+}
+
+func (g *guard) audit(typeID ndb.TypeID, op ndb.Operation) error {
+	key := decision{typeID: typeID, op: op}
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if err, ok := g.cache[key]; ok {
+		return err
+	}
+
+	err := Audit(g.acls, g.subject, typeID, op)
+	g.cache[key] = err
+	return err
+}
+
+// allowed filters types to those the subject may access by op. An empty types
+// slice means all types, which are resolved to those with a declared ACL,
+// because a type without ACL is never accessible.
+func (g *guard) allowed(types []ndb.TypeID, op ndb.Operation) []ndb.TypeID {
+	if len(types) == 0 {
+		all, err := g.acls.ACLTypes()
+		if err != nil {
+			slog.Error("ndbacl: cannot list acl types", "err", err.Error())
+			return nil
+		}
+
+		types = all
+	}
+
+	out := make([]ndb.TypeID, 0, len(types))
+	for _, t := range types {
+		if err := g.audit(t, op); err != nil {
+			slog.Warn("ndbacl: type excluded", "type", t, "op", op, "subject", g.subject.ID(), "err", err.Error())
+			continue
+		}
+
+		out = append(out, t)
+	}
+
+	return out
+}
+
+func (g *guard) Append(typeID ndb.TypeID, traceID ndb.TraceID, payload []byte) (ndb.Seq, error) {
+	if err := g.audit(typeID, ndb.OpAppend); err != nil {
+		return 0, err
+	}
+
+	return g.msgs.Append(typeID, traceID, payload)
+}
+
+func (g *guard) Replay(types []ndb.TypeID, minSeq, maxSeq ndb.Seq) iter.Seq2[ndb.TypeID, ndb.Message] {
+	return func(yield func(ndb.TypeID, ndb.Message) bool) {
+		// never pass an empty slice down, which would mean all types
+		permitted := g.allowed(types, ndb.OpReplay)
+		if len(permitted) == 0 {
+			return
+		}
+
+		for t, msg := range g.msgs.Replay(permitted, minSeq, maxSeq) {
+			if !yield(t, msg) {
+				return
+			}
+		}
+	}
+}
+
+func (g *guard) Put(typeID ndb.TypeID, traceID ndb.TraceID, payload []byte) (ndb.Seq, error) {
+	if err := g.audit(typeID, ndb.OpPut); err != nil {
+		return 0, err
+	}
+
+	return g.msgs.Put(typeID, traceID, payload)
+}
+
+func (g *guard) Get(typeID ndb.TypeID) (option.Opt[ndb.Message], error) {
+	if err := g.audit(typeID, ndb.OpReplay); err != nil {
+		return option.None[ndb.Message](), err
+	}
+
+	return g.msgs.Get(typeID)
+}
+
+// SeqForTime only reveals a sequence number and no payload, thus any valid
+// subject may use it.
+func (g *guard) SeqForTime(tsNano int64) (ndb.Seq, error) {
+	if g.subject == nil || !g.subject.Valid() {
+		return 0, user.InvalidSubjectErr
+	}
+
+	return g.msgs.SeqForTime(tsNano)
+}
+
+func (g *guard) DeleteType(typeID ndb.TypeID) error {
+	if err := g.audit(typeID, ndb.OpDelete); err != nil {
+		return err
+	}
+
+	return g.msgs.DeleteType(typeID)
+}
+
+func (g *guard) DeleteSeq(typeID ndb.TypeID, seq ndb.Seq) error {
+	if err := g.audit(typeID, ndb.OpDelete); err != nil {
+		return err
+	}
+
+	return g.msgs.DeleteSeq(typeID, seq)
+}
+
+func (g *guard) Subscribe(types []ndb.TypeID, fn func(ndb.Notification)) (close func()) {
+	if len(types) > 0 {
+		permitted := g.allowed(types, ndb.OpSubscribe)
+		if len(permitted) == 0 {
+			return func() {}
+		}
+
+		return g.msgs.Subscribe(permitted, fn)
+	}
+
+	// Subscribing to all types must also include types which receive an ACL
+	// later, thus the decision is taken per notification. It is cached, so the
+	// ACL is read at most once per type and the writer is not slowed down.
+	return g.msgs.Subscribe(nil, func(n ndb.Notification) {
+		if g.audit(n.Type, ndb.OpSubscribe) != nil {
+			return
+		}
+
+		fn(n)
+	})
+}
+
+// Close is a no-op, because the guard does not own the wrapped engine.
+func (g *guard) Close() error {
+	return nil
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package ndbacl_test
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/worldiety/option"
+	"go.wdy.de/nago/application/group"
+	"go.wdy.de/nago/application/ndbacl"
+	"go.wdy.de/nago/application/permission"
+	"go.wdy.de/nago/application/role"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/pkg/ndb"
+	"go.wdy.de/nago/pkg/ndb/msgstore"
+)
+
+// testSubject only implements what the guard asks for; everything else panics
+// through the nil embedded interface.
+type testSubject struct {
+	user.Subject
+	id     user.ID
+	groups []group.ID
+	roles  []role.ID
+	perms  []permission.ID
+	token  bool
+}
+
+func (s testSubject) ID() user.ID                         { return s.id }
+func (s testSubject) Valid() bool                         { return s.id != "" }
+func (s testSubject) HasGroup(id group.ID) bool           { return slices.Contains(s.groups, id) }
+func (s testSubject) HasRole(id role.ID) bool             { return slices.Contains(s.roles, id) }
+func (s testSubject) HasPermission(id permission.ID) bool { return slices.Contains(s.perms, id) }
+func (s testSubject) Audit(id permission.ID) error        { return nil }
+func (s testSubject) String() string                      { return string(s.id) }
+func (s testSubject) IsToken() bool                       { return s.token }
+
+func openMessages(t *testing.T) (ndb.Messages, ndb.ACLs) {
+	t.Helper()
+	db := option.Must(ndb.Open(t.TempDir(), ndb.Options{}))
+	t.Cleanup(func() { option.MustZero(db.Close()) })
+
+	eng, err := db.Engine("events", ndb.EngineOptions{Kind: msgstore.EngineKind, Config: msgstore.Options{}})
+	if err != nil {
+		t.Fatalf("open engine: %v", err)
+	}
+
+	msgs := eng.(ndb.MessageEngine).Messages()
+	acls, ok := msgs.(ndb.ACLs)
+	if !ok {
+		t.Fatal("expected msgstore to persist acls")
+	}
+
+	return msgs, acls
+}
+
+func isPermissionDenied(err error) bool {
+	var pd interface{ PermissionDenied() bool }
+	return errors.As(err, &pd) && pd.PermissionDenied()
+}
+
+func TestGuardDeniesTypesWithoutACL(t *testing.T) {
+	msgs, acls := openMessages(t)
+	alice := testSubject{id: "alice"}
+
+	var tr ndb.TraceID
+	_, err := ndbacl.Guard(msgs, acls, alice).Append("orders", tr, []byte("x"))
+	if !isPermissionDenied(err) {
+		t.Fatalf("expected permission denied, got %v", err)
+	}
+
+	// bypass is allowed to write anything
+	admin := testSubject{id: "admin", perms: []permission.ID{ndbacl.PermBypass}}
+	if _, err := ndbacl.Guard(msgs, acls, admin).Append("orders", tr, []byte("x")); err != nil {
+		t.Fatal(err)
+	}
+
+	// an invalid subject is never allowed
+	if _, err := ndbacl.Guard(msgs, acls, testSubject{}).Append("orders", tr, []byte("x")); err == nil {
+		t.Fatal("expected invalid subject to be rejected")
+	}
+}
+
+func TestGuardEnforcesPrincipals(t *testing.T) {
+	msgs, acls := openMessages(t)
+
+	option.MustZero(acls.SetACL("orders", ndb.ACL{
+		Append: []ndb.Principal{ndb.NewPrincipal(ndb.PrincipalKindUser, "writer")},
+		Replay: []ndb.Principal{ndb.NewPrincipal(ndb.PrincipalKindGroup, "sales"), ndb.NewPrincipal(ndb.PrincipalKindRole, "auditor")},
+		Delete: []ndb.Principal{ndb.NewPrincipal(ndb.PrincipalKindToken, "tok-1")},
+	}))
+	option.MustZero(acls.SetACL("users", ndb.ACL{
+		Append: []ndb.Principal{ndb.PrincipalAuthenticated},
+		Replay: []ndb.Principal{ndb.PrincipalAuthenticated},
+	}))
+
+	writer := testSubject{id: "writer"}
+	seller := testSubject{id: "bob", groups: []group.ID{"sales"}}
+	auditor := testSubject{id: "carol", roles: []role.ID{"auditor"}}
+	token := testSubject{id: "tok-1", token: true}
+
+	var tr ndb.TraceID
+	seq := option.Must(ndbacl.Guard(msgs, acls, writer).Append("orders", tr, []byte("order")))
+	option.Must(ndbacl.Guard(msgs, acls, seller).Append("users", tr, []byte("user")))
+
+	if _, err := ndbacl.Guard(msgs, acls, seller).Append("orders", tr, []byte("order")); !isPermissionDenied(err) {
+		t.Fatalf("expected seller not to append orders, got %v", err)
+	}
+
+	count := func(s testSubject, types []ndb.TypeID) map[ndb.TypeID]int {
+		res := map[ndb.TypeID]int{}
+		for typ := range ndbacl.Guard(msgs, acls, s).Replay(types, 1, math.MaxUint64) {
+			res[typ]++
+		}
+		return res
+	}
+
+	if got := count(seller, nil); got["orders"] != 1 || got["users"] != 1 {
+		t.Fatalf("seller replay = %v", got)
+	}
+	if got := count(auditor, []ndb.TypeID{"orders"}); got["orders"] != 1 {
+		t.Fatalf("auditor replay = %v", got)
+	}
+
+	// the writer may only replay the types open to every authenticated subject
+	if got := count(writer, nil); got["orders"] != 0 || got["users"] != 1 {
+		t.Fatalf("writer replay = %v", got)
+	}
+	if got := count(writer, []ndb.TypeID{"orders"}); len(got) != 0 {
+		t.Fatalf("writer explicit replay = %v", got)
+	}
+
+	if err := ndbacl.Guard(msgs, acls, writer).DeleteSeq("orders", seq); !isPermissionDenied(err) {
+		t.Fatalf("expected writer not to delete, got %v", err)
+	}
+	if err := ndbacl.Guard(msgs, acls, token).DeleteSeq("orders", seq); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMatchesComparesPrincipalKind(t *testing.T) {
+	user := testSubject{id: "x"}
+	token := testSubject{id: "x", token: true}
+
+	userP := ndb.NewPrincipal(ndb.PrincipalKindUser, "x")
+	tokenP := ndb.NewPrincipal(ndb.PrincipalKindToken, "x")
+
+	if !ndbacl.Matches(user, userP) || ndbacl.Matches(user, tokenP) {
+		t.Fatal("user subject must only match the user principal")
+	}
+	if !ndbacl.Matches(token, tokenP) || ndbacl.Matches(token, userP) {
+		t.Fatal("token subject must only match the token principal")
+	}
+}
+
+func TestGuardSubscribeFiltersNotifications(t *testing.T) {
+	msgs, acls := openMessages(t)
+
+	option.MustZero(acls.SetACL("public", ndb.ACL{Subscribe: []ndb.Principal{ndb.PrincipalAuthenticated}}))
+
+	var got []ndb.TypeID
+	closeFn := ndbacl.Guard(msgs, acls, testSubject{id: "alice"}).Subscribe(nil, func(n ndb.Notification) {
+		got = append(got, n.Type)
+	})
+	defer closeFn()
+
+	var tr ndb.TraceID
+	option.Must(msgs.Append("secret", tr, []byte("x")))
+	option.Must(msgs.Append("public", tr, []byte("y")))
+
+	if !slices.Equal(got, []ndb.TypeID{"public"}) {
+		t.Fatalf("notifications = %v", got)
+	}
+}
@@ -0,0 +1,20 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package ndbacl
+
+import "go.wdy.de/nago/application/permission"
+
+// Bypass is the marker use-case type for the ACL bypass permission. The
+// permission framework requires a named func type.
+type Bypass func()
+
+var (
+	// PermBypass grants every message operation on every event type, regardless
+	// of the declared ACLs. It is intended for system tasks and maintenance.
+	PermBypass = permission.Declare[Bypass]("nago.ndb.acl.bypass", "ndb ACLs umgehen", "Träger dieser Berechtigung dürfen unabhängig von den hinterlegten Zugriffslisten auf alle Nachrichtentypen einer ndb Datenbank zugreifen.")
+)
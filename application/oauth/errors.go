@@ -0,0 +1,67 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package oauth
+
+// ErrorCode is one of the error codes of RFC 6749 section 5.2 and 4.1.2.1.
+type ErrorCode string
+
+const (
+	InvalidRequest       ErrorCode = "invalid_request"
+	InvalidClient        ErrorCode = "invalid_client"
+	InvalidGrant         ErrorCode = "invalid_grant"
+	UnauthorizedClient   ErrorCode = "unauthorized_client"
+	UnsupportedGrantType ErrorCode = "unsupported_grant_type"
+	InvalidScope         ErrorCode = "invalid_scope"
+	AccessDenied         ErrorCode = "access_denied"
+	UnsupportedResponse  ErrorCode = "unsupported_response_type"
+)
+
+// Error is returned to the client as is. The description must never contain internal details.
+type Error struct {
+	Code        ErrorCode `json:"error"`
+	Description string    `json:"error_description,omitempty"`
+}
+
+func (e Error) Error() string {
+	if e.Description == "" {
+		return string(e.Code)
+	}
+
+	return string(e.Code) + ": " + e.Description
+}
+
+func newError(code ErrorCode, desc string) error {
+	return Error{Code: code, Description: desc}
+}
+
+const (
+	UnknownClientErr        UnknownClientError        = "unknown oauth client"
+	InvalidRedirectURIErr   InvalidRedirectURIError   = "redirect uri is not registered for the client"
+	UnknownAuthorizationErr UnknownAuthorizationError = "unknown or expired authorization request"
+)
+
+// UnknownClientError is returned by the authorize endpoint, if the client id is unknown. In contrast to the
+// other errors, the user agent must not be redirected to the client.
+type UnknownClientError string
+
+func (e UnknownClientError) Error() string {
+	return string(e)
+}
+
+// InvalidRedirectURIError must not be redirected either, see RFC 6749 section 4.1.2.1.
+type InvalidRedirectURIError string
+
+func (e InvalidRedirectURIError) Error() string {
+	return string(e)
+}
+
+type UnknownAuthorizationError string
+
+func (e UnknownAuthorizationError) Error() string {
+	return string(e)
+}
@@ -0,0 +1,235 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+// Package oauthhttp serves the protocol endpoints of the OAuth 2.0 authorization server. The consent itself is
+// given within the regular session UI, to which the authorization endpoint redirects.
+package oauthhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	"go.wdy.de/nago/application/oauth"
+)
+
+// Endpoint is the prefix of all protocol endpoints.
+const Endpoint = "/api/nago/v1/oauth"
+
+// MetadataEndpoint is the well-known location of the authorization server metadata of RFC 8414.
+const MetadataEndpoint = "/.well-known/oauth-authorization-server"
+
+const (
+	AuthorizeEndpoint = Endpoint + "/authorize"
+	TokenEndpoint     = Endpoint + "/token"
+	RevokeEndpoint    = Endpoint + "/revoke"
+	JWKSEndpoint      = Endpoint + "/jwks"
+)
+
+// NewHandler returns the handler for [Endpoint] and all paths below. The consent is the path of the consent page,
+// which receives the id of the pending authorization as query parameter request.
+func NewHandler(uc oauth.UseCases, issuer string, consent string) http.HandlerFunc {
+	h := handler{uc: uc, issuer: issuer, consent: consent}
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == AuthorizeEndpoint && r.Method == http.MethodGet:
+			h.authorize(w, r)
+		case r.URL.Path == TokenEndpoint && r.Method == http.MethodPost:
+			h.token(w, r)
+		case r.URL.Path == RevokeEndpoint && r.Method == http.MethodPost:
+			h.revoke(w, r)
+		case r.URL.Path == JWKSEndpoint && r.Method == http.MethodGet:
+			h.jwks(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	}
+}
+
+type metadata struct {
+	Issuer                                 string   `json:"issuer"`
+	AuthorizationEndpoint                  string   `json:"authorization_endpoint"`
+	TokenEndpoint                          string   `json:"token_endpoint"`
+	RevocationEndpoint                     string   `json:"revocation_endpoint"`
+	JWKSURI                                string   `json:"jwks_uri"`
+	ResponseTypesSupported                 []string `json:"response_types_supported"`
+	GrantTypesSupported                    []string `json:"grant_types_supported"`
+	CodeChallengeMethodsSupported          []string `json:"code_challenge_methods_supported"`
+	TokenEndpointAuthMethodsSupported      []string `json:"token_endpoint_auth_methods_supported"`
+	AuthorizationResponseIssSupported      bool     `json:"authorization_response_iss_parameter_supported"`
+	RevocationEndpointAuthMethodsSupported []string `json:"revocation_endpoint_auth_methods_supported"`
+}
+
+// NewMetadataHandler returns the handler for [MetadataEndpoint].
+func NewMetadataHandler(issuer string) http.HandlerFunc {
+	authMethods := []string{"client_secret_basic", "client_secret_post", "none"}
+	doc := metadata{
+		Issuer:                                 issuer,
+		AuthorizationEndpoint:                  issuer + AuthorizeEndpoint,
+		TokenEndpoint:                          issuer + TokenEndpoint,
+		RevocationEndpoint:                     issuer + RevokeEndpoint,
+		JWKSURI:                                issuer + JWKSEndpoint,
+		ResponseTypesSupported:                 []string{"code"},
+		GrantTypesSupported:                    []string{string(oauth.GrantAuthorizationCode), string(oauth.GrantClientCredentials), string(oauth.GrantRefreshToken)},
+		CodeChallengeMethodsSupported:          []string{"S256"},
+		TokenEndpointAuthMethodsSupported:      authMethods,
+		AuthorizationResponseIssSupported:      true,
+		RevocationEndpointAuthMethodsSupported: authMethods,
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, doc)
+	}
+}
+
+type handler struct {
+	uc      oauth.UseCases
+	issuer  string
+	consent string
+}
+
+func (h handler) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	req, err := h.uc.StartAuthorization(oauth.AuthorizationParams{
+		ResponseType:        q.Get("response_type"),
+		ClientID:            oauth.ClientID(q.Get("client_id")),
+		RedirectURI:         q.Get("redirect_uri"),
+		Scope:               q.Get("scope"),
+		State:               q.Get("state"),
+		CodeChallenge:       q.Get("code_challenge"),
+		CodeChallengeMethod: q.Get("code_challenge_method"),
+	})
+
+	var oerr oauth.Error
+	switch {
+	case err == nil:
+		http.Redirect(w, r, "/"+h.consent+"?"+url.Values{"request": {req.ID}}.Encode(), http.StatusFound)
+	case errors.Is(err, oauth.UnknownClientErr), errors.Is(err, oauth.InvalidRedirectURIErr):
+		// security note: never redirect to an unverified uri
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.As(err, &oerr) && req.RedirectURI != "":
+		uri, err := oauth.ErrorRedirectURI(req, h.issuer, oerr)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		http.Redirect(w, r, uri, http.StatusFound)
+	default:
+		slog.Error("oauth authorization failed", "err", err.Error())
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
+
+func (h handler) token(w http.ResponseWriter, r *http.Request) {
+	id, secret, ok := clientCredentials(w, r)
+	if !ok {
+		return
+	}
+
+	res, err := h.uc.Token(oauth.TokenRequest{
+		GrantType:    oauth.GrantType(r.PostForm.Get("grant_type")),
+		Code:         r.PostForm.Get("code"),
+		RedirectURI:  r.PostForm.Get("redirect_uri"),
+		CodeVerifier: r.PostForm.Get("code_verifier"),
+		RefreshToken: r.PostForm.Get("refresh_token"),
+		Scope:        r.PostForm.Get("scope"),
+		ClientID:     id,
+		ClientSecret: secret,
+	})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (h handler) revoke(w http.ResponseWriter, r *http.Request) {
+	id, secret, ok := clientCredentials(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.uc.Revoke(id, secret, r.PostForm.Get("token")); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h handler) jwks(w http.ResponseWriter, r *http.Request) {
+	set, err := h.uc.FindKeySet()
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	writeJSON(w, http.StatusOK, set)
+}
+
+// clientCredentials parses the form and reads the client authentication of RFC 6749 section 2.3.1, either from
+// the basic auth header or from the form body, but never both.
+func clientCredentials(w http.ResponseWriter, r *http.Request) (oauth.ClientID, string, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, 64*1024)
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, oauth.Error{Code: oauth.InvalidRequest, Description: "invalid form body"})
+		return "", "", false
+	}
+
+	user, pass, ok := r.BasicAuth()
+	if !ok {
+		return oauth.ClientID(r.PostForm.Get("client_id")), r.PostForm.Get("client_secret"), true
+	}
+
+	if r.PostForm.Has("client_secret") {
+		writeJSON(w, http.StatusBadRequest, oauth.Error{Code: oauth.InvalidRequest, Description: "multiple client authentication methods"})
+		return "", "", false
+	}
+
+	// the credentials are form encoded before they are put into the header
+	id, err1 := url.QueryUnescape(user)
+	secret, err2 := url.QueryUnescape(pass)
+	if err1 != nil || err2 != nil {
+		writeJSON(w, http.StatusBadRequest, oauth.Error{Code: oauth.InvalidRequest, Description: "invalid basic auth encoding"})
+		return "", "", false
+	}
+
+	return oauth.ClientID(id), secret, true
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	var oerr oauth.Error
+	if !errors.As(err, &oerr) {
+		slog.Error("oauth endpoint failed", "path", r.URL.Path, "err", err.Error())
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	status := http.StatusBadRequest
+	if oerr.Code == oauth.InvalidClient {
+		status = http.StatusUnauthorized
+		if _, _, ok := r.BasicAuth(); ok {
+			w.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+		}
+	}
+
+	writeJSON(w, status, oerr)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("cannot encode oauth response", "err", err.Error())
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package oauth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"go.wdy.de/nago/pkg/data"
+	"go.wdy.de/nago/pkg/jwt"
+)
+
+// keys holds the signing keys of the access tokens. The newest key signs, but all stored keys are published and
+// accepted, so that a key can be rotated by just inserting a new one.
+type keys struct {
+	repo SigningKeyRepository
+
+	mutex   sync.Mutex
+	loaded  bool
+	current string
+	signers map[string]crypto.Signer
+}
+
+func newKeys(repo SigningKeyRepository) *keys {
+	return &keys{repo: repo}
+}
+
+func (k *keys) load() error {
+	if k.loaded {
+		return nil
+	}
+
+	signers := map[string]crypto.Signer{}
+	var newest SigningKey
+	for key, err := range k.repo.All() {
+		if err != nil {
+			return fmt.Errorf("cannot read signing key: %w", err)
+		}
+
+		pk, err := x509.ParsePKCS8PrivateKey(key.PKCS8)
+		if err != nil {
+			return fmt.Errorf("cannot parse signing key %s: %w", key.ID, err)
+		}
+
+		signer, ok := pk.(crypto.Signer)
+		if !ok {
+			return fmt.Errorf("signing key %s is not a signer", key.ID)
+		}
+
+		signers[key.ID] = signer
+		if newest.ID == "" || key.CreatedAt.After(newest.CreatedAt) {
+			newest = key
+		}
+	}
+
+	if newest.ID == "" {
+		pk, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return err
+		}
+
+		buf, err := x509.MarshalPKCS8PrivateKey(pk)
+		if err != nil {
+			return err
+		}
+
+		newest = SigningKey{ID: data.RandIdent[string](), PKCS8: buf, CreatedAt: now()}
+		if err := k.repo.Save(newest); err != nil {
+			return fmt.Errorf("cannot save signing key: %w", err)
+		}
+
+		signers[newest.ID] = pk
+	}
+
+	k.current = newest.ID
+	k.signers = signers
+	k.loaded = true
+
+	return nil
+}
+
+func (k *keys) sign(claims any) (string, error) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	if err := k.load(); err != nil {
+		return "", err
+	}
+
+	return jwt.Sign(jwt.Header{Kid: k.current, Typ: accessTokenType}, claims, k.signers[k.current])
+}
+
+func (k *keys) publicKey(hdr jwt.Header) (crypto.PublicKey, error) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	if err := k.load(); err != nil {
+		return nil, err
+	}
+
+	signer, ok := k.signers[hdr.Kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", hdr.Kid)
+	}
+
+	return signer.Public(), nil
+}
+
+func (k *keys) keySet() (jwt.KeySet, error) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	if err := k.load(); err != nil {
+		return jwt.KeySet{}, err
+	}
+
+	var set jwt.KeySet
+	for kid, signer := range k.signers {
+		jwk, err := jwt.NewJWK(signer.Public(), kid)
+		if err != nil {
+			return jwt.KeySet{}, err
+		}
+
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	slices.SortFunc(set.Keys, func(a, b jwt.JWK) int {
+		return strings.Compare(a.Kid, b.Kid)
+	})
+
+	return set, nil
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package oauth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"time"
+
+	"go.wdy.de/nago/application/permission"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/pkg/data"
+)
+
+type ClientID string
+
+type GrantType string
+
+const (
+	GrantAuthorizationCode GrantType = "authorization_code"
+	GrantClientCredentials GrantType = "client_credentials"
+	GrantRefreshToken      GrantType = "refresh_token"
+)
+
+// Client is a registered third-party integration. Its scopes are the upper bound of the permissions, which
+// can ever be delegated to it, either by a consenting user or directly by the client credentials grant.
+type Client struct {
+	ID          ClientID `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	// SecretHash is the sha256 of the generated secret. Public clients like single page or mobile apps have no
+	// secret and must always use PKCE.
+	SecretHash   string          `json:"secretHash,omitempty"`
+	RedirectURIs []string        `json:"redirectURIs,omitempty"`
+	Grants       []GrantType     `json:"grants,omitempty"`
+	Scopes       []permission.ID `json:"scopes,omitempty"`
+	CreatedBy    user.ID         `json:"createdBy,omitempty"`
+	CreatedAt    time.Time       `json:"createdAt"`
+}
+
+func (c Client) Identity() ClientID {
+	return c.ID
+}
+
+func (c Client) Public() bool {
+	return c.SecretHash == ""
+}
+
+func (c Client) Allows(grant GrantType) bool {
+	return slices.Contains(c.Grants, grant)
+}
+
+// AuthorizationRequest is a validated request of the authorize endpoint, which waits for the consent of the user.
+type AuthorizationRequest struct {
+	ID            string          `json:"id"`
+	Client        ClientID        `json:"client"`
+	RedirectURI   string          `json:"redirectURI"`
+	Scopes        []permission.ID `json:"scopes"`
+	State         string          `json:"state,omitempty"`
+	CodeChallenge string          `json:"codeChallenge"`
+	CreatedAt     time.Time       `json:"createdAt"`
+}
+
+func (r AuthorizationRequest) Identity() string {
+	return r.ID
+}
+
+// AuthorizationCode is the granted consent. It is stored by its hash, is single use and short living.
+type AuthorizationCode struct {
+	ID            string          `json:"id"`
+	Client        ClientID        `json:"client"`
+	RedirectURI   string          `json:"redirectURI"`
+	User          user.ID         `json:"user"`
+	Scopes        []permission.ID `json:"scopes"`
+	CodeChallenge string          `json:"codeChallenge"`
+	CreatedAt     time.Time       `json:"createdAt"`
+}
+
+func (c AuthorizationCode) Identity() string {
+	return c.ID
+}
+
+// RefreshToken is stored by its hash and rotated on each use.
+type RefreshToken struct {
+	ID        string          `json:"id"`
+	Client    ClientID        `json:"client"`
+	User      user.ID         `json:"user"`
+	Scopes    []permission.ID `json:"scopes"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+func (t RefreshToken) Identity() string {
+	return t.ID
+}
+
+// SigningKey is the private key in PKCS #8 form, which signs the access tokens.
+type SigningKey struct {
+	ID        string    `json:"id"`
+	PKCS8     []byte    `json:"pkcs8"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (k SigningKey) Identity() string {
+	return k.ID
+}
+
+type ClientRepository data.Repository[Client, ClientID]
+type AuthorizationRequestRepository data.Repository[AuthorizationRequest, string]
+type AuthorizationCodeRepository data.Repository[AuthorizationCode, string]
+type RefreshTokenRepository data.Repository[RefreshToken, string]
+type SigningKeyRepository data.Repository[SigningKey, string]
+
+const (
+	requestLifetime      = 10 * time.Minute
+	codeLifetime         = time.Minute
+	accessTokenLifetime  = 15 * time.Minute
+	refreshTokenLifetime = 30 * 24 * time.Hour
+)
+
+// TokenRequest contains the form values of the token endpoint as defined by RFC 6749.
+type TokenRequest struct {
+	GrantType    GrantType
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+	RefreshToken string
+	Scope        string
+	ClientID     ClientID
+	ClientSecret string
+}
+
+// TokenResponse is the successful response of the token endpoint.
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+// ParseScope splits the space delimited scope parameter. Each scope is a [permission.ID].
+func ParseScope(scope string) []permission.ID {
+	var res []permission.ID
+	for _, s := range strings.Fields(scope) {
+		if !slices.Contains(res, permission.ID(s)) {
+			res = append(res, permission.ID(s))
+		}
+	}
+
+	return res
+}
+
+// FormatScope is the inverse of [ParseScope].
+func FormatScope(scopes []permission.ID) string {
+	var sb strings.Builder
+	for i, s := range scopes {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+
+		sb.WriteString(string(s))
+	}
+
+	return sb.String()
+}
+
+// hashOf is used for the generated secrets and tokens. They carry at least 128 bit of entropy, thus a fast hash
+// is sufficient and allows an O(1) lookup.
+func hashOf(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
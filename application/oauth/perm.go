@@ -0,0 +1,16 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package oauth
+
+import "go.wdy.de/nago/application/permission"
+
+var (
+	PermCreateClient   = permission.Declare[CreateClient]("nago.oauth.client.create", "OAuth Client anlegen", "Träger dieser Berechtigung können neue OAuth Clients für Drittanwendungen registrieren.")
+	PermDeleteClient   = permission.Declare[DeleteClient]("nago.oauth.client.delete", "OAuth Client entfernen", "Träger dieser Berechtigung können OAuth Clients entfernen und damit alle ausgestellten Refresh Tokens widerrufen.")
+	PermFindAllClients = permission.Declare[FindAllClients]("nago.oauth.client.find_all", "OAuth Clients finden", "Träger dieser Berechtigung können die Metadaten registrierter OAuth Clients sehen.")
+)
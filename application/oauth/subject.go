@@ -0,0 +1,194 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package oauth
+
+import (
+	"context"
+	"iter"
+	"slices"
+	"time"
+
+	"github.com/worldiety/i18n"
+	"go.wdy.de/nago/application/group"
+	"go.wdy.de/nago/application/permission"
+	"go.wdy.de/nago/application/rebac"
+	"go.wdy.de/nago/application/role"
+	"go.wdy.de/nago/application/user"
+	"golang.org/x/text/language"
+)
+
+var _ user.Subject = (*scopedSubject)(nil)
+
+// scopedSubject is a user, who has delegated a subset of their permissions to a client. A permission is only
+// granted, if it is within the scopes and the user still holds it. Roles are hidden, because they are just
+// bundles of permissions and checking them would bypass the scopes.
+type scopedSubject struct {
+	user.Subject
+	scopes    []permission.ID
+	expiresAt time.Time
+}
+
+func (s *scopedSubject) Valid() bool {
+	return s.Subject.Valid() && now().Before(s.expiresAt)
+}
+
+func (s *scopedSubject) HasPermission(p permission.ID) bool {
+	return s.Valid() && slices.Contains(s.scopes, p) && s.Subject.HasPermission(p)
+}
+
+func (s *scopedSubject) Audit(p permission.ID) error {
+	if !s.Valid() {
+		return s.deny(permission.Decision{Permission: p}, user.PermissionDeniedErr)
+	}
+
+	if !slices.Contains(s.scopes, p) {
+		return s.deny(permission.Decision{Permission: p}, user.PermissionDeniedError(permissionName(p)))
+	}
+
+	return s.Subject.Audit(p)
+}
+
+func (s *scopedSubject) HasResourcePermission(name rebac.Namespace, id rebac.Instance, p permission.ID) bool {
+	return s.Valid() && slices.Contains(s.scopes, p) && s.Subject.HasResourcePermission(name, id, p)
+}
+
+func (s *scopedSubject) AuditResource(name rebac.Namespace, id rebac.Instance, p permission.ID) error {
+	d := permission.Decision{Permission: p, Namespace: string(name), Instance: string(id)}
+	if !s.Valid() {
+		return s.deny(d, user.PermissionDeniedErr)
+	}
+
+	if !slices.Contains(s.scopes, p) {
+		return s.deny(d, user.PermissionDeniedError(permissionName(p)))
+	}
+
+	return s.Subject.AuditResource(name, id, p)
+}
+
+// deny reports a decision, which has been rejected by the token before the user itself has been audited.
+func (s *scopedSubject) deny(d permission.Decision, err error) error {
+	d.Subject = string(s.Subject.ID())
+	return permission.Report(d, err)
+}
+
+func (s *scopedSubject) Roles() iter.Seq[role.ID] {
+	return func(yield func(role.ID) bool) {}
+}
+
+func (s *scopedSubject) HasRole(id role.ID) bool {
+	return false
+}
+
+var _ user.Subject = (*clientSubject)(nil)
+
+// clientSubject is a client acting on its own behalf by the client credentials grant. It holds exactly the
+// scopes of the access token and is neither member of a role nor of a group.
+type clientSubject struct {
+	ctx       context.Context
+	client    Client
+	scopes    []permission.ID
+	expiresAt time.Time
+}
+
+func (s *clientSubject) Audit(p permission.ID) error {
+	return permission.Report(permission.Decision{Subject: string(s.ID()), Permission: p}, s.audit(p))
+}
+
+func (s *clientSubject) audit(p permission.ID) error {
+	if !s.Valid() {
+		return user.PermissionDeniedErr
+	}
+
+	if !s.HasPermission(p) {
+		return user.PermissionDeniedError(permissionName(p))
+	}
+
+	return nil
+}
+
+func (s *clientSubject) HasPermission(p permission.ID) bool {
+	return s.Valid() && slices.Contains(s.scopes, p)
+}
+
+func (s *clientSubject) AuditResource(name rebac.Namespace, id rebac.Instance, p permission.ID) error {
+	return permission.Report(permission.Decision{
+		Subject:    string(s.ID()),
+		Permission: p,
+		Namespace:  string(name),
+		Instance:   string(id),
+	}, s.audit(p))
+}
+
+func (s *clientSubject) HasResourcePermission(name rebac.Namespace, id rebac.Instance, p permission.ID) bool {
+	return s.HasPermission(p)
+}
+
+func (s *clientSubject) ID() user.ID {
+	return user.ID(s.client.ID)
+}
+
+func (s *clientSubject) Name() string {
+	return s.client.Name
+}
+
+func (s *clientSubject) Firstname() string {
+	return s.client.Name
+}
+
+func (s *clientSubject) Lastname() string {
+	return ""
+}
+
+func (s *clientSubject) Email() string {
+	return ""
+}
+
+func (s *clientSubject) Avatar() string {
+	return ""
+}
+
+func (s *clientSubject) Roles() iter.Seq[role.ID] {
+	return func(yield func(role.ID) bool) {}
+}
+
+func (s *clientSubject) HasRole(id role.ID) bool {
+	return false
+}
+
+func (s *clientSubject) Groups() iter.Seq[group.ID] {
+	return func(yield func(group.ID) bool) {}
+}
+
+func (s *clientSubject) HasGroup(id group.ID) bool {
+	return false
+}
+
+func (s *clientSubject) Valid() bool {
+	return now().Before(s.expiresAt)
+}
+
+func (s *clientSubject) Language() language.Tag {
+	return language.English
+}
+
+func (s *clientSubject) Bundle() *i18n.Bundle {
+	bnd, _ := i18n.Default.MatchBundle(s.Language())
+	return bnd
+}
+
+func (s *clientSubject) Context() context.Context {
+	return s.ctx
+}
+
+func permissionName(p permission.ID) string {
+	if perm, ok := permission.Find(p); ok {
+		return perm.Name
+	}
+
+	return string(p)
+}
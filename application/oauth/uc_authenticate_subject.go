@@ -0,0 +1,82 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.wdy.de/nago/application/token"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/auth"
+	"go.wdy.de/nago/pkg/jwt"
+)
+
+func NewAuthenticateSubject(
+	ctx context.Context,
+	issuer string,
+	clients ClientRepository,
+	keys *keys,
+	subjectFromUser user.SubjectFromUser,
+	getAnonUser user.GetAnonUser,
+	fallback token.AuthenticateSubject,
+) token.AuthenticateSubject {
+	return func(remoteAddr string, plaintext token.Plaintext) (auth.Subject, error) {
+		if strings.Count(string(plaintext), ".") != 2 {
+			return fallback(remoteAddr, plaintext)
+		}
+
+		// a static token may be chosen freely and thus look like a JWT, which is resolved by the fallback.
+		// This is safe, because a forged JWT is just an unknown static token.
+		tok, err := jwt.Verify(string(plaintext), keys.publicKey)
+		if err != nil {
+			return fallback(remoteAddr, plaintext)
+		}
+
+		claims := tok.Claims
+		exp, ok := claims.Time("exp")
+		if tok.Header.Typ != accessTokenType || claims.String("iss") != issuer || !slices.Contains(claims.Strings("aud"), issuer) || !ok || !now().Before(exp) {
+			return getAnonUser(), nil
+		}
+
+		// deleting a client revokes all its access tokens immediately
+		optClient, err := clients.FindByID(ClientID(claims.String("client_id")))
+		if err != nil {
+			return nil, fmt.Errorf("cannot find oauth client: %w", err)
+		}
+
+		if optClient.IsNone() {
+			return getAnonUser(), nil
+		}
+
+		client := optClient.Unwrap()
+		scopes := ParseScope(claims.String("scope"))
+		sub := claims.String("sub")
+
+		if sub == string(client.ID) {
+			if !client.Allows(GrantClientCredentials) {
+				return getAnonUser(), nil
+			}
+
+			return &clientSubject{ctx: ctx, client: client, scopes: scopes, expiresAt: exp}, nil
+		}
+
+		optSubject, err := subjectFromUser(user.SU(), user.ID(sub))
+		if err != nil {
+			return nil, fmt.Errorf("cannot get subject from user: %w", err)
+		}
+
+		if optSubject.IsNone() {
+			return getAnonUser(), nil
+		}
+
+		return &scopedSubject{Subject: optSubject.Unwrap(), scopes: scopes, expiresAt: exp}, nil
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package oauth
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/url"
+	"sync"
+
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/auth"
+)
+
+func NewAuthorize(mutex *sync.Mutex, issuer string, requests AuthorizationRequestRepository, codes AuthorizationCodeRepository) Authorize {
+	return func(subject auth.Subject, id string, approve bool) (string, error) {
+		if !subject.Valid() {
+			return "", user.InvalidSubjectErr
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		optReq, err := requests.FindByID(id)
+		if err != nil {
+			return "", fmt.Errorf("cannot find authorization request: %w", err)
+		}
+
+		if optReq.IsNone() {
+			return "", UnknownAuthorizationErr
+		}
+
+		// the request is single use, whether the user agrees or not
+		if err := requests.DeleteByID(id); err != nil {
+			return "", fmt.Errorf("cannot delete authorization request: %w", err)
+		}
+
+		req := optReq.Unwrap()
+		if now().Sub(req.CreatedAt) > requestLifetime {
+			return "", UnknownAuthorizationErr
+		}
+
+		query := url.Values{}
+		if !approve {
+			query.Set("error", string(AccessDenied))
+			return redirectURI(req, issuer, query)
+		}
+
+		removeExpired(codes, func(c AuthorizationCode) bool { return now().Sub(c.CreatedAt) > codeLifetime })
+
+		// security note: the scopes are not narrowed to the current permissions of the user, because they are
+		// intersected on each request anyway, which also covers resource based permissions
+		code := rand.Text()
+		err = codes.Save(AuthorizationCode{
+			ID:            hashOf(code),
+			Client:        req.Client,
+			RedirectURI:   req.RedirectURI,
+			User:          subject.ID(),
+			Scopes:        req.Scopes,
+			CodeChallenge: req.CodeChallenge,
+			CreatedAt:     now(),
+		})
+		if err != nil {
+			return "", fmt.Errorf("cannot save authorization code: %w", err)
+		}
+
+		query.Set("code", code)
+		return redirectURI(req, issuer, query)
+	}
+}
+
+// ErrorRedirectURI returns the uri to report the given error of [StartAuthorization] back to the client.
+func ErrorRedirectURI(req AuthorizationRequest, issuer string, err Error) (string, error) {
+	query := url.Values{}
+	query.Set("error", string(err.Code))
+	if err.Description != "" {
+		query.Set("error_description", err.Description)
+	}
+
+	return redirectURI(req, issuer, query)
+}
+
+// redirectURI adds the state and the issuer of RFC 9207 to mitigate mix-up attacks of clients, which talk to
+// multiple authorization servers.
+func redirectURI(req AuthorizationRequest, issuer string, query url.Values) (string, error) {
+	u, err := url.Parse(req.RedirectURI)
+	if err != nil {
+		return "", fmt.Errorf("invalid redirect uri: %w", err)
+	}
+
+	q := u.Query()
+	for k, v := range query {
+		q[k] = v
+	}
+
+	if req.State != "" {
+		q.Set("state", req.State)
+	}
+
+	q.Set("iss", issuer)
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
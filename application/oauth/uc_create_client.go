@@ -0,0 +1,133 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package oauth
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"go.wdy.de/nago/application/permission"
+	"go.wdy.de/nago/auth"
+	"go.wdy.de/nago/pkg/data"
+)
+
+func NewCreateClient(mutex *sync.Mutex, repo ClientRepository) CreateClient {
+	return func(subject auth.Subject, cdata ClientCreationData) (ClientID, string, error) {
+		if err := subject.Audit(PermCreateClient); err != nil {
+			return "", "", err
+		}
+
+		if strings.TrimSpace(cdata.Name) == "" {
+			return "", "", fmt.Errorf("name must not be empty")
+		}
+
+		client := Client{
+			ID:          data.RandIdent[ClientID](),
+			Name:        cdata.Name,
+			Description: cdata.Description,
+			CreatedBy:   subject.ID(),
+			CreatedAt:   now(),
+		}
+
+		if cdata.AuthorizationCode {
+			client.Grants = append(client.Grants, GrantAuthorizationCode)
+			if cdata.RefreshToken {
+				client.Grants = append(client.Grants, GrantRefreshToken)
+			}
+		}
+
+		if cdata.ClientCredentials {
+			if cdata.Public {
+				return "", "", fmt.Errorf("a public client cannot use the client credentials grant")
+			}
+
+			client.Grants = append(client.Grants, GrantClientCredentials)
+		}
+
+		if len(client.Grants) == 0 {
+			return "", "", fmt.Errorf("at least one grant type must be enabled")
+		}
+
+		for _, line := range cdata.RedirectURIs {
+			uri := strings.TrimSpace(line)
+			if uri == "" {
+				continue
+			}
+
+			if err := validateRedirectURI(uri); err != nil {
+				return "", "", err
+			}
+
+			client.RedirectURIs = append(client.RedirectURIs, uri)
+		}
+
+		if client.Allows(GrantAuthorizationCode) && len(client.RedirectURIs) == 0 {
+			return "", "", fmt.Errorf("the authorization code grant requires at least one redirect uri")
+		}
+
+		// security note: a subject must not be able to escalate its privileges by registering a client, which may
+		// act on its own behalf with permissions the subject does not hold
+		for _, scope := range cdata.Scopes {
+			if slices.Contains(client.Scopes, scope) {
+				continue
+			}
+
+			if _, ok := permission.Find(scope); !ok {
+				return "", "", fmt.Errorf("unknown permission: %s", scope)
+			}
+
+			if err := subject.Audit(scope); err != nil {
+				return "", "", err
+			}
+
+			client.Scopes = append(client.Scopes, scope)
+		}
+
+		var secret string
+		if !cdata.Public {
+			secret = rand.Text()
+			client.SecretHash = hashOf(secret)
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if err := repo.Save(client); err != nil {
+			return "", "", fmt.Errorf("cannot save oauth client: %w", err)
+		}
+
+		return client.ID, secret, nil
+	}
+}
+
+// validateRedirectURI follows RFC 8252 and the OAuth 2.0 security best current practice: uris must be absolute
+// and without fragment. Plain http is only allowed for the loopback interface of native apps, but private-use
+// schemes like com.example.app are allowed.
+func validateRedirectURI(uri string) error {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return fmt.Errorf("invalid redirect uri %q: %w", uri, err)
+	}
+
+	if u.Scheme == "" || u.Fragment != "" || u.User != nil {
+		return fmt.Errorf("redirect uri must be absolute and must not contain a fragment or user info: %s", uri)
+	}
+
+	if u.Scheme == "http" {
+		if ip := net.ParseIP(u.Hostname()); u.Hostname() != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			return fmt.Errorf("plain http is only allowed for loopback redirect uris: %s", uri)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package oauth
+
+import (
+	"fmt"
+	"sync"
+
+	"go.wdy.de/nago/auth"
+)
+
+func NewDeleteClient(mutex *sync.Mutex, repo ClientRepository, codes AuthorizationCodeRepository, refreshTokens RefreshTokenRepository) DeleteClient {
+	return func(subject auth.Subject, id ClientID) error {
+		if err := subject.Audit(PermDeleteClient); err != nil {
+			return err
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if err := repo.DeleteByID(id); err != nil {
+			return fmt.Errorf("cannot delete oauth client: %w", err)
+		}
+
+		if err := codes.Delete(func(c AuthorizationCode) (bool, error) { return c.Client == id, nil }); err != nil {
+			return fmt.Errorf("cannot delete authorization codes: %w", err)
+		}
+
+		if err := refreshTokens.Delete(func(t RefreshToken) (bool, error) { return t.Client == id, nil }); err != nil {
+			return fmt.Errorf("cannot revoke refresh tokens: %w", err)
+		}
+
+		return nil
+	}
+}
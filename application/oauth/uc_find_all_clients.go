@@ -0,0 +1,31 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package oauth
+
+import (
+	"iter"
+
+	"go.wdy.de/nago/auth"
+)
+
+func NewFindAllClients(repo ClientRepository) FindAllClients {
+	return func(subject auth.Subject) iter.Seq2[Client, error] {
+		return func(yield func(Client, error) bool) {
+			if err := subject.Audit(PermFindAllClients); err != nil {
+				yield(Client{}, err)
+				return
+			}
+
+			for client, err := range repo.All() {
+				if !yield(client, err) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package oauth
+
+import (
+	"fmt"
+
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/auth"
+)
+
+func NewFindConsent(clients ClientRepository, requests AuthorizationRequestRepository) FindConsent {
+	return func(subject auth.Subject, id string) (Consent, error) {
+		if !subject.Valid() {
+			return Consent{}, user.InvalidSubjectErr
+		}
+
+		optReq, err := requests.FindByID(id)
+		if err != nil {
+			return Consent{}, fmt.Errorf("cannot find authorization request: %w", err)
+		}
+
+		if optReq.IsNone() || now().Sub(optReq.Unwrap().CreatedAt) > requestLifetime {
+			return Consent{}, UnknownAuthorizationErr
+		}
+
+		req := optReq.Unwrap()
+		optClient, err := clients.FindByID(req.Client)
+		if err != nil {
+			return Consent{}, fmt.Errorf("cannot find oauth client: %w", err)
+		}
+
+		if optClient.IsNone() {
+			return Consent{}, UnknownClientErr
+		}
+
+		client := optClient.Unwrap()
+		client.SecretHash = ""
+
+		return Consent{Request: req, Client: client}, nil
+	}
+}
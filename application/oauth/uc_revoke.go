@@ -0,0 +1,41 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package oauth
+
+import (
+	"fmt"
+	"sync"
+)
+
+func NewRevoke(mutex *sync.Mutex, clients ClientRepository, refreshTokens RefreshTokenRepository) Revoke {
+	return func(id ClientID, secret string, token string) error {
+		client, err := authenticateClient(clients, id, secret)
+		if err != nil {
+			return err
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		optToken, err := refreshTokens.FindByID(hashOf(token))
+		if err != nil {
+			return fmt.Errorf("cannot find refresh token: %w", err)
+		}
+
+		// a client must not be able to revoke the tokens of other clients
+		if optToken.IsNone() || optToken.Unwrap().Client != client.ID {
+			return nil
+		}
+
+		if err := refreshTokens.DeleteByID(optToken.Unwrap().ID); err != nil {
+			return fmt.Errorf("cannot delete refresh token: %w", err)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package oauth
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+
+	"go.wdy.de/nago/application/permission"
+	"go.wdy.de/nago/pkg/data"
+)
+
+func NewStartAuthorization(mutex *sync.Mutex, clients ClientRepository, requests AuthorizationRequestRepository) StartAuthorization {
+	return func(params AuthorizationParams) (AuthorizationRequest, error) {
+		optClient, err := clients.FindByID(params.ClientID)
+		if err != nil {
+			return AuthorizationRequest{}, fmt.Errorf("cannot find oauth client: %w", err)
+		}
+
+		if optClient.IsNone() {
+			return AuthorizationRequest{}, UnknownClientErr
+		}
+
+		client := optClient.Unwrap()
+
+		// security note: only exact matches are allowed, otherwise we may become an open redirector
+		redirect := params.RedirectURI
+		if redirect == "" && len(client.RedirectURIs) == 1 {
+			redirect = client.RedirectURIs[0]
+		}
+
+		if !slices.Contains(client.RedirectURIs, redirect) {
+			return AuthorizationRequest{}, InvalidRedirectURIErr
+		}
+
+		// from here on, errors are reported back to the client
+		req := AuthorizationRequest{
+			Client:      client.ID,
+			RedirectURI: redirect,
+			State:       params.State,
+		}
+
+		if params.ResponseType != "code" {
+			return req, newError(UnsupportedResponse, "only the code response type is supported")
+		}
+
+		if !client.Allows(GrantAuthorizationCode) {
+			return req, newError(UnauthorizedClient, "the client is not allowed to use the authorization code grant")
+		}
+
+		// PKCE is mandatory for all clients, as recommended by the OAuth 2.0 security best current practice
+		if params.CodeChallengeMethod != "S256" || len(params.CodeChallenge) < 43 {
+			return req, newError(InvalidRequest, "a S256 code challenge is required")
+		}
+
+		scopes, err := restrictScopes(client, params.Scope)
+		if err != nil {
+			return req, err
+		}
+
+		req.ID = data.RandIdent[string]()
+		req.Scopes = scopes
+		req.CodeChallenge = params.CodeChallenge
+		req.CreatedAt = now()
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		removeExpired(requests, func(r AuthorizationRequest) bool { return now().Sub(r.CreatedAt) > requestLifetime })
+
+		if err := requests.Save(req); err != nil {
+			return AuthorizationRequest{}, fmt.Errorf("cannot save authorization request: %w", err)
+		}
+
+		return req, nil
+	}
+}
+
+// restrictScopes returns the requested scopes or all scopes of the client, if nothing was requested.
+func restrictScopes(client Client, scope string) ([]permission.ID, error) {
+	requested := ParseScope(scope)
+	if len(requested) == 0 {
+		return slices.Clone(client.Scopes), nil
+	}
+
+	for _, s := range requested {
+		if !slices.Contains(client.Scopes, s) {
+			return nil, newError(InvalidScope, fmt.Sprintf("the scope %s is not allowed for the client", s))
+		}
+	}
+
+	return requested, nil
+}
+
+// removeExpired deletes the abandoned entries of the given repository.
+func removeExpired[E data.Aggregate[string]](repo data.Repository[E, string], expired func(E) bool) {
+	if err := repo.Delete(func(e E) (bool, error) { return expired(e), nil }); err != nil {
+		slog.Error("cannot remove expired oauth entries", "repo", repo.Name(), "err", err.Error())
+	}
+}
@@ -0,0 +1,226 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package oauth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"go.wdy.de/nago/application/permission"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/pkg/data"
+	"go.wdy.de/nago/pkg/oidc"
+)
+
+// accessTokenType is the typ header of RFC 9068, which prevents that other kinds of JWTs are accepted.
+const accessTokenType = "at+jwt"
+
+type accessTokenClaims struct {
+	Issuer   string   `json:"iss"`
+	Subject  string   `json:"sub"`
+	Audience string   `json:"aud"`
+	ClientID ClientID `json:"client_id"`
+	Scope    string   `json:"scope"`
+	IssuedAt int64    `json:"iat"`
+	Expiry   int64    `json:"exp"`
+	JWTID    string   `json:"jti"`
+}
+
+func NewToken(
+	mutex *sync.Mutex,
+	issuer string,
+	clients ClientRepository,
+	codes AuthorizationCodeRepository,
+	refreshTokens RefreshTokenRepository,
+	keys *keys,
+	subjectFromUser user.SubjectFromUser,
+) Token {
+	issue := func(client Client, subject string, scopes []permission.ID) (TokenResponse, error) {
+		ts := now()
+		at, err := keys.sign(accessTokenClaims{
+			Issuer:   issuer,
+			Subject:  subject,
+			Audience: issuer,
+			ClientID: client.ID,
+			Scope:    FormatScope(scopes),
+			IssuedAt: ts.Unix(),
+			Expiry:   ts.Add(accessTokenLifetime).Unix(),
+			JWTID:    data.RandIdent[string](),
+		})
+		if err != nil {
+			return TokenResponse{}, fmt.Errorf("cannot sign access token: %w", err)
+		}
+
+		return TokenResponse{
+			AccessToken: at,
+			TokenType:   "Bearer",
+			ExpiresIn:   int(accessTokenLifetime / time.Second),
+			Scope:       FormatScope(scopes),
+		}, nil
+	}
+
+	// issueForUser must be called with the lock held. The rotated refresh token keeps the originally granted
+	// scopes, even if the access token has been narrowed.
+	issueForUser := func(client Client, uid user.ID, scopes, granted []permission.ID, refreshCreatedAt time.Time) (TokenResponse, error) {
+		// a disabled or deleted user must not get any new tokens
+		optSubject, err := subjectFromUser(user.SU(), uid)
+		if err != nil {
+			return TokenResponse{}, fmt.Errorf("cannot find user: %w", err)
+		}
+
+		if optSubject.IsNone() || !optSubject.Unwrap().Valid() {
+			return TokenResponse{}, newError(InvalidGrant, "the user is not valid anymore")
+		}
+
+		res, err := issue(client, string(uid), scopes)
+		if err != nil {
+			return TokenResponse{}, err
+		}
+
+		if client.Allows(GrantRefreshToken) {
+			rt := rand.Text()
+			err := refreshTokens.Save(RefreshToken{
+				ID:        hashOf(rt),
+				Client:    client.ID,
+				User:      uid,
+				Scopes:    granted,
+				CreatedAt: refreshCreatedAt,
+			})
+			if err != nil {
+				return TokenResponse{}, fmt.Errorf("cannot save refresh token: %w", err)
+			}
+
+			res.RefreshToken = rt
+		}
+
+		return res, nil
+	}
+
+	return func(req TokenRequest) (TokenResponse, error) {
+		client, err := authenticateClient(clients, req.ClientID, req.ClientSecret)
+		if err != nil {
+			return TokenResponse{}, err
+		}
+
+		if !client.Allows(req.GrantType) {
+			switch req.GrantType {
+			case GrantAuthorizationCode, GrantClientCredentials, GrantRefreshToken:
+				return TokenResponse{}, newError(UnauthorizedClient, "the grant type is not allowed for the client")
+			default:
+				return TokenResponse{}, newError(UnsupportedGrantType, "")
+			}
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		switch req.GrantType {
+		case GrantAuthorizationCode:
+			optCode, err := codes.FindByID(hashOf(req.Code))
+			if err != nil {
+				return TokenResponse{}, fmt.Errorf("cannot find authorization code: %w", err)
+			}
+
+			if optCode.IsNone() {
+				return TokenResponse{}, newError(InvalidGrant, "unknown authorization code")
+			}
+
+			code := optCode.Unwrap()
+			if err := codes.DeleteByID(code.ID); err != nil {
+				return TokenResponse{}, fmt.Errorf("cannot delete authorization code: %w", err)
+			}
+
+			if code.Client != client.ID || code.RedirectURI != req.RedirectURI || now().Sub(code.CreatedAt) > codeLifetime {
+				return TokenResponse{}, newError(InvalidGrant, "the authorization code is expired or has been issued to another client")
+			}
+
+			if subtle.ConstantTimeCompare([]byte(oidc.CodeChallenge(req.CodeVerifier)), []byte(code.CodeChallenge)) != 1 {
+				return TokenResponse{}, newError(InvalidGrant, "invalid code verifier")
+			}
+
+			removeExpired(refreshTokens, func(t RefreshToken) bool { return now().Sub(t.CreatedAt) > refreshTokenLifetime })
+
+			return issueForUser(client, code.User, code.Scopes, code.Scopes, now())
+
+		case GrantRefreshToken:
+			optToken, err := refreshTokens.FindByID(hashOf(req.RefreshToken))
+			if err != nil {
+				return TokenResponse{}, fmt.Errorf("cannot find refresh token: %w", err)
+			}
+
+			if optToken.IsNone() {
+				return TokenResponse{}, newError(InvalidGrant, "unknown refresh token")
+			}
+
+			// refresh tokens are rotated, thus a replayed token has been stolen from either party
+			rt := optToken.Unwrap()
+			if err := refreshTokens.DeleteByID(rt.ID); err != nil {
+				return TokenResponse{}, fmt.Errorf("cannot delete refresh token: %w", err)
+			}
+
+			if rt.Client != client.ID || now().Sub(rt.CreatedAt) > refreshTokenLifetime {
+				return TokenResponse{}, newError(InvalidGrant, "the refresh token is expired or has been issued to another client")
+			}
+
+			scopes := rt.Scopes
+			if requested := ParseScope(req.Scope); len(requested) > 0 {
+				for _, s := range requested {
+					if !slices.Contains(rt.Scopes, s) {
+						return TokenResponse{}, newError(InvalidScope, fmt.Sprintf("the scope %s has not been granted", s))
+					}
+				}
+
+				scopes = requested
+			}
+
+			// the lifetime is absolute and not extended by a rotation
+			return issueForUser(client, rt.User, scopes, rt.Scopes, rt.CreatedAt)
+
+		case GrantClientCredentials:
+			scopes, err := restrictScopes(client, req.Scope)
+			if err != nil {
+				return TokenResponse{}, err
+			}
+
+			return issue(client, string(client.ID), scopes)
+		}
+
+		return TokenResponse{}, newError(UnsupportedGrantType, "")
+	}
+}
+
+// authenticateClient checks the credentials of confidential clients. Public clients are only identified.
+func authenticateClient(clients ClientRepository, id ClientID, secret string) (Client, error) {
+	if id == "" {
+		return Client{}, newError(InvalidClient, "")
+	}
+
+	optClient, err := clients.FindByID(id)
+	if err != nil {
+		return Client{}, fmt.Errorf("cannot find oauth client: %w", err)
+	}
+
+	if optClient.IsNone() {
+		return Client{}, newError(InvalidClient, "")
+	}
+
+	client := optClient.Unwrap()
+	if client.Public() {
+		return client, nil
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashOf(secret)), []byte(client.SecretHash)) != 1 {
+		return Client{}, newError(InvalidClient, "")
+	}
+
+	return client, nil
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package uioauth
+
+import (
+	"strconv"
+	"strings"
+
+	"go.wdy.de/nago/application/oauth"
+	oauthhttp "go.wdy.de/nago/application/oauth/http"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/pkg/xtime"
+	"go.wdy.de/nago/presentation/core"
+	flowbiteOutline "go.wdy.de/nago/presentation/icons/flowbite/outline"
+	"go.wdy.de/nago/presentation/ui"
+	"go.wdy.de/nago/presentation/ui/alert"
+	"go.wdy.de/nago/presentation/ui/form"
+)
+
+// credentials are shown once after a client has been created.
+type credentials struct {
+	ID     oauth.ClientID
+	Secret string
+}
+
+func PageClients(wnd core.Window, uc oauth.UseCases) core.View {
+	if !wnd.Subject().Valid() {
+		return alert.BannerError(user.InvalidSubjectErr)
+	}
+
+	selected := core.AutoState[oauth.Client](wnd)
+	deletePresented := core.AutoState[bool](wnd)
+
+	var rows []ui.TTableRow
+	for client, err := range uc.FindAllClients(wnd.Subject()) {
+		if err != nil {
+			return alert.BannerError(err)
+		}
+
+		rows = append(rows, ui.TableRow(
+			ui.TableCell(ui.Text(client.Name)),
+			ui.TableCell(ui.Text(string(client.ID))),
+			ui.TableCell(ui.Text(formatGrants(client))),
+			ui.TableCell(ui.Text(strconv.Itoa(len(client.Scopes)))),
+			ui.TableCell(ui.Text(client.CreatedAt.Format(xtime.GermanDate))),
+			ui.TableCell(ui.HStack(
+				ui.SecondaryButton(func() {
+					selected.Set(client)
+					deletePresented.Set(true)
+				}).PreIcon(flowbiteOutline.TrashBin).AccessibilityLabel(client.Name+" löschen"),
+			).Gap(ui.L8)).Alignment(ui.Trailing),
+		))
+	}
+
+	createPresented := core.AutoState[bool](wnd)
+	credentialsPresented := core.AutoState[bool](wnd)
+	created := core.AutoState[credentials](wnd).Observe(func(newValue credentials) {
+		credentialsPresented.Set(newValue.ID != "")
+	})
+
+	return ui.VStack(
+		ui.H1("OAuth Clients"),
+		ui.TextLayout(
+			ui.Text("Drittanwendungen erhalten über OAuth 2.0 kurzlebige Access Tokens für die REST-APIs dieser Anwendung. Die Scopes entsprechen Berechtigungen und begrenzen, was ein Client maximal anfragen darf. Die Metadaten des Autorisierungsservers stehen unter "+oauthhttp.MetadataEndpoint+" bereit."),
+		),
+		createDialog(wnd, createPresented, created, uc),
+		deleteDialog(wnd, deletePresented, selected.Get(), uc),
+		credentialsDialog(wnd, credentialsPresented, created.Get()),
+		ui.HStack(
+			ui.PrimaryButton(func() {
+				createPresented.Set(true)
+			}).Title("Client hinzufügen"),
+		).FullWidth().Alignment(ui.Trailing).Gap(ui.L8),
+		ui.Table(
+			ui.TableColumn(ui.Text("Name")),
+			ui.TableColumn(ui.Text("Client ID")),
+			ui.TableColumn(ui.Text("Grants")),
+			ui.TableColumn(ui.Text("Scopes")),
+			ui.TableColumn(ui.Text("Erstellt am")),
+			ui.TableColumn(ui.Text("Optionen")),
+		).Rows(rows...),
+		ui.If(len(rows) == 0, ui.Text("Noch keine Clients vorhanden")),
+	).Alignment(ui.Leading).FullWidth().Gap(ui.L16)
+}
+
+func createDialog(wnd core.Window, presented *core.State[bool], created *core.State[credentials], uc oauth.UseCases) core.View {
+	if !presented.Get() {
+		return nil
+	}
+
+	state := core.AutoState[oauth.ClientCreationData](wnd).Init(func() oauth.ClientCreationData {
+		return oauth.ClientCreationData{AuthorizationCode: true, RefreshToken: true}
+	})
+
+	return alert.Dialog(
+		"Neuen OAuth Client registrieren",
+		form.Auto(form.AutoOptions{Window: wnd}, state),
+		presented,
+		alert.Width(ui.L560),
+		alert.Cancel(nil),
+		alert.Save(func() (close bool) {
+			id, secret, err := uc.CreateClient(wnd.Subject(), state.Get())
+			if err != nil {
+				alert.ShowBannerError(wnd, err)
+				return false
+			}
+
+			state.Set(oauth.ClientCreationData{})
+			created.Set(credentials{ID: id, Secret: secret})
+			created.Notify()
+
+			return true
+		}),
+	)
+}
+
+func deleteDialog(wnd core.Window, presented *core.State[bool], client oauth.Client, uc oauth.UseCases) core.View {
+	if !presented.Get() {
+		return nil
+	}
+
+	return alert.Dialog(
+		client.Name+" löschen",
+		ui.Text("Soll der Client '"+client.Name+"' gelöscht werden? Alle ausgestellten Tokens werden sofort ungültig."),
+		presented,
+		alert.Cancel(nil),
+		alert.Delete(func() {
+			if err := uc.DeleteClient(wnd.Subject(), client.ID); err != nil {
+				alert.ShowBannerError(wnd, err)
+			}
+		}),
+	)
+}
+
+func credentialsDialog(wnd core.Window, presented *core.State[bool], c credentials) core.View {
+	if !presented.Get() {
+		return nil
+	}
+
+	copyable := func(label, value string) core.View {
+		return ui.HStack(
+			ui.Text(label+": "+value),
+			ui.TertiaryButton(func() {
+				if err := wnd.Clipboard().SetText(value); err != nil {
+					alert.ShowBannerError(wnd, err)
+				}
+			}).PreIcon(flowbiteOutline.Clipboard).AccessibilityLabel(label+" in die Zwischenablage kopieren"),
+		)
+	}
+
+	text := "Der Client ist öffentlich und besitzt kein Secret. Er muss PKCE verwenden."
+	if c.Secret != "" {
+		text = "Kopieren Sie das folgende Client Secret und verwahren Sie es sicher auf. Das Secret wird nicht gespeichert und kann nicht wieder eingesehen werden."
+	}
+
+	return alert.Dialog(
+		"OAuth Client",
+		ui.VStack(
+			ui.Text(text),
+			copyable("Client ID", string(c.ID)),
+			ui.If(c.Secret != "", copyable("Client Secret", c.Secret)),
+		).Alignment(ui.Leading).Gap(ui.L8),
+		presented,
+		alert.Closeable(),
+		alert.Ok(),
+		alert.Large(),
+	)
+}
+
+func formatGrants(client oauth.Client) string {
+	tmp := make([]string, 0, len(client.Grants))
+	for _, g := range client.Grants {
+		tmp = append(tmp, string(g))
+	}
+
+	return strings.Join(tmp, ", ")
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package uioauth
+
+import (
+	"net/url"
+
+	"go.wdy.de/nago/application/oauth"
+	"go.wdy.de/nago/application/permission"
+	uisession "go.wdy.de/nago/application/session/ui"
+	"go.wdy.de/nago/presentation/core"
+	flowbiteOutline "go.wdy.de/nago/presentation/icons/flowbite/outline"
+	"go.wdy.de/nago/presentation/ui"
+	"go.wdy.de/nago/presentation/ui/alert"
+	"go.wdy.de/nago/presentation/ui/cardlayout"
+	"go.wdy.de/nago/presentation/ui/list"
+)
+
+// PageConsent asks the user to delegate the requested scopes to the client. Anonymous users are asked to log in
+// first and return afterward.
+func PageConsent(wnd core.Window, pages Pages, findConsent oauth.FindConsent, authorize oauth.Authorize) core.View {
+	id := wnd.Values()["request"]
+
+	if !wnd.Subject().Valid() {
+		sessionPages, _ := core.FromContext[uisession.Pages](wnd.Context(), "")
+		return consentCard(
+			"Anmeldung erforderlich",
+			ui.VStack(
+				ui.Text("Eine Anwendung möchte auf Ihr Konto zugreifen. Melden Sie sich an, um die Anfrage zu prüfen."),
+				ui.PrimaryButton(func() {
+					wnd.Navigation().ForwardTo(sessionPages.Login, core.Values{
+						"redirect": string(pages.Consent) + "?" + url.Values{"request": {id}}.Encode(),
+					})
+				}).Title("Anmelden").Frame(ui.Frame{}.FullWidth()),
+			).Gap(ui.L16).FullWidth(),
+		)
+	}
+
+	consent, err := findConsent(wnd.Subject(), id)
+	if err != nil {
+		return ui.VStack(alert.BannerError(err)).Frame(ui.Frame{}.MatchScreen())
+	}
+
+	decide := func(approve bool) {
+		redirect, err := authorize(wnd.Subject(), id, approve)
+		if err != nil {
+			alert.ShowBannerError(wnd, err)
+			return
+		}
+
+		core.HTTPOpen(wnd.Navigation(), core.URI(redirect), "_self")
+	}
+
+	return consentCard(
+		consent.Client.Name+" möchte auf Ihr Konto zugreifen",
+		ui.VStack(
+			alert.BannerMessages(wnd),
+			ui.If(consent.Client.Description != "", ui.Text(consent.Client.Description)),
+			ui.Text("Die Anwendung erhält die folgenden Berechtigungen, soweit Sie selbst darüber verfügen. Sie handelt dabei in Ihrem Namen als "+wnd.Subject().Email()+"."),
+			list.List(ui.ForEach(consent.Request.Scopes, func(id permission.ID) core.View {
+				entry := list.Entry().Headline(string(id)).Leading(ui.ImageIcon(flowbiteOutline.Shield))
+				if perm, ok := permission.Find(id); ok {
+					entry = entry.Headline(perm.Name).SupportingText(perm.Description)
+				}
+
+				return entry
+			})...).Caption(ui.Text("Berechtigungen")).Frame(ui.Frame{}.FullWidth()),
+			ui.If(len(consent.Request.Scopes) == 0, ui.Text("Es werden keine Berechtigungen angefragt.")),
+			ui.Text("Nach der Zustimmung werden Sie zu "+redirectHost(consent.Request.RedirectURI)+" weitergeleitet.").Font(ui.Small),
+			ui.HStack(
+				ui.SecondaryButton(func() {
+					decide(false)
+				}).Title("Ablehnen"),
+				ui.PrimaryButton(func() {
+					decide(true)
+				}).Title("Zulassen"),
+			).Gap(ui.L8).FullWidth().Alignment(ui.Trailing),
+		).Gap(ui.L16).FullWidth(),
+	)
+}
+
+func consentCard(title string, body core.View) core.View {
+	return ui.VStack(
+		ui.VStack(
+			ui.WindowTitle(title),
+			cardlayout.Card(title).
+				Padding(ui.Padding{}.All(ui.L12)).
+				Body(body),
+		).Frame(ui.Frame{Width: ui.L480}),
+	).Frame(ui.Frame{}.MatchScreen())
+}
+
+// redirectHost shows the user, where the code is sent to, which helps to detect phishing clients.
+func redirectHost(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Host == "" {
+		return uri
+	}
+
+	return u.Host
+}
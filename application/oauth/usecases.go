@@ -0,0 +1,133 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+// Package oauth implements an OAuth 2.0 authorization server (RFC 6749) for the hapi endpoints of the Nago
+// instance itself. It supports the authorization code grant with mandatory PKCE, the client credentials grant
+// and rotating refresh tokens. Access tokens are short living JWTs (RFC 9068) and the scopes are
+// [permission.ID]s, which restrict the permissions of the subject to the intersection of the granted scopes
+// and the permissions the user actually holds at the time of the request.
+package oauth
+
+import (
+	"context"
+	"iter"
+	"sync"
+	"time"
+
+	"go.wdy.de/nago/application/permission"
+	"go.wdy.de/nago/application/token"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/auth"
+	"go.wdy.de/nago/pkg/jwt"
+)
+
+type ClientCreationData struct {
+	Name              string
+	Description       string          `label:"Beschreibung" lines:"3"`
+	RedirectURIs      []string        `label:"Redirect URIs" supportingText:"Eine URI pro Zeile. Erforderlich für den Authorization Code Grant." lines:"3"`
+	Public            bool            `label:"Öffentlicher Client" supportingText:"Für Single-Page- oder native Apps, welche kein Secret geheim halten können."`
+	AuthorizationCode bool            `label:"Authorization Code Grant" supportingText:"Benutzer delegieren ihre Berechtigungen nach Zustimmung."`
+	RefreshToken      bool            `label:"Refresh Tokens ausstellen"`
+	ClientCredentials bool            `label:"Client Credentials Grant" supportingText:"Der Client handelt in eigenem Namen mit den freigegebenen Scopes."`
+	Scopes            []permission.ID `label:"Scopes" source:"nago.permissions"`
+}
+
+// CreateClient registers a new client and returns the plaintext secret, which is never stored. Public clients
+// have no secret. The scopes must be held by the creating subject.
+type CreateClient func(subject auth.Subject, data ClientCreationData) (ClientID, string, error)
+
+// DeleteClient removes the client and revokes all its refresh tokens. Issued access tokens are immediately
+// rejected.
+type DeleteClient func(subject auth.Subject, id ClientID) error
+
+type FindAllClients func(subject auth.Subject) iter.Seq2[Client, error]
+
+// AuthorizationParams are the query parameters of the authorization endpoint.
+type AuthorizationParams struct {
+	ResponseType        string
+	ClientID            ClientID
+	RedirectURI         string
+	Scope               string
+	State               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+}
+
+// StartAuthorization validates the parameters and stores the request until the user has given or refused
+// consent. If the client or the redirect uri are invalid, [UnknownClientErr] or [InvalidRedirectURIErr] are
+// returned and the user agent must not be redirected. For any other [Error], the returned request contains
+// the redirect uri and the state to report the error to the client.
+type StartAuthorization func(params AuthorizationParams) (AuthorizationRequest, error)
+
+// Consent is what the user has to agree to.
+type Consent struct {
+	Request AuthorizationRequest
+	Client  Client
+}
+
+// FindConsent returns the pending authorization request or [UnknownAuthorizationErr].
+type FindConsent func(subject auth.Subject, id string) (Consent, error)
+
+// Authorize consumes the authorization request and returns the uri of the client, to which the user agent must
+// be redirected. If approved, the uri contains an authorization code bound to the subject.
+type Authorize func(subject auth.Subject, id string, approve bool) (redirect string, err error)
+
+// Token implements the token endpoint. Failures are reported as [Error].
+type Token func(req TokenRequest) (TokenResponse, error)
+
+// Revoke implements RFC 7009 for refresh tokens. Unknown tokens are ignored as required by the specification.
+// Access tokens cannot be revoked but expire after a few minutes.
+type Revoke func(client ClientID, secret string, token string) error
+
+// FindKeySet returns the public keys to verify the access tokens.
+type FindKeySet func() (jwt.KeySet, error)
+
+type UseCases struct {
+	CreateClient       CreateClient
+	DeleteClient       DeleteClient
+	FindAllClients     FindAllClients
+	StartAuthorization StartAuthorization
+	FindConsent        FindConsent
+	Authorize          Authorize
+	Token              Token
+	Revoke             Revoke
+	FindKeySet         FindKeySet
+	// AuthenticateSubject accepts the access tokens issued by this server and delegates all other tokens to the
+	// [token.AuthenticateSubject] of the token management. Use it with hapi.BearerAuth.
+	AuthenticateSubject token.AuthenticateSubject
+}
+
+func NewUseCases(
+	ctx context.Context,
+	issuer string,
+	clients ClientRepository,
+	requests AuthorizationRequestRepository,
+	codes AuthorizationCodeRepository,
+	refreshTokens RefreshTokenRepository,
+	signingKeys SigningKeyRepository,
+	subjectFromUser user.SubjectFromUser,
+	getAnonUser user.GetAnonUser,
+	fallback token.AuthenticateSubject,
+) UseCases {
+	var mutex sync.Mutex
+	k := newKeys(signingKeys)
+
+	return UseCases{
+		CreateClient:        NewCreateClient(&mutex, clients),
+		DeleteClient:        NewDeleteClient(&mutex, clients, codes, refreshTokens),
+		FindAllClients:      NewFindAllClients(clients),
+		StartAuthorization:  NewStartAuthorization(&mutex, clients, requests),
+		FindConsent:         NewFindConsent(clients, requests),
+		Authorize:           NewAuthorize(&mutex, issuer, requests, codes),
+		Token:               NewToken(&mutex, issuer, clients, codes, refreshTokens, k, subjectFromUser),
+		Revoke:              NewRevoke(&mutex, clients, refreshTokens),
+		FindKeySet:          k.keySet,
+		AuthenticateSubject: NewAuthenticateSubject(ctx, issuer, clients, k, subjectFromUser, getAnonUser, fallback),
+	}
+}
+
+var now = time.Now
@@ -0,0 +1,289 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package oauth
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"slices"
+	"testing"
+
+	"github.com/worldiety/option"
+	"go.wdy.de/nago/application/permission"
+	"go.wdy.de/nago/application/token"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/auth"
+	"go.wdy.de/nago/pkg/blob/mem"
+	"go.wdy.de/nago/pkg/data/json"
+	"go.wdy.de/nago/pkg/oidc"
+)
+
+const testIssuer = "https://app.example"
+
+// testUser only implements what the use cases and the scoped subject need.
+type testUser struct {
+	user.Subject
+	id    user.ID
+	perms []permission.ID
+}
+
+func (u *testUser) ID() user.ID {
+	return u.id
+}
+
+func (u *testUser) Valid() bool {
+	return true
+}
+
+func (u *testUser) HasPermission(p permission.ID) bool {
+	return slices.Contains(u.perms, p)
+}
+
+func (u *testUser) Audit(p permission.ID) error {
+	if !u.HasPermission(p) {
+		return user.PermissionDeniedErr
+	}
+
+	return nil
+}
+
+var anon = &testUser{id: "anon"}
+
+type testEnv struct {
+	uc       UseCases
+	admin    *testUser
+	alice    *testUser
+	fallback []token.Plaintext
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	env := &testEnv{
+		admin: &testUser{id: "admin", perms: []permission.ID{PermCreateClient, PermDeleteClient, token.PermFindAll, token.PermCreate}},
+		alice: &testUser{id: "alice", perms: []permission.ID{token.PermFindAll}},
+	}
+
+	subjectFromUser := func(subject permission.Auditable, id user.ID) (option.Opt[user.Subject], error) {
+		if id == env.alice.id {
+			return option.Some[user.Subject](env.alice), nil
+		}
+
+		return option.None[user.Subject](), nil
+	}
+
+	fallback := func(remoteAddr string, plaintext token.Plaintext) (auth.Subject, error) {
+		env.fallback = append(env.fallback, plaintext)
+		return anon, nil
+	}
+
+	env.uc = NewUseCases(
+		context.Background(),
+		testIssuer,
+		json.NewSloppyJSONRepository[Client, ClientID](mem.NewBlobStore("clients")),
+		json.NewSloppyJSONRepository[AuthorizationRequest, string](mem.NewBlobStore("requests")),
+		json.NewSloppyJSONRepository[AuthorizationCode, string](mem.NewBlobStore("codes")),
+		json.NewSloppyJSONRepository[RefreshToken, string](mem.NewBlobStore("refresh")),
+		json.NewSloppyJSONRepository[SigningKey, string](mem.NewBlobStore("keys")),
+		subjectFromUser,
+		func() user.Subject { return anon },
+		fallback,
+	)
+
+	return env
+}
+
+func (env *testEnv) authorize(t *testing.T, client ClientID, verifier string, scope string) string {
+	t.Helper()
+
+	req, err := env.uc.StartAuthorization(AuthorizationParams{
+		ResponseType:        "code",
+		ClientID:            client,
+		RedirectURI:         "https://client.example/cb",
+		Scope:               scope,
+		State:               "xyz",
+		CodeChallenge:       oidc.CodeChallenge(verifier),
+		CodeChallengeMethod: "S256",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	consent, err := env.uc.FindConsent(env.alice, req.ID)
+	if err != nil || consent.Client.Name != "CRM" {
+		t.Fatalf("unexpected consent: %v %v", consent, err)
+	}
+
+	redirect, err := env.uc.Authorize(env.alice, req.ID, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, _ := url.Parse(redirect)
+	if u.Query().Get("state") != "xyz" || u.Query().Get("iss") != testIssuer {
+		t.Fatalf("unexpected redirect: %s", redirect)
+	}
+
+	return u.Query().Get("code")
+}
+
+func oauthErrorCode(err error) ErrorCode {
+	var oerr Error
+	if errors.As(err, &oerr) {
+		return oerr.Code
+	}
+
+	return ""
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	env := newTestEnv(t)
+
+	if _, _, err := env.uc.CreateClient(env.alice, ClientCreationData{Name: "CRM", AuthorizationCode: true, RedirectURIs: []string{"https://client.example/cb"}}); err == nil {
+		t.Fatal("expected permission denied")
+	}
+
+	// the admin must not delegate permissions, which the admin does not hold
+	if _, _, err := env.uc.CreateClient(env.admin, ClientCreationData{Name: "CRM", ClientCredentials: true, Scopes: []permission.ID{PermFindAllClients}}); err == nil {
+		t.Fatal("expected privilege escalation to be refused")
+	}
+
+	if _, _, err := env.uc.CreateClient(env.admin, ClientCreationData{Name: "CRM", AuthorizationCode: true, RedirectURIs: []string{"http://client.example/cb"}}); err == nil {
+		t.Fatal("expected insecure redirect uri to be refused")
+	}
+
+	cid, secret, err := env.uc.CreateClient(env.admin, ClientCreationData{
+		Name:              "CRM",
+		RedirectURIs:      []string{"https://client.example/cb", ""},
+		AuthorizationCode: true,
+		RefreshToken:      true,
+		Scopes:            []permission.ID{token.PermFindAll, token.PermCreate},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := env.uc.StartAuthorization(AuthorizationParams{ResponseType: "code", ClientID: cid, RedirectURI: "https://evil.example/cb"}); !errors.Is(err, InvalidRedirectURIErr) {
+		t.Fatalf("expected invalid redirect uri, got %v", err)
+	}
+
+	req, err := env.uc.StartAuthorization(AuthorizationParams{ResponseType: "code", ClientID: cid, State: "xyz"})
+	if oauthErrorCode(err) != InvalidRequest || req.RedirectURI != "https://client.example/cb" {
+		t.Fatalf("expected pkce to be required, got %v", err)
+	}
+
+	verifier := oidc.RandomString()
+	code := env.authorize(t, cid, verifier, "nago.token.find_all")
+
+	tokenReq := TokenRequest{
+		GrantType:    GrantAuthorizationCode,
+		Code:         code,
+		RedirectURI:  "https://client.example/cb",
+		CodeVerifier: oidc.RandomString(),
+		ClientID:     cid,
+		ClientSecret: secret,
+	}
+
+	// a wrong verifier consumes the code anyway
+	if _, err := env.uc.Token(tokenReq); oauthErrorCode(err) != InvalidGrant {
+		t.Fatalf("expected invalid grant, got %v", err)
+	}
+
+	tokenReq.CodeVerifier = verifier
+	if _, err := env.uc.Token(tokenReq); oauthErrorCode(err) != InvalidGrant {
+		t.Fatalf("expected consumed code, got %v", err)
+	}
+
+	tokenReq.Code = env.authorize(t, cid, verifier, "")
+	res, err := env.uc.Token(tokenReq)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subject, err := env.uc.AuthenticateSubject("", token.Plaintext(res.AccessToken))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if subject.ID() != "alice" || !subject.HasPermission(token.PermFindAll) {
+		t.Fatal("expected delegated permission")
+	}
+
+	// the scope has been granted, but the user does not hold the permission
+	if subject.HasPermission(token.PermCreate) || subject.Audit(PermCreateClient) == nil {
+		t.Fatal("scopes must not escalate permissions")
+	}
+
+	if len(env.fallback) != 0 {
+		t.Fatal("access token must not reach the fallback")
+	}
+
+	rotated, err := env.uc.Token(TokenRequest{GrantType: GrantRefreshToken, RefreshToken: res.RefreshToken, Scope: "nago.token.find_all", ClientID: cid, ClientSecret: secret})
+	if err != nil || rotated.RefreshToken == "" || rotated.Scope != "nago.token.find_all" {
+		t.Fatalf("unexpected refresh: %+v %v", rotated, err)
+	}
+
+	if _, err := env.uc.Token(TokenRequest{GrantType: GrantRefreshToken, RefreshToken: res.RefreshToken, ClientID: cid, ClientSecret: secret}); oauthErrorCode(err) != InvalidGrant {
+		t.Fatalf("expected replayed refresh token to be refused, got %v", err)
+	}
+
+	if err := env.uc.Revoke(cid, secret, rotated.RefreshToken); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := env.uc.Token(TokenRequest{GrantType: GrantRefreshToken, RefreshToken: rotated.RefreshToken, ClientID: cid, ClientSecret: secret}); oauthErrorCode(err) != InvalidGrant {
+		t.Fatalf("expected revoked refresh token to be refused, got %v", err)
+	}
+}
+
+func TestClientCredentials(t *testing.T) {
+	env := newTestEnv(t)
+
+	if _, _, err := env.uc.CreateClient(env.admin, ClientCreationData{Name: "SPA", Public: true, ClientCredentials: true}); err == nil {
+		t.Fatal("public clients must not use client credentials")
+	}
+
+	cid, secret, err := env.uc.CreateClient(env.admin, ClientCreationData{Name: "Backup", ClientCredentials: true, Scopes: []permission.ID{token.PermFindAll}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := env.uc.Token(TokenRequest{GrantType: GrantClientCredentials, ClientID: cid, ClientSecret: "wrong"}); oauthErrorCode(err) != InvalidClient {
+		t.Fatalf("expected invalid client, got %v", err)
+	}
+
+	if _, err := env.uc.Token(TokenRequest{GrantType: GrantClientCredentials, ClientID: cid, ClientSecret: secret, Scope: "nago.token.create"}); oauthErrorCode(err) != InvalidScope {
+		t.Fatalf("expected invalid scope, got %v", err)
+	}
+
+	if _, err := env.uc.Token(TokenRequest{GrantType: GrantAuthorizationCode, ClientID: cid, ClientSecret: secret}); oauthErrorCode(err) != UnauthorizedClient {
+		t.Fatalf("expected unauthorized client, got %v", err)
+	}
+
+	res, err := env.uc.Token(TokenRequest{GrantType: GrantClientCredentials, ClientID: cid, ClientSecret: secret})
+	if err != nil || res.RefreshToken != "" {
+		t.Fatalf("unexpected token response: %+v %v", res, err)
+	}
+
+	subject, _ := env.uc.AuthenticateSubject("", token.Plaintext(res.AccessToken))
+	if subject.ID() != user.ID(cid) || !subject.HasPermission(token.PermFindAll) || subject.HasPermission(token.PermCreate) {
+		t.Fatal("expected client subject with exactly the granted scopes")
+	}
+
+	if err := env.uc.DeleteClient(env.admin, cid); err != nil {
+		t.Fatal(err)
+	}
+
+	if subject, _ := env.uc.AuthenticateSubject("", token.Plaintext(res.AccessToken)); subject != anon {
+		t.Fatal("access tokens of deleted clients must be rejected")
+	}
+
+	// static tokens are delegated, even if they look like a JWT
+	if subject, _ := env.uc.AuthenticateSubject("", "my.static.token"); subject != anon || len(env.fallback) != 1 {
+		t.Fatal("expected fallback")
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package oidc
+
+import (
+	"fmt"
+
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/pkg/jwt"
+)
+
+// subjectID scopes the subject to its issuer, because the specification only guarantees its uniqueness
+// within a single issuer. Accounts which have been merged by the NLS before, carry a different identity and
+// are refused by [user.MergeSingleSignOnUser], until an administrator resolves that by hand. The same holds for
+// local password accounts with a matching mail, which must be linked by [user.LinkSingleSignOn] first.
+func subjectID(issuer, subject string) user.NLSUserID {
+	return user.NLSUserID("oidc:" + issuer + "#" + subject)
+}
+
+// ssoUserFromClaims maps the standard claims of OpenID Connect Core 1.0 section 5.1 to the structure of
+// the existing single sign-on merge logic.
+func ssoUserFromClaims(cfg Settings, issuer string, claims jwt.Claims) (user.SingleSignOnUser, error) {
+	email := user.Email(claims.String(cfg.emailClaim()))
+	if email == "" {
+		return user.SingleSignOnUser{}, fmt.Errorf("claim %q is missing, check the scopes and the mappers of the identity provider", cfg.emailClaim())
+	}
+
+	// the merge logic treats the address as verified, thus a missing claim is refused as well. Providers
+	// like Entra ID do not send the claim at all, which the operator must accept explicitly.
+	if verified, _ := claims.Bool("email_verified"); !verified && !cfg.TrustEmail {
+		return user.SingleSignOnUser{}, UnverifiedEmailErr
+	}
+
+	usr := user.SingleSignOnUser{
+		ID:                subjectID(issuer, claims.String("sub")),
+		RequireLinked:     true,
+		Firstname:         claims.String("given_name"),
+		Lastname:          claims.String("family_name"),
+		Name:              claims.String("name"),
+		Email:             email,
+		PreferredLanguage: claims.String("locale"),
+		MobilePhone:       claims.String("phone_number"),
+	}
+
+	if addr, ok := claims["address"].(map[string]any); ok {
+		address := jwt.Claims(addr)
+		usr.Country = address.String("country")
+		usr.State = address.String("region")
+		usr.PostalCode = address.String("postal_code")
+		usr.City = address.String("locality")
+	}
+
+	return usr, nil
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package oidc
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.wdy.de/nago/application/group"
+	"go.wdy.de/nago/application/secret"
+	"go.wdy.de/nago/application/user"
+	oidcrp "go.wdy.de/nago/pkg/oidc"
+	"go.wdy.de/nago/pkg/std"
+)
+
+// clients keeps a discovered client per provider, so that the discovery document and the signing keys are
+// not requested for each login. A client is replaced, as soon as its relevant settings change.
+type clients struct {
+	ctx         context.Context
+	http        *http.Client
+	redirect    string
+	findSecrets secret.FindGroupSecrets
+
+	mutex   sync.Mutex
+	entries map[secret.ID]clientEntry
+}
+
+type clientEntry struct {
+	key    string
+	client *oidcrp.Client
+}
+
+func newClients(ctx context.Context, redirect string, findSecrets secret.FindGroupSecrets) *clients {
+	return &clients{
+		ctx:         ctx,
+		http:        &http.Client{Timeout: 30 * time.Second},
+		redirect:    redirect,
+		findSecrets: findSecrets,
+		entries:     map[secret.ID]clientEntry{},
+	}
+}
+
+// settings returns the current settings of the given provider. Only secrets shared with the system group
+// are considered.
+func (c *clients) settings(id secret.ID) (std.Option[Settings], error) {
+	for sec, err := range c.findSecrets(user.SU(), group.System) {
+		if err != nil {
+			return std.None[Settings](), err
+		}
+
+		if sec.ID != id {
+			continue
+		}
+
+		if cfg, ok := sec.Credentials.(Settings); ok {
+			return std.Some(cfg), nil
+		}
+	}
+
+	return std.None[Settings](), nil
+}
+
+func (c *clients) get(id secret.ID, cfg Settings) (*oidcrp.Client, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	key := cfg.clientKey()
+	if e, ok := c.entries[id]; ok && e.key == key {
+		return e.client, nil
+	}
+
+	ctx, cancel := context.WithTimeout(c.ctx, 30*time.Second)
+	defer cancel()
+
+	client, err := oidcrp.NewClient(ctx, c.http, oidcrp.Config{
+		Issuer:       cfg.Issuer,
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		RedirectURL:  c.redirect,
+		Scopes:       cfg.scopes(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.entries[id] = clientEntry{key: key, client: client}
+	return client, nil
+}
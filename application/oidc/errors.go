@@ -0,0 +1,32 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package oidc
+
+const (
+	InvalidStateErr    InvalidStateError    = "invalid or expired authentication flow"
+	UnknownProviderErr UnknownProviderError = "unknown identity provider"
+	UnverifiedEmailErr UnverifiedEmailError = "the identity provider has not verified the mail address"
+)
+
+type InvalidStateError string
+
+func (e InvalidStateError) Error() string {
+	return string(e)
+}
+
+type UnknownProviderError string
+
+func (e UnknownProviderError) Error() string {
+	return string(e)
+}
+
+type UnverifiedEmailError string
+
+func (e UnverifiedEmailError) Error() string {
+	return string(e)
+}
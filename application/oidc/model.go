@@ -0,0 +1,45 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package oidc
+
+import (
+	"time"
+
+	"go.wdy.de/nago/application/secret"
+	"go.wdy.de/nago/application/session"
+	"go.wdy.de/nago/pkg/data"
+)
+
+// State is the random value, which binds the redirect of the identity provider to a pending [Flow].
+type State string
+
+// Flow is a pending authorization request. It is single use and consumed by the first exchange attempt,
+// whether it succeeds or not.
+type Flow struct {
+	ID        State      `json:"id"`
+	Session   session.ID `json:"sid"`
+	Provider  secret.ID  `json:"provider"`
+	Nonce     string     `json:"nonce"`
+	Verifier  string     `json:"verifier"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+func (f Flow) Identity() State {
+	return f.ID
+}
+
+type FlowRepository data.Repository[Flow, State]
+
+// flowLifetime limits how long a user may take at the identity provider.
+const flowLifetime = 10 * time.Minute
+
+// Provider is a configured identity provider, as offered at the login page.
+type Provider struct {
+	ID   secret.ID
+	Name string
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package oidc
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/worldiety/enum"
+	"go.wdy.de/nago/application/secret"
+)
+
+var _ = enum.Variant[secret.Credentials, Settings](enum.Rename[Settings]("nago.oidc.settings"))
+
+// Settings configure a single OpenID Connect identity provider. They are kept as [secret.Credentials] and
+// must be shared with the system group to become available at the login page. Multiple providers may exist
+// side by side, each one is offered as a separate button.
+type Settings struct {
+	Name         string   `json:"name" value:"Mein OpenID Connect Anbieter" label:"Name" supportingText:"Die Beschriftung der Schaltfläche auf der Anmeldeseite, z.B. Keycloak oder Microsoft."`
+	Issuer       string   `json:"issuer" label:"Issuer URL" supportingText:"Z.B. https://keycloak.example.com/realms/myrealm, https://login.microsoftonline.com/<tenant>/v2.0 oder https://authentik.example.com/application/o/<slug>/. Die Anwendung muss als Redirect-URI <App-URL>/account/oidc/callback eingetragen haben."`
+	ClientID     string   `json:"clientId" label:"Client ID"`
+	ClientSecret string   `json:"clientSecret" label:"Client Secret" style:"secret" supportingText:"Bei öffentlichen Clients leer lassen, dann wird ausschließlich PKCE verwendet."`
+	Scopes       string   `json:"scopes" value:"profile email" label:"Scopes" supportingText:"Durch Leerzeichen getrennt. Der Scope openid wird immer angefragt."`
+	EmailClaim   string   `json:"emailClaim" value:"email" label:"E-Mail Claim" supportingText:"Bei Entra ID ohne optionalen email-Claim z.B. preferred_username."`
+	GroupsClaim  string   `json:"groupsClaim" value:"groups" label:"Gruppen Claim" supportingText:"Der Claim, dessen Werte für die Zuordnung zu Rollen und Gruppen ausgewertet werden."`
+	TrustEmail   bool     `json:"trustEmail" label:"E-Mail-Adressen vertrauen" supportingText:"Nur aktivieren, wenn der Anbieter keinen email_verified-Claim sendet (z.B. Entra ID) und Nutzer ihre E-Mail-Adresse dort nicht selbst ändern können. Sonst werden nur bestätigte Adressen akzeptiert."`
+	UserInfo     bool     `json:"userInfo" label:"Userinfo abfragen" supportingText:"Fehlende Claims zusätzlich vom Userinfo-Endpunkt laden, falls der Anbieter sie nicht in das ID-Token schreibt."`
+	RoleMapping  []string `json:"roleMapping" lines:"5" label:"Rollenzuordnung" supportingText:"Jede Zeile ordnet einen Wert des Gruppen-Claims einer Rolle zu, z.B. /nago-admins=nago.admin. Für zugeordnete Rollen ist der Anbieter maßgeblich, d.h. die Mitgliedschaft wird bei jeder Anmeldung hinzugefügt oder entfernt."`
+	GroupMapping []string `json:"groupMapping" lines:"5" label:"Gruppenzuordnung" supportingText:"Jede Zeile ordnet einen Wert des Gruppen-Claims einer Gruppe zu, z.B. staff=staff-group-id. Es gelten dieselben Regeln wie für Rollen."`
+	_            struct{} `credentialName:"OpenID Connect" credentialDescription:"Anmeldung über einen OpenID Connect Anbieter wie Keycloak, Entra ID oder Authentik." credentialLogo:"https://openid.net/favicon.ico"`
+}
+
+func (s Settings) GetName() string {
+	return s.Name
+}
+
+func (s Settings) Credentials() bool {
+	return true
+}
+
+func (s Settings) IsZero() bool {
+	return s.Name == "" && s.Issuer == "" && s.ClientID == "" && s.ClientSecret == "" && s.Scopes == "" &&
+		s.EmailClaim == "" && s.GroupsClaim == "" && !s.TrustEmail && !s.UserInfo && len(s.RoleMapping) == 0 && len(s.GroupMapping) == 0
+}
+
+func (s Settings) scopes() []string {
+	return strings.Fields(s.Scopes)
+}
+
+func (s Settings) emailClaim() string {
+	if s.EmailClaim == "" {
+		return "email"
+	}
+
+	return s.EmailClaim
+}
+
+func (s Settings) groupsClaim() string {
+	if s.GroupsClaim == "" {
+		return "groups"
+	}
+
+	return s.GroupsClaim
+}
+
+// clientKey identifies the fields, which require a new discovery, if they change.
+func (s Settings) clientKey() string {
+	return strings.Join(append([]string{s.Issuer, s.ClientID, s.ClientSecret}, s.scopes()...), "\x00")
+}
+
+// mapping is a parsed role or group mapping, which resolves claim values to target identifiers.
+type mapping[T ~string] map[string][]T
+
+// parseMapping reads lines in the form value=target. The last equal sign separates both parts, because
+// claim values like LDAP distinguished names may contain equal signs themselves, but our identifiers do not.
+func parseMapping[T ~string](lines []string) mapping[T] {
+	m := mapping[T]{}
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		idx := strings.LastIndex(line, "=")
+		if idx <= 0 {
+			continue
+		}
+
+		value := strings.TrimSpace(line[:idx])
+		target := T(strings.TrimSpace(line[idx+1:]))
+		if value == "" || target == "" || slices.Contains(m[value], target) {
+			continue
+		}
+
+		m[value] = append(m[value], target)
+	}
+
+	return m
+}
+
+// managed returns all targets, which are controlled by the mapping.
+func (m mapping[T]) managed() []T {
+	var res []T
+	for _, targets := range m {
+		for _, t := range targets {
+			if !slices.Contains(res, t) {
+				res = append(res, t)
+			}
+		}
+	}
+
+	return res
+}
+
+// granted returns all targets, which are granted by the given claim values.
+func (m mapping[T]) granted(values []string) []T {
+	var res []T
+	for _, v := range values {
+		for _, t := range m[v] {
+			if !slices.Contains(res, t) {
+				res = append(res, t)
+			}
+		}
+	}
+
+	return res
+}
+
+// apply returns the new memberships, in which all managed targets are replaced by the granted ones and all
+// other targets are kept untouched. The second return value is false, if nothing has changed.
+func (m mapping[T]) apply(current []T, values []string) ([]T, bool) {
+	managed := m.managed()
+	granted := m.granted(values)
+
+	var res []T
+	for _, t := range current {
+		if slices.Contains(managed, t) && !slices.Contains(granted, t) {
+			continue
+		}
+
+		res = append(res, t)
+	}
+
+	for _, t := range granted {
+		if !slices.Contains(res, t) {
+			res = append(res, t)
+		}
+	}
+
+	changed := len(res) != len(current)
+	if !changed {
+		for _, t := range res {
+			if !slices.Contains(current, t) {
+				changed = true
+				break
+			}
+		}
+	}
+
+	return res, changed
+}
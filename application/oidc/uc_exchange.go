@@ -0,0 +1,186 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package oidc
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"go.wdy.de/nago/application/group"
+	"go.wdy.de/nago/application/role"
+	"go.wdy.de/nago/application/session"
+	"go.wdy.de/nago/application/user"
+)
+
+func NewExchange(
+	ctx context.Context,
+	mutex *sync.Mutex,
+	repo FlowRepository,
+	clients *clients,
+	mergeUser user.MergeSingleSignOnUser,
+	loginUser session.LoginUser,
+	sysUser user.SysUser,
+	findUserByID user.FindByID,
+	listRoles user.ListRoles,
+	listGroups user.ListGroups,
+	updateRoles user.UpdateOtherRoles,
+	updateGroups user.UpdateOtherGroups,
+) Exchange {
+	return func(id session.ID, state State, code string) error {
+		flow, err := consumeFlow(mutex, repo, id, state)
+		if err != nil {
+			return err
+		}
+
+		optCfg, err := clients.settings(flow.Provider)
+		if err != nil {
+			return fmt.Errorf("cannot load oidc settings: %w", err)
+		}
+
+		if optCfg.IsNone() {
+			return UnknownProviderErr
+		}
+
+		cfg := optCfg.Unwrap()
+		client, err := clients.get(flow.Provider, cfg)
+		if err != nil {
+			return fmt.Errorf("cannot connect to identity provider: %w", err)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+
+		tokens, err := client.Exchange(ctx, code, flow.Verifier)
+		if err != nil {
+			return err
+		}
+
+		idt, err := client.VerifyIDToken(ctx, tokens.IDToken, flow.Nonce)
+		if err != nil {
+			return err
+		}
+
+		claims := idt.Claims
+		if cfg.UserInfo && tokens.AccessToken != "" {
+			info, err := client.UserInfo(ctx, tokens.AccessToken, idt.Subject)
+			if err != nil {
+				return err
+			}
+
+			// the signed id token always wins
+			for k, v := range info {
+				if _, ok := claims[k]; !ok {
+					claims[k] = v
+				}
+			}
+		}
+
+		ssoUser, err := ssoUserFromClaims(cfg, idt.Issuer, claims)
+		if err != nil {
+			return err
+		}
+
+		uid, err := mergeUser(ssoUser, nil)
+		if err != nil {
+			return fmt.Errorf("cannot merge oidc user: %w", err)
+		}
+
+		optUsr, err := findUserByID(sysUser(), uid)
+		if err != nil {
+			return fmt.Errorf("cannot find user: %w", err)
+		}
+
+		if optUsr.IsNone() || !optUsr.Unwrap().Enabled() {
+			return fmt.Errorf("user %s is disabled: %w", uid, os.ErrPermission)
+		}
+
+		groupValues := claims.Strings(cfg.groupsClaim())
+
+		if m := parseMapping[role.ID](cfg.RoleMapping); len(m) > 0 {
+			current, err := collect(listRoles(sysUser(), uid))
+			if err != nil {
+				return fmt.Errorf("cannot list roles: %w", err)
+			}
+
+			if roles, changed := m.apply(current, groupValues); changed {
+				if err := updateRoles(sysUser(), uid, roles); err != nil {
+					return fmt.Errorf("cannot update roles: %w", err)
+				}
+
+				slog.Info("updated roles from oidc claims", "user", uid, "roles", roles)
+			}
+		}
+
+		if m := parseMapping[group.ID](cfg.GroupMapping); len(m) > 0 {
+			current, err := collect(listGroups(sysUser(), uid))
+			if err != nil {
+				return fmt.Errorf("cannot list groups: %w", err)
+			}
+
+			if groups, changed := m.apply(current, groupValues); changed {
+				if err := updateGroups(sysUser(), uid, groups); err != nil {
+					return fmt.Errorf("cannot update groups: %w", err)
+				}
+
+				slog.Info("updated groups from oidc claims", "user", uid, "groups", groups)
+			}
+		}
+
+		return loginUser(id, uid)
+	}
+}
+
+// consumeFlow removes the flow in any case, so that a state can never be tried twice.
+func consumeFlow(mutex *sync.Mutex, repo FlowRepository, id session.ID, state State) (Flow, error) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if state == "" {
+		return Flow{}, InvalidStateErr
+	}
+
+	optFlow, err := repo.FindByID(state)
+	if err != nil {
+		return Flow{}, fmt.Errorf("cannot find oidc flow: %w", err)
+	}
+
+	if optFlow.IsNone() {
+		return Flow{}, InvalidStateErr
+	}
+
+	if err := repo.DeleteByID(state); err != nil {
+		return Flow{}, fmt.Errorf("cannot delete oidc flow: %w", err)
+	}
+
+	flow := optFlow.Unwrap()
+
+	// the state must return to the same browser, which started the flow, otherwise an attacker could inject
+	// their own authorization response into a foreign session (login CSRF)
+	if flow.Session != id || now().Sub(flow.CreatedAt) > flowLifetime {
+		return Flow{}, InvalidStateErr
+	}
+
+	return flow, nil
+}
+
+func collect[T any](it func(yield func(T, error) bool)) ([]T, error) {
+	var res []T
+	for v, err := range it {
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, v)
+	}
+
+	return res, nil
+}
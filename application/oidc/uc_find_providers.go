@@ -0,0 +1,41 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package oidc
+
+import (
+	"slices"
+	"strings"
+
+	"go.wdy.de/nago/application/group"
+	"go.wdy.de/nago/application/secret"
+	"go.wdy.de/nago/application/user"
+)
+
+func NewFindProviders(findSecrets secret.FindGroupSecrets) FindProviders {
+	return func() ([]Provider, error) {
+		var res []Provider
+		for sec, err := range findSecrets(user.SU(), group.System) {
+			if err != nil {
+				return nil, err
+			}
+
+			cfg, ok := sec.Credentials.(Settings)
+			if !ok || cfg.Issuer == "" || cfg.ClientID == "" {
+				continue
+			}
+
+			res = append(res, Provider{ID: sec.ID, Name: cfg.Name})
+		}
+
+		slices.SortFunc(res, func(a, b Provider) int {
+			return strings.Compare(a.Name, b.Name)
+		})
+
+		return res, nil
+	}
+}
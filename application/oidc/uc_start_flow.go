@@ -0,0 +1,84 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package oidc
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.wdy.de/nago/application/secret"
+	"go.wdy.de/nago/application/session"
+	oidcrp "go.wdy.de/nago/pkg/oidc"
+)
+
+func NewStartFlow(mutex *sync.Mutex, repo FlowRepository, clients *clients) StartFlow {
+	return func(id session.ID, provider secret.ID) (string, error) {
+		if id == "" {
+			return "", fmt.Errorf("id must not be empty")
+		}
+
+		optCfg, err := clients.settings(provider)
+		if err != nil {
+			return "", fmt.Errorf("cannot load oidc settings: %w", err)
+		}
+
+		if optCfg.IsNone() {
+			return "", UnknownProviderErr
+		}
+
+		client, err := clients.get(provider, optCfg.Unwrap())
+		if err != nil {
+			return "", fmt.Errorf("cannot connect to identity provider: %w", err)
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		removeExpiredFlows(repo)
+
+		flow := Flow{
+			ID:        State(oidcrp.RandomString()),
+			Session:   id,
+			Provider:  provider,
+			Nonce:     oidcrp.RandomString(),
+			Verifier:  oidcrp.RandomString(),
+			CreatedAt: now(),
+		}
+
+		if err := repo.Save(flow); err != nil {
+			return "", fmt.Errorf("cannot save oidc flow: %w", err)
+		}
+
+		return client.AuthCodeURL(string(flow.ID), flow.Nonce, flow.Verifier), nil
+	}
+}
+
+// removeExpiredFlows deletes the flows which have been abandoned at the identity provider.
+func removeExpiredFlows(repo FlowRepository) {
+	var expired []State
+	for flow, err := range repo.All() {
+		if err != nil {
+			slog.Error("cannot read oidc flow", "err", err.Error())
+			return
+		}
+
+		if now().Sub(flow.CreatedAt) > flowLifetime {
+			expired = append(expired, flow.ID)
+		}
+	}
+
+	for _, id := range expired {
+		if err := repo.DeleteByID(id); err != nil {
+			slog.Error("cannot delete expired oidc flow", "err", err.Error())
+		}
+	}
+}
+
+var now = time.Now
@@ -0,0 +1,65 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package uioidc
+
+import (
+	"log/slog"
+
+	"go.wdy.de/nago/application/localization/rstring"
+	"go.wdy.de/nago/application/oidc"
+	uisession "go.wdy.de/nago/application/session/ui"
+	"go.wdy.de/nago/pkg/xsync"
+	"go.wdy.de/nago/presentation/core"
+	"go.wdy.de/nago/presentation/ui"
+	"go.wdy.de/nago/presentation/ui/alert"
+)
+
+// PageCallback receives the redirect of the identity provider and completes the login.
+func PageCallback(wnd core.Window, exchange oidc.Exchange) core.View {
+	values := wnd.Values()
+	if errCode := values["error"]; errCode != "" {
+		// e.g. access_denied, if the user has cancelled the login at the identity provider
+		slog.Warn("identity provider returned an error", "error", errCode, "description", values["error_description"])
+		return ui.VStack(alert.Banner(rstring.LabelError.Get(wnd), uisession.StrInvalidNonce.Get(wnd))).Frame(ui.Frame{}.MatchScreen())
+	}
+
+	state := oidc.State(values["state"])
+	code := values["code"]
+	if state == "" || code == "" {
+		return ui.VStack(alert.Banner(rstring.LabelError.Get(wnd), uisession.StrInvalidNonce.Get(wnd))).Frame(ui.Frame{}.MatchScreen())
+	}
+
+	// the exchange consumes the state, thus it must not run again, if the page is rendered once more
+	started := core.AutoState[bool](wnd)
+	if started.Get() {
+		return ui.VStack(
+			alert.BannerMessages(wnd),
+			ui.Text(rstring.LabelPleaseWait.Get(wnd)),
+		).Frame(ui.Frame{}.MatchScreen())
+	}
+
+	started.Set(true)
+	xsync.Go(func() error {
+		if err := exchange(wnd.Session().ID(), state, code); err != nil {
+			alert.ShowBannerError(wnd, err)
+			return nil
+		}
+
+		wnd.Navigation().ForwardTo(".", nil)
+		return nil
+	}, func(err error) {
+		if err != nil {
+			alert.ShowBannerError(wnd, err)
+		}
+	})
+
+	return ui.VStack(
+		alert.BannerMessages(wnd),
+		ui.Text(rstring.LabelPleaseWait.Get(wnd)),
+	).Frame(ui.Frame{}.MatchScreen())
+}
@@ -0,0 +1,17 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package uioidc
+
+import (
+	"go.wdy.de/nago/presentation/core"
+)
+
+type Pages struct {
+	// Callback is the redirect uri, which must be registered at the identity provider.
+	Callback core.NavigationPath
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package oidc
+
+import (
+	"context"
+	"sync"
+
+	"go.wdy.de/nago/application/secret"
+	"go.wdy.de/nago/application/session"
+	"go.wdy.de/nago/application/user"
+)
+
+// FindProviders returns all configured identity providers, which are shared with the system group. This is
+// public information, because it is shown at the login page.
+type FindProviders func() ([]Provider, error)
+
+// StartFlow allocates a new authorization request for the given session and returns the uri of the
+// identity provider, to which the user agent must be sent.
+type StartFlow func(id session.ID, provider secret.ID) (uri string, err error)
+
+// Exchange completes the flow identified by the returned state. It redeems the code, validates the id token,
+// merges the user, applies the role and group mapping and finally authenticates the given session.
+type Exchange func(id session.ID, state State, code string) error
+
+type UseCases struct {
+	FindProviders FindProviders
+	StartFlow     StartFlow
+	Exchange      Exchange
+}
+
+func NewUseCases(
+	ctx context.Context,
+	redirect string,
+	repo FlowRepository,
+	findSecrets secret.FindGroupSecrets,
+	mergeUser user.MergeSingleSignOnUser,
+	loginUser session.LoginUser,
+	sysUser user.SysUser,
+	findUserByID user.FindByID,
+	listRoles user.ListRoles,
+	listGroups user.ListGroups,
+	updateRoles user.UpdateOtherRoles,
+	updateGroups user.UpdateOtherGroups,
+) UseCases {
+	var mutex sync.Mutex
+	c := newClients(ctx, redirect, findSecrets)
+
+	return UseCases{
+		FindProviders: NewFindProviders(findSecrets),
+		StartFlow:     NewStartFlow(&mutex, repo, c),
+		Exchange:      NewExchange(ctx, &mutex, repo, c, mergeUser, loginUser, sysUser, findUserByID, listRoles, listGroups, updateRoles, updateGroups),
+	}
+}
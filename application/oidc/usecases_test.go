@@ -0,0 +1,327 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/worldiety/option"
+	"go.wdy.de/nago/application/group"
+	"go.wdy.de/nago/application/permission"
+	"go.wdy.de/nago/application/role"
+	"go.wdy.de/nago/application/secret"
+	"go.wdy.de/nago/application/session"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/auth"
+	"go.wdy.de/nago/pkg/blob/mem"
+	datajson "go.wdy.de/nago/pkg/data/json"
+	"go.wdy.de/nago/pkg/jwt"
+	oidcrp "go.wdy.de/nago/pkg/oidc"
+)
+
+// stubIdP issues an id token with the configured claims for each authorization request.
+type stubIdP struct {
+	srv    *httptest.Server
+	key    *ecdsa.PrivateKey
+	mutex  sync.Mutex
+	nonces map[string]string // code => nonce
+	claims map[string]any
+}
+
+func newStubIdP(t *testing.T) *stubIdP {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	idp := &stubIdP{key: key, nonces: map[string]string{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(oidcrp.Metadata{
+			Issuer:                idp.srv.URL,
+			AuthorizationEndpoint: idp.srv.URL + "/auth",
+			TokenEndpoint:         idp.srv.URL + "/token",
+			JWKSURI:               idp.srv.URL + "/jwks",
+		})
+	})
+
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(jwt.KeySet{Keys: []jwt.JWK{{
+			Kty: "EC",
+			Crv: "P-256",
+			X:   base64.RawURLEncoding.EncodeToString(key.PublicKey.X.FillBytes(make([]byte, 32))),
+			Y:   base64.RawURLEncoding.EncodeToString(key.PublicKey.Y.FillBytes(make([]byte, 32))),
+		}}})
+	})
+
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		idp.mutex.Lock()
+		defer idp.mutex.Unlock()
+
+		nonce, ok := idp.nonces[r.FormValue("code")]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(oidcrp.Error{Code: "invalid_grant"})
+			return
+		}
+
+		claims := map[string]any{
+			"iss":   idp.srv.URL,
+			"sub":   "4711",
+			"aud":   "nago",
+			"exp":   time.Now().Add(time.Minute).Unix(),
+			"iat":   time.Now().Unix(),
+			"nonce": nonce,
+		}
+
+		for k, v := range idp.claims {
+			claims[k] = v
+		}
+
+		_ = json.NewEncoder(w).Encode(oidcrp.TokenResponse{AccessToken: "at", TokenType: "Bearer", IDToken: idp.sign(claims)})
+	})
+
+	idp.srv = httptest.NewServer(mux)
+	t.Cleanup(idp.srv.Close)
+
+	return idp
+}
+
+func (idp *stubIdP) sign(claims map[string]any) string {
+	hdr, _ := json.Marshal(jwt.Header{Alg: jwt.ES256})
+	body, _ := json.Marshal(claims)
+	input := base64.RawURLEncoding.EncodeToString(hdr) + "." + base64.RawURLEncoding.EncodeToString(body)
+	sum := sha256.Sum256([]byte(input))
+	r, s, _ := ecdsa.Sign(rand.Reader, idp.key, sum[:])
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+// authorize simulates the login at the identity provider and returns the state and the code.
+func (idp *stubIdP) authorize(t *testing.T, uri string) (State, string) {
+	t.Helper()
+
+	u, err := url.Parse(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if u.Query().Get("code_challenge_method") != "S256" || u.Query().Get("code_challenge") == "" {
+		t.Fatalf("expected pkce: %s", uri)
+	}
+
+	idp.mutex.Lock()
+	defer idp.mutex.Unlock()
+
+	code := oidcrp.RandomString()
+	idp.nonces[code] = u.Query().Get("nonce")
+
+	return State(u.Query().Get("state")), code
+}
+
+type testEnv struct {
+	uc       UseCases
+	merged   []user.SingleSignOnUser
+	loggedIn map[session.ID]user.ID
+	roles    []role.ID
+}
+
+func newTestEnv(t *testing.T, cfg Settings) *testEnv {
+	env := &testEnv{loggedIn: map[session.ID]user.ID{}, roles: []role.ID{"local"}}
+
+	findSecrets := func(subject auth.Subject, gid group.ID) iter.Seq2[secret.Secret, error] {
+		return func(yield func(secret.Secret, error) bool) {
+			yield(secret.Secret{ID: "keycloak", Groups: []group.ID{group.System}, Credentials: cfg}, nil)
+		}
+	}
+
+	merge := func(u user.SingleSignOnUser, avatar []byte) (user.ID, error) {
+		env.merged = append(env.merged, u)
+		return "alice", nil
+	}
+
+	login := func(id session.ID, uid user.ID) error {
+		env.loggedIn[id] = uid
+		return nil
+	}
+
+	findByID := func(subject permission.Auditable, id user.ID) (option.Opt[user.User], error) {
+		return option.Some(user.User{ID: id, Status: user.Enabled{}}), nil
+	}
+
+	listRoles := func(subject user.AuditableUser, uid user.ID) iter.Seq2[role.ID, error] {
+		return func(yield func(role.ID, error) bool) {
+			for _, rid := range env.roles {
+				if !yield(rid, nil) {
+					return
+				}
+			}
+		}
+	}
+
+	listGroups := func(subject user.AuditableUser, uid user.ID) iter.Seq2[group.ID, error] {
+		return func(yield func(group.ID, error) bool) {}
+	}
+
+	updateRoles := func(subject user.AuditableUser, id user.ID, roles []role.ID) error {
+		env.roles = roles
+		return nil
+	}
+
+	updateGroups := func(subject user.AuditableUser, id user.ID, groups []group.ID) error {
+		return nil
+	}
+
+	repo := datajson.NewSloppyJSONRepository[Flow, State](mem.NewBlobStore("oidc"))
+	env.uc = NewUseCases(context.Background(), "https://app.example/account/oidc/callback", repo, findSecrets, merge, login, user.SU, findByID, listRoles, listGroups, updateRoles, updateGroups)
+
+	return env
+}
+
+func TestExchange(t *testing.T) {
+	idp := newStubIdP(t)
+	idp.claims = map[string]any{
+		"email":          "Alice@Example.com",
+		"email_verified": true,
+		"given_name":     "Alice",
+		"family_name":    "Liddell",
+		"groups":         []string{"/nago-admins"},
+	}
+
+	env := newTestEnv(t, Settings{
+		Name:        "Keycloak",
+		Issuer:      idp.srv.URL,
+		ClientID:    "nago",
+		RoleMapping: []string{"/nago-admins = nago.admin", "/auditors=nago.audit", "broken"},
+	})
+
+	providers, err := env.uc.FindProviders()
+	if err != nil || len(providers) != 1 || providers[0].Name != "Keycloak" {
+		t.Fatalf("unexpected providers: %v %v", providers, err)
+	}
+
+	uri, err := env.uc.StartFlow("s1", providers[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state, code := idp.authorize(t, uri)
+
+	// a different browser must not be able to complete the flow, which also consumes the state
+	if err := env.uc.Exchange("s2", state, code); !errors.Is(err, InvalidStateErr) {
+		t.Fatalf("expected invalid state, got %v", err)
+	}
+
+	if err := env.uc.Exchange("s1", state, code); !errors.Is(err, InvalidStateErr) {
+		t.Fatalf("expected consumed state, got %v", err)
+	}
+
+	uri, _ = env.uc.StartFlow("s1", providers[0].ID)
+	state, code = idp.authorize(t, uri)
+	if err := env.uc.Exchange("s1", state, code); err != nil {
+		t.Fatal(err)
+	}
+
+	if env.loggedIn["s1"] != "alice" {
+		t.Fatal("expected session to be authenticated")
+	}
+
+	got := env.merged[0]
+	if got.ID != subjectID(idp.srv.URL, "4711") || got.Firstname != "Alice" || got.Email != "Alice@Example.com" {
+		t.Fatalf("unexpected merge data: %+v", got)
+	}
+
+	if !got.RequireLinked {
+		t.Fatal("an oidc login must never take over an unlinked local account")
+	}
+
+	if !slices.Equal(env.roles, []role.ID{"local", "nago.admin"}) {
+		t.Fatalf("unexpected roles: %v", env.roles)
+	}
+
+	// the provider removes the group, thus the mapped role must be revoked but the local one kept
+	idp.claims["groups"] = []string{"/auditors"}
+	uri, _ = env.uc.StartFlow("s1", providers[0].ID)
+	state, code = idp.authorize(t, uri)
+	if err := env.uc.Exchange("s1", state, code); err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(env.roles, []role.ID{"local", "nago.audit"}) {
+		t.Fatalf("unexpected roles: %v", env.roles)
+	}
+}
+
+func TestExchangeRefusesUnverifiedEmail(t *testing.T) {
+	idp := newStubIdP(t)
+	idp.claims = map[string]any{"email": "mallory@example.com", "email_verified": false}
+
+	env := newTestEnv(t, Settings{Name: "Authentik", Issuer: idp.srv.URL, ClientID: "nago"})
+	uri, err := env.uc.StartFlow("s1", "keycloak")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state, code := idp.authorize(t, uri)
+	if err := env.uc.Exchange("s1", state, code); !errors.Is(err, UnverifiedEmailErr) {
+		t.Fatalf("expected unverified email, got %v", err)
+	}
+
+	if len(env.merged) != 0 || len(env.loggedIn) != 0 {
+		t.Fatal("user must not be merged or logged in")
+	}
+}
+
+func TestSSOUserFromClaimsRequiresVerifiedEmail(t *testing.T) {
+	claims := jwt.Claims{"sub": "1", "email": "mallory@example.com"}
+
+	if _, err := ssoUserFromClaims(Settings{}, "https://idp", claims); !errors.Is(err, UnverifiedEmailErr) {
+		t.Fatalf("a missing email_verified claim must be refused, got %v", err)
+	}
+
+	if _, err := ssoUserFromClaims(Settings{TrustEmail: true}, "https://idp", claims); err != nil {
+		t.Fatalf("a trusted provider must be accepted, got %v", err)
+	}
+}
+
+func TestParseMapping(t *testing.T) {
+	m := parseMapping[group.ID]([]string{
+		"# comment",
+		"cn=staff,ou=groups,dc=example,dc=com=staff",
+		"staff=staff",
+		"staff=everyone",
+		"=nothing",
+		"nothing=",
+	})
+
+	if got := m.granted([]string{"cn=staff,ou=groups,dc=example,dc=com"}); !slices.Equal(got, []group.ID{"staff"}) {
+		t.Fatalf("unexpected groups: %v", got)
+	}
+
+	if got := m.granted([]string{"staff"}); !slices.Equal(got, []group.ID{"staff", "everyone"}) {
+		t.Fatalf("unexpected groups: %v", got)
+	}
+
+	if _, changed := m.apply([]group.ID{"other", "staff", "everyone"}, []string{"staff"}); changed {
+		t.Fatal("expected no change")
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package permission
+
+import "sync/atomic"
+
+// Decision is the outcome of a single audit of a subject, see [Auditable.Audit]. Subject implementations report
+// each decision by [Report], so that a central audit trail can be recorded without package cycles. The system user
+// and anonymous subjects do not report, because they have no identity worth auditing.
+type Decision struct {
+	// Subject is the id of the acting user, token or client.
+	Subject string
+	// Permission is the audited use case permission.
+	Permission ID
+	// Namespace and Instance identify the resource, if the audit was resource based. Both are empty for global
+	// permissions.
+	Namespace string
+	Instance  string
+	// Allowed is true, if the subject had the permission.
+	Allowed bool
+	// Reason is the error message of a denied audit.
+	Reason string
+}
+
+var recorder atomic.Pointer[func(Decision)]
+
+// SetRecorder installs the global receiver of all reported decisions. There is only a single recorder and a nil
+// fn removes it. The recorder is invoked synchronously on the auditing goroutine, thus it must not block.
+func SetRecorder(fn func(Decision)) {
+	if fn == nil {
+		recorder.Store(nil)
+		return
+	}
+
+	recorder.Store(&fn)
+}
+
+// Report passes the decision to the installed recorder, if any, and returns err unchanged, so that it can wrap
+// the return statement of an audit. The decision is allowed, if err is nil.
+func Report(d Decision, err error) error {
+	fn := recorder.Load()
+	if fn == nil {
+		return err
+	}
+
+	d.Allowed = err == nil
+	if err != nil {
+		d.Reason = err.Error()
+	}
+
+	(*fn)(d)
+
+	return err
+}
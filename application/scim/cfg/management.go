@@ -0,0 +1,70 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package cfgscim
+
+import (
+	"log/slog"
+	"strings"
+
+	"go.wdy.de/nago/application"
+	cfghapi "go.wdy.de/nago/application/hapi/cfg"
+	"go.wdy.de/nago/application/scim"
+	scimhttp "go.wdy.de/nago/application/scim/http"
+	"go.wdy.de/nago/presentation/core"
+)
+
+// Management serves a SCIM 2.0 endpoint at [scimhttp.Endpoint], so that identity providers like Entra ID or
+// Okta can provision users, groups and group memberships. The identity provider authenticates with an API
+// token of the token management, which must hold the permission [scim.PermProvision].
+type Management struct {
+	UseCases scim.UseCases
+}
+
+func Enable(cfg *application.Configurator) (Management, error) {
+	management, ok := core.FromContext[Management](cfg.Context(), "")
+	if ok {
+		return management, nil
+	}
+
+	api, err := cfghapi.Enable(cfg)
+	if err != nil {
+		return Management{}, err
+	}
+
+	tokens, err := cfg.TokenManagement()
+	if err != nil {
+		return Management{}, err
+	}
+
+	users, err := cfg.UserManagement()
+	if err != nil {
+		return Management{}, err
+	}
+
+	groups, err := cfg.GroupManagement()
+	if err != nil {
+		return Management{}, err
+	}
+
+	links, err := application.JSONRepository[scim.Link](cfg, "nago.scim.link")
+	if err != nil {
+		return Management{}, err
+	}
+
+	management = Management{
+		UseCases: scim.NewUseCases(links, users.UseCases, groups.UseCases),
+	}
+
+	base := strings.TrimSuffix(cfg.ContextPathURI("", nil), "/") + scimhttp.Endpoint
+	scimhttp.Register(api.API, tokens.UseCases.AuthenticateSubject, management.UseCases, base)
+
+	cfg.AddContextValue(core.ContextValue("nago.scim", management))
+
+	slog.Info("installed scim management", "endpoint", base)
+	return management, nil
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package scim
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// ErrorType is one of the scimType values of RFC 7644 section 3.12.
+type ErrorType string
+
+const (
+	InvalidFilter ErrorType = "invalidFilter"
+	TooMany       ErrorType = "tooMany"
+	Uniqueness    ErrorType = "uniqueness"
+	Mutability    ErrorType = "mutability"
+	InvalidSyntax ErrorType = "invalidSyntax"
+	InvalidPath   ErrorType = "invalidPath"
+	NoTarget      ErrorType = "noTarget"
+	InvalidValue  ErrorType = "invalidValue"
+)
+
+// Error is returned to the client as is. The detail must never contain internal details.
+type Error struct {
+	Status int
+	Type   ErrorType
+	Detail string
+}
+
+func newError(status int, typ ErrorType, detail string) error {
+	return Error{Status: status, Type: typ, Detail: detail}
+}
+
+func notFound(id string) error {
+	return Error{Status: 404, Detail: "resource " + id + " not found"}
+}
+
+func (e Error) Error() string {
+	if e.Type == "" {
+		return e.Detail
+	}
+
+	return string(e.Type) + ": " + e.Detail
+}
+
+func (e Error) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Schemas []string  `json:"schemas"`
+		Status  string    `json:"status"`
+		Type    ErrorType `json:"scimType,omitempty"`
+		Detail  string    `json:"detail,omitempty"`
+	}{
+		Schemas: []string{SchemaError},
+		Status:  strconv.Itoa(e.Status),
+		Type:    e.Type,
+		Detail:  e.Detail,
+	})
+}
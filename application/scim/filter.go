@@ -0,0 +1,451 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package scim
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode"
+)
+
+// filter is a parsed filter expression of RFC 7644 section 3.4.2.2. It is evaluated against the generic JSON
+// representation of a resource, thus it works the same for users, groups and the elements of multi-valued
+// attributes.
+type filter interface {
+	match(doc map[string]any) bool
+}
+
+type andFilter struct{ left, right filter }
+
+func (f andFilter) match(doc map[string]any) bool { return f.left.match(doc) && f.right.match(doc) }
+
+type orFilter struct{ left, right filter }
+
+func (f orFilter) match(doc map[string]any) bool { return f.left.match(doc) || f.right.match(doc) }
+
+type notFilter struct{ filter filter }
+
+func (f notFilter) match(doc map[string]any) bool { return !f.filter.match(doc) }
+
+// valuePathFilter matches, if any element of a multi-valued attribute matches, e.g. emails[type eq "work"].
+type valuePathFilter struct {
+	attr   string
+	filter filter
+}
+
+func (f valuePathFilter) match(doc map[string]any) bool {
+	for _, v := range resolve(doc, strings.Split(f.attr, ".")) {
+		if m, ok := v.(map[string]any); ok && f.filter.match(m) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type compareFilter struct {
+	attr  string
+	op    string
+	value any
+}
+
+func (f compareFilter) match(doc map[string]any) bool {
+	values := resolve(doc, strings.Split(f.attr, "."))
+	if f.op == "pr" {
+		for _, v := range values {
+			if v != nil && v != "" {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if len(values) == 0 {
+		return f.op == "ne"
+	}
+
+	for _, v := range values {
+		// a complex multi-valued attribute is compared by its value, e.g. emails eq "a@b.c"
+		if m, ok := v.(map[string]any); ok {
+			v = get(m, "value")
+		}
+
+		if compare(v, f.op, f.value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func compare(have any, op string, want any) bool {
+	switch want := want.(type) {
+	case string:
+		s, ok := have.(string)
+		if !ok {
+			return op == "ne"
+		}
+
+		// all attributes are treated as caseExact=false
+		s, want = strings.ToLower(s), strings.ToLower(want)
+		switch op {
+		case "eq":
+			return s == want
+		case "ne":
+			return s != want
+		case "co":
+			return strings.Contains(s, want)
+		case "sw":
+			return strings.HasPrefix(s, want)
+		case "ew":
+			return strings.HasSuffix(s, want)
+		case "gt":
+			return s > want
+		case "ge":
+			return s >= want
+		case "lt":
+			return s < want
+		case "le":
+			return s <= want
+		}
+	case bool:
+		b, ok := have.(bool)
+		switch op {
+		case "eq":
+			return ok && b == want
+		case "ne":
+			return !ok || b != want
+		}
+	case float64:
+		n, ok := have.(float64)
+		if !ok {
+			return op == "ne"
+		}
+
+		switch op {
+		case "eq":
+			return n == want
+		case "ne":
+			return n != want
+		case "gt":
+			return n > want
+		case "ge":
+			return n >= want
+		case "lt":
+			return n < want
+		case "le":
+			return n <= want
+		}
+	case nil:
+		return op == "ne"
+	}
+
+	return false
+}
+
+// resolve returns all values of the dotted attribute path. Multi-valued attributes are flattened.
+func resolve(v any, path []string) []any {
+	if arr, ok := v.([]any); ok {
+		var res []any
+		for _, e := range arr {
+			res = append(res, resolve(e, path)...)
+		}
+
+		return res
+	}
+
+	if len(path) == 0 {
+		if v == nil {
+			return nil
+		}
+
+		return []any{v}
+	}
+
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	return resolve(get(m, path[0]), path[1:])
+}
+
+// get looks the attribute up case-insensitive, as required by RFC 7643 section 2.1.
+func get(m map[string]any, name string) any {
+	return m[keyOf(m, name)]
+}
+
+// keyOf returns the existing key of the attribute or the name itself.
+func keyOf(m map[string]any, name string) string {
+	if _, ok := m[name]; ok {
+		return name
+	}
+
+	for k := range m {
+		if strings.EqualFold(k, name) {
+			return k
+		}
+	}
+
+	return name
+}
+
+// stripURN removes a schema prefix like urn:ietf:params:scim:schemas:core:2.0:User: from an attribute path.
+// Attributes of extension schemas are thus treated like unknown core attributes.
+func stripURN(attr string) string {
+	if !strings.HasPrefix(strings.ToLower(attr), "urn:") {
+		return attr
+	}
+
+	return attr[strings.LastIndex(attr, ":")+1:]
+}
+
+func parseFilter(s string) (filter, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &filterParser{tokens: tokens}
+	f, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos != len(p.tokens) {
+		return nil, p.errorf("unexpected " + p.tokens[p.pos].text)
+	}
+
+	return f, nil
+}
+
+type token struct {
+	text   string
+	quoted bool
+}
+
+func tokenize(s string) ([]token, error) {
+	var res []token
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.IndexByte("()[]", c) >= 0:
+			res = append(res, token{text: string(c)})
+			i++
+		case c == '"':
+			end := i + 1
+			for end < len(s) && s[end] != '"' {
+				if s[end] == '\\' {
+					end++
+				}
+
+				end++
+			}
+
+			if end >= len(s) {
+				return nil, newError(400, InvalidFilter, "unterminated string")
+			}
+
+			var str string
+			if err := json.Unmarshal([]byte(s[i:end+1]), &str); err != nil {
+				return nil, newError(400, InvalidFilter, "invalid string "+s[i:end+1])
+			}
+
+			res = append(res, token{text: str, quoted: true})
+			i = end + 1
+		default:
+			end := i
+			for end < len(s) && !unicode.IsSpace(rune(s[end])) && strings.IndexByte("()[]\"", s[end]) < 0 {
+				end++
+			}
+
+			res = append(res, token{text: s[i:end]})
+			i = end
+		}
+	}
+
+	return res, nil
+}
+
+type filterParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *filterParser) errorf(msg string) error {
+	return newError(400, InvalidFilter, msg)
+}
+
+func (p *filterParser) peek(keyword string) bool {
+	return p.pos < len(p.tokens) && !p.tokens[p.pos].quoted && strings.EqualFold(p.tokens[p.pos].text, keyword)
+}
+
+func (p *filterParser) expect(keyword string) error {
+	if !p.peek(keyword) {
+		return p.errorf("expected " + keyword)
+	}
+
+	p.pos++
+	return nil
+}
+
+func (p *filterParser) or() (filter, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek("or") {
+		p.pos++
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+
+		left = orFilter{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *filterParser) and() (filter, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek("and") {
+		p.pos++
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+
+		left = andFilter{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *filterParser) unary() (filter, error) {
+	switch {
+	case p.peek("not"):
+		p.pos++
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+
+		f, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+
+		return notFilter{f}, p.expect(")")
+	case p.peek("("):
+		p.pos++
+		f, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+
+		return f, p.expect(")")
+	}
+
+	if p.pos >= len(p.tokens) || p.tokens[p.pos].quoted {
+		return nil, p.errorf("expected attribute")
+	}
+
+	attr := stripURN(p.tokens[p.pos].text)
+	p.pos++
+
+	if p.peek("[") {
+		p.pos++
+		f, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+
+		return valuePathFilter{attr: attr, filter: f}, p.expect("]")
+	}
+
+	if p.pos >= len(p.tokens) {
+		return nil, p.errorf("expected operator")
+	}
+
+	op := strings.ToLower(p.tokens[p.pos].text)
+	p.pos++
+	switch op {
+	case "pr":
+		return compareFilter{attr: attr, op: op}, nil
+	case "eq", "ne", "co", "sw", "ew", "gt", "ge", "lt", "le":
+	default:
+		return nil, p.errorf("unsupported operator " + op)
+	}
+
+	if p.pos >= len(p.tokens) {
+		return nil, p.errorf("expected value")
+	}
+
+	tok := p.tokens[p.pos]
+	p.pos++
+	if tok.quoted {
+		return compareFilter{attr: attr, op: op, value: tok.text}, nil
+	}
+
+	// true, false, null and numbers are valid json literals
+	var value any
+	if err := json.Unmarshal([]byte(strings.ToLower(tok.text)), &value); err != nil {
+		return nil, p.errorf("invalid value " + tok.text)
+	}
+
+	return compareFilter{attr: attr, op: op, value: value}, nil
+}
+
+// path is the target of a PATCH operation, e.g. members[value eq "2819c223"] or name.givenName.
+type path struct {
+	attr   string
+	filter filter
+	sub    string
+}
+
+func parsePath(s string) (path, error) {
+	var p path
+	head, rest, hasFilter := strings.Cut(s, "[")
+	head = stripURN(strings.TrimSpace(head))
+	if hasFilter {
+		end := strings.LastIndex(rest, "]")
+		if end < 0 {
+			return path{}, newError(400, InvalidPath, "unterminated value filter in "+s)
+		}
+
+		f, err := parseFilter(rest[:end])
+		if err != nil {
+			return path{}, newError(400, InvalidPath, "invalid value filter in "+s)
+		}
+
+		p.attr, p.filter = head, f
+		if tail := rest[end+1:]; tail != "" {
+			if !strings.HasPrefix(tail, ".") {
+				return path{}, newError(400, InvalidPath, "invalid path "+s)
+			}
+
+			p.sub = tail[1:]
+		}
+	} else {
+		p.attr, p.sub, _ = strings.Cut(head, ".")
+	}
+
+	if p.attr == "" {
+		return path{}, newError(400, InvalidPath, "invalid path "+s)
+	}
+
+	return p, nil
+}
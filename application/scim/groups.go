@@ -0,0 +1,198 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package scim
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.wdy.de/nago/application/group"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/pkg/xstrings"
+)
+
+// memberIndex resolves the members of all groups. The memberships are stored per user, thus all users must
+// be visited, which is why identity providers should request excludedAttributes=members where possible.
+func memberIndex(links LinkRepository, users user.UseCases) (map[group.ID][]MultiValue, error) {
+	res := map[group.ID][]MultiValue{}
+	for usr, err := range users.FindAll(user.SU()) {
+		if err != nil {
+			return nil, fmt.Errorf("cannot find users: %w", err)
+		}
+
+		link, err := loadLink(links, UserLink(usr.ID))
+		if err != nil {
+			return nil, err
+		}
+
+		if link.Deprovisioned {
+			continue
+		}
+
+		display := xstrings.Join2(" ", usr.Contact.Firstname, usr.Contact.Lastname)
+		if display == "" {
+			display = string(usr.Email)
+		}
+
+		for gid, err := range users.ListGroups(user.SU(), usr.ID) {
+			if err != nil {
+				return nil, fmt.Errorf("cannot list groups of %s: %w", usr.ID, err)
+			}
+
+			res[gid] = append(res[gid], MultiValue{Value: string(usr.ID), Display: display, Type: "User"})
+		}
+	}
+
+	for _, members := range res {
+		slices.SortFunc(members, func(a, b MultiValue) int {
+			return strings.Compare(a.Value, b.Value)
+		})
+	}
+
+	return res, nil
+}
+
+func groupResource(grp group.Group, link Link, members []MultiValue) Group {
+	return Group{
+		Schemas:     []string{SchemaGroup},
+		ID:          string(grp.ID),
+		ExternalID:  link.ExternalID,
+		DisplayName: grp.Name,
+		Members:     members,
+		Meta:        &Meta{ResourceType: "Group"},
+	}
+}
+
+func findGroup(groups group.UseCases, id group.ID) (group.Group, error) {
+	if !visibleGroup(id) {
+		return group.Group{}, notFound(string(id))
+	}
+
+	optGrp, err := groups.FindByID(user.SU(), id)
+	if err != nil {
+		return group.Group{}, fmt.Errorf("cannot find group: %w", err)
+	}
+
+	if optGrp.IsNone() {
+		return group.Group{}, notFound(string(id))
+	}
+
+	return optGrp.Unwrap(), nil
+}
+
+func loadGroup(links LinkRepository, users user.UseCases, groups group.UseCases, id group.ID) (Group, error) {
+	grp, err := findGroup(groups, id)
+	if err != nil {
+		return Group{}, err
+	}
+
+	link, err := loadLink(links, GroupLink(string(id)))
+	if err != nil {
+		return Group{}, err
+	}
+
+	idx, err := memberIndex(links, users)
+	if err != nil {
+		return Group{}, err
+	}
+
+	return groupResource(grp, link, idx[id]), nil
+}
+
+// nameUsed returns true, if another group has the same display name.
+func nameUsed(groups group.UseCases, name string, id group.ID) (bool, error) {
+	for g, err := range groups.FindAll(user.SU()) {
+		if err != nil {
+			return false, fmt.Errorf("cannot find groups: %w", err)
+		}
+
+		if g.ID != id && strings.EqualFold(g.Name, name) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// applyMembers adds and removes members, so that the group contains exactly the given users. Unknown or
+// deprovisioned users are rejected before anything has been changed.
+func applyMembers(links LinkRepository, users user.UseCases, gid group.ID, current, desired []MultiValue) error {
+	ids := func(values []MultiValue) []user.ID {
+		var res []user.ID
+		for _, v := range values {
+			if !slices.Contains(res, user.ID(v.Value)) {
+				res = append(res, user.ID(v.Value))
+			}
+		}
+
+		return res
+	}
+
+	have, want := ids(current), ids(desired)
+	for _, uid := range want {
+		if slices.Contains(have, uid) {
+			continue
+		}
+
+		if _, _, err := findUser(links, users, uid); err != nil {
+			return newError(400, InvalidValue, "unknown member "+string(uid))
+		}
+	}
+
+	for _, uid := range want {
+		if slices.Contains(have, uid) {
+			continue
+		}
+
+		if err := users.AddUserToGroup(user.SU(), uid, gid); err != nil {
+			return fmt.Errorf("cannot add %s to group: %w", uid, err)
+		}
+	}
+
+	for _, uid := range have {
+		if slices.Contains(want, uid) {
+			continue
+		}
+
+		var remaining []group.ID
+		for other, err := range users.ListGroups(user.SU(), uid) {
+			if err != nil {
+				return fmt.Errorf("cannot list groups of %s: %w", uid, err)
+			}
+
+			if other != gid {
+				remaining = append(remaining, other)
+			}
+		}
+
+		if err := users.UpdateOtherGroups(user.SU(), uid, remaining); err != nil {
+			return fmt.Errorf("cannot remove %s from group: %w", uid, err)
+		}
+	}
+
+	return nil
+}
+
+func saveGroupLink(links LinkRepository, id group.ID, externalID string) error {
+	link, err := loadLink(links, GroupLink(string(id)))
+	if err != nil {
+		return err
+	}
+
+	if link.ExternalID == externalID {
+		return nil
+	}
+
+	link.ExternalID = externalID
+	if err := links.Save(link); err != nil {
+		return fmt.Errorf("cannot save scim link: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,358 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+// Package scimhttp serves the SCIM 2.0 endpoints through hapi. All endpoints require an API token, whose
+// subject must hold [scim.PermProvision].
+package scimhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go.wdy.de/nago/application/group"
+	"go.wdy.de/nago/application/hapi"
+	"go.wdy.de/nago/application/scim"
+	"go.wdy.de/nago/application/token"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/auth"
+)
+
+// Endpoint is the base url, which must be configured in the identity provider as tenant url.
+const Endpoint = "/api/nago/v1/scim/v2"
+
+const (
+	UsersEndpoint                 = Endpoint + "/Users"
+	GroupsEndpoint                = Endpoint + "/Groups"
+	ServiceProviderConfigEndpoint = Endpoint + "/ServiceProviderConfig"
+	ResourceTypesEndpoint         = Endpoint + "/ResourceTypes"
+)
+
+const (
+	contentType = "application/scim+json; charset=utf-8"
+	// defaultCount is the page size, if the identity provider does not ask for one.
+	defaultCount = 100
+	maxBodySize  = 1024 * 1024
+)
+
+type request struct {
+	subject auth.Subject
+	id      string
+	query   scim.Query
+	body    []byte
+}
+
+type handler struct {
+	uc   scim.UseCases
+	base string
+}
+
+// Register adds all endpoints to the api. The base is the absolute url of [Endpoint] and is used to render the
+// resource locations.
+func Register(api *hapi.API, authenticate token.AuthenticateSubject, uc scim.UseCases, base string) {
+	h := handler{uc: uc, base: base}
+	endpoint := func(b *hapi.EndpointBuilder[request], fn func(in request) (int, any, error)) {
+		b.Request(
+			hapi.BearerAuth[request](authenticate, func(dst *request, subject auth.Subject) error {
+				dst.subject = subject
+				return nil
+			}),
+			hapi.RawRequest(parseRequest),
+		).Response(hapi.RawResponse(func(in request, w http.ResponseWriter, r *http.Request) {
+			status, res, err := fn(in)
+			if err != nil {
+				writeError(w, in.subject, err)
+				return
+			}
+
+			write(w, status, res)
+		}))
+	}
+
+	endpoint(hapi.Get[request](api, hapi.Operation{Path: ServiceProviderConfigEndpoint, Summary: "SCIM service provider configuration"}), h.serviceProviderConfig)
+	endpoint(hapi.Get[request](api, hapi.Operation{Path: ResourceTypesEndpoint, Summary: "SCIM resource types"}), h.resourceTypes)
+
+	endpoint(hapi.Get[request](api, hapi.Operation{Path: UsersEndpoint, Summary: "List or filter SCIM users"}), h.findUsers)
+	endpoint(hapi.Post[request](api, hapi.Operation{Path: UsersEndpoint, Summary: "Provision a SCIM user"}), h.createUser)
+	endpoint(hapi.Get[request](api, hapi.Operation{Path: UsersEndpoint + "/{id}", Summary: "Get a SCIM user"}), h.findUser)
+	endpoint(hapi.Put[request](api, hapi.Operation{Path: UsersEndpoint + "/{id}", Summary: "Replace a SCIM user"}), h.replaceUser)
+	endpoint(hapi.Patch[request](api, hapi.Operation{Path: UsersEndpoint + "/{id}", Summary: "Patch a SCIM user"}), h.patchUser)
+	endpoint(hapi.Delete[request](api, hapi.Operation{Path: UsersEndpoint + "/{id}", Summary: "Deprovision a SCIM user", Description: "The user account is disabled and not deleted."}), h.deleteUser)
+
+	endpoint(hapi.Get[request](api, hapi.Operation{Path: GroupsEndpoint, Summary: "List or filter SCIM groups"}), h.findGroups)
+	endpoint(hapi.Post[request](api, hapi.Operation{Path: GroupsEndpoint, Summary: "Provision a SCIM group"}), h.createGroup)
+	endpoint(hapi.Get[request](api, hapi.Operation{Path: GroupsEndpoint + "/{id}", Summary: "Get a SCIM group"}), h.findGroup)
+	endpoint(hapi.Put[request](api, hapi.Operation{Path: GroupsEndpoint + "/{id}", Summary: "Replace a SCIM group"}), h.replaceGroup)
+	endpoint(hapi.Patch[request](api, hapi.Operation{Path: GroupsEndpoint + "/{id}", Summary: "Patch a SCIM group"}), h.patchGroup)
+	endpoint(hapi.Delete[request](api, hapi.Operation{Path: GroupsEndpoint + "/{id}", Summary: "Delete a SCIM group"}), h.deleteGroup)
+}
+
+func parseRequest(dst *request, r *http.Request) error {
+	dst.id = r.PathValue("id")
+
+	q := r.URL.Query()
+	dst.query = scim.Query{
+		Filter:     q.Get("filter"),
+		StartIndex: 1,
+		Count:      defaultCount,
+	}
+
+	if v := q.Get("startIndex"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid startIndex: %w", err)
+		}
+
+		dst.query.StartIndex = n
+	}
+
+	if v := q.Get("count"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid count: %w", err)
+		}
+
+		dst.query.Count = n
+	}
+
+	for _, attr := range strings.Split(q.Get("excludedAttributes"), ",") {
+		if strings.EqualFold(strings.TrimSpace(attr), "members") {
+			dst.query.ExcludeMembers = true
+		}
+	}
+
+	switch r.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+		buf, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+		if err != nil {
+			return fmt.Errorf("cannot read body: %w", err)
+		}
+
+		dst.body = buf
+	}
+
+	return nil
+}
+
+func decode[T any](in request) (T, error) {
+	var v T
+	if err := json.Unmarshal(in.body, &v); err != nil {
+		var scimErr scim.Error
+		if errors.As(err, &scimErr) {
+			return v, scimErr
+		}
+
+		return v, scim.Error{Status: http.StatusBadRequest, Type: scim.InvalidSyntax, Detail: "invalid json"}
+	}
+
+	return v, nil
+}
+
+func (h handler) userLocation(res scim.User) scim.User {
+	if res.Meta != nil {
+		res.Meta.Location = h.base + "/Users/" + res.ID
+	}
+
+	return res
+}
+
+func (h handler) groupLocation(res scim.Group) scim.Group {
+	if res.Meta != nil {
+		res.Meta.Location = h.base + "/Groups/" + res.ID
+	}
+
+	return res
+}
+
+func (h handler) findUsers(in request) (int, any, error) {
+	res, err := h.uc.FindUsers(in.subject, in.query)
+	for i, u := range res.Resources {
+		res.Resources[i] = h.userLocation(u)
+	}
+
+	return http.StatusOK, res, err
+}
+
+func (h handler) findUser(in request) (int, any, error) {
+	res, err := h.uc.FindUser(in.subject, user.ID(in.id))
+	return http.StatusOK, h.userLocation(res), err
+}
+
+func (h handler) createUser(in request) (int, any, error) {
+	model, err := decode[scim.User](in)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	res, err := h.uc.CreateUser(in.subject, model)
+	return http.StatusCreated, h.userLocation(res), err
+}
+
+func (h handler) replaceUser(in request) (int, any, error) {
+	model, err := decode[scim.User](in)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	res, err := h.uc.ReplaceUser(in.subject, user.ID(in.id), model)
+	return http.StatusOK, h.userLocation(res), err
+}
+
+func (h handler) patchUser(in request) (int, any, error) {
+	model, err := decode[scim.PatchRequest](in)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	res, err := h.uc.PatchUser(in.subject, user.ID(in.id), model)
+	return http.StatusOK, h.userLocation(res), err
+}
+
+func (h handler) deleteUser(in request) (int, any, error) {
+	return http.StatusNoContent, nil, h.uc.DeleteUser(in.subject, user.ID(in.id))
+}
+
+func (h handler) findGroups(in request) (int, any, error) {
+	res, err := h.uc.FindGroups(in.subject, in.query)
+	for i, g := range res.Resources {
+		res.Resources[i] = h.groupLocation(g)
+	}
+
+	return http.StatusOK, res, err
+}
+
+func (h handler) findGroup(in request) (int, any, error) {
+	res, err := h.uc.FindGroup(in.subject, group.ID(in.id))
+	return http.StatusOK, h.groupLocation(res), err
+}
+
+func (h handler) createGroup(in request) (int, any, error) {
+	model, err := decode[scim.Group](in)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	res, err := h.uc.CreateGroup(in.subject, model)
+	return http.StatusCreated, h.groupLocation(res), err
+}
+
+func (h handler) replaceGroup(in request) (int, any, error) {
+	model, err := decode[scim.Group](in)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	res, err := h.uc.ReplaceGroup(in.subject, group.ID(in.id), model)
+	return http.StatusOK, h.groupLocation(res), err
+}
+
+func (h handler) patchGroup(in request) (int, any, error) {
+	model, err := decode[scim.PatchRequest](in)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	res, err := h.uc.PatchGroup(in.subject, group.ID(in.id), model)
+	return http.StatusOK, h.groupLocation(res), err
+}
+
+func (h handler) deleteGroup(in request) (int, any, error) {
+	return http.StatusNoContent, nil, h.uc.DeleteGroup(in.subject, group.ID(in.id))
+}
+
+func (h handler) serviceProviderConfig(in request) (int, any, error) {
+	if err := in.subject.Audit(scim.PermProvision); err != nil {
+		return 0, nil, err
+	}
+
+	type supported struct {
+		Supported bool `json:"supported"`
+	}
+
+	return http.StatusOK, map[string]any{
+		"schemas":        []string{scim.SchemaServiceProviderConfig},
+		"patch":          supported{true},
+		"bulk":           map[string]any{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         map[string]any{"supported": true, "maxResults": 1000},
+		"changePassword": supported{false},
+		"sort":           supported{false},
+		"etag":           supported{false},
+		"authenticationSchemes": []map[string]any{{
+			"type":        "oauthbearertoken",
+			"name":        "API Token",
+			"description": "A Nago API token, whose subject holds the permission " + string(scim.PermProvision) + ".",
+			"primary":     true,
+		}},
+		"meta": map[string]any{"resourceType": "ServiceProviderConfig", "location": h.base + "/ServiceProviderConfig"},
+	}, nil
+}
+
+func (h handler) resourceTypes(in request) (int, any, error) {
+	if err := in.subject.Audit(scim.PermProvision); err != nil {
+		return 0, nil, err
+	}
+
+	resourceType := func(name, endpoint, schema string) map[string]any {
+		return map[string]any{
+			"schemas":  []string{scim.SchemaResourceType},
+			"id":       name,
+			"name":     name,
+			"endpoint": endpoint,
+			"schema":   schema,
+			"meta":     map[string]any{"resourceType": "ResourceType", "location": h.base + "/ResourceTypes/" + name},
+		}
+	}
+
+	types := []map[string]any{
+		resourceType("User", "/Users", scim.SchemaUser),
+		resourceType("Group", "/Groups", scim.SchemaGroup),
+	}
+
+	return http.StatusOK, scim.ListResponse[map[string]any]{
+		Schemas:      []string{scim.SchemaListResponse},
+		TotalResults: len(types),
+		StartIndex:   1,
+		ItemsPerPage: len(types),
+		Resources:    types,
+	}, nil
+}
+
+func write(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	if v == nil {
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("failed to write scim response", "err", err.Error())
+	}
+}
+
+func writeError(w http.ResponseWriter, subject auth.Subject, err error) {
+	var scimErr scim.Error
+	var permErr interface{ PermissionDenied() bool }
+	switch {
+	case errors.As(err, &scimErr):
+	case errors.As(err, &permErr) && permErr.PermissionDenied():
+		scimErr = scim.Error{Status: http.StatusForbidden, Detail: "permission denied"}
+		if subject == nil || !subject.Valid() {
+			scimErr.Status = http.StatusUnauthorized
+			scimErr.Detail = "a valid api token is required"
+		}
+	default:
+		slog.Error("failed to handle scim request", "err", err.Error())
+		scimErr = scim.Error{Status: http.StatusInternalServerError, Detail: "internal server error"}
+	}
+
+	write(w, scimErr.Status, scimErr)
+}
@@ -0,0 +1,197 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package scim
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.wdy.de/nago/application/user"
+)
+
+const (
+	SchemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SchemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SchemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SchemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SchemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+	SchemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	SchemaResourceType          = "urn:ietf:params:scim:schemas:core:2.0:ResourceType"
+)
+
+// Meta is the common read-only meta data of all resources. The location is filled in by the http layer.
+type Meta struct {
+	ResourceType string     `json:"resourceType"`
+	Created      *time.Time `json:"created,omitempty"`
+	Location     string     `json:"location,omitempty"`
+}
+
+type Name struct {
+	Formatted       string `json:"formatted,omitempty"`
+	FamilyName      string `json:"familyName,omitempty"`
+	GivenName       string `json:"givenName,omitempty"`
+	HonorificPrefix string `json:"honorificPrefix,omitempty"`
+}
+
+// MultiValue is an element of a multi-valued attribute like emails or members.
+type MultiValue struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Primary Bool   `json:"primary,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+// Bool also accepts the strings True and False, which are sent by Entra ID within PATCH operations.
+type Bool bool
+
+func (b *Bool) UnmarshalJSON(buf []byte) error {
+	var v any
+	if err := json.Unmarshal(buf, &v); err != nil {
+		return err
+	}
+
+	switch t := v.(type) {
+	case bool:
+		*b = Bool(t)
+	case string:
+		tmp, err := strconv.ParseBool(strings.ToLower(t))
+		if err != nil {
+			return newError(400, InvalidValue, "not a boolean: "+t)
+		}
+
+		*b = Bool(tmp)
+	case nil:
+		*b = false
+	default:
+		return newError(400, InvalidValue, "not a boolean: "+string(buf))
+	}
+
+	return nil
+}
+
+type User struct {
+	Schemas           []string     `json:"schemas"`
+	ID                string       `json:"id,omitempty"`
+	ExternalID        string       `json:"externalId,omitempty"`
+	UserName          string       `json:"userName"`
+	Name              Name         `json:"name,omitzero"`
+	DisplayName       string       `json:"displayName,omitempty"`
+	Title             string       `json:"title,omitempty"`
+	PreferredLanguage string       `json:"preferredLanguage,omitempty"`
+	Active            *Bool        `json:"active,omitempty"`
+	Emails            []MultiValue `json:"emails,omitempty"`
+	PhoneNumbers      []MultiValue `json:"phoneNumbers,omitempty"`
+	// Groups is read-only, memberships are changed through the group resource.
+	Groups []MultiValue `json:"groups,omitempty"`
+	Meta   *Meta        `json:"meta,omitempty"`
+}
+
+// Email returns the primary mail address. The user name is used, if no address has been given, because most
+// identity providers map the user principal name to it.
+func (u User) Email() user.Email {
+	var res user.Email
+	for _, e := range u.Emails {
+		if e.Value == "" {
+			continue
+		}
+
+		if e.Primary || res == "" {
+			res = user.Email(e.Value)
+		}
+	}
+
+	if res == "" {
+		res = user.Email(u.UserName)
+	}
+
+	return user.NormalizeEmail(res)
+}
+
+// IsActive returns true, unless the user has been deactivated explicitly.
+func (u User) IsActive() bool {
+	return u.Active == nil || bool(*u.Active)
+}
+
+// phone returns the first phone number of the given type or any number if the type is empty.
+func (u User) phone(typ string) string {
+	for _, p := range u.PhoneNumbers {
+		if typ == "" || strings.EqualFold(p.Type, typ) {
+			return p.Value
+		}
+	}
+
+	return ""
+}
+
+type Group struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id,omitempty"`
+	ExternalID  string       `json:"externalId,omitempty"`
+	DisplayName string       `json:"displayName"`
+	Members     []MultiValue `json:"members,omitempty"`
+	Meta        *Meta        `json:"meta,omitempty"`
+}
+
+// Query contains the list parameters of RFC 7644 section 3.4.2. The start index is 1-based.
+type Query struct {
+	Filter     string
+	StartIndex int
+	Count      int
+	// ExcludeMembers avoids the expensive resolution of group members, as requested by
+	// excludedAttributes=members.
+	ExcludeMembers bool
+}
+
+type ListResponse[T any] struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    []T      `json:"Resources"`
+}
+
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+// Link keeps the provisioning attributes, which have no counterpart in the user or group entity. Its id is the
+// resource type followed by the entity id, see [UserLink] and [GroupLink].
+type Link struct {
+	ID         string `json:"id"`
+	ExternalID string `json:"externalId,omitempty"`
+	UserName   string `json:"userName,omitempty"`
+	// Deprovisioned users are disabled and hidden from the identity provider, until they are provisioned again.
+	Deprovisioned bool `json:"deprovisioned,omitempty"`
+}
+
+func (l Link) Identity() string {
+	return l.ID
+}
+
+func (l Link) WithIdentity(id string) Link {
+	l.ID = id
+	return l
+}
+
+func UserLink(id user.ID) string {
+	return "User/" + string(id)
+}
+
+func GroupLink(id string) string {
+	return "Group/" + id
+}
@@ -0,0 +1,255 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// patch applies the operations of RFC 7644 section 3.5.2 to the resource. The resource is modified as generic
+// JSON document and decoded again afterward, so that unknown attributes, e.g. of unsupported extension schemas,
+// are silently dropped instead of failing the whole provisioning cycle.
+func patch[T any](resource T, req PatchRequest) (T, error) {
+	var zero T
+	buf, err := json.Marshal(resource)
+	if err != nil {
+		return zero, fmt.Errorf("cannot encode resource: %w", err)
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(buf, &doc); err != nil {
+		return zero, fmt.Errorf("cannot decode resource: %w", err)
+	}
+
+	for _, op := range req.Operations {
+		var value any
+		if len(op.Value) > 0 {
+			if err := json.Unmarshal(op.Value, &value); err != nil {
+				return zero, newError(400, InvalidSyntax, "invalid value")
+			}
+		}
+
+		switch strings.ToLower(op.Op) {
+		case "add":
+			err = patchSet(doc, op.Path, value, false)
+		case "replace":
+			err = patchSet(doc, op.Path, value, true)
+		case "remove":
+			err = patchRemove(doc, op.Path, value)
+		default:
+			err = newError(400, InvalidSyntax, "unsupported operation "+op.Op)
+		}
+
+		if err != nil {
+			return zero, err
+		}
+	}
+
+	buf, err = json.Marshal(doc)
+	if err != nil {
+		return zero, fmt.Errorf("cannot encode patched resource: %w", err)
+	}
+
+	var res T
+	if err := json.Unmarshal(buf, &res); err != nil {
+		return zero, newError(400, InvalidValue, "patched resource is invalid")
+	}
+
+	return res, nil
+}
+
+func patchSet(doc map[string]any, pathStr string, value any, replace bool) error {
+	if pathStr == "" {
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return newError(400, InvalidValue, "value must be an object without a path")
+		}
+
+		// keys may be paths themselves, e.g. name.givenName as sent by Entra ID
+		for k, v := range obj {
+			if err := patchSet(doc, k, v, replace); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	p, err := parsePath(pathStr)
+	if err != nil {
+		return err
+	}
+
+	key := keyOf(doc, p.attr)
+	switch {
+	case p.filter == nil && p.sub == "":
+		doc[key] = merge(doc[key], value, replace)
+	case p.filter == nil:
+		obj, ok := doc[key].(map[string]any)
+		if !ok {
+			obj = map[string]any{}
+			doc[key] = obj
+		}
+
+		obj[keyOf(obj, p.sub)] = value
+	default:
+		arr, _ := doc[key].([]any)
+		matched := false
+		for _, e := range arr {
+			elem, ok := e.(map[string]any)
+			if !ok || !p.filter.match(elem) {
+				continue
+			}
+
+			matched = true
+			setElem(elem, p.sub, value)
+		}
+
+		if !matched {
+			// e.g. replace emails[type eq "work"].value on a user without any work address
+			cmp, ok := p.filter.(compareFilter)
+			if !ok || cmp.op != "eq" || strings.Contains(cmp.attr, ".") {
+				return newError(400, NoTarget, "no element matches "+pathStr)
+			}
+
+			elem := map[string]any{cmp.attr: cmp.value}
+			setElem(elem, p.sub, value)
+			arr = append(arr, elem)
+		}
+
+		doc[key] = arr
+	}
+
+	return nil
+}
+
+func setElem(elem map[string]any, sub string, value any) {
+	if sub != "" {
+		elem[keyOf(elem, sub)] = value
+		return
+	}
+
+	if obj, ok := value.(map[string]any); ok {
+		for k, v := range obj {
+			elem[keyOf(elem, k)] = v
+		}
+	}
+}
+
+// merge adds elements to multi-valued attributes and sub-attributes to complex attributes. Replacing a
+// multi-valued attribute replaces all its elements.
+func merge(have, value any, replace bool) any {
+	switch have := have.(type) {
+	case []any:
+		if replace {
+			return value
+		}
+
+		values, ok := value.([]any)
+		if !ok {
+			values = []any{value}
+		}
+
+		for _, v := range values {
+			if !containsValue(have, v) {
+				have = append(have, v)
+			}
+		}
+
+		return have
+	case map[string]any:
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return value
+		}
+
+		for k, v := range obj {
+			have[keyOf(have, k)] = v
+		}
+
+		return have
+	}
+
+	return value
+}
+
+func containsValue(arr []any, v any) bool {
+	want := elemValue(v)
+	for _, e := range arr {
+		if elemValue(e) == want {
+			return true
+		}
+	}
+
+	return false
+}
+
+func elemValue(v any) any {
+	if m, ok := v.(map[string]any); ok {
+		return get(m, "value")
+	}
+
+	return v
+}
+
+func patchRemove(doc map[string]any, pathStr string, value any) error {
+	if pathStr == "" {
+		return newError(400, NoTarget, "remove requires a path")
+	}
+
+	p, err := parsePath(pathStr)
+	if err != nil {
+		return err
+	}
+
+	key := keyOf(doc, p.attr)
+	switch {
+	case p.filter == nil && p.sub == "":
+		arr, isArr := doc[key].([]any)
+		values, hasValues := value.([]any)
+		if !isArr || !hasValues {
+			delete(doc, key)
+			return nil
+		}
+
+		// Entra ID removes members by value instead of a value filter
+		var res []any
+		for _, e := range arr {
+			if !containsValue(values, e) {
+				res = append(res, e)
+			}
+		}
+
+		doc[key] = res
+	case p.filter == nil:
+		if obj, ok := doc[key].(map[string]any); ok {
+			delete(obj, keyOf(obj, p.sub))
+		}
+	default:
+		arr, _ := doc[key].([]any)
+		var res []any
+		for _, e := range arr {
+			elem, ok := e.(map[string]any)
+			if !ok || !p.filter.match(elem) {
+				res = append(res, e)
+				continue
+			}
+
+			if p.sub != "" {
+				delete(elem, keyOf(elem, p.sub))
+				res = append(res, elem)
+			}
+		}
+
+		doc[key] = res
+	}
+
+	return nil
+}
@@ -0,0 +1,14 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package scim
+
+import "go.wdy.de/nago/application/permission"
+
+var (
+	PermProvision = permission.Declare[CreateUser]("nago.scim.provision", "SCIM Provisionierung", "Träger dieser Berechtigung können über die SCIM Schnittstelle Benutzer und Gruppen lesen, anlegen, ändern und deprovisionieren. Die Berechtigung ist für API Tokens von Identity Providern wie Entra ID oder Okta gedacht.")
+)
@@ -0,0 +1,58 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package scim
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"go.wdy.de/nago/application/group"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/auth"
+)
+
+func NewCreateGroup(mutex *sync.Mutex, links LinkRepository, users user.UseCases, groups group.UseCases) CreateGroup {
+	return func(subject auth.Subject, res Group) (Group, error) {
+		if err := subject.Audit(PermProvision); err != nil {
+			return Group{}, err
+		}
+
+		if strings.TrimSpace(res.DisplayName) == "" {
+			return Group{}, newError(400, InvalidValue, "displayName is required")
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		used, err := nameUsed(groups, res.DisplayName, "")
+		if err != nil {
+			return Group{}, err
+		}
+
+		if used {
+			return Group{}, newError(409, Uniqueness, "a group with this displayName already exists")
+		}
+
+		// the id is generated by the upsert
+		gid, err := groups.Upsert(user.SU(), group.Group{Name: res.DisplayName})
+		if err != nil {
+			return Group{}, fmt.Errorf("cannot create group: %w", err)
+		}
+
+		if err := saveGroupLink(links, gid, res.ExternalID); err != nil {
+			return Group{}, err
+		}
+
+		if err := applyMembers(links, users, gid, nil, res.Members); err != nil {
+			return Group{}, err
+		}
+
+		return loadGroup(links, users, groups, gid)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package scim
+
+import (
+	"fmt"
+	"sync"
+
+	"go.wdy.de/nago/application/group"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/auth"
+)
+
+func NewCreateUser(mutex *sync.Mutex, links LinkRepository, users user.UseCases, groups group.UseCases) CreateUser {
+	return func(subject auth.Subject, res User) (User, error) {
+		if err := subject.Audit(PermProvision); err != nil {
+			return User{}, err
+		}
+
+		if err := validateUser(res); err != nil {
+			return User{}, err
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		optUsr, err := users.FindByMail(user.SU(), res.Email())
+		if err != nil {
+			return User{}, fmt.Errorf("cannot find user by mail: %w", err)
+		}
+
+		var usr user.User
+		var link Link
+		if optUsr.IsSome() {
+			usr = optUsr.Unwrap()
+			link, err = loadLink(links, UserLink(usr.ID))
+			if err != nil {
+				return User{}, err
+			}
+
+			// only a deprovisioned user is taken over again, all others must be matched by the identity
+			// provider through a filter on userName or emails
+			if !link.Deprovisioned {
+				return User{}, newError(409, Uniqueness, "a user with this email address already exists")
+			}
+		} else {
+			// without a password, a random one is set and must be changed, which is irrelevant for single sign on
+			usr, err = users.Create(user.SU(), user.ShortRegistrationUser{
+				Firstname:   res.Name.GivenName,
+				Lastname:    res.Name.FamilyName,
+				Email:       res.Email(),
+				Verified:    true,
+				Title:       res.Name.HonorificPrefix,
+				Position:    res.Title,
+				MobilePhone: res.phone("mobile"),
+			})
+			if err != nil {
+				return User{}, fmt.Errorf("cannot create user: %w", err)
+			}
+
+			link = Link{ID: UserLink(usr.ID)}
+		}
+
+		if err := applyUser(links, users, usr, link, res); err != nil {
+			return User{}, err
+		}
+
+		return loadUser(links, users, groups, usr.ID)
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package scim
+
+import (
+	"fmt"
+	"sync"
+
+	"go.wdy.de/nago/application/group"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/auth"
+)
+
+func NewDeleteGroup(mutex *sync.Mutex, links LinkRepository, groups group.UseCases) DeleteGroup {
+	return func(subject auth.Subject, id group.ID) error {
+		if err := subject.Audit(PermProvision); err != nil {
+			return err
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if _, err := findGroup(groups, id); err != nil {
+			return err
+		}
+
+		if err := groups.Delete(user.SU(), id); err != nil {
+			return fmt.Errorf("cannot delete group: %w", err)
+		}
+
+		if err := links.DeleteByID(GroupLink(string(id))); err != nil {
+			return fmt.Errorf("cannot delete scim link: %w", err)
+		}
+
+		return nil
+	}
+}
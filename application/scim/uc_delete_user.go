@@ -0,0 +1,43 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package scim
+
+import (
+	"fmt"
+	"sync"
+
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/auth"
+)
+
+func NewDeleteUser(mutex *sync.Mutex, links LinkRepository, users user.UseCases) DeleteUser {
+	return func(subject auth.Subject, id user.ID) error {
+		if err := subject.Audit(PermProvision); err != nil {
+			return err
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		_, link, err := findUser(links, users, id)
+		if err != nil {
+			return err
+		}
+
+		if err := users.UpdateAccountStatus(user.SU(), id, user.Disabled{}); err != nil {
+			return fmt.Errorf("cannot disable user: %w", err)
+		}
+
+		link.Deprovisioned = true
+		if err := links.Save(link); err != nil {
+			return fmt.Errorf("cannot save scim link: %w", err)
+		}
+
+		return nil
+	}
+}
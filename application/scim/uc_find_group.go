@@ -0,0 +1,24 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package scim
+
+import (
+	"go.wdy.de/nago/application/group"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/auth"
+)
+
+func NewFindGroup(links LinkRepository, users user.UseCases, groups group.UseCases) FindGroup {
+	return func(subject auth.Subject, id group.ID) (Group, error) {
+		if err := subject.Audit(PermProvision); err != nil {
+			return Group{}, err
+		}
+
+		return loadGroup(links, users, groups, id)
+	}
+}
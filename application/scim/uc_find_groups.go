@@ -0,0 +1,65 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package scim
+
+import (
+	"fmt"
+
+	"go.wdy.de/nago/application/group"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/auth"
+)
+
+func NewFindGroups(links LinkRepository, users user.UseCases, groups group.UseCases) FindGroups {
+	return func(subject auth.Subject, query Query) (ListResponse[Group], error) {
+		if err := subject.Audit(PermProvision); err != nil {
+			return ListResponse[Group]{}, err
+		}
+
+		f, err := queryFilter(query)
+		if err != nil {
+			return ListResponse[Group]{}, err
+		}
+
+		var idx map[group.ID][]MultiValue
+		if !query.ExcludeMembers {
+			idx, err = memberIndex(links, users)
+			if err != nil {
+				return ListResponse[Group]{}, err
+			}
+		}
+
+		var res []Group
+		for grp, err := range groups.FindAll(user.SU()) {
+			if err != nil {
+				return ListResponse[Group]{}, fmt.Errorf("cannot find groups: %w", err)
+			}
+
+			if !visibleGroup(grp.ID) {
+				continue
+			}
+
+			link, err := loadLink(links, GroupLink(string(grp.ID)))
+			if err != nil {
+				return ListResponse[Group]{}, err
+			}
+
+			resource := groupResource(grp, link, idx[grp.ID])
+			ok, err := matches(f, resource)
+			if err != nil {
+				return ListResponse[Group]{}, err
+			}
+
+			if ok {
+				res = append(res, resource)
+			}
+		}
+
+		return page(res, query, func(g Group) string { return g.ID }), nil
+	}
+}
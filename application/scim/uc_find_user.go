@@ -0,0 +1,24 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package scim
+
+import (
+	"go.wdy.de/nago/application/group"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/auth"
+)
+
+func NewFindUser(links LinkRepository, users user.UseCases, groups group.UseCases) FindUser {
+	return func(subject auth.Subject, id user.ID) (User, error) {
+		if err := subject.Audit(PermProvision); err != nil {
+			return User{}, err
+		}
+
+		return loadUser(links, users, groups, id)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.wdy.de/nago/application/group"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/auth"
+)
+
+// maxResults limits the page size, RFC 7644 allows the server to return less than requested.
+const maxResults = 1000
+
+func NewFindUsers(links LinkRepository, users user.UseCases, groups group.UseCases) FindUsers {
+	return func(subject auth.Subject, query Query) (ListResponse[User], error) {
+		if err := subject.Audit(PermProvision); err != nil {
+			return ListResponse[User]{}, err
+		}
+
+		f, err := queryFilter(query)
+		if err != nil {
+			return ListResponse[User]{}, err
+		}
+
+		allLinks := map[string]Link{}
+		for link, err := range links.All() {
+			if err != nil {
+				return ListResponse[User]{}, fmt.Errorf("cannot find scim links: %w", err)
+			}
+
+			allLinks[link.ID] = link
+		}
+
+		names, err := groupNames(groups)
+		if err != nil {
+			return ListResponse[User]{}, err
+		}
+
+		var res []User
+		for usr, err := range users.FindAll(user.SU()) {
+			if err != nil {
+				return ListResponse[User]{}, fmt.Errorf("cannot find users: %w", err)
+			}
+
+			link, ok := allLinks[UserLink(usr.ID)]
+			if !ok {
+				link = Link{ID: UserLink(usr.ID)}
+			}
+
+			if link.Deprovisioned {
+				continue
+			}
+
+			resource, err := userResource(users, names, usr, link)
+			if err != nil {
+				return ListResponse[User]{}, err
+			}
+
+			ok, err = matches(f, resource)
+			if err != nil {
+				return ListResponse[User]{}, err
+			}
+
+			if ok {
+				res = append(res, resource)
+			}
+		}
+
+		return page(res, query, func(u User) string { return u.ID }), nil
+	}
+}
+
+func queryFilter(query Query) (filter, error) {
+	if strings.TrimSpace(query.Filter) == "" {
+		return nil, nil
+	}
+
+	return parseFilter(query.Filter)
+}
+
+// matches evaluates the filter against the JSON representation, which is what the identity provider sees.
+func matches(f filter, resource any) (bool, error) {
+	if f == nil {
+		return true, nil
+	}
+
+	buf, err := json.Marshal(resource)
+	if err != nil {
+		return false, fmt.Errorf("cannot encode resource: %w", err)
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(buf, &doc); err != nil {
+		return false, fmt.Errorf("cannot decode resource: %w", err)
+	}
+
+	return f.match(doc), nil
+}
+
+// page sorts by id, so that the 1-based start index is stable between requests.
+func page[T any](all []T, query Query, id func(T) string) ListResponse[T] {
+	slices.SortFunc(all, func(a, b T) int {
+		return strings.Compare(id(a), id(b))
+	})
+
+	start := max(query.StartIndex, 1)
+	count := min(max(query.Count, 0), maxResults)
+	from := min(start-1, len(all))
+	to := min(from+count, len(all))
+
+	resources := all[from:to]
+	if resources == nil {
+		resources = []T{}
+	}
+
+	return ListResponse[T]{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: len(all),
+		StartIndex:   start,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	}
+}
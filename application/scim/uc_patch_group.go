@@ -0,0 +1,34 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package scim
+
+import (
+	"go.wdy.de/nago/application/group"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/auth"
+)
+
+func NewPatchGroup(links LinkRepository, users user.UseCases, groups group.UseCases, replaceGroup ReplaceGroup) PatchGroup {
+	return func(subject auth.Subject, id group.ID, req PatchRequest) (Group, error) {
+		if err := subject.Audit(PermProvision); err != nil {
+			return Group{}, err
+		}
+
+		current, err := loadGroup(links, users, groups, id)
+		if err != nil {
+			return Group{}, err
+		}
+
+		patched, err := patch(current, req)
+		if err != nil {
+			return Group{}, err
+		}
+
+		return replaceGroup(subject, id, patched)
+	}
+}
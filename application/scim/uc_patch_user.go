@@ -0,0 +1,34 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package scim
+
+import (
+	"go.wdy.de/nago/application/group"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/auth"
+)
+
+func NewPatchUser(links LinkRepository, users user.UseCases, groups group.UseCases, replaceUser ReplaceUser) PatchUser {
+	return func(subject auth.Subject, id user.ID, req PatchRequest) (User, error) {
+		if err := subject.Audit(PermProvision); err != nil {
+			return User{}, err
+		}
+
+		current, err := loadUser(links, users, groups, id)
+		if err != nil {
+			return User{}, err
+		}
+
+		patched, err := patch(current, req)
+		if err != nil {
+			return User{}, err
+		}
+
+		return replaceUser(subject, id, patched)
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package scim
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"go.wdy.de/nago/application/group"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/auth"
+)
+
+func NewReplaceGroup(mutex *sync.Mutex, links LinkRepository, users user.UseCases, groups group.UseCases) ReplaceGroup {
+	return func(subject auth.Subject, id group.ID, res Group) (Group, error) {
+		if err := subject.Audit(PermProvision); err != nil {
+			return Group{}, err
+		}
+
+		if strings.TrimSpace(res.DisplayName) == "" {
+			return Group{}, newError(400, InvalidValue, "displayName is required")
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		current, err := loadGroup(links, users, groups, id)
+		if err != nil {
+			return Group{}, err
+		}
+
+		if current.DisplayName != res.DisplayName {
+			used, err := nameUsed(groups, res.DisplayName, id)
+			if err != nil {
+				return Group{}, err
+			}
+
+			if used {
+				return Group{}, newError(409, Uniqueness, "a group with this displayName already exists")
+			}
+
+			grp, err := findGroup(groups, id)
+			if err != nil {
+				return Group{}, err
+			}
+
+			grp.Name = res.DisplayName
+			if _, err := groups.Upsert(user.SU(), grp); err != nil {
+				return Group{}, fmt.Errorf("cannot update group: %w", err)
+			}
+		}
+
+		if err := saveGroupLink(links, id, res.ExternalID); err != nil {
+			return Group{}, err
+		}
+
+		if err := applyMembers(links, users, id, current.Members, res.Members); err != nil {
+			return Group{}, err
+		}
+
+		return loadGroup(links, users, groups, id)
+	}
+}
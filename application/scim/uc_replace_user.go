@@ -0,0 +1,42 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package scim
+
+import (
+	"sync"
+
+	"go.wdy.de/nago/application/group"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/auth"
+)
+
+func NewReplaceUser(mutex *sync.Mutex, links LinkRepository, users user.UseCases, groups group.UseCases) ReplaceUser {
+	return func(subject auth.Subject, id user.ID, res User) (User, error) {
+		if err := subject.Audit(PermProvision); err != nil {
+			return User{}, err
+		}
+
+		if err := validateUser(res); err != nil {
+			return User{}, err
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		usr, link, err := findUser(links, users, id)
+		if err != nil {
+			return User{}, err
+		}
+
+		if err := applyUser(links, users, usr, link, res); err != nil {
+			return User{}, err
+		}
+
+		return loadUser(links, users, groups, id)
+	}
+}
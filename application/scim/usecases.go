@@ -0,0 +1,80 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+// Package scim implements the user and group resources of SCIM 2.0 (RFC 7643 and RFC 7644), so that identity
+// providers like Entra ID or Okta can provision the Nago IAM. The resources are mapped onto the regular user
+// and group use cases. Deprovisioned users are disabled instead of deleted, because they may own data or
+// audit records.
+package scim
+
+import (
+	"sync"
+
+	"go.wdy.de/nago/application/group"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/auth"
+	"go.wdy.de/nago/pkg/data"
+)
+
+type LinkRepository data.Repository[Link, string]
+
+type FindUsers func(subject auth.Subject, query Query) (ListResponse[User], error)
+type FindUser func(subject auth.Subject, id user.ID) (User, error)
+type CreateUser func(subject auth.Subject, usr User) (User, error)
+
+// ReplaceUser applies all writable attributes of the given resource, as defined for PUT.
+type ReplaceUser func(subject auth.Subject, id user.ID, usr User) (User, error)
+type PatchUser func(subject auth.Subject, id user.ID, req PatchRequest) (User, error)
+
+// DeleteUser deprovisions the user by disabling the account. A deprovisioned user is not visible to the
+// identity provider anymore, but is enabled again if the same mail address is provisioned later.
+type DeleteUser func(subject auth.Subject, id user.ID) error
+
+type FindGroups func(subject auth.Subject, query Query) (ListResponse[Group], error)
+type FindGroup func(subject auth.Subject, id group.ID) (Group, error)
+type CreateGroup func(subject auth.Subject, grp Group) (Group, error)
+
+// ReplaceGroup applies the display name and the complete member list.
+type ReplaceGroup func(subject auth.Subject, id group.ID, grp Group) (Group, error)
+type PatchGroup func(subject auth.Subject, id group.ID, req PatchRequest) (Group, error)
+type DeleteGroup func(subject auth.Subject, id group.ID) error
+
+type UseCases struct {
+	FindUsers    FindUsers
+	FindUser     FindUser
+	CreateUser   CreateUser
+	ReplaceUser  ReplaceUser
+	PatchUser    PatchUser
+	DeleteUser   DeleteUser
+	FindGroups   FindGroups
+	FindGroup    FindGroup
+	CreateGroup  CreateGroup
+	ReplaceGroup ReplaceGroup
+	PatchGroup   PatchGroup
+	DeleteGroup  DeleteGroup
+}
+
+func NewUseCases(links LinkRepository, users user.UseCases, groups group.UseCases) UseCases {
+	var mutex sync.Mutex
+	replaceUserFn := NewReplaceUser(&mutex, links, users, groups)
+	replaceGroupFn := NewReplaceGroup(&mutex, links, users, groups)
+
+	return UseCases{
+		FindUsers:    NewFindUsers(links, users, groups),
+		FindUser:     NewFindUser(links, users, groups),
+		CreateUser:   NewCreateUser(&mutex, links, users, groups),
+		ReplaceUser:  replaceUserFn,
+		PatchUser:    NewPatchUser(links, users, groups, replaceUserFn),
+		DeleteUser:   NewDeleteUser(&mutex, links, users),
+		FindGroups:   NewFindGroups(links, users, groups),
+		FindGroup:    NewFindGroup(links, users, groups),
+		CreateGroup:  NewCreateGroup(&mutex, links, users, groups),
+		ReplaceGroup: replaceGroupFn,
+		PatchGroup:   NewPatchGroup(links, users, groups, replaceGroupFn),
+		DeleteGroup:  NewDeleteGroup(&mutex, links, groups),
+	}
+}
@@ -0,0 +1,346 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package scim
+
+import (
+	"encoding/json"
+	"errors"
+	"iter"
+	"slices"
+	"testing"
+
+	"github.com/worldiety/option"
+	"go.wdy.de/nago/application/group"
+	"go.wdy.de/nago/application/permission"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/pkg/blob/mem"
+	"go.wdy.de/nago/pkg/data"
+	jsonrepo "go.wdy.de/nago/pkg/data/json"
+)
+
+// testEnv keeps the local users and groups in memory.
+type testEnv struct {
+	uc      UseCases
+	users   map[user.ID]user.User
+	groups  map[group.ID]group.Group
+	members map[user.ID][]group.ID
+}
+
+func newTestEnv() *testEnv {
+	env := &testEnv{
+		users: map[user.ID]user.User{
+			"admin": {ID: "admin", Email: "admin@example.org", Status: user.Enabled{}},
+		},
+		groups: map[group.ID]group.Group{
+			group.System: {ID: group.System, Name: "System"},
+		},
+		members: map[user.ID][]group.ID{
+			"admin": {group.System},
+		},
+	}
+
+	findByMail := func(subject permission.Auditable, email user.Email) (option.Opt[user.User], error) {
+		for _, u := range env.users {
+			if u.Email.Equals(email) {
+				return option.Some(u), nil
+			}
+		}
+
+		return option.None[user.User](), nil
+	}
+
+	users := user.UseCases{
+		FindAll: func(subject permission.Auditable) iter.Seq2[user.User, error] {
+			return func(yield func(user.User, error) bool) {
+				for _, u := range env.users {
+					if !yield(u, nil) {
+						return
+					}
+				}
+			}
+		},
+		FindByID: func(subject permission.Auditable, id user.ID) (option.Opt[user.User], error) {
+			u, ok := env.users[id]
+			if !ok {
+				return option.None[user.User](), nil
+			}
+
+			return option.Some(u), nil
+		},
+		FindByMail: findByMail,
+		Create: func(subject permission.Auditable, model user.ShortRegistrationUser) (user.User, error) {
+			u := user.User{ID: data.RandIdent[user.ID](), Email: model.Email, Status: user.Enabled{}, EMailVerified: model.Verified}
+			u.Contact.Firstname = model.Firstname
+			u.Contact.Lastname = model.Lastname
+			env.users[u.ID] = u
+			return u, nil
+		},
+		UpdateOtherContact: func(subject user.AuditableUser, id user.ID, contact user.Contact) error {
+			u := env.users[id]
+			u.Contact = contact
+			env.users[id] = u
+			return nil
+		},
+		ChangeOtherEmail: func(subject user.AuditableUser, id user.ID, newEmail user.Email, notifyUser bool) error {
+			if other, _ := findByMail(subject, newEmail); other.IsSome() && other.Unwrap().ID != id {
+				return user.EMailAlreadyInUseErr
+			}
+
+			u := env.users[id]
+			u.Email = newEmail
+			u.EMailVerified = false
+			env.users[id] = u
+			return nil
+		},
+		UpdateVerification: func(subject permission.Auditable, id user.ID, verified bool) error {
+			u := env.users[id]
+			u.EMailVerified = verified
+			env.users[id] = u
+			return nil
+		},
+		UpdateAccountStatus: func(subject permission.Auditable, id user.ID, status user.AccountStatus) error {
+			u := env.users[id]
+			u.Status = status
+			env.users[id] = u
+			return nil
+		},
+		ListGroups: func(subject user.AuditableUser, uid user.ID) iter.Seq2[group.ID, error] {
+			return func(yield func(group.ID, error) bool) {
+				for _, gid := range env.members[uid] {
+					if !yield(gid, nil) {
+						return
+					}
+				}
+			}
+		},
+		UpdateOtherGroups: func(subject user.AuditableUser, id user.ID, groups []group.ID) error {
+			env.members[id] = groups
+			return nil
+		},
+		AddUserToGroup: func(subject user.AuditableUser, id user.ID, gid group.ID) error {
+			if !slices.Contains(env.members[id], gid) {
+				env.members[id] = append(env.members[id], gid)
+			}
+
+			return nil
+		},
+	}
+
+	groups := group.UseCases{
+		FindAll: func(subject permission.Auditable) iter.Seq2[group.Group, error] {
+			return func(yield func(group.Group, error) bool) {
+				for _, g := range env.groups {
+					if !yield(g, nil) {
+						return
+					}
+				}
+			}
+		},
+		FindByID: func(subject permission.Auditable, id group.ID) (option.Opt[group.Group], error) {
+			g, ok := env.groups[id]
+			if !ok {
+				return option.None[group.Group](), nil
+			}
+
+			return option.Some(g), nil
+		},
+		Upsert: func(subject permission.Auditable, g group.Group) (group.ID, error) {
+			if g.ID == "" {
+				g.ID = data.RandIdent[group.ID]()
+			}
+
+			env.groups[g.ID] = g
+			return g.ID, nil
+		},
+		Delete: func(subject permission.Auditable, id group.ID) error {
+			delete(env.groups, id)
+			return nil
+		},
+	}
+
+	links := jsonrepo.NewSloppyJSONRepository[Link, string](mem.NewBlobStore("links"))
+	env.uc = NewUseCases(links, users, groups)
+	return env
+}
+
+func TestFilter(t *testing.T) {
+	doc := map[string]any{
+		"userName": "Alice@Example.org",
+		"active":   true,
+		"name":     map[string]any{"givenName": "Alice"},
+		"emails": []any{
+			map[string]any{"value": "alice@example.org", "type": "work"},
+			map[string]any{"value": "alice@home.example", "type": "home"},
+		},
+		"meta": map[string]any{"created": "2026-01-02T03:04:05Z"},
+	}
+
+	tests := []struct {
+		filter string
+		want   bool
+	}{
+		{`userName eq "alice@example.org"`, true},
+		{`urn:ietf:params:scim:schemas:core:2.0:User:userName eq "alice@example.org"`, true},
+		{`USERNAME sw "alice"`, true},
+		{`name.givenName co "lic" and active eq true`, true},
+		{`active eq false or name.givenName ew "ce"`, true},
+		{`not (active eq true)`, false},
+		{`emails[type eq "home" and value co "home"]`, true},
+		{`emails[type eq "mobile"]`, false},
+		{`emails co "@home"`, true},
+		{`title pr`, false},
+		{`title ne "x"`, true},
+		{`meta.created gt "2026-01-01T00:00:00Z"`, true},
+		{`(userName eq "bob") or (emails.type eq "work")`, true},
+	}
+
+	for _, tt := range tests {
+		f, err := parseFilter(tt.filter)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.filter, err)
+		}
+
+		if got := f.match(doc); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.filter, tt.want, got)
+		}
+	}
+
+	for _, invalid := range []string{`userName eq`, `userName xx "a"`, `(userName pr`, `userName eq "a`, `emails[type eq "a"`} {
+		var scimErr Error
+		if _, err := parseFilter(invalid); !errors.As(err, &scimErr) || scimErr.Type != InvalidFilter {
+			t.Errorf("%s: expected invalid filter, got %v", invalid, err)
+		}
+	}
+}
+
+func TestPatch(t *testing.T) {
+	active := Bool(true)
+	usr := User{
+		UserName: "alice@example.org",
+		Active:   &active,
+		Emails:   []MultiValue{{Value: "alice@example.org", Type: "work", Primary: true}},
+	}
+
+	// a typical update of Entra ID
+	req := PatchRequest{Operations: []PatchOperation{
+		{Op: "Replace", Path: "active", Value: json.RawMessage(`"False"`)},
+		{Op: "Replace", Path: `emails[type eq "work"].value`, Value: json.RawMessage(`"alice@corp.example"`)},
+		{Op: "Add", Path: `phoneNumbers[type eq "mobile"].value`, Value: json.RawMessage(`"+49 123"`)},
+		{Op: "Replace", Value: json.RawMessage(`{"name.givenName":"Alice","name.familyName":"Liddell","urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department":"Sales"}`)},
+	}}
+
+	res, err := patch(usr, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.IsActive() || res.Email() != "alice@corp.example" || res.phone("mobile") != "+49 123" || res.Name.GivenName != "Alice" || res.Name.FamilyName != "Liddell" {
+		t.Fatalf("unexpected patch result: %+v", res)
+	}
+
+	grp := Group{DisplayName: "staff", Members: []MultiValue{{Value: "a"}, {Value: "b"}}}
+	patched, err := patch(grp, PatchRequest{Operations: []PatchOperation{
+		{Op: "add", Path: "members", Value: json.RawMessage(`[{"value":"c"},{"value":"a"}]`)},
+		{Op: "remove", Path: `members[value eq "b"]`},
+		{Op: "Remove", Path: "members", Value: json.RawMessage(`[{"value":"c"}]`)},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(patched.Members) != 1 || patched.Members[0].Value != "a" {
+		t.Fatalf("unexpected members: %+v", patched.Members)
+	}
+
+	if _, err := patch(grp, PatchRequest{Operations: []PatchOperation{{Op: "replace", Path: `members[display co "x"].value`, Value: json.RawMessage(`"d"`)}}}); err == nil {
+		t.Fatal("expected no target error")
+	}
+}
+
+func TestProvisioning(t *testing.T) {
+	env := newTestEnv()
+	su := user.SU()
+
+	alice, err := env.uc.CreateUser(su, User{
+		UserName:   "alice@corp.example",
+		ExternalID: "ext-1",
+		Name:       Name{GivenName: "Alice", FamilyName: "Liddell"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !env.users[user.ID(alice.ID)].EMailVerified || alice.ExternalID != "ext-1" || !alice.IsActive() {
+		t.Fatalf("unexpected user: %+v", alice)
+	}
+
+	var scimErr Error
+	if _, err := env.uc.CreateUser(su, User{UserName: "ALICE@corp.example"}); !errors.As(err, &scimErr) || scimErr.Status != 409 {
+		t.Fatalf("expected uniqueness error, got %v", err)
+	}
+
+	list, err := env.uc.FindUsers(su, Query{Filter: `userName eq "alice@corp.example"`, Count: 10})
+	if err != nil || list.TotalResults != 1 || list.Resources[0].ID != alice.ID {
+		t.Fatalf("expected to find alice: %+v %v", list, err)
+	}
+
+	staff, err := env.uc.CreateGroup(su, Group{DisplayName: "Staff", Members: []MultiValue{{Value: alice.ID}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(staff.Members) != 1 || staff.Members[0].Value != alice.ID {
+		t.Fatalf("expected alice to be a member: %+v", staff)
+	}
+
+	if _, err := env.uc.PatchGroup(su, group.ID(staff.ID), PatchRequest{Operations: []PatchOperation{{Op: "add", Path: "members", Value: json.RawMessage(`[{"value":"unknown"}]`)}}}); !errors.As(err, &scimErr) || scimErr.Status != 400 {
+		t.Fatalf("expected unknown member error, got %v", err)
+	}
+
+	// the system group is never exposed
+	if _, err := env.uc.FindGroup(su, group.System); !errors.As(err, &scimErr) || scimErr.Status != 404 {
+		t.Fatalf("expected system group to be hidden, got %v", err)
+	}
+
+	groups, err := env.uc.FindGroups(su, Query{Count: 10, ExcludeMembers: true})
+	if err != nil || groups.TotalResults != 1 || groups.Resources[0].Members != nil {
+		t.Fatalf("unexpected groups: %+v %v", groups, err)
+	}
+
+	alice, err = env.uc.PatchUser(su, user.ID(alice.ID), PatchRequest{Operations: []PatchOperation{{Op: "Replace", Path: "active", Value: json.RawMessage(`"False"`)}}})
+	if err != nil || alice.IsActive() || env.users[user.ID(alice.ID)].Enabled() {
+		t.Fatalf("expected alice to be disabled: %v", err)
+	}
+
+	if _, err := env.uc.PatchGroup(su, group.ID(staff.ID), PatchRequest{Operations: []PatchOperation{{Op: "remove", Path: `members[value eq "` + alice.ID + `"]`}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if slices.Contains(env.members[user.ID(alice.ID)], group.ID(staff.ID)) {
+		t.Fatal("expected alice to be removed from staff")
+	}
+
+	// deprovisioning disables instead of deleting
+	if err := env.uc.DeleteUser(su, user.ID(alice.ID)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := env.users[user.ID(alice.ID)]; !ok {
+		t.Fatal("user must not be deleted")
+	}
+
+	if _, err := env.uc.FindUser(su, user.ID(alice.ID)); !errors.As(err, &scimErr) || scimErr.Status != 404 {
+		t.Fatalf("expected deprovisioned user to be hidden, got %v", err)
+	}
+
+	// provisioning the same address again reactivates the account
+	again, err := env.uc.CreateUser(su, User{UserName: "alice@corp.example", Name: Name{GivenName: "Alice"}})
+	if err != nil || again.ID != alice.ID || !env.users[user.ID(alice.ID)].Enabled() {
+		t.Fatalf("expected alice to be provisioned again: %+v %v", again, err)
+	}
+}
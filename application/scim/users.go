@@ -0,0 +1,201 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package scim
+
+import (
+	"errors"
+	"fmt"
+
+	"go.wdy.de/nago/application/group"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/pkg/xstrings"
+)
+
+func loadLink(links LinkRepository, id string) (Link, error) {
+	optLink, err := links.FindByID(id)
+	if err != nil {
+		return Link{}, fmt.Errorf("cannot find scim link: %w", err)
+	}
+
+	if optLink.IsNone() {
+		return Link{ID: id}, nil
+	}
+
+	return optLink.Unwrap(), nil
+}
+
+// groupNames resolves all groups, which are visible to the identity provider.
+func groupNames(groups group.UseCases) (map[group.ID]string, error) {
+	res := map[group.ID]string{}
+	for g, err := range groups.FindAll(user.SU()) {
+		if err != nil {
+			return nil, fmt.Errorf("cannot find groups: %w", err)
+		}
+
+		if visibleGroup(g.ID) {
+			res[g.ID] = g.Name
+		}
+	}
+
+	return res, nil
+}
+
+// visibleGroup hides the system group, because its members are effectively administrators.
+func visibleGroup(id group.ID) bool {
+	return id != group.System
+}
+
+func userResource(users user.UseCases, names map[group.ID]string, usr user.User, link Link) (User, error) {
+	created := usr.CreatedAt
+	active := Bool(usr.Enabled())
+	res := User{
+		Schemas:    []string{SchemaUser},
+		ID:         string(usr.ID),
+		ExternalID: link.ExternalID,
+		UserName:   link.UserName,
+		Name: Name{
+			Formatted:       xstrings.Join2(" ", usr.Contact.Firstname, usr.Contact.Lastname),
+			FamilyName:      usr.Contact.Lastname,
+			GivenName:       usr.Contact.Firstname,
+			HonorificPrefix: usr.Contact.Title,
+		},
+		DisplayName: xstrings.Join2(" ", usr.Contact.Firstname, usr.Contact.Lastname),
+		Title:       usr.Contact.Position,
+		Active:      &active,
+		Emails:      []MultiValue{{Value: string(usr.Email), Type: "work", Primary: true}},
+		Meta:        &Meta{ResourceType: "User", Created: &created},
+	}
+
+	if res.UserName == "" {
+		res.UserName = string(usr.Email)
+	}
+
+	if usr.Contact.Phone != "" {
+		res.PhoneNumbers = append(res.PhoneNumbers, MultiValue{Value: usr.Contact.Phone, Type: "work"})
+	}
+
+	if usr.Contact.MobilePhone != "" {
+		res.PhoneNumbers = append(res.PhoneNumbers, MultiValue{Value: usr.Contact.MobilePhone, Type: "mobile"})
+	}
+
+	for gid, err := range users.ListGroups(user.SU(), usr.ID) {
+		if err != nil {
+			return User{}, fmt.Errorf("cannot list groups of %s: %w", usr.ID, err)
+		}
+
+		if name, ok := names[gid]; ok {
+			res.Groups = append(res.Groups, MultiValue{Value: string(gid), Display: name})
+		}
+	}
+
+	return res, nil
+}
+
+// findUser returns the user, unless it does not exist or has been deprovisioned.
+func findUser(links LinkRepository, users user.UseCases, id user.ID) (user.User, Link, error) {
+	optUsr, err := users.FindByID(user.SU(), id)
+	if err != nil {
+		return user.User{}, Link{}, fmt.Errorf("cannot find user: %w", err)
+	}
+
+	if optUsr.IsNone() {
+		return user.User{}, Link{}, notFound(string(id))
+	}
+
+	link, err := loadLink(links, UserLink(id))
+	if err != nil {
+		return user.User{}, Link{}, err
+	}
+
+	if link.Deprovisioned {
+		return user.User{}, Link{}, notFound(string(id))
+	}
+
+	return optUsr.Unwrap(), link, nil
+}
+
+func loadUser(links LinkRepository, users user.UseCases, groups group.UseCases, id user.ID) (User, error) {
+	usr, link, err := findUser(links, users, id)
+	if err != nil {
+		return User{}, err
+	}
+
+	names, err := groupNames(groups)
+	if err != nil {
+		return User{}, err
+	}
+
+	return userResource(users, names, usr, link)
+}
+
+func validateUser(res User) error {
+	if res.UserName == "" {
+		return newError(400, InvalidValue, "userName is required")
+	}
+
+	if !res.Email().Valid() {
+		return newError(400, InvalidValue, "a valid email address is required")
+	}
+
+	return nil
+}
+
+// applyUser writes all writable attributes of the resource. Only changed values are written, because each
+// use case publishes its own events.
+func applyUser(links LinkRepository, users user.UseCases, usr user.User, link Link, res User) error {
+	contact := usr.Contact
+	contact.Firstname = res.Name.GivenName
+	contact.Lastname = res.Name.FamilyName
+	contact.Title = res.Name.HonorificPrefix
+	contact.Position = res.Title
+	contact.Phone = res.phone("work")
+	contact.MobilePhone = res.phone("mobile")
+
+	if contact.Firstname != usr.Contact.Firstname || contact.Lastname != usr.Contact.Lastname ||
+		contact.Title != usr.Contact.Title || contact.Position != usr.Contact.Position ||
+		contact.Phone != usr.Contact.Phone || contact.MobilePhone != usr.Contact.MobilePhone {
+		if err := users.UpdateOtherContact(user.SU(), usr.ID, contact); err != nil {
+			return fmt.Errorf("cannot update contact: %w", err)
+		}
+	}
+
+	if mail := res.Email(); !usr.Email.Equals(mail) {
+		if err := users.ChangeOtherEmail(user.SU(), usr.ID, mail, false); err != nil {
+			if errors.Is(err, user.EMailAlreadyInUseErr) {
+				return newError(409, Uniqueness, "email address is already in use")
+			}
+
+			return fmt.Errorf("cannot change email: %w", err)
+		}
+
+		// the identity provider has verified the address
+		if err := users.UpdateVerification(user.SU(), usr.ID, true); err != nil {
+			return fmt.Errorf("cannot update verification: %w", err)
+		}
+	}
+
+	if res.IsActive() != usr.Enabled() {
+		var status user.AccountStatus = user.Disabled{}
+		if res.IsActive() {
+			status = user.Enabled{}
+		}
+
+		if err := users.UpdateAccountStatus(user.SU(), usr.ID, status); err != nil {
+			return fmt.Errorf("cannot update account status: %w", err)
+		}
+	}
+
+	updated := Link{ID: UserLink(usr.ID), ExternalID: res.ExternalID, UserName: res.UserName}
+	if updated != link {
+		if err := links.Save(updated); err != nil {
+			return fmt.Errorf("cannot save scim link: %w", err)
+		}
+	}
+
+	return nil
+}
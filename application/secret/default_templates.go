@@ -12,9 +12,15 @@ type SMTP struct {
 	Host          string
 	Port          int `value:"587"`
 	Username      string
-	Password      string   `style:"secret"`
-	SenderAddress string   `value:"" label:"Absenderadresse" supportingText:"Wenn leer, wird der Username verwendet, ansonsten hat diese Absenderadresse Vorrang."`
-	_             struct{} `credentialName:"SMTP Postausgangsserver" credentialDescription:"Ein Postausgangsserver wird benötigt, um E-Mails zu verschicken." credentialLogo:"https://www.thunderbird.net/media/img/thunderbird/favicon-196.png"`
+	Password      string `style:"secret"`
+	SenderAddress string `value:"" label:"Absenderadresse" supportingText:"Wenn leer, wird der Username verwendet, ansonsten hat diese Absenderadresse Vorrang."`
+	MaxPerHour    int    `label:"Maximale Mails pro Stunde" supportingText:"Viele Anbieter begrenzen die Anzahl versendeter Mails. Wenn 0, ist der Versand nicht begrenzt."`
+
+	DKIMDomain     string `label:"DKIM Domain" supportingText:"Die signierende Domain (d=), meist die Domain der Absenderadresse. Wenn leer, werden Mails nicht signiert."`
+	DKIMSelector   string `label:"DKIM Selektor" supportingText:"Der Selektor (s=), unter dem der öffentliche Schlüssel im DNS als TXT-Eintrag <selektor>._domainkey.<domain> veröffentlicht ist."`
+	DKIMPrivateKey string `label:"DKIM privater Schlüssel" style:"secret" supportingText:"Ein RSA oder Ed25519 Schlüssel im PEM Format (PKCS#1 oder PKCS#8)."`
+
+	_ struct{} `credentialName:"SMTP Postausgangsserver" credentialDescription:"Ein Postausgangsserver wird benötigt, um E-Mails zu verschicken." credentialLogo:"https://www.thunderbird.net/media/img/thunderbird/favicon-196.png"`
 }
 
 func (SMTP) Credentials() bool {
@@ -9,6 +9,10 @@ package session
 
 const (
 	NotLoggedInErr NotLoggedInError = "not logged in"
+
+	// SecondFactorRequiredErr is returned by [Login], if the password was correct but the user must still
+	// complete the login using [LoginSecondFactor].
+	SecondFactorRequiredErr SecondFactorRequiredError = "second factor required"
 )
 
 type NotLoggedInError string
@@ -24,3 +28,9 @@ func (e NotLoggedInError) PermissionDenied() bool {
 func (e NotLoggedInError) NotLoggedIn() bool {
 	return true
 }
+
+type SecondFactorRequiredError string
+
+func (e SecondFactorRequiredError) Error() string {
+	return string(e)
+}
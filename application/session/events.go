@@ -13,3 +13,10 @@ type Authenticated struct {
 	Session ID
 	User    user.ID
 }
+
+// LoggedOut is published, whenever an authenticated session has been logged out, either by the user, by an
+// administrator or because it expired. Open windows of the session drop their subject immediately.
+type LoggedOut struct {
+	Session ID
+	User    user.ID
+}
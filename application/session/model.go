@@ -24,10 +24,29 @@ type Session struct {
 	AuthenticatedAt time.Time           `json:"authenticatedAt,omitempty,omitzero"`
 	Values          map[string]string   `json:"values,omitempty,omitzero"`
 	RefreshToken    NLSRefreshToken     `json:"refreshToken,omitzero"`
+
+	// PendingUser has passed the password check but not yet the second factor, see [LoginSecondFactor].
+	PendingUser     std.Option[user.ID] `json:"pendingUser,omitempty,omitzero"`
+	PendingSince    time.Time           `json:"pendingSince,omitempty,omitzero"`
+	PendingFailures int                 `json:"pendingFailures,omitempty"`
+
+	// UserAgent, RemoteAddr and LastSeenAt describe the device of an authenticated session, see [Seen].
+	UserAgent  string    `json:"userAgent,omitempty"`
+	RemoteAddr string    `json:"remoteAddr,omitempty"`
+	LastSeenAt time.Time `json:"lastSeenAt,omitzero"`
 }
 
 func (s Session) Identity() ID {
 	return s.ID
 }
 
+// LastActivity returns the time of the last login or the last usage, whichever is later.
+func (s Session) LastActivity() time.Time {
+	if s.LastSeenAt.After(s.AuthenticatedAt) {
+		return s.LastSeenAt
+	}
+
+	return s.AuthenticatedAt
+}
+
 type Repository = data.Repository[Session, ID]
@@ -0,0 +1,15 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package session
+
+import "go.wdy.de/nago/application/permission"
+
+var (
+	PermFindUserSessions = permission.Declare[FindUserSessions]("nago.session.find_user_sessions", "Sitzungen anzeigen", "Träger dieser Berechtigung können die angemeldeten Geräte aller Nutzer sehen. Die eigenen Geräte kann jeder Nutzer sehen.")
+	PermRevoke           = permission.Declare[Revoke]("nago.session.revoke", "Sitzungen abmelden", "Träger dieser Berechtigung können Nutzer auf einzelnen oder allen Geräten abmelden. Die eigenen Geräte kann jeder Nutzer abmelden.")
+)
@@ -6,3 +6,39 @@
 // SPDX-License-Identifier: Custom-License
 
 package session
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+type ScheduleOptions struct {
+	ExpireInterval time.Duration // default is 5 minutes, within this interval expired sessions are logged out
+}
+
+// StartScheduler starts a new scheduler instance, which enforces the lifetimes of the [Settings] by invoking
+// expire periodically until the context is done.
+func StartScheduler(ctx context.Context, opts ScheduleOptions, expire Expire) {
+	if opts.ExpireInterval == 0 {
+		opts.ExpireInterval = 5 * time.Minute
+	}
+
+	go func() {
+		slog.Info("session scheduler started")
+		ticker := time.NewTicker(opts.ExpireInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				slog.Info("session scheduler stopped")
+				return
+			case <-ticker.C:
+				if err := expire(); err != nil {
+					slog.Error("session scheduler cannot expire sessions", "err", err)
+				}
+			}
+		}
+	}()
+}
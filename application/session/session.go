@@ -65,6 +65,14 @@ func (s *sessionImpl) refresh() Session {
 	return session
 }
 
+// invalidate forces a reload of the session at the next access, e.g. because it has been logged out elsewhere.
+func (s *sessionImpl) invalidate() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.lastRefreshedAt = time.Time{}
+}
+
 func (s *sessionImpl) load() Session {
 	optSess, err := s.repo.FindByID(s.id)
 	if err != nil {
@@ -0,0 +1,42 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package session
+
+import (
+	"cmp"
+	"time"
+
+	"github.com/worldiety/enum"
+	"go.wdy.de/nago/application/settings"
+)
+
+var _ = enum.Variant[settings.GlobalSettings, Settings](
+	enum.Rename[Settings]("nago.session.settings"),
+)
+
+type Settings struct {
+	_ any `title:"Sitzungen" description:"Legt fest, wie lange eine Anmeldung gültig bleibt. Abgelaufene Sitzungen werden regelmäßig abgemeldet und geöffnete Fenster verlieren sofort ihre Anmeldung."`
+
+	IdleTimeout time.Duration `json:"idleTimeout,omitempty" label:"Abmelden nach Inaktivität" supportingText:"Eine Sitzung wird abgemeldet, wenn sie so lange nicht verwendet wurde. Wenn leer, gibt es keine Begrenzung."`
+	MaxLifetime time.Duration `json:"maxLifetime,omitempty" label:"Maximale Dauer einer Anmeldung" supportingText:"Spätestens nach dieser Zeit muss sich ein Nutzer erneut anmelden. Standard sind 90 Tage."`
+}
+
+func (s Settings) GlobalSettings() bool { return true }
+
+func (s Settings) maxLifetime() time.Duration {
+	return cmp.Or(max(s.MaxLifetime, 0), 90*24*time.Hour)
+}
+
+// expired returns true, if the authenticated session has exceeded its absolute or its idle lifetime.
+func (s Settings) expired(session Session, now time.Time) bool {
+	if now.Sub(session.AuthenticatedAt) > s.maxLifetime() {
+		return true
+	}
+
+	return s.IdleTimeout > 0 && now.Sub(session.LastActivity()) > s.IdleTimeout
+}
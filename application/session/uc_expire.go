@@ -0,0 +1,64 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package session
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.wdy.de/nago/application/settings"
+	"go.wdy.de/nago/pkg/events"
+)
+
+func NewExpire(mutex *sync.Mutex, bus events.Bus, sessions Repository, loadGlobal settings.LoadGlobal) Expire {
+	return func() error {
+		cfg := settings.ReadGlobal[Settings](loadGlobal)
+		now := time.Now()
+
+		// collect first, because we must not modify the repository while iterating
+		var expired []ID
+		for session, err := range sessions.All() {
+			if err != nil {
+				return fmt.Errorf("cannot iterate sessions: %w", err)
+			}
+
+			if session.User.IsSome() && cfg.expired(session, now) {
+				expired = append(expired, session.ID)
+			}
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		for _, id := range expired {
+			// double-check, the session may have been used or renewed in the meantime
+			optSession, err := sessions.FindByID(id)
+			if err != nil {
+				return fmt.Errorf("sessions.FindByID failed: %w", err)
+			}
+
+			if optSession.IsNone() {
+				continue
+			}
+
+			session := optSession.Unwrap()
+			if session.User.IsNone() || !cfg.expired(session, now) {
+				continue
+			}
+
+			slog.Info("session expired", "session", session.ID, "user", session.User.Unwrap())
+			if err := logout(bus, sessions, session); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
@@ -9,13 +9,16 @@ package session
 
 import (
 	"fmt"
-	"go.wdy.de/nago/application/user"
-	"go.wdy.de/nago/pkg/std"
 	"log/slog"
+	"sync"
 	"time"
+
+	"go.wdy.de/nago/application/settings"
+	"go.wdy.de/nago/pkg/events"
+	"go.wdy.de/nago/pkg/std"
 )
 
-func NewFindByID(sessions Repository) FindByID {
+func NewFindByID(mutex *sync.Mutex, bus events.Bus, sessions Repository, loadGlobal settings.LoadGlobal) FindByID {
 	return func(id ID) (std.Option[Session], error) {
 		optSession, err := sessions.FindByID(id)
 		if err != nil {
@@ -32,13 +35,14 @@ func NewFindByID(sessions Repository) FindByID {
 			return std.None[Session](), nil
 		}
 
-		// TODO make this configurable and perhaps add another deadline for short sessions?
-		const day = 24 * time.Hour
-		if time.Now().Sub(session.AuthenticatedAt) > day*30*3 {
-			slog.Error("session expired for user", "sessionID", session.ID, "user", session.User)
-			session.User = std.None[user.ID]()
-			session.AuthenticatedAt = time.Time{}
-			if err := sessions.Save(session); err != nil {
+		cfg := settings.ReadGlobal[Settings](loadGlobal)
+		if cfg.expired(session, time.Now()) {
+			slog.Info("session expired for user", "sessionID", session.ID, "user", session.User)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			if err := logout(bus, sessions, session); err != nil {
 				return std.None[Session](), fmt.Errorf("failed to save expired session: %w", err)
 			}
 
@@ -7,11 +7,20 @@
 
 package session
 
-import "go.wdy.de/nago/pkg/std/concurrent"
+import (
+	"go.wdy.de/nago/pkg/events"
+	"go.wdy.de/nago/pkg/std/concurrent"
+)
 
-func NewFindUserSessionByID(repository Repository, refresh RefreshNLS) FindUserSessionByID {
+func NewFindUserSessionByID(bus events.Bus, repository Repository, refresh RefreshNLS) FindUserSessionByID {
 	var cache concurrent.RWMap[ID, *sessionImpl]
 
+	events.SubscribeFor[LoggedOut](bus, func(evt LoggedOut) {
+		if v, ok := cache.Get(evt.Session); ok {
+			v.invalidate()
+		}
+	})
+
 	return func(id ID) UserSession {
 		if v, ok := cache.Get(id); ok {
 			return v
@@ -0,0 +1,58 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package session
+
+import (
+	"fmt"
+	"slices"
+
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/auth"
+)
+
+func NewFindUserSessions(sessions Repository) FindUserSessions {
+	return func(subject auth.Subject, uid user.ID) ([]Session, error) {
+		if !ownedBy(subject, uid) {
+			if err := subject.Audit(PermFindUserSessions); err != nil {
+				return nil, err
+			}
+		}
+
+		res, err := userSessions(sessions, uid)
+		if err != nil {
+			return nil, err
+		}
+
+		slices.SortFunc(res, func(a, b Session) int {
+			return b.LastActivity().Compare(a.LastActivity())
+		})
+
+		return res, nil
+	}
+}
+
+// ownedBy returns true, if the subject is the given user itself.
+func ownedBy(subject auth.Subject, uid user.ID) bool {
+	return subject.Valid() && subject.ID() == uid
+}
+
+// userSessions returns all authenticated sessions of the given user. Note, that this is O(n) over all sessions.
+func userSessions(sessions Repository, uid user.ID) ([]Session, error) {
+	var res []Session
+	for session, err := range sessions.All() {
+		if err != nil {
+			return nil, fmt.Errorf("cannot iterate sessions: %w", err)
+		}
+
+		if session.User.IsSome() && session.User.Unwrap() == uid {
+			res = append(res, session)
+		}
+	}
+
+	return res, nil
+}
@@ -8,16 +8,23 @@
 package session
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
+	"go.wdy.de/nago/application/lockout"
 	"go.wdy.de/nago/application/user"
 	"go.wdy.de/nago/pkg/events"
 	"go.wdy.de/nago/pkg/std"
 )
 
-func NewLogin(bus events.Bus, sessions Repository, authenticate user.AuthenticateByPassword) Login {
-	return func(id ID, login user.Email, password user.Password) (bool, error) {
+func NewLogin(bus events.Bus, sessions Repository, authenticate user.AuthenticateByPassword, requiresSecondFactor RequiresSecondFactor, throttle lockout.UseCases) Login {
+	return func(id ID, login user.Email, password user.Password, remoteAddr string) (bool, error) {
+		ip := lockout.IPKey(remoteAddr)
+		if err := throttle.Check(ip); err != nil {
+			return false, err
+		}
+
 		// first install the session
 		optSession, err := sessions.FindByID(id)
 		if err != nil {
@@ -35,15 +42,51 @@ func NewLogin(bus events.Bus, sessions Repository, authenticate user.Authenticat
 		// try to authenticate
 		optUsr, err := authenticate(login, password)
 		if err != nil {
+			if errors.Is(err, user.InvalidCredentialsErr) {
+				if err := throttle.Fail(ip); err != nil {
+					return false, err
+				}
+			}
+
 			return false, fmt.Errorf("auhentication failed: %w", err)
 		}
 
 		if optUsr.IsNone() {
+			if err := throttle.Fail(ip); err != nil {
+				return false, err
+			}
+
 			return false, nil
 		}
 
-		session.User = std.Some(optUsr.Unwrap().ID)
+		uid := optUsr.Unwrap().ID
+		if requiresSecondFactor != nil {
+			required, err := requiresSecondFactor(uid)
+			if err != nil {
+				return false, fmt.Errorf("cannot check second factor requirement: %w", err)
+			}
+
+			if required {
+				session.PendingUser = std.Some(uid)
+				session.PendingSince = time.Now()
+				session.PendingFailures = 0
+				if err := sessions.Save(session); err != nil {
+					return false, fmt.Errorf("sessions.Save failed: %w", err)
+				}
+
+				return false, SecondFactorRequiredErr
+			}
+		}
+
+		if err := throttle.Succeed(lockout.UserKey(string(uid))); err != nil {
+			return false, err
+		}
+
+		session.User = std.Some(uid)
 		session.AuthenticatedAt = time.Now()
+		session.PendingUser = std.None[user.ID]()
+		session.PendingSince = time.Time{}
+		session.PendingFailures = 0
 
 		if err := sessions.Save(session); err != nil {
 			return false, fmt.Errorf("sessions.Save failed: %w", err)
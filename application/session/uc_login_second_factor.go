@@ -0,0 +1,109 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package session
+
+import (
+	"fmt"
+	"time"
+
+	"go.wdy.de/nago/application/lockout"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/pkg/events"
+	"go.wdy.de/nago/pkg/std"
+)
+
+// pendingSecondFactorTimeout is the time a user has to complete the second factor after the password check.
+const pendingSecondFactorTimeout = 10 * time.Minute
+
+// maxPendingFailures is the number of wrong second factors, after which the pending login is dropped. The account
+// counter of the lockout applies in addition, but a fresh password login must not simply continue guessing.
+const maxPendingFailures = 3
+
+func pendingUser(session Session) std.Option[user.ID] {
+	if session.PendingUser.IsNone() || time.Since(session.PendingSince) > pendingSecondFactorTimeout {
+		return std.None[user.ID]()
+	}
+
+	return session.PendingUser
+}
+
+func NewPendingSecondFactor(sessions Repository) PendingSecondFactor {
+	return func(id ID) (std.Option[user.ID], error) {
+		optSession, err := sessions.FindByID(id)
+		if err != nil {
+			return std.None[user.ID](), fmt.Errorf("sessions.FindByID failed: %w", err)
+		}
+
+		if optSession.IsNone() {
+			return std.None[user.ID](), nil
+		}
+
+		return pendingUser(optSession.Unwrap()), nil
+	}
+}
+
+func NewLoginSecondFactor(bus events.Bus, sessions Repository, throttle lockout.UseCases) LoginSecondFactor {
+	return func(id ID, verify VerifySecondFactor) (bool, error) {
+		optSession, err := sessions.FindByID(id)
+		if err != nil {
+			return false, fmt.Errorf("sessions.FindByID failed: %w", err)
+		}
+
+		if optSession.IsNone() {
+			return false, NotLoggedInErr
+		}
+
+		session := optSession.Unwrap()
+		optUid := pendingUser(session)
+		if optUid.IsNone() {
+			return false, NotLoggedInErr
+		}
+
+		uid := optUid.Unwrap()
+		ok, err := verify(uid)
+		if err != nil {
+			return false, err
+		}
+
+		if !ok {
+			session.PendingFailures++
+			if session.PendingFailures >= maxPendingFailures {
+				session.PendingUser = std.None[user.ID]()
+				session.PendingSince = time.Time{}
+				session.PendingFailures = 0
+			}
+
+			if err := sessions.Save(session); err != nil {
+				return false, fmt.Errorf("sessions.Save failed: %w", err)
+			}
+
+			return false, nil
+		}
+
+		if err := throttle.Succeed(lockout.UserKey(string(uid))); err != nil {
+			return false, err
+		}
+
+		session.User = std.Some(uid)
+		session.AuthenticatedAt = time.Now()
+		session.PendingUser = std.None[user.ID]()
+		session.PendingSince = time.Time{}
+		session.PendingFailures = 0
+
+		if err := sessions.Save(session); err != nil {
+			return false, fmt.Errorf("sessions.Save failed: %w", err)
+		}
+
+		bus.Publish(Authenticated{
+			Session: id,
+			User:    uid,
+		})
+
+		return true, nil
+	}
+}
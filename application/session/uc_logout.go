@@ -9,14 +9,19 @@ package session
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/pkg/events"
 	"go.wdy.de/nago/pkg/std"
 )
 
-func NewLogout(sessions Repository) Logout {
+func NewLogout(mutex *sync.Mutex, bus events.Bus, sessions Repository) Logout {
 	return func(id ID) (bool, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
 		optSession, err := sessions.FindByID(id)
 		if err != nil {
 			return false, fmt.Errorf("sessions.FindByID failed: %v", err)
@@ -27,14 +32,28 @@ func NewLogout(sessions Repository) Logout {
 			return true, nil
 		}
 
-		session := optSession.Unwrap()
-		session.User = std.None[user.ID]()
-		session.AuthenticatedAt = time.Time{}
-		session.RefreshToken = ""
-		if err := sessions.Save(session); err != nil {
-			return false, fmt.Errorf("sessions.Save failed: %v", err)
+		if err := logout(bus, sessions, optSession.Unwrap()); err != nil {
+			return false, err
 		}
 
 		return true, nil
 	}
 }
+
+// logout removes the authentication from the session and tells all open windows about it. The caller must hold
+// the lock.
+func logout(bus events.Bus, sessions Repository, session Session) error {
+	uid := session.User
+	session.User = std.None[user.ID]()
+	session.AuthenticatedAt = time.Time{}
+	session.RefreshToken = ""
+	if err := sessions.Save(session); err != nil {
+		return fmt.Errorf("sessions.Save failed: %v", err)
+	}
+
+	if uid.IsSome() {
+		bus.Publish(LoggedOut{Session: session.ID, User: uid.Unwrap()})
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package session
+
+import (
+	"log/slog"
+	"sync"
+
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/auth"
+	"go.wdy.de/nago/pkg/events"
+)
+
+func NewLogoutUser(mutex *sync.Mutex, bus events.Bus, sessions Repository) LogoutUser {
+	return func(subject auth.Subject, uid user.ID) (int, error) {
+		if !ownedBy(subject, uid) {
+			if err := subject.Audit(PermRevoke); err != nil {
+				return 0, err
+			}
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		res, err := userSessions(sessions, uid)
+		if err != nil {
+			return 0, err
+		}
+
+		for _, session := range res {
+			if err := logout(bus, sessions, session); err != nil {
+				return 0, err
+			}
+		}
+
+		slog.Info("logged out user everywhere", "user", uid, "sessions", len(res))
+
+		return len(res), nil
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package session
+
+import (
+	"fmt"
+	"sync"
+
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/auth"
+	"go.wdy.de/nago/pkg/events"
+)
+
+func NewRevoke(mutex *sync.Mutex, bus events.Bus, sessions Repository) Revoke {
+	return func(subject auth.Subject, id ID) error {
+		if !subject.Valid() {
+			return user.InvalidSubjectErr
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		optSession, err := sessions.FindByID(id)
+		if err != nil {
+			return fmt.Errorf("sessions.FindByID failed: %w", err)
+		}
+
+		if optSession.IsNone() || optSession.Unwrap().User.IsNone() {
+			return nil
+		}
+
+		session := optSession.Unwrap()
+		if !ownedBy(subject, session.User.Unwrap()) {
+			if err := subject.Audit(PermRevoke); err != nil {
+				return err
+			}
+		}
+
+		return logout(bus, sessions, session)
+	}
+}
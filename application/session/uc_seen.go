@@ -0,0 +1,49 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package session
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+func NewSeen(mutex *sync.Mutex, sessions Repository) Seen {
+	return func(id ID, userAgent string, remoteAddr string) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		optSession, err := sessions.FindByID(id)
+		if err != nil {
+			return fmt.Errorf("sessions.FindByID failed: %w", err)
+		}
+
+		if optSession.IsNone() || optSession.Unwrap().User.IsNone() {
+			// anonymous sessions are not tracked
+			return nil
+		}
+
+		session := optSession.Unwrap()
+		now := time.Now()
+
+		// each window is seen at least once, thus avoid writing the store for each navigation
+		if session.UserAgent == userAgent && session.RemoteAddr == remoteAddr && now.Sub(session.LastSeenAt) < time.Minute {
+			return nil
+		}
+
+		session.UserAgent = userAgent
+		session.RemoteAddr = remoteAddr
+		session.LastSeenAt = now
+
+		if err := sessions.Save(session); err != nil {
+			return fmt.Errorf("sessions.Save failed: %w", err)
+		}
+
+		return nil
+	}
+}
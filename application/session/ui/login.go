@@ -10,11 +10,14 @@ package uisession
 import (
 	"errors"
 	"fmt"
+	"net/url"
+	"strings"
 
 	"github.com/worldiety/i18n"
 	"go.wdy.de/nago/application/image"
 	httpimage "go.wdy.de/nago/application/image/http"
 	"go.wdy.de/nago/application/localization/rstring"
+	"go.wdy.de/nago/application/lockout"
 	"go.wdy.de/nago/application/session"
 	"go.wdy.de/nago/application/settings"
 	"go.wdy.de/nago/application/theme"
@@ -33,6 +36,20 @@ var (
 type SendPasswordResetMail func(email user.Email) error
 type SendVerificationMail func(uid user.ID) error
 
+// SecondFactorView renders the second step of a login, after [session.Login] returned
+// [session.SecondFactorRequiredErr]. It must call onSuccess, after the login has been completed.
+type SecondFactorView func(wnd core.Window, onSuccess func()) core.View
+
+// ExternalLogin is an additional identity provider, which is offered as a button below the password form.
+// Start returns the uri, to which the user agent is sent.
+type ExternalLogin struct {
+	Name  string
+	Start func(id session.ID) (uri string, err error)
+}
+
+// ExternalLogins returns the currently configured additional identity providers.
+type ExternalLogins func() []ExternalLogin
+
 func Login(
 	wnd core.Window,
 	loginFn session.Login,
@@ -43,17 +60,49 @@ func Login(
 	sendVerifyMail SendVerificationMail,
 	loadGlobalSettings settings.LoadGlobal,
 	registerPath core.NavigationPath,
+	secondFactor SecondFactorView,
+	externalLogins ExternalLogins,
 ) core.View {
+	// the second step may still show something, e.g. recovery codes, although the login has already been completed
+	secondFactorStep := core.AutoState[bool](wnd)
+	if secondFactorStep.Get() && secondFactor != nil {
+		return ui.VStack(
+			ui.VStack(
+				ui.WindowTitle(rstring.ActionLogin.Get(wnd)),
+				cardlayout.Card("").
+					Padding(ui.Padding{}.All(ui.L12)).
+					Body(secondFactor(wnd, func() {
+						secondFactorStep.Set(false)
+						forwardAfterLogin(wnd)
+					})),
+				ui.LinkWithAction("zurück zur Anmeldung", func() {
+					secondFactorStep.Set(false)
+				}).Font(ui.Small),
+			).Gap(ui.L16).Frame(ui.Frame{Width: ui.L320, Height: ""}),
+		).Frame(ui.Frame{}.MatchScreen())
+	}
+
 	if wnd.Subject().Valid() {
+		title := "Zurück zur Hauptseite"
+		if wnd.Values()["redirect"] != "" {
+			title = "Weiter"
+		}
+
 		return ui.VStack(
 			alert.Banner("Login", "Sie sind bereits eingeloggt.").Intent(alert.IntentOk),
 			ui.PrimaryButton(func() {
-				wnd.Navigation().ForwardTo(".", nil)
-			}).Title("Zurück zur Hauptseite"),
+				forwardAfterLogin(wnd)
+			}).Title(title),
 		).Gap(ui.L8).Frame(ui.Frame{}.MatchScreen())
 	}
 
 	usrSettings := settings.ReadGlobal[user.Settings](loadGlobalSettings)
+
+	var externals []ExternalLogin
+	if externalLogins != nil {
+		externals = externalLogins()
+	}
+
 	themeSettings := settings.ReadGlobal[theme.Settings](loadGlobalSettings)
 
 	var logoImg core.View
@@ -94,13 +143,24 @@ func Login(
 			return
 		}
 
-		ok, err := loginFn(wnd.Session().ID(), user.Email(login.Get()), user.Password(password.Get()))
+		ok, err := loginFn(wnd.Session().ID(), user.Email(login.Get()), user.Password(password.Get()), wnd.Info().RemoteAddr)
 		if err != nil {
 			if errors.Is(err, user.EMailNotVerifiedErr) {
 				verificationDialogPresented.Set(true)
 				return
 			}
 
+			if errors.Is(err, session.SecondFactorRequiredErr) && secondFactor != nil {
+				password.Set("") // clean the password immediately from memory
+				secondFactorStep.Set(true)
+				return
+			}
+
+			if errors.Is(err, lockout.TooManyAttemptsErr) {
+				passwordErr.Set("Zu viele Fehlversuche. Bitte versuchen Sie es später erneut.")
+				return
+			}
+
 			passwordErr.Set("Der Benutzer existiert nicht, das Konto wurde deaktiviert oder das Kennwort ist falsch.")
 			return
 		}
@@ -108,7 +168,7 @@ func Login(
 			fmt.Println("cannot happen?")
 		} else {
 			password.Set("") // clean the password immediately from memory
-			wnd.Navigation().ForwardTo(".", nil)
+			forwardAfterLogin(wnd)
 		}
 	}
 
@@ -186,7 +246,7 @@ func Login(
 
 						}).Font(ui.Small).Visible(presentPasswordForgotten.Get()),
 
-						ui.IfFunc(usrSettings.HasSSO(), func() core.View {
+						ui.IfFunc(usrSettings.HasSSO() || len(externals) > 0, func() core.View {
 							return ui.VStack(
 								ui.HStack(
 									ui.HLine().Frame(ui.Frame{Width: ui.L40}),
@@ -194,7 +254,7 @@ func Login(
 									ui.HLine().Frame(ui.Frame{Width: ui.L40}),
 								).FullWidth().Gap(ui.L8),
 
-								ui.SecondaryButton(func() {
+								ui.If(usrSettings.HasSSO(), ui.SecondaryButton(func() {
 									uri, err := startNLSFlow(wnd.Session().ID())
 									if err != nil {
 										alert.ShowBannerError(wnd, err)
@@ -202,7 +262,21 @@ func Login(
 									}
 
 									core.HTTPOpen(wnd.Navigation(), core.URI(uri), "_self")
-								}).Title("SSO").Frame(ui.Frame{}.FullWidth()),
+								}).Title("SSO").Frame(ui.Frame{}.FullWidth())),
+
+								ui.VStack(
+									ui.ForEach(externals, func(ext ExternalLogin) core.View {
+										return ui.SecondaryButton(func() {
+											uri, err := ext.Start(wnd.Session().ID())
+											if err != nil {
+												alert.ShowBannerError(wnd, err)
+												return
+											}
+
+											core.HTTPOpen(wnd.Navigation(), core.URI(uri), "_self")
+										}).Title(ext.Name).Frame(ui.Frame{}.FullWidth())
+									})...,
+								).FullWidth().Gap(ui.L8),
 							).FullWidth().Gap(ui.L8)
 						}),
 					).Gap(ui.L8),
@@ -236,3 +310,25 @@ func Login(
 		).Gap(ui.L16).Frame(ui.Frame{Width: ui.L320, Height: ""}), // "calc(100dvh - 7rem)"
 	).Frame(ui.Frame{}.MatchScreen())
 }
+
+// forwardAfterLogin navigates to the page given by the redirect query parameter, e.g. to continue a pending
+// OAuth authorization. Only relative paths of this application are accepted, otherwise the login page could be
+// abused as an open redirector.
+func forwardAfterLogin(wnd core.Window) {
+	path, values := redirectTarget(wnd.Values()["redirect"])
+	wnd.Navigation().ForwardTo(path, values)
+}
+
+func redirectTarget(redirect string) (core.NavigationPath, core.Values) {
+	u, err := url.Parse(redirect)
+	if redirect == "" || err != nil || u.Scheme != "" || u.Host != "" || u.User != nil || strings.HasPrefix(u.Path, "/") || strings.Contains(redirect, "\\") {
+		return ".", nil
+	}
+
+	values := core.Values{}
+	for k, v := range u.Query() {
+		values[k] = v[0]
+	}
+
+	return core.NavigationPath(u.Path), values
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package uisession
+
+import (
+	"strings"
+	"time"
+
+	"go.wdy.de/nago/application/session"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/presentation/core"
+	"go.wdy.de/nago/presentation/ui"
+	"go.wdy.de/nago/presentation/ui/alert"
+	"go.wdy.de/nago/presentation/ui/list"
+)
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "unbekannt"
+	}
+
+	return t.Local().Format("02.01.2006 15:04")
+}
+
+// deviceName returns a short human-readable description of the given user agent.
+func deviceName(userAgent string) string {
+	if userAgent == "" {
+		return "Unbekanntes Gerät"
+	}
+
+	ua := core.UserAgent(userAgent)
+	var browser string
+	switch {
+	case ua.IsEdge():
+		browser = "Edge"
+	case ua.IsChrome():
+		browser = "Chrome"
+	case ua.IsFirefox():
+		browser = "Firefox"
+	case ua.IsSafari():
+		browser = "Safari"
+	default:
+		browser = "Browser"
+	}
+
+	var system string
+	switch {
+	case strings.Contains(userAgent, "iPhone"), strings.Contains(userAgent, "iPad"):
+		system = "iOS"
+	case strings.Contains(userAgent, "Android"):
+		system = "Android"
+	case strings.Contains(userAgent, "Windows"):
+		system = "Windows"
+	case strings.Contains(userAgent, "Mac OS"):
+		system = "macOS"
+	case strings.Contains(userAgent, "Linux"):
+		system = "Linux"
+	}
+
+	if system == "" {
+		return browser
+	}
+
+	return browser + " auf " + system
+}
+
+// PageMyDevices lists all devices on which the current user is logged in and allows to log out each of them
+// remotely.
+func PageMyDevices(wnd core.Window, findUserSessions session.FindUserSessions, revoke session.Revoke, logoutUser session.LogoutUser) core.View {
+	if !wnd.Subject().Valid() {
+		return alert.BannerError(user.InvalidSubjectErr)
+	}
+
+	sessions, err := findUserSessions(wnd.Subject(), wnd.Subject().ID())
+	if err != nil {
+		return alert.BannerError(err)
+	}
+
+	logoutAllPresented := core.AutoState[bool](wnd)
+
+	return ui.VStack(
+		ui.H1("Meine Geräte"),
+		ui.Text("Auf diesen Geräten sind Sie derzeit angemeldet. Wenn Sie ein Gerät nicht kennen, melden Sie es ab und ändern Sie Ihr Kennwort."),
+		devicesList(wnd, sessions, revoke),
+		alert.Dialog("Überall abmelden", ui.Text("Sie werden auf allen Geräten abgemeldet, auch auf diesem."), logoutAllPresented,
+			alert.Cancel(nil),
+			alert.Custom(func(close func(closeDlg bool)) core.View {
+				return ui.PrimaryButton(func() {
+					if _, err := logoutUser(wnd.Subject(), wnd.Subject().ID()); err != nil {
+						alert.ShowBannerError(wnd, err)
+						return
+					}
+
+					close(true)
+				}).Title("Abmelden")
+			}),
+		),
+		ui.SecondaryButton(func() {
+			logoutAllPresented.Set(true)
+		}).Title("Überall abmelden"),
+	).Gap(ui.L20).
+		Alignment(ui.Leading).
+		Frame(ui.Frame{Width: ui.L560, MaxWidth: "100%"})
+}
+
+func devicesList(wnd core.Window, sessions []session.Session, revoke session.Revoke) core.View {
+	var entries []core.View
+	for _, ses := range sessions {
+		current := ses.ID == wnd.Session().ID()
+
+		headline := deviceName(ses.UserAgent)
+		if current {
+			headline += " (dieses Gerät)"
+		}
+
+		supportingText := "angemeldet am " + formatTime(ses.AuthenticatedAt) + ", zuletzt aktiv am " + formatTime(ses.LastActivity())
+		if ses.RemoteAddr != "" {
+			supportingText = "IP " + ses.RemoteAddr + ", " + supportingText
+		}
+
+		var trailing core.View
+		if !current {
+			trailing = ui.TertiaryButton(func() {
+				if err := revoke(wnd.Subject(), ses.ID); err != nil {
+					alert.ShowBannerError(wnd, err)
+					return
+				}
+
+				wnd.Navigation().Reload()
+			}).Title("Abmelden")
+		}
+
+		entries = append(entries, list.Entry().
+			Headline(headline).
+			SupportingText(supportingText).
+			Trailing(trailing))
+	}
+
+	return list.List(entries...).Frame(ui.Frame{}.FullWidth())
+}
@@ -13,4 +13,5 @@ type Pages struct {
 	Login          core.NavigationPath
 	Logout         core.NavigationPath
 	Authentication core.NavigationPath
+	MyDevices      core.NavigationPath
 }
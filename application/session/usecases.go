@@ -11,8 +11,10 @@ import (
 	"sync"
 	"time"
 
+	"go.wdy.de/nago/application/lockout"
 	"go.wdy.de/nago/application/settings"
 	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/auth"
 	"go.wdy.de/nago/pkg/data"
 	"go.wdy.de/nago/pkg/events"
 	"go.wdy.de/nago/pkg/std"
@@ -29,14 +31,59 @@ type FindUserSessionByID func(id ID) UserSession
 // Login authenticates a user by mail/password combination. The session is either hijacked or created.
 // Not sure, what this means security wise, but stealing a session by compromising the client
 // would always work.
-type Login func(id ID, login user.Email, password user.Password) (bool, error)
+//
+// If requiresSecondFactor reports true for the user, the session is not authenticated yet but remembers the user
+// as pending and [SecondFactorRequiredErr] is returned. The login must then be completed by [LoginSecondFactor].
+//
+// Failed attempts are counted per remoteAddr, which is the ip address of the client and may be empty if unknown.
+// The authenticator counts them per account, see [lockout.Check]. The account counter is only reset by a completed
+// login, thus a correct password does not forget the wrong codes of a pending second factor.
+type Login func(id ID, login user.Email, password user.Password, remoteAddr string) (bool, error)
+
+// RequiresSecondFactor decides whether a user must provide a second factor after a successful password check.
+type RequiresSecondFactor func(uid user.ID) (bool, error)
+
+// VerifySecondFactor checks the second factor of the given user, e.g. a TOTP code or a passkey assertion.
+type VerifySecondFactor func(uid user.ID) (bool, error)
+
+// LoginSecondFactor completes a pending login of the session, if verify succeeds for the pending user. A pending
+// login expires after a few minutes and afterward the session is treated as not logged in. The same happens after
+// a few failed verifications, so that a new password login is required.
+type LoginSecondFactor func(id ID, verify VerifySecondFactor) (bool, error)
+
+// PendingSecondFactor returns the user, which has passed the password check but still needs to provide the
+// second factor.
+type PendingSecondFactor func(id ID) (std.Option[user.ID], error)
 
 // LoginUser blindly accepts the given user id and marks the session as authenticated. There is no additional check,
 // if the given user really exists or if it is a valid user at all. Afterward, the session is treated as authenticated
 // and other mechanics apply, to keep up with the user state, see [user.SubjectFromUser] for details.
 type LoginUser func(id ID, usr user.ID) error
+
+// Logout removes the authentication from the session. All open windows of the session drop their subject, see
+// [LoggedOut].
 type Logout func(id ID) (bool, error)
 
+// Seen remembers the device and the time of the last use of an authenticated session. Writes are throttled, thus it
+// is fine to call this for each new window. Anonymous sessions are ignored.
+type Seen func(id ID, userAgent string, remoteAddr string) error
+
+// FindUserSessions returns all authenticated sessions of the given user, the most recently used first. A user
+// may always inspect its own sessions, otherwise [PermFindUserSessions] is required.
+type FindUserSessions func(subject auth.Subject, uid user.ID) ([]Session, error)
+
+// Revoke logs out the given session remotely. A user may always revoke its own sessions, otherwise [PermRevoke]
+// is required.
+type Revoke func(subject auth.Subject, id ID) error
+
+// LogoutUser logs out all sessions of the given user and returns how many sessions have been affected. A user
+// may always log out itself everywhere, otherwise [PermRevoke] is required.
+type LogoutUser func(subject auth.Subject, uid user.ID) (int, error)
+
+// Expire logs out all sessions which have exceeded their idle or absolute lifetime, see [Settings]. It is
+// invoked periodically by the [StartScheduler].
+type Expire func() error
+
 // Clear removes all entries from the session store and is only required for fixing session problems.
 type Clear func() error
 
@@ -105,27 +152,41 @@ type UseCases struct {
 	FindSessionByID     FindByID
 	FindUserSessionByID FindUserSessionByID
 	Login               Login
+	LoginSecondFactor   LoginSecondFactor
+	PendingSecondFactor PendingSecondFactor
 	LoginUser           LoginUser
 	Logout              Logout
+	Seen                Seen
+	FindUserSessions    FindUserSessions
+	Revoke              Revoke
+	LogoutUser          LogoutUser
+	Expire              Expire
 	Clear               Clear
 	StartNLSFlow        StartNLSFlow
 	ExchangeNLS         ExchangeNLS
 }
 
-func NewUseCases(bus events.Bus, defaultNLSRedirectURL string, loadGlobal settings.LoadGlobal, mergeSSO user.MergeSingleSignOnUser, repo Repository, nonceRepo NLSNonceRepository, authByPwd user.AuthenticateByPassword) UseCases {
+func NewUseCases(bus events.Bus, defaultNLSRedirectURL string, loadGlobal settings.LoadGlobal, mergeSSO user.MergeSingleSignOnUser, repo Repository, nonceRepo NLSNonceRepository, authByPwd user.AuthenticateByPassword, requiresSecondFactor RequiresSecondFactor, throttle lockout.UseCases) UseCases {
 	var mutex sync.Mutex
 
-	sessionByIdFn := NewFindByID(repo)
-	loginFn := NewLogin(bus, repo, authByPwd)
-	logoutFn := NewLogout(repo)
+	sessionByIdFn := NewFindByID(&mutex, bus, repo, loadGlobal)
+	loginFn := NewLogin(bus, repo, authByPwd, requiresSecondFactor, throttle)
+	logoutFn := NewLogout(&mutex, bus, repo)
 	refreshNLSFn := NewRefreshNLS(&mutex, bus, repo, loadGlobal, mergeSSO, logoutFn)
-	findUserSessionByIDFn := NewFindUserSessionByID(repo, refreshNLSFn)
+	findUserSessionByIDFn := NewFindUserSessionByID(bus, repo, refreshNLSFn)
 
 	return UseCases{
 		FindSessionByID:     sessionByIdFn,
 		Login:               loginFn,
+		LoginSecondFactor:   NewLoginSecondFactor(bus, repo, throttle),
+		PendingSecondFactor: NewPendingSecondFactor(repo),
 		LoginUser:           NewLoginUser(bus, repo),
 		Logout:              logoutFn,
+		Seen:                NewSeen(&mutex, repo),
+		FindUserSessions:    NewFindUserSessions(repo),
+		Revoke:              NewRevoke(&mutex, bus, repo),
+		LogoutUser:          NewLogoutUser(&mutex, bus, repo),
+		Expire:              NewExpire(&mutex, bus, repo, loadGlobal),
 		FindUserSessionByID: findUserSessionByIDFn,
 		Clear:               NewClear(&mutex, repo),
 		StartNLSFlow:        NewStartNLSFlow(&mutex, defaultNLSRedirectURL, nonceRepo, loadGlobal),
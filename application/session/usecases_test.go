@@ -0,0 +1,261 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package session
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"go.wdy.de/nago/application/lockout"
+	"go.wdy.de/nago/application/permission"
+	"go.wdy.de/nago/application/settings"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/pkg/blob/mem"
+	"go.wdy.de/nago/pkg/data/json"
+	"go.wdy.de/nago/pkg/events/eventstest"
+	"go.wdy.de/nago/pkg/std"
+)
+
+// testSubject implements just enough of the subject for the session use cases.
+type testSubject struct {
+	user.Subject
+	id    user.ID
+	perms []permission.ID
+}
+
+func (s testSubject) ID() user.ID {
+	return s.id
+}
+
+func (s testSubject) Valid() bool {
+	return s.id != ""
+}
+
+func (s testSubject) HasPermission(p permission.ID) bool {
+	return slices.Contains(s.perms, p)
+}
+
+func (s testSubject) Audit(p permission.ID) error {
+	if !s.Valid() {
+		return user.InvalidSubjectErr
+	}
+
+	if !s.HasPermission(p) {
+		return user.PermissionDeniedErr
+	}
+
+	return nil
+}
+
+func newTestRepo(t *testing.T, sessions ...Session) Repository {
+	t.Helper()
+
+	repo := json.NewSloppyJSONRepository[Session, ID](mem.NewBlobStore("sessions"))
+	for _, session := range sessions {
+		if err := repo.Save(session); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return repo
+}
+
+func testLoadGlobal(cfg Settings) settings.LoadGlobal {
+	return func(subject permission.Auditable, t reflect.Type) (settings.GlobalSettings, error) {
+		return cfg, nil
+	}
+}
+
+func authenticated(id ID, uid user.ID, at time.Time) Session {
+	return Session{ID: id, User: std.Some(uid), CreatedAt: at, AuthenticatedAt: at}
+}
+
+func TestExpire(t *testing.T) {
+	now := time.Now()
+	repo := newTestRepo(t,
+		authenticated("fresh", "alice", now.Add(-time.Minute)),
+		authenticated("idle", "alice", now.Add(-2*time.Hour)),
+		authenticated("old", "bob", now.Add(-48*time.Hour)),
+		Session{ID: "anon", CreatedAt: now.Add(-48 * time.Hour)},
+	)
+
+	// a session in use must not idle out, even though the login is older than the idle timeout
+	active := authenticated("active", "bob", now.Add(-2*time.Hour))
+	active.LastSeenAt = now.Add(-time.Minute)
+	if err := repo.Save(active); err != nil {
+		t.Fatal(err)
+	}
+
+	var bus eventstest.Recorder
+	var mutex sync.Mutex
+	expire := NewExpire(&mutex, &bus, repo, testLoadGlobal(Settings{IdleTimeout: time.Hour, MaxLifetime: 24 * time.Hour}))
+	if err := expire(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []ID{"fresh", "active"} {
+		optSession, err := repo.FindByID(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if optSession.Unwrap().User.IsNone() {
+			t.Fatalf("session %s must still be authenticated", id)
+		}
+	}
+
+	for _, id := range []ID{"idle", "old"} {
+		optSession, err := repo.FindByID(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if optSession.Unwrap().User.IsSome() {
+			t.Fatalf("session %s must have been logged out", id)
+		}
+	}
+
+	if got := len(eventstest.Of[LoggedOut](&bus)); got != 2 {
+		t.Fatalf("expected 2 logout events, got %d", got)
+	}
+}
+
+func TestFindByIDExpired(t *testing.T) {
+	repo := newTestRepo(t, authenticated("s1", "alice", time.Now().Add(-2*time.Hour)))
+
+	var bus eventstest.Recorder
+	var mutex sync.Mutex
+	findByID := NewFindByID(&mutex, &bus, repo, testLoadGlobal(Settings{MaxLifetime: time.Hour}))
+
+	optSession, err := findByID("s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if optSession.IsSome() {
+		t.Fatal("expired session must not be returned")
+	}
+
+	if evts := eventstest.Of[LoggedOut](&bus); len(evts) != 1 || evts[0].User != "alice" {
+		t.Fatalf("unexpected events: %v", evts)
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	now := time.Now()
+	repo := newTestRepo(t,
+		authenticated("a1", "alice", now),
+		authenticated("a2", "alice", now),
+		authenticated("b1", "bob", now),
+	)
+
+	var bus eventstest.Recorder
+	var mutex sync.Mutex
+	revoke := NewRevoke(&mutex, &bus, repo)
+	logoutUser := NewLogoutUser(&mutex, &bus, repo)
+	findUserSessions := NewFindUserSessions(repo)
+
+	alice := testSubject{id: "alice"}
+	admin := testSubject{id: "admin", perms: []permission.ID{PermRevoke, PermFindUserSessions}}
+
+	if err := revoke(alice, "b1"); !errors.Is(err, user.PermissionDeniedErr) {
+		t.Fatalf("alice must not revoke sessions of bob: %v", err)
+	}
+
+	if _, err := findUserSessions(alice, "bob"); !errors.Is(err, user.PermissionDeniedErr) {
+		t.Fatalf("alice must not see sessions of bob: %v", err)
+	}
+
+	if err := revoke(alice, "a2"); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions, err := findUserSessions(alice, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sessions) != 1 || sessions[0].ID != "a1" {
+		t.Fatalf("unexpected sessions: %v", sessions)
+	}
+
+	if _, err := logoutUser(alice, "bob"); !errors.Is(err, user.PermissionDeniedErr) {
+		t.Fatalf("alice must not log out bob: %v", err)
+	}
+
+	count, err := logoutUser(admin, "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count != 1 {
+		t.Fatalf("expected 1 session of bob, got %d", count)
+	}
+
+	want := []LoggedOut{{Session: "a2", User: "alice"}, {Session: "b1", User: "bob"}}
+	if got := eventstest.Of[LoggedOut](&bus); !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestSeen(t *testing.T) {
+	repo := newTestRepo(t, authenticated("s1", "alice", time.Now()), Session{ID: "anon"})
+
+	var mutex sync.Mutex
+	seen := NewSeen(&mutex, repo)
+
+	if err := seen("s1", "Mozilla/5.0 (X11; Linux x86_64) Firefox/140.0", "192.0.2.1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := seen("anon", "Mozilla/5.0", "192.0.2.2"); err != nil {
+		t.Fatal(err)
+	}
+
+	session := std.Must(repo.FindByID("s1")).Unwrap()
+	if session.RemoteAddr != "192.0.2.1" || session.LastSeenAt.IsZero() {
+		t.Fatalf("device not recorded: %+v", session)
+	}
+
+	if anon := std.Must(repo.FindByID("anon")).Unwrap(); anon.RemoteAddr != "" {
+		t.Fatalf("anonymous sessions must not be tracked: %+v", anon)
+	}
+}
+
+func TestLoginSecondFactorDropsPendingLogin(t *testing.T) {
+	repo := newTestRepo(t, Session{ID: "s1", PendingUser: std.Some[user.ID]("alice"), PendingSince: time.Now()})
+
+	attempts := json.NewSloppyJSONRepository[lockout.Attempts, lockout.Key](mem.NewBlobStore("lockout"))
+	throttle := lockout.NewUseCases(&eventstest.Recorder{}, attempts, func(subject permission.Auditable, t reflect.Type) (settings.GlobalSettings, error) {
+		return lockout.Settings{}, nil
+	})
+
+	var bus eventstest.Recorder
+	login := NewLoginSecondFactor(&bus, repo, throttle)
+
+	wrong := func(uid user.ID) (bool, error) { return false, nil }
+	for range maxPendingFailures {
+		if ok, err := login("s1", wrong); ok || err != nil {
+			t.Fatalf("expected wrong code to be rejected, got %v %v", ok, err)
+		}
+	}
+
+	// security note: even the correct code must be rejected, a new password login is required
+	correct := func(uid user.ID) (bool, error) { return true, nil }
+	if ok, err := login("s1", correct); ok || !errors.Is(err, NotLoggedInErr) {
+		t.Fatalf("expected dropped login, got %v %v", ok, err)
+	}
+
+	if optSession, _ := repo.FindByID("s1"); optSession.Unwrap().User.IsSome() {
+		t.Fatal("session must not be authenticated")
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"go.wdy.de/nago/application/admin"
 	"go.wdy.de/nago/application/localization/rstring"
 	"go.wdy.de/nago/application/sms"
+	smshttp "go.wdy.de/nago/application/sms/http"
 	"go.wdy.de/nago/application/sms/message"
 	uisms "go.wdy.de/nago/application/sms/ui"
 	"go.wdy.de/nago/auth"
@@ -51,6 +52,8 @@ func Enable(cfg *application.Configurator) (Management, error) {
 
 	ucSMS := sms.NewUseCases(cfg.Context(), cfg.EventBus(), secrets.UseCases.FindGroupSecrets, repoSMS)
 
+	cfg.HandleFunc(smshttp.Endpoint, smshttp.NewHandler(ucSMS.FindProviderByID, ucSMS.ProcessCallback))
+
 	management = Management{
 		UseCases: ucSMS,
 		Pages: uisms.Pages{
@@ -0,0 +1,66 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+// Package smshttp receives the delivery reports and inbound messages of those providers, which implement
+// [provider.Webhook]. The endpoint is public, thus each provider must authenticate the request itself.
+package smshttp
+
+import (
+	"log/slog"
+	"net/http"
+
+	"go.wdy.de/nago/application/sms"
+	"go.wdy.de/nago/application/sms/provider"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/pkg/xhttp"
+)
+
+const Endpoint = "/api/nago/v1/sms/webhook"
+
+func NewHandler(findProvider sms.FindProviderByID, processCallback sms.ProcessCallback) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodPost {
+			http.Error(w, "", http.StatusMethodNotAllowed)
+			return
+		}
+
+		id := provider.ID(r.URL.Query().Get("p"))
+		optProv, err := findProvider(user.SU(), id)
+		if err != nil {
+			slog.Error("failed to find sms provider", "id", id, "err", err.Error())
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+
+		if optProv.IsNone() {
+			http.Error(w, "", http.StatusNotFound)
+			return
+		}
+
+		webhook, ok := optProv.Unwrap().(provider.Webhook)
+		if !ok {
+			http.Error(w, "", http.StatusNotFound)
+			return
+		}
+
+		cb, err := webhook.ParseWebhook(r)
+		if err != nil {
+			// do not reveal any details to the public
+			slog.Warn("rejected sms webhook request", "id", id, "remote", xhttp.RemoteIP(r), "err", err.Error())
+			http.Error(w, "", http.StatusBadRequest)
+			return
+		}
+
+		if err := processCallback(user.SU(), id, cb); err != nil {
+			slog.Error("failed to process sms webhook", "id", id, "err", err.Error())
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -26,7 +26,12 @@ func loop(ctx context.Context, repo message.Repository, sendFn Send) {
 				continue
 			}
 
-			if sms.Status == message.StatusSent {
+			if sms.Status == message.StatusUndelivered {
+				// the provider has accepted the message, thus sending it again would just cause costs
+				continue
+			}
+
+			if sms.Status == message.StatusSent || sms.Status == message.StatusDelivered {
 				// we retain sms history 7 days for successful messages, others are kept infinite
 				retentionDuration := time.Hour * 24 * 7
 				retain := time.UnixMilli(int64(sms.SendAt)).Add(retentionDuration).After(time.Now())
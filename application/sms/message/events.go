@@ -7,6 +7,8 @@
 
 package message
 
+import "go.wdy.de/nago/pkg/xtime"
+
 type SendRequested struct {
 	// ProviderHint allows to narrow the wanted provider, e.g. for specific originator signatures or credit accounts.
 	// The hint is matched against the [provider.provider.Name] or [provider.provider.ID]. Note that providers
@@ -24,3 +26,27 @@ type SendRequested struct {
 	// a longer body may be split and send using 2-10 distinct SMS. Note, that each one causes additional costs.
 	Body string `json:"body,omitempty"`
 }
+
+// DeliveryReported is published, after the status of a sent message has been updated by a delivery report
+// (DLR) of its provider.
+type DeliveryReported struct {
+	ID        ID     `json:"id"`
+	Recipient MSISDN `json:"recipient,omitempty"`
+	Status    Status `json:"status"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Received is published for each inbound message, e.g. the answer to a confirmation request.
+type Received struct {
+	// Provider is the identity of the provider, which has received the message.
+	Provider string `json:"provider,omitempty"`
+	// ProviderMessage is the id assigned by the provider, if any.
+	ProviderMessage ID `json:"providerMessage,omitempty"`
+	// Sender of the message.
+	Sender MSISDN `json:"sender,omitempty"`
+	// Recipient is the number or short code of the gateway, which may be shared by multiple applications
+	// using a keyword.
+	Recipient  string                 `json:"recipient,omitempty"`
+	Body       string                 `json:"body,omitempty"`
+	ReceivedAt xtime.UnixMilliseconds `json:"receivedAt,omitempty"`
+}
@@ -44,6 +44,9 @@ type SMS struct {
 
 	CreatedAt xtime.UnixMilliseconds `json:"createdAt,omitempty"`
 	SendAt    xtime.UnixMilliseconds `json:"sendAt,omitempty"`
+
+	// ReportedAt is the time of the last delivery report of the provider, if any.
+	ReportedAt xtime.UnixMilliseconds `json:"reportedAt,omitempty"`
 }
 
 // MSISDN is a special number format like 49179555111XXX.
@@ -76,6 +79,11 @@ const (
 	StatusQueued Status = "queued"
 	StatusSent   Status = "sent"
 	StatusFailed Status = "failed"
+	// StatusDelivered is reported by the provider, after the message has reached the handset.
+	StatusDelivered Status = "delivered"
+	// StatusUndelivered is reported by the provider, if the message has been sent but could not be delivered,
+	// e.g. because the number does not exist or the validity period has expired.
+	StatusUndelivered Status = "undelivered"
 )
 
 func (s SMS) Identity() ID {
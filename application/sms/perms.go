@@ -15,4 +15,6 @@ var (
 	PermFindAllMessageIDs = permission.DeclareFindAllIdentifiers[FindAllMessageIDs]("nago.sms.provider.find_all_idents", "SMS")
 	PermFindByID          = permission.DeclareFindByID[FindMessageByID]("nago.sms.provider.find_by_id", "SMS")
 	PermDeleteMessageByID = permission.DeclareDeleteByID[DeleteMessageByID]("nago.sms.provider.delete_by_id", "SMS")
+	PermFindProviderByID  = permission.DeclareFindByID[FindProviderByID]("nago.sms.provider.find_provider_by_id", "SMS Provider")
+	PermProcessCallback   = permission.Declare[ProcessCallback]("nago.sms.provider.process_callback", "SMS Rückmeldungen verarbeiten", "Träger dieser Berechtigung können Zustellberichte und eingehende SMS eines Providers verarbeiten lassen.")
 )
@@ -0,0 +1,301 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package gateway
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"go.wdy.de/nago/application/sms/message"
+	"go.wdy.de/nago/application/sms/provider"
+	"go.wdy.de/nago/auth"
+	"go.wdy.de/nago/pkg/xhttp"
+	"go.wdy.de/nago/pkg/xtime"
+)
+
+var _ provider.Provider = (*Provider)(nil)
+var _ provider.Webhook = (*Provider)(nil)
+
+// maxBody limits the size of gateway responses and of the public webhook requests.
+const maxBody = 64 * 1024
+
+var funcs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		buf, err := json.Marshal(v)
+		return string(buf), err
+	},
+}
+
+// templateData is passed to the templates of the [Settings].
+type templateData struct {
+	Recipient  string
+	Originator string
+	Body       string
+	Token      string
+}
+
+type Provider struct {
+	id       provider.ID
+	settings Settings
+	cl       *http.Client
+	group    *xhttp.RequestGroup
+}
+
+func NewProvider(id provider.ID, settings Settings) *Provider {
+	grp := xhttp.NewRequestGroup()
+	if settings.RPS > 0 {
+		grp = grp.RateLimit(settings.RPS)
+	}
+
+	return &Provider{
+		id:       id,
+		settings: settings,
+		cl:       &http.Client{Timeout: 30 * time.Second},
+		group:    grp,
+	}
+}
+
+func (p *Provider) Send(subject auth.Subject, sms message.SendRequested) (message.ID, error) {
+	data := templateData{
+		Recipient:  sms.Recipient.String(),
+		Originator: string(sms.Originator),
+		Body:       sms.Body,
+		Token:      p.settings.Token,
+	}
+
+	reqURL, err := execute("url", p.settings.URL, data)
+	if err != nil {
+		return "", err
+	}
+
+	header, err := execute("header", p.settings.Header, data)
+	if err != nil {
+		return "", err
+	}
+
+	body, err := execute("body", p.settings.Body, data)
+	if err != nil {
+		return "", err
+	}
+
+	req := xhttp.NewRequest().
+		Client(p.cl).
+		Group(p.group).
+		URL(reqURL).
+		Assert2xx(true).
+		ToLimit(maxBody)
+
+	for _, line := range strings.Split(header, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(key) == "" {
+			continue
+		}
+
+		req.Header(strings.TrimSpace(key), strings.TrimSpace(value))
+	}
+
+	if body != "" {
+		req.Header("Content-Type", p.settings.ContentType)
+		req.Body(func() (io.Reader, error) {
+			return strings.NewReader(body), nil
+		})
+	}
+
+	var res []byte
+	req.To(func(r io.Reader) error {
+		res, err = io.ReadAll(r)
+		return err
+	})
+
+	if err := req.Do(p.settings.method()); err != nil {
+		return "", err
+	}
+
+	if strings.TrimSpace(p.settings.MessageID) == "" {
+		return "", nil
+	}
+
+	id, err := lookup(res, p.settings.MessageID)
+	if err != nil {
+		return "", fmt.Errorf("cannot find message id in gateway response: %w", err)
+	}
+
+	return message.ID(id), nil
+}
+
+// ParseWebhook accepts delivery reports and inbound messages as query, form or flat JSON object.
+func (p *Provider) ParseWebhook(r *http.Request) (provider.Callback, error) {
+	token := r.URL.Query().Get("token")
+	if p.settings.WebhookToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(p.settings.WebhookToken)) != 1 {
+		return provider.Callback{}, fmt.Errorf("invalid webhook token")
+	}
+
+	fields, err := webhookFields(r)
+	if err != nil {
+		return provider.Callback{}, err
+	}
+
+	var cb provider.Callback
+	if status, ok := fields[p.settings.ReportStatus]; ok && p.settings.ReportStatus != "" {
+		delivered, final := p.settings.status(status)
+		if !final {
+			return cb, nil
+		}
+
+		report := provider.DeliveryReport{
+			ProviderMessage: message.ID(fields[p.settings.ReportID]),
+			Status:          message.StatusUndelivered,
+			Error:           fields[p.settings.ReportError],
+		}
+
+		if delivered {
+			report.Status = message.StatusDelivered
+			report.Error = ""
+		} else if report.Error == "" {
+			report.Error = status
+		}
+
+		if report.ProviderMessage == "" {
+			return cb, fmt.Errorf("delivery report without message id")
+		}
+
+		cb.Reports = append(cb.Reports, report)
+		return cb, nil
+	}
+
+	if body, ok := fields[p.settings.InboundBody]; ok && p.settings.InboundBody != "" {
+		sender, err := message.NewMSISDN(fields[p.settings.InboundSender])
+		if err != nil {
+			return cb, fmt.Errorf("invalid sender of inbound message: %w", err)
+		}
+
+		cb.Received = append(cb.Received, message.Received{
+			Provider:   string(p.id),
+			Sender:     sender,
+			Recipient:  fields[p.settings.InboundRecipient],
+			Body:       body,
+			ReceivedAt: xtime.Now(),
+		})
+
+		return cb, nil
+	}
+
+	return cb, fmt.Errorf("webhook request is neither a delivery report nor an inbound message")
+}
+
+func (p *Provider) Name() string {
+	return p.settings.Name
+}
+
+func (p *Provider) Identity() provider.ID {
+	return p.id
+}
+
+func execute(name, text string, data templateData) (string, error) {
+	tpl, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("cannot execute %s template: %w", name, err)
+	}
+
+	return buf.String(), nil
+}
+
+// lookup follows the dot separated path through the JSON document. Numeric segments index arrays.
+func lookup(doc []byte, path string) (string, error) {
+	var v any
+	if err := json.Unmarshal(doc, &v); err != nil {
+		return "", err
+	}
+
+	for _, segment := range strings.Split(strings.TrimSpace(path), ".") {
+		switch node := v.(type) {
+		case map[string]any:
+			v = node[segment]
+		case []any:
+			idx, err := strconv.Atoi(segment)
+			if err != nil || idx < 0 || idx >= len(node) {
+				return "", fmt.Errorf("invalid index %s", segment)
+			}
+
+			v = node[idx]
+		default:
+			return "", fmt.Errorf("cannot resolve %s", segment)
+		}
+	}
+
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	default:
+		return "", fmt.Errorf("no value at %s", path)
+	}
+}
+
+// webhookFields merges the query with the form or the flat JSON object of the body.
+func webhookFields(r *http.Request) (map[string]string, error) {
+	fields := map[string]string{}
+	for key, values := range r.URL.Query() {
+		fields[key] = values[0]
+	}
+
+	if r.Body == nil {
+		return fields, nil
+	}
+
+	buf, err := io.ReadAll(io.LimitReader(r.Body, maxBody))
+	if err != nil {
+		return nil, err
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		r.Body = io.NopCloser(bytes.NewReader(buf))
+		if err := r.ParseForm(); err != nil {
+			return nil, err
+		}
+
+		for key, values := range r.PostForm {
+			fields[key] = values[0]
+		}
+	case strings.HasSuffix(mediaType, "json"):
+		var obj map[string]any
+		if err := json.Unmarshal(buf, &obj); err != nil {
+			return nil, fmt.Errorf("invalid json webhook body: %w", err)
+		}
+
+		for key, value := range obj {
+			switch value := value.(type) {
+			case string:
+				fields[key] = value
+			case float64:
+				fields[key] = strconv.FormatFloat(value, 'f', -1, 64)
+			case bool:
+				fields[key] = strconv.FormatBool(value)
+			}
+		}
+	}
+
+	return fields, nil
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package gateway
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"go.wdy.de/nago/application/sms/message"
+	"go.wdy.de/nago/application/user"
+)
+
+func testSettings() Settings {
+	return Settings{
+		Name:              "gateway",
+		Method:            "POST",
+		Header:            "Authorization: Bearer {{.Token}}\nX-Custom: {{.Originator}}",
+		ContentType:       "application/json",
+		Body:              `{"to": {{json .Recipient}}, "text": {{json .Body}}}`,
+		Token:             "api-token",
+		MessageID:         "messages.0.id",
+		WebhookToken:      "hook-token",
+		ReportID:          "id",
+		ReportStatus:      "status",
+		ReportError:       "error",
+		DeliveredValues:   "delivered DELIVRD",
+		UndeliveredValues: "undelivered failed",
+		InboundSender:     "from",
+		InboundRecipient:  "to",
+		InboundBody:       "text",
+	}
+}
+
+func TestProviderSend(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer api-token" || r.Header.Get("X-Custom") != "Nago" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if body["to"] != "49179555111" || body["text"] != `Sag "Ja"` {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		_, _ = io.WriteString(w, `{"messages": [{"id": 4711}]}`)
+	}))
+	defer srv.Close()
+
+	settings := testSettings()
+	settings.URL = srv.URL + "/send"
+	prov := NewProvider("gw", settings)
+
+	id, err := prov.Send(user.SU(), message.SendRequested{Recipient: 49179555111, Originator: "Nago", Body: `Sag "Ja"`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id != "4711" {
+		t.Fatalf("unexpected id: %s", id)
+	}
+}
+
+func TestProviderParseWebhook(t *testing.T) {
+	prov := NewProvider("gw", testSettings())
+
+	r := httptest.NewRequest(http.MethodPost, "/?token=hook-token", strings.NewReader(url.Values{"id": {"4711"}, "status": {"FAILED"}}.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	cb, err := prov.ParseWebhook(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(cb.Reports) != 1 || cb.Reports[0].ProviderMessage != "4711" || cb.Reports[0].Status != message.StatusUndelivered || cb.Reports[0].Error != "FAILED" {
+		t.Fatalf("unexpected callback: %+v", cb)
+	}
+
+	// intermediate states are ignored
+	r = httptest.NewRequest(http.MethodGet, "/?token=hook-token&id=4711&status=buffered", nil)
+	cb, err = prov.ParseWebhook(r)
+	if err != nil || len(cb.Reports) != 0 {
+		t.Fatalf("unexpected callback: %+v %v", cb, err)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/?token=hook-token", strings.NewReader(`{"from": "+49 179 555222", "to": "4930123", "text": "JA"}`))
+	r.Header.Set("Content-Type", "application/json")
+	cb, err = prov.ParseWebhook(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(cb.Received) != 1 || cb.Received[0].Sender != 49179555222 || cb.Received[0].Body != "JA" || cb.Received[0].Provider != "gw" {
+		t.Fatalf("unexpected callback: %+v", cb)
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/?token=wrong&id=4711&status=delivered", nil)
+	if _, err := prov.ParseWebhook(r); err == nil {
+		t.Fatal("expected error for invalid token")
+	}
+}
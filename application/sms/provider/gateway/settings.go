@@ -0,0 +1,81 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package gateway
+
+import (
+	"strings"
+
+	"github.com/worldiety/enum"
+	"go.wdy.de/nago/application/secret"
+)
+
+var _ = enum.Variant[secret.Credentials, Settings](enum.Rename[Settings]("nago.sms.gateway.settings"))
+
+// Settings describe an arbitrary HTTP gateway. The URL, Header and Body are templates in the syntax of
+// text/template, which are executed with the fields Recipient, Originator, Body and Token. The function json
+// quotes a value as JSON string and urlquery escapes it for a query.
+type Settings struct {
+	Name        string `json:"name" value:"Mein SMS Gateway" label:"Name"`
+	URL         string `json:"url" label:"URL" supportingText:"Die Adresse der Versand-API, z.B. https://gateway.example.com/send?to={{.Recipient}}&text={{urlquery .Body}}."`
+	Method      string `json:"method" value:"POST" label:"Methode"`
+	Header      string `json:"header" lines:"3" value:"Authorization: Bearer {{.Token}}" label:"Header" supportingText:"Ein Header pro Zeile im Format Name: Wert."`
+	ContentType string `json:"contentType" value:"application/json" label:"Content-Type"`
+	Body        string `json:"body" lines:"5" value:"{\"to\": {{json .Recipient}}, \"from\": {{json .Originator}}, \"text\": {{json .Body}}}" label:"Body" supportingText:"Bei GET bleibt der Body leer."`
+	Token       string `json:"token" style:"secret" label:"API Token" supportingText:"Wird über {{.Token}} in die Vorlagen eingesetzt, damit er nicht im Klartext in der Vorlage steht."`
+	MessageID   string `json:"messageID" value:"id" label:"Pfad der Nachrichten-ID" supportingText:"Der durch Punkte getrennte Pfad zur ID in der JSON Antwort, z.B. messages.0.id. Ohne ID können keine Zustellberichte zugeordnet werden."`
+	RPS         int    `json:"rps" label:"Anfragen pro Sekunde" supportingText:"0 bedeutet unbegrenzt."`
+
+	WebhookToken      string `json:"webhookToken" style:"secret" label:"Webhook Token" supportingText:"Ohne Token ist der Webhook deaktiviert. Das Gateway muss Zustellberichte und eingehende Nachrichten an <App-URL>/api/nago/v1/sms/webhook?p=<Secret-ID>&token=<Webhook Token> senden, entweder als Query, Formular oder flaches JSON Objekt."`
+	ReportID          string `json:"reportID" value:"id" label:"Feld der Nachrichten-ID" supportingText:"Das Feld eines Zustellberichts mit der ID der versendeten Nachricht."`
+	ReportStatus      string `json:"reportStatus" value:"status" label:"Feld des Status" supportingText:"Nur Anfragen mit diesem Feld werden als Zustellbericht behandelt."`
+	ReportError       string `json:"reportError" value:"error" label:"Feld der Fehlerbeschreibung"`
+	DeliveredValues   string `json:"deliveredValues" value:"delivered DELIVRD" label:"Werte für zugestellt" supportingText:"Durch Leerzeichen getrennt, ohne Beachtung der Groß- und Kleinschreibung."`
+	UndeliveredValues string `json:"undeliveredValues" value:"undelivered failed rejected expired UNDELIV REJECTD EXPIRED" label:"Werte für unzustellbar" supportingText:"Durch Leerzeichen getrennt. Alle anderen Werte gelten als Zwischenstand und werden ignoriert."`
+	InboundSender     string `json:"inboundSender" value:"from" label:"Feld des Absenders" supportingText:"Das Feld einer eingehenden Nachricht mit der Nummer des Absenders."`
+	InboundRecipient  string `json:"inboundRecipient" value:"to" label:"Feld des Empfängers"`
+	InboundBody       string `json:"inboundBody" value:"text" label:"Feld des Textes" supportingText:"Nur Anfragen mit diesem Feld werden als eingehende Nachricht behandelt."`
+
+	_ struct{} `credentialName:"SMS HTTP Gateway" credentialDescription:"Anbindung eines beliebigen SMS Gateways über frei konfigurierbare HTTP Anfragen und Webhooks." credentialLogo:"https://www.w3.org/favicon.ico"`
+}
+
+func (s Settings) GetName() string {
+	return s.Name
+}
+
+func (s Settings) Credentials() bool {
+	return true
+}
+
+func (s Settings) IsZero() bool {
+	return s == Settings{}
+}
+
+func (s Settings) method() string {
+	if m := strings.TrimSpace(s.Method); m != "" {
+		return strings.ToUpper(m)
+	}
+
+	return "POST"
+}
+
+// status maps the reported value to a final status. Intermediate values return false.
+func (s Settings) status(value string) (delivered bool, final bool) {
+	for _, v := range strings.Fields(s.DeliveredValues) {
+		if strings.EqualFold(v, value) {
+			return true, true
+		}
+	}
+
+	for _, v := range strings.Fields(s.UndeliveredValues) {
+		if strings.EqualFold(v, value) {
+			return false, true
+		}
+	}
+
+	return false, false
+}
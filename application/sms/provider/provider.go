@@ -8,6 +8,8 @@
 package provider
 
 import (
+	"net/http"
+
 	"go.wdy.de/nago/application/sms/message"
 	"go.wdy.de/nago/auth"
 )
@@ -19,3 +21,30 @@ type Provider interface {
 	Name() string
 	Identity() ID
 }
+
+// DeliveryReport is the delivery status of a previously sent message.
+type DeliveryReport struct {
+	// ProviderMessage is the id, which has been returned by [Provider.Send].
+	ProviderMessage message.ID
+	// Status is either [message.StatusDelivered] or [message.StatusUndelivered].
+	Status message.Status
+	// Error describes, why a message is undelivered.
+	Error string
+}
+
+// Callback contains the delivery reports and inbound messages, which have been received by a provider at
+// once.
+type Callback struct {
+	Reports  []DeliveryReport
+	Received []message.Received
+}
+
+// Notify is passed to providers, which receive callbacks asynchronously, e.g. over a bound SMPP session.
+type Notify func(cb Callback)
+
+// Webhook is optionally implemented by a [Provider], whose gateway posts delivery reports or inbound
+// messages to an HTTP endpoint. The request must be authenticated by the provider itself, e.g. using a
+// shared token, because the endpoint is public.
+type Webhook interface {
+	ParseWebhook(r *http.Request) (Callback, error)
+}
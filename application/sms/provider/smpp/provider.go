@@ -0,0 +1,194 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package smpp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+
+	"go.wdy.de/nago/application/sms/message"
+	"go.wdy.de/nago/application/sms/provider"
+	"go.wdy.de/nago/auth"
+	smppclient "go.wdy.de/nago/pkg/smpp"
+	"go.wdy.de/nago/pkg/xtime"
+)
+
+var _ provider.Provider = (*Provider)(nil)
+
+// reconnectDelay is the pause after a failed or lost session.
+const reconnectDelay = 30 * time.Second
+
+// Provider keeps a transceiver session bound in the background, so that delivery receipts and inbound messages
+// are also received, if nothing is sent. It must be closed, if the settings change.
+type Provider struct {
+	id       provider.ID
+	settings Settings
+	notify   provider.Notify
+	cancel   context.CancelFunc
+
+	mutex  sync.Mutex
+	conn   *smppclient.Conn
+	closed bool
+}
+
+func NewProvider(id provider.ID, settings Settings, notify provider.Notify) *Provider {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Provider{id: id, settings: settings, notify: notify, cancel: cancel}
+	go p.run(ctx)
+
+	return p
+}
+
+func (p *Provider) Send(subject auth.Subject, sms message.SendRequested) (message.ID, error) {
+	conn, err := p.session(context.Background())
+	if err != nil {
+		return "", err
+	}
+
+	id, err := conn.Submit(smppclient.ShortMessage{
+		Source:             string(sms.Originator),
+		Destination:        sms.Recipient.String(),
+		Text:               sms.Body,
+		RegisteredDelivery: p.settings.DeliveryReports,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return message.ID(id), nil
+}
+
+func (p *Provider) Name() string {
+	return p.settings.Name
+}
+
+func (p *Provider) Identity() provider.ID {
+	return p.id
+}
+
+// Close unbinds the session and stops reconnecting.
+func (p *Provider) Close() error {
+	p.cancel()
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.closed = true
+	if p.conn == nil {
+		return nil
+	}
+
+	_ = p.conn.Unbind()
+	err := p.conn.Close()
+	p.conn = nil
+	return err
+}
+
+func (p *Provider) run(ctx context.Context) {
+	for ctx.Err() == nil {
+		conn, err := p.session(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Error("cannot bind smpp session", "provider", p.id, "err", err.Error())
+			}
+		} else {
+			select {
+			case <-ctx.Done():
+				return
+			case <-conn.Done():
+				slog.Warn("smpp session lost", "provider", p.id, "err", conn.Err())
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+// session returns the bound session or binds a new one.
+func (p *Provider) session(ctx context.Context) (*smppclient.Conn, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.closed {
+		return nil, net.ErrClosed
+	}
+
+	if p.conn != nil {
+		select {
+		case <-p.conn.Done():
+			p.conn = nil
+		default:
+			return p.conn, nil
+		}
+	}
+
+	tlsConfig, err := p.settings.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := smppclient.Dial(ctx, p.settings.URL, smppclient.Options{TLSConfig: tlsConfig, OnDeliver: p.deliver})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn.BindTransceiver(p.settings.SystemID, p.settings.Password, p.settings.SystemType); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("cannot bind: %w", err)
+	}
+
+	p.conn = conn
+	return conn, nil
+}
+
+func (p *Provider) deliver(d smppclient.Deliver) {
+	if p.notify == nil {
+		return
+	}
+
+	if d.Receipt != nil {
+		if !d.Receipt.Final() {
+			return
+		}
+
+		report := provider.DeliveryReport{
+			ProviderMessage: message.ID(d.Receipt.MessageID),
+			Status:          message.StatusDelivered,
+		}
+
+		if !d.Receipt.Delivered() {
+			report.Status = message.StatusUndelivered
+			report.Error = fmt.Sprintf("%s (err %s)", d.Receipt.Stat, d.Receipt.Err)
+		}
+
+		p.notify(provider.Callback{Reports: []provider.DeliveryReport{report}})
+		return
+	}
+
+	sender, err := message.NewMSISDN(d.Source)
+	if err != nil {
+		slog.Error("ignored inbound smpp message with invalid sender", "provider", p.id, "sender", d.Source)
+		return
+	}
+
+	p.notify(provider.Callback{Received: []message.Received{{
+		Provider:   string(p.id),
+		Sender:     sender,
+		Recipient:  d.Destination,
+		Body:       d.Text,
+		ReceivedAt: xtime.Now(),
+	}}})
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package smpp
+
+import (
+	"testing"
+	"time"
+
+	"go.wdy.de/nago/application/sms/message"
+	"go.wdy.de/nago/application/sms/provider"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/pkg/smpp/smpptest"
+)
+
+func TestProvider(t *testing.T) {
+	srv, err := smpptest.NewServer("nago", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer srv.Close()
+
+	callbacks := make(chan provider.Callback, 2)
+	prov := NewProvider("smsc", Settings{URL: srv.URL(), SystemID: "nago", Password: "secret", DeliveryReports: true}, func(cb provider.Callback) {
+		callbacks <- cb
+	})
+
+	defer prov.Close()
+
+	id, err := prov.Send(user.SU(), message.SendRequested{Recipient: 49179555111, Originator: "Nago", Body: "Ihr Code: 1234"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	submitted := srv.Submitted()
+	if len(submitted) != 1 || string(id) != submitted[0].MessageID || !submitted[0].ShortMessage.RegisteredDelivery {
+		t.Fatalf("unexpected submission: %v", submitted)
+	}
+
+	if err := srv.DeliverReceipt(submitted[0].MessageID, "UNDELIV"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := srv.Deliver("49179555111", "Nago", "STOP"); err != nil {
+		t.Fatal(err)
+	}
+
+	cb := receive(t, callbacks)
+	if len(cb.Reports) != 1 || cb.Reports[0].ProviderMessage != id || cb.Reports[0].Status != message.StatusUndelivered {
+		t.Fatalf("unexpected callback: %+v", cb)
+	}
+
+	cb = receive(t, callbacks)
+	if len(cb.Received) != 1 || cb.Received[0].Sender != 49179555111 || cb.Received[0].Body != "STOP" || cb.Received[0].Provider != "smsc" {
+		t.Fatalf("unexpected callback: %+v", cb)
+	}
+}
+
+func receive(t *testing.T, ch chan provider.Callback) provider.Callback {
+	t.Helper()
+
+	select {
+	case cb := <-ch:
+		return cb
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+		return provider.Callback{}
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package smpp
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"strings"
+
+	"github.com/worldiety/enum"
+	"go.wdy.de/nago/application/secret"
+)
+
+var _ = enum.Variant[secret.Credentials, Settings](enum.Rename[Settings]("nago.sms.smpp.settings"))
+
+// Settings configure the connection to an SMSC, which is kept bound as transceiver to receive delivery
+// receipts and inbound messages.
+type Settings struct {
+	Name            string   `json:"name" value:"Mein SMPP Zugang" label:"Name"`
+	URL             string   `json:"url" label:"Server URL" supportingText:"Z.B. smpps://smsc.example.com:3550 oder smpp://smsc.example.com:2775. Ohne TLS werden Passwort und Nachrichten im Klartext übertragen."`
+	RootCA          string   `json:"rootCA" lines:"5" label:"CA Zertifikat" supportingText:"PEM-kodiertes Zertifikat der internen CA, falls der Server kein öffentlich vertrauenswürdiges Zertifikat verwendet."`
+	SystemID        string   `json:"systemID" label:"System ID"`
+	Password        string   `json:"password" style:"secret" label:"Passwort"`
+	SystemType      string   `json:"systemType" label:"System Type" supportingText:"Nur angeben, wenn der Anbieter es verlangt."`
+	DeliveryReports bool     `json:"deliveryReports" label:"Zustellberichte anfordern" supportingText:"Der Anbieter meldet den Zustellstatus jeder Nachricht, was je nach Vertrag zusätzliche Kosten verursachen kann."`
+	_               struct{} `credentialName:"SMPP" credentialDescription:"Anbindung eines SMS Centers über das SMPP 3.4 Protokoll für Versand, Zustellberichte und eingehende Nachrichten." credentialLogo:"https://smpp.org/favicon.ico"`
+}
+
+func (s Settings) GetName() string {
+	return s.Name
+}
+
+func (s Settings) Credentials() bool {
+	return true
+}
+
+func (s Settings) IsZero() bool {
+	return s == Settings{}
+}
+
+func (s Settings) tlsConfig() (*tls.Config, error) {
+	if strings.TrimSpace(s.RootCA) == "" {
+		return nil, nil
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM([]byte(s.RootCA)) {
+		return nil, fmt.Errorf("the CA certificate of %s is not a valid PEM certificate", s.Name)
+	}
+
+	return &tls.Config{RootCAs: pool}, nil
+}
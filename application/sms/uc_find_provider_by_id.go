@@ -0,0 +1,30 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package sms
+
+import (
+	"github.com/worldiety/option"
+	"go.wdy.de/nago/application/sms/provider"
+	"go.wdy.de/nago/auth"
+	"go.wdy.de/nago/pkg/std/concurrent"
+)
+
+func NewFindProviderByID(providers *concurrent.RWMap[provider.ID, provider.Provider]) FindProviderByID {
+	return func(subject auth.Subject, id provider.ID) (option.Opt[provider.Provider], error) {
+		if err := subject.Audit(PermFindProviderByID); err != nil {
+			return option.None[provider.Provider](), err
+		}
+
+		prov, ok := providers.Get(id)
+		if !ok {
+			return option.None[provider.Provider](), nil
+		}
+
+		return option.Some(prov), nil
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package sms
+
+import (
+	"log/slog"
+
+	"go.wdy.de/nago/application/sms/message"
+	"go.wdy.de/nago/application/sms/provider"
+	"go.wdy.de/nago/auth"
+	"go.wdy.de/nago/pkg/events"
+	"go.wdy.de/nago/pkg/xtime"
+)
+
+func NewProcessCallback(repo message.Repository, bus events.Bus) ProcessCallback {
+	return func(subject auth.Subject, id provider.ID, cb provider.Callback) error {
+		if err := subject.Audit(PermProcessCallback); err != nil {
+			return err
+		}
+
+		if len(cb.Reports) > 0 {
+			reports := make(map[message.ID]provider.DeliveryReport, len(cb.Reports))
+			for _, report := range cb.Reports {
+				reports[report.ProviderMessage] = report
+			}
+
+			for sms, err := range repo.All() {
+				if err != nil {
+					return err
+				}
+
+				report, ok := reports[sms.ProviderMessage]
+				if !ok || sms.ProviderMessage == "" {
+					continue
+				}
+
+				// messages sent by the queue loop have no provider assigned
+				if sms.Provider != "" && sms.Provider != string(id) {
+					continue
+				}
+
+				sms.Status = report.Status
+				sms.LastError = report.Error
+				sms.ReportedAt = xtime.Now()
+				if err := repo.Save(sms); err != nil {
+					return err
+				}
+
+				delete(reports, sms.ProviderMessage)
+				bus.Publish(message.DeliveryReported{
+					ID:        sms.ID,
+					Recipient: sms.Recipient,
+					Status:    sms.Status,
+					Error:     sms.LastError,
+				})
+			}
+
+			for providerMessage := range reports {
+				// the history may have been purged already or the message was not sent by us
+				slog.Warn("ignored sms delivery report of unknown message", "provider", id, "providerMessage", providerMessage)
+			}
+		}
+
+		for _, received := range cb.Received {
+			if received.Provider == "" {
+				received.Provider = string(id)
+			}
+
+			if received.ReceivedAt == 0 {
+				received.ReceivedAt = xtime.Now()
+			}
+
+			slog.Info("sms received", "provider", id, "providerMessage", received.ProviderMessage)
+			bus.Publish(received)
+		}
+
+		return nil
+	}
+}
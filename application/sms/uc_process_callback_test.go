@@ -0,0 +1,69 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package sms
+
+import (
+	"testing"
+
+	"go.wdy.de/nago/application/sms/message"
+	"go.wdy.de/nago/application/sms/provider"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/pkg/blob/mem"
+	"go.wdy.de/nago/pkg/data/json"
+	"go.wdy.de/nago/pkg/events/eventstest"
+)
+
+func TestProcessCallback(t *testing.T) {
+	repo := message.Repository(json.NewSloppyJSONRepository[message.SMS, message.ID](mem.NewBlobStore("sms")))
+	for _, sms := range []message.SMS{
+		{ID: "a", Status: message.StatusSent, Provider: "gw", ProviderMessage: "4711", Recipient: 49179555111},
+		{ID: "b", Status: message.StatusSent, Provider: "other", ProviderMessage: "4712"},
+		{ID: "c", Status: message.StatusSent, ProviderMessage: "4712"},
+	} {
+		if err := repo.Save(sms); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bus := &eventstest.Recorder{}
+	processCallback := NewProcessCallback(repo, bus)
+
+	err := processCallback(user.SU(), "gw", provider.Callback{
+		Reports: []provider.DeliveryReport{
+			{ProviderMessage: "4711", Status: message.StatusDelivered},
+			{ProviderMessage: "4712", Status: message.StatusUndelivered, Error: "UNDELIV"},
+			{ProviderMessage: "unknown", Status: message.StatusDelivered},
+		},
+		Received: []message.Received{{Sender: 49179555222, Body: "JA"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[message.ID]message.Status{"a": message.StatusDelivered, "b": message.StatusSent, "c": message.StatusUndelivered}
+	for id, status := range want {
+		optSMS, err := repo.FindByID(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if optSMS.Unwrap().Status != status {
+			t.Fatalf("expected %s to be %s but got %s", id, status, optSMS.Unwrap().Status)
+		}
+	}
+
+	evts := bus.Events()
+	if len(evts) != 3 {
+		t.Fatalf("unexpected events: %v", evts)
+	}
+
+	received, ok := evts[2].(message.Received)
+	if !ok || received.Provider != "gw" || received.ReceivedAt == 0 {
+		t.Fatalf("unexpected event: %v", evts[2])
+	}
+}
@@ -8,23 +8,35 @@
 package sms
 
 import (
+	"io"
 	"log/slog"
 
 	"go.wdy.de/nago/application/group"
 	"go.wdy.de/nago/application/secret"
 	"go.wdy.de/nago/application/sms/provider"
+	"go.wdy.de/nago/application/sms/provider/gateway"
+	"go.wdy.de/nago/application/sms/provider/smpp"
 	"go.wdy.de/nago/application/sms/provider/spryng"
 	"go.wdy.de/nago/application/user"
 	"go.wdy.de/nago/auth"
 	"go.wdy.de/nago/pkg/std/concurrent"
 )
 
-func NewReloadProvider(m *concurrent.RWMap[provider.ID, provider.Provider], findSecrets secret.FindGroupSecrets) ReloadProvider {
+func NewReloadProvider(m *concurrent.RWMap[provider.ID, provider.Provider], findSecrets secret.FindGroupSecrets, processCallback ProcessCallback) ReloadProvider {
 	return func(subject auth.Subject, opts ReloadProviderOptions) error {
 		if err := subject.Audit(PermReloadProvider); err != nil {
 			return err
 		}
 
+		// providers like smpp keep a connection, which must not leak
+		for id, prov := range m.All() {
+			if closer, ok := prov.(io.Closer); ok {
+				if err := closer.Close(); err != nil {
+					slog.Error("failed to close sms provider", "id", id, "err", err.Error())
+				}
+			}
+		}
+
 		m.Clear()
 
 		for sec, err := range findSecrets(user.SU(), group.System) {
@@ -33,10 +45,21 @@ func NewReloadProvider(m *concurrent.RWMap[provider.ID, provider.Provider], find
 				continue
 			}
 
+			id := provider.ID(sec.ID)
+			notify := func(cb provider.Callback) {
+				if err := processCallback(user.SU(), id, cb); err != nil {
+					slog.Error("failed to process sms provider callback", "id", id, "err", err.Error())
+				}
+			}
+
 			var prov provider.Provider
 			switch cfg := sec.Credentials.(type) {
 			case spryng.Settings:
-				prov = spryng.NewProvider(provider.ID(sec.ID), cfg)
+				prov = spryng.NewProvider(id, cfg)
+			case gateway.Settings:
+				prov = gateway.NewProvider(id, cfg)
+			case smpp.Settings:
+				prov = smpp.NewProvider(id, cfg, notify)
 			}
 
 			if prov == nil {
@@ -82,12 +82,17 @@ func NewSend(repo message.Repository, providers *concurrent.RWMap[provider.ID, p
 
 		slog.Info("sms sent successfully", "id", msg.ID, "provider", prov.Identity())
 
+		// the queue already owns a message, which is updated by the caller
+		if opts.NoQueue {
+			return provSendId, nil
+		}
+
 		msg.LastError = ""
 		msg.Status = message.StatusSent
 		msg.SendAt = xtime.Now()
-		msg.Provider = string(subject.ID())
+		msg.Provider = string(prov.Identity())
 		msg.ProviderMessage = provSendId
-		
+
 		if err := repo.Save(msg); err != nil {
 			return "", err
 		}
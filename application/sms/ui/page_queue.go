@@ -28,6 +28,9 @@ var (
 	StrSMSQueued = i18n.MustString("nago.sms.queue.queued", i18n.Values{language.English: "queued", language.German: "wartet auf Versand"})
 	StrSMSSent   = i18n.MustString("nago.sms.queue.sent", i18n.Values{language.English: "sent", language.German: "versendet"})
 	StrSMSFailed = i18n.MustString("nago.sms.queue.failed", i18n.Values{language.English: "failed", language.German: "Fehler beim Versand"})
+
+	StrSMSDelivered   = i18n.MustString("nago.sms.queue.delivered", i18n.Values{language.English: "delivered", language.German: "zugestellt"})
+	StrSMSUndelivered = i18n.MustString("nago.sms.queue.undelivered", i18n.Values{language.English: "undelivered", language.German: "nicht zustellbar"})
 )
 
 func PageQueue(wnd core.Window, uc sms.UseCases) core.View {
@@ -64,6 +67,10 @@ func PageQueue(wnd core.Window, uc sms.UseCases) core.View {
 							return ui.Text(StrSMSFailed.Get(wnd))
 						case message.StatusQueued:
 							return ui.Text(StrSMSQueued.Get(wnd))
+						case message.StatusDelivered:
+							return ui.Text(StrSMSDelivered.Get(wnd))
+						case message.StatusUndelivered:
+							return ui.Text(StrSMSUndelivered.Get(wnd))
 						default:
 							return ui.Text(string(obj.Status))
 						}
@@ -38,17 +38,27 @@ type FindMessageByID func(subject auth.Subject, id message.ID) (option.Opt[messa
 
 type DeleteMessageByID func(subject auth.Subject, id message.ID) error
 
+// ProcessCallback applies the delivery reports of the given provider to the according messages and publishes
+// [message.DeliveryReported]. Each inbound message is published as [message.Received].
+type ProcessCallback func(subject auth.Subject, id provider.ID, cb provider.Callback) error
+
+// FindProviderByID returns the provider, which has been created from the secret with the given id.
+type FindProviderByID func(subject auth.Subject, id provider.ID) (option.Opt[provider.Provider], error)
+
 type UseCases struct {
 	Send              Send
 	ReloadProvider    ReloadProvider
 	FindAllMessageIDs FindAllMessageIDs
 	FindMessageByID   FindMessageByID
 	DeleteMessageByID DeleteMessageByID
+	ProcessCallback   ProcessCallback
+	FindProviderByID  FindProviderByID
 }
 
 func NewUseCases(ctx context.Context, bus events.Bus, findSecrets secret.FindGroupSecrets, repo message.Repository) UseCases {
 	var providers concurrent.RWMap[provider.ID, provider.Provider]
-	fnReload := NewReloadProvider(&providers, findSecrets)
+	processCallbackFn := NewProcessCallback(repo, bus)
+	fnReload := NewReloadProvider(&providers, findSecrets, processCallbackFn)
 
 	fnInvokeReload := func() {
 		if err := fnReload(user.SU(), ReloadProviderOptions{}); err != nil {
@@ -90,5 +100,7 @@ func NewUseCases(ctx context.Context, bus events.Bus, findSecrets secret.FindGro
 		FindAllMessageIDs: NewFindAllMessageIDs(repo),
 		FindMessageByID:   NewFindMessageByID(repo),
 		DeleteMessageByID: NewDeleteMessageByID(repo),
+		ProcessCallback:   processCallbackFn,
+		FindProviderByID:  NewFindProviderByID(&providers),
 	}
 }
@@ -0,0 +1,60 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package token
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+type ScheduleOptions struct {
+	FlushInterval  time.Duration // default is 1 minute, within this interval usage counters are persisted
+	NotifyInterval time.Duration // default is 1 hour, within this interval expiring tokens are notified
+}
+
+// StartScheduler starts a new scheduler instance, which persists the token usages and sends the expiry
+// notifications periodically until the context is done. Pending usages are flushed a last time on shutdown.
+func StartScheduler(ctx context.Context, opts ScheduleOptions, flush FlushUsage, notify NotifyExpiring) {
+	if opts.FlushInterval == 0 {
+		opts.FlushInterval = time.Minute
+	}
+
+	if opts.NotifyInterval == 0 {
+		opts.NotifyInterval = time.Hour
+	}
+
+	go func() {
+		slog.Info("token scheduler started")
+		flushTicker := time.NewTicker(opts.FlushInterval)
+		defer flushTicker.Stop()
+
+		notifyTicker := time.NewTicker(opts.NotifyInterval)
+		defer notifyTicker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				if err := flush(); err != nil {
+					slog.Error("token scheduler cannot flush usages", "err", err)
+				}
+
+				slog.Info("token scheduler stopped")
+				return
+			case <-flushTicker.C:
+				if err := flush(); err != nil {
+					slog.Error("token scheduler cannot flush usages", "err", err)
+				}
+			case <-notifyTicker.C:
+				if err := notify(); err != nil {
+					slog.Error("token scheduler cannot notify expiring tokens", "err", err)
+				}
+			}
+		}
+	}()
+}
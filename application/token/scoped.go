@@ -0,0 +1,79 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package token
+
+import (
+	"iter"
+	"slices"
+
+	"go.wdy.de/nago/application/permission"
+	"go.wdy.de/nago/application/rebac"
+	"go.wdy.de/nago/application/role"
+	"go.wdy.de/nago/application/user"
+)
+
+var _ user.Subject = (*scopedSubject)(nil)
+
+// scopedSubject limits the rights of a token to its [Token.Scopes]. A permission is only granted, if it is
+// within the scopes and the underlying token or impersonated user still holds it. Roles are hidden, because
+// they are just bundles of permissions and checking them would bypass the scopes.
+type scopedSubject struct {
+	user.Subject
+	scopes []permission.ID
+}
+
+func newScopedSubject(subject user.Subject, scopes []permission.ID) user.Subject {
+	if len(scopes) == 0 {
+		return subject
+	}
+
+	return &scopedSubject{Subject: subject, scopes: slices.Clone(scopes)}
+}
+
+func (s *scopedSubject) HasPermission(p permission.ID) bool {
+	return slices.Contains(s.scopes, p) && s.Subject.HasPermission(p)
+}
+
+func (s *scopedSubject) Audit(p permission.ID) error {
+	if !slices.Contains(s.scopes, p) {
+		return s.deny(permission.Decision{Permission: p})
+	}
+
+	return s.Subject.Audit(p)
+}
+
+func (s *scopedSubject) HasResourcePermission(name rebac.Namespace, id rebac.Instance, p permission.ID) bool {
+	return slices.Contains(s.scopes, p) && s.Subject.HasResourcePermission(name, id, p)
+}
+
+func (s *scopedSubject) AuditResource(name rebac.Namespace, id rebac.Instance, p permission.ID) error {
+	if !slices.Contains(s.scopes, p) {
+		return s.deny(permission.Decision{Permission: p, Namespace: string(name), Instance: string(id)})
+	}
+
+	return s.Subject.AuditResource(name, id, p)
+}
+
+// deny reports a decision, which has been rejected by the scopes before the subject itself has been audited.
+func (s *scopedSubject) deny(d permission.Decision) error {
+	var name = string(d.Permission)
+	if p, ok := permission.Find(d.Permission); ok {
+		name = p.Name
+	}
+
+	d.Subject = string(s.Subject.ID())
+	return permission.Report(d, user.PermissionDeniedError(name))
+}
+
+func (s *scopedSubject) Roles() iter.Seq[role.ID] {
+	return func(yield func(role.ID) bool) {}
+}
+
+func (s *scopedSubject) HasRole(id role.ID) bool {
+	return false
+}
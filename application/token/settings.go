@@ -0,0 +1,35 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package token
+
+import (
+	"cmp"
+	"time"
+
+	"github.com/worldiety/enum"
+	"go.wdy.de/nago/application/settings"
+	"go.wdy.de/nago/application/user"
+)
+
+var _ = enum.Variant[settings.GlobalSettings, Settings](
+	enum.Rename[Settings]("nago.token.settings"),
+)
+
+type Settings struct {
+	_ any `title:"Access Tokens" description:"Benachrichtigungen über ablaufende API Access Tokens."`
+
+	DisableExpiryNotice bool          `json:"disableExpiryNotice,omitempty" label:"Keine Benachrichtigungen" supportingText:"Wenn aktiviert, werden keine E-Mails zu ablaufenden Tokens versendet."`
+	ExpiryNotice        time.Duration `json:"expiryNotice,omitempty" label:"Vorwarnzeit" supportingText:"So lange vor dem Ablauf eines Tokens wird per E-Mail benachrichtigt. Standard sind 14 Tage."`
+	NotifyMail          user.Email    `json:"notifyMail,omitempty" label:"Zusätzlicher Empfänger" supportingText:"Diese E-Mail Adresse wird über alle ablaufenden Tokens benachrichtigt, z.B. ein Team-Postfach."`
+}
+
+func (s Settings) GlobalSettings() bool { return true }
+
+func (s Settings) expiryNotice() time.Duration {
+	return cmp.Or(max(s.ExpiryNotice, 0), 14*24*time.Hour)
+}
@@ -80,6 +80,10 @@ func (s *subject) HasResourcePermission(name rebac.Namespace, id rebac.Instance,
 }
 
 func (s *subject) Audit(perm permission.ID) error {
+	return permission.Report(permission.Decision{Subject: string(s.ID()), Permission: perm}, s.audit(perm))
+}
+
+func (s *subject) audit(perm permission.ID) error {
 	if !s.Valid() {
 		return user.PermissionDeniedErr
 	}
@@ -108,6 +112,15 @@ func (s *subject) source() rebac.Entity {
 }
 
 func (s *subject) AuditResource(name rebac.Namespace, id rebac.Instance, p permission.ID) error {
+	return permission.Report(permission.Decision{
+		Subject:    string(s.ID()),
+		Permission: p,
+		Namespace:  string(name),
+		Instance:   string(id),
+	}, s.auditResource(name, id, p))
+}
+
+func (s *subject) auditResource(name rebac.Namespace, id rebac.Instance, p permission.ID) error {
 	if !s.Valid() {
 		return user.PermissionDeniedErr
 	}
@@ -148,6 +161,12 @@ func (s *subject) ID() user.ID {
 	return user.ID(s.token.ID)
 }
 
+// IsToken marks this subject as an API access token, so that token principals
+// are not confused with users of the same id.
+func (s *subject) IsToken() bool {
+	return true
+}
+
 func (s *subject) Name() string {
 	s.load()
 	return s.token.Name
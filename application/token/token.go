@@ -9,9 +9,13 @@ package token
 
 import (
 	"encoding/hex"
+	"fmt"
+	"net"
+	"net/netip"
 	"time"
 
 	"github.com/worldiety/option"
+	"go.wdy.de/nago/application/permission"
 	"go.wdy.de/nago/application/user"
 	"go.wdy.de/nago/pkg/xtime"
 )
@@ -36,6 +40,21 @@ type Token struct {
 
 	// Impersonation has priority thus if valid, other Groups, Roles, Permissions and Resources are ignored.
 	Impersonation option.Opt[user.ID] `json:"impersonation"`
+
+	// Scopes limits the token to the given permissions, which are matched against global permissions and the
+	// relations to resources. Empty means that all assigned or impersonated rights are available.
+	Scopes []permission.ID `json:"scopes,omitempty"`
+	// AllowedNetworks restricts the usage to clients within the given CIDR ranges, e.g. 10.0.0.0/8.
+	// Empty means that the token can be used from anywhere. Behind a reverse proxy, the client address is only
+	// known, if the proxy is trusted, see [go.wdy.de/nago/pkg/xhttp.TrustedProxies].
+	AllowedNetworks []string `json:"allowedNetworks,omitempty"`
+	// NotifyMail receives the expiry notification. Impersonated users are always notified.
+	NotifyMail user.Email `json:"notifyMail,omitempty"`
+
+	LastUsedAt       time.Time `json:"lastUsedAt,omitzero"`
+	LastUsedFrom     string    `json:"lastUsedFrom,omitempty"`
+	UsageCount       int64     `json:"usageCount,omitempty"`
+	ExpiryNotifiedAt time.Time `json:"expiryNotifiedAt,omitzero"`
 }
 
 func HashString(hash []byte) Hash {
@@ -45,3 +64,51 @@ func HashString(hash []byte) Hash {
 func (t Token) Identity() ID {
 	return t.ID
 }
+
+// Allowed returns true, if the token may be used from the given remote address, which is either an ip address or
+// an ip address with a port. Tokens without network restrictions are allowed from everywhere, even if the
+// address is unknown.
+func (t Token) Allowed(remoteAddr string) bool {
+	if len(t.AllowedNetworks) == 0 {
+		return true
+	}
+
+	host := remoteAddr
+	if h, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		host = h
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+
+	addr = addr.Unmap()
+	for _, network := range t.AllowedNetworks {
+		prefix, err := parseNetwork(network)
+		if err != nil {
+			continue
+		}
+
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseNetwork accepts a CIDR range or a single ip address.
+func parseNetwork(network string) (netip.Prefix, error) {
+	if prefix, err := netip.ParsePrefix(network); err == nil {
+		return prefix.Masked(), nil
+	}
+
+	addr, err := netip.ParseAddr(network)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid network: %s", network)
+	}
+
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
@@ -9,7 +9,9 @@ package token
 
 import (
 	"context"
+	"log/slog"
 
+	"go.wdy.de/nago/application/lockout"
 	"go.wdy.de/nago/application/rebac"
 	"go.wdy.de/nago/application/role"
 	"go.wdy.de/nago/application/user"
@@ -23,20 +25,43 @@ func NewAuthenticateSubject(
 	algo user.HashAlgorithm,
 	reverseHashLookup *concurrent.RWMap[Hash, ID],
 	subjectFromUser user.SubjectFromUser,
-	subjectLookup *concurrent.RWMap[Plaintext, user.Subject],
+	subjectLookup *concurrent.RWMap[Plaintext, authenticated],
 	anonUser user.GetAnonUser,
 	findRoleByID role.FindByID,
 	rdb *rebac.DB,
+	throttle lockout.UseCases,
+	usage *usage,
 ) AuthenticateSubject {
-	return func(plaintext Plaintext) (auth.Subject, error) {
-		subj, ok := subjectLookup.Get(plaintext)
+	// use checks the network restrictions on each request and counts the usage
+	use := func(remoteAddr string, entry authenticated) auth.Subject {
+		if !entry.token.Allowed(remoteAddr) {
+			slog.Warn("rejected access token from disallowed network", "token", entry.token.ID, "remoteAddr", remoteAddr)
+			return anonUser()
+		}
+
+		usage.track(entry.token.ID, remoteAddr)
+		return entry.subject
+	}
+
+	return func(remoteAddr string, plaintext Plaintext) (auth.Subject, error) {
+		entry, ok := subjectLookup.Get(plaintext)
 		if ok {
 			// security note: we trade security (keeping all authenticated plaintext token in-memory) against
 			// speed. REST APIs must be as fast as possible and this is a reasonable compromise.
 			// If we would not do this, we would limit our amount of requests to a few hundred per second at best
 			// because the password hash algorithm is intentionally very expensive. Note, that the subjects
 			// will enable or disable themselves automatically even though we leak them infinitely.
-			return subj, nil
+			return use(remoteAddr, entry), nil
+		}
+
+		if plaintext == "" {
+			return anonUser(), nil
+		}
+
+		// security note: checking before hashing also limits the load, an attacker can cause from a single address
+		ip := lockout.IPKey(remoteAddr)
+		if err := throttle.Check(ip); err != nil {
+			return nil, err
 		}
 
 		// security note: we currently expect that all hash algorithms are of the same and given kind. Otherwise,
@@ -51,7 +76,10 @@ func NewAuthenticateSubject(
 		hash := HashString(hbytes)
 		tid, ok := reverseHashLookup.Get(hash)
 		if !ok {
-			//
+			if err := throttle.Fail(ip); err != nil {
+				return nil, err
+			}
+
 			return anonUser(), nil
 		}
 
@@ -69,9 +97,9 @@ func NewAuthenticateSubject(
 		token := optToken.Unwrap()
 
 		if token.Impersonation.IsNone() {
-			s := newSubject(ctx, findRoleByID, repo, token, rdb)
-			subjectLookup.Put(plaintext, s)
-			return s, nil
+			entry = authenticated{token: token, subject: newScopedSubject(newSubject(ctx, findRoleByID, repo, token, rdb), token.Scopes)}
+			subjectLookup.Put(plaintext, entry)
+			return use(remoteAddr, entry), nil
 		}
 
 		uid := token.Impersonation.Unwrap()
@@ -84,9 +112,9 @@ func NewAuthenticateSubject(
 			return anonUser(), nil
 		}
 
-		usr := optUsr.Unwrap()
-		subjectLookup.Put(plaintext, usr)
+		entry = authenticated{token: token, subject: newScopedSubject(optUsr.Unwrap(), token.Scopes)}
+		subjectLookup.Put(plaintext, entry)
 
-		return usr, nil
+		return use(remoteAddr, entry), nil
 	}
 }
@@ -11,6 +11,7 @@ import (
 	"crypto/rand"
 	"fmt"
 	"log/slog"
+	"strings"
 	"sync"
 	"time"
 
@@ -20,6 +21,7 @@ import (
 	"go.wdy.de/nago/application/user"
 	"go.wdy.de/nago/auth"
 	"go.wdy.de/nago/pkg/data"
+	"go.wdy.de/nago/pkg/std"
 	"go.wdy.de/nago/pkg/std/concurrent"
 )
 
@@ -49,6 +51,24 @@ func NewCreate(mutex *sync.Mutex, repo Repository, algo user.HashAlgorithm, reve
 			return "", "", fmt.Errorf("plaintext is too short: %s", plaintext)
 		}
 
+		var networks []string
+		for _, network := range cdata.AllowedNetworks {
+			network = strings.TrimSpace(network)
+			if network == "" {
+				continue
+			}
+
+			if _, err := parseNetwork(network); err != nil {
+				return "", "", std.NewLocalizedError("Ungültiges Netzwerk", fmt.Sprintf("'%s' ist weder eine IP-Adresse noch ein CIDR Bereich wie 10.0.0.0/8.", network)).WithError(err)
+			}
+
+			networks = append(networks, network)
+		}
+
+		if cdata.NotifyMail != "" && !cdata.NotifyMail.Valid() {
+			return "", "", std.NewLocalizedError("Eingabebeschränkung", "Die E-Mail Adresse für Benachrichtigungen ist ungültig.").WithError(user.InvalidEMailErr)
+		}
+
 		hBytes, err := plaintext.TokenHash(algo)
 		if err != nil {
 			return "", "", err
@@ -71,6 +91,10 @@ func NewCreate(mutex *sync.Mutex, repo Repository, algo user.HashAlgorithm, reve
 			TokenHash:   hBytes,
 			CreatedAt:   time.Now(),
 			ValidUntil:  cdata.ValidUntil,
+
+			Scopes:          cdata.Scopes,
+			AllowedNetworks: networks,
+			NotifyMail:      cdata.NotifyMail,
 		}
 
 		optToken, err := repo.FindByID(token.ID)
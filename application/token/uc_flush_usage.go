@@ -0,0 +1,53 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package token
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+func NewFlushUsage(mutex *sync.Mutex, repo Repository, usage *usage) FlushUsage {
+	return func() error {
+		pending := usage.take()
+		if len(pending) == 0 {
+			return nil
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		var errs []error
+		for id, delta := range pending {
+			optToken, err := repo.FindByID(id)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("cannot find token %s: %w", id, err))
+				continue
+			}
+
+			if optToken.IsNone() {
+				// deleted in the meantime
+				continue
+			}
+
+			token := optToken.Unwrap()
+			token.UsageCount += delta.count
+			if delta.lastAt.After(token.LastUsedAt) {
+				token.LastUsedAt = delta.lastAt
+				token.LastUsedFrom = delta.lastFrom
+			}
+
+			if err := repo.Save(token); err != nil {
+				errs = append(errs, fmt.Errorf("cannot save token %s: %w", id, err))
+			}
+		}
+
+		return errors.Join(errs...)
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package token
+
+import (
+	"fmt"
+	"log/slog"
+	netmail "net/mail"
+	"slices"
+	"sync"
+	"time"
+
+	"go.wdy.de/nago/application/mail"
+	"go.wdy.de/nago/application/settings"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/pkg/events"
+	"go.wdy.de/nago/pkg/xtime"
+)
+
+func NewNotifyExpiring(mutex *sync.Mutex, bus events.Bus, loadGlobal settings.LoadGlobal, repo Repository, findUserByID user.FindByID) NotifyExpiring {
+	return func() error {
+		cfg := settings.ReadGlobal[Settings](loadGlobal)
+		if cfg.DisableExpiryNotice {
+			return nil
+		}
+
+		now := time.Now()
+
+		// collect first, because we must not modify the repository while iterating
+		var expiring []ID
+		for token, err := range repo.All() {
+			if err != nil {
+				return fmt.Errorf("cannot iterate tokens: %w", err)
+			}
+
+			if expiresSoon(token, cfg, now) {
+				expiring = append(expiring, token.ID)
+			}
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		for _, id := range expiring {
+			optToken, err := repo.FindByID(id)
+			if err != nil {
+				return fmt.Errorf("cannot find token: %w", err)
+			}
+
+			if optToken.IsNone() {
+				continue
+			}
+
+			token := optToken.Unwrap()
+			if !expiresSoon(token, cfg, now) {
+				continue
+			}
+
+			recipients, err := expiryRecipients(token, cfg, findUserByID)
+			if err != nil {
+				return err
+			}
+
+			if len(recipients) == 0 {
+				slog.Warn("access token expires soon but nobody can be notified", "token", token.ID)
+				continue
+			}
+
+			bus.Publish(mail.SendMailRequested{
+				To:       recipients,
+				Subject:  fmt.Sprintf("Access Token '%s' läuft bald ab", token.Name),
+				TextBody: expiryText(token),
+			})
+
+			token.ExpiryNotifiedAt = now
+			if err := repo.Save(token); err != nil {
+				return fmt.Errorf("cannot save token: %w", err)
+			}
+		}
+
+		return nil
+	}
+}
+
+// expiresSoon returns true, if the token is still valid but within the notice period and has not been notified yet.
+func expiresSoon(token Token, cfg Settings, now time.Time) bool {
+	if token.ValidUntil.IsZero() || !token.ExpiryNotifiedAt.IsZero() {
+		return false
+	}
+
+	validUntil := token.ValidUntil.Time(now.Location())
+
+	return now.Before(validUntil) && validUntil.Sub(now) <= cfg.expiryNotice()
+}
+
+func expiryRecipients(token Token, cfg Settings, findUserByID user.FindByID) ([]netmail.Address, error) {
+	var mails []user.Email
+	if token.Impersonation.IsSome() {
+		optUsr, err := findUserByID(user.SU(), token.Impersonation.Unwrap())
+		if err != nil {
+			return nil, fmt.Errorf("cannot find impersonated user: %w", err)
+		}
+
+		if optUsr.IsSome() {
+			mails = append(mails, optUsr.Unwrap().Email)
+		}
+	}
+
+	mails = append(mails, token.NotifyMail, cfg.NotifyMail)
+
+	var res []netmail.Address
+	for _, m := range mails {
+		if m == "" || slices.ContainsFunc(res, func(a netmail.Address) bool { return a.Address == string(m) }) {
+			continue
+		}
+
+		res = append(res, netmail.Address{Address: string(m)})
+	}
+
+	return res, nil
+}
+
+func expiryText(token Token) string {
+	lastUsed := "Der Token wurde bisher nicht verwendet."
+	if !token.LastUsedAt.IsZero() {
+		lastUsed = fmt.Sprintf("Der Token wurde zuletzt am %s verwendet und insgesamt %d mal genutzt.", token.LastUsedAt.Format(xtime.GermanDateTime), token.UsageCount)
+	}
+
+	return fmt.Sprintf("Guten Tag,\n\nder API Access Token '%s' ist nur noch bis zum %s gültig. %s\n\nBitte erstellen Sie rechtzeitig einen neuen Token und hinterlegen Sie diesen in den angebundenen Anwendungen.\n", token.Name, token.ValidUntil.Format(xtime.GermanDate), lastUsed)
+}
@@ -19,7 +19,7 @@ import (
 	"go.wdy.de/nago/pkg/std/concurrent"
 )
 
-func NewRotate(mutex *sync.Mutex, repo Repository, algo user.HashAlgorithm, reverseHashLookup *concurrent.RWMap[Hash, ID]) Rotate {
+func NewRotate(mutex *sync.Mutex, repo Repository, algo user.HashAlgorithm, reverseHashLookup *concurrent.RWMap[Hash, ID], subjectLookup *concurrent.RWMap[Plaintext, authenticated]) Rotate {
 	return func(subject auth.Subject, id ID) (Plaintext, error) {
 		mutex.Lock()
 		defer mutex.Unlock()
@@ -68,6 +68,9 @@ func NewRotate(mutex *sync.Mutex, repo Repository, algo user.HashAlgorithm, reve
 		// security note: delete is important to immediately disable any future token requests using the old token
 		reverseHashLookup.Delete(oldHash)
 		reverseHashLookup.Put(hash, token.ID)
+		subjectLookup.DeleteFunc(func(t Plaintext, entry authenticated) bool {
+			return entry.token.ID == token.ID
+		})
 
 		return plaintext, nil
 	}
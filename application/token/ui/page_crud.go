@@ -8,6 +8,10 @@
 package uitoken
 
 import (
+	"fmt"
+	"strconv"
+	"time"
+
 	"go.wdy.de/nago/application/group"
 	"go.wdy.de/nago/application/permission"
 	"go.wdy.de/nago/application/role"
@@ -43,6 +47,8 @@ func PageCrud(wnd core.Window, uc token.UseCases) core.View {
 			ui.TableCell(ui.Text(tok.Description)),
 			ui.TableCell(ui.Text(tok.CreatedAt.Format(xtime.GermanDate))),
 			ui.TableCell(ui.Text(formatValidUntil(tok.ValidUntil))),
+			ui.TableCell(ui.Text(formatLastUsed(tok))),
+			ui.TableCell(ui.Text(strconv.FormatInt(tok.UsageCount, 10))),
 			ui.TableCell(ui.HStack(
 				ui.SecondaryButton(func() {
 					selectedToken.Set(tok)
@@ -92,6 +98,8 @@ func PageCrud(wnd core.Window, uc token.UseCases) core.View {
 			ui.TableColumn(ui.Text("Beschreibung")),
 			ui.TableColumn(ui.Text("Erstellt am")),
 			ui.TableColumn(ui.Text("Gültig bis")),
+			ui.TableColumn(ui.Text("Zuletzt verwendet")),
+			ui.TableColumn(ui.Text("Nutzungen")),
 			ui.TableColumn(ui.Text("Optionen")),
 		).Rows(rows...),
 		ui.If(len(rows) == 0, ui.Text("Noch keine Tokens vorhanden")),
@@ -167,7 +175,10 @@ func deleteDialog(wnd core.Window, presented *core.State[bool], token token.Toke
 
 	return alert.Dialog(
 		token.Name+" rotieren",
-		ui.Text("Soll der Access Token '"+token.Name+"' gelöscht werden?"),
+		ui.VStack(
+			ui.Text("Soll der Access Token '"+token.Name+"' gelöscht werden?"),
+			usageHint(token),
+		).Alignment(ui.Leading).Gap(ui.L8),
 		presented,
 		alert.Cancel(nil),
 		alert.Delete(func() {
@@ -185,7 +196,10 @@ func rotateDialog(wnd core.Window, presented *core.State[bool], token token.Toke
 
 	return alert.Dialog(
 		token.Name+" rotieren",
-		ui.Text("Soll der Token '"+token.Name+"' rotiert werden? Die Berechtigungen ändern sich dadurch nicht, aber sämtliche Zugriffe über den alten Token sind dann nicht mehr möglich."),
+		ui.VStack(
+			ui.Text("Soll der Token '"+token.Name+"' rotiert werden? Die Berechtigungen ändern sich dadurch nicht, aber sämtliche Zugriffe über den alten Token sind dann nicht mehr möglich."),
+			usageHint(token),
+		).Alignment(ui.Leading).Gap(ui.L8),
 		presented,
 		alert.Closeable(),
 		alert.Cancel(nil),
@@ -264,3 +278,30 @@ func formatValidUntil(t xtime.Date) string {
 
 	return t.Format(xtime.GermanDate)
 }
+
+func formatLastUsed(t token.Token) string {
+	if t.LastUsedAt.IsZero() {
+		return "nie"
+	}
+
+	return t.LastUsedAt.Format(xtime.GermanDateTime)
+}
+
+// usageHint warns about a token which is still in use, before it gets rotated or deleted.
+func usageHint(t token.Token) core.View {
+	if t.LastUsedAt.IsZero() {
+		return ui.Text("Der Token wurde bisher nicht verwendet.")
+	}
+
+	msg := fmt.Sprintf("Der Token wurde zuletzt am %s", t.LastUsedAt.Format(xtime.GermanDateTime))
+	if t.LastUsedFrom != "" {
+		msg += " von " + t.LastUsedFrom
+	}
+
+	msg += fmt.Sprintf(" verwendet und insgesamt %d mal genutzt.", t.UsageCount)
+	if time.Since(t.LastUsedAt) < 24*time.Hour {
+		return alert.Banner("Token in Verwendung", msg).Intent(alert.IntentWarning)
+	}
+
+	return ui.Text(msg)
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package token
+
+import (
+	"sync"
+	"time"
+
+	"go.wdy.de/nago/application/user"
+)
+
+// authenticated is the cached result of a successful token lookup. The token is a snapshot of the time of
+// the lookup, which is fine, because neither its id nor its network restrictions can be changed.
+type authenticated struct {
+	token   Token
+	subject user.Subject
+}
+
+// usage collects the usages of tokens in memory, because writing each request through the repository would
+// slow down the REST APIs considerably. See [FlushUsage].
+type usage struct {
+	mutex   sync.Mutex
+	pending map[ID]usageDelta
+}
+
+type usageDelta struct {
+	count    int64
+	lastAt   time.Time
+	lastFrom string
+}
+
+func (u *usage) track(id ID, remoteAddr string) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	if u.pending == nil {
+		u.pending = map[ID]usageDelta{}
+	}
+
+	delta := u.pending[id]
+	delta.count++
+	delta.lastAt = time.Now()
+	delta.lastFrom = remoteAddr
+	u.pending[id] = delta
+}
+
+// take returns and resets all pending usages.
+func (u *usage) take() map[ID]usageDelta {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	tmp := u.pending
+	u.pending = nil
+
+	return tmp
+}
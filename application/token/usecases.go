@@ -17,12 +17,15 @@ import (
 	"github.com/worldiety/i18n"
 	"github.com/worldiety/option"
 	"go.wdy.de/nago/application/group"
+	"go.wdy.de/nago/application/lockout"
 	"go.wdy.de/nago/application/permission"
 	"go.wdy.de/nago/application/rebac"
 	"go.wdy.de/nago/application/role"
+	"go.wdy.de/nago/application/settings"
 	"go.wdy.de/nago/application/user"
 	"go.wdy.de/nago/auth"
 	"go.wdy.de/nago/pkg/data"
+	"go.wdy.de/nago/pkg/events"
 	"go.wdy.de/nago/pkg/std/concurrent"
 	"go.wdy.de/nago/pkg/xtime"
 	"golang.org/x/text/language"
@@ -44,6 +47,10 @@ type CreationData struct {
 	Roles       []role.ID       `label:"Rollen" source:"nago.roles"`
 	Permissions []permission.ID `label:"Berechtigungen" source:"nago.permissions"`
 	Resources   map[user.Resource][]permission.ID
+
+	Scopes          []permission.ID `label:"Einschränken auf" source:"nago.permissions" supportingText:"Wenn gesetzt, kann der Token nur diese Berechtigungen verwenden, selbst wenn ihm über Rollen oder Gruppen mehr zugewiesen ist."`
+	AllowedNetworks []string        `label:"Erlaubte Netzwerke" lines:"3" supportingText:"Jede Zeile enthält eine IP-Adresse oder einen CIDR Bereich wie 10.0.0.0/8. Wenn leer, ist der Token von überall verwendbar."`
+	NotifyMail      user.Email      `label:"Benachrichtigung an" supportingText:"Diese E-Mail Adresse wird informiert, bevor der Token abläuft."`
 }
 
 // Create allocates a new token and returns the generated Hash and Plaintext. The Plaintext is never stored.
@@ -61,8 +68,10 @@ type UserCreationData struct {
 type CreateUserToken func(subject auth.Subject, data UserCreationData) (Hash, Plaintext, error)
 
 // AuthenticateSubject returns always a [auth.Subject]. If the plaintext token is unknown or out of life, an invalid
-// subject is returned. Errors are only returned, if the infrastructure fails.
-type AuthenticateSubject func(plaintext Plaintext) (auth.Subject, error)
+// subject is returned. Unknown tokens are counted as failed attempts of the remoteAddr, which is the ip address of
+// the client and may be empty if unknown. Errors are only returned, if the infrastructure fails or if the remoteAddr
+// has been locked, see [lockout.TooManyAttemptsErr].
+type AuthenticateSubject func(remoteAddr string, plaintext Plaintext) (auth.Subject, error)
 
 // Delete removes a token. A subject can always remove his tokens.
 type Delete func(subject auth.Subject, id ID) error
@@ -76,6 +85,14 @@ type FindAll func(subject auth.Subject) iter.Seq2[Token, error]
 
 type FindByID func(subject auth.Subject, id ID) (option.Opt[Token], error)
 
+// FlushUsage persists the usage counters and last-used timestamps, which are collected in memory by
+// [AuthenticateSubject]. See also [StartScheduler].
+type FlushUsage func() error
+
+// NotifyExpiring sends a mail for each token, which expires within the notice period of the [Settings].
+// Each token is notified only once. See also [StartScheduler].
+type NotifyExpiring func() error
+
 // deprecated: use rebac api
 type ResolvedTokenRights struct {
 	Impersonated bool
@@ -96,11 +113,15 @@ type UseCases struct {
 	Rotate              Rotate
 	ResolveTokenRights  ResolveTokenRights
 	FindByID            FindByID
+	FlushUsage          FlushUsage
+	NotifyExpiring      NotifyExpiring
 	Resources           rebac.Resources
 }
 
 func NewUseCases(
 	ctx context.Context,
+	bus events.Bus,
+	loadGlobal settings.LoadGlobal,
 	repository Repository,
 	subjectFromUser user.SubjectFromUser,
 	findGroupByID group.FindByID,
@@ -108,12 +129,14 @@ func NewUseCases(
 	findUserByID user.FindByID,
 	getAnonUser user.GetAnonUser,
 	rdb *rebac.DB,
+	throttle lockout.UseCases,
 ) (UseCases, error) {
 	var mutex sync.Mutex
 
 	// the reverse lookup keeps all plaintext tokens in memory and makes an O(1) lookup for the token so that
 	// a potential REST api can be as fast as possible and only the initial call is slow
-	subjectLookup := &concurrent.RWMap[Plaintext, user.Subject]{}
+	subjectLookup := &concurrent.RWMap[Plaintext, authenticated]{}
+	tokenUsage := &usage{}
 
 	reverseHashLookup := &concurrent.RWMap[Hash, ID]{}
 
@@ -149,8 +172,8 @@ func NewUseCases(
 	repo.AddDeletedObserver(func(repository data.Repository[Token, ID], deleted data.Deleted[ID]) error {
 		// note, that these clean up functions are all O(n), but at least it is in memory and probably
 		// fast enough for anything a nago app will ever serve.
-		subjectLookup.DeleteFunc(func(t Plaintext, token user.Subject) bool {
-			return ID(token.ID()) == deleted.ID
+		subjectLookup.DeleteFunc(func(t Plaintext, entry authenticated) bool {
+			return entry.token.ID == deleted.ID
 		})
 
 		reverseHashLookup.DeleteFunc(func(hash Hash, id ID) bool {
@@ -166,9 +189,11 @@ func NewUseCases(
 		Delete:              NewDelete(&mutex, repo),
 		FindAll:             NewFindAll(repo),
 		Create:              NewCreate(&mutex, repo, algo, reverseHashLookup, rdb),
-		AuthenticateSubject: NewAuthenticateSubject(ctx, repo, algo, reverseHashLookup, subjectFromUser, subjectLookup, getAnonUser, findRoleByID, rdb),
-		Rotate:              NewRotate(&mutex, repo, algo, reverseHashLookup),
+		AuthenticateSubject: NewAuthenticateSubject(ctx, repo, algo, reverseHashLookup, subjectFromUser, subjectLookup, getAnonUser, findRoleByID, rdb, throttle, tokenUsage),
+		Rotate:              NewRotate(&mutex, repo, algo, reverseHashLookup, subjectLookup),
 		FindByID:            NewFindByID(repo),
+		FlushUsage:          NewFlushUsage(&mutex, repo, tokenUsage),
+		NotifyExpiring:      NewNotifyExpiring(&mutex, bus, loadGlobal, repo, findUserByID),
 		ResolveTokenRights: NewResolveTokenRights(
 			repo,
 			findGroupByID,
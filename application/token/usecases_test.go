@@ -0,0 +1,165 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package token
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/worldiety/option"
+	"go.wdy.de/nago/application/mail"
+	"go.wdy.de/nago/application/permission"
+	"go.wdy.de/nago/application/rebac"
+	"go.wdy.de/nago/application/settings"
+	"go.wdy.de/nago/application/user"
+	"go.wdy.de/nago/pkg/blob/mem"
+	"go.wdy.de/nago/pkg/data/json"
+	"go.wdy.de/nago/pkg/events/eventstest"
+	"go.wdy.de/nago/pkg/xtime"
+)
+
+func testLoadGlobal(cfg Settings) settings.LoadGlobal {
+	return func(subject permission.Auditable, t reflect.Type) (settings.GlobalSettings, error) {
+		return cfg, nil
+	}
+}
+
+func newTestRepo(t *testing.T, tokens ...Token) Repository {
+	t.Helper()
+
+	repo := json.NewSloppyJSONRepository[Token](mem.NewBlobStore("tokens"))
+	for _, token := range tokens {
+		if err := repo.Save(token); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return repo
+}
+
+func TestToken_Allowed(t *testing.T) {
+	token := Token{AllowedNetworks: []string{"10.0.0.0/8", "2001:db8::/32", "192.168.1.7"}}
+
+	tests := map[string]bool{
+		"10.1.2.3":            true,
+		"10.1.2.3:4711":       true,
+		"[2001:db8::1]:443":   true,
+		"192.168.1.7":         true,
+		"::ffff:10.0.0.1":     true,
+		"192.168.1.8":         false,
+		"11.0.0.1":            false,
+		"":                    false,
+		"not-an-ip-address:1": false,
+	}
+
+	for addr, want := range tests {
+		if got := token.Allowed(addr); got != want {
+			t.Errorf("Allowed(%q) = %v, want %v", addr, got, want)
+		}
+	}
+
+	if !(Token{}).Allowed("") {
+		t.Fatal("tokens without restriction must be allowed from everywhere")
+	}
+}
+
+func TestScopedSubject(t *testing.T) {
+	rdb := option.Must(rebac.NewDB(mem.NewBlobStore("rebac")))
+	read := permission.ID("test.token.read")
+	write := permission.ID("test.token.write")
+
+	for _, p := range []permission.ID{read, write} {
+		rdb.RegisterStaticRule(rebac.StaticRule{Source: Namespace, Relation: rebac.Relation(p), Target: rebac.Global})
+	}
+
+	repo := newTestRepo(t)
+
+	token := Token{ID: "1", Name: "scoped", Scopes: []permission.ID{read}}
+	if err := repo.Save(token); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := grantTokenRights(rdb, token.ID, CreationData{Permissions: []permission.ID{read, write}}); err != nil {
+		t.Fatal(err)
+	}
+
+	subject := newScopedSubject(newSubject(context.Background(), nil, repo, token, rdb), token.Scopes)
+	if !subject.HasPermission(read) || subject.Audit(read) != nil {
+		t.Fatal("scoped permission must be granted")
+	}
+
+	if subject.HasPermission(write) {
+		t.Fatal("permission outside of the scopes must not be granted")
+	}
+
+	var denied user.PermissionDeniedError
+	if err := subject.Audit(write); !errors.As(err, &denied) {
+		t.Fatalf("want permission denied, got %v", err)
+	}
+}
+
+func TestFlushUsage(t *testing.T) {
+	repo := newTestRepo(t, Token{ID: "1", UsageCount: 5})
+
+	var u usage
+	u.track("1", "10.0.0.1")
+	u.track("1", "10.0.0.2")
+	u.track("deleted", "10.0.0.3")
+
+	if err := NewFlushUsage(&sync.Mutex{}, repo, &u)(); err != nil {
+		t.Fatal(err)
+	}
+
+	token := option.Must(repo.FindByID("1")).Unwrap()
+	if token.UsageCount != 7 || token.LastUsedFrom != "10.0.0.2" || token.LastUsedAt.IsZero() {
+		t.Fatalf("unexpected usage: %+v", token)
+	}
+
+	if len(u.take()) != 0 {
+		t.Fatal("usages must have been taken")
+	}
+}
+
+func TestNotifyExpiring(t *testing.T) {
+	soon := time.Now().AddDate(0, 0, 3)
+	later := time.Now().AddDate(0, 2, 0)
+
+	repo := newTestRepo(t,
+		Token{ID: "soon", Name: "CI", NotifyMail: "ci@example.com", ValidUntil: xtime.Date{Day: soon.Day(), Month: soon.Month(), Year: soon.Year()}},
+		Token{ID: "later", Name: "Backup", NotifyMail: "ops@example.com", ValidUntil: xtime.Date{Day: later.Day(), Month: later.Month(), Year: later.Year()}},
+		Token{ID: "forever", Name: "Import", NotifyMail: "ops@example.com"},
+	)
+
+	bus := &eventstest.Recorder{}
+	notify := NewNotifyExpiring(&sync.Mutex{}, bus, testLoadGlobal(Settings{NotifyMail: "admin@example.com"}), repo, nil)
+
+	// the second run must not notify again
+	for range 2 {
+		if err := notify(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	evts := bus.Events()
+	if len(evts) != 1 {
+		t.Fatalf("want exactly 1 mail, got %d", len(evts))
+	}
+
+	evt := evts[0].(mail.SendMailRequested)
+	if len(evt.To) != 2 || evt.To[0].Address != "ci@example.com" || evt.To[1].Address != "admin@example.com" {
+		t.Fatalf("unexpected recipients: %v", evt.To)
+	}
+
+	if option.Must(repo.FindByID("soon")).Unwrap().ExpiryNotifiedAt.IsZero() {
+		t.Fatal("token must have been marked as notified")
+	}
+}
@@ -15,6 +15,10 @@ const (
 	InvalidOldPasswordErr                        InvalidOldPasswordError                        = "invalid old password"
 	InvalidEMailErr                              InvalidEMailError                              = "invalid email"
 	EMailAlreadyInUseErr                         EMailAlreadyInUseError                         = "email already in use"
+	PasswordTooShortErr                          PasswordPolicyError                            = "password too short"
+	PasswordTooWeakErr                           PasswordPolicyError                            = "password entropy too low"
+	PasswordReusedErr                            PasswordPolicyError                            = "password has been used before"
+	PasswordBreachedErr                          PasswordPolicyError                            = "password is known from data breaches"
 )
 
 type InvalidSubjectError string
@@ -70,3 +74,10 @@ type EMailAlreadyInUseError string
 func (e EMailAlreadyInUseError) Error() string {
 	return string(e)
 }
+
+// PasswordPolicyError is the cause of all violations of the configured [PasswordPolicy].
+type PasswordPolicyError string
+
+func (e PasswordPolicyError) Error() string {
+	return string(e)
+}
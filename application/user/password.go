@@ -19,7 +19,7 @@ import (
 	"unicode/utf8"
 )
 
-var noLoginErr = std.NewLocalizedError("Login nicht möglich", "Der Nutzer existiert nicht, das Konto ist deaktiviert oder das Passwort ist falsch.")
+var noLoginErr = std.NewLocalizedError("Login nicht möglich", "Der Nutzer existiert nicht, das Konto ist deaktiviert oder das Passwort ist falsch.").WithError(InvalidCredentialsErr)
 
 type Password string
 
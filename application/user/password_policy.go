@@ -0,0 +1,159 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package user
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	passwordvalidator "github.com/wagslane/go-password-validator"
+	"github.com/worldiety/enum"
+	"go.wdy.de/nago/application/settings"
+	"go.wdy.de/nago/pkg/std"
+)
+
+var _ = enum.Variant[settings.GlobalSettings, PasswordPolicy](
+	enum.Rename[PasswordPolicy]("nago.user.password.policy"),
+)
+
+// PasswordPolicy contains the configurable rules for new passwords. They are applied in addition to the
+// built-in rules of [Password.Validate] whenever a password is set by a user, by an administrator or by a
+// reset code. Randomly generated passwords and SSO managed users are not affected.
+type PasswordPolicy struct {
+	_ any `title:"Kennwortrichtlinie" description:"Zusätzliche Anforderungen an neue Kennwörter. Diese gelten bei der Registrierung, beim Ändern und beim Zurücksetzen eines Kennworts."`
+
+	MinLength    int           `json:"minLength,omitempty" label:"Mindestlänge" supportingText:"Die minimale Anzahl an Zeichen. Werte unter 8 haben keine Wirkung, da dies bereits das Minimum ist."`
+	MinEntropy   float64       `json:"minEntropy,omitempty" label:"Minimale Entropie in Bit" supportingText:"Die geschätzte Entropie muss mindestens diesem Wert entsprechen. Empfohlen sind Werte zwischen 60 und 80. Wenn leer, gilt die eingebaute Mindestkomplexität."`
+	History      int           `json:"history,omitempty" label:"Kennworthistorie" supportingText:"Die Anzahl der letzten Kennwörter, die nicht wiederverwendet werden dürfen. Wenn leer, darf nur das aktuelle Kennwort nicht erneut gesetzt werden."`
+	MaxAge       time.Duration `json:"maxAge,omitempty" label:"Maximales Kennwortalter" supportingText:"Nach dieser Zeit müssen Nutzer ihr Kennwort ändern. Wenn leer, laufen Kennwörter nicht ab."`
+	BreachedList string        `json:"breachedList,omitempty" label:"Verzeichnis kompromittierter Kennwörter" supportingText:"Ein lokales Verzeichnis mit SHA-1 Hashlisten im k-Anonymitätsformat, also je eine Datei pro Präfix (z.B. 5BAA6.txt) mit Zeilen der Form SUFFIX:ANZAHL. Wenn leer, wird nicht geprüft."`
+}
+
+func (p PasswordPolicy) GlobalSettings() bool { return true }
+
+// expired returns true, if the password of the given user is older than the allowed maximum age.
+func (p PasswordPolicy) expired(usr User, now time.Time) bool {
+	if p.MaxAge <= 0 || usr.NLSManagedUser || len(usr.PasswordHash) == 0 {
+		return false
+	}
+
+	return now.Sub(usr.LastPasswordChangedAt) > p.MaxAge
+}
+
+// check applies the policy to the new password of the given user. The usr may be the zero value for new users.
+func (p PasswordPolicy) check(pwd Password, usr User) error {
+	if p.MinLength > 0 && utf8.RuneCountInString(string(pwd)) < p.MinLength {
+		return std.NewLocalizedError("Kennwortrichtlinie", fmt.Sprintf("Das Kennwort muss mindestens %d Zeichen enthalten.", p.MinLength)).WithError(PasswordTooShortErr)
+	}
+
+	if p.MinEntropy > 0 && passwordvalidator.GetEntropy(string(pwd)) < p.MinEntropy {
+		return std.NewLocalizedError("Kennwortrichtlinie", "Das Kennwort ist zu leicht zu erraten. Verwenden Sie ein längeres Kennwort oder mehr unterschiedliche Zeichen.").WithError(PasswordTooWeakErr)
+	}
+
+	if p.reused(pwd, usr) {
+		return std.NewLocalizedError("Kennwortrichtlinie", "Dieses Kennwort wurde bereits verwendet. Bitte wählen Sie ein neues Kennwort.").WithError(PasswordReusedErr)
+	}
+
+	if p.BreachedList != "" {
+		breached, err := breachedPassword(p.BreachedList, pwd)
+		if err != nil {
+			return fmt.Errorf("cannot check breached password list: %w", err)
+		}
+
+		if breached {
+			return std.NewLocalizedError("Kennwortrichtlinie", "Dieses Kennwort ist aus Datenlecks bekannt und darf nicht verwendet werden.").WithError(PasswordBreachedErr)
+		}
+	}
+
+	return nil
+}
+
+// reused checks the current password and the last History passwords of the user.
+func (p PasswordPolicy) reused(pwd Password, usr User) bool {
+	if len(usr.PasswordHash) > 0 && pwd.CompareHashAndPassword(usr.Algorithm, usr.Salt, usr.PasswordHash) == nil {
+		return true
+	}
+
+	for i, h := range usr.PasswordHistory {
+		if i >= p.History {
+			break
+		}
+
+		if pwd.CompareHashAndPassword(h.Algorithm, h.Salt, h.PasswordHash) == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// rememberPassword moves the current credentials of the user into the history, which is capped at the History
+// of the policy. A zero History removes all remembered hashes.
+func (p PasswordPolicy) rememberPassword(usr *User) {
+	if len(usr.PasswordHash) > 0 {
+		usr.PasswordHistory = append([]PreviousPassword{{
+			Algorithm:    usr.Algorithm,
+			Salt:         usr.Salt,
+			PasswordHash: usr.PasswordHash,
+		}}, usr.PasswordHistory...)
+	}
+
+	if len(usr.PasswordHistory) > max(p.History, 0) {
+		usr.PasswordHistory = usr.PasswordHistory[:max(p.History, 0)]
+	}
+
+	if len(usr.PasswordHistory) == 0 {
+		usr.PasswordHistory = nil
+	}
+}
+
+// PreviousPassword holds the credentials of a former password, see [PasswordPolicy.History].
+type PreviousPassword struct {
+	Algorithm    HashAlgorithm `json:"algorithm,omitempty"`
+	Salt         []byte        `json:"salt,omitempty"`
+	PasswordHash []byte        `json:"passwordHash,omitempty"`
+}
+
+// breachedPassword looks up the password in a local copy of a breached password corpus. Just like the
+// range API of haveibeenpwned, the list is partitioned by the first 5 hex digits of the SHA-1 hash, so
+// that only a single small file must be scanned. A missing partition is treated as not breached.
+func breachedPassword(dir string, pwd Password) (bool, error) {
+	sum := sha1.Sum([]byte(pwd))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	file, err := os.Open(filepath.Join(dir, prefix+".txt"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if strings.EqualFold(line, suffix) {
+			return true, nil
+		}
+	}
+
+	return false, scanner.Err()
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package user
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.wdy.de/nago/application/permission"
+	"go.wdy.de/nago/application/settings"
+	"go.wdy.de/nago/pkg/data/mem"
+)
+
+func testPolicy(policy PasswordPolicy) settings.LoadGlobal {
+	return func(subject permission.Auditable, t reflect.Type) (settings.GlobalSettings, error) {
+		return policy, nil
+	}
+}
+
+func newPolicyUser(t *testing.T, repo *mem.Repository[User, ID], pwd Password) {
+	t.Helper()
+
+	salt, hash, err := pwd.Hash(Argon2IdMin)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.Save(User{ID: "1", Email: "a@example.com", Algorithm: Argon2IdMin, Salt: salt, PasswordHash: hash, LastPasswordChangedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPasswordPolicy_History(t *testing.T) {
+	repo := &mem.Repository[User, ID]{}
+	newPolicyUser(t, repo, "Erstes#Kennwort2026")
+
+	var mutex sync.Mutex
+	uc := NewChangeOtherPassword(&mutex, testPolicy(PasswordPolicy{History: 2}), repo)
+	admin := testSubject{id: "42", perms: []permission.ID{PermChangeOtherPassword}}
+
+	change := func(pwd Password) error {
+		return uc(admin, "1", pwd, pwd)
+	}
+
+	if err := change("Zweites#Kennwort2026"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := change("Erstes#Kennwort2026"); !errors.Is(err, PasswordReusedErr) {
+		t.Fatalf("want reused, got %v", err)
+	}
+
+	if err := change("Drittes#Kennwort2026"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := change("Viertes#Kennwort2026"); err != nil {
+		t.Fatal(err)
+	}
+
+	usr, _ := repo.Load("1")
+	if len(usr.PasswordHistory) != 2 {
+		t.Fatalf("want capped history of 2, got %d", len(usr.PasswordHistory))
+	}
+
+	// the first one is out of the history now
+	if err := change("Erstes#Kennwort2026"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPasswordPolicy_Check(t *testing.T) {
+	const pwd Password = "Sicheres#Kennwort2026"
+
+	if err := (PasswordPolicy{MinLength: 30}).check(pwd, User{}); !errors.Is(err, PasswordTooShortErr) {
+		t.Fatalf("want too short, got %v", err)
+	}
+
+	if err := (PasswordPolicy{MinEntropy: 200}).check(pwd, User{}); !errors.Is(err, PasswordTooWeakErr) {
+		t.Fatalf("want too weak, got %v", err)
+	}
+
+	dir := t.TempDir()
+	sum := sha1.Sum([]byte(pwd))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	list := "0018A45C4D1DEF81644B54AB7F969B88D65:1\n" + strings.ToLower(hash[5:]) + ":42\n"
+	if err := os.WriteFile(filepath.Join(dir, hash[:5]+".txt"), []byte(list), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := (PasswordPolicy{BreachedList: dir}).check(pwd, User{}); !errors.Is(err, PasswordBreachedErr) {
+		t.Fatalf("want breached, got %v", err)
+	}
+
+	if err := (PasswordPolicy{MinLength: 12, MinEntropy: 60, BreachedList: dir}).check("Anderes#Kennwort2026", User{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExpirePasswords(t *testing.T) {
+	repo := &mem.Repository[User, ID]{}
+	newPolicyUser(t, repo, "Erstes#Kennwort2026")
+
+	usr, _ := repo.Load("1")
+	usr.LastPasswordChangedAt = time.Now().Add(-48 * time.Hour)
+	if err := repo.Save(usr); err != nil {
+		t.Fatal(err)
+	}
+
+	var mutex sync.Mutex
+	if err := NewExpirePasswords(&mutex, testPolicy(PasswordPolicy{MaxAge: 72 * time.Hour}), repo)(); err != nil {
+		t.Fatal(err)
+	}
+
+	if usr, _ := repo.Load("1"); usr.RequirePasswordChange {
+		t.Fatal("password is not expired yet")
+	}
+
+	if err := NewExpirePasswords(&mutex, testPolicy(PasswordPolicy{MaxAge: 24 * time.Hour}), repo)(); err != nil {
+		t.Fatal(err)
+	}
+
+	if usr, _ := repo.Load("1"); !usr.RequirePasswordChange {
+		t.Fatal("password must be expired")
+	}
+}
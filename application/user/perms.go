@@ -41,6 +41,10 @@ var (
 	PermListGrantedPermissions = permission.Declare[ListGrantedPermissions]("nago.grant.listgrants", "List permissions for a users resource", "A user with that permission assigned can list granted permissions for specific user and resource.")
 
 	PermConsent = permission.Declare[Consent]("nago.user.consent_other", "Zustimmungen anderer Nutzer setzen", "Träger dieser Berechtigung können die Datenschutz, Nutzungsbedingungen oder sonstige Erlaubnisse in deren Namen zustimmen.")
+
+	PermLinkSingleSignOn = permission.Declare[LinkSingleSignOn]("nago.user.link_single_sign_on", "Nutzer für Single Sign-On freigeben", "Träger dieser Berechtigung können ein lokales Konto an einen Identitätsanbieter übergeben, dessen Anmeldung mit derselben E-Mail-Adresse das Konto dann übernimmt.")
+
+	PermMarkMailUndeliverable = permission.Declare[MarkMailUndeliverable]("nago.user.mark_mail_undeliverable", "E-Mail als unzustellbar markieren", "Träger dieser Berechtigung können die E-Mail Adresse eines Nutzers als unzustellbar kennzeichnen.")
 )
 
 var Permissions = []permission.ID{
@@ -64,4 +68,6 @@ var Permissions = []permission.ID{
 	PermListGrantedUsers,
 	PermListGrantedPermissions,
 	PermConsent,
+	PermMarkMailUndeliverable,
+	PermLinkSingleSignOn,
 }
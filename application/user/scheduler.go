@@ -0,0 +1,44 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package user
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+type ScheduleOptions struct {
+	ExpireInterval time.Duration // default is 1 hour, within this interval outdated passwords are flagged
+}
+
+// StartScheduler starts a new scheduler instance, which enforces the maximum password age of the
+// [PasswordPolicy] by invoking expire periodically until the context is done.
+func StartScheduler(ctx context.Context, opts ScheduleOptions, expire ExpirePasswords) {
+	if opts.ExpireInterval == 0 {
+		opts.ExpireInterval = time.Hour
+	}
+
+	go func() {
+		slog.Info("user scheduler started")
+		ticker := time.NewTicker(opts.ExpireInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				slog.Info("user scheduler stopped")
+				return
+			case <-ticker.C:
+				if err := expire(); err != nil {
+					slog.Error("user scheduler cannot expire passwords", "err", err)
+				}
+			}
+		}
+	}()
+}
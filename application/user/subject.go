@@ -242,6 +242,15 @@ func (v *viewImpl) HasResourcePermission(name rebac.Namespace, id rebac.Instance
 }
 
 func (v *viewImpl) AuditResource(name rebac.Namespace, id rebac.Instance, p permission.ID) error {
+	return permission.Report(permission.Decision{
+		Subject:    string(v.ID()),
+		Permission: p,
+		Namespace:  string(name),
+		Instance:   string(id),
+	}, v.auditResource(name, id, p))
+}
+
+func (v *viewImpl) auditResource(name rebac.Namespace, id rebac.Instance, p permission.ID) error {
 	if !v.HasResourcePermission(name, id, p) {
 		var permName = string(p)
 		if perm, ok := permission.Find(p); ok {
@@ -255,6 +264,10 @@ func (v *viewImpl) AuditResource(name rebac.Namespace, id rebac.Instance, p perm
 }
 
 func (v *viewImpl) Audit(perm permission.ID) error {
+	return permission.Report(permission.Decision{Subject: string(v.ID()), Permission: perm}, v.audit(perm))
+}
+
+func (v *viewImpl) audit(perm permission.ID) error {
 	usr := v.refresh()
 
 	if v.user.ID == "" {
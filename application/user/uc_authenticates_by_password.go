@@ -8,11 +8,21 @@
 package user
 
 import (
+	"errors"
+
+	"go.wdy.de/nago/application/lockout"
 	"go.wdy.de/nago/pkg/std"
 )
 
 const EMailNotVerifiedErr std.Error = "email not verified"
 
+// InvalidCredentialsErr is wrapped by all errors of a login with an unknown account or a wrong password. Other
+// authenticators should wrap it as well, so that failed attempts are counted per account and
+// per remote address.
+const InvalidCredentialsErr std.Error = "invalid credentials"
+
+// NewAuthenticatesByPassword checks the local password of the user. It does not throttle failed attempts by
+// itself, see [NewThrottledAuthenticateByPassword].
 func NewAuthenticatesByPassword(userByMail FindByMail, system SysUser) AuthenticateByPassword {
 	return func(email Email, password Password) (std.Option[User], error) {
 
@@ -50,3 +60,43 @@ func NewAuthenticatesByPassword(userByMail FindByMail, system SysUser) Authentic
 		return optUsr, nil
 	}
 }
+
+// NewThrottledAuthenticateByPassword counts the failed attempts of authenticate per account, thus it can wrap any
+// chain of authenticators, including external directories. A failed attempt is an error which wraps
+// [InvalidCredentialsErr] or a result without a user.
+func NewThrottledAuthenticateByPassword(userByMail FindByMail, system SysUser, throttle lockout.UseCases, authenticate AuthenticateByPassword) AuthenticateByPassword {
+	return func(email Email, password Password) (std.Option[User], error) {
+		optUsr, err := userByMail(system(), email)
+		if err != nil {
+			return std.None[User](), err
+		}
+
+		// security note: unknown accounts are throttled just like existing ones, otherwise an attacker could
+		// tell them apart
+		key := lockout.MailKey(string(email))
+		if optUsr.IsSome() {
+			key = lockout.UserKey(string(optUsr.Unwrap().ID))
+		}
+
+		if err := throttle.Check(key); err != nil {
+			return std.None[User](), err
+		}
+
+		optUsr, err = authenticate(email, password)
+		if errors.Is(err, InvalidCredentialsErr) || (err == nil && optUsr.IsNone()) {
+			if err := throttle.Fail(key); err != nil {
+				return std.None[User](), err
+			}
+		}
+
+		return optUsr, err
+	}
+}
+
+func failLogin(throttle lockout.UseCases, key lockout.Key, cause error) error {
+	if err := throttle.Fail(key); err != nil {
+		return err
+	}
+
+	return cause
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package user
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.wdy.de/nago/application/lockout"
+	"go.wdy.de/nago/application/permission"
+	"go.wdy.de/nago/application/settings"
+	blobmem "go.wdy.de/nago/pkg/blob/mem"
+	"go.wdy.de/nago/pkg/data"
+	"go.wdy.de/nago/pkg/data/json"
+	"go.wdy.de/nago/pkg/data/mem"
+	"go.wdy.de/nago/pkg/std"
+)
+
+func newAuthenticateFixture(t *testing.T, usr User) AuthenticateByPassword {
+	t.Helper()
+
+	return newThrottledFixture(t, usr, func(userByMail FindByMail) AuthenticateByPassword {
+		return NewAuthenticatesByPassword(userByMail, SU)
+	})
+}
+
+func newThrottledFixture(t *testing.T, usr User, authenticator func(userByMail FindByMail) AuthenticateByPassword) AuthenticateByPassword {
+	t.Helper()
+
+	repo := &mem.Repository[User, ID]{}
+	if err := repo.Save(usr); err != nil {
+		t.Fatal(err)
+	}
+
+	notifyRepo := data.NewNotifyRepository[User, ID](nil, repo)
+	loadGlobal := func(subject permission.Auditable, t reflect.Type) (settings.GlobalSettings, error) {
+		return lockout.Settings{AccountLimit: 3, Delay: time.Nanosecond}, nil
+	}
+
+	attempts := json.NewSloppyJSONRepository[lockout.Attempts, lockout.Key](blobmem.NewBlobStore("lockout"))
+	throttle := lockout.NewUseCases(&syncBus{}, attempts, loadGlobal)
+
+	findByMail := NewFindByMail(notifyRepo, NewUserIndex(notifyRepo))
+	return NewThrottledAuthenticateByPassword(findByMail, SU, throttle, authenticator(findByMail))
+}
+
+func TestAuthenticatesByPassword_Lockout(t *testing.T) {
+	salt, hash, err := Password("correct horse").Hash(Argon2IdMin)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authenticate := newAuthenticateFixture(t, User{
+		ID:            "1",
+		Email:         "alice@example.com",
+		EMailVerified: true,
+		Algorithm:     Argon2IdMin,
+		Salt:          salt,
+		PasswordHash:  hash,
+	})
+
+	for _, mail := range []Email{"alice@example.com", "unknown@example.com"} {
+		for range 3 {
+			time.Sleep(time.Millisecond)
+			if _, err := authenticate(mail, "wrong"); !errors.Is(err, InvalidCredentialsErr) {
+				t.Fatalf("want invalid credentials for %s, got %v", mail, err)
+			}
+		}
+
+		// security note: even the correct password must be rejected and unknown accounts behave the same
+		if _, err := authenticate(mail, "correct horse"); !errors.Is(err, lockout.TooManyAttemptsErr) {
+			t.Fatalf("want lock for %s, got %v", mail, err)
+		}
+	}
+}
+
+func TestThrottledAuthenticateByPassword_External(t *testing.T) {
+	var calls int
+	authenticate := newThrottledFixture(t, User{ID: "1", Email: "alice@example.com"}, func(userByMail FindByMail) AuthenticateByPassword {
+		return func(email Email, password Password) (std.Option[User], error) {
+			calls++
+			if password != "correct horse" {
+				return std.None[User](), std.NewLocalizedError("Login nicht möglich", "wrong").WithError(InvalidCredentialsErr)
+			}
+
+			return userByMail(SU(), email)
+		}
+	})
+
+	for range 3 {
+		time.Sleep(time.Millisecond)
+		if _, err := authenticate("alice@example.com", "wrong"); !errors.Is(err, InvalidCredentialsErr) {
+			t.Fatalf("want invalid credentials, got %v", err)
+		}
+	}
+
+	if _, err := authenticate("alice@example.com", "correct horse"); !errors.Is(err, lockout.TooManyAttemptsErr) {
+		t.Fatalf("want lock, got %v", err)
+	}
+
+	if calls != 3 {
+		t.Fatalf("a locked account must not reach the authenticator, got %d calls", calls)
+	}
+}
@@ -12,9 +12,11 @@ import (
 	"fmt"
 	"sync"
 	"time"
+
+	"go.wdy.de/nago/application/settings"
 )
 
-func NewChangeMyPassword(mutex *sync.Mutex, repo Repository) ChangeMyPassword {
+func NewChangeMyPassword(mutex *sync.Mutex, loadGlobal settings.LoadGlobal, repo Repository) ChangeMyPassword {
 	return func(subject AuditableUser, oldPassword, newPassword, newRepeated Password) error {
 		mutex.Lock()
 		defer mutex.Unlock() // this is really harsh and allows intentionally only to change one user per second
@@ -55,12 +57,18 @@ func NewChangeMyPassword(mutex *sync.Mutex, repo Repository) ChangeMyPassword {
 			return err
 		}
 
+		policy := settings.ReadGlobal[PasswordPolicy](loadGlobal)
+		if err := policy.check(newPassword, usr); err != nil {
+			return err
+		}
+
 		// create new credentials
 		newSalt, newHash, err := newPassword.Hash(Argon2IdMin)
 		if err != nil {
 			return err
 		}
 
+		policy.rememberPassword(&usr)
 		usr.Salt = newSalt
 		usr.PasswordHash = newHash
 		usr.Algorithm = Argon2IdMin
@@ -51,6 +51,8 @@ func applyEmailChange(bus events.Bus, repo Repository, idx *UserIndex, usr User,
 
 	oldMail := usr.Email
 	usr.Email = newEmail
+	usr.MailUndeliverable = false
+	usr.MailUndeliverableReason = ""
 
 	if !opts.KeepVerified {
 		usr.EMailVerified = false
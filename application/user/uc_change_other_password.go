@@ -9,12 +9,14 @@ package user
 
 import (
 	"fmt"
-	"go.wdy.de/nago/pkg/std"
 	"sync"
 	"time"
+
+	"go.wdy.de/nago/application/settings"
+	"go.wdy.de/nago/pkg/std"
 )
 
-func NewChangeOtherPassword(mutex *sync.Mutex, repo Repository) ChangeOtherPassword {
+func NewChangeOtherPassword(mutex *sync.Mutex, loadGlobal settings.LoadGlobal, repo Repository) ChangeOtherPassword {
 	return func(subject AuditableUser, uid ID, newPassword Password, newRepeated Password) error {
 		if err := subject.Audit(PermChangeOtherPassword); err != nil {
 			return err
@@ -50,12 +52,18 @@ func NewChangeOtherPassword(mutex *sync.Mutex, repo Repository) ChangeOtherPassw
 			// whatever, ignore any error (e.g. either discontinued hash or just a different password), thus continue and write over
 		}
 
+		policy := settings.ReadGlobal[PasswordPolicy](loadGlobal)
+		if err := policy.check(newPassword, usr); err != nil {
+			return err
+		}
+
 		// create new credentials
 		newSalt, newHash, err := newPassword.Hash(Argon2IdMin)
 		if err != nil {
 			return err
 		}
 
+		policy.rememberPassword(&usr)
 		usr.Salt = newSalt
 		usr.PasswordHash = newHash
 		usr.Algorithm = Argon2IdMin
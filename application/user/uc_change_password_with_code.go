@@ -8,13 +8,22 @@
 package user
 
 import (
-	"go.wdy.de/nago/pkg/std"
 	"sync"
 	"time"
+
+	"go.wdy.de/nago/application/lockout"
+	"go.wdy.de/nago/pkg/std"
 )
 
-func NewChangePasswordWithCode(mutex *sync.Mutex, su SysUser, repo Repository, changePwd ChangeOtherPassword) ChangePasswordWithCode {
+func NewChangePasswordWithCode(mutex *sync.Mutex, su SysUser, repo Repository, changePwd ChangeOtherPassword, throttle lockout.UseCases) ChangePasswordWithCode {
 	return func(uid ID, code string, newPassword Password, newRepeated Password) error {
+		// security note: the code is a shorter secret than a password, thus guessing it must share the
+		// same budget of failed attempts as the password login of the account
+		key := lockout.UserKey(string(uid))
+		if err := throttle.Check(key); err != nil {
+			return err
+		}
+
 		optUser, err := repo.FindByID(uid)
 		if err != nil {
 			return err
@@ -23,21 +32,21 @@ func NewChangePasswordWithCode(mutex *sync.Mutex, su SysUser, repo Repository, c
 		accountErr := std.NewLocalizedError("Kennwortänderung", "Das Konto existiert nicht, ist deaktiviert oder der Code ist bereits abgelaufen.").WithError(AccountVerificationFailed)
 		if optUser.IsNone() {
 			// security note: don't expose any detail
-			return accountErr
+			return failLogin(throttle, key, accountErr)
 		}
 
 		user := optUser.Unwrap()
 		if user.PasswordRequestCode.ValidUntil.Before(time.Now()) {
-			return accountErr
+			return failLogin(throttle, key, accountErr)
 		}
 
 		if len(user.PasswordRequestCode.Value) < 6 {
 			// security note: don't fool ourselves
-			return accountErr
+			return failLogin(throttle, key, accountErr)
 		}
 
 		if user.PasswordRequestCode.Value != code {
-			return accountErr
+			return failLogin(throttle, key, accountErr)
 		}
 
 		if !user.Enabled() {
@@ -48,6 +57,10 @@ func NewChangePasswordWithCode(mutex *sync.Mutex, su SysUser, repo Repository, c
 			return err
 		}
 
+		if err := throttle.Succeed(key); err != nil {
+			return err
+		}
+
 		mutex.Lock()
 		defer mutex.Unlock()
 
@@ -67,6 +80,8 @@ func NewChangePasswordWithCode(mutex *sync.Mutex, su SysUser, repo Repository, c
 		// security note: it is ok, to verify and remove any need for the verification code,
 		// if the password has been reset by code which was also by mail; likely, just as [ConfirmMail].
 		user.EMailVerified = true
+		user.MailUndeliverable = false
+		user.MailUndeliverableReason = ""
 
 		return repo.Save(user)
 	}
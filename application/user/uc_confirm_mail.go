@@ -51,6 +51,8 @@ func NewConfirmMail(mutex *sync.Mutex, repository Repository) ConfirmMail {
 
 		user.EMailVerified = true
 		user.VerificationCode = Code{}
+		user.MailUndeliverable = false
+		user.MailUndeliverableReason = ""
 
 		return repository.Save(user)
 	}
@@ -55,6 +55,10 @@ func NewCreate(mutex *sync.Mutex, rdb *rebac.DB, loadGlobal settings.LoadGlobal,
 			if err := model.Password.Validate(); err != nil {
 				return User{}, err
 			}
+
+			if err := settings.ReadGlobal[PasswordPolicy](loadGlobal).check(model.Password, User{}); err != nil {
+				return User{}, err
+			}
 		}
 
 		salt, hash, err := model.Password.Hash(Argon2IdMin)
@@ -0,0 +1,68 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package user
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.wdy.de/nago/application/settings"
+)
+
+func NewExpirePasswords(mutex *sync.Mutex, loadGlobal settings.LoadGlobal, repo Repository) ExpirePasswords {
+	return func() error {
+		policy := settings.ReadGlobal[PasswordPolicy](loadGlobal)
+		if policy.MaxAge <= 0 {
+			return nil
+		}
+
+		now := time.Now()
+
+		// collect first, because we must not modify the repository while iterating
+		var expired []ID
+		for usr, err := range repo.All() {
+			if err != nil {
+				return fmt.Errorf("cannot iterate users: %w", err)
+			}
+
+			if !usr.RequirePasswordChange && policy.expired(usr, now) {
+				expired = append(expired, usr.ID)
+			}
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		for _, id := range expired {
+			// double-check, the password may have been changed in the meantime
+			optUsr, err := repo.FindByID(id)
+			if err != nil {
+				return fmt.Errorf("cannot find user: %w", err)
+			}
+
+			if optUsr.IsNone() {
+				continue
+			}
+
+			usr := optUsr.Unwrap()
+			if usr.RequirePasswordChange || !policy.expired(usr, now) {
+				continue
+			}
+
+			slog.Info("password expired", "user", usr.ID)
+			usr.RequirePasswordChange = true
+			if err := repo.Save(usr); err != nil {
+				return fmt.Errorf("cannot save user: %w", err)
+			}
+		}
+
+		return nil
+	}
+}
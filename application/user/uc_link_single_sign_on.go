@@ -0,0 +1,56 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package user
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"go.wdy.de/nago/pkg/std"
+)
+
+func NewLinkSingleSignOn(mutex *sync.Mutex, repo Repository) LinkSingleSignOn {
+	return func(subject AuditableUser, id ID) error {
+		if err := subject.Audit(PermLinkSingleSignOn); err != nil {
+			return err
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		optUsr, err := repo.FindByID(id)
+		if err != nil {
+			return fmt.Errorf("cannot find user by id: %w", err)
+		}
+
+		if optUsr.IsNone() {
+			return std.NewLocalizedError("Nutzer nicht aktualisiert", "Der Nutzer ist nicht (mehr) vorhanden.")
+		}
+
+		usr := optUsr.Unwrap()
+		if usr.NLSManagedUser {
+			return nil
+		}
+
+		// the same state, which the merge leaves behind, but the external identity is backfilled by the next
+		// login through the identity provider
+		usr.NLSManagedUser = true
+		usr.PasswordRequestCode = Code{}
+		usr.PasswordHash = nil
+		usr.PasswordHistory = nil
+		usr.RequirePasswordChange = false
+
+		if err := repo.Save(usr); err != nil {
+			return fmt.Errorf("cannot save user: %w", err)
+		}
+
+		slog.Info("linked local user for single sign-on", "user", usr.ID, "subject", subject.ID())
+		return nil
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package user
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"go.wdy.de/nago/application/permission"
+)
+
+func NewMarkMailUndeliverable(mutex *sync.Mutex, repo Repository, byMail FindByMail) MarkMailUndeliverable {
+	return func(subject permission.Auditable, mail Email, reason string) error {
+		if err := subject.Audit(PermMarkMailUndeliverable); err != nil {
+			return err
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		optUsr, err := byMail(SU(), Email(strings.TrimSpace(strings.ToLower(string(mail)))))
+		if err != nil {
+			return fmt.Errorf("cannot find user by mail: %w", err)
+		}
+
+		if optUsr.IsNone() {
+			return nil
+		}
+
+		usr := optUsr.Unwrap()
+		usr.MailUndeliverable = true
+		usr.MailUndeliverableReason = reason
+
+		return repo.Save(usr)
+	}
+}
@@ -80,6 +80,16 @@ func findSingleSignOnUser(repo Repository, idx *UserIndex, createData SingleSign
 			createData.Email, usr.NLSUserID, createData.ID, os.ErrPermission)
 	}
 
+	// security note: an identity provider may let its users pick any address, so a matching mail alone must
+	// not turn a local password account, which may even be an administrator, into an SSO account.
+	if createData.RequireLinked && !usr.NLSManagedUser {
+		slog.Error("refused sso login, mail belongs to a local account which has not been linked",
+			"user", usr.ID, "mail", createData.Email, "provided", createData.ID)
+
+		return std.None[User](), fmt.Errorf("mail %s belongs to a local account, which an administrator must link first: %w",
+			createData.Email, os.ErrPermission)
+	}
+
 	return optUsr, nil
 }
 
@@ -237,6 +247,7 @@ func NewMergeSingleSignOnUser(mutex *sync.Mutex, bus events.Bus, repo Repository
 		user.EMailVerified = true
 		user.PasswordRequestCode = Code{}
 		user.PasswordHash = nil
+		user.PasswordHistory = nil
 		user.RequirePasswordChange = false
 
 		// security note: DO NEVER merge default roles or groups. People keep requesting for that,
@@ -218,3 +218,34 @@ func TestMergeSingleSignOnUser_MatchesByIDDespiteOtherMail(t *testing.T) {
 		t.Fatalf("want the existing user, got %s", uid)
 	}
 }
+
+// TestMergeSingleSignOnUser_RequireLinkedRefusesLocalAccount covers identity providers, which let their users
+// choose an address: a matching mail must not take over a local password account until it has been linked.
+func TestMergeSingleSignOnUser_RequireLinkedRefusesLocalAccount(t *testing.T) {
+	merge, repo, _, _ := newMergeFixture(t, User{ID: "1", Email: "admin@example.com", PasswordHash: []byte("hash")})
+
+	_, err := merge(SingleSignOnUser{ID: "oidc-1", Email: "admin@example.com", RequireLinked: true}, nil)
+	if !errors.Is(err, os.ErrPermission) {
+		t.Fatalf("want permission error, got %v", err)
+	}
+
+	usr, _ := repo.Load("1")
+	if usr.NLSManagedUser || len(usr.PasswordHash) == 0 {
+		t.Fatal("the local account must be left untouched")
+	}
+
+	// after an administrator has linked the account, the next login takes it over
+	usr.NLSManagedUser = true
+	if err := repo.Save(usr); err != nil {
+		t.Fatal(err)
+	}
+
+	uid, err := merge(SingleSignOnUser{ID: "oidc-1", Email: "admin@example.com", RequireLinked: true}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if uid != "1" {
+		t.Fatalf("want the linked user, got %s", uid)
+	}
+}
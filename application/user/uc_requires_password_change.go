@@ -7,9 +7,14 @@
 
 package user
 
-import "fmt"
+import (
+	"fmt"
+	"time"
 
-func NewRequiresPasswordChange(sysUser SysUser, findByID FindByID) RequiresPasswordChange {
+	"go.wdy.de/nago/application/settings"
+)
+
+func NewRequiresPasswordChange(sysUser SysUser, loadGlobal settings.LoadGlobal, findByID FindByID) RequiresPasswordChange {
 	return func(uid ID) (bool, error) {
 		optUser, err := findByID(sysUser(), uid)
 		if err != nil {
@@ -22,7 +27,10 @@ func NewRequiresPasswordChange(sysUser SysUser, findByID FindByID) RequiresPassw
 		}
 
 		usr := optUser.Unwrap()
-		
-		return usr.RequirePasswordChange, nil
+
+		// the scheduler flags expired passwords only periodically, thus check the policy as well
+		policy := settings.ReadGlobal[PasswordPolicy](loadGlobal)
+
+		return usr.RequirePasswordChange || policy.expired(usr, time.Now()), nil
 	}
 }
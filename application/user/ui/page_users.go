@@ -15,8 +15,10 @@ import (
 	"go.wdy.de/nago/application/consent"
 	"go.wdy.de/nago/application/group"
 	"go.wdy.de/nago/application/localization/rstring"
+	"go.wdy.de/nago/application/lockout"
 	"go.wdy.de/nago/application/permission"
 	"go.wdy.de/nago/application/role"
+	"go.wdy.de/nago/application/session"
 	"go.wdy.de/nago/application/user"
 	"go.wdy.de/nago/pkg/xslices"
 	"go.wdy.de/nago/pkg/xstrings"
@@ -96,7 +98,7 @@ func (u UserModel) IntoUser() user.User {
 	}
 }
 
-func PageUsers(wnd core.Window, ucUsers user.UseCases, ucGroups group.UseCases, ucRoles role.UseCases, ucPermissions permission.UseCases) core.View {
+func PageUsers(wnd core.Window, ucUsers user.UseCases, ucGroups group.UseCases, ucRoles role.UseCases, ucPermissions permission.UseCases, ucLockout lockout.UseCases, logoutUser session.LogoutUser) core.View {
 	if err := wnd.Subject().Audit(user.PermFindAll); err != nil {
 		return alert.BannerError(err)
 	}
@@ -260,7 +262,7 @@ func PageUsers(wnd core.Window, ucUsers user.UseCases, ucGroups group.UseCases,
 			}),
 
 		ui.Space(ui.L64),
-		dlgEditUser(wnd, ucUsers, ucGroups, ucRoles, ucPermissions, editUserPresented, selectedUser),
+		dlgEditUser(wnd, ucUsers, ucGroups, ucRoles, ucPermissions, ucLockout, logoutUser, editUserPresented, selectedUser),
 		dlgCreateUserModel(wnd, ucUsers, createUserPresented),
 	).FullWidth().Alignment(ui.Leading)
 
@@ -284,7 +286,7 @@ func stateStr(usr user.User) string {
 	return "Aktiv"
 }
 
-func dlgEditUser(wnd core.Window, ucUsers user.UseCases, ucGroups group.UseCases, ucRoles role.UseCases, ucPermissions permission.UseCases, presented *core.State[bool], selectedUsr *core.State[user.User]) core.View {
+func dlgEditUser(wnd core.Window, ucUsers user.UseCases, ucGroups group.UseCases, ucRoles role.UseCases, ucPermissions permission.UseCases, ucLockout lockout.UseCases, logoutUser session.LogoutUser, presented *core.State[bool], selectedUsr *core.State[user.User]) core.View {
 	if !presented.Get() {
 		return nil
 	}
@@ -337,7 +339,7 @@ func dlgEditUser(wnd core.Window, ucUsers user.UseCases, ucGroups group.UseCases
 		return usrM
 	})
 
-	return alert.Dialog("Nutzer bearbeiten", ViewEditUser(wnd, ucUsers, ucGroups, ucRoles, ucPermissions, transientUserClone).Frame(ui.Frame{Height: ui.Full, Width: ui.Full}), presented, alert.Closeable(), alert.XLarge(), alert.FullHeight(), alert.Cancel(nil), alert.Save(func() (close bool) {
+	return alert.Dialog("Nutzer bearbeiten", ViewEditUser(wnd, ucUsers, ucGroups, ucRoles, ucPermissions, ucLockout, logoutUser, transientUserClone).Frame(ui.Frame{Height: ui.Full, Width: ui.Full}), presented, alert.Closeable(), alert.XLarge(), alert.FullHeight(), alert.Cancel(nil), alert.Save(func() (close bool) {
 		usr := transientUserClone.Get()
 		if err := ucUsers.UpdateOtherContact(wnd.Subject(), usr.ID, usr.Contact); err != nil {
 			alert.ShowBannerError(wnd, err)
@@ -17,7 +17,9 @@ import (
 	"go.wdy.de/nago/application/image"
 	httpimage "go.wdy.de/nago/application/image/http"
 	"go.wdy.de/nago/application/localization/rstring"
+	uimfa "go.wdy.de/nago/application/mfa/ui"
 	"go.wdy.de/nago/application/role"
+	uisession "go.wdy.de/nago/application/session/ui"
 	"go.wdy.de/nago/application/user"
 	"go.wdy.de/nago/pkg/xstrings"
 	"go.wdy.de/nago/presentation/core"
@@ -211,6 +213,26 @@ func actionCard(wnd core.Window, presentPasswordChange *core.State[bool], uid us
 			Trailing(ui.ImageIcon(heroSolid.ChevronRight)))
 	}
 
+	if mfaPages, ok := core.FromContext[uimfa.Pages](wnd.Context(), ""); ok && mfaPages.MyMFA != "" {
+		actionItems = append(actionItems, list.Entry().
+			Headline("Zwei-Faktor-Authentifizierung").
+			Action(func() {
+				wnd.Navigation().ForwardTo(mfaPages.MyMFA, nil)
+			}).
+			Frame(ui.Frame{Height: ui.L48}.FullWidth()).
+			Trailing(ui.ImageIcon(heroSolid.ChevronRight)))
+	}
+
+	if sessionPages, ok := core.FromContext[uisession.Pages](wnd.Context(), ""); ok && sessionPages.MyDevices != "" {
+		actionItems = append(actionItems, list.Entry().
+			Headline("Meine Geräte").
+			Action(func() {
+				wnd.Navigation().ForwardTo(sessionPages.MyDevices, nil)
+			}).
+			Frame(ui.Frame{Height: ui.L48}.FullWidth()).
+			Trailing(ui.ImageIcon(heroSolid.ChevronRight)))
+	}
+
 	return list.List(actionItems...).Frame(ui.Frame{}.FullWidth())
 }
 
@@ -9,8 +9,10 @@ package uiuser
 
 import (
 	"go.wdy.de/nago/application/group"
+	"go.wdy.de/nago/application/lockout"
 	"go.wdy.de/nago/application/permission"
 	"go.wdy.de/nago/application/role"
+	"go.wdy.de/nago/application/session"
 	"go.wdy.de/nago/application/user"
 	"go.wdy.de/nago/pkg/xslices"
 	"go.wdy.de/nago/presentation/core"
@@ -22,7 +24,7 @@ import (
 	"go.wdy.de/nago/presentation/ui/tabs"
 )
 
-func ViewEditUser(wnd core.Window, ucUsers user.UseCases, ucGroups group.UseCases, ucRoles role.UseCases, ucPermissions permission.UseCases, usr *core.State[UserModel]) ui.DecoredView {
+func ViewEditUser(wnd core.Window, ucUsers user.UseCases, ucGroups group.UseCases, ucRoles role.UseCases, ucPermissions permission.UseCases, ucLockout lockout.UseCases, logoutUser session.LogoutUser, usr *core.State[UserModel]) ui.DecoredView {
 	editContact := core.AutoState[contactViewModel](wnd).Init(func() contactViewModel {
 		return newContactViewModel(string(usr.Get().Email), usr.Get().Contact)
 	}).Observe(func(c contactViewModel) {
@@ -60,7 +62,7 @@ func ViewEditUser(wnd core.Window, ucUsers user.UseCases, ucGroups group.UseCase
 				return ui.Text("todo")
 			}).Icon(icons.Book).Disabled(true),
 			tabs.Page("Sonstiges", func() core.View {
-				return viewEtc(wnd, ucUsers, usr)
+				return viewEtc(wnd, ucUsers, ucLockout, logoutUser, usr)
 			}).Icon(icons.UserSettings),
 		).InputValue(pageIdx).Frame(ui.Frame{}.FullWidth()),
 	).FullWidth().Alignment(ui.Leading)
@@ -9,16 +9,19 @@ package uiuser
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 
+	"go.wdy.de/nago/application/lockout"
+	"go.wdy.de/nago/application/session"
 	"go.wdy.de/nago/application/user"
 	"go.wdy.de/nago/presentation/core"
 	"go.wdy.de/nago/presentation/ui"
 	"go.wdy.de/nago/presentation/ui/alert"
 )
 
-func viewEtc(wnd core.Window, ucUsers user.UseCases, usr *core.State[UserModel]) core.View {
+func viewEtc(wnd core.Window, ucUsers user.UseCases, ucLockout lockout.UseCases, logoutUser session.LogoutUser, usr *core.State[UserModel]) core.View {
 	presentedPwdChange := core.AutoState[bool](wnd)
 	presentedMailChange := core.AutoState[bool](wnd)
 
@@ -85,6 +88,9 @@ func viewEtc(wnd core.Window, ucUsers user.UseCases, usr *core.State[UserModel])
 			),
 		),
 
+		etcActionUnlock(wnd, ucLockout, usr),
+		etcActionLogoutUser(wnd, logoutUser, usr),
+
 		// security note: an unverified mail address blocks the login, thus this action must stay reachable
 		// even if no verification code is pending, e.g. after the mail address has been changed manually.
 		ui.If(!usr.Get().EMailVerified,
@@ -147,6 +153,35 @@ func viewEtc(wnd core.Window, ucUsers user.UseCases, usr *core.State[UserModel])
 				},
 			),
 		),
+
+		// security note: identity providers never take over a local account just by its mail address, thus an
+		// administrator must hand it over explicitly.
+		ui.If(!usr.Get().IntoUser().SSO() && wnd.Subject().HasPermission(user.PermLinkSingleSignOn),
+			etcAction(
+				wnd,
+				"Für Single Sign-On freigeben",
+				"Die nächste Anmeldung über einen Identitätsanbieter mit derselben E-Mail-Adresse übernimmt dieses Konto. Das Kennwort wird sofort gelöscht, eine Anmeldung mit Kennwort ist danach nicht mehr möglich. Es muss über einen sicheren Kanal bestätigt werden, dass die E-Mail-Adresse beim Identitätsanbieter wirklich dem Kontoinhaber gehört.",
+				"",
+				"Freigeben",
+				func() {
+					if err := ucUsers.LinkSingleSignOn(wnd.Subject(), usr.Get().ID); err != nil {
+						alert.ShowBannerError(wnd, err)
+						return
+					}
+
+					u := usr.Get()
+					u.NLSManagedUser = true
+					usr.Set(u)
+					usr.Notify()
+
+					alert.ShowBannerMessage(wnd, alert.Message{
+						Title:   "Nutzer freigegeben",
+						Message: "Der Nutzer " + usr.String() + " kann nun über Single Sign-On übernommen werden.",
+						Intent:  alert.IntentOk,
+					})
+				},
+			),
+		),
 	).FullWidth().Gap(ui.L32)
 }
 
@@ -259,6 +294,78 @@ func mailChangeOtherDialog(wnd core.Window, usr *core.State[UserModel], changeOt
 		))
 }
 
+// etcActionUnlock shows the failed login attempts of the account and allows to remove a lock before it expires.
+func etcActionUnlock(wnd core.Window, ucLockout lockout.UseCases, usr *core.State[UserModel]) core.View {
+	if !wnd.Subject().HasPermission(lockout.PermFindByKey) {
+		return nil
+	}
+
+	key := lockout.UserKey(string(usr.Get().ID))
+	attempts, err := ucLockout.FindByKey(wnd.Subject(), key)
+	if err != nil {
+		return alert.BannerError(err)
+	}
+
+	if attempts.Failures == 0 {
+		return nil
+	}
+
+	title := "Fehlversuche zurücksetzen"
+	text := fmt.Sprintf("Für dieses Konto gab es %d fehlgeschlagene Anmeldeversuche, zuletzt am %s. Mit jedem weiteren Fehlversuch muss länger gewartet werden.", attempts.Failures, attempts.LastFailure.Local().Format("02.01.2006 15:04"))
+	if attempts.Locked(time.Now()) {
+		title = "Konto entsperren"
+		text = fmt.Sprintf("Das Konto ist nach %d fehlgeschlagenen Anmeldeversuchen bis %s gesperrt. Ein Konto sollte nur entsperrt werden, wenn über einen sicheren Kanal bestätigt wurde, dass die Fehlversuche vom Kontoinhaber selbst stammen.", attempts.Failures, attempts.LockedUntil.Local().Format("02.01.2006 15:04"))
+	}
+
+	return etcAction(
+		wnd,
+		title,
+		text,
+		"",
+		title,
+		func() {
+			if err := ucLockout.Unlock(wnd.Subject(), key); err != nil {
+				alert.ShowBannerError(wnd, err)
+				return
+			}
+
+			alert.ShowBannerMessage(wnd, alert.Message{
+				Title:   "Konto entsperrt",
+				Message: "Die Fehlversuche von " + usr.String() + " wurden zurückgesetzt.",
+				Intent:  alert.IntentOk,
+			})
+		},
+	)
+}
+
+// etcActionLogoutUser ends all sessions of the user, e.g. after a device has been lost or compromised.
+func etcActionLogoutUser(wnd core.Window, logoutUser session.LogoutUser, usr *core.State[UserModel]) core.View {
+	if logoutUser == nil || !wnd.Subject().HasPermission(session.PermRevoke) {
+		return nil
+	}
+
+	return etcAction(
+		wnd,
+		"Überall abmelden",
+		"Der Nutzer wird sofort auf allen Geräten abgemeldet und geöffnete Fenster verlieren ihre Anmeldung. Das Konto bleibt aktiv, sodass sich der Nutzer erneut anmelden kann. Falls die Zugangsdaten kompromittiert sind, sollte zusätzlich das Konto deaktiviert oder das Kennwort geändert werden.",
+		"",
+		"Überall abmelden",
+		func() {
+			count, err := logoutUser(wnd.Subject(), usr.Get().ID)
+			if err != nil {
+				alert.ShowBannerError(wnd, err)
+				return
+			}
+
+			alert.ShowBannerMessage(wnd, alert.Message{
+				Title:   "Nutzer abgemeldet",
+				Message: fmt.Sprintf("%s wurde auf %d Geräten abgemeldet.", usr.String(), count),
+				Intent:  alert.IntentOk,
+			})
+		},
+	)
+}
+
 func etcActionExportUsers(wnd core.Window, action func()) core.View {
 	return etcAction(
 		wnd,
@@ -18,6 +18,7 @@ import (
 	"go.wdy.de/nago/application/consent"
 	"go.wdy.de/nago/application/group"
 	"go.wdy.de/nago/application/image"
+	"go.wdy.de/nago/application/lockout"
 	"go.wdy.de/nago/application/permission"
 	"go.wdy.de/nago/application/rebac"
 	"go.wdy.de/nago/application/role"
@@ -81,7 +82,9 @@ type SysUser func() Subject
 // change that, for logical and performance implications.
 type GetAnonUser func() Subject
 
-// AuthenticateByPassword checks mail and password and returns the view of the user to the caller.
+// AuthenticateByPassword checks mail and password and returns the view of the user to the caller. Failed attempts
+// are counted per account, but a correct password does not reset them, because a second factor may still be
+// pending. The caller must invoke [lockout.Succeed] for the [lockout.UserKey] once the login is complete.
 type AuthenticateByPassword func(email Email, password Password) (std.Option[User], error)
 
 type ConfirmMail func(userId ID, code string) error
@@ -92,6 +95,10 @@ type ResetPasswordRequestCode func(mail Email, lifetime time.Duration) (code str
 
 type RequiresPasswordChange func(uid ID) (bool, error)
 
+// ExpirePasswords requests a password change from all users, whose password is older than allowed by the
+// [PasswordPolicy]. See also [StartScheduler].
+type ExpirePasswords func() error
+
 type ChangePasswordWithCode func(uid ID, code string, newPassword Password, newRepeated Password) error
 
 // DisplayName leaks information details about the given user, if you already know that the ID is there.
@@ -104,6 +111,11 @@ type UpdateVerification func(subject permission.Auditable, id ID, verified bool)
 
 type UpdateVerificationByMail func(subject permission.Auditable, mail Email, verified bool) error
 
+// MarkMailUndeliverable flags the user with the given mail address, because a mail server has permanently
+// rejected it. The flag is removed, as soon as the address is changed or confirmed again. Unknown addresses
+// are ignored.
+type MarkMailUndeliverable func(subject permission.Auditable, mail Email, reason string) error
+
 type CountUsers func() (int, error)
 
 // Consent either approves or revokes a given consent. Usually, this is something caused by GDPR concerns.
@@ -129,7 +141,11 @@ type SingleSignOnUser struct {
 	// ID is the stable and opaque subject identifier of the identity provider. It is the primary matching
 	// criteria, because in contrast to the mail address it never changes. It may be empty, if the connected
 	// NLS does not provide it, in which case we fall back to mail based matching.
-	ID                NLSUserID
+	ID NLSUserID
+	// RequireLinked refuses to take over a local password account just because its mail address matches.
+	// Such an account must be linked by an administrator first, see [LinkSingleSignOn]. Set this for identity
+	// providers, which are not under the control of the operator or which let users choose their address.
+	RequireLinked     bool
 	Firstname         string
 	Lastname          string
 	Name              string
@@ -171,9 +187,13 @@ func (u SingleSignOnUser) LastName() string {
 
 // MergeSingleSignOnUser accepts the given user credentials as verified and trusted. If any existing user
 // is found with the same mail address, it will be marked as SSO-managed and the password-login and profile editing
-// is disabled.
+// is disabled, unless [SingleSignOnUser.RequireLinked] is set.
 type MergeSingleSignOnUser func(user SingleSignOnUser, avatar []byte) (ID, error)
 
+// LinkSingleSignOn marks a local account as SSO-managed and disables its password login. The next login of an
+// identity provider with the same mail address takes it over, even if [SingleSignOnUser.RequireLinked] is set.
+type LinkSingleSignOn func(subject AuditableUser, id ID) error
+
 type ExportFormat int
 
 const (
@@ -230,6 +250,9 @@ type UseCases struct {
 	Consent                  Consent
 	ExportUsers              ExportUsers
 	MergeSingleSignOnUser    MergeSingleSignOnUser
+	LinkSingleSignOn         LinkSingleSignOn
+	ExpirePasswords          ExpirePasswords
+	MarkMailUndeliverable    MarkMailUndeliverable
 
 	// deprecated: use rules api
 	AddResourcePermissions AddResourcePermissions
@@ -251,7 +274,7 @@ type UseCases struct {
 	Resources rebac.Resources
 }
 
-func NewUseCases(ctx func() context.Context, eventBus events.EventBus, rdb *rebac.DB, loadGlobal settings.LoadGlobal, users data.NotifyRepository[User, ID], roles data.ReadRepository[role.Role, role.ID], groups group.FindAll, findRoleByID role.FindByID, listRolePerms role.ListPermissions, createSrcSet image.CreateSrcSet) UseCases {
+func NewUseCases(ctx func() context.Context, eventBus events.EventBus, rdb *rebac.DB, loadGlobal settings.LoadGlobal, users data.NotifyRepository[User, ID], roles data.ReadRepository[role.Role, role.ID], groups group.FindAll, findRoleByID role.FindByID, listRolePerms role.ListPermissions, createSrcSet image.CreateSrcSet, throttle lockout.UseCases) UseCases {
 	// note: the user index attaches itself to the repository, thus it must be created before any
 	// writing use case, otherwise updates would be lost.
 	idx := NewUserIndex(users)
@@ -266,12 +289,12 @@ func NewUseCases(ctx func() context.Context, eventBus events.EventBus, rdb *reba
 	systemFn := NewSystem(ctx)
 	enableBootstrapAdminFn := NewEnableBootstrapAdmin(users, systemFn, findByMailFn, rdb)
 
-	changeMyPasswordFn := NewChangeMyPassword(&globalLock, users)
-	changeOtherPasswordFn := NewChangeOtherPassword(&globalLock, users)
-	changePasswordWithCodeFn := NewChangePasswordWithCode(&globalLock, systemFn, users, changeOtherPasswordFn)
+	changeMyPasswordFn := NewChangeMyPassword(&globalLock, loadGlobal, users)
+	changeOtherPasswordFn := NewChangeOtherPassword(&globalLock, loadGlobal, users)
+	changePasswordWithCodeFn := NewChangePasswordWithCode(&globalLock, systemFn, users, changeOtherPasswordFn, throttle)
 	deleteFn := NewDelete(users)
 
-	authenticateByPasswordFn := NewAuthenticatesByPassword(findByMailFn, systemFn)
+	authenticateByPasswordFn := NewThrottledAuthenticateByPassword(findByMailFn, systemFn, throttle, NewAuthenticatesByPassword(findByMailFn, systemFn))
 	subjectFromUserFn := NewViewOf(ctx, eventBus, users, rdb)
 
 	readMyContactFn := NewReadMyContact(users)
@@ -284,7 +307,7 @@ func NewUseCases(ctx func() context.Context, eventBus events.EventBus, rdb *reba
 
 	confirmMailFn := NewConfirmMail(&globalLock, users)
 	resetVerificationCodeFn := NewResetVerificationCode(&globalLock, users)
-	requiresPasswordChangeFn := NewRequiresPasswordChange(systemFn, findByIdFn)
+	requiresPasswordChangeFn := NewRequiresPasswordChange(systemFn, loadGlobal, findByIdFn)
 	resetPasswordRequestCodeFn := NewResetPasswordRequestCode(&globalLock, systemFn, users, findByMailFn)
 
 	findAllIdentsFn := NewFindAllIdentifiers(users)
@@ -331,6 +354,9 @@ func NewUseCases(ctx func() context.Context, eventBus events.EventBus, rdb *reba
 		ListGrantedUsers:          NewListGrantedUsers(rdb),
 		ExportUsers:               NewExportUsers(users),
 		MergeSingleSignOnUser:     NewMergeSingleSignOnUser(&globalLock, eventBus, users, idx, loadGlobal, createSrcSet, rdb),
+		LinkSingleSignOn:          NewLinkSingleSignOn(&globalLock, users),
+		ExpirePasswords:           NewExpirePasswords(&globalLock, loadGlobal, users),
+		MarkMailUndeliverable:     NewMarkMailUndeliverable(&globalLock, users, findByMailFn),
 		ListGroups:                NewListGroups(rdb),
 		ListRoles:                 NewListRoles(rdb),
 		ListGlobalPermissions:     NewListGlobalPermissions(rdb),
@@ -202,21 +202,26 @@ func (c Code) IsZero() bool {
 type NLSUserID string
 
 type User struct {
-	ID                    ID            `json:"id"`
-	Email                 Email         `json:"email"`
-	Contact               Contact       `json:"contact,omitzero"`
-	Salt                  []byte        `json:"salt,omitempty"`
-	Algorithm             HashAlgorithm `json:"algorithm,omitempty"`
-	PasswordHash          []byte        `json:"passwordHash,omitempty"`
-	LastPasswordChangedAt time.Time     `json:"lastPasswordChangedAt"`
-	CreatedAt             time.Time     `json:"createdAt"`
-	EMailVerified         bool          `json:"emailVerified,omitempty"`
-	Status                AccountStatus `json:"status,omitempty"`
-	RequirePasswordChange bool          `json:"requirePasswordChange,omitempty"`
-	NLSManagedUser        bool          `json:"nls,omitempty"`
-	NLSUserID             NLSUserID     `json:"nlsUserId,omitempty"`
-	VerificationCode      Code          `json:"verificationCode,omitzero"`
-	PasswordRequestCode   Code          `json:"passwordRequestCode,omitzero"`
+	ID                    ID                 `json:"id"`
+	Email                 Email              `json:"email"`
+	Contact               Contact            `json:"contact,omitzero"`
+	Salt                  []byte             `json:"salt,omitempty"`
+	Algorithm             HashAlgorithm      `json:"algorithm,omitempty"`
+	PasswordHash          []byte             `json:"passwordHash,omitempty"`
+	LastPasswordChangedAt time.Time          `json:"lastPasswordChangedAt"`
+	PasswordHistory       []PreviousPassword `json:"passwordHistory,omitempty"`
+	CreatedAt             time.Time          `json:"createdAt"`
+	EMailVerified         bool               `json:"emailVerified,omitempty"`
+	Status                AccountStatus      `json:"status,omitempty"`
+	RequirePasswordChange bool               `json:"requirePasswordChange,omitempty"`
+	NLSManagedUser        bool               `json:"nls,omitempty"`
+	NLSUserID             NLSUserID          `json:"nlsUserId,omitempty"`
+	VerificationCode      Code               `json:"verificationCode,omitzero"`
+	PasswordRequestCode   Code               `json:"passwordRequestCode,omitzero"`
+
+	// MailUndeliverable is set, if a mail server has permanently rejected the Email, see [MarkMailUndeliverable].
+	MailUndeliverable       bool   `json:"mailUndeliverable,omitempty"`
+	MailUndeliverableReason string `json:"mailUndeliverableReason,omitempty"`
 
 	// some legal/regulatory properties
 	Consents []consent.Consent `json:"consents,omitzero"`
@@ -253,8 +253,9 @@ func setup(cfg *application.Configurator) (*app, error) {
 
 	// ---- Message-Store (msgstore) einrichten -------------------------------
 	msgEng, err := db.Engine("accounts", ndb.EngineOptions{
-		Kind:   msgstore.EngineKind,
-		Config: msgstore.Options{},
+		Kind: msgstore.EngineKind,
+		// im Debug-Modus wird jeder Payload gegen das registrierte Schema geprüft
+		Config: msgstore.Options{ValidateSchemas: cfg.IsDebug()},
 	})
 	if err != nil {
 		return nil, err
@@ -0,0 +1,131 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/worldiety/option"
+)
+
+// BatchOp is a single write within a [Batch].
+type BatchOp struct {
+	Key string
+	// Value is written, unless Delete is true.
+	Value  []byte
+	Delete bool
+}
+
+// Batch collects multiple puts and deletes, which shall be applied together, see [Apply]. Operations are applied
+// in order, thus for the same key, the last operation wins. The zero value is an empty batch.
+type Batch struct {
+	ops []BatchOp
+}
+
+// Put adds a write of the given value. The slice is not copied, thus it must not be modified until the batch
+// has been applied.
+func (b *Batch) Put(key string, value []byte) {
+	b.ops = append(b.ops, BatchOp{Key: key, Value: value})
+}
+
+// Delete adds the removal of the given key. Just like [Deleter.Delete], removing a non-existent entry is fine.
+func (b *Batch) Delete(key string) {
+	b.ops = append(b.ops, BatchOp{Key: key, Delete: true})
+}
+
+// Len returns the amount of collected operations.
+func (b *Batch) Len() int {
+	return len(b.ops)
+}
+
+// Ops returns the collected operations in order.
+func (b *Batch) Ops() []BatchOp {
+	return b.ops
+}
+
+// Transactional is an optional capability of a [Store], which applies multiple writes at once. See also the
+// general notes on [Store] why this is not a generic transaction: there are neither reads nor isolation
+// guarantees, just an all-or-nothing commit of blind writes.
+type Transactional interface {
+	// Apply writes all operations of the batch atomically, so that after a crash either all or none of them
+	// are visible. If the context is cancelled before the commit, nothing is written.
+	Apply(ctx context.Context, batch Batch) error
+}
+
+// Apply writes all operations of the batch into the store. If the store implements [Transactional], the batch is
+// committed atomically. Otherwise, the operations are applied one by one and on failure, the previous values of
+// already written keys are restored on a best-effort basis. This fallback is not crash safe and concurrent readers
+// may see intermediate states.
+func Apply(ctx context.Context, store Store, batch Batch) error {
+	if batch.Len() == 0 {
+		return nil
+	}
+
+	if tx, ok := store.(Transactional); ok {
+		return tx.Apply(ctx, batch)
+	}
+
+	type undo struct {
+		key  string
+		prev option.Opt[[]byte]
+	}
+
+	var undos []undo
+	var err error
+	for _, op := range batch.Ops() {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+
+		var prev option.Opt[[]byte]
+		prev, err = Get(store, op.Key)
+		if err != nil {
+			break
+		}
+
+		undos = append(undos, undo{key: op.Key, prev: prev})
+
+		if op.Delete {
+			err = store.Delete(ctx, op.Key)
+		} else {
+			err = Put(store, op.Key, op.Value)
+		}
+
+		if err != nil {
+			break
+		}
+	}
+
+	if err == nil {
+		return nil
+	}
+
+	// roll back in reverse order, so that the oldest value of a key which has been written multiple times wins
+	var rollbackErrs []error
+	for _, u := range slices.Backward(undos) {
+		var e error
+		if u.prev.IsSome() {
+			e = Put(store, u.key, u.prev.Unwrap())
+		} else {
+			e = store.Delete(context.Background(), u.key)
+		}
+
+		if e != nil {
+			rollbackErrs = append(rollbackErrs, e)
+		}
+	}
+
+	if len(rollbackErrs) > 0 {
+		return fmt.Errorf("blob: batch failed and rollback is incomplete: %w", errors.Join(append([]error{err}, rollbackErrs...)...))
+	}
+
+	return err
+}
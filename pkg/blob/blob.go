@@ -87,6 +87,7 @@ type Counter interface {
 // Providing a transactional closure will also either provide surprising behavior or deadlocks by definition
 // (start a read transaction and nest a write transaction - what shall happen?). Massive scalable cloud systems
 // are also only scalable and fast, if used in a non-transactional and eventual-consistent way.
+// However, some stores can commit a batch of blind writes atomically, see [Transactional] and [Apply].
 type Store interface {
 	// List takes a snapshot of all available entries and returns an iterator for it.
 	// While iterating, any operation on the dataset can be performed without blocking, however
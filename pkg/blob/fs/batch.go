@@ -0,0 +1,197 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package fs
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/worldiety/option"
+	"go.wdy.de/nago/pkg/blob"
+	"go.wdy.de/nago/pkg/blob/tdb"
+)
+
+var _ blob.Transactional = (*BlobStore)(nil)
+
+// Apply first writes the payloads of all puts into their deduplicated data files and afterward commits all
+// inode and reference count changes atomically into the meta data index, see [tdb.DB.Batch]. Just like the
+// [BlobStore.NewWriter], a crash in between leaves at worst some unreferenced data files behind.
+func (b *BlobStore) Apply(ctx context.Context, batch blob.Batch) error {
+	ops := batch.Ops()
+	hashes := make([]string, len(ops))
+	for i, op := range ops {
+		if op.Delete {
+			continue
+		}
+
+		hash, err := b.writeData(op.Value)
+		if err != nil {
+			return err
+		}
+
+		hashes[i] = hash
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	b.dirLock.Lock()
+	defer b.dirLock.Unlock()
+
+	// track the state as it evolves throughout the batch, so that multiple ops on the same key or hash add up
+	paths := map[string]option.Opt[string]{}
+	counts := map[string]int64{}
+
+	currentHash := func(key string) (option.Opt[string], error) {
+		if h, ok := paths[key]; ok {
+			return h, nil
+		}
+
+		var ind inode
+		ok, err := b.readMeta(b.bucketNamePaths, key, &ind)
+		if err != nil || !ok {
+			return option.None[string](), err
+		}
+
+		return option.Some(ind.Sha512), nil
+	}
+
+	addCount := func(hash string, delta int64) error {
+		if _, ok := counts[hash]; !ok {
+			var ifo dataInfo
+			if _, err := b.readMeta(b.bucketNameRC, hash, &ifo); err != nil {
+				return err
+			}
+
+			counts[hash] = ifo.ReferenceCount
+		}
+
+		counts[hash] += delta
+		return nil
+	}
+
+	for i, op := range ops {
+		prev, err := currentHash(op.Key)
+		if err != nil {
+			return err
+		}
+
+		if prev.IsSome() {
+			if err := addCount(prev.Unwrap(), -1); err != nil {
+				return err
+			}
+		}
+
+		if op.Delete {
+			paths[op.Key] = option.None[string]()
+			continue
+		}
+
+		paths[op.Key] = option.Some(hashes[i])
+		if err := addCount(hashes[i], 1); err != nil {
+			return err
+		}
+	}
+
+	var dbOps []tdb.Op
+	for key, hash := range paths {
+		if hash.IsNone() {
+			dbOps = append(dbOps, tdb.Op{Bucket: b.bucketNamePaths, Key: key, Delete: true})
+			continue
+		}
+
+		buf, err := json.Marshal(inode{Sha512: hash.Unwrap()})
+		if err != nil {
+			return fmt.Errorf("cannot marshal inode: %w", err)
+		}
+
+		dbOps = append(dbOps, tdb.Op{Bucket: b.bucketNamePaths, Key: key, Value: buf})
+	}
+
+	for hash, count := range counts {
+		if count <= 0 {
+			dbOps = append(dbOps, tdb.Op{Bucket: b.bucketNameRC, Key: hash, Delete: true})
+			continue
+		}
+
+		buf, err := json.Marshal(dataInfo{ReferenceCount: count})
+		if err != nil {
+			return fmt.Errorf("cannot marshal dataInfo: %w", err)
+		}
+
+		dbOps = append(dbOps, tdb.Op{Bucket: b.bucketNameRC, Key: hash, Value: buf})
+	}
+
+	if err := b.db.Batch(dbOps); err != nil {
+		return fmt.Errorf("cannot commit batch to meta data index: %w", err)
+	}
+
+	// the meta data is consistent now, thus a failure to remove a data file just wastes a few bytes
+	for hash, count := range counts {
+		if count > 0 {
+			continue
+		}
+
+		if err := b.removeData(hash); err != nil {
+			return fmt.Errorf("rc dropped to 0, but cannot delete physical data file: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// readMeta decodes the json entry of the given bucket into dst and returns false, if no such entry exists.
+func (b *BlobStore) readMeta(bucket, key string, dst any) (bool, error) {
+	optReader := b.db.Get(bucket, key)
+	if optReader.IsNone() {
+		return false, nil
+	}
+
+	reader := optReader.Unwrap()
+	defer reader.Close()
+
+	if err := json.NewDecoder(reader).Decode(dst); err != nil {
+		return false, fmt.Errorf("cannot parse meta data of '%s' in bucket '%s': %w", key, bucket, err)
+	}
+
+	return true, nil
+}
+
+// writeData places the given payload into its fan-out location and returns its hash, just like the txWriter does.
+func (b *BlobStore) writeData(value []byte) (string, error) {
+	var rbuf [16]byte
+	if _, err := rand.Read(rbuf[:]); err != nil {
+		return "", fmt.Errorf("cannot get random bytes: %w", err)
+	}
+
+	tmpFname := filepath.Join(b.baseDir, hex.EncodeToString(rbuf[:])+".tmp")
+	if err := os.WriteFile(tmpFname, value, os.ModePerm); err != nil {
+		return "", fmt.Errorf("cannot write tmp file '%s': %w", tmpFname, err)
+	}
+
+	hash := sha512.Sum512_256(value)
+	sha := hex.EncodeToString(hash[:])
+	targetFname := b.filepath(sha)
+
+	if err := os.MkdirAll(filepath.Dir(targetFname), 0755); err != nil {
+		return "", fmt.Errorf("cannot create target fan-out directory '%s': %w", filepath.Dir(targetFname), err)
+	}
+
+	if err := os.Rename(tmpFname, targetFname); err != nil {
+		return "", fmt.Errorf("cannot rename tmp file '%s' to '%s': %w", tmpFname, targetFname, err)
+	}
+
+	return sha, nil
+}
@@ -62,6 +62,10 @@ type BlobStore struct {
 	bucketNamePaths string
 	bucketNameRC    string
 	name            string
+
+	snapshotMu      sync.Mutex
+	snapshots       int      // amount of open snapshots
+	pendingRemovals []string // hashes of data files, whose removal is deferred until all snapshots are closed
 }
 
 func NewBlobStore(baseDir string) (*BlobStore, error) {
@@ -176,9 +180,7 @@ func (b *BlobStore) Delete(ctx context.Context, key string) error {
 		}
 
 		// this was the last reference, thus remove it also from the filesystem
-		fname := b.filepath(ind.Sha512)
-		err := os.Remove(fname)
-		if err != nil && !os.IsNotExist(err) {
+		if err := b.removeData(ind.Sha512); err != nil {
 			// this may be a permission problem or just running on windows and having a Reader open...
 			return fmt.Errorf("rc dropped to 0, but cannot delete physical data file: %w", err)
 		}
@@ -0,0 +1,157 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package fs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"sync"
+
+	"go.wdy.de/nago/pkg/blob"
+	"go.wdy.de/nago/pkg/blob/tdb"
+	"go.wdy.de/nago/pkg/std"
+)
+
+var _ blob.Snapshotter = (*BlobStore)(nil)
+var _ blob.Freezer = (*BlobStore)(nil)
+
+// Freeze blocks all writers of the meta data index, see [tdb.DB.Freeze]. Data files may still be written, but
+// they only become visible after the index has been updated.
+func (b *BlobStore) Freeze() (unfreeze func()) {
+	return b.db.Freeze()
+}
+
+// Snapshot captures the current path index. Because data files are content addressed and never modified, the
+// snapshot just needs to defer the removal of unreferenced data files until it has been closed.
+func (b *BlobStore) Snapshot() (blob.Snapshot, error) {
+	// register before capturing the index, so that any removal which happens after the capture sees us
+	b.snapshotMu.Lock()
+	b.snapshots++
+	b.snapshotMu.Unlock()
+
+	return &snapshot{parent: b, paths: b.db.Snapshot(b.bucketNamePaths)}, nil
+}
+
+// removeData deletes the physical data file of the given hash or defers that, while snapshots are open.
+func (b *BlobStore) removeData(hash string) error {
+	b.snapshotMu.Lock()
+	defer b.snapshotMu.Unlock()
+
+	if b.snapshots > 0 {
+		b.pendingRemovals = append(b.pendingRemovals, hash)
+		return nil
+	}
+
+	if err := os.Remove(b.filepath(hash)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (b *BlobStore) releaseSnapshot() error {
+	b.dirLock.Lock()
+	defer b.dirLock.Unlock()
+
+	b.snapshotMu.Lock()
+	defer b.snapshotMu.Unlock()
+
+	b.snapshots--
+	if b.snapshots > 0 {
+		return nil
+	}
+
+	pending := b.pendingRemovals
+	b.pendingRemovals = nil
+
+	for _, hash := range pending {
+		// the same content may have been written again in the meantime
+		if b.db.Exists(b.bucketNameRC, hash) {
+			continue
+		}
+
+		if err := os.Remove(b.filepath(hash)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("cannot delete deferred physical data file: %w", err)
+		}
+	}
+
+	return nil
+}
+
+type snapshot struct {
+	parent *BlobStore
+	paths  *tdb.Snapshot
+	once   sync.Once
+}
+
+func (s *snapshot) List(ctx context.Context, opts blob.ListOptions) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		minK := opts.Prefix + opts.MinInc
+		maxK := opts.Prefix + opts.MaxInc
+		if opts.Prefix != "" && len(opts.MaxInc) == 0 {
+			maxK = nextPrefix(maxK)
+		}
+
+		entries := s.paths.AscendRange(minK, maxK)
+		if opts.Reverse {
+			entries = s.paths.DescendRange(minK, maxK)
+		}
+
+		for entry := range entries {
+			if !yield(entry.Key(), nil) {
+				return
+			}
+		}
+	}
+}
+
+func (s *snapshot) NewReader(ctx context.Context, key string) (std.Option[io.ReadCloser], error) {
+	optReader := s.paths.Get(key)
+	if optReader.IsNone() {
+		return std.None[io.ReadCloser](), nil
+	}
+
+	reader := optReader.Unwrap()
+	defer reader.Close()
+
+	var ind inode
+	if err := json.NewDecoder(reader).Decode(&ind); err != nil {
+		return std.None[io.ReadCloser](), fmt.Errorf("cannot parse inode meta data: %w", err)
+	}
+
+	f, err := os.Open(s.parent.filepath(ind.Sha512))
+	if err != nil {
+		return std.None[io.ReadCloser](), fmt.Errorf("cannot open physical data file: %w", err)
+	}
+
+	return std.Some[io.ReadCloser](f), nil
+}
+
+func (s *snapshot) Close() error {
+	var err error
+	s.once.Do(func() {
+		_ = s.paths.Close()
+		err = s.parent.releaseSnapshot()
+	})
+
+	return err
+}
+
+func nextPrefix(s string) string {
+	for i := len(s) - 1; i >= 0; i-- {
+		if s[i] < 0xFF {
+			return s[:i] + string(s[i]+1)
+		}
+	}
+
+	return ""
+}
@@ -15,6 +15,7 @@ import (
 	"slices"
 	"sort"
 	"strings"
+	"sync"
 
 	"go.wdy.de/nago/pkg/blob"
 	"go.wdy.de/nago/pkg/std"
@@ -22,13 +23,15 @@ import (
 )
 
 var _ blob.Store = (*BlobStore)(nil)
+var _ blob.Transactional = (*BlobStore)(nil)
 
 // BlobStore provides an in-memory implementation without transactions.
 // The transactions are just fake implementations to satisfy the contract and respect the read/write property.
 // However, the store itself is at least thread safe.
 type BlobStore struct {
-	name   string
-	values *xmaps.ConcurrentMap[string, []byte]
+	name    string
+	values  *xmaps.ConcurrentMap[string, []byte]
+	batchMu sync.Mutex
 }
 
 func (b *BlobStore) Name() string {
@@ -132,3 +135,26 @@ func (b *BlobStore) NewWriter(ctx context.Context, key string) (io.WriteCloser,
 		key:    key,
 	}, nil
 }
+
+// Apply writes all operations of the batch. Batches are serialized against each other, however concurrent
+// readers and single writes may observe an intermediate state. Because nothing is persisted, there is nothing
+// to be torn by a crash.
+func (b *BlobStore) Apply(ctx context.Context, batch blob.Batch) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	b.batchMu.Lock()
+	defer b.batchMu.Unlock()
+
+	for _, op := range batch.Ops() {
+		if op.Delete {
+			b.values.Delete(op.Key)
+			continue
+		}
+
+		b.values.Store(op.Key, slices.Clone(op.Value)) // defensive copy
+	}
+
+	return nil
+}
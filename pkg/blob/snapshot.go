@@ -0,0 +1,78 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+)
+
+// Snapshot is a read-only point-in-time view of a [Store]. Writes to the store are not visible in the
+// snapshot. A snapshot must be closed, because it usually retains resources of its store.
+type Snapshot interface {
+	// List behaves like [Store.List] but only returns the keys at the time the snapshot was taken.
+	List(ctx context.Context, opts ListOptions) iter.Seq2[string, error]
+	Reader
+	io.Closer
+}
+
+// Snapshotter is an optional capability of a [Store], which can capture a [Snapshot] cheaply, e.g. without
+// copying any payloads.
+type Snapshotter interface {
+	Snapshot() (Snapshot, error)
+}
+
+// Freezer is an optional capability of a [Store] to block all writers until unfreeze is called. Readers are not
+// affected. Calls can be nested and the last unfreeze releases the writers.
+type Freezer interface {
+	Freeze() (unfreeze func())
+}
+
+// SnapshotAll captures a consistent view across all given stores. Writers of stores implementing [Freezer] are
+// paused just while the snapshots are taken, which is usually a matter of milliseconds. All stores must implement
+// [Snapshotter].
+func SnapshotAll(stores []Store) ([]Snapshot, error) {
+	for _, store := range stores {
+		if _, ok := store.(Snapshotter); !ok {
+			return nil, fmt.Errorf("blob: store %s does not support snapshots", store.Name())
+		}
+	}
+
+	var unfreezes []func()
+	for _, store := range stores {
+		if f, ok := store.(Freezer); ok {
+			unfreezes = append(unfreezes, f.Freeze())
+		}
+	}
+
+	defer func() {
+		for _, unfreeze := range unfreezes {
+			unfreeze()
+		}
+	}()
+
+	res := make([]Snapshot, 0, len(stores))
+	for _, store := range stores {
+		snapshot, err := store.(Snapshotter).Snapshot()
+		if err != nil {
+			var errs []error
+			for _, s := range res {
+				errs = append(errs, s.Close())
+			}
+
+			return nil, fmt.Errorf("blob: cannot snapshot store %s: %w", store.Name(), errors.Join(append([]error{err}, errs...)...))
+		}
+
+		res = append(res, snapshot)
+	}
+
+	return res, nil
+}
@@ -35,6 +35,14 @@ type IndexEntry struct {
 	val Value
 }
 
+func (e IndexEntry) Key() string {
+	return e.key
+}
+
+func (e IndexEntry) Value() Value {
+	return e.val
+}
+
 type DB struct {
 	buckets           *xmaps.ConcurrentMap[string, *btree.BTreeG[IndexEntry]]
 	wal               *WAL
@@ -45,6 +53,10 @@ type DB struct {
 	tx                atomic.Uint64
 	btreeSnapshotLock sync.RWMutex
 	compactLock       sync.Mutex
+	snapshotGuard     sync.RWMutex // held for reading by each open Snapshot and for writing while compacting
+	writeGate         sync.RWMutex // held for reading by each writer and for writing while frozen
+	freezeMu          sync.Mutex
+	frozen            int
 	dir               string
 }
 
@@ -162,6 +174,9 @@ func (db *DB) Sync() error {
 
 // Set writes to the WAL and updates in-memory index tree.
 func (db *DB) Set(bucket, key string, value []byte) error {
+	db.writeGate.RLock()
+	defer db.writeGate.RUnlock()
+
 	// lock on our trees
 	db.btreeSnapshotLock.Lock()
 	defer db.btreeSnapshotLock.Unlock()
@@ -355,6 +370,9 @@ func (db *DB) Len(bucket string) int {
 
 // Delete removes the entry from the in-memory index and adds that to the WAL. Deleting non-existing entries is a no-op.
 func (db *DB) Delete(bucket, key string) error {
+	db.writeGate.RLock()
+	defer db.writeGate.RUnlock()
+
 	db.btreeSnapshotLock.Lock()
 	defer db.btreeSnapshotLock.Unlock()
 
@@ -375,6 +393,71 @@ func (db *DB) Delete(bucket, key string) error {
 	return db.wal.DeleteWithTx(unsafeStr(bucket), unsafeStr(key), db.tx.Add(1))
 }
 
+// Op describes a single write within a batch, see [DB.Batch].
+type Op struct {
+	Bucket string
+	Key    string
+	// Value is written, unless Delete is true.
+	Value  []byte
+	Delete bool
+}
+
+// Batch writes all operations atomically into the WAL and updates the in-memory index trees afterward.
+// Either all operations are applied or none of them, even after a crash, because replaying ignores a batch
+// without its commit marker. Operations may span different buckets and are applied in the given order,
+// thus for the same key, the last operation wins.
+func (db *DB) Batch(ops []Op) error {
+	if len(ops) == 0 {
+		return nil
+	}
+
+	db.writeGate.RLock()
+	defer db.writeGate.RUnlock()
+
+	db.btreeSnapshotLock.Lock()
+	defer db.btreeSnapshotLock.Unlock()
+
+	nodes := make([]Node, 0, len(ops))
+	for _, op := range ops {
+		n := Node{
+			kind:   setKeyValue,
+			bucket: unsafeStr(op.Bucket),
+			key:    unsafeStr(op.Key),
+			val:    op.Value,
+		}
+
+		if op.Delete {
+			n.kind = removeKeyValue
+			n.val = nil
+		}
+
+		nodes = append(nodes, n)
+	}
+
+	if err := db.wal.WriteBatch(nodes, db.tx.Add(1)); err != nil {
+		return err
+	}
+
+	for i, op := range ops {
+		tree, ok := db.buckets.Load(op.Bucket)
+		if !ok {
+			tree, _ = db.buckets.LoadOrStore(op.Bucket, newBtree())
+		}
+
+		if op.Delete {
+			tree.Delete(IndexEntry{key: op.Key})
+			continue
+		}
+
+		tree.ReplaceOrInsert(IndexEntry{
+			key: op.Key,
+			val: nodes[i].Value(),
+		})
+	}
+
+	return nil
+}
+
 // Compact writes a snapshot of the current in-memory state and persistent values to a new disk file.
 // It mostly runs concurrently, however if changes are made during compaction, the WAL will not be removed afterwards.
 // The DB performs also compaction on startup, which has the following advantages:
@@ -385,6 +468,10 @@ func (db *DB) Compact() error {
 	db.compactLock.Lock()
 	defer db.compactLock.Unlock()
 
+	// open snapshots still reference the offsets in the current files
+	db.snapshotGuard.Lock()
+	defer db.snapshotGuard.Unlock()
+
 	var tmp [16]byte
 	if _, err := rand.Read(tmp[:]); err != nil {
 		return err
@@ -11,6 +11,7 @@ import (
 	"bytes"
 	"io"
 	"math/rand"
+	"os"
 	"path/filepath"
 	"reflect"
 	"slices"
@@ -319,3 +320,156 @@ func TestDB_Races(t *testing.T) {
 		t.Fatalf("mismatched entries")
 	}
 }
+
+func TestDB_Batch(t *testing.T) {
+	dbdir := t.TempDir()
+	db, err := Open(dbdir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.Set("a", "old", []byte("x")); err != nil {
+		t.Fatal(err)
+	}
+
+	err = db.Batch([]Op{
+		{Bucket: "a", Key: "1", Value: []byte("hello")},
+		{Bucket: "b", Key: "2", Value: []byte("world")},
+		{Bucket: "a", Key: "old", Delete: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertValue := func(db *DB, bucket, key, want string) {
+		t.Helper()
+		optReader := db.Get(bucket, key)
+		if want == "" {
+			if optReader.IsSome() {
+				t.Fatalf("expected %s/%s to be absent", bucket, key)
+			}
+			return
+		}
+
+		if optReader.IsNone() {
+			t.Fatalf("missing entry %s/%s", bucket, key)
+		}
+
+		buf, err := io.ReadAll(optReader.Unwrap())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(buf) != want {
+			t.Fatalf("expected %s/%s=%q, got %q", bucket, key, want, buf)
+		}
+	}
+
+	assertValue(db, "a", "1", "hello")
+	assertValue(db, "b", "2", "world")
+	assertValue(db, "a", "old", "")
+
+	// this batch is torn by a simulated crash before the commit marker has been written
+	if err := db.Batch([]Op{{Bucket: "a", Key: "1", Value: []byte("torn")}, {Bucket: "b", Key: "3", Value: []byte("torn")}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	walFile := filepath.Join(dbdir, "tdb.wal")
+	info, err := os.Stat(walFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Truncate(walFile, info.Size()-3); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err = Open(dbdir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertValue(db, "a", "1", "hello")
+	assertValue(db, "b", "3", "")
+
+	// the incomplete tail has been cut off, thus appending afterward is fine
+	if err := db.Set("b", "4", []byte("after")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err = Open(dbdir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	assertValue(db, "a", "1", "hello")
+	assertValue(db, "b", "2", "world")
+	assertValue(db, "b", "3", "")
+	assertValue(db, "b", "4", "after")
+}
+
+func TestDB_Snapshot(t *testing.T) {
+	db, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if err := db.Set("a", "1", []byte("old")); err != nil {
+		t.Fatal(err)
+	}
+
+	unfreeze := db.Freeze()
+	snapshot := db.Snapshot("a")
+
+	written := make(chan error)
+	go func() {
+		written <- db.Set("a", "2", []byte("new"))
+	}()
+
+	select {
+	case <-written:
+		t.Fatal("expected writer to be blocked while frozen")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	unfreeze()
+	if err := <-written; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.Delete("a", "1"); err != nil {
+		t.Fatal(err)
+	}
+
+	var keys []string
+	for entry := range snapshot.AscendRange("", "") {
+		keys = append(keys, entry.Key())
+	}
+
+	if !slices.Equal(keys, []string{"1"}) {
+		t.Fatalf("unexpected snapshot keys: %v", keys)
+	}
+
+	buf, err := io.ReadAll(snapshot.Get("1").Unwrap())
+	if err != nil || string(buf) != "old" {
+		t.Fatalf("unexpected snapshot value %q: %v", buf, err)
+	}
+
+	if err := snapshot.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if db.Len("a") != 1 || !db.Exists("a", "2") {
+		t.Fatal("unexpected db state after snapshot")
+	}
+}
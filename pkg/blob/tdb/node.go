@@ -23,6 +23,10 @@ type nodeKind uint8
 const (
 	setKeyValue nodeKind = iota + 1
 	removeKeyValue
+	// beginTx and commitTx enclose the nodes of a batch, which all share the same tx. The nodes of a batch
+	// without a commit marker are discarded on replay.
+	beginTx
+	commitTx
 )
 
 type Node struct {
@@ -84,7 +88,7 @@ func (e *Node) read(r *xbytes.Buffer) error {
 	}
 
 	e.kind = nodeKind(kind)
-	if e.kind != setKeyValue && e.kind != removeKeyValue {
+	if e.kind < setKeyValue || e.kind > commitTx {
 		return InvalidNodeType
 	}
 
@@ -0,0 +1,123 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package tdb
+
+import (
+	"io"
+	"iter"
+	"sync"
+
+	"github.com/google/btree"
+	"go.wdy.de/nago/pkg/std"
+)
+
+// Snapshot is a read-only point-in-time view of a single bucket. Taking a snapshot is cheap, because the index
+// tree is cloned copy-on-write and values are just offsets into the append-only log files. A compaction would
+// invalidate these offsets and is therefore blocked until the snapshot has been closed.
+type Snapshot struct {
+	db     *DB
+	bucket string
+	tree   *btree.BTreeG[IndexEntry]
+	once   sync.Once
+}
+
+// Snapshot captures the current state of the given bucket. You must close the snapshot, otherwise
+// the DB can never be compacted again.
+func (db *DB) Snapshot(bucket string) *Snapshot {
+	db.snapshotGuard.RLock()
+
+	db.btreeSnapshotLock.RLock()
+	defer db.btreeSnapshotLock.RUnlock()
+
+	tree, ok := db.buckets.Load(bucket)
+	if ok {
+		tree = tree.Clone()
+	} else {
+		tree = newBtree()
+	}
+
+	return &Snapshot{db: db, bucket: bucket, tree: tree}
+}
+
+// Freeze blocks all writers until the returned func is called. Readers and snapshots are not affected, thus
+// freezing multiple databases and taking snapshots in between gives a consistent view across all of them.
+// Calls can be nested and the last unfreeze releases the writers.
+func (db *DB) Freeze() (unfreeze func()) {
+	db.freezeMu.Lock()
+	if db.frozen == 0 {
+		db.writeGate.Lock()
+	}
+	db.frozen++
+	db.freezeMu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			db.freezeMu.Lock()
+			defer db.freezeMu.Unlock()
+
+			db.frozen--
+			if db.frozen == 0 {
+				db.writeGate.Unlock()
+			}
+		})
+	}
+}
+
+func (s *Snapshot) Bucket() string {
+	return s.bucket
+}
+
+func (s *Snapshot) Len() int {
+	return s.tree.Len()
+}
+
+// Stat returns the Value metadata for the given key without reading the payload.
+func (s *Snapshot) Stat(key string) (Value, bool) {
+	entry, ok := s.tree.Get(IndexEntry{key: key})
+	return entry.val, ok
+}
+
+func (s *Snapshot) Get(key string) std.Option[io.ReadCloser] {
+	entry, ok := s.tree.Get(IndexEntry{key: key})
+	if !ok {
+		return std.Option[io.ReadCloser]{}
+	}
+
+	return std.Some(entry.val.NewReader())
+}
+
+// AscendRange behaves like [DB.AscendRange], thus maxKey is exclusive and empty keys denote the entire bucket.
+func (s *Snapshot) AscendRange(minKey, maxKey string) iter.Seq[IndexEntry] {
+	return func(yield func(IndexEntry) bool) {
+		if minKey == "" && maxKey == "" {
+			s.tree.Ascend(yield)
+			return
+		}
+
+		s.tree.AscendRange(IndexEntry{key: minKey}, IndexEntry{key: maxKey}, yield)
+	}
+}
+
+// DescendRange behaves like [DB.DescendRange].
+func (s *Snapshot) DescendRange(minKey, maxKey string) iter.Seq[IndexEntry] {
+	return func(yield func(IndexEntry) bool) {
+		if minKey == "" && maxKey == "" {
+			s.tree.Descend(yield)
+			return
+		}
+
+		s.tree.DescendRange(IndexEntry{key: maxKey}, IndexEntry{key: minKey}, yield)
+	}
+}
+
+// Close releases the snapshot, so that compaction can proceed. Afterward, readers must not be used anymore.
+func (s *Snapshot) Close() error {
+	s.once.Do(s.db.snapshotGuard.RUnlock)
+	return nil
+}
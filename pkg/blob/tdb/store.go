@@ -17,6 +17,10 @@ import (
 	"go.wdy.de/nago/pkg/std"
 )
 
+var _ blob.Transactional = (*BlobStore)(nil)
+var _ blob.Snapshotter = (*BlobStore)(nil)
+var _ blob.Freezer = (*BlobStore)(nil)
+
 type BlobStore struct {
 	db     *DB
 	bucket string
@@ -34,6 +38,14 @@ func (b *BlobStore) Name() string {
 }
 
 func (b *BlobStore) List(ctx context.Context, opts blob.ListOptions) iter.Seq2[string, error] {
+	return listRange(opts, func(minK, maxK string) iter.Seq[IndexEntry] {
+		return b.db.AscendRange(b.bucket, minK, maxK)
+	}, func(minK, maxK string) iter.Seq[IndexEntry] {
+		return b.db.DescendRange(b.bucket, minK, maxK)
+	})
+}
+
+func listRange(opts blob.ListOptions, ascend, descend func(minK, maxK string) iter.Seq[IndexEntry]) iter.Seq2[string, error] {
 	return func(yield func(string, error) bool) {
 		minK := opts.Prefix + opts.MinInc
 		maxK := opts.Prefix + opts.MaxInc
@@ -42,7 +54,7 @@ func (b *BlobStore) List(ctx context.Context, opts blob.ListOptions) iter.Seq2[s
 		}
 
 		if opts.Reverse {
-			for entry := range b.db.DescendRange(b.bucket, minK, maxK) {
+			for entry := range descend(minK, maxK) {
 				if !yield(entry.key, nil) {
 					return
 				}
@@ -51,13 +63,12 @@ func (b *BlobStore) List(ctx context.Context, opts blob.ListOptions) iter.Seq2[s
 			return
 		}
 
-		for entry := range b.db.AscendRange(b.bucket, minK, maxK) {
+		for entry := range ascend(minK, maxK) {
 			if !yield(entry.key, nil) {
 				return
 			}
 		}
 	}
-
 }
 
 func nextPrefix(s string) string {
@@ -104,6 +115,42 @@ func (b *BlobStore) NewWriter(ctx context.Context, key string) (io.WriteCloser,
 	}, nil
 }
 
+// Apply writes all operations of the batch atomically into the WAL, see [DB.Batch].
+func (b *BlobStore) Apply(ctx context.Context, batch blob.Batch) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ops := make([]Op, 0, batch.Len())
+	for _, op := range batch.Ops() {
+		ops = append(ops, Op{Bucket: b.bucket, Key: op.Key, Value: op.Value, Delete: op.Delete})
+	}
+
+	return b.db.Batch(ops)
+}
+
+// Freeze blocks all writers of the underlying DB, thus also of all other buckets, see [DB.Freeze].
+func (b *BlobStore) Freeze() (unfreeze func()) {
+	return b.db.Freeze()
+}
+
+// Snapshot captures the current state of the bucket, see [DB.Snapshot].
+func (b *BlobStore) Snapshot() (blob.Snapshot, error) {
+	return &blobSnapshot{Snapshot: b.db.Snapshot(b.bucket)}, nil
+}
+
+type blobSnapshot struct {
+	*Snapshot
+}
+
+func (s *blobSnapshot) List(ctx context.Context, opts blob.ListOptions) iter.Seq2[string, error] {
+	return listRange(opts, s.AscendRange, s.DescendRange)
+}
+
+func (s *blobSnapshot) NewReader(ctx context.Context, key string) (std.Option[io.ReadCloser], error) {
+	return s.Get(key), nil
+}
+
 func (b *BlobStore) Close() error {
 	return nil
 }
@@ -16,6 +16,7 @@ import (
 	"iter"
 	"log/slog"
 	"os"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -76,6 +77,9 @@ func NewWAL(f *lockedfile.File, replay func(entry *Node)) (*WAL, error) {
 	maxSize := 0
 	totalSize := int64(0)
 	var lastTx uint64
+	var pending []Node // nodes of an open batch, which are only replayed after its commit marker
+	inBatch := false
+	committedEnd := int64(0) // end of the last node, which has been applied
 	start := time.Now()
 	for node, err := range w.All() {
 		if err != nil {
@@ -87,16 +91,67 @@ func NewWAL(f *lockedfile.File, replay func(entry *Node)) (*WAL, error) {
 		}
 
 		lastTx = node.tx
-		count++
+
+		switch node.kind {
+		case beginTx:
+			inBatch = true
+			pending = pending[:0]
+			continue
+		case commitTx:
+			if !inBatch {
+				return nil, fmt.Errorf("commit marker without batch in tx %d", node.tx)
+			}
+
+			for i := range pending {
+				count++
+				if replay != nil {
+					replay(&pending[i])
+				}
+			}
+
+			inBatch = false
+			pending = pending[:0]
+			committedEnd = w.readPos
+			continue
+		}
+
 		if int(node.valLength) > maxSize {
 			maxSize = int(node.valLength)
 		}
 
 		totalSize += int64(node.valLength)
 
+		if inBatch {
+			// the node is re-used by the iterator, however the value is only referenced by its file offset
+			pending = append(pending, Node{
+				f:         node.f,
+				kind:      node.kind,
+				tx:        node.tx,
+				bucket:    slices.Clone(node.bucket),
+				key:       slices.Clone(node.key),
+				valOffset: node.valOffset,
+				valLength: node.valLength,
+			})
+			continue
+		}
+
+		count++
 		if replay != nil {
 			replay(node)
 		}
+
+		committedEnd = w.readPos
+	}
+
+	// an interrupted batch or a torn tail write must not be visible, and we must not append behind it either,
+	// because it would garble all following nodes
+	if committedEnd < w.size.Load() {
+		slog.Warn("tdb WAL contains an incomplete tail, truncating", "file", f.Name(), "size", w.size.Load(), "truncate", committedEnd)
+		if err := f.Truncate(committedEnd); err != nil {
+			return nil, fmt.Errorf("cannot truncate incomplete WAL tail: %w", err)
+		}
+
+		w.size.Store(committedEnd)
 	}
 
 	w.tx.Store(lastTx)
@@ -260,6 +315,51 @@ func (w *WAL) DeleteWithTx(bucket, key []byte, tx uint64) error {
 	return err
 }
 
+// WriteBatch appends the given set and remove nodes enclosed by a begin and commit marker using a single write
+// call. All nodes get the same tx. Replaying the WAL applies either all nodes of the batch or none of them.
+// The value offsets of the given nodes are updated, so that [Node.Value] can be used afterward.
+func (w *WAL) WriteBatch(nodes []Node, tx uint64) error {
+	if w.debugTx.Load() > tx {
+		panic("debug tx is smaller than tx to write")
+	}
+	w.debugTx.Store(tx)
+
+	w.writelock.Lock()
+	defer w.writelock.Unlock()
+
+	startOfBatchInFile := w.size.Load()
+	w.buf.Reset()
+	begin := Node{kind: beginTx, tx: tx}
+	begin.write(w.buf)
+	for i := range nodes {
+		nodes[i].tx = tx
+		nodes[i].write(w.buf)
+	}
+
+	commit := Node{kind: commitTx, tx: tx}
+	commit.write(w.buf)
+
+	buf := w.buf.Buf[:w.buf.Pos]
+	n, err := w.f.WriteAt(buf, startOfBatchInFile)
+	if err != nil {
+		// see write, a partial batch is also discarded on replay, but we must not append behind it
+		if err := w.f.Truncate(startOfBatchInFile); err != nil {
+			slog.Error("batch write to WAL failed and unable to truncate", "err", err)
+		}
+
+		return err
+	}
+
+	for i := range nodes {
+		nodes[i].f = w.f
+		nodes[i].valOffset += uint64(startOfBatchInFile)
+	}
+
+	w.size.Add(int64(n))
+
+	return nil
+}
+
 func (w *WAL) Close() error {
 	if err := w.f.Sync(); err != nil {
 		return err
@@ -0,0 +1,622 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package data
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/worldiety/option"
+	"go.wdy.de/nago/pkg/blob"
+	"go.wdy.de/nago/pkg/blob/mem"
+	"go.wdy.de/nago/pkg/xiter"
+)
+
+// ErrIndexConflict is returned (wrapped) by [IndexedRepository.Save], if a unique index value is already used by
+// another entity.
+var ErrIndexConflict = errors.New("unique index conflict")
+
+// IndexOptions configures a secondary index, see [WithIndex].
+type IndexOptions struct {
+	// Store keeps the index entries. It must be exclusive to the index. If nil, the index is kept in memory
+	// and rebuilt with each start, which is still O(n) once but O(log(n)) for each lookup afterward.
+	Store blob.Store
+
+	// Unique rejects saving an entity, whose index value is already used by another entity.
+	Unique bool
+}
+
+type secondaryIndex[E any] struct {
+	name    string
+	store   blob.Store
+	unique  bool
+	extract func(E) string
+}
+
+// IndexedRepository decorates a [Repository] and keeps its secondary indexes consistent with each Save and
+// Delete. Create it with [WithIndex]. Just like [NewNotifyRepository], batch operations are split up and
+// executed per entity.
+//
+// Index entries are written before and removed after the entity itself, so that a crash between both leaves a
+// stale entry but never a missing one. Stale entries are detected and skipped by all lookups, because each hit is
+// loaded and its value is extracted again. An index is only rebuilt automatically if its store is empty. If an
+// extractor changes its semantics, call [IndexedRepository.RebuildIndex].
+type IndexedRepository[E Aggregate[ID], ID IDType] struct {
+	other   Repository[E, ID]
+	mutex   sync.Mutex   // serializes writes, so that unique checks are not racy
+	lookMu  sync.RWMutex // guards indexes for readers, which must not wait for the writes under mutex
+	indexes map[string]*secondaryIndex[E]
+}
+
+var _ Repository[Aggregate[string], string] = (*IndexedRepository[Aggregate[string], string])(nil)
+
+// WithIndex declares a secondary index with the given name on repo. The extract function derives the indexed
+// value from an entity. Entities with an empty value are not indexed, which is useful for optional attributes.
+// If repo is already an [IndexedRepository], the index is added to it and the same instance is returned.
+// Otherwise, repo is decorated and all writes must go through the returned repository from now on.
+//
+// Values are ordered lexicographically by their bytes. The key of an entry contains the hex encoded identifier
+// but only a bounded prefix of the value, thus identifiers should not be much longer than those of [RandIdent].
+//
+//	users, err := data.WithIndex(repo, "email", func(u User) string {
+//		return strings.ToLower(string(u.Email))
+//	}, data.IndexOptions{Store: store, Unique: true})
+func WithIndex[E Aggregate[ID], ID IDType](repo Repository[E, ID], name string, extract func(E) string, opts IndexOptions) (*IndexedRepository[E, ID], error) {
+	r, ok := repo.(*IndexedRepository[E, ID])
+	if !ok {
+		r = &IndexedRepository[E, ID]{
+			other:   repo,
+			indexes: map[string]*secondaryIndex[E]{},
+		}
+	}
+
+	if name == "" {
+		return nil, fmt.Errorf("data: index name must not be empty")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.indexes[name]; ok {
+		return nil, fmt.Errorf("data: index %q already declared on %s", name, repo.Name())
+	}
+
+	store := opts.Store
+	if store == nil {
+		store = mem.NewBlobStore("index." + repo.Name() + "." + name)
+	}
+
+	idx := &secondaryIndex[E]{
+		name:    name,
+		store:   store,
+		unique:  opts.Unique,
+		extract: extract,
+	}
+
+	count, err := blob.Count(context.Background(), store)
+	if err != nil {
+		return nil, fmt.Errorf("data: cannot count index %q: %w", name, err)
+	}
+
+	if count == 0 {
+		if err := r.rebuild(idx); err != nil {
+			return nil, err
+		}
+	}
+
+	r.lookMu.Lock()
+	r.indexes[name] = idx
+	r.lookMu.Unlock()
+
+	return r, nil
+}
+
+// HasIndex reports whether an index with the given name has been declared.
+func (r *IndexedRepository[E, ID]) HasIndex(name string) bool {
+	_, ok := r.index(name)
+	return ok
+}
+
+// index returns the named index. It is safe to call concurrently with [WithIndex].
+func (r *IndexedRepository[E, ID]) index(name string) (*secondaryIndex[E], bool) {
+	r.lookMu.RLock()
+	defer r.lookMu.RUnlock()
+
+	idx, ok := r.indexes[name]
+	return idx, ok
+}
+
+// RebuildIndex discards all entries of the named index and recreates them from a full scan.
+func (r *IndexedRepository[E, ID]) RebuildIndex(name string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	idx, ok := r.indexes[name]
+	if !ok {
+		return fmt.Errorf("data: no such index: %q", name)
+	}
+
+	return r.rebuild(idx)
+}
+
+func (r *IndexedRepository[E, ID]) rebuild(idx *secondaryIndex[E]) error {
+	if err := blob.DeleteAll(idx.store); err != nil {
+		return fmt.Errorf("data: cannot clear index %q: %w", idx.name, err)
+	}
+
+	seen := map[string]ID{}
+	for e, err := range r.other.All() {
+		if err != nil {
+			return err
+		}
+
+		value := idx.extract(e)
+		if value == "" {
+			continue
+		}
+
+		if idx.unique {
+			if other, ok := seen[value]; ok {
+				return fmt.Errorf("data: index %q: value %q used by %v and %v: %w", idx.name, value, other, e.Identity(), ErrIndexConflict)
+			}
+			seen[value] = e.Identity()
+		}
+
+		if err := blob.Put(idx.store, indexKey(value, e.Identity()), []byte(value)); err != nil {
+			return fmt.Errorf("data: cannot write index %q: %w", idx.name, err)
+		}
+	}
+
+	return nil
+}
+
+// FindByIndex returns all entities whose value in the named index equals value, ordered by their identifiers.
+func (r *IndexedRepository[E, ID]) FindByIndex(name string, value string) iter.Seq2[E, error] {
+	idx, ok := r.index(name)
+	if !ok {
+		return xiter.WithError[E](fmt.Errorf("data: no such index: %q", name))
+	}
+
+	if value == "" {
+		return func(yield func(E, error) bool) {}
+	}
+
+	return r.lookup(idx, blob.ListOptions{Prefix: valueKey(value)}, func(string) bool {
+		return true
+	})
+}
+
+// FindOneByIndex is like [IndexedRepository.FindByIndex] but returns only the first match, which is what you
+// want for a unique index.
+func (r *IndexedRepository[E, ID]) FindOneByIndex(name string, value string) (option.Opt[E], error) {
+	for e, err := range r.FindByIndex(name, value) {
+		if err != nil {
+			return option.None[E](), err
+		}
+
+		return option.Some(e), nil
+	}
+
+	return option.None[E](), nil
+}
+
+// IterateIndexRange returns all entities whose value in the named index is within [from, to), ordered by their
+// value and identifier. An empty from or to is unbounded.
+func (r *IndexedRepository[E, ID]) IterateIndexRange(name string, from, to string) iter.Seq2[E, error] {
+	idx, ok := r.index(name)
+	if !ok {
+		return xiter.WithError[E](fmt.Errorf("data: no such index: %q", name))
+	}
+
+	// hex digits are greater than the separator, thus the encoded to is a tight exclusive upper bound. If to is
+	// truncated, all entries of its prefix must be included, which is done by the next character after the
+	// separator. The unbounded case uses a key greater than any hex digit. Note, that some stores treat MaxInc as
+	// exclusive.
+	opts := blob.ListOptions{
+		MinInc: hex.EncodeToString([]byte(indexPrefix(from))),
+		MaxInc: hex.EncodeToString([]byte(to)),
+	}
+
+	switch {
+	case to == "":
+		opts.MaxInc = "g"
+	case len(to) > indexPrefixLen:
+		opts.MaxInc = hex.EncodeToString([]byte(indexPrefix(to))) + "/"
+	}
+
+	return r.lookup(idx, opts, func(value string) bool {
+		return value >= from && (to == "" || value < to)
+	})
+}
+
+// lookup loads the entities of all matching index entries, skipping stale ones.
+func (r *IndexedRepository[E, ID]) lookup(idx *secondaryIndex[E], opts blob.ListOptions, accept func(value string) bool) iter.Seq2[E, error] {
+	return func(yield func(E, error) bool) {
+		var zero E
+
+		// entries with a truncated prefix are ordered by the hash of their value, thus collect and sort them
+		var group []indexEntry[ID]
+		var groupPrefix string
+		flush := func() bool {
+			slices.SortFunc(group, func(a, b indexEntry[ID]) int {
+				if c := strings.Compare(a.value, b.value); c != 0 {
+					return c
+				}
+
+				return strings.Compare(Idtos(a.id), Idtos(b.id))
+			})
+
+			for _, entry := range group {
+				if !r.load(idx, entry, accept, yield) {
+					return false
+				}
+			}
+
+			group = group[:0]
+			return true
+		}
+
+		for key, err := range idx.store.List(context.Background(), opts) {
+			if err != nil {
+				if !yield(zero, err) {
+					return
+				}
+
+				continue
+			}
+
+			prefix, id, err := parseIndexKey[ID](key)
+			if err != nil {
+				if !yield(zero, fmt.Errorf("data: index %q: %w", idx.name, err)) {
+					return
+				}
+
+				continue
+			}
+
+			if len(group) > 0 && prefix != groupPrefix {
+				if !flush() {
+					return
+				}
+			}
+
+			if len(prefix) < indexPrefixLen {
+				if !r.load(idx, indexEntry[ID]{value: prefix, id: id}, accept, yield) {
+					return
+				}
+
+				continue
+			}
+
+			optValue, err := blob.Get(idx.store, key)
+			if err != nil {
+				if !yield(zero, fmt.Errorf("data: index %q: %w", idx.name, err)) {
+					return
+				}
+
+				continue
+			}
+
+			if optValue.IsNone() {
+				continue // removed in the meantime
+			}
+
+			groupPrefix = prefix
+			group = append(group, indexEntry[ID]{value: string(optValue.Unwrap()), id: id})
+		}
+
+		flush()
+	}
+}
+
+type indexEntry[ID IDType] struct {
+	value string
+	id    ID
+}
+
+// load yields the entity of entry, if its value is accepted and the entry is not stale. It returns false, if
+// yield asked to stop.
+func (r *IndexedRepository[E, ID]) load(idx *secondaryIndex[E], entry indexEntry[ID], accept func(value string) bool, yield func(E, error) bool) bool {
+	if !accept(entry.value) {
+		return true
+	}
+
+	var zero E
+	optE, err := r.other.FindByID(entry.id)
+	if err != nil {
+		return yield(zero, err)
+	}
+
+	if optE.IsNone() || idx.extract(optE.Unwrap()) != entry.value {
+		return true
+	}
+
+	return yield(optE.Unwrap(), nil)
+}
+
+// usedBy returns the identifiers of all entities which currently have value in idx.
+func (r *IndexedRepository[E, ID]) usedBy(idx *secondaryIndex[E], value string) ([]ID, error) {
+	var ids []ID
+	for e, err := range r.lookup(idx, blob.ListOptions{Prefix: valueKey(value)}, func(string) bool { return true }) {
+		if err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, e.Identity())
+	}
+
+	return ids, nil
+}
+
+// indexPrefixLen is the number of leading bytes of a value, which are kept in the key of an index entry to
+// preserve the order. Together with the hash and the hex encoded identifier, a key stays below the file name
+// limit of 255 bytes for identifiers up to 80 bytes. The full value is stored as the content of the entry.
+const indexPrefixLen = 32
+
+func indexPrefix(value string) string {
+	if len(value) > indexPrefixLen {
+		return value[:indexPrefixLen]
+	}
+
+	return value
+}
+
+// valueKey returns the common prefix of the keys of all entries with the given value.
+func valueKey(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString([]byte(indexPrefix(value))) + "." + hex.EncodeToString(sum[:8]) + "."
+}
+
+func indexKey[ID IDType](value string, id ID) string {
+	return valueKey(value) + hex.EncodeToString([]byte(Idtos(id)))
+}
+
+// parseIndexKey returns the value prefix and the identifier of an index entry. The prefix equals the value, if
+// it is shorter than indexPrefixLen.
+func parseIndexKey[ID IDType](key string) (string, ID, error) {
+	var zero ID
+	encPrefix, rest, ok := strings.Cut(key, ".")
+	if !ok {
+		return "", zero, fmt.Errorf("invalid index key: %s", key)
+	}
+
+	_, encID, ok := strings.Cut(rest, ".")
+	if !ok {
+		return "", zero, fmt.Errorf("invalid index key: %s", key)
+	}
+
+	prefix, err := hex.DecodeString(encPrefix)
+	if err != nil {
+		return "", zero, fmt.Errorf("invalid index key: %s: %w", key, err)
+	}
+
+	rawID, err := hex.DecodeString(encID)
+	if err != nil {
+		return "", zero, fmt.Errorf("invalid index key: %s: %w", key, err)
+	}
+
+	id, err := Stoid[ID](string(rawID))
+	if err != nil {
+		return "", zero, fmt.Errorf("invalid index key: %s: %w", key, err)
+	}
+
+	return string(prefix), id, nil
+}
+
+func (r *IndexedRepository[E, ID]) FindByID(id ID) (option.Opt[E], error) {
+	return r.other.FindByID(id)
+}
+
+func (r *IndexedRepository[E, ID]) FindAllByPrefix(prefix ID) iter.Seq2[E, error] {
+	return r.other.FindAllByPrefix(prefix)
+}
+
+func (r *IndexedRepository[E, ID]) Identifiers() iter.Seq2[ID, error] {
+	return r.other.Identifiers()
+}
+
+func (r *IndexedRepository[E, ID]) IdentifiersByPrefix(prefix ID) iter.Seq2[ID, error] {
+	return r.other.IdentifiersByPrefix(prefix)
+}
+
+func (r *IndexedRepository[E, ID]) FindAllByID(ids iter.Seq[ID]) iter.Seq2[E, error] {
+	return r.other.FindAllByID(ids)
+}
+
+func (r *IndexedRepository[E, ID]) All() iter.Seq2[E, error] {
+	return r.other.All()
+}
+
+func (r *IndexedRepository[E, ID]) Count() (int, error) {
+	return r.other.Count()
+}
+
+func (r *IndexedRepository[E, ID]) Name() string {
+	return r.other.Name()
+}
+
+func (r *IndexedRepository[E, ID]) DeleteByID(id ID) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.deleteByID(id)
+}
+
+func (r *IndexedRepository[E, ID]) deleteByID(id ID) error {
+	optOld, err := r.other.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := r.other.DeleteByID(id); err != nil {
+		return err
+	}
+
+	if optOld.IsNone() {
+		return nil
+	}
+
+	for _, idx := range r.indexes {
+		if value := idx.extract(optOld.Unwrap()); value != "" {
+			if err := idx.store.Delete(context.Background(), indexKey(value, id)); err != nil {
+				return fmt.Errorf("data: cannot delete from index %q: %w", idx.name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *IndexedRepository[E, ID]) DeleteAll() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.other.DeleteAll(); err != nil {
+		return err
+	}
+
+	for _, idx := range r.indexes {
+		if err := blob.DeleteAll(idx.store); err != nil {
+			return fmt.Errorf("data: cannot clear index %q: %w", idx.name, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *IndexedRepository[E, ID]) DeleteAllByID(ids iter.Seq[ID]) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for id := range ids {
+		if err := r.deleteByID(id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *IndexedRepository[E, ID]) Delete(predicate func(E) (bool, error)) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// collect first, because most repositories do not tolerate nested calls
+	var ids []ID
+	for e, err := range r.other.All() {
+		if err != nil {
+			return err
+		}
+
+		accept, err := predicate(e)
+		if errors.Is(err, SkipAll) {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if accept {
+			ids = append(ids, e.Identity())
+		}
+	}
+
+	for _, id := range ids {
+		if err := r.deleteByID(id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *IndexedRepository[E, ID]) DeleteByEntity(e E) error {
+	return r.DeleteByID(e.Identity())
+}
+
+func (r *IndexedRepository[E, ID]) Save(e E) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.save(e)
+}
+
+func (r *IndexedRepository[E, ID]) save(e E) error {
+	id := e.Identity()
+	optOld, err := r.other.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	values := make(map[string]string, len(r.indexes))
+	for name, idx := range r.indexes {
+		value := idx.extract(e)
+		values[name] = value
+		if value == "" || !idx.unique {
+			continue
+		}
+
+		ids, err := r.usedBy(idx, value)
+		if err != nil {
+			return err
+		}
+
+		for _, other := range ids {
+			if other != id {
+				return fmt.Errorf("data: index %q: value %q already used by %v: %w", name, value, other, ErrIndexConflict)
+			}
+		}
+	}
+
+	for name, idx := range r.indexes {
+		if value := values[name]; value != "" {
+			if err := blob.Put(idx.store, indexKey(value, id), []byte(value)); err != nil {
+				return fmt.Errorf("data: cannot write index %q: %w", name, err)
+			}
+		}
+	}
+
+	if err := r.other.Save(e); err != nil {
+		return err
+	}
+
+	if optOld.IsNone() {
+		return nil
+	}
+
+	for name, idx := range r.indexes {
+		if old := idx.extract(optOld.Unwrap()); old != "" && old != values[name] {
+			if err := idx.store.Delete(context.Background(), indexKey(old, id)); err != nil {
+				return fmt.Errorf("data: cannot delete from index %q: %w", name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *IndexedRepository[E, ID]) SaveAll(it iter.Seq[E]) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for e := range it {
+		if err := r.save(e); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
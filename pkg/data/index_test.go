@@ -0,0 +1,156 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package data_test
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/worldiety/option"
+	"go.wdy.de/nago/pkg/blob"
+	"go.wdy.de/nago/pkg/blob/mem"
+	"go.wdy.de/nago/pkg/blob/tdb"
+	"go.wdy.de/nago/pkg/data"
+	"go.wdy.de/nago/pkg/data/json"
+)
+
+type Person struct {
+	ID   string
+	Mail string
+	City string
+}
+
+func (p Person) Identity() string {
+	return p.ID
+}
+
+func ids(t *testing.T, it iter.Seq2[Person, error]) []string {
+	t.Helper()
+	var res []string
+	for p, err := range it {
+		if err != nil {
+			t.Fatal(err)
+		}
+		res = append(res, p.ID)
+	}
+
+	return res
+}
+
+func TestWithIndex(t *testing.T) {
+	t.Run("mem", func(t *testing.T) {
+		testIndex(t, mem.NewBlobStore("persons.mail"), mem.NewBlobStore("persons.city"))
+	})
+
+	t.Run("tdb", func(t *testing.T) {
+		db := option.Must(tdb.Open(t.TempDir()))
+		defer db.Close()
+		testIndex(t, tdb.NewBlobStore(db, "persons.mail"), tdb.NewBlobStore(db, "persons.city"))
+	})
+}
+
+func testIndex(t *testing.T, mailStore, cityStore blob.Store) {
+	persons := json.NewSloppyJSONRepository[Person, string](mem.NewBlobStore("persons"))
+	option.MustZero(persons.Save(Person{ID: "1", Mail: "a@example.com", City: "Oldenburg"}))
+
+	repo := option.Must(data.WithIndex[Person, string](persons, "mail", func(p Person) string { return p.Mail }, data.IndexOptions{Store: mailStore, Unique: true}))
+	repo = option.Must(data.WithIndex[Person, string](repo, "city", func(p Person) string { return p.City }, data.IndexOptions{Store: cityStore}))
+
+	// existing entities are indexed on declaration
+	if got := option.Must(repo.FindOneByIndex("mail", "a@example.com")); got.IsNone() || got.Unwrap().ID != "1" {
+		t.Fatalf("expected person 1, got %v", got)
+	}
+
+	option.MustZero(repo.Save(Person{ID: "2", Mail: "b@example.com", City: "Bremen"}))
+	option.MustZero(repo.Save(Person{ID: "3", City: "Oldenburg"}))
+
+	if err := repo.Save(Person{ID: "4", Mail: "a@example.com"}); !errors.Is(err, data.ErrIndexConflict) {
+		t.Fatalf("expected unique conflict, got %v", err)
+	}
+
+	// re-saving the owner of a unique value is fine
+	option.MustZero(repo.Save(Person{ID: "1", Mail: "a@example.com", City: "Hamburg"}))
+
+	if got := ids(t, repo.FindByIndex("city", "Oldenburg")); !slices.Equal(got, []string{"3"}) {
+		t.Fatalf("unexpected city lookup: %v", got)
+	}
+
+	if got := ids(t, repo.IterateIndexRange("city", "Bremen", "Oldenburg")); !slices.Equal(got, []string{"2", "1"}) {
+		t.Fatalf("unexpected range: %v", got)
+	}
+
+	if got := ids(t, repo.IterateIndexRange("city", "C", "")); !slices.Equal(got, []string{"1", "3"}) {
+		t.Fatalf("unexpected open range: %v", got)
+	}
+
+	option.MustZero(repo.DeleteByID("1"))
+	if got := option.Must(repo.FindOneByIndex("mail", "a@example.com")); got.IsSome() {
+		t.Fatalf("expected deleted index entry, got %v", got)
+	}
+
+	// a stale entry, e.g. after a crash, is skipped
+	option.MustZero(persons.Save(Person{ID: "2", Mail: "c@example.com"}))
+	if got := ids(t, repo.FindByIndex("mail", "b@example.com")); len(got) != 0 {
+		t.Fatalf("expected stale entry to be skipped, got %v", got)
+	}
+
+	option.MustZero(repo.RebuildIndex("mail"))
+	if got := ids(t, repo.FindByIndex("mail", "c@example.com")); !slices.Equal(got, []string{"2"}) {
+		t.Fatalf("unexpected lookup after rebuild: %v", got)
+	}
+
+	// long values share a truncated key but keep their order
+	long := strings.Repeat("Wolfschlugen-Nürtingen-Oberensingen ", 20)
+	option.MustZero(repo.Save(Person{ID: "5", City: long + "c"}))
+	option.MustZero(repo.Save(Person{ID: "6", City: long + "a"}))
+	option.MustZero(repo.Save(Person{ID: "7", City: long + "b"}))
+
+	for key, err := range cityStore.List(context.Background(), blob.ListOptions{}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(key) > 255 {
+			t.Fatalf("index key too long: %d", len(key))
+		}
+	}
+
+	if got := ids(t, repo.FindByIndex("city", long+"b")); !slices.Equal(got, []string{"7"}) {
+		t.Fatalf("unexpected long lookup: %v", got)
+	}
+
+	if got := ids(t, repo.IterateIndexRange("city", long, long+"c")); !slices.Equal(got, []string{"6", "7"}) {
+		t.Fatalf("unexpected long range: %v", got)
+	}
+
+	if got := ids(t, repo.IterateIndexRange("city", "W", "")); !slices.Equal(got, []string{"6", "7", "5"}) {
+		t.Fatalf("unexpected open long range: %v", got)
+	}
+}
+
+func TestWithIndexWhileInUse(t *testing.T) {
+	persons := json.NewSloppyJSONRepository[Person, string](mem.NewBlobStore("persons"))
+	repo := option.Must(data.WithIndex[Person, string](persons, "mail", func(p Person) string { return p.Mail }, data.IndexOptions{}))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 100 {
+			repo.HasIndex("city")
+			for range repo.FindByIndex("mail", "a@example.com") {
+			}
+		}
+	}()
+
+	option.Must(data.WithIndex[Person, string](repo, "city", func(p Person) string { return p.City }, data.IndexOptions{}))
+	<-done
+}
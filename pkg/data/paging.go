@@ -12,6 +12,7 @@ import (
 	"iter"
 	"log/slog"
 	"math"
+	"slices"
 )
 
 type PaginateOptions struct {
@@ -24,6 +25,10 @@ type PaginateOptions struct {
 	MaxResults int
 	// IgnoreErrors silently ignores any errors but prints them into the log.
 	IgnoreErrors bool
+	// Sort orders the entire result set by the given keys before the page is cut, see [SortFunc]. This
+	// requires to load all entities instead of just those of the requested page. If empty, the order of the
+	// identifiers is kept.
+	Sort []SortKey
 }
 
 // Page wraps a set of loaded items.
@@ -40,7 +45,8 @@ type Page[E any] struct {
 // Paginate requires the read repository which is used to resolve the items on a page.
 // Technically, if no MaxResults is given, all identifiers are loaded into memory to calculate the actual paging.
 // Afterward, just the items for the required page are loaded from the repository. See also [Filter] to combine
-// a paging based on an ID or Aggregate Filter.
+// a paging based on an ID or Aggregate Filter. If sort keys are given, all entities are loaded and sorted
+// before the page is cut.
 func Paginate[E Aggregate[ID], ID IDType](findByID ByIDFinder[E, ID], it iter.Seq2[ID, error], opts PaginateOptions) (Page[E], error) {
 	if opts.PageSize < 1 {
 		opts.PageSize = 50
@@ -70,6 +76,21 @@ func Paginate[E Aggregate[ID], ID IDType](findByID ByIDFinder[E, ID], it iter.Se
 		}
 	}
 
+	var sorted []E
+	if len(opts.Sort) > 0 {
+		all, err := loadEntries(findByID, idents, opts)
+		if err != nil {
+			return Page[E]{}, err
+		}
+
+		slices.SortStableFunc(all, SortFunc[E](opts.Sort))
+		sorted = all
+		idents = idents[:0]
+		for _, e := range sorted {
+			idents = append(idents, e.Identity())
+		}
+	}
+
 	var page Page[E]
 	page.PageCount = int(math.Ceil(float64(len(idents)) / float64(opts.PageSize)))
 	page.PageIdx = opts.PageIdx
@@ -88,8 +109,22 @@ func Paginate[E Aggregate[ID], ID IDType](findByID ByIDFinder[E, ID], it iter.Se
 
 	offsetStart := min(opts.PageIdx*opts.PageSize, len(idents))
 	offsetEnd := min(offsetStart+opts.PageSize, len(idents))
-	idents = idents[offsetStart:offsetEnd]
+	if sorted != nil {
+		page.Items = slices.Clone(sorted[offsetStart:offsetEnd])
+		return page, nil
+	}
+
+	entries, err := loadEntries(findByID, idents[offsetStart:offsetEnd], opts)
+	if err != nil {
+		return Page[E]{}, err
+	}
+
+	page.Items = entries
 
+	return page, nil
+}
+
+func loadEntries[E Aggregate[ID], ID IDType](findByID ByIDFinder[E, ID], idents []ID, opts PaginateOptions) ([]E, error) {
 	entries := make([]E, 0, len(idents))
 	for _, ident := range idents {
 		optEnt, err := findByID(ident)
@@ -99,7 +134,7 @@ func Paginate[E Aggregate[ID], ID IDType](findByID ByIDFinder[E, ID], it iter.Se
 				continue
 			}
 
-			return Page[E]{}, fmt.Errorf("failed to paginate: cannot find entry: %w", err)
+			return nil, fmt.Errorf("failed to paginate: cannot find entry: %w", err)
 		}
 
 		if optEnt.IsNone() {
@@ -110,7 +145,5 @@ func Paginate[E Aggregate[ID], ID IDType](findByID ByIDFinder[E, ID], it iter.Se
 		entries = append(entries, optEnt.Unwrap())
 	}
 
-	page.Items = entries
-
-	return page, nil
+	return entries, nil
 }
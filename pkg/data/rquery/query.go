@@ -0,0 +1,563 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package rquery
+
+import (
+	"cmp"
+	"fmt"
+	"iter"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.wdy.de/nago/pkg/data"
+)
+
+// Query is a parsed structured query, see [Parse]. The zero value matches everything.
+type Query struct {
+	expr expr
+
+	// Sort contains the keys of all sort terms in the order of their appearance. Pass them to
+	// [data.PaginateOptions.Sort] or [data.SortFunc].
+	Sort []data.SortKey
+}
+
+// Parse parses a query of the following grammar:
+//
+//	query   = or
+//	or      = and { "OR" and }
+//	and     = unary { [ "AND" ] unary }
+//	unary   = "NOT" unary | "-" term | "(" or ")" | term
+//	term    = field op value | "sort:" [ "-" ] field | value
+//	op      = ":" | "=" | "!=" | "<" | "<=" | ">" | ">="
+//
+// Terms separated by space must all apply. A field is a dot separated path of struct fields, which are
+// matched case-insensitively or by their json tag name (see [data.FieldValues]). Values containing spaces or
+// parentheses can be quoted with double quotes. The operators mean:
+//
+//   - ":" matches case-insensitively, where * is a wildcard, e.g. receiver:*@example.com.
+//   - "=" and "!=" compare the exact string representation.
+//   - "<", "<=", ">" and ">=" compare numbers numerically, points in time by parsing the value as
+//     2006-01-02, 2006-01-02T15:04 or RFC 3339 in the local time zone, and everything else case-insensitively.
+//
+// For a point in time and a date only value, all operators compare entire days, e.g. ":" matches the day and
+// ">" everything after it. A bare value matches case-insensitively anywhere, just like [SimplePredicate], so
+// that a plain text search keeps working. Sort terms order ascending, or descending if the field is prefixed
+// with "-", and are collected into [Query.Sort] instead of filtering.
+//
+//	status:queued receiver:*@example.com createdAt>2026-01-01 sort:-createdAt
+//	(status:failed OR status:bounced) NOT subject:"Newsletter *"
+func Parse(query string) (Query, error) {
+	toks, err := lex(query)
+	if err != nil {
+		return Query{}, err
+	}
+
+	var q Query
+	var rest []token
+	for _, tok := range toks {
+		if tok.kind == tokWord && strings.HasPrefix(strings.ToLower(tok.text), "sort:") {
+			key := data.SortKey{Field: tok.text[len("sort:"):]}
+			if strings.HasPrefix(key.Field, "-") {
+				key.Field = key.Field[1:]
+				key.Desc = true
+			}
+
+			if !validField.MatchString(key.Field) {
+				return Query{}, fmt.Errorf("rquery: invalid sort field: %q", key.Field)
+			}
+
+			q.Sort = append(q.Sort, key)
+			continue
+		}
+
+		rest = append(rest, tok)
+	}
+
+	if len(rest) == 0 {
+		return q, nil
+	}
+
+	p := &parser{toks: rest}
+	q.expr, err = p.parseOr()
+	if err != nil {
+		return Query{}, err
+	}
+
+	if p.pos < len(p.toks) {
+		return Query{}, fmt.Errorf("rquery: unexpected ')'")
+	}
+
+	return q, nil
+}
+
+// MustParse is like [Parse] but panics on error.
+func MustParse(query string) Query {
+	q, err := Parse(query)
+	if err != nil {
+		panic(err)
+	}
+
+	return q
+}
+
+// Predicate returns a filter predicate which evaluates the query against T by reflection.
+func Predicate[T any](q Query) func(T) bool {
+	if q.expr == nil {
+		return func(a T) bool {
+			return true
+		}
+	}
+
+	return func(a T) bool {
+		return q.expr.match(a)
+	}
+}
+
+// QueryPredicate is a drop-in replacement for [SimplePredicate] for search boxes. It parses the query and falls
+// back to [SimplePredicate], if the query is not well-formed, e.g. while the user is still typing.
+func QueryPredicate[T any](query string) func(T) bool {
+	q, err := Parse(query)
+	if err != nil {
+		return SimplePredicate[T](query)
+	}
+
+	return Predicate[T](q)
+}
+
+// Select returns the identifiers of all entities matching the query, without applying [Query.Sort]. If repo is a
+// [data.IndexedRepository], a term field=value on the top level is looked up in the index named like the field,
+// instead of scanning all entities. Such an index must extract exactly the value of the field, as formatted by
+// [data.FormatValue]. The result can be passed to [data.Paginate] together with the sort keys.
+func Select[E data.Aggregate[ID], ID data.IDType](repo data.ReadRepository[E, ID], q Query) iter.Seq2[ID, error] {
+	candidates := repo.All()
+	if indexed, ok := repo.(*data.IndexedRepository[E, ID]); ok {
+		for _, t := range conjunction(q.expr) {
+			if t.op == "=" && indexed.HasIndex(t.field) {
+				candidates = indexed.FindByIndex(t.field, t.value)
+				break
+			}
+		}
+	}
+
+	pred := Predicate[E](q)
+	return func(yield func(ID, error) bool) {
+		var zero ID
+		for e, err := range candidates {
+			if err != nil {
+				if !yield(zero, err) {
+					return
+				}
+
+				continue
+			}
+
+			if !pred(e) {
+				continue
+			}
+
+			if !yield(e.Identity(), nil) {
+				return
+			}
+		}
+	}
+}
+
+// conjunction returns the field terms which must all apply on the top level.
+func conjunction(e expr) []termExpr {
+	switch e := e.(type) {
+	case termExpr:
+		return []termExpr{e}
+	case andExpr:
+		var res []termExpr
+		for _, sub := range e {
+			if t, ok := sub.(termExpr); ok {
+				res = append(res, t)
+			}
+		}
+		return res
+	default:
+		return nil
+	}
+}
+
+type expr interface {
+	match(v any) bool
+}
+
+type andExpr []expr
+
+func (e andExpr) match(v any) bool {
+	for _, sub := range e {
+		if !sub.match(v) {
+			return false
+		}
+	}
+
+	return true
+}
+
+type orExpr []expr
+
+func (e orExpr) match(v any) bool {
+	for _, sub := range e {
+		if sub.match(v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type notExpr struct {
+	expr expr
+}
+
+func (e notExpr) match(v any) bool {
+	return !e.expr.match(v)
+}
+
+// textExpr is a bare value, which behaves like a term of SimplePredicate.
+type textExpr struct {
+	text string // lower case
+}
+
+func (e textExpr) match(v any) bool {
+	if len(e.text) < 2 {
+		return true
+	}
+
+	return contains(v, e.text)
+}
+
+type termExpr struct {
+	field string
+	op    string
+	value string
+}
+
+func (e termExpr) match(v any) bool {
+	values := data.FieldValues(v, e.field)
+	if e.op == "!=" {
+		for _, val := range values {
+			if e.equal(val) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	for _, val := range values {
+		if e.matchValue(val) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e termExpr) matchValue(val reflect.Value) bool {
+	switch e.op {
+	case ":":
+		if _, ok := data.TimeValue(val); ok {
+			return e.equal(val)
+		}
+
+		return glob(strings.ToLower(e.value), strings.ToLower(data.FormatValue(val)))
+	case "=":
+		return e.equal(val)
+	}
+
+	c, ok := compareLiteral(val, e.value)
+	if !ok {
+		return false
+	}
+
+	switch e.op {
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	default:
+		return false
+	}
+}
+
+func (e termExpr) equal(val reflect.Value) bool {
+	if t, ok := data.TimeValue(val); ok {
+		lit, layout, ok := parseTime(e.value)
+		if !ok {
+			return false
+		}
+
+		if layout == time.DateOnly {
+			y1, m1, d1 := t.In(time.Local).Date()
+			y2, m2, d2 := lit.Date()
+			return y1 == y2 && m1 == m2 && d1 == d2
+		}
+
+		return t.Equal(lit)
+	}
+
+	return data.FormatValue(val) == e.value
+}
+
+// compareLiteral compares val with the literal and returns false, if the literal cannot be
+// interpreted like val.
+func compareLiteral(val reflect.Value, lit string) (int, bool) {
+	if t, ok := data.TimeValue(val); ok {
+		tLit, layout, ok := parseTime(lit)
+		if !ok {
+			return 0, false
+		}
+
+		if layout == time.DateOnly {
+			// compare whole days, so that >2026-01-01 means after that day
+			y, m, d := t.In(time.Local).Date()
+			t = time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+		}
+
+		return t.Compare(tLit), true
+	}
+
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(lit, 64)
+		if err != nil {
+			return 0, false
+		}
+
+		v, _ := strconv.ParseFloat(data.FormatValue(val), 64)
+		return cmp.Compare(v, f), true
+	case reflect.Bool:
+		b, err := strconv.ParseBool(lit)
+		if err != nil {
+			return 0, false
+		}
+
+		return cmp.Compare(strconv.FormatBool(val.Bool()), strconv.FormatBool(b)), true
+	default:
+		return strings.Compare(strings.ToLower(data.FormatValue(val)), strings.ToLower(lit)), true
+	}
+}
+
+var timeLayouts = []string{time.DateOnly, "2006-01-02T15:04", time.RFC3339}
+
+func parseTime(lit string) (time.Time, string, bool) {
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, lit, time.Local); err == nil {
+			return t, layout, true
+		}
+	}
+
+	return time.Time{}, "", false
+}
+
+// glob reports whether s matches pattern, where * matches any sequence of characters.
+func glob(pattern, s string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == s
+	}
+
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		idx := strings.Index(s, part)
+		if idx < 0 {
+			return false
+		}
+		s = s[idx+len(part):]
+	}
+
+	return len(s) >= len(last) && strings.HasSuffix(s, last)
+}
+
+type tokenKind int
+
+const (
+	tokWord tokenKind = iota
+	tokOpen
+	tokClose
+)
+
+type token struct {
+	kind tokenKind
+	text string // raw text including quotes
+}
+
+func (t token) isKeyword(kw string) bool {
+	return t.kind == tokWord && t.text == kw
+}
+
+func lex(query string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(query); {
+		switch c := query[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			toks = append(toks, token{kind: tokOpen})
+			i++
+		case c == ')':
+			toks = append(toks, token{kind: tokClose})
+			i++
+		default:
+			start := i
+			inQuote := false
+			for ; i < len(query); i++ {
+				c := query[i]
+				if c == '"' {
+					inQuote = !inQuote
+					continue
+				}
+
+				if !inQuote && (c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '(' || c == ')') {
+					break
+				}
+			}
+
+			if inQuote {
+				return nil, fmt.Errorf("rquery: unterminated quote at %d", start)
+			}
+
+			toks = append(toks, token{kind: tokWord, text: query[start:i]})
+		}
+	}
+
+	return toks, nil
+}
+
+type parser struct {
+	toks []token
+	pos  int
+}
+
+func (p *parser) parseOr() (expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	res := orExpr{left}
+	for p.pos < len(p.toks) && p.toks[p.pos].isKeyword("OR") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, right)
+	}
+
+	if len(res) == 1 {
+		return left, nil
+	}
+
+	return res, nil
+}
+
+func (p *parser) parseAnd() (expr, error) {
+	var res andExpr
+	for p.pos < len(p.toks) {
+		tok := p.toks[p.pos]
+		if tok.kind == tokClose || tok.isKeyword("OR") {
+			break
+		}
+
+		if tok.isKeyword("AND") {
+			p.pos++
+			continue
+		}
+
+		e, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, e)
+	}
+
+	switch len(res) {
+	case 0:
+		return nil, fmt.Errorf("rquery: expected term")
+	case 1:
+		return res[0], nil
+	default:
+		return res, nil
+	}
+}
+
+func (p *parser) parseUnary() (expr, error) {
+	tok := p.toks[p.pos]
+	p.pos++
+
+	switch {
+	case tok.kind == tokClose:
+		return nil, fmt.Errorf("rquery: unexpected ')'")
+	case tok.kind == tokOpen:
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.pos >= len(p.toks) || p.toks[p.pos].kind != tokClose {
+			return nil, fmt.Errorf("rquery: missing ')'")
+		}
+
+		p.pos++
+		return e, nil
+	case tok.isKeyword("NOT"):
+		if p.pos >= len(p.toks) {
+			return nil, fmt.Errorf("rquery: expected term after NOT")
+		}
+
+		e, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return notExpr{e}, nil
+	case len(tok.text) > 1 && tok.text[0] == '-':
+		return notExpr{parseTerm(tok.text[1:])}, nil
+	default:
+		return parseTerm(tok.text), nil
+	}
+}
+
+var validField = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)*$`)
+
+var operators = []string{"!=", "<=", ">=", ":", "=", "<", ">"}
+
+func parseTerm(raw string) expr {
+	if idx := strings.IndexAny(raw, `:=!<>"`); idx > 0 && raw[idx] != '"' && validField.MatchString(raw[:idx]) {
+		for _, op := range operators {
+			if strings.HasPrefix(raw[idx:], op) {
+				return termExpr{field: raw[:idx], op: op, value: unquote(raw[idx+len(op):])}
+			}
+		}
+	}
+
+	return textExpr{text: strings.ToLower(unquote(raw))}
+}
+
+func unquote(s string) string {
+	return strings.ReplaceAll(s, `"`, "")
+}
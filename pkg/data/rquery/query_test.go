@@ -0,0 +1,159 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package rquery
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"go.wdy.de/nago/pkg/blob/mem"
+	"go.wdy.de/nago/pkg/data"
+	"go.wdy.de/nago/pkg/data/json"
+	"go.wdy.de/nago/pkg/xiter"
+	"go.wdy.de/nago/pkg/xtime"
+)
+
+type Mail struct {
+	ID        string
+	Status    string
+	Receiver  []string `json:"receiver"`
+	Subject   string
+	Attempts  int
+	CreatedAt xtime.UnixMilliseconds
+}
+
+func (m Mail) Identity() string {
+	return m.ID
+}
+
+func testMails() []Mail {
+	day := func(d int) xtime.UnixMilliseconds {
+		return xtime.UnixMilliseconds(time.Date(2026, 1, d, 12, 0, 0, 0, time.Local).UnixMilli())
+	}
+
+	return []Mail{
+		{ID: "1", Status: "queued", Receiver: []string{"a@example.com"}, Subject: "Welcome", Attempts: 0, CreatedAt: day(1)},
+		{ID: "2", Status: "failed", Receiver: []string{"b@worldiety.de"}, Subject: "Newsletter January", Attempts: 3, CreatedAt: day(2)},
+		{ID: "3", Status: "bounced", Receiver: []string{"c@example.com", "d@worldiety.de"}, Subject: "Password reset", Attempts: 1, CreatedAt: day(3)},
+		{ID: "4", Status: "Queued", Receiver: []string{"e@example.org"}, Subject: "Newsletter February", Attempts: 10, CreatedAt: day(4)},
+	}
+}
+
+func filter(t *testing.T, query string) []string {
+	t.Helper()
+	q, err := Parse(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+	for m := range xiter.Filter(Predicate[Mail](q), slices.Values(testMails())) {
+		ids = append(ids, m.ID)
+	}
+
+	return ids
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"1", "2", "3", "4"}},
+		{"status:queued", []string{"1", "4"}},
+		{"status=queued", []string{"1"}},
+		{"status!=queued", []string{"2", "3", "4"}},
+		{"receiver:*@example.com", []string{"1", "3"}},
+		{"receiver:*@example.com status:queued", []string{"1"}},
+		{"createdAt>2026-01-02", []string{"3", "4"}},
+		{"createdAt>=2026-01-02 createdAt<2026-01-04", []string{"2", "3"}},
+		{"createdAt:2026-01-03", []string{"3"}},
+		{"attempts>2", []string{"2", "4"}},
+		{"(status:failed OR status:bounced) AND NOT attempts>2", []string{"3"}},
+		{`-subject:"Newsletter *"`, []string{"1", "3"}},
+		{"newsletter febr", []string{"4"}},
+		{"unknown:x", nil},
+	}
+
+	for _, tt := range tests {
+		if got := filter(t, tt.query); !slices.Equal(got, tt.want) {
+			t.Errorf("%q: expected %v, got %v", tt.query, tt.want, got)
+		}
+	}
+
+	for _, query := range []string{"(status:queued", "status:queued)", `subject:"open`, "NOT", "sort:-1x"} {
+		if _, err := Parse(query); err == nil {
+			t.Errorf("%q: expected error", query)
+		}
+	}
+}
+
+func TestSortAndPaginate(t *testing.T) {
+	q, err := Parse("status!=failed sort:-attempts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(q.Sort, []data.SortKey{{Field: "attempts", Desc: true}}) {
+		t.Fatalf("unexpected sort keys: %v", q.Sort)
+	}
+
+	repo := json.NewSloppyJSONRepository[Mail, string](mem.NewBlobStore("mails"))
+	for _, m := range testMails() {
+		if err := repo.Save(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	page, err := data.Paginate(repo.FindByID, Select[Mail, string](repo, q), data.PaginateOptions{PageSize: 2, Sort: q.Sort})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+	for _, m := range page.Items {
+		ids = append(ids, m.ID)
+	}
+
+	if page.Total != 3 || !slices.Equal(ids, []string{"4", "3"}) {
+		t.Fatalf("unexpected page: total=%d ids=%v", page.Total, ids)
+	}
+}
+
+func TestSelectUsesIndex(t *testing.T) {
+	repo := json.NewSloppyJSONRepository[Mail, string](mem.NewBlobStore("mails"))
+	for _, m := range testMails() {
+		if err := repo.Save(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lookups := 0
+	indexed, err := data.WithIndex[Mail, string](repo, "status", func(m Mail) string {
+		lookups++
+		return m.Status
+	}, data.IndexOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lookups = 0
+	var ids []string
+	for id, err := range Select[Mail, string](indexed, MustParse("status=queued")) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+
+	// only the single index hit is verified, instead of extracting the status of all mails
+	if !slices.Equal(ids, []string{"1"}) || lookups != 1 {
+		t.Fatalf("unexpected result %v with %d extractions", ids, lookups)
+	}
+}
@@ -0,0 +1,242 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package data
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// SortKey orders entities by a field, see [SortFunc] and [PaginateOptions.Sort].
+type SortKey struct {
+	// Field is a dot separated path of struct fields, e.g. "createdAt" or "address.city". Each segment
+	// matches a field name case-insensitively or its json tag name.
+	Field string
+	// Desc inverts the order.
+	Desc bool
+}
+
+// SortFunc returns a comparator which orders by the given keys by reflection. The first key has the highest
+// precedence. Numbers, strings (case-insensitive), booleans and points in time (see [TimeValue]) are compared by
+// their natural order, other types by their [fmt.Stringer] representation, if any. Missing values and nil pointers
+// sort first.
+// If a field resolves to multiple values, e.g. within a slice, only the first one is compared.
+func SortFunc[E any](keys []SortKey) func(a, b E) int {
+	return func(a, b E) int {
+		for _, key := range keys {
+			c := compareFields(FieldValues(a, key.Field), FieldValues(b, key.Field))
+			if key.Desc {
+				c = -c
+			}
+
+			if c != 0 {
+				return c
+			}
+		}
+
+		return 0
+	}
+}
+
+// FieldValues resolves the dot separated field path within v, just like [SortKey.Field]. Pointers and interfaces
+// are dereferenced and slices are flattened, so the result contains all non-nil leaf values. An unknown path
+// results in an empty slice.
+func FieldValues(v any, path string) []reflect.Value {
+	res := []reflect.Value{reflect.ValueOf(v)}
+	for _, name := range strings.Split(path, ".") {
+		var next []reflect.Value
+		for _, val := range res {
+			for _, elem := range flatten(val) {
+				if elem.Kind() != reflect.Struct {
+					continue
+				}
+
+				if field, ok := fieldByName(elem, name); ok {
+					next = append(next, field)
+				}
+			}
+		}
+
+		res = next
+	}
+
+	var leafs []reflect.Value
+	for _, val := range res {
+		leafs = append(leafs, flatten(val)...)
+	}
+
+	return leafs
+}
+
+// flatten dereferences pointers and interfaces and expands slices, except byte slices.
+func flatten(v reflect.Value) []reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	if !v.IsValid() {
+		return nil
+	}
+
+	if (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Type().Elem().Kind() != reflect.Uint8 {
+		var res []reflect.Value
+		for i := range v.Len() {
+			res = append(res, flatten(v.Index(i))...)
+		}
+
+		return res
+	}
+
+	return []reflect.Value{v}
+}
+
+func fieldByName(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tagName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if strings.EqualFold(field.Name, name) || (tagName != "" && tagName == name) {
+			return v.Field(i), true
+		}
+	}
+
+	return reflect.Value{}, false
+}
+
+func compareFields(a, b []reflect.Value) int {
+	switch {
+	case len(a) == 0 && len(b) == 0:
+		return 0
+	case len(a) == 0:
+		return -1
+	case len(b) == 0:
+		return 1
+	}
+
+	return CompareValues(a[0], b[0])
+}
+
+// CompareValues compares two resolved field values by their natural order, as documented by [SortFunc].
+// Values of different kinds are compared by their string representation.
+func CompareValues(a, b reflect.Value) int {
+	if ta, ok := TimeValue(a); ok {
+		if tb, ok := TimeValue(b); ok {
+			return ta.Compare(tb)
+		}
+	}
+
+	switch {
+	case isInt(a) && isInt(b):
+		return cmp.Compare(a.Int(), b.Int())
+	case isUint(a) && isUint(b):
+		return cmp.Compare(a.Uint(), b.Uint())
+	case isNumber(a) && isNumber(b):
+		return cmp.Compare(toFloat(a), toFloat(b))
+	case a.Kind() == reflect.Bool && b.Kind() == reflect.Bool:
+		return cmp.Compare(boolInt(a.Bool()), boolInt(b.Bool()))
+	}
+
+	sa, sb := FormatValue(a), FormatValue(b)
+	if c := strings.Compare(strings.ToLower(sa), strings.ToLower(sb)); c != 0 {
+		return c
+	}
+
+	return strings.Compare(sa, sb)
+}
+
+// FormatValue returns the string representation of a resolved field value, which is used to compare and
+// match values of otherwise incomparable types.
+func FormatValue(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return fmt.Sprint(v.Interface())
+	}
+
+	if t, ok := v.Interface().(time.Time); ok {
+		return t.Format(time.RFC3339)
+	}
+
+	if s, ok := v.Interface().(fmt.Stringer); ok {
+		return s.String()
+	}
+
+	buf, _ := json.Marshal(v.Interface())
+	return string(buf)
+}
+
+// TimeValue returns the point in time of a resolved field value, which is either a [time.Time] or a type with a
+// Time(*time.Location) method, like xtime.UnixMilliseconds or xtime.Date, evaluated in the local time zone.
+func TimeValue(v reflect.Value) (time.Time, bool) {
+	switch t := v.Interface().(type) {
+	case time.Time:
+		return t, true
+	case timer:
+		return t.Time(time.Local), true
+	default:
+		return time.Time{}, false
+	}
+}
+
+type timer interface {
+	Time(loc *time.Location) time.Time
+}
+
+func isInt(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	default:
+		return false
+	}
+}
+
+func isUint(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	default:
+		return false
+	}
+}
+
+func isNumber(v reflect.Value) bool {
+	return isInt(v) || isUint(v) || v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64
+}
+
+func toFloat(v reflect.Value) float64 {
+	switch {
+	case isInt(v):
+		return float64(v.Int())
+	case isUint(v):
+		return float64(v.Uint())
+	default:
+		return v.Float()
+	}
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+
+	return 0
+}
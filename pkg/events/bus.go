@@ -58,6 +58,17 @@ func TypeFor[T any]() SubscriberOption {
 	})
 }
 
+// SubscriberType returns the event type the given options narrow a subscription to, or nil if the subscriber
+// wants all events. It allows alternative [EventBus] implementations to honour [TypeFor].
+func SubscriberType(opts ...SubscriberOption) reflect.Type {
+	var cfg busSubscriberOptions
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+
+	return cfg.typeDef
+}
+
 // NewEventBus creates a new default async event bus. Each invocation to a subscriber spawns a new go routine, which is
 // expensive but free of stalls and deadlocks. See package documentation [events] to better understand when
 // to use this pattern.
@@ -0,0 +1,412 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+// Package durable provides an [events.Bus] which persists selected event types
+// into an ndb message engine before they are delivered, so that an event which
+// has been published is not lost if the process crashes before its subscribers
+// have handled it.
+//
+// # Delivery semantics
+//
+// Events of registered types are appended to the log by [Bus.Publish] and
+// delivered by a single [checkpoint.Consumer] started with [Bus.Run]. The
+// cursor is committed after all subscribers have returned, so delivery is
+// at-least-once and in publish order: after a crash, the events after the last
+// committed cursor are delivered again. Subscribers of durable types must
+// therefore be idempotent, and they are invoked sequentially, so a slow
+// subscriber delays all others.
+//
+// A panicking subscriber does not commit the cursor. Instead, the event is
+// delivered again after a growing delay, see [Options.RetryDelay], but only to
+// the subscribers which have not handled it yet. Until then, no later event is
+// delivered to anyone. An event which still fails after [Options.MaxAttempts]
+// deliveries is logged and skipped, so that a single poison event cannot stall
+// the bus forever. A restart forgets which subscribers have already handled
+// the failed event, thus all of them receive it again.
+//
+// Events of all other types are passed to a fallback bus, which is by default
+// the fire-and-forget [events.NewEventBus]. Thus, the durable bus can replace
+// the in-memory bus without changing the behaviour of existing event types,
+// and [events.SubscribeFor] keeps working for both kinds.
+//
+// The log grows with every published event. Configure a retention policy for
+// the registered types on the message engine to bound it, and bind its floor
+// to [Bus.Committed], so that events which have not been delivered yet, e.g.
+// after a long outage, are never dropped.
+package durable
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"runtime/debug"
+	"slices"
+	"sync"
+	"time"
+
+	"go.wdy.de/nago/pkg/blob"
+	"go.wdy.de/nago/pkg/events"
+	"go.wdy.de/nago/pkg/ndb"
+	"go.wdy.de/nago/pkg/ndb/checkpoint"
+)
+
+const (
+	// DefaultCursorKey is the zero-value [Options.CursorKey].
+	DefaultCursorKey = "events.cursor"
+	// DefaultRetryDelay is the zero-value [Options.RetryDelay].
+	DefaultRetryDelay = time.Second
+	// DefaultMaxAttempts is the zero-value [Options.MaxAttempts].
+	DefaultMaxAttempts = 10
+
+	maxRetryDelay = time.Minute
+)
+
+// errRedeliver marks a handler error which is retried by [Bus.Run].
+var errRedeliver = errors.New("durable: subscriber failed")
+
+// Options configures a [Bus]. The zero value is valid.
+type Options struct {
+	// CursorKey is the blob key of the delivery cursor. Empty selects
+	// [DefaultCursorKey].
+	CursorKey string
+
+	// Checkpoint configures the batching of cursor writes. A larger batch
+	// means more redelivered events after a crash.
+	Checkpoint checkpoint.Options
+
+	// Fallback receives all events of unregistered types, and their
+	// subscriptions. nil selects [events.NewEventBus].
+	Fallback events.Bus
+
+	// RetryDelay is the pause before an event is delivered again, after one
+	// of its subscribers has failed. It doubles with each further failure of
+	// the same event, up to one minute. 0 selects [DefaultRetryDelay].
+	RetryDelay time.Duration
+
+	// MaxAttempts is the number of failed deliveries of a single event, after
+	// which it is skipped. 0 selects [DefaultMaxAttempts].
+	MaxAttempts int
+}
+
+type hnd int
+
+// Bus is a durable [events.Bus]. See the package documentation for details.
+type Bus struct {
+	msgs    ndb.Messages
+	cursors blob.Store
+	opts    Options
+
+	mutex   sync.RWMutex
+	byType  map[reflect.Type]ndb.TypeID
+	byID    map[ndb.TypeID]reflect.Type
+	typed   map[reflect.Type]map[hnd]func(evt any)
+	any     map[hnd]func(evt any)
+	lastHnd hnd
+	running bool
+
+	// failedSeq, failures and delivered track the event which is currently
+	// retried and the subscribers which have already handled it. They are
+	// only accessed by the single delivery goroutine of Run.
+	failedSeq ndb.Seq
+	failures  int
+	delivered map[hnd]struct{}
+}
+
+var _ events.Bus = (*Bus)(nil)
+
+// NewBus creates a durable bus which appends the events of registered types to
+// msgs and keeps its delivery cursor in cursors. Register the durable types
+// and subscribe, then start the delivery with [Bus.Run].
+func NewBus(msgs ndb.Messages, cursors blob.Store, opts Options) *Bus {
+	if opts.CursorKey == "" {
+		opts.CursorKey = DefaultCursorKey
+	}
+
+	if opts.Fallback == nil {
+		opts.Fallback = events.NewEventBus()
+	}
+
+	if opts.RetryDelay <= 0 {
+		opts.RetryDelay = DefaultRetryDelay
+	}
+
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultMaxAttempts
+	}
+
+	return &Bus{
+		msgs:    msgs,
+		cursors: cursors,
+		opts:    opts,
+		byType:  make(map[reflect.Type]ndb.TypeID),
+		byID:    make(map[ndb.TypeID]reflect.Type),
+		typed:   make(map[reflect.Type]map[hnd]func(evt any)),
+		any:     make(map[hnd]func(evt any)),
+
+		delivered: make(map[hnd]struct{}),
+	}
+}
+
+// Register makes events of type t durable. They are stored as JSON under the
+// given message type, which must stay stable across releases, just like an evs
+// discriminator. Types must be registered before [Bus.Run] is called.
+func (b *Bus) Register(t reflect.Type, typeID ndb.TypeID) error {
+	if !ndb.ValidTypeID(string(typeID)) {
+		return fmt.Errorf("durable: invalid type id %q", typeID)
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.running {
+		return fmt.Errorf("durable: cannot register %v after the bus has been started", t)
+	}
+
+	if existing, ok := b.byType[t]; ok {
+		if existing != typeID {
+			return fmt.Errorf("durable: type %v already registered as %q, not %q", t, existing, typeID)
+		}
+		return nil
+	}
+
+	if existing, ok := b.byID[typeID]; ok {
+		return fmt.Errorf("durable: type id %q already registered for %v", typeID, existing)
+	}
+
+	b.byType[t] = typeID
+	b.byID[typeID] = t
+	return nil
+}
+
+// Register is a generic shortcut for [Bus.Register].
+func Register[T any](bus *Bus, typeID ndb.TypeID) error {
+	return bus.Register(reflect.TypeFor[T](), typeID)
+}
+
+// Publish appends events of registered types to the log, from where they are
+// delivered by [Bus.Run]. All other events are published on the fallback bus.
+// If the append fails, the event is delivered in-memory as a last resort, thus
+// without any durability.
+func (b *Bus) Publish(evt any) {
+	if evt == nil {
+		return
+	}
+
+	b.mutex.RLock()
+	typeID, ok := b.byType[reflect.TypeOf(evt)]
+	b.mutex.RUnlock()
+
+	if !ok {
+		b.opts.Fallback.Publish(evt)
+		return
+	}
+
+	buf, err := json.Marshal(evt)
+	if err == nil {
+		_, err = b.msgs.Append(typeID, ndb.NewTraceID(), buf)
+	}
+
+	if err != nil {
+		slog.Error("durable: cannot persist event, delivering without durability", "type", typeID, "err", err)
+		go b.deliver(evt, nil)
+	}
+}
+
+// Subscribe registers fn for the events selected by opts (see
+// [events.TypeFor]). A subscription to a durable type is served by [Bus.Run],
+// all others by the fallback bus. A subscription without a type receives
+// both.
+func (b *Bus) Subscribe(fn func(evt any), opts ...events.SubscriberOption) (close func()) {
+	t := events.SubscriberType(opts...)
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if t != nil {
+		if _, ok := b.byType[t]; !ok {
+			return b.opts.Fallback.Subscribe(fn, opts...)
+		}
+	}
+
+	b.lastHnd++
+	myHnd := b.lastHnd
+
+	if t == nil {
+		b.any[myHnd] = fn
+		closeFallback := b.opts.Fallback.Subscribe(fn)
+		return func() {
+			closeFallback()
+
+			b.mutex.Lock()
+			defer b.mutex.Unlock()
+			delete(b.any, myHnd)
+		}
+	}
+
+	subMap, ok := b.typed[t]
+	if !ok {
+		subMap = make(map[hnd]func(evt any))
+		b.typed[t] = subMap
+	}
+
+	subMap[myHnd] = fn
+	return func() {
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+		delete(subMap, myHnd)
+	}
+}
+
+// Run delivers the logged events of all registered types to the subscribers,
+// starting after the last committed cursor, and then follows new events. It
+// blocks until ctx is cancelled and must be called at most once. Events which
+// have been published before Run are delivered as soon as Run starts. If a
+// subscriber fails, Run pauses and resumes with the failed event.
+func (b *Bus) Run(ctx context.Context) error {
+	b.mutex.Lock()
+	if b.running {
+		b.mutex.Unlock()
+		return fmt.Errorf("durable: bus is already running")
+	}
+	b.running = true
+
+	types := make([]ndb.TypeID, 0, len(b.byID))
+	for typeID := range b.byID {
+		types = append(types, typeID)
+	}
+	b.mutex.Unlock()
+
+	if len(types) == 0 {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	slices.Sort(types)
+	for {
+		// the consumer stops before it commits a failed event, thus a new one
+		// resumes exactly there
+		consumer := checkpoint.NewConsumer(b.msgs, types, b.cursors, b.opts.CursorKey, b.opts.Checkpoint, b.handle)
+		err := consumer.Run(ctx)
+		if !errors.Is(err, errRedeliver) || ctx.Err() != nil {
+			return err
+		}
+
+		delay := b.retryDelay()
+		slog.Warn("durable: redelivering event after subscriber failure", "seq", b.failedSeq, "attempt", b.failures, "delay", delay, "err", err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// retryDelay doubles the configured delay for each failure of the current event.
+func (b *Bus) retryDelay() time.Duration {
+	delay := b.opts.RetryDelay
+	for i := 1; i < b.failures && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, maxRetryDelay)
+}
+
+// Committed returns the sequence number of the last event whose delivery has
+// been durably committed. All events up to and including it may be dropped by
+// a retention policy without breaking the at-least-once delivery.
+func (b *Bus) Committed() (ndb.Seq, error) {
+	return checkpoint.NewBlobStore(b.cursors, b.opts.CursorKey).Load()
+}
+
+func (b *Bus) handle(typeID ndb.TypeID, msg ndb.Message) error {
+	b.mutex.RLock()
+	t := b.byID[typeID]
+	b.mutex.RUnlock()
+
+	payload, err := ndb.Decompress(msg.Encoding, msg.Payload, msg.UncompressedLen)
+	if err != nil {
+		// retrying cannot fix a broken payload, so do not stall the bus
+		slog.Error("durable: skipping undecodable event", "type", typeID, "seq", msg.Seq, "err", err)
+		return nil
+	}
+
+	rval := reflect.New(t)
+	if err := json.Unmarshal(payload, rval.Interface()); err != nil {
+		slog.Error("durable: skipping undecodable event", "type", typeID, "seq", msg.Seq, "err", err)
+		return nil
+	}
+
+	// only a redelivery of the failed event skips the subscribers which have
+	// already handled it
+	if b.failedSeq != msg.Seq {
+		b.failures = 0
+		clear(b.delivered)
+	}
+
+	if err := b.deliver(rval.Elem().Interface(), b.delivered); err != nil {
+		b.failedSeq = msg.Seq
+		b.failures++
+		if b.failures >= b.opts.MaxAttempts {
+			slog.Error("durable: skipping event after repeated subscriber failures", "type", typeID, "seq", msg.Seq, "attempts", b.failures, "err", err)
+			return nil
+		}
+
+		return fmt.Errorf("%w: seq %d: %w", errRedeliver, msg.Seq, err)
+	}
+
+	return nil
+}
+
+// deliver invokes all subscribers of evt sequentially, except those within
+// delivered, and adds each subscriber which has returned to delivered, if it
+// is not nil. A panicking subscriber is logged and does not prevent the
+// delivery to the others, but its panic is returned as an error.
+func (b *Bus) deliver(evt any, delivered map[hnd]struct{}) error {
+	b.mutex.RLock()
+	fns := make(map[hnd]func(evt any), len(b.any)+len(b.typed[reflect.TypeOf(evt)]))
+	for h, fn := range b.any {
+		fns[h] = fn
+	}
+	for h, fn := range b.typed[reflect.TypeOf(evt)] {
+		fns[h] = fn
+	}
+	b.mutex.RUnlock()
+
+	var errs []error
+	for h, fn := range fns {
+		if _, ok := delivered[h]; ok {
+			continue
+		}
+
+		if err := invoke(evt, fn); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if delivered != nil {
+			delivered[h] = struct{}{}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func invoke(evt any, fn func(evt any)) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("durable: panic in event subscriber", "type", fmt.Sprintf("%T", evt), "panic", r, "stack", string(debug.Stack()))
+			err = fmt.Errorf("panic in subscriber of %T: %v", evt, r)
+		}
+	}()
+
+	fn(evt)
+	return nil
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package durable_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/worldiety/option"
+	"go.wdy.de/nago/pkg/blob/mem"
+	"go.wdy.de/nago/pkg/events"
+	"go.wdy.de/nago/pkg/events/durable"
+	"go.wdy.de/nago/pkg/ndb"
+	"go.wdy.de/nago/pkg/ndb/checkpoint"
+	"go.wdy.de/nago/pkg/ndb/msgstore"
+)
+
+type MailRequested struct {
+	To string `json:"to"`
+}
+
+type ThemeChanged struct{}
+
+func openMessages(t *testing.T) ndb.Messages {
+	t.Helper()
+	db := option.Must(ndb.Open(t.TempDir(), ndb.Options{}))
+	t.Cleanup(func() { option.MustZero(db.Close()) })
+
+	eng := option.Must(db.Engine("events", ndb.EngineOptions{Kind: msgstore.EngineKind, Config: msgstore.Options{}}))
+	return eng.(ndb.MessageEngine).Messages()
+}
+
+func newBus(t *testing.T, msgs ndb.Messages, cursors *mem.BlobStore, got chan<- string) (*durable.Bus, context.CancelFunc, <-chan error) {
+	t.Helper()
+	bus := durable.NewBus(msgs, cursors, durable.Options{Checkpoint: checkpointEveryEvent})
+	option.MustZero(durable.Register[MailRequested](bus, "MailRequested"))
+	events.SubscribeFor[MailRequested](bus, func(evt MailRequested) {
+		got <- evt.To
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- bus.Run(ctx) }()
+	return bus, cancel, done
+}
+
+func receive(t *testing.T, got <-chan string) string {
+	t.Helper()
+	select {
+	case v := <-got:
+		return v
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for event")
+		return ""
+	}
+}
+
+func TestBusDeliversAfterRestart(t *testing.T) {
+	msgs := openMessages(t)
+	cursors := mem.NewBlobStore("cursors")
+	got := make(chan string, 10)
+
+	bus, cancel, done := newBus(t, msgs, cursors, got)
+	bus.Publish(MailRequested{To: "a@example.com"})
+	if v := receive(t, got); v != "a@example.com" {
+		t.Fatalf("unexpected event %q", v)
+	}
+
+	// not registered: fire-and-forget through the fallback bus
+	fallback := make(chan struct{}, 1)
+	events.SubscribeFor[ThemeChanged](bus, func(ThemeChanged) { fallback <- struct{}{} })
+	bus.Publish(ThemeChanged{})
+	select {
+	case <-fallback:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected fallback delivery")
+	}
+
+	if err := durable.Register[ThemeChanged](bus, "ThemeChanged"); err == nil {
+		t.Fatal("expected registration of a running bus to fail")
+	}
+
+	cancel()
+	<-done
+
+	// the cursor releases the delivered event for retention
+	if seq, err := bus.Committed(); err != nil || seq == 0 {
+		t.Fatalf("expected a committed cursor, got %d: %v", seq, err)
+	}
+
+	// published while no delivery is running, e.g. shortly before a crash
+	bus.Publish(MailRequested{To: "b@example.com"})
+
+	_, cancel, done = newBus(t, msgs, cursors, got)
+	defer func() { cancel(); <-done }()
+
+	// the first event has been committed and is not delivered again
+	if v := receive(t, got); v != "b@example.com" {
+		t.Fatalf("expected only the pending event after restart, got %q", v)
+	}
+
+	select {
+	case v := <-got:
+		t.Fatalf("unexpected redelivery of %q", v)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestBusRedeliversAfterPanic(t *testing.T) {
+	msgs := openMessages(t)
+	bus := durable.NewBus(msgs, mem.NewBlobStore("cursors"), durable.Options{Checkpoint: checkpointEveryEvent, RetryDelay: time.Millisecond})
+	option.MustZero(durable.Register[MailRequested](bus, "MailRequested"))
+
+	got := make(chan string, 10)
+	var calls int
+	events.SubscribeFor[MailRequested](bus, func(evt MailRequested) {
+		calls++
+		if calls == 1 {
+			panic("temporary failure")
+		}
+		got <- evt.To
+	})
+
+	// a subscriber which has already handled the failed event must not receive it again
+	other := make(chan string, 10)
+	events.SubscribeFor[MailRequested](bus, func(evt MailRequested) {
+		other <- evt.To
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- bus.Run(ctx) }()
+	defer func() { cancel(); <-done }()
+
+	bus.Publish(MailRequested{To: "a@example.com"})
+	bus.Publish(MailRequested{To: "b@example.com"})
+
+	// the failed event is neither lost nor overtaken by the next one
+	if v := receive(t, got); v != "a@example.com" {
+		t.Fatalf("expected redelivery of the failed event, got %q", v)
+	}
+
+	if v := receive(t, got); v != "b@example.com" {
+		t.Fatalf("unexpected event %q", v)
+	}
+
+	for _, want := range []string{"a@example.com", "b@example.com"} {
+		if v := receive(t, other); v != want {
+			t.Fatalf("expected %q exactly once, got %q", want, v)
+		}
+	}
+}
+
+var checkpointEveryEvent = checkpoint.Options{SaveEvery: 1}
@@ -0,0 +1,70 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+// Package eventstest provides an [events.Bus] for tests, which do not want to deal with the goroutine spawning
+// of the default async bus.
+package eventstest
+
+import (
+	"slices"
+	"sync"
+
+	"go.wdy.de/nago/pkg/events"
+)
+
+// Recorder is a synchronous [events.Bus], which records all published events. Subscribers are never invoked.
+// The zero value is ready to use.
+type Recorder struct {
+	mutex  sync.Mutex
+	events []any
+}
+
+var _ events.Bus = (*Recorder)(nil)
+
+func (r *Recorder) Publish(evt any) {
+	if evt == nil {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.events = append(r.events, evt)
+}
+
+// Subscribe ignores fn, because the recorder never delivers events.
+func (r *Recorder) Subscribe(fn func(evt any), opts ...events.SubscriberOption) (close func()) {
+	return func() {}
+}
+
+// Events returns a copy of all events recorded so far, in publish order.
+func (r *Recorder) Events() []any {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return slices.Clone(r.events)
+}
+
+// Reset forgets all recorded events.
+func (r *Recorder) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.events = nil
+}
+
+// Of returns the recorded events of type T, in publish order.
+func Of[T any](r *Recorder) []T {
+	var res []T
+	for _, evt := range r.Events() {
+		if e, ok := evt.(T); ok {
+			res = append(res, e)
+		}
+	}
+
+	return res
+}
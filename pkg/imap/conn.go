@@ -0,0 +1,351 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+// Package imap contains a minimal IMAP4rev1 client (RFC 3501), which is just enough to poll a mailbox for
+// unseen messages, download them and flag them afterwards.
+package imap
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Options configure a connection.
+type Options struct {
+	// TLSConfig is used for imaps and StartTLS. If nil, the system roots are used and the server name is taken
+	// from the url.
+	TLSConfig *tls.Config
+	// StartTLS upgrades a plain imap connection before anything else is sent.
+	StartTLS bool
+	// Timeout limits each command. Defaults to 30 seconds.
+	Timeout time.Duration
+	// MaxMessageSize limits the size of a single literal, thus of a fetched message. Larger literals are
+	// skipped without buffering them. Defaults to [DefaultMaxMessageSize].
+	MaxMessageSize int
+}
+
+// DefaultMaxMessageSize is used, if [Options.MaxMessageSize] is not set.
+const DefaultMaxMessageSize = 32 << 20
+
+// ErrMessageTooLarge is returned by [Conn.Fetch], if the message exceeds [Options.MaxMessageSize]. The
+// connection can still be used.
+var ErrMessageTooLarge = errors.New("imap message too large")
+
+// Error is returned, if the server answers a command with NO or BAD.
+type Error struct {
+	Status  string // NO or BAD
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("imap %s: %s", e.Status, e.Message)
+}
+
+// Conn is a synchronous connection to an IMAP server. It is safe for concurrent use, but commands are
+// serialized.
+type Conn struct {
+	mutex   sync.Mutex
+	conn    net.Conn
+	reader  *bufio.Reader
+	tag     int
+	timeout time.Duration
+	maxSize int
+}
+
+// Dial connects to an imap:// or imaps:// url and reads the greeting. The default ports are 143 and 993.
+func Dial(ctx context.Context, rawURL string, opts Options) (*Conn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid imap url: %w", err)
+	}
+
+	if opts.Timeout == 0 {
+		opts.Timeout = 30 * time.Second
+	}
+
+	if opts.MaxMessageSize <= 0 {
+		opts.MaxMessageSize = DefaultMaxMessageSize
+	}
+
+	tlsConfig := opts.TLSConfig
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	}
+
+	if tlsConfig.ServerName == "" {
+		tlsConfig = tlsConfig.Clone()
+		tlsConfig.ServerName = u.Hostname()
+	}
+
+	host := u.Host
+	var useTLS bool
+	switch u.Scheme {
+	case "imap":
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "143")
+		}
+	case "imaps":
+		useTLS = true
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "993")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported imap url scheme: %s", u.Scheme)
+	}
+
+	if useTLS && opts.StartTLS {
+		return nil, fmt.Errorf("StartTLS cannot be used with imaps")
+	}
+
+	dialer := &net.Dialer{Timeout: opts.Timeout}
+	var conn net.Conn
+	if useTLS {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", host)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", host)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to %s: %w", host, err)
+	}
+
+	c := &Conn{conn: conn, reader: bufio.NewReader(conn), timeout: opts.Timeout, maxSize: opts.MaxMessageSize}
+	_ = conn.SetDeadline(time.Now().Add(c.timeout))
+	greeting, err := c.readLine()
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("cannot read greeting: %w", err)
+	}
+
+	if !strings.HasPrefix(greeting.text, "* OK") && !strings.HasPrefix(greeting.text, "* PREAUTH") {
+		_ = conn.Close()
+		return nil, fmt.Errorf("unexpected greeting: %s", greeting.text)
+	}
+
+	if opts.StartTLS {
+		if err := c.startTLS(ctx, tlsConfig); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+func (c *Conn) startTLS(ctx context.Context, config *tls.Config) error {
+	if _, err := c.command("STARTTLS"); err != nil {
+		return fmt.Errorf("cannot start tls: %w", err)
+	}
+
+	tlsConn := tls.Client(c.conn, config)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return fmt.Errorf("cannot start tls: %w", err)
+	}
+
+	c.conn = tlsConn
+	c.reader = bufio.NewReader(tlsConn)
+	return nil
+}
+
+// Login authenticates with a plaintext password, which should only be used on a TLS connection.
+func (c *Conn) Login(username, password string) error {
+	_, err := c.command("LOGIN " + Quote(username) + " " + Quote(password))
+	return err
+}
+
+// Select opens the given mailbox, e.g. INBOX, for reading and writing.
+func (c *Conn) Select(mailbox string) error {
+	_, err := c.command("SELECT " + Quote(mailbox))
+	return err
+}
+
+// SearchUnseen returns the unique identifiers of all messages in the selected mailbox without the \Seen flag.
+func (c *Conn) SearchUnseen() ([]uint32, error) {
+	res, err := c.command("UID SEARCH UNSEEN")
+	if err != nil {
+		return nil, err
+	}
+
+	var uids []uint32
+	for _, line := range res {
+		fields := strings.Fields(line.text)
+		if len(fields) < 2 || !strings.EqualFold(fields[1], "SEARCH") {
+			continue
+		}
+
+		for _, f := range fields[2:] {
+			uid, err := strconv.ParseUint(f, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid uid in search response: %s", f)
+			}
+
+			uids = append(uids, uint32(uid))
+		}
+	}
+
+	return uids, nil
+}
+
+// Fetch returns the complete raw message in RFC 5322 format without setting the \Seen flag.
+func (c *Conn) Fetch(uid uint32) ([]byte, error) {
+	res, err := c.command(fmt.Sprintf("UID FETCH %d BODY.PEEK[]", uid))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range res {
+		if !strings.Contains(strings.ToUpper(line.text), "FETCH") {
+			continue
+		}
+
+		if line.tooLarge {
+			return nil, fmt.Errorf("message %d: %w", uid, ErrMessageTooLarge)
+		}
+
+		if len(line.literals) > 0 {
+			return line.literals[0], nil
+		}
+	}
+
+	return nil, fmt.Errorf("message %d not found", uid)
+}
+
+// AddFlags adds flags like \Seen or \Deleted to the given message.
+func (c *Conn) AddFlags(uid uint32, flags ...string) error {
+	_, err := c.command(fmt.Sprintf("UID STORE %d +FLAGS.SILENT (%s)", uid, strings.Join(flags, " ")))
+	return err
+}
+
+// Expunge permanently removes all messages with the \Deleted flag from the selected mailbox.
+func (c *Conn) Expunge() error {
+	_, err := c.command("EXPUNGE")
+	return err
+}
+
+// Close logs out and closes the connection.
+func (c *Conn) Close() error {
+	_, _ = c.command("LOGOUT")
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.conn.Close()
+}
+
+// line is a single response line. Literals are cut out of the text and kept in order. Literals exceeding
+// the maximum size are dropped and only flagged by tooLarge.
+type line struct {
+	text     string
+	literals [][]byte
+	tooLarge bool
+}
+
+// command sends the command and returns all untagged responses, if the tagged response is OK.
+func (c *Conn) command(cmd string) ([]line, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.tag++
+	tag := "a" + strconv.Itoa(c.tag)
+
+	_ = c.conn.SetDeadline(time.Now().Add(c.timeout))
+	if _, err := io.WriteString(c.conn, tag+" "+cmd+"\r\n"); err != nil {
+		return nil, err
+	}
+
+	var res []line
+	for {
+		l, err := c.readLine()
+		if err != nil {
+			return nil, err
+		}
+
+		if status, ok := strings.CutPrefix(l.text, tag+" "); ok {
+			state, msg, _ := strings.Cut(status, " ")
+			if !strings.EqualFold(state, "OK") {
+				return nil, &Error{Status: strings.ToUpper(state), Message: msg}
+			}
+
+			return res, nil
+		}
+
+		if strings.HasPrefix(l.text, "* ") {
+			res = append(res, l)
+		}
+	}
+}
+
+// readLine reads a logical response line, which may be continued after each literal of the form {n}.
+func (c *Conn) readLine() (line, error) {
+	var l line
+	for {
+		str, err := c.reader.ReadString('\n')
+		if err != nil {
+			return l, err
+		}
+
+		str = strings.TrimRight(str, "\r\n")
+		size, ok := literalSize(str)
+		if !ok {
+			l.text += str
+			return l, nil
+		}
+
+		l.text += str[:strings.LastIndexByte(str, '{')]
+		if size > c.maxSize {
+			// the size is announced by the server, thus never allocate it but stay in sync by skipping it
+			if _, err := io.CopyN(io.Discard, c.reader, int64(size)); err != nil {
+				return l, err
+			}
+
+			l.tooLarge = true
+			continue
+		}
+
+		buf := make([]byte, size)
+		if _, err := io.ReadFull(c.reader, buf); err != nil {
+			return l, err
+		}
+
+		l.literals = append(l.literals, buf)
+	}
+}
+
+// literalSize parses a trailing {n} literal announcement.
+func literalSize(str string) (int, bool) {
+	if !strings.HasSuffix(str, "}") {
+		return 0, false
+	}
+
+	idx := strings.LastIndexByte(str, '{')
+	if idx < 0 {
+		return 0, false
+	}
+
+	size, err := strconv.Atoi(strings.TrimSuffix(str[idx+1:len(str)-1], "+"))
+	if err != nil || size < 0 {
+		return 0, false
+	}
+
+	return size, true
+}
+
+// Quote returns a quoted string. Line breaks cannot be represented and are removed.
+func Quote(str string) string {
+	str = strings.NewReplacer("\r", "", "\n", "", `\`, `\\`, `"`, `\"`).Replace(str)
+	return `"` + str + `"`
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package imap_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"go.wdy.de/nago/pkg/imap"
+	"go.wdy.de/nago/pkg/imap/imaptest"
+)
+
+func TestConn(t *testing.T) {
+	srv, err := imaptest.NewServer("alice", `pa"ss\word`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer srv.Close()
+
+	srv.Deliver([]byte("Subject: first\r\n\r\nhello\r\n"))
+	srv.Deliver([]byte("Subject: second\r\n\r\n{5}\r\nworld\r\n"))
+
+	conn, err := imap.Dial(context.Background(), srv.URL(), imap.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer conn.Close()
+
+	if err := conn.Login("alice", `pa"ss\word`); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := conn.Select("INBOX"); err != nil {
+		t.Fatal(err)
+	}
+
+	uids, err := conn.SearchUnseen()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(uids, []uint32{1, 2}) {
+		t.Fatalf("unexpected uids: %v", uids)
+	}
+
+	body, err := conn.Fetch(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(body) != "Subject: second\r\n\r\n{5}\r\nworld\r\n" {
+		t.Fatalf("unexpected body: %q", body)
+	}
+
+	if err := conn.AddFlags(1, `\Seen`); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := conn.AddFlags(2, `\Deleted`); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := conn.Expunge(); err != nil {
+		t.Fatal(err)
+	}
+
+	uids, err = conn.SearchUnseen()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(uids) != 0 {
+		t.Fatalf("expected no unseen messages but got %v", uids)
+	}
+
+	if msgs := srv.Messages(); len(msgs) != 1 || msgs[0].UID != 1 {
+		t.Fatalf("unexpected messages: %v", msgs)
+	}
+}
+
+func TestLoginFailed(t *testing.T) {
+	srv, err := imaptest.NewServer("alice", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer srv.Close()
+
+	conn, err := imap.Dial(context.Background(), srv.URL(), imap.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer conn.Close()
+
+	var imapErr *imap.Error
+	if err := conn.Login("alice", "wrong"); !errors.As(err, &imapErr) || imapErr.Status != "NO" {
+		t.Fatalf("expected NO but got %v", err)
+	}
+}
+
+func TestFetchTooLarge(t *testing.T) {
+	srv, err := imaptest.NewServer("alice", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer srv.Close()
+
+	srv.Deliver([]byte("Subject: large\r\n\r\n0123456789\r\n"))
+	srv.Deliver([]byte("Subject: small\r\n\r\n"))
+
+	conn, err := imap.Dial(context.Background(), srv.URL(), imap.Options{MaxMessageSize: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer conn.Close()
+
+	if err := conn.Login("alice", "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := conn.Select("INBOX"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := conn.Fetch(1); !errors.Is(err, imap.ErrMessageTooLarge) {
+		t.Fatalf("expected ErrMessageTooLarge but got %v", err)
+	}
+
+	// the connection must still be in sync
+	body, err := conn.Fetch(2)
+	if err != nil || string(body) != "Subject: small\r\n\r\n" {
+		t.Fatalf("unexpected body: %q %v", body, err)
+	}
+}
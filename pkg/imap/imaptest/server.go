@@ -0,0 +1,248 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+// Package imaptest provides an in-memory IMAP server to test mail clients against.
+package imaptest
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Message is a message in the mailbox of a [Server].
+type Message struct {
+	UID   uint32
+	Flags []string
+	Body  []byte
+}
+
+// Server holds a single mailbox and understands just the commands used by [go.wdy.de/nago/pkg/imap.Conn].
+type Server struct {
+	listener net.Listener
+	username string
+	password string
+	mutex    sync.Mutex
+	messages []Message
+	nextUID  uint32
+	wg       sync.WaitGroup
+}
+
+// NewServer starts a server on a random local port, which accepts the given credentials. Use [Server.URL] to
+// connect.
+func NewServer(username, password string) (*Server, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{listener: l, username: username, password: password, nextUID: 1}
+	s.wg.Add(1)
+	go s.serve()
+
+	return s, nil
+}
+
+// URL returns the imap:// url of the server.
+func (s *Server) URL() string {
+	return "imap://" + s.listener.Addr().String()
+}
+
+// Deliver appends a new unseen message.
+func (s *Server) Deliver(body []byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.messages = append(s.messages, Message{UID: s.nextUID, Body: body})
+	s.nextUID++
+}
+
+// Messages returns a copy of all messages.
+func (s *Server) Messages() []Message {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	res := make([]Message, 0, len(s.messages))
+	for _, m := range s.messages {
+		m.Flags = slices.Clone(m.Flags)
+		res = append(res, m)
+	}
+
+	return res
+}
+
+// Close stops listening. Open connections are served until the clients disconnect.
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				slog.Error("imap test server cannot accept", "err", err.Error())
+			}
+
+			return
+		}
+
+		go s.handle(conn)
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	reply := func(format string, args ...any) {
+		_, _ = fmt.Fprintf(w, format+"\r\n", args...)
+	}
+
+	reply("* OK nago test server ready")
+	_ = w.Flush()
+
+	authenticated := false
+	for {
+		str, err := r.ReadString('\n')
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				slog.Error("imap test server cannot read", "err", err.Error())
+			}
+
+			return
+		}
+
+		tag, cmd, _ := strings.Cut(strings.TrimRight(str, "\r\n"), " ")
+		args := parseArgs(cmd)
+		if len(args) == 0 {
+			reply("%s BAD empty command", tag)
+			_ = w.Flush()
+			continue
+		}
+
+		name := strings.ToUpper(args[0])
+		if name == "UID" && len(args) > 1 {
+			name += " " + strings.ToUpper(args[1])
+			args = args[1:]
+		}
+
+		switch {
+		case name == "LOGOUT":
+			reply("* BYE")
+			reply("%s OK LOGOUT completed", tag)
+			_ = w.Flush()
+			return
+		case name == "LOGIN":
+			if len(args) == 3 && args[1] == s.username && args[2] == s.password {
+				authenticated = true
+				reply("%s OK LOGIN completed", tag)
+			} else {
+				reply("%s NO [AUTHENTICATIONFAILED] invalid credentials", tag)
+			}
+		case !authenticated:
+			reply("%s BAD not authenticated", tag)
+		case name == "SELECT":
+			s.mutex.Lock()
+			reply("* %d EXISTS", len(s.messages))
+			s.mutex.Unlock()
+			reply("%s OK [READ-WRITE] SELECT completed", tag)
+		case name == "UID SEARCH":
+			var uids []string
+			s.mutex.Lock()
+			for _, m := range s.messages {
+				if !slices.Contains(m.Flags, `\Seen`) {
+					uids = append(uids, strconv.FormatUint(uint64(m.UID), 10))
+				}
+			}
+			s.mutex.Unlock()
+			reply("* SEARCH %s", strings.Join(uids, " "))
+			reply("%s OK SEARCH completed", tag)
+		case name == "UID FETCH" && len(args) > 1:
+			s.mutex.Lock()
+			for i, m := range s.messages {
+				if strconv.FormatUint(uint64(m.UID), 10) == args[1] {
+					reply("* %d FETCH (UID %d BODY[] {%d}", i+1, m.UID, len(m.Body))
+					_, _ = w.Write(m.Body)
+					reply(")")
+				}
+			}
+			s.mutex.Unlock()
+			reply("%s OK FETCH completed", tag)
+		case name == "UID STORE" && len(args) > 3:
+			flags := strings.Fields(strings.Trim(strings.Join(args[3:], " "), "()"))
+			s.mutex.Lock()
+			for i, m := range s.messages {
+				if strconv.FormatUint(uint64(m.UID), 10) == args[1] {
+					for _, f := range flags {
+						if !slices.Contains(m.Flags, f) {
+							s.messages[i].Flags = append(s.messages[i].Flags, f)
+						}
+					}
+				}
+			}
+			s.mutex.Unlock()
+			reply("%s OK STORE completed", tag)
+		case name == "EXPUNGE":
+			s.mutex.Lock()
+			s.messages = slices.DeleteFunc(s.messages, func(m Message) bool {
+				return slices.Contains(m.Flags, `\Deleted`)
+			})
+			s.mutex.Unlock()
+			reply("%s OK EXPUNGE completed", tag)
+		default:
+			reply("%s BAD unsupported command", tag)
+		}
+
+		_ = w.Flush()
+	}
+}
+
+// parseArgs splits at spaces but keeps quoted strings together and unquotes them.
+func parseArgs(cmd string) []string {
+	var res []string
+	var sb strings.Builder
+	quoted, escaped, inArg := false, false, false
+	for _, r := range cmd {
+		switch {
+		case escaped:
+			sb.WriteRune(r)
+			escaped = false
+		case quoted && r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+			inArg = true
+		case r == ' ' && !quoted:
+			if inArg {
+				res = append(res, sb.String())
+				sb.Reset()
+				inArg = false
+			}
+		default:
+			sb.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if inArg {
+		res = append(res, sb.String())
+	}
+
+	return res
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// JWK is a single public JSON Web Key, see RFC 7517 and RFC 8037. Private key members are never read.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// EC and OKP
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// KeySet is the document served at the jwks_uri of an issuer.
+type KeySet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// Find returns the first key which is suitable to verify a token with the given header. If the header has no
+// kid, the key is only determined, if exactly one key of the matching type exists.
+func (s KeySet) Find(hdr Header) (JWK, bool) {
+	var candidates []JWK
+	for _, key := range s.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+
+		if key.Alg != "" && key.Alg != hdr.Alg {
+			continue
+		}
+
+		if !keyTypeFits(key.Kty, hdr.Alg) {
+			continue
+		}
+
+		if hdr.Kid != "" {
+			if key.Kid == hdr.Kid {
+				return key, true
+			}
+
+			continue
+		}
+
+		candidates = append(candidates, key)
+	}
+
+	if len(candidates) == 1 {
+		return candidates[0], true
+	}
+
+	return JWK{}, false
+}
+
+func keyTypeFits(kty string, alg string) bool {
+	switch alg {
+	case RS256, RS384, RS512, PS256, PS384, PS512:
+		return kty == "RSA"
+	case ES256, ES384, ES512:
+		return kty == "EC"
+	case EdDSA:
+		return kty == "OKP"
+	default:
+		return false
+	}
+}
+
+// PublicKey decodes the key into *rsa.PublicKey, *ecdsa.PublicKey or ed25519.PublicKey.
+func (k JWK) PublicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("jwt: invalid rsa modulus: %w", err)
+		}
+
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("jwt: invalid rsa exponent: %w", err)
+		}
+
+		if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, errors.New("jwt: unsupported rsa exponent")
+		}
+
+		if n.BitLen() < 2048 {
+			return nil, fmt.Errorf("jwt: rsa key too small: %d bits", n.BitLen())
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("jwt: unsupported curve: %q", k.Crv)
+		}
+
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("jwt: invalid x coordinate: %w", err)
+		}
+
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("jwt: invalid y coordinate: %w", err)
+		}
+
+		size := (curve.Params().BitSize + 7) / 8
+		if x.BitLen() > size*8 || y.BitLen() > size*8 {
+			return nil, errors.New("jwt: ec coordinates exceed curve size")
+		}
+
+		// go through the uncompressed encoding, which validates that the point is on the curve
+		buf := make([]byte, 1+2*size)
+		buf[0] = 4
+		x.FillBytes(buf[1 : 1+size])
+		y.FillBytes(buf[1+size:])
+
+		pub, err := ecdsa.ParseUncompressedPublicKey(curve, buf)
+		if err != nil {
+			return nil, fmt.Errorf("jwt: invalid ec point: %w", err)
+		}
+
+		return pub, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("jwt: unsupported curve: %q", k.Crv)
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("jwt: invalid ed25519 key: %w", err)
+		}
+
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("jwt: invalid ed25519 key size")
+		}
+
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("jwt: unsupported key type: %q", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(buf) == 0 {
+		return nil, errors.New("empty value")
+	}
+
+	return new(big.Int).SetBytes(buf), nil
+}
@@ -0,0 +1,265 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+// Package jwt verifies JSON Web Tokens in the compact JWS serialization (RFC 7515, RFC 7519).
+//
+// Only asymmetric algorithms are accepted. The unsecured "none" algorithm and the HMAC family are rejected
+// deliberately, because a verifier which accepts them together with public keys is open to the well-known
+// algorithm confusion attacks.
+package jwt
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// Algorithms as registered in RFC 7518 and RFC 8037.
+const (
+	RS256 = "RS256"
+	RS384 = "RS384"
+	RS512 = "RS512"
+	PS256 = "PS256"
+	PS384 = "PS384"
+	PS512 = "PS512"
+	ES256 = "ES256"
+	ES384 = "ES384"
+	ES512 = "ES512"
+	EdDSA = "EdDSA"
+)
+
+var (
+	ErrMalformed        = errors.New("jwt: malformed token")
+	ErrAlgorithm        = errors.New("jwt: unsupported algorithm")
+	ErrInvalidSignature = errors.New("jwt: invalid signature")
+)
+
+// maxTokenSize protects the decoder from absurd inputs. Real world id tokens are a few kilobytes.
+const maxTokenSize = 64 * 1024
+
+// Header is the protected JOSE header.
+type Header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid,omitempty"`
+	Typ string `json:"typ,omitempty"`
+}
+
+// Claims is the decoded claim set. Numbers are kept as [json.Number] to not lose precision.
+type Claims map[string]any
+
+// String returns the claim as string or the empty string, if it is missing or not a string.
+func (c Claims) String(name string) string {
+	s, _ := c[name].(string)
+	return s
+}
+
+// Strings returns the claim as list of strings. A single string is returned as list with one element,
+// because claims like aud or groups are sent in either form.
+func (c Claims) Strings(name string) []string {
+	switch v := c[name].(type) {
+	case string:
+		return []string{v}
+	case []any:
+		res := make([]string, 0, len(v))
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				res = append(res, s)
+			}
+		}
+
+		return res
+	default:
+		return nil
+	}
+}
+
+// Bool returns the claim as bool. The second return value is false, if the claim is missing. Some providers
+// send booleans as strings, which are accepted as well.
+func (c Claims) Bool(name string) (bool, bool) {
+	switch v := c[name].(type) {
+	case bool:
+		return v, true
+	case string:
+		switch v {
+		case "true":
+			return true, true
+		case "false":
+			return false, true
+		}
+	}
+
+	return false, false
+}
+
+// Time interprets the claim as NumericDate. The second return value is false, if the claim is missing or
+// not a number.
+func (c Claims) Time(name string) (time.Time, bool) {
+	n, ok := c[name].(json.Number)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	f, err := n.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	sec := int64(f)
+	return time.Unix(sec, int64((f-float64(sec))*1e9)), true
+}
+
+// Token is a parsed and verified JWT.
+type Token struct {
+	Header Header
+	Claims Claims
+}
+
+// KeyFunc resolves the public key for the given header.
+type KeyFunc func(hdr Header) (crypto.PublicKey, error)
+
+// Verify parses the compact serialization, resolves the key and checks the signature. The claims are not
+// validated beyond their syntax, which is up to the caller, because the rules for exp, aud etc. depend on
+// the kind of token.
+func Verify(raw string, keyFn KeyFunc) (Token, error) {
+	if len(raw) > maxTokenSize {
+		return Token{}, fmt.Errorf("%w: token too large", ErrMalformed)
+	}
+
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return Token{}, fmt.Errorf("%w: expected 3 parts but got %d", ErrMalformed, len(parts))
+	}
+
+	var hdr Header
+	if err := decodeSegment(parts[0], &hdr); err != nil {
+		return Token{}, fmt.Errorf("%w: header: %w", ErrMalformed, err)
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Token{}, fmt.Errorf("%w: signature: %w", ErrMalformed, err)
+	}
+
+	pub, err := keyFn(hdr)
+	if err != nil {
+		return Token{}, err
+	}
+
+	if err := verifySignature(hdr.Alg, pub, []byte(parts[0]+"."+parts[1]), sig); err != nil {
+		return Token{}, err
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return Token{}, fmt.Errorf("%w: claims: %w", ErrMalformed, err)
+	}
+
+	return Token{Header: hdr, Claims: claims}, nil
+}
+
+func decodeSegment(seg string, v any) error {
+	buf, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+func verifySignature(alg string, pub crypto.PublicKey, data, sig []byte) error {
+	switch alg {
+	case RS256, RS384, RS512, PS256, PS384, PS512:
+		key, ok := pub.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%w: %s requires an rsa key", ErrAlgorithm, alg)
+		}
+
+		h := hashOf(alg)
+		sum := digest(h, data)
+		var err error
+		if strings.HasPrefix(alg, "PS") {
+			err = rsa.VerifyPSS(key, h, sum, sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		} else {
+			err = rsa.VerifyPKCS1v15(key, h, sum, sig)
+		}
+
+		if err != nil {
+			return ErrInvalidSignature
+		}
+	case ES256, ES384, ES512:
+		key, ok := pub.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%w: %s requires an ec key", ErrAlgorithm, alg)
+		}
+
+		// JWS uses the fixed size R || S encoding instead of ASN.1
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size || key.Curve.Params().BitSize != curveBits(alg) {
+			return ErrInvalidSignature
+		}
+
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		if !ecdsa.Verify(key, digest(hashOf(alg), data), r, s) {
+			return ErrInvalidSignature
+		}
+	case EdDSA:
+		key, ok := pub.(ed25519.PublicKey)
+		if !ok {
+			return fmt.Errorf("%w: %s requires an ed25519 key", ErrAlgorithm, alg)
+		}
+
+		if !ed25519.Verify(key, data, sig) {
+			return ErrInvalidSignature
+		}
+	default:
+		return fmt.Errorf("%w: %q", ErrAlgorithm, alg)
+	}
+
+	return nil
+}
+
+func hashOf(alg string) crypto.Hash {
+	switch alg[2:] {
+	case "384":
+		return crypto.SHA384
+	case "512":
+		return crypto.SHA512
+	default:
+		return crypto.SHA256
+	}
+}
+
+func curveBits(alg string) int {
+	switch alg {
+	case ES384:
+		return 384
+	case ES512:
+		return 521
+	default:
+		return 256
+	}
+}
+
+func digest(h crypto.Hash, data []byte) []byte {
+	hh := h.New()
+	hh.Write(data)
+	return hh.Sum(nil)
+}
@@ -0,0 +1,251 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func sign(t *testing.T, hdr Header, claims map[string]any, key crypto.Signer) string {
+	t.Helper()
+
+	h, _ := json.Marshal(hdr)
+	c, _ := json.Marshal(claims)
+	input := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(c)
+
+	var sig []byte
+	var err error
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		hash := hashOf(hdr.Alg)
+		if hdr.Alg[0] == 'P' {
+			sig, err = rsa.SignPSS(rand.Reader, k, hash, digest(hash, []byte(input)), &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		} else {
+			sig, err = rsa.SignPKCS1v15(rand.Reader, k, hash, digest(hash, []byte(input)))
+		}
+	case *ecdsa.PrivateKey:
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, k, digest(hashOf(hdr.Alg), []byte(input)))
+		size := (k.Curve.Params().BitSize + 7) / 8
+		sig = make([]byte, 2*size)
+		r.FillBytes(sig[:size])
+		s.FillBytes(sig[size:])
+	case ed25519.PrivateKey:
+		sig = ed25519.Sign(k, []byte(input))
+	}
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func staticKey(pub crypto.PublicKey) KeyFunc {
+	return func(hdr Header) (crypto.PublicKey, error) {
+		return pub, nil
+	}
+}
+
+func TestVerify(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	p256, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	p384, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	p521, _ := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	tests := []struct {
+		alg string
+		key crypto.Signer
+	}{
+		{RS256, rsaKey},
+		{RS384, rsaKey},
+		{RS512, rsaKey},
+		{PS256, rsaKey},
+		{PS512, rsaKey},
+		{ES256, p256},
+		{ES384, p384},
+		{ES512, p521},
+		{EdDSA, edKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.alg, func(t *testing.T) {
+			raw := sign(t, Header{Alg: tt.alg, Kid: "k1"}, map[string]any{"sub": "alice", "exp": 1700000000}, tt.key)
+
+			tok, err := Verify(raw, staticKey(tt.key.Public()))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tok.Claims.String("sub") != "alice" || tok.Header.Kid != "k1" {
+				t.Fatalf("unexpected token: %+v", tok)
+			}
+
+			if exp, ok := tok.Claims.Time("exp"); !ok || exp.Unix() != 1700000000 {
+				t.Fatalf("unexpected exp: %v", exp)
+			}
+
+			// flip a bit of the payload
+			tampered := []byte(raw)
+			tampered[len(raw)/2] ^= 1
+			if _, err := Verify(string(tampered), staticKey(tt.key.Public())); err == nil {
+				t.Fatal("expected tampered token to fail")
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsAlgorithmConfusion(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	p256, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	h, _ := json.Marshal(Header{Alg: "none"})
+	c, _ := json.Marshal(map[string]any{"sub": "mallory"})
+	unsecured := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(c) + "."
+	if _, err := Verify(unsecured, staticKey(rsaKey.Public())); !errors.Is(err, ErrAlgorithm) {
+		t.Fatalf("expected algorithm error, got %v", err)
+	}
+
+	// an ES256 token must not be accepted with an rsa key and vice versa
+	raw := sign(t, Header{Alg: ES256}, map[string]any{"sub": "alice"}, p256)
+	if _, err := Verify(raw, staticKey(rsaKey.Public())); !errors.Is(err, ErrAlgorithm) {
+		t.Fatalf("expected algorithm error, got %v", err)
+	}
+
+	if _, err := Verify("a.b", staticKey(rsaKey.Public())); !errors.Is(err, ErrMalformed) {
+		t.Fatalf("expected malformed error, got %v", err)
+	}
+}
+
+func TestKeySet(t *testing.T) {
+	p256, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	ecPub := p256.PublicKey
+	set := KeySet{Keys: []JWK{
+		{
+			Kty: "EC",
+			Kid: "ec",
+			Crv: "P-256",
+			X:   base64.RawURLEncoding.EncodeToString(ecPub.X.FillBytes(make([]byte, 32))),
+			Y:   base64.RawURLEncoding.EncodeToString(ecPub.Y.FillBytes(make([]byte, 32))),
+		},
+		{
+			Kty: "OKP",
+			Kid: "ed",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(edKey.Public().(ed25519.PublicKey)),
+		},
+		{
+			Kty: "RSA",
+			Kid: "enc",
+			Use: "enc",
+		},
+	}}
+
+	key, ok := set.Find(Header{Alg: ES256, Kid: "ec"})
+	if !ok {
+		t.Fatal("expected ec key")
+	}
+
+	pub, err := key.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !pub.(*ecdsa.PublicKey).Equal(&p256.PublicKey) {
+		t.Fatal("decoded ec key differs")
+	}
+
+	// without kid, the only key of a fitting type is taken
+	key, ok = set.Find(Header{Alg: EdDSA})
+	if !ok || key.Kid != "ed" {
+		t.Fatalf("expected ed key, got %v", key)
+	}
+
+	if _, ok := set.Find(Header{Alg: RS256, Kid: "enc"}); ok {
+		t.Fatal("encryption keys must not be used for signatures")
+	}
+
+	if _, ok := set.Find(Header{Alg: ES256, Kid: "ed"}); ok {
+		t.Fatal("key type must fit the algorithm")
+	}
+
+	raw := sign(t, Header{Alg: EdDSA}, map[string]any{"aud": []string{"a", "b"}, "email_verified": "true"}, edKey)
+	tok, err := Verify(raw, func(hdr Header) (crypto.PublicKey, error) {
+		key, _ := set.Find(hdr)
+		return key.PublicKey()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if aud := tok.Claims.Strings("aud"); len(aud) != 2 || aud[1] != "b" {
+		t.Fatalf("unexpected aud: %v", aud)
+	}
+
+	if v, ok := tok.Claims.Bool("email_verified"); !v || !ok {
+		t.Fatal("expected email to be verified")
+	}
+}
+
+func TestSign(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	p384, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	for _, key := range []crypto.Signer{rsaKey, p384, edKey} {
+		jwk, err := NewJWK(key.Public(), "k1")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the published key must survive the json roundtrip
+		buf, _ := json.Marshal(KeySet{Keys: []JWK{jwk}})
+		var set KeySet
+		if err := json.Unmarshal(buf, &set); err != nil {
+			t.Fatal(err)
+		}
+
+		raw, err := Sign(Header{Kid: "k1", Typ: "at+jwt"}, map[string]any{"sub": "alice"}, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tok, err := Verify(raw, func(hdr Header) (crypto.PublicKey, error) {
+			key, ok := set.Find(hdr)
+			if !ok {
+				return nil, errors.New("unknown key")
+			}
+
+			return key.PublicKey()
+		})
+		if err != nil {
+			t.Fatalf("%T: %v", key, err)
+		}
+
+		if tok.Header.Typ != "at+jwt" || tok.Claims.String("sub") != "alice" {
+			t.Fatalf("unexpected token: %+v", tok)
+		}
+	}
+
+	if _, err := Sign(Header{Alg: ES256}, nil, p384); !errors.Is(err, ErrAlgorithm) {
+		t.Fatalf("expected algorithm error, got %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// Sign creates the compact serialization of the given claims. If the header has no algorithm, the default
+// algorithm of the key is used, see [AlgorithmOf].
+func Sign(hdr Header, claims any, key crypto.Signer) (string, error) {
+	if hdr.Alg == "" {
+		alg, err := AlgorithmOf(key.Public())
+		if err != nil {
+			return "", err
+		}
+
+		hdr.Alg = alg
+	}
+
+	h, err := json.Marshal(hdr)
+	if err != nil {
+		return "", err
+	}
+
+	c, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	input := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(c)
+
+	var sig []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		hash := hashOf(hdr.Alg)
+		if hdr.Alg[0] == 'P' {
+			sig, err = rsa.SignPSS(rand.Reader, k, hash, digest(hash, []byte(input)), &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		} else {
+			sig, err = rsa.SignPKCS1v15(rand.Reader, k, hash, digest(hash, []byte(input)))
+		}
+	case *ecdsa.PrivateKey:
+		if k.Curve.Params().BitSize != curveBits(hdr.Alg) {
+			return "", fmt.Errorf("%w: %s does not fit the curve", ErrAlgorithm, hdr.Alg)
+		}
+
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, k, digest(hashOf(hdr.Alg), []byte(input)))
+		if err == nil {
+			size := (k.Curve.Params().BitSize + 7) / 8
+			sig = make([]byte, 2*size)
+			r.FillBytes(sig[:size])
+			s.FillBytes(sig[size:])
+		}
+	case ed25519.PrivateKey:
+		sig = ed25519.Sign(k, []byte(input))
+	default:
+		return "", fmt.Errorf("%w: unsupported key type %T", ErrAlgorithm, key)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// AlgorithmOf returns the default algorithm for the given public key.
+func AlgorithmOf(pub crypto.PublicKey) (string, error) {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return RS256, nil
+	case *ecdsa.PublicKey:
+		switch k.Curve.Params().BitSize {
+		case 256:
+			return ES256, nil
+		case 384:
+			return ES384, nil
+		case 521:
+			return ES512, nil
+		}
+	case ed25519.PublicKey:
+		return EdDSA, nil
+	}
+
+	return "", fmt.Errorf("%w: unsupported key type %T", ErrAlgorithm, pub)
+}
+
+// NewJWK encodes the given public key for publication in a [KeySet].
+func NewJWK(pub crypto.PublicKey, kid string) (JWK, error) {
+	alg, err := AlgorithmOf(pub)
+	if err != nil {
+		return JWK{}, err
+	}
+
+	b64 := base64.RawURLEncoding.EncodeToString
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return JWK{Kty: "RSA", Kid: kid, Use: "sig", Alg: alg, N: b64(k.N.Bytes()), E: b64(big.NewInt(int64(k.E)).Bytes())}, nil
+	case *ecdsa.PublicKey:
+		size := (k.Curve.Params().BitSize + 7) / 8
+		return JWK{
+			Kty: "EC",
+			Kid: kid,
+			Use: "sig",
+			Alg: alg,
+			Crv: k.Curve.Params().Name,
+			X:   b64(k.X.FillBytes(make([]byte, size))),
+			Y:   b64(k.Y.FillBytes(make([]byte, size))),
+		}, nil
+	default:
+		return JWK{Kty: "OKP", Kid: kid, Use: "sig", Alg: alg, Crv: "Ed25519", X: b64(pub.(ed25519.PublicKey))}, nil
+	}
+}
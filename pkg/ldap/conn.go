@@ -0,0 +1,346 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package ldap
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+
+	"go.wdy.de/nago/pkg/ldap/internal/proto"
+)
+
+// Options configure a connection.
+type Options struct {
+	// TLSConfig is used for ldaps and StartTLS. If nil, the system roots are used and the server name is taken
+	// from the url.
+	TLSConfig *tls.Config
+	// StartTLS upgrades a plain ldap connection before anything else is sent.
+	StartTLS bool
+	// Timeout limits each operation. Defaults to 30 seconds.
+	Timeout time.Duration
+}
+
+// Conn is a synchronous connection to a directory server. It is safe for concurrent use, but operations are
+// serialized.
+type Conn struct {
+	mutex   sync.Mutex
+	conn    net.Conn
+	reader  *bufio.Reader
+	msgID   int64
+	timeout time.Duration
+}
+
+// Dial connects to a ldap:// or ldaps:// url. The default ports are 389 and 636.
+func Dial(ctx context.Context, rawURL string, opts Options) (*Conn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ldap url: %w", err)
+	}
+
+	if opts.Timeout == 0 {
+		opts.Timeout = 30 * time.Second
+	}
+
+	tlsConfig := opts.TLSConfig
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	}
+
+	if tlsConfig.ServerName == "" {
+		tlsConfig = tlsConfig.Clone()
+		tlsConfig.ServerName = u.Hostname()
+	}
+
+	host := u.Host
+	var useTLS bool
+	switch u.Scheme {
+	case "ldap":
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "389")
+		}
+	case "ldaps":
+		useTLS = true
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "636")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported ldap url scheme: %s", u.Scheme)
+	}
+
+	if useTLS && opts.StartTLS {
+		return nil, fmt.Errorf("StartTLS cannot be used with ldaps")
+	}
+
+	dialer := &net.Dialer{Timeout: opts.Timeout}
+	var conn net.Conn
+	if useTLS {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", host)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", host)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to %s: %w", host, err)
+	}
+
+	c := &Conn{conn: conn, reader: bufio.NewReader(conn), timeout: opts.Timeout}
+	if opts.StartTLS {
+		if err := c.startTLS(ctx, tlsConfig); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+func (c *Conn) startTLS(ctx context.Context, config *tls.Config) error {
+	res, err := c.roundTrip(proto.NewPacket(proto.ClassApplication, proto.OpExtendedRequest, proto.NewPrimitive(proto.ClassContext, 0, []byte(proto.OIDStartTLS))), nil)
+	if err != nil {
+		return fmt.Errorf("cannot start tls: %w", err)
+	}
+
+	if err := resultOf(res[0], proto.OpExtendedResponse); err != nil {
+		return fmt.Errorf("cannot start tls: %w", err)
+	}
+
+	tlsConn := tls.Client(c.conn, config)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return fmt.Errorf("cannot start tls: %w", err)
+	}
+
+	c.conn = tlsConn
+	c.reader = bufio.NewReader(tlsConn)
+	return nil
+}
+
+// Bind authenticates the connection with the given distinguished name and password. An empty password is
+// refused, because most servers treat it as an unauthenticated bind, which succeeds for any name (RFC 4513
+// section 5.1.2).
+func (c *Conn) Bind(dn, password string) error {
+	if password == "" {
+		return &Error{Code: InvalidCredentials, Message: "empty password"}
+	}
+
+	req := proto.NewPacket(proto.ClassApplication, proto.OpBindRequest,
+		proto.NewInt(proto.TagInteger, 3),
+		proto.NewString(dn),
+		proto.NewPrimitive(proto.ClassContext, 0, []byte(password)),
+	)
+
+	res, err := c.roundTrip(req, nil)
+	if err != nil {
+		return err
+	}
+
+	return resultOf(res[0], proto.OpBindResponse)
+}
+
+// SearchRequest describes a search operation.
+type SearchRequest struct {
+	BaseDN     string
+	Scope      Scope
+	Filter     string
+	Attributes []string
+	// SizeLimit is the maximum number of entries in total. Zero means no client side limit.
+	SizeLimit int
+	// PageSize enables the paged results control, if greater than zero.
+	PageSize int
+}
+
+// Search returns all matching entries. If a page size is set, all pages are requested until the server
+// reports that no more entries are available. Continuation references are ignored.
+func (c *Conn) Search(req SearchRequest) ([]Entry, error) {
+	f, err := proto.ParseFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := proto.NewSequence()
+	for _, a := range req.Attributes {
+		attrs.Children = append(attrs.Children, proto.NewString(a))
+	}
+
+	var entries []Entry
+	var cookie []byte
+	for {
+		op := proto.NewPacket(proto.ClassApplication, proto.OpSearchRequest,
+			proto.NewString(req.BaseDN),
+			proto.NewInt(proto.TagEnumerated, int64(req.Scope)),
+			proto.NewInt(proto.TagEnumerated, 0), // never deref aliases
+			proto.NewInt(proto.TagInteger, int64(req.SizeLimit)),
+			proto.NewInt(proto.TagInteger, 0),
+			proto.NewBool(false),
+			f.Packet(),
+			attrs,
+		)
+
+		var controls *proto.Packet
+		if req.PageSize > 0 {
+			value := proto.NewSequence(proto.NewInt(proto.TagInteger, int64(req.PageSize)), proto.NewPrimitive(proto.ClassUniversal, proto.TagOctetString, cookie)).Bytes()
+			controls = proto.NewPacket(proto.ClassContext, 0, proto.NewSequence(
+				proto.NewString(proto.OIDPagedResults),
+				proto.NewPrimitive(proto.ClassUniversal, proto.TagOctetString, value),
+			))
+		}
+
+		res, err := c.roundTrip(op, controls)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, msg := range res[:len(res)-1] {
+			if msg.Is(proto.ClassApplication, proto.OpSearchEntry) {
+				entries = append(entries, decodeEntry(msg))
+			}
+		}
+
+		done := res[len(res)-1]
+		if err := resultOf(done, proto.OpSearchDone); err != nil {
+			return entries, err
+		}
+
+		cookie = pagedResultsCookie(done)
+		if req.PageSize == 0 || len(cookie) == 0 {
+			return entries, nil
+		}
+	}
+}
+
+// Close sends an unbind request and closes the connection.
+func (c *Conn) Close() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.msgID++
+	msg := proto.NewSequence(proto.NewInt(proto.TagInteger, c.msgID), proto.NewPrimitive(proto.ClassApplication, proto.OpUnbindRequest, nil))
+	_ = c.conn.SetDeadline(time.Now().Add(c.timeout))
+	_, _ = c.conn.Write(msg.Bytes())
+
+	return c.conn.Close()
+}
+
+// roundTrip sends the operation and returns all protocol operations of the response, up to and including the
+// final one, which is any other than a search entry or reference. The controls of the last response are
+// attached as its trailing child.
+func (c *Conn) roundTrip(op *proto.Packet, controls *proto.Packet) ([]*proto.Packet, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.msgID++
+	id := c.msgID
+	msg := proto.NewSequence(proto.NewInt(proto.TagInteger, id), op)
+	if controls != nil {
+		msg.Children = append(msg.Children, controls)
+	}
+
+	if err := c.conn.SetDeadline(time.Now().Add(c.timeout)); err != nil {
+		return nil, err
+	}
+
+	if _, err := c.conn.Write(msg.Bytes()); err != nil {
+		return nil, fmt.Errorf("cannot send ldap request: %w", err)
+	}
+
+	var res []*proto.Packet
+	for {
+		if err := c.conn.SetDeadline(time.Now().Add(c.timeout)); err != nil {
+			return nil, err
+		}
+
+		p, err := proto.ReadPacket(c.reader)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read ldap response: %w", err)
+		}
+
+		if len(p.Children) < 2 {
+			return nil, fmt.Errorf("invalid ldap message: %w", proto.ErrMalformed)
+		}
+
+		// unsolicited notifications have the id 0, e.g. a notice of disconnection
+		if msgID := p.Child(0).Int(); msgID != id {
+			if msgID == 0 {
+				if err := resultOf(p.Child(1), proto.OpExtendedResponse); err != nil {
+					return nil, fmt.Errorf("notice of disconnection: %w", err)
+				}
+			}
+
+			return nil, fmt.Errorf("unexpected message id %d: %w", msgID, proto.ErrMalformed)
+		}
+
+		response := p.Child(1)
+		if len(p.Children) > 2 {
+			response.Children = append(response.Children, p.Child(2))
+		}
+
+		res = append(res, response)
+		if response.Class != proto.ClassApplication || (response.Tag != proto.OpSearchEntry && response.Tag != proto.OpSearchReference) {
+			return res, nil
+		}
+	}
+}
+
+// resultOf checks the LDAPResult components and the operation type.
+func resultOf(p *proto.Packet, op int) error {
+	if p.Class != proto.ClassApplication {
+		return fmt.Errorf("unexpected response class %d: %w", p.Class, proto.ErrMalformed)
+	}
+
+	if code := ResultCode(p.Child(0).Int()); code != Success {
+		return &Error{Code: code, MatchedDN: p.Child(1).Str(), Message: p.Child(2).Str()}
+	}
+
+	if p.Tag != op {
+		return fmt.Errorf("unexpected response type %d: %w", p.Tag, proto.ErrMalformed)
+	}
+
+	return nil
+}
+
+func decodeEntry(p *proto.Packet) Entry {
+	e := Entry{DN: p.Child(0).Str()}
+	for _, a := range p.Child(1).Children {
+		attr := Attribute{Name: a.Child(0).Str()}
+		for _, v := range a.Child(1).Children {
+			attr.Values = append(attr.Values, v.Value)
+		}
+
+		e.Attributes = append(e.Attributes, attr)
+	}
+
+	return e
+}
+
+// pagedResultsCookie returns the cookie of the paged results control, which is attached to the done message.
+func pagedResultsCookie(done *proto.Packet) []byte {
+	last := done.Child(len(done.Children) - 1)
+	if !last.Is(proto.ClassContext, 0) || !last.Constructed {
+		return nil
+	}
+
+	for _, ctrl := range last.Children {
+		if ctrl.Child(0).Str() != proto.OIDPagedResults {
+			continue
+		}
+
+		value, err := proto.DecodePacket(ctrl.Child(len(ctrl.Children) - 1).Value)
+		if err != nil {
+			return nil
+		}
+
+		return value.Child(1).Value
+	}
+
+	return nil
+}
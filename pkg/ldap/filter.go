@@ -0,0 +1,29 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package ldap
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EscapeFilter escapes the special characters of RFC 4515, so that arbitrary values can be embedded into a
+// filter string as assertion values.
+func EscapeFilter(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '*', '(', ')', '\\', 0:
+			sb.WriteString(fmt.Sprintf(`\%02x`, c))
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,252 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package proto
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// the identifier classes of the basic encoding rules (X.690)
+const (
+	ClassUniversal   byte = 0x00
+	ClassApplication byte = 0x40
+	ClassContext     byte = 0x80
+)
+
+// the universal tags used by LDAP
+const (
+	TagBoolean     = 0x01
+	TagInteger     = 0x02
+	TagOctetString = 0x04
+	TagEnumerated  = 0x0a
+	TagSequence    = 0x10
+	TagSet         = 0x11
+)
+
+// maxPacketSize limits the memory a single message may allocate. Large directories are read with paged
+// searches, thus a single entry never comes close to this.
+const maxPacketSize = 16 << 20
+
+// Packet is a decoded BER element. Only the subset required by LDAP is supported, which means definite
+// lengths and tag numbers below 31.
+type Packet struct {
+	Class       byte
+	Constructed bool
+	Tag         int
+	Value       []byte
+	Children    []*Packet
+}
+
+func (p *Packet) Is(class byte, tag int) bool {
+	return p.Class == class && p.Tag == tag
+}
+
+func (p *Packet) Child(i int) *Packet {
+	if i < len(p.Children) {
+		return p.Children[i]
+	}
+
+	return &Packet{}
+}
+
+func (p *Packet) Str() string {
+	return string(p.Value)
+}
+
+func (p *Packet) Int() int64 {
+	var v int64
+	for i, b := range p.Value {
+		if i == 0 && b&0x80 != 0 {
+			v = -1
+		}
+
+		v = v<<8 | int64(b)
+	}
+
+	return v
+}
+
+func (p *Packet) Bool() bool {
+	return len(p.Value) > 0 && p.Value[0] != 0
+}
+
+func NewPacket(class byte, tag int, children ...*Packet) *Packet {
+	return &Packet{Class: class, Constructed: true, Tag: tag, Children: children}
+}
+
+func NewSequence(children ...*Packet) *Packet {
+	return NewPacket(ClassUniversal, TagSequence, children...)
+}
+
+func NewPrimitive(class byte, tag int, value []byte) *Packet {
+	return &Packet{Class: class, Tag: tag, Value: value}
+}
+
+func NewString(s string) *Packet {
+	return NewPrimitive(ClassUniversal, TagOctetString, []byte(s))
+}
+
+func NewBool(v bool) *Packet {
+	if v {
+		return NewPrimitive(ClassUniversal, TagBoolean, []byte{0xff})
+	}
+
+	return NewPrimitive(ClassUniversal, TagBoolean, []byte{0x00})
+}
+
+func NewInt(tag int, v int64) *Packet {
+	// minimal two's complement
+	var buf []byte
+	for {
+		buf = append([]byte{byte(v)}, buf...)
+		v >>= 8
+		if (v == 0 && buf[0]&0x80 == 0) || (v == -1 && buf[0]&0x80 != 0) {
+			break
+		}
+	}
+
+	return NewPrimitive(ClassUniversal, tag, buf)
+}
+
+func (p *Packet) Bytes() []byte {
+	content := p.Value
+	if p.Constructed {
+		content = nil
+		for _, c := range p.Children {
+			content = append(content, c.Bytes()...)
+		}
+	}
+
+	id := p.Class | byte(p.Tag)
+	if p.Constructed {
+		id |= 0x20
+	}
+
+	buf := []byte{id}
+	n := len(content)
+	switch {
+	case n < 0x80:
+		buf = append(buf, byte(n))
+	default:
+		var tmp []byte
+		for ; n > 0; n >>= 8 {
+			tmp = append([]byte{byte(n)}, tmp...)
+		}
+
+		buf = append(buf, 0x80|byte(len(tmp)))
+		buf = append(buf, tmp...)
+	}
+
+	return append(buf, content...)
+}
+
+var ErrMalformed = errors.New("malformed ber packet")
+
+// ReadPacket reads exactly one element from the stream.
+func ReadPacket(r io.Reader) (*Packet, error) {
+	var hdr [2]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, err
+	}
+
+	n := int(hdr[1])
+	if n&0x80 != 0 {
+		size := n & 0x7f
+		if size == 0 || size > 4 {
+			return nil, fmt.Errorf("unsupported length encoding: %w", ErrMalformed)
+		}
+
+		var tmp [4]byte
+		if _, err := io.ReadFull(r, tmp[:size]); err != nil {
+			return nil, err
+		}
+
+		n = 0
+		for _, b := range tmp[:size] {
+			n = n<<8 | int(b)
+		}
+	}
+
+	if n > maxPacketSize {
+		return nil, fmt.Errorf("packet of %d bytes exceeds limit: %w", n, ErrMalformed)
+	}
+
+	content := make([]byte, n)
+	if _, err := io.ReadFull(r, content); err != nil {
+		return nil, err
+	}
+
+	return parsePacket(hdr[0], content)
+}
+
+func parsePacket(id byte, content []byte) (*Packet, error) {
+	p := &Packet{Class: id & 0xc0, Constructed: id&0x20 != 0, Tag: int(id & 0x1f)}
+	if p.Tag == 0x1f {
+		return nil, fmt.Errorf("high tag numbers are not supported: %w", ErrMalformed)
+	}
+
+	if !p.Constructed {
+		p.Value = content
+		return p, nil
+	}
+
+	for len(content) > 0 {
+		if len(content) < 2 {
+			return nil, ErrMalformed
+		}
+
+		cid := content[0]
+		n := int(content[1])
+		offset := 2
+		if n&0x80 != 0 {
+			size := n & 0x7f
+			if size == 0 || size > 4 || len(content) < 2+size {
+				return nil, ErrMalformed
+			}
+
+			n = 0
+			for _, b := range content[2 : 2+size] {
+				n = n<<8 | int(b)
+			}
+
+			offset += size
+		}
+
+		if n < 0 || len(content)-offset < n {
+			return nil, ErrMalformed
+		}
+
+		child, err := parsePacket(cid, content[offset:offset+n])
+		if err != nil {
+			return nil, err
+		}
+
+		p.Children = append(p.Children, child)
+		content = content[offset+n:]
+	}
+
+	return p, nil
+}
+
+// DecodePacket decodes a single element, which must span the entire buffer.
+func DecodePacket(buf []byte) (*Packet, error) {
+	r := bytes.NewReader(buf)
+	p, err := ReadPacket(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("trailing bytes: %w", ErrMalformed)
+	}
+
+	return p, nil
+}
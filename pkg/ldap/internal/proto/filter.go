@@ -0,0 +1,429 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package proto
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// the context specific tags of the Filter choice of RFC 4511 section 4.5.1
+const (
+	filterAnd        = 0
+	filterOr         = 1
+	filterNot        = 2
+	filterEquality   = 3
+	filterSubstrings = 4
+	filterGreater    = 5
+	filterLess       = 6
+	filterPresent    = 7
+	filterApprox     = 8
+	filterExtensible = 9
+)
+
+// the bitwise matching rules of Active Directory, which are understood by [Filter.Match]. Any other rule, like
+// LDAP_MATCHING_RULE_IN_CHAIN, is evaluated as equality.
+const (
+	matchBitAnd = "1.2.840.113556.1.4.803"
+	matchBitOr  = "1.2.840.113556.1.4.804"
+)
+
+type substring struct {
+	kind  int // 0 initial, 1 any, 2 final
+	Value []byte
+}
+
+type Filter struct {
+	op         int
+	attr       string
+	Value      []byte
+	substrings []substring
+	rule       string
+	dnAttrs    bool
+	Children   []Filter
+}
+
+// ParseFilter parses the string representation of RFC 4515. The enclosing parentheses are optional for a
+// single item, because administrators tend to forget them.
+func ParseFilter(s string) (Filter, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "(") {
+		s = "(" + s + ")"
+	}
+
+	f, rest, err := parseExpr(s)
+	if err != nil {
+		return Filter{}, fmt.Errorf("invalid filter %q: %w", s, err)
+	}
+
+	if strings.TrimSpace(rest) != "" {
+		return Filter{}, fmt.Errorf("invalid filter %q: unexpected trailing %q", s, rest)
+	}
+
+	return f, nil
+}
+
+func parseExpr(s string) (Filter, string, error) {
+	if len(s) < 2 || s[0] != '(' {
+		return Filter{}, "", fmt.Errorf("expected '('")
+	}
+
+	switch s[1] {
+	case '&', '|', '!':
+		op := map[byte]int{'&': filterAnd, '|': filterOr, '!': filterNot}[s[1]]
+		f := Filter{op: op}
+		rest := strings.TrimSpace(s[2:])
+		for rest != "" && rest[0] != ')' {
+			child, r, err := parseExpr(rest)
+			if err != nil {
+				return Filter{}, "", err
+			}
+
+			f.Children = append(f.Children, child)
+			rest = strings.TrimSpace(r)
+		}
+
+		if rest == "" {
+			return Filter{}, "", fmt.Errorf("missing ')'")
+		}
+
+		if op == filterNot && len(f.Children) != 1 {
+			return Filter{}, "", fmt.Errorf("'!' requires exactly one operand")
+		}
+
+		return f, rest[1:], nil
+	}
+
+	// assertion values must escape parentheses, thus the first one closes the item
+	end := strings.IndexByte(s, ')')
+	if end < 0 {
+		return Filter{}, "", fmt.Errorf("missing ')'")
+	}
+
+	f, err := parseItem(s[1:end])
+	if err != nil {
+		return Filter{}, "", err
+	}
+
+	return f, s[end+1:], nil
+}
+
+func parseItem(item string) (Filter, error) {
+	eq := strings.IndexByte(item, '=')
+	if eq < 1 {
+		return Filter{}, fmt.Errorf("invalid item %q", item)
+	}
+
+	rawValue := item[eq+1:]
+	lhs := item[:eq]
+	var f Filter
+	switch lhs[len(lhs)-1] {
+	case '~':
+		f.op, lhs = filterApprox, lhs[:len(lhs)-1]
+	case '>':
+		f.op, lhs = filterGreater, lhs[:len(lhs)-1]
+	case '<':
+		f.op, lhs = filterLess, lhs[:len(lhs)-1]
+	case ':':
+		f.op = filterExtensible
+		parts := strings.Split(lhs[:len(lhs)-1], ":")
+		f.attr = parts[0]
+		for _, p := range parts[1:] {
+			if strings.EqualFold(p, "dn") {
+				f.dnAttrs = true
+			} else {
+				f.rule = p
+			}
+		}
+
+		if f.attr == "" && f.rule == "" {
+			return Filter{}, fmt.Errorf("extensible match %q requires a type or a matching rule", item)
+		}
+
+		v, err := unescapeFilter(rawValue)
+		if err != nil {
+			return Filter{}, err
+		}
+
+		f.Value = v
+		return f, nil
+	default:
+		f.op = filterEquality
+	}
+
+	f.attr = strings.TrimSpace(lhs)
+	if f.attr == "" {
+		return Filter{}, fmt.Errorf("missing attribute in %q", item)
+	}
+
+	if f.op != filterEquality || !strings.Contains(rawValue, "*") {
+		v, err := unescapeFilter(rawValue)
+		if err != nil {
+			return Filter{}, err
+		}
+
+		f.Value = v
+		return f, nil
+	}
+
+	if rawValue == "*" {
+		f.op = filterPresent
+		return f, nil
+	}
+
+	f.op = filterSubstrings
+	parts := strings.Split(rawValue, "*")
+	for i, p := range parts {
+		if p == "" {
+			continue
+		}
+
+		v, err := unescapeFilter(p)
+		if err != nil {
+			return Filter{}, err
+		}
+
+		kind := 1
+		switch i {
+		case 0:
+			kind = 0
+		case len(parts) - 1:
+			kind = 2
+		}
+
+		f.substrings = append(f.substrings, substring{kind: kind, Value: v})
+	}
+
+	return f, nil
+}
+
+func unescapeFilter(s string) ([]byte, error) {
+	var buf []byte
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			buf = append(buf, s[i])
+			continue
+		}
+
+		if i+2 >= len(s) {
+			return nil, fmt.Errorf("invalid escape sequence in %q", s)
+		}
+
+		b, err := hex.DecodeString(s[i+1 : i+3])
+		if err != nil {
+			return nil, fmt.Errorf("invalid escape sequence in %q", s)
+		}
+
+		buf = append(buf, b[0])
+		i += 2
+	}
+
+	return buf, nil
+}
+
+func (f Filter) Packet() *Packet {
+	switch f.op {
+	case filterAnd, filterOr, filterNot:
+		p := NewPacket(ClassContext, f.op)
+		for _, c := range f.Children {
+			p.Children = append(p.Children, c.Packet())
+		}
+
+		return p
+	case filterPresent:
+		return NewPrimitive(ClassContext, filterPresent, []byte(f.attr))
+	case filterSubstrings:
+		subs := NewSequence()
+		for _, s := range f.substrings {
+			subs.Children = append(subs.Children, NewPrimitive(ClassContext, s.kind, s.Value))
+		}
+
+		return NewPacket(ClassContext, filterSubstrings, NewString(f.attr), subs)
+	case filterExtensible:
+		p := NewPacket(ClassContext, filterExtensible)
+		if f.rule != "" {
+			p.Children = append(p.Children, NewPrimitive(ClassContext, 1, []byte(f.rule)))
+		}
+
+		if f.attr != "" {
+			p.Children = append(p.Children, NewPrimitive(ClassContext, 2, []byte(f.attr)))
+		}
+
+		p.Children = append(p.Children, NewPrimitive(ClassContext, 3, f.Value))
+		if f.dnAttrs {
+			p.Children = append(p.Children, NewPrimitive(ClassContext, 4, []byte{0xff}))
+		}
+
+		return p
+	default:
+		return NewPacket(ClassContext, f.op, NewString(f.attr), NewPrimitive(ClassUniversal, TagOctetString, f.Value))
+	}
+}
+
+func DecodeFilter(p *Packet) (Filter, error) {
+	if p.Class != ClassContext {
+		return Filter{}, fmt.Errorf("invalid filter Class: %w", ErrMalformed)
+	}
+
+	f := Filter{op: p.Tag}
+	switch p.Tag {
+	case filterAnd, filterOr, filterNot:
+		for _, c := range p.Children {
+			child, err := DecodeFilter(c)
+			if err != nil {
+				return Filter{}, err
+			}
+
+			f.Children = append(f.Children, child)
+		}
+
+		if p.Tag == filterNot && len(f.Children) != 1 {
+			return Filter{}, fmt.Errorf("invalid not filter: %w", ErrMalformed)
+		}
+	case filterPresent:
+		f.attr = p.Str()
+	case filterSubstrings:
+		f.attr = p.Child(0).Str()
+		for _, s := range p.Child(1).Children {
+			f.substrings = append(f.substrings, substring{kind: s.Tag, Value: s.Value})
+		}
+	case filterExtensible:
+		for _, c := range p.Children {
+			switch c.Tag {
+			case 1:
+				f.rule = c.Str()
+			case 2:
+				f.attr = c.Str()
+			case 3:
+				f.Value = c.Value
+			case 4:
+				f.dnAttrs = c.Bool()
+			}
+		}
+	case filterEquality, filterGreater, filterLess, filterApprox:
+		f.attr = p.Child(0).Str()
+		f.Value = p.Child(1).Value
+	default:
+		return Filter{}, fmt.Errorf("unknown filter type %d: %w", p.Tag, ErrMalformed)
+	}
+
+	return f, nil
+}
+
+// Match evaluates the filter case-insensitively against the attribute values of an entry. This is a
+// simplification, which is good enough for a test server, but does not respect the matching rules of the
+// attribute types.
+func (f Filter) Match(values func(attr string) []string) bool {
+	switch f.op {
+	case filterAnd:
+		for _, c := range f.Children {
+			if !c.Match(values) {
+				return false
+			}
+		}
+
+		return true
+	case filterOr:
+		for _, c := range f.Children {
+			if c.Match(values) {
+				return true
+			}
+		}
+
+		return false
+	case filterNot:
+		return !f.Children[0].Match(values)
+	case filterPresent:
+		return strings.EqualFold(f.attr, "objectClass") || len(values(f.attr)) > 0
+	}
+
+	for _, v := range values(f.attr) {
+		if f.matchValue(v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f Filter) matchValue(v string) bool {
+	lv := strings.ToLower(v)
+	assertion := strings.ToLower(string(f.Value))
+	switch f.op {
+	case filterEquality, filterApprox:
+		return lv == assertion
+	case filterGreater:
+		return compareValues(lv, assertion) >= 0
+	case filterLess:
+		return compareValues(lv, assertion) <= 0
+	case filterSubstrings:
+		for _, s := range f.substrings {
+			part := strings.ToLower(string(s.Value))
+			switch s.kind {
+			case 0:
+				if !strings.HasPrefix(lv, part) {
+					return false
+				}
+
+				lv = lv[len(part):]
+			case 1:
+				idx := strings.Index(lv, part)
+				if idx < 0 {
+					return false
+				}
+
+				lv = lv[idx+len(part):]
+			case 2:
+				if !strings.HasSuffix(lv, part) {
+					return false
+				}
+			}
+		}
+
+		return true
+	case filterExtensible:
+		switch f.rule {
+		case matchBitAnd, matchBitOr:
+			a, err1 := strconv.ParseInt(v, 10, 64)
+			b, err2 := strconv.ParseInt(string(f.Value), 10, 64)
+			if err1 != nil || err2 != nil {
+				return false
+			}
+
+			if f.rule == matchBitAnd {
+				return a&b == b
+			}
+
+			return a&b != 0
+		default:
+			return lv == assertion
+		}
+	}
+
+	return false
+}
+
+// compareValues orders integers numerically and everything else lexically.
+func compareValues(a, b string) int {
+	ia, err1 := strconv.ParseInt(a, 10, 64)
+	ib, err2 := strconv.ParseInt(b, 10, 64)
+	if err1 == nil && err2 == nil {
+		switch {
+		case ia < ib:
+			return -1
+		case ia > ib:
+			return 1
+		}
+
+		return 0
+	}
+
+	return strings.Compare(a, b)
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package proto
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	attrs := map[string][]string{
+		"objectclass":        {"inetOrgPerson"},
+		"mail":               {"jdoe@example.org"},
+		"cn":                 {"J. (Doe)"},
+		"useraccountcontrol": {"514"},
+	}
+
+	values := func(attr string) []string {
+		return attrs[strings.ToLower(attr)]
+	}
+
+	tests := []struct {
+		filter string
+		want   bool
+	}{
+		{"(mail=jdoe@example.org)", true},
+		{"mail=JDOE@example.org", true},
+		{"(&(objectClass=inetOrgPerson)(mail=*))", true},
+		{"(|(mail=nobody@example.org)(cn=J. \\28Doe\\29))", true},
+		{"(!(mail=*@example.org))", false},
+		{"(mail=j*@*.org)", true},
+		{"(mail=*@example.com)", false},
+		{"(userAccountControl:1.2.840.113556.1.4.803:=2)", true},
+		{"(!(userAccountControl:1.2.840.113556.1.4.803:=1))", true},
+		{"(userAccountControl>=600)", false},
+	}
+
+	for _, tt := range tests {
+		f, err := ParseFilter(tt.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the server only sees the encoded form
+		decoded, err := DecodePacket(f.Packet().Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		f, err = DecodeFilter(decoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := f.Match(values); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+
+	for _, invalid := range []string{"(mail=x", "(&(mail=x)", "(=x)", "(!(a=b)(c=d))", "(mail=\\zz)"} {
+		if _, err := ParseFilter(invalid); err == nil {
+			t.Errorf("expected %q to be invalid", invalid)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+// Package proto contains the wire format of LDAPv3, which is shared by the client in package ldap and the
+// in-memory directory in package ldaptest.
+package proto
+
+// the protocol operations of RFC 4511 section 4.2 and following
+const (
+	OpBindRequest      = 0
+	OpBindResponse     = 1
+	OpUnbindRequest    = 2
+	OpSearchRequest    = 3
+	OpSearchEntry      = 4
+	OpSearchDone       = 5
+	OpSearchReference  = 19
+	OpExtendedRequest  = 23
+	OpExtendedResponse = 24
+)
+
+const (
+	OIDStartTLS     = "1.3.6.1.4.1.1466.20037"
+	OIDPagedResults = "1.2.840.113556.1.4.319"
+)
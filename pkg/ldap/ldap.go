@@ -0,0 +1,151 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+// Package ldap implements a minimal LDAPv3 client (RFC 4511) to read from directories like OpenLDAP or
+// Active Directory.
+//
+// It covers the simple bind, StartTLS and LDAPS, and searches including the simple paged results control of
+// RFC 2696, which is required to read more than the server side size limit, e.g. 1000 entries per default
+// for Active Directory. Write operations, SASL and referral chasing are intentionally not supported.
+//
+// See package ldaptest for an in-memory directory to test clients against.
+package ldap
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Scope of a search request.
+type Scope int
+
+const (
+	ScopeBaseObject Scope = iota
+	ScopeSingleLevel
+	ScopeWholeSubtree
+)
+
+// ResultCode of an operation as defined in RFC 4511 appendix A.
+type ResultCode int
+
+const (
+	Success                ResultCode = 0
+	OperationsError        ResultCode = 1
+	ProtocolError          ResultCode = 2
+	SizeLimitExceeded      ResultCode = 4
+	AuthMethodNotSupported ResultCode = 7
+	Referral               ResultCode = 10
+	UnavailableCriticalExt ResultCode = 12
+	NoSuchObject           ResultCode = 32
+	InvalidDNSyntax        ResultCode = 34
+	InvalidCredentials     ResultCode = 49
+	InsufficientAccess     ResultCode = 50
+	Busy                   ResultCode = 51
+	Unavailable            ResultCode = 52
+	UnwillingToPerform     ResultCode = 53
+	Other                  ResultCode = 80
+)
+
+// Error is a result code other than [Success] returned by the server.
+type Error struct {
+	Code      ResultCode
+	MatchedDN string
+	Message   string
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("ldap result code %d", e.Code)
+	}
+
+	return fmt.Sprintf("ldap result code %d: %s", e.Code, e.Message)
+}
+
+// IsCode returns true, if the error contains an [Error] with the given code.
+func IsCode(err error, code ResultCode) bool {
+	var lerr *Error
+	return errors.As(err, &lerr) && lerr.Code == code
+}
+
+// Attribute of an entry. Values are kept as raw bytes, because some attributes like the objectGUID of Active
+// Directory are binary.
+type Attribute struct {
+	Name   string
+	Values [][]byte
+}
+
+// Entry of the directory.
+type Entry struct {
+	DN         string
+	Attributes []Attribute
+}
+
+// Raw returns the values of the attribute, which is matched case-insensitively.
+func (e Entry) Raw(name string) [][]byte {
+	for _, a := range e.Attributes {
+		if strings.EqualFold(a.Name, name) {
+			return a.Values
+		}
+	}
+
+	return nil
+}
+
+// Values returns the values of the attribute as strings.
+func (e Entry) Values(name string) []string {
+	var res []string
+	for _, v := range e.Raw(name) {
+		res = append(res, string(v))
+	}
+
+	return res
+}
+
+// Get returns the first value of the attribute or the empty string.
+func (e Entry) Get(name string) string {
+	values := e.Raw(name)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return string(values[0])
+}
+
+// NormalizeDN returns a canonical form of a distinguished name to compare it with others. It lower cases the
+// name and removes insignificant spaces around the separators. This is a simplification of RFC 4517, which
+// is good enough to match member attributes against entry names.
+func NormalizeDN(dn string) string {
+	var rdns []string
+	for _, rdn := range splitDN(dn) {
+		attr, value, _ := strings.Cut(rdn, "=")
+		rdns = append(rdns, strings.ToLower(strings.TrimSpace(attr))+"="+strings.ToLower(strings.TrimSpace(value)))
+	}
+
+	return strings.Join(rdns, ",")
+}
+
+// splitDN splits at unescaped commas.
+func splitDN(dn string) []string {
+	var res []string
+	start := 0
+	for i := 0; i < len(dn); i++ {
+		switch dn[i] {
+		case '\\':
+			i++
+		case ',':
+			res = append(res, dn[start:i])
+			start = i + 1
+		}
+	}
+
+	if strings.TrimSpace(dn[start:]) != "" {
+		res = append(res, dn[start:])
+	}
+
+	return res
+}
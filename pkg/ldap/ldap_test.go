@@ -0,0 +1,16 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package ldap
+
+import "testing"
+
+func TestEscapeFilter(t *testing.T) {
+	if got := EscapeFilter(`J. (Doe)*\`); got != `J. \28Doe\29\2a\5c` {
+		t.Fatalf("unexpected escaped filter: %s", got)
+	}
+}
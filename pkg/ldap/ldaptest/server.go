@@ -0,0 +1,300 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+// Package ldaptest provides an in-memory directory to test LDAP clients and the directory synchronization
+// against.
+package ldaptest
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.wdy.de/nago/pkg/ldap"
+	"go.wdy.de/nago/pkg/ldap/internal/proto"
+)
+
+// Server behaves like an OpenLDAP server without access control. It understands simple binds against the
+// userPassword attribute in plaintext, searches with all filter types and the paged results control.
+// Anonymous binds and searches are permitted.
+type Server struct {
+	listener net.Listener
+	mutex    sync.RWMutex
+	entries  []ldap.Entry
+	wg       sync.WaitGroup
+}
+
+// NewServer starts a server on a random local port. Use [Server.URL] to connect.
+func NewServer(entries ...ldap.Entry) (*Server, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{listener: l, entries: entries}
+	s.wg.Add(1)
+	go s.serve()
+
+	return s, nil
+}
+
+// URL returns the ldap:// url of the server.
+func (s *Server) URL() string {
+	return "ldap://" + s.listener.Addr().String()
+}
+
+// SetEntries replaces the content of the directory.
+func (s *Server) SetEntries(entries ...ldap.Entry) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.entries = entries
+}
+
+// Close stops listening. Open connections are served until the clients disconnect.
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				slog.Error("ldap test server cannot accept", "err", err.Error())
+			}
+
+			return
+		}
+
+		go s.handle(conn)
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	for {
+		msg, err := proto.ReadPacket(r)
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				slog.Error("ldap test server cannot read", "err", err.Error())
+			}
+
+			return
+		}
+
+		id := msg.Child(0).Int()
+		op := msg.Child(1)
+		var controls *proto.Packet
+		if len(msg.Children) > 2 {
+			controls = msg.Child(2)
+		}
+
+		var res []reply
+		switch {
+		case op.Is(proto.ClassApplication, proto.OpBindRequest):
+			res = append(res, s.bind(op))
+		case op.Is(proto.ClassApplication, proto.OpUnbindRequest):
+			return
+		case op.Is(proto.ClassApplication, proto.OpSearchRequest):
+			res = s.search(op, controls)
+		case op.Is(proto.ClassApplication, proto.OpExtendedRequest):
+			res = append(res, result(proto.OpExtendedResponse, ldap.ProtocolError, "unsupported extended operation"))
+		default:
+			return
+		}
+
+		for _, r := range res {
+			out := proto.NewSequence(proto.NewInt(proto.TagInteger, id), r.op)
+			if r.controls != nil {
+				out.Children = append(out.Children, r.controls)
+			}
+
+			if _, err := conn.Write(out.Bytes()); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// reply is a protocol operation with the optional controls of the enclosing message.
+type reply struct {
+	op       *proto.Packet
+	controls *proto.Packet
+}
+
+func result(op int, code ldap.ResultCode, msg string) reply {
+	return reply{op: proto.NewPacket(proto.ClassApplication, op, proto.NewInt(proto.TagEnumerated, int64(code)), proto.NewString(""), proto.NewString(msg))}
+}
+
+func (s *Server) bind(op *proto.Packet) reply {
+	dn := op.Child(1).Str()
+	password := op.Child(2)
+	if !password.Is(proto.ClassContext, 0) {
+		return result(proto.OpBindResponse, ldap.AuthMethodNotSupported, "only simple binds are supported")
+	}
+
+	if dn == "" && len(password.Value) == 0 {
+		return result(proto.OpBindResponse, ldap.Success, "")
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for _, e := range s.entries {
+		if ldap.NormalizeDN(e.DN) != ldap.NormalizeDN(dn) {
+			continue
+		}
+
+		for _, pwd := range e.Values("userPassword") {
+			if pwd == string(password.Value) && pwd != "" {
+				return result(proto.OpBindResponse, ldap.Success, "")
+			}
+		}
+	}
+
+	return result(proto.OpBindResponse, ldap.InvalidCredentials, "")
+}
+
+func (s *Server) search(op *proto.Packet, controls *proto.Packet) []reply {
+	base := ldap.NormalizeDN(op.Child(0).Str())
+	scope := ldap.Scope(op.Child(1).Int())
+	sizeLimit := int(op.Child(3).Int())
+	f, err := proto.DecodeFilter(op.Child(6))
+	if err != nil {
+		return []reply{result(proto.OpSearchDone, ldap.ProtocolError, err.Error())}
+	}
+
+	var attrs []string
+	for _, a := range op.Child(7).Children {
+		attrs = append(attrs, a.Str())
+	}
+
+	s.mutex.RLock()
+	var matches []ldap.Entry
+	for _, e := range s.entries {
+		if inScope(ldap.NormalizeDN(e.DN), base, scope) && f.Match(e.Values) {
+			matches = append(matches, selected(e, attrs))
+		}
+	}
+	s.mutex.RUnlock()
+
+	pageSize, offset, paged := pagedRequest(controls)
+	done := result(proto.OpSearchDone, ldap.Success, "")
+	if paged {
+		end := min(offset+pageSize, len(matches))
+		var cookie []byte
+		if end < len(matches) {
+			cookie = []byte(strconv.Itoa(end))
+		}
+
+		value := proto.NewSequence(proto.NewInt(proto.TagInteger, int64(len(matches))), proto.NewPrimitive(proto.ClassUniversal, proto.TagOctetString, cookie)).Bytes()
+		done.controls = proto.NewPacket(proto.ClassContext, 0, proto.NewSequence(proto.NewString(proto.OIDPagedResults), proto.NewPrimitive(proto.ClassUniversal, proto.TagOctetString, value)))
+		matches = matches[min(offset, len(matches)):end]
+	}
+
+	if sizeLimit > 0 && len(matches) > sizeLimit {
+		matches = matches[:sizeLimit]
+		done.op = result(proto.OpSearchDone, ldap.SizeLimitExceeded, "").op
+	}
+
+	var res []reply
+	for _, e := range matches {
+		res = append(res, reply{op: encodeEntry(e)})
+	}
+
+	return append(res, done)
+}
+
+func inScope(dn, base string, scope ldap.Scope) bool {
+	switch scope {
+	case ldap.ScopeBaseObject:
+		return dn == base
+	case ldap.ScopeSingleLevel:
+		return parentDN(dn) == base
+	default:
+		return base == "" || dn == base || strings.HasSuffix(dn, ","+base)
+	}
+}
+
+// pagedRequest returns the page size and the offset, which is encoded as decimal cookie.
+func pagedRequest(controls *proto.Packet) (size, offset int, ok bool) {
+	if controls == nil {
+		return 0, 0, false
+	}
+
+	for _, ctrl := range controls.Children {
+		if ctrl.Child(0).Str() != proto.OIDPagedResults {
+			continue
+		}
+
+		value, err := proto.DecodePacket(ctrl.Child(len(ctrl.Children) - 1).Value)
+		if err != nil {
+			return 0, 0, false
+		}
+
+		cookie, _ := strconv.Atoi(value.Child(1).Str())
+		return int(value.Child(0).Int()), cookie, true
+	}
+
+	return 0, 0, false
+}
+
+func encodeEntry(e ldap.Entry) *proto.Packet {
+	attrs := proto.NewSequence()
+	for _, a := range e.Attributes {
+		values := proto.NewPacket(proto.ClassUniversal, proto.TagSet)
+		for _, v := range a.Values {
+			values.Children = append(values.Children, proto.NewPrimitive(proto.ClassUniversal, proto.TagOctetString, v))
+		}
+
+		attrs.Children = append(attrs.Children, proto.NewSequence(proto.NewString(a.Name), values))
+	}
+
+	return proto.NewPacket(proto.ClassApplication, proto.OpSearchEntry, proto.NewString(e.DN), attrs)
+}
+
+// parentDN removes the first relative name, which ends at the first unescaped comma.
+func parentDN(dn string) string {
+	for i := 0; i < len(dn); i++ {
+		switch dn[i] {
+		case '\\':
+			i++
+		case ',':
+			return dn[i+1:]
+		}
+	}
+
+	return ""
+}
+
+// selected returns a copy, which only contains the given attributes. No names or "*" select all.
+func selected(e ldap.Entry, names []string) ldap.Entry {
+	if len(names) == 0 || slices.Contains(names, "*") {
+		return e
+	}
+
+	res := ldap.Entry{DN: e.DN}
+	for _, a := range e.Attributes {
+		if slices.ContainsFunc(names, func(n string) bool { return strings.EqualFold(n, a.Name) }) {
+			res.Attributes = append(res.Attributes, a)
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package ldaptest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"go.wdy.de/nago/pkg/ldap"
+)
+
+func entry(dn string, attrs ...string) ldap.Entry {
+	e := ldap.Entry{DN: dn}
+	for i := 0; i+1 < len(attrs); i += 2 {
+		e.Attributes = append(e.Attributes, ldap.Attribute{Name: attrs[i], Values: [][]byte{[]byte(attrs[i+1])}})
+	}
+
+	return e
+}
+
+func TestServer(t *testing.T) {
+	entries := []ldap.Entry{
+		entry("dc=example,dc=org", "objectClass", "domain"),
+		entry("cn=admin,dc=example,dc=org", "objectClass", "person", "userPassword", "secret"),
+	}
+
+	for i := range 25 {
+		entries = append(entries, entry(fmt.Sprintf("uid=user%d, ou=people,dc=example,dc=org", i), "objectClass", "person", "uid", fmt.Sprintf("user%d", i)))
+	}
+
+	srv, err := NewServer(entries...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer srv.Close()
+
+	conn, err := ldap.Dial(context.Background(), srv.URL(), ldap.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer conn.Close()
+
+	if err := conn.Bind("cn=admin,dc=example,dc=org", "wrong"); !ldap.IsCode(err, ldap.InvalidCredentials) {
+		t.Fatalf("expected invalid credentials, got %v", err)
+	}
+
+	if err := conn.Bind("cn=admin,dc=example,dc=org", ""); !ldap.IsCode(err, ldap.InvalidCredentials) {
+		t.Fatalf("unauthenticated binds must be refused, got %v", err)
+	}
+
+	if err := conn.Bind("CN=Admin, DC=example,DC=org", "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := conn.Search(ldap.SearchRequest{
+		BaseDN:     "ou=people,dc=example,dc=org",
+		Scope:      ldap.ScopeWholeSubtree,
+		Filter:     "(objectClass=person)",
+		Attributes: []string{"uid"},
+		PageSize:   10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res) != 25 || res[24].Get("uid") != "user24" || res[0].Get("objectClass") != "" {
+		t.Fatalf("unexpected result: %d entries", len(res))
+	}
+
+	if _, err := conn.Search(ldap.SearchRequest{BaseDN: "dc=example,dc=org", Scope: ldap.ScopeWholeSubtree, Filter: "(objectClass=*)", SizeLimit: 3}); !ldap.IsCode(err, ldap.SizeLimitExceeded) {
+		t.Fatalf("expected size limit to be exceeded, got %v", err)
+	}
+
+	res, err = conn.Search(ldap.SearchRequest{BaseDN: "dc=example,dc=org", Scope: ldap.ScopeSingleLevel, Filter: "(objectClass=*)"})
+	if err != nil || len(res) != 1 || res[0].Get("userPassword") != "secret" {
+		t.Fatalf("unexpected single level result: %v %v", res, err)
+	}
+
+	if _, err := ldap.Dial(context.Background(), srv.URL(), ldap.Options{StartTLS: true}); err == nil {
+		t.Fatal("the test server does not support StartTLS")
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package ndb
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/worldiety/option"
+)
+
+// Operation names a message capability which is controlled by an [ACL].
+type Operation string
+
+const (
+	// OpAppend guards [History.Append].
+	OpAppend Operation = "append"
+	// OpPut guards [Retained.Put].
+	OpPut Operation = "put"
+	// OpReplay guards all reads of stored payloads, i.e. [History.Replay] and
+	// [Retained.Get].
+	OpReplay Operation = "replay"
+	// OpSubscribe guards [Notifier.Subscribe].
+	OpSubscribe Operation = "subscribe"
+	// OpDelete guards the [Pruner] capability.
+	OpDelete Operation = "delete"
+)
+
+// Operations lists all operations in a stable order, e.g. for rendering an
+// ACL editor.
+var Operations = []Operation{OpAppend, OpPut, OpReplay, OpSubscribe, OpDelete}
+
+// Principal identifies who is granted an operation by an [ACL]. It is a textual
+// "<kind>:<id>" reference, e.g. "user:1234", "token:abcd", "group:sales" or
+// "role:admin", or the special value [PrincipalAuthenticated].
+//
+// The store treats principals as opaque strings and never resolves them. The
+// meaning of a kind is defined by the enforcing layer, which matches them
+// against an authenticated subject. Keeping the reference textual makes an ACL
+// file readable and editable without any id lookup.
+type Principal string
+
+// PrincipalAuthenticated grants an operation to every authenticated subject.
+const PrincipalAuthenticated Principal = "authenticated"
+
+// Principal kinds understood by the enforcing layer.
+const (
+	PrincipalKindUser  = "user"
+	PrincipalKindToken = "token"
+	PrincipalKindGroup = "group"
+	PrincipalKindRole  = "role"
+)
+
+// NewPrincipal returns the principal reference for the given kind and id.
+func NewPrincipal(kind, id string) Principal {
+	return Principal(kind + ":" + id)
+}
+
+// Split returns the kind and id of the principal. The special
+// [PrincipalAuthenticated] and malformed values return an empty id.
+func (p Principal) Split() (kind, id string) {
+	kind, id, _ = strings.Cut(string(p), ":")
+	return kind, id
+}
+
+// ACL is the access control list of a single event type. Each operation lists
+// the principals which are allowed to perform it. An empty list grants the
+// operation to nobody.
+//
+// ACLs compensate for the deliberately missing schema validation of an engine:
+// instead of checking every payload, only vetted writers may append to a type,
+// and only vetted readers may replay it.
+type ACL struct {
+	Append    []Principal `json:"append,omitempty"`
+	Put       []Principal `json:"put,omitempty"`
+	Replay    []Principal `json:"replay,omitempty"`
+	Subscribe []Principal `json:"subscribe,omitempty"`
+	Delete    []Principal `json:"delete,omitempty"`
+}
+
+// Principals returns the principals which are granted op.
+func (a ACL) Principals(op Operation) []Principal {
+	switch op {
+	case OpAppend:
+		return a.Append
+	case OpPut:
+		return a.Put
+	case OpReplay:
+		return a.Replay
+	case OpSubscribe:
+		return a.Subscribe
+	case OpDelete:
+		return a.Delete
+	default:
+		return nil
+	}
+}
+
+// With returns a copy of the ACL with the principals of op replaced.
+func (a ACL) With(op Operation, principals []Principal) ACL {
+	principals = slices.Clone(principals)
+	switch op {
+	case OpAppend:
+		a.Append = principals
+	case OpPut:
+		a.Put = principals
+	case OpReplay:
+		a.Replay = principals
+	case OpSubscribe:
+		a.Subscribe = principals
+	case OpDelete:
+		a.Delete = principals
+	}
+	return a
+}
+
+// Allows reports whether op is granted to the subject, by asking match for
+// each listed principal until one matches.
+func (a ACL) Allows(op Operation, match func(Principal) bool) bool {
+	return slices.ContainsFunc(a.Principals(op), match)
+}
+
+// ACLs is the optional capability to persist an [ACL] per event type alongside
+// the messages. An engine only stores the lists; enforcement is done by a
+// wrapper in the application layer which knows the authenticated subject.
+type ACLs interface {
+	// ACL returns the access control list of typeID, or an empty option if none
+	// has been declared yet.
+	ACL(typeID TypeID) (option.Opt[ACL], error)
+
+	// SetACL declares or replaces the access control list of typeID. The write
+	// is atomic: readers either see the old or the new list.
+	SetACL(typeID TypeID, acl ACL) error
+
+	// RemoveACL drops the access control list of typeID. It is not an error if
+	// none exists.
+	RemoveACL(typeID TypeID) error
+
+	// ACLTypes returns all event types which have a declared ACL, sorted
+	// ascending.
+	ACLTypes() ([]TypeID, error)
+}
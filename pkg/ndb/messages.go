@@ -238,6 +238,26 @@ type Pruner interface {
 	DeleteSeq(typeID TypeID, seq Seq) error
 }
 
+// RewriteFunc maps a stored message to its replacement payload. msg.Payload is
+// already decompressed and only valid for the duration of the call. Returning
+// changed=false keeps the message byte for byte.
+type RewriteFunc func(msg Message) (payload []byte, changed bool, err error)
+
+// Rewriter is the optional capability to migrate stored payloads in place, e.g.
+// after a breaking change of an event type. Seq, append time and trace id of
+// each message are preserved, so the global order and all consumer checkpoints
+// stay valid. Tombstones are never passed to fn.
+//
+// A rewrite is a maintenance operation: it breaks the append-only promise of
+// [History] for the affected type and should only be run while no consumer
+// relies on the old encoding anymore.
+type Rewriter interface {
+	// Rewrite applies fn to every message of typeID and returns the number of
+	// changed messages. If fn fails, the error is returned and segments which
+	// have not been replaced yet keep their old content.
+	Rewrite(typeID TypeID, fn RewriteFunc) (int, error)
+}
+
 // Notification is the live signal that a message was written. It deliberately
 // carries no payload: fan-out stays cheap and allocation-light, and the payload
 // lifetime pitfalls of [History.Replay] (a reused-buffer view) do not apply.
@@ -0,0 +1,124 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package msgstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/worldiety/option"
+	"go.wdy.de/nago/pkg/ndb"
+)
+
+// aclFile is the name of the per-type access control list inside an event type
+// directory. It does not end in ".bin" and is therefore never mistaken for a
+// segment.
+const aclFile = "acl.json"
+
+var _ ndb.ACLs = (*DB)(nil)
+
+// ACL returns the access control list stored in the acl.json of the type
+// directory, or an empty option if the type has none.
+//
+// This implements [ndb.ACLs].
+func (db *DB) ACL(typeID TypeID) (option.Opt[ndb.ACL], error) {
+	if !ndb.ValidTypeID(string(typeID)) {
+		return option.None[ndb.ACL](), fmt.Errorf("msgstore: invalid type id %q", string(typeID))
+	}
+
+	buf, err := os.ReadFile(db.aclPath(typeID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return option.None[ndb.ACL](), nil
+		}
+		return option.None[ndb.ACL](), fmt.Errorf("msgstore: read acl: %w", err)
+	}
+
+	var acl ndb.ACL
+	if err := json.Unmarshal(buf, &acl); err != nil {
+		return option.None[ndb.ACL](), fmt.Errorf("msgstore: decode acl of type %q: %w", typeID, err)
+	}
+
+	return option.Some(acl), nil
+}
+
+// SetACL writes the acl.json of the type directory, creating the directory if
+// the type has no messages yet. The file is written to a temporary file first
+// and atomically renamed, like every other configuration file of the store.
+//
+// The ACL is removed together with its type by [DB.DeleteType].
+//
+// This implements [ndb.ACLs].
+func (db *DB) SetACL(typeID TypeID, acl ndb.ACL) error {
+	if !ndb.ValidTypeID(string(typeID)) {
+		return fmt.Errorf("msgstore: invalid type id %q", string(typeID))
+	}
+
+	buf, err := json.MarshalIndent(acl, "", "  ")
+	if err != nil {
+		return fmt.Errorf("msgstore: encode acl: %w", err)
+	}
+
+	path := db.aclPath(typeID)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("msgstore: create type dir: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf, 0644); err != nil {
+		return fmt.Errorf("msgstore: write acl: %w", err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("msgstore: replace acl: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveACL deletes the acl.json of the type directory, if any.
+//
+// This implements [ndb.ACLs].
+func (db *DB) RemoveACL(typeID TypeID) error {
+	if !ndb.ValidTypeID(string(typeID)) {
+		return fmt.Errorf("msgstore: invalid type id %q", string(typeID))
+	}
+
+	if err := os.Remove(db.aclPath(typeID)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("msgstore: remove acl: %w", err)
+	}
+
+	return nil
+}
+
+// ACLTypes returns the event types which have an acl.json, sorted ascending.
+// Like [DB.Types], it never scans any segment.
+//
+// This implements [ndb.ACLs].
+func (db *DB) ACLTypes() ([]TypeID, error) {
+	types, err := db.Types()
+	if err != nil {
+		return nil, err
+	}
+
+	var out []TypeID
+	for _, t := range types {
+		if _, err := os.Stat(db.aclPath(t)); err == nil {
+			out = append(out, t)
+		}
+	}
+
+	return out, nil
+}
+
+func (db *DB) aclPath(typeID TypeID) string {
+	return filepath.Join(db.dir, "events", string(typeID), aclFile)
+}
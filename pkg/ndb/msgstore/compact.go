@@ -0,0 +1,267 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package msgstore
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// compactTmpSuffix is appended to a segment path while its compacted
+// replacement is written. Such files never end in ".bin" and are therefore
+// ignored by listSegments, so a crash during compaction leaves the original
+// segment fully intact.
+const compactTmpSuffix = ".compact"
+
+// CompactStats summarizes the effect of [DB.Compact].
+type CompactStats struct {
+	// Segments is the number of finalized segment files which have been
+	// rewritten or removed.
+	Segments int
+	// Removed is the number of segment files which only contained tombstones
+	// and have therefore been deleted entirely.
+	Removed int
+	// Tombstones is the number of tombstone frames which have been dropped.
+	Tombstones uint64
+	// BytesFreed is the number of bytes released on disk.
+	BytesFreed int64
+}
+
+func (s *CompactStats) add(o CompactStats) {
+	s.Segments += o.Segments
+	s.Removed += o.Removed
+	s.Tombstones += o.Tombstones
+	s.BytesFreed += o.BytesFreed
+}
+
+// Compact rewrites all finalized segments of the given types (empty = all
+// known types) which contain tombstones created by [DB.DeleteSeq], so that the
+// zeroed payloads are physically released. Segments without tombstones are not
+// touched, and the pending segment is never compacted.
+//
+// The rewritten segment keeps its file name and thus its sequence range: the
+// monotonic order is preserved and freed space is never refilled with newer
+// messages. A segment which only held tombstones is removed entirely. Each
+// replacement is first written to a temporary file and then atomically renamed
+// over the original, so a crash leaves either the old or the new segment.
+//
+// Compaction is serialized with [DB.DeleteSeq] and [DB.ApplyRetention]. A
+// concurrent Replay which is positioned inside a segment while it is being
+// replaced may skip messages of that segment, therefore schedule compaction at
+// a time where no long-running replay of the affected types is active.
+func (db *DB) Compact(types []TypeID) (CompactStats, error) {
+	var stats CompactStats
+	for _, td := range db.resolveTypeDirs(filepath.Join(db.dir, "events"), types) {
+		s, err := db.compactType(td.dir)
+		stats.add(s)
+		if err != nil {
+			return stats, fmt.Errorf("msgstore: compact type %q: %w", td.id, err)
+		}
+	}
+
+	if stats.Segments > 0 {
+		slog.Info("msgstore: compaction complete", "segments", stats.Segments, "removed", stats.Removed, "tombstones", stats.Tombstones, "freed", stats.BytesFreed)
+	}
+
+	return stats, nil
+}
+
+// compactType compacts the finalized segments of a single type directory.
+func (db *DB) compactType(dir string) (CompactStats, error) {
+	db.maintMu.Lock()
+	defer db.maintMu.Unlock()
+
+	var stats CompactStats
+
+	segments, err := listSegments(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return stats, nil
+		}
+		return stats, err
+	}
+
+	for _, seg := range segments {
+		if seg.isPending() {
+			continue
+		}
+
+		s, err := compactSegment(db.pool, seg.path, db.opts.MaxMessageSize)
+		stats.add(s)
+		if err != nil {
+			return stats, err
+		}
+	}
+
+	return stats, nil
+}
+
+// compactSegment rewrites a single finalized segment without its tombstone
+// frames. It is a no-op if the segment holds no tombstones.
+func compactSegment(pool *FilePool, path string, maxMsgSize int64) (CompactStats, error) {
+	var stats CompactStats
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		return stats, fmt.Errorf("stat segment: %w", err)
+	}
+
+	// a cheap first pass avoids rewriting segments which have nothing to free
+	var tombstones, live uint64
+	read := int64(segHeaderSize)
+	for msg, err := range readMessages(pool, path, maxMsgSize) {
+		if err != nil {
+			return stats, fmt.Errorf("read segment: %w", err)
+		}
+		if msg.IsTombstone() {
+			tombstones++
+		} else {
+			live++
+		}
+		read += frameSize(&msg)
+	}
+
+	if tombstones == 0 {
+		return stats, nil
+	}
+
+	// readMessages skips corrupt frames and stops at read errors, but a partial
+	// read must never be mistaken for a segment without live messages, thus the
+	// segment is left untouched unless each of its bytes has been accounted for
+	if read != fi.Size() {
+		return stats, fmt.Errorf("segment %s is only readable up to %d of %d bytes, refusing to compact", path, read, fi.Size())
+	}
+
+	stats.Segments = 1
+	stats.Tombstones = tombstones
+
+	if live == 0 {
+		pool.Evict(path)
+		if err := os.Remove(path); err != nil {
+			return stats, fmt.Errorf("remove empty segment: %w", err)
+		}
+		stats.Removed = 1
+		stats.BytesFreed = fi.Size()
+		return stats, nil
+	}
+
+	tmpPath := path + compactTmpSuffix
+	_ = os.Remove(tmpPath) // leftover of an interrupted compaction
+
+	tmp, err := os.Create(tmpPath)
+	if err != nil {
+		return stats, fmt.Errorf("create compaction file: %w", err)
+	}
+
+	size, err := writeCompacted(tmp, pool, path, maxMsgSize)
+	if err != nil {
+		discardReplacement(tmp)
+		return stats, fmt.Errorf("write compaction file: %w", err)
+	}
+
+	if err := replaceSegment(pool, tmp, path); err != nil {
+		return stats, err
+	}
+
+	stats.BytesFreed = fi.Size() - size
+	return stats, nil
+}
+
+// replaceSegment makes the fully written replacement tmp durable and then
+// atomically renames it over the segment at path. The replacement is synced
+// before the rename and the directory afterward, otherwise a power loss could
+// persist the rename but not the data and leave a truncated segment behind.
+// The tmp file is always closed, and removed on failure.
+func replaceSegment(pool *FilePool, tmp *os.File, path string) error {
+	if err := tmp.Sync(); err != nil {
+		discardReplacement(tmp)
+		return fmt.Errorf("sync replacement: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("close replacement: %w", err)
+	}
+
+	// evict the old handle before renaming so the pool stays consistent
+	pool.Evict(path)
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("replace segment: %w", err)
+	}
+
+	if err := syncDir(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("sync segment dir: %w", err)
+	}
+
+	return nil
+}
+
+// frameSize returns the number of bytes the message occupies in a segment.
+func frameSize(msg *Message) int64 {
+	return int64(msgFrameOverhead + msgFixedSize + len(msg.Payload))
+}
+
+// discardReplacement closes and removes an unused replacement file.
+func discardReplacement(tmp *os.File) {
+	_ = tmp.Close()
+	_ = os.Remove(tmp.Name())
+}
+
+// syncDir flushes the directory entries of dir, so that a preceding rename
+// survives a power loss. Windows does not support syncing directories and
+// persists renames on its own.
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+
+	f, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+
+	err = f.Sync()
+	if cerr := f.Close(); cerr != nil && err == nil {
+		err = cerr
+	}
+
+	return err
+}
+
+// writeCompacted writes the segment header and all live messages of the
+// segment at path into dst and returns the number of bytes written.
+func writeCompacted(dst *os.File, pool *FilePool, path string, maxMsgSize int64) (int64, error) {
+	n, err := dst.Write(marshalSegHeader())
+	if err != nil {
+		return 0, err
+	}
+
+	size := int64(n)
+	var buf []byte
+	for msg, err := range readMessages(pool, path, maxMsgSize) {
+		if err != nil {
+			return 0, err
+		}
+		if msg.IsTombstone() {
+			continue
+		}
+
+		buf = MarshalInto(&msg, buf)
+		n, err := dst.Write(buf)
+		if err != nil {
+			return 0, err
+		}
+		size += int64(n)
+	}
+
+	return size, nil
+}
@@ -0,0 +1,311 @@
+package msgstore_test
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/worldiety/option"
+	"go.wdy.de/nago/pkg/ndb/msgstore"
+)
+
+// ---------- Compact ----------
+
+func TestCompactRemovesTombstones(t *testing.T) {
+	dir := t.TempDir()
+	db := option.Must(msgstore.Open(dir, msgstore.Options{
+		Compress:    msgstore.NoCompression,
+		ShouldSplit: msgstore.SplitByCount(5),
+	}))
+	defer func() { option.MustZero(db.Close()) }()
+
+	var traceID [16]byte
+	const typeID msgstore.TypeID = "1"
+
+	var seqs []msgstore.Seq
+	for i := range 12 {
+		seqs = append(seqs, option.Must(db.Append(typeID, traceID, []byte("payload-"+strconv.Itoa(i)))))
+	}
+
+	// delete two messages in the first finalized segment
+	option.MustZero(db.DeleteSeq(typeID, seqs[1]))
+	option.MustZero(db.DeleteSeq(typeID, seqs[3]))
+
+	before := option.Must(db.TypeStat(typeID))
+
+	stats := option.Must(db.Compact(nil))
+	if stats.Segments != 1 {
+		t.Fatalf("expected 1 compacted segment, got %d", stats.Segments)
+	}
+	if stats.Tombstones != 2 {
+		t.Fatalf("expected 2 dropped tombstones, got %d", stats.Tombstones)
+	}
+	if stats.BytesFreed <= 0 {
+		t.Fatalf("expected freed bytes, got %d", stats.BytesFreed)
+	}
+
+	after := option.Must(db.TypeStat(typeID))
+	if after.Bytes != before.Bytes-stats.BytesFreed {
+		t.Fatalf("expected %d bytes on disk, got %d", before.Bytes-stats.BytesFreed, after.Bytes)
+	}
+	if after.Segments != before.Segments {
+		t.Fatalf("expected segment count to stay %d, got %d", before.Segments, after.Segments)
+	}
+
+	// the remaining messages are still intact and in order
+	var got []string
+	for _, msg := range db.Replay([]msgstore.TypeID{typeID}, 1, math.MaxUint64) {
+		got = append(got, string(msg.Payload))
+	}
+	if len(got) != 10 {
+		t.Fatalf("expected 10 messages after compaction, got %d", len(got))
+	}
+	if got[0] != "payload-0" || got[1] != "payload-2" || got[2] != "payload-4" {
+		t.Fatalf("unexpected order after compaction: %v", got[:3])
+	}
+
+	// compacting again is a no-op
+	stats = option.Must(db.Compact(nil))
+	if stats.Segments != 0 {
+		t.Fatalf("expected second compaction to be a no-op, got %+v", stats)
+	}
+}
+
+func TestCompactRemovesEmptySegment(t *testing.T) {
+	dir := t.TempDir()
+	db := option.Must(msgstore.Open(dir, msgstore.Options{
+		Compress:    msgstore.NoCompression,
+		ShouldSplit: msgstore.SplitByCount(3),
+	}))
+
+	var traceID [16]byte
+	const typeID msgstore.TypeID = "1"
+
+	var seqs []msgstore.Seq
+	for i := range 4 {
+		seqs = append(seqs, option.Must(db.Append(typeID, traceID, []byte("msg-"+strconv.Itoa(i)))))
+	}
+
+	for _, seq := range seqs[:3] {
+		option.MustZero(db.DeleteSeq(typeID, seq))
+	}
+
+	stats := option.Must(db.Compact([]msgstore.TypeID{typeID}))
+	if stats.Removed != 1 {
+		t.Fatalf("expected the fully deleted segment to be removed, got %+v", stats)
+	}
+
+	if c := countReplayForType(db, typeID); c != 1 {
+		t.Fatalf("expected 1 remaining message, got %d", c)
+	}
+
+	// no stale temporary files are left behind
+	entries := option.Must(os.ReadDir(filepath.Join(dir, "events", string(typeID))))
+	for _, e := range entries {
+		if filepath.Ext(e.Name()) != ".bin" {
+			t.Fatalf("unexpected file after compaction: %s", e.Name())
+		}
+	}
+
+	option.MustZero(db.Close())
+
+	// sequence ids are never reissued after reopen
+	db = option.Must(msgstore.Open(dir, msgstore.Options{Compress: msgstore.NoCompression}))
+	defer func() { option.MustZero(db.Close()) }()
+
+	seq := option.Must(db.Append(typeID, traceID, []byte("after-reopen")))
+	if seq <= seqs[len(seqs)-1] {
+		t.Fatalf("expected seq > %d after reopen, got %d", seqs[len(seqs)-1], seq)
+	}
+}
+
+func TestCompactKeepsUnreadableSegment(t *testing.T) {
+	dir := t.TempDir()
+	db := option.Must(msgstore.Open(dir, msgstore.Options{
+		Compress:    msgstore.NoCompression,
+		ShouldSplit: msgstore.SplitByCount(3),
+	}))
+	defer func() { option.MustZero(db.Close()) }()
+
+	var traceID [16]byte
+	const typeID msgstore.TypeID = "1"
+
+	var seqs []msgstore.Seq
+	for i := range 4 {
+		seqs = append(seqs, option.Must(db.Append(typeID, traceID, []byte("msg-"+strconv.Itoa(i)))))
+	}
+
+	for _, seq := range seqs[1:3] {
+		option.MustZero(db.DeleteSeq(typeID, seq))
+	}
+
+	// corrupt the payload of the only live message, so that it is skipped while reading
+	var path string
+	for _, e := range option.Must(os.ReadDir(filepath.Join(dir, "events", string(typeID)))) {
+		if !strings.HasSuffix(e.Name(), "_.bin") {
+			path = filepath.Join(dir, "events", string(typeID), e.Name())
+		}
+	}
+
+	buf := option.Must(os.ReadFile(path))
+	buf[bytes.Index(buf, []byte("msg-0"))] ^= 0xff
+	option.MustZero(os.WriteFile(path, buf, 0600))
+
+	if _, err := db.Compact([]msgstore.TypeID{typeID}); err == nil {
+		t.Fatal("expected compaction of an unreadable segment to fail")
+	}
+
+	// the segment must neither be removed nor rewritten
+	if got := option.Must(os.ReadFile(path)); !bytes.Equal(got, buf) {
+		t.Fatal("expected the unreadable segment to be left untouched")
+	}
+}
+
+// ---------- Retention ----------
+
+func TestRetentionMaxCount(t *testing.T) {
+	dir := t.TempDir()
+	const typeA msgstore.TypeID = "a"
+	const typeB msgstore.TypeID = "b"
+
+	db := option.Must(msgstore.Open(dir, msgstore.Options{
+		Compress:    msgstore.NoCompression,
+		ShouldSplit: msgstore.SplitByCount(10),
+		Retention: msgstore.RetentionPolicies(map[msgstore.TypeID]msgstore.RetentionPolicy{
+			typeA: {MaxCount: 15},
+		}),
+	}))
+	defer func() { option.MustZero(db.Close()) }()
+
+	var traceID [16]byte
+	for i := range 50 {
+		option.Must(db.Append(typeA, traceID, []byte("a-"+strconv.Itoa(i))))
+		option.Must(db.Append(typeB, traceID, []byte("b-"+strconv.Itoa(i))))
+	}
+
+	// retention runs on every split: at least 15 messages are kept, and the
+	// finalized segments hold at most one segment more than required, plus
+	// whatever has been appended to the pending segment since
+	if c := countReplayForType(db, typeA); c < 15 || c > 15+10+10 {
+		t.Fatalf("expected between 15 and 35 retained messages, got %d", c)
+	}
+
+	// types without a policy keep their full history
+	if c := countReplayForType(db, typeB); c != 50 {
+		t.Fatalf("expected 50 messages for type without policy, got %d", c)
+	}
+
+	// the newest message is always retained
+	var last string
+	for _, msg := range db.Replay([]msgstore.TypeID{typeA}, 1, math.MaxUint64) {
+		last = string(msg.Payload)
+	}
+	if last != "a-49" {
+		t.Fatalf("expected newest message to survive, got %q", last)
+	}
+}
+
+func TestRetentionMaxBytes(t *testing.T) {
+	dir := t.TempDir()
+	const typeID msgstore.TypeID = "1"
+
+	db := option.Must(msgstore.Open(dir, msgstore.Options{
+		Compress:    msgstore.NoCompression,
+		ShouldSplit: msgstore.SplitByCount(10),
+		Retention:   msgstore.RetainAll(msgstore.RetentionPolicy{MaxBytes: 2048}),
+	}))
+	defer func() { option.MustZero(db.Close()) }()
+
+	var traceID [16]byte
+	payload := make([]byte, 100)
+	for range 100 {
+		option.Must(db.Append(typeID, traceID, payload))
+	}
+
+	stat := option.Must(db.TypeStat(typeID))
+	if stat.Bytes < 2048 {
+		t.Fatalf("retention dropped too much: %d bytes left", stat.Bytes)
+	}
+	// a single segment of 10 messages is ~1.6 KiB, so at most two finalized
+	// segments plus the pending one may survive
+	if stat.Bytes > 3*10*(100+57)+3*9 {
+		t.Fatalf("retention did not drop enough: %d bytes left", stat.Bytes)
+	}
+}
+
+func TestApplyRetentionMaxAge(t *testing.T) {
+	dir := t.TempDir()
+	const typeID msgstore.TypeID = "1"
+
+	db := option.Must(msgstore.Open(dir, msgstore.Options{
+		Compress:    msgstore.NoCompression,
+		ShouldSplit: msgstore.SplitByCount(5),
+		Retention:   msgstore.RetainAll(msgstore.RetentionPolicy{MaxAge: 50 * time.Millisecond}),
+	}))
+	defer func() { option.MustZero(db.Close()) }()
+
+	var traceID [16]byte
+	for i := range 10 {
+		option.Must(db.Append(typeID, traceID, []byte("old-"+strconv.Itoa(i))))
+	}
+
+	// nothing is old enough yet
+	stats := option.Must(db.ApplyRetention(nil))
+	if stats.Segments != 0 {
+		t.Fatalf("expected no dropped segments yet, got %+v", stats)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	// the first segment with 5 messages is finalized and expired, the pending
+	// segment is never dropped
+	stats = option.Must(db.ApplyRetention(nil))
+	if stats.Segments != 1 {
+		t.Fatalf("expected 1 dropped segment, got %+v", stats)
+	}
+
+	if c := countReplayForType(db, typeID); c != 5 {
+		t.Fatalf("expected 5 remaining messages, got %d", c)
+	}
+}
+
+func TestRetentionKeepsSegmentsAboveFloor(t *testing.T) {
+	dir := t.TempDir()
+	const typeID msgstore.TypeID = "1"
+
+	var floor msgstore.Seq
+	db := option.Must(msgstore.Open(dir, msgstore.Options{
+		Compress:    msgstore.NoCompression,
+		ShouldSplit: msgstore.SplitByCount(5),
+		Retention: msgstore.RetainAll(msgstore.RetentionPolicy{
+			MaxAge: time.Nanosecond,
+			Floor:  func() (msgstore.Seq, error) { return floor, nil },
+		}),
+	}))
+	defer func() { option.MustZero(db.Close()) }()
+
+	var traceID [16]byte
+	for i := range 10 {
+		option.Must(db.Append(typeID, traceID, []byte("msg-"+strconv.Itoa(i))))
+	}
+
+	time.Sleep(time.Millisecond)
+
+	// nothing has been consumed, so nothing may be dropped
+	stats := option.Must(db.ApplyRetention(nil))
+	if stats.Segments != 0 {
+		t.Fatalf("expected no dropped segments below floor, got %+v", stats)
+	}
+
+	// the consumer has seen the first segment
+	floor = 5
+	stats = option.Must(db.ApplyRetention(nil))
+	if stats.Segments != 1 {
+		t.Fatalf("expected 1 dropped segment, got %+v", stats)
+	}
+}
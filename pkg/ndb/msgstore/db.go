@@ -33,6 +33,12 @@ type DB struct {
 	pool     *FilePool
 	ownPool  bool            // true only if pool was created by this DB (default); shared/injected pools are not closed here
 	notify   *notifyRegistry // live append/put subscribers
+
+	maintMu  sync.Mutex                         // serializes in-place rewrites of finalized segments (DeleteSeq, Compact, retention)
+	segStats map[string]map[string]segmentStats // per type dir and segment path, cached by retention, guarded by maintMu
+
+	schemaMu sync.Mutex             // serializes schema.json updates
+	schemas  map[TypeID]*ndb.Schema // latest schema per type, for Options.ValidateSchemas
 }
 
 // Compile-time proof that the engine implements the full neutral contract as
@@ -95,6 +101,8 @@ func Open(dir string, opts Options) (*DB, error) {
 		tindex:   newTimeIndex(timesDir, pool),
 		types:    make(map[TypeID]*typeState),
 		notify:   newNotifyRegistry(),
+		schemas:  make(map[TypeID]*ndb.Schema),
+		segStats: make(map[string]map[string]segmentStats),
 	}
 
 	// bootstrap: find the global max sequence ID across all event type directories
@@ -237,6 +245,12 @@ func (db *DB) Append(typeID TypeID, traceID TraceID, payload []byte) (Seq, error
 		return 0, fmt.Errorf("%w: %d bytes", ErrPayloadTooLarge, len(payload))
 	}
 
+	if db.opts.ValidateSchemas {
+		if err := db.validate(typeID, payload); err != nil {
+			return 0, err
+		}
+	}
+
 	ts, err := db.getTypeState(typeID)
 	if err != nil {
 		return 0, err
@@ -264,11 +278,13 @@ func (db *DB) Append(typeID TypeID, traceID TraceID, payload []byte) (Seq, error
 	ts.mu.Lock()
 
 	// check split condition before writing
+	split := false
 	if ts.seg.info.MessageCount > 0 && db.opts.ShouldSplit(ts.seg.info) {
 		if err := db.splitSegment(ts); err != nil {
 			ts.mu.Unlock()
 			return 0, fmt.Errorf("msgstore: split segment: %w", err)
 		}
+		split = true
 	}
 
 	data := MarshalInto(&msg, ts.writeBuf)
@@ -302,9 +318,42 @@ func (db *DB) Append(typeID TypeID, traceID TraceID, payload []byte) (Seq, error
 	// notify live subscribers (non-blocking; never stalls the writer)
 	db.notify.publish(Notification{Type: typeID, Seq: Seq(seqID), TimeNano: now, TraceID: traceID})
 
+	// a split just produced a new finalized segment, which is the natural point
+	// to drop expired history of this type
+	if split {
+		db.retainAfterSplit(typeID, ts.dir)
+	}
+
 	return Seq(seqID), nil
 }
 
+// retainAfterSplit applies the retention policy of typeID, if any. Failures
+// are only logged, because the append itself has already succeeded. The
+// figures of the finalized segments are cached, thus only the new segment is
+// scanned. If another maintenance operation like [DB.Compact] is running, the
+// retention is skipped instead of stalling the append, and the next split
+// catches up.
+func (db *DB) retainAfterSplit(typeID TypeID, dir string) {
+	if db.opts.Retention == nil {
+		return
+	}
+
+	policy, ok := db.opts.Retention(typeID)
+	if !ok || policy.IsZero() {
+		return
+	}
+
+	if !db.maintMu.TryLock() {
+		slog.Debug("msgstore: retention after split skipped, maintenance is running", "type", typeID)
+		return
+	}
+	defer db.maintMu.Unlock()
+
+	if _, err := db.applyRetentionLocked(dir, policy, time.Now()); err != nil {
+		slog.Warn("msgstore: retention after split failed", "type", typeID, "err", err)
+	}
+}
+
 // splitSegment finalizes the current pending segment and opens a new one.
 // The typeState is updated in-place. Caller must hold ts.mu.
 func (db *DB) splitSegment(ts *typeState) error {
@@ -468,6 +517,12 @@ func (db *DB) Put(typeID TypeID, traceID TraceID, payload []byte) (Seq, error) {
 		return 0, fmt.Errorf("%w: %d bytes", ErrPayloadTooLarge, len(payload))
 	}
 
+	if db.opts.ValidateSchemas {
+		if err := db.validate(typeID, payload); err != nil {
+			return 0, err
+		}
+	}
+
 	ts, err := db.getTypeState(typeID)
 	if err != nil {
 		return 0, err
@@ -712,11 +767,15 @@ func (db *DB) DeleteType(typeID TypeID) error {
 		db.pool.Evict(seg.path)
 	}
 
-	// remove the entire type directory
+	// remove the entire type directory, including its acl and schema files
 	if err := os.RemoveAll(typeDir); err != nil {
 		return fmt.Errorf("msgstore: remove type dir: %w", err)
 	}
 
+	db.schemaMu.Lock()
+	delete(db.schemas, typeID)
+	db.schemaMu.Unlock()
+
 	return nil
 }
 
@@ -727,6 +786,9 @@ func (db *DB) DeleteType(typeID TypeID) error {
 //
 // This implements [ndb.Pruner].
 func (db *DB) DeleteSeq(typeID TypeID, seq Seq) error {
+	db.maintMu.Lock()
+	defer db.maintMu.Unlock()
+
 	seqID := uint64(seq)
 	typeDir := filepath.Join(db.dir, "events", string(typeID))
 
@@ -749,6 +811,8 @@ func (db *DB) DeleteSeq(typeID TypeID, seq Seq) error {
 			return err
 		}
 		if found {
+			// the size is unchanged, thus do not rely on the modification time alone
+			delete(db.segStats[typeDir], seg.path)
 			return nil
 		}
 	}
@@ -25,6 +25,7 @@ import (
 //	split    = <size> | count:<n> | day | a,b   → e.g. "64mib", "count:5000", "64mib,day"
 //	maxmsg   = <size>                           → MaxMessageSize, e.g. "16mib"
 //	filepool = <n>                              → NewFilePool(n)
+//	validate = true | false                     → ValidateSchemas
 //
 // Sizes accept the suffixes b, kb, kib, mb, mib, gb, gib (case-insensitive); a
 // bare number is bytes. Unknown keys and malformed values are reported as an
@@ -92,6 +93,13 @@ func parseDSN(dsn string) (Options, error) {
 			}
 			opts.FilePool = NewFilePool(n)
 
+		case "validate":
+			b, err := strconv.ParseBool(val)
+			if err != nil {
+				return opts, fmt.Errorf("validate: invalid bool %q", val)
+			}
+			opts.ValidateSchemas = b
+
 		default:
 			return opts, fmt.Errorf("unknown config key %q", key)
 		}
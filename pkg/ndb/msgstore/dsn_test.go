@@ -147,6 +147,16 @@ func TestParseDSNFilePool(t *testing.T) {
 	}
 }
 
+func TestParseDSNValidate(t *testing.T) {
+	opts, err := parseDSN("validate=true")
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if !opts.ValidateSchemas {
+		t.Fatal("expected ValidateSchemas to be set")
+	}
+}
+
 func TestParseDSNCombinedKeys(t *testing.T) {
 	opts, err := parseDSN("?compress=s2&split=count:5&maxmsg=8mib&filepool=128")
 	if err != nil {
@@ -171,6 +181,7 @@ func TestParseDSNErrors(t *testing.T) {
 		"filepool=0",         // non-positive handle count
 		"filepool=-1",        // negative
 		"filepool=abc",       // non-numeric
+		"validate=maybe",     // not a bool
 		"split=",             // empty split criterion
 	} {
 		if _, err := parseDSN(in); err == nil {
@@ -105,6 +105,7 @@ var (
 	ErrPayloadTooLarge   = errors.New("msgstore: payload exceeds maximum message size")
 	ErrInvalidSyncMarker = errors.New("msgstore: invalid sync marker")
 	ErrNotFound          = errors.New("msgstore: message not found")
+	ErrSchemaViolation   = errors.New("msgstore: payload violates schema")
 )
 
 // UnmarshalMessage decodes a single framed message starting at buf.
@@ -17,6 +17,17 @@ type Options struct {
 	// nil defaults to split at 64 MiB or on day boundary.
 	ShouldSplit SplitFunc
 
+	// Retention declares per event type how much history is kept. Expired
+	// finalized segments are dropped whenever a type splits and on
+	// [DB.ApplyRetention]. nil keeps the full history of every type.
+	Retention RetentionFunc
+
+	// ValidateSchemas checks each payload written by Append and Put against the
+	// latest registered schema of its type (see [ndb.RegisterSchema]) and
+	// rejects violations with [ErrSchemaViolation]. Every payload is decoded, so
+	// this is meant for debug builds and tests, not for production throughput.
+	ValidateSchemas bool
+
 	// FilePool manages open file handles with LRU eviction.
 	// nil defaults to NewFilePool(1024).
 	FilePool *FilePool
@@ -0,0 +1,290 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package msgstore
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// RetentionPolicy declares how much history of a single event type is kept.
+// Each limit is optional; a zero value disables it. When several limits are
+// set, a segment is dropped as soon as any of them allows it.
+//
+// Retention works at segment granularity on purpose: expired data is freed by
+// removing whole finalized segment files with a single unlink, so no message is
+// ever rewritten. A segment is only dropped if every message in it lies outside
+// the policy, which means a type may temporarily hold up to one segment more
+// than a limit strictly requires. Choose the [SplitFunc] accordingly, e.g.
+// [SplitByDay] for age-based retention. The pending segment is never dropped.
+type RetentionPolicy struct {
+	// MaxAge drops finalized segments whose newest message is older than
+	// MaxAge.
+	MaxAge time.Duration
+	// MaxCount drops the oldest finalized segments as long as the remaining
+	// segments still hold at least MaxCount live messages.
+	MaxCount uint64
+	// MaxBytes drops the oldest finalized segments as long as the remaining
+	// segments still occupy at least MaxBytes on disk.
+	MaxBytes int64
+	// Floor optionally protects history which has not been consumed yet, e.g.
+	// the committed cursor of a checkpoint consumer. A segment which holds a
+	// message with a sequence number above Floor is never dropped, even if a
+	// limit allows it. If Floor fails, no segment is dropped at all.
+	Floor func() (Seq, error)
+}
+
+// IsZero reports whether the policy has no limit at all, i.e. keeps everything.
+func (p RetentionPolicy) IsZero() bool {
+	return p.MaxAge <= 0 && p.MaxCount == 0 && p.MaxBytes <= 0
+}
+
+// RetentionFunc returns the retention policy for an event type. Returning
+// false keeps the full history of the type.
+type RetentionFunc func(typeID TypeID) (RetentionPolicy, bool)
+
+// RetentionPolicies returns a RetentionFunc backed by a static per-type table.
+// Types not contained in policies keep their full history.
+func RetentionPolicies(policies map[TypeID]RetentionPolicy) RetentionFunc {
+	return func(typeID TypeID) (RetentionPolicy, bool) {
+		p, ok := policies[typeID]
+		return p, ok
+	}
+}
+
+// RetainAll returns a RetentionFunc which applies the same policy to every
+// event type.
+func RetainAll(policy RetentionPolicy) RetentionFunc {
+	return func(TypeID) (RetentionPolicy, bool) {
+		return policy, true
+	}
+}
+
+// RetentionStats summarizes the effect of [DB.ApplyRetention].
+type RetentionStats struct {
+	// Segments is the number of finalized segment files which have been removed.
+	Segments int
+	// Bytes is the on-disk size of the removed segment files.
+	Bytes int64
+}
+
+func (s *RetentionStats) add(o RetentionStats) {
+	s.Segments += o.Segments
+	s.Bytes += o.Bytes
+}
+
+// ApplyRetention enforces the configured [Options.Retention] policies for the
+// given types (empty = all known types) by removing whole finalized segment
+// files. Types without a policy are left untouched.
+//
+// Retention is also applied automatically to a type whenever its pending
+// segment is finalized by a split, so calling this explicitly is only required
+// to enforce age limits on types which are not written anymore.
+//
+// Sequence numbers of dropped messages are never reissued. Time index entries
+// are intentionally kept, just as for [DB.DeleteType]. A concurrent Replay which
+// is positioned inside a dropped segment stops reading that segment early.
+func (db *DB) ApplyRetention(types []TypeID) (RetentionStats, error) {
+	var stats RetentionStats
+	if db.opts.Retention == nil {
+		return stats, nil
+	}
+
+	for _, td := range db.resolveTypeDirs(filepath.Join(db.dir, "events"), types) {
+		policy, ok := db.opts.Retention(td.id)
+		if !ok || policy.IsZero() {
+			continue
+		}
+
+		s, err := db.applyRetention(td.dir, policy, time.Now())
+		stats.add(s)
+		if err != nil {
+			return stats, fmt.Errorf("msgstore: retention for type %q: %w", td.id, err)
+		}
+	}
+
+	return stats, nil
+}
+
+// applyRetention drops the expired finalized segments of a single type
+// directory. Segments are evaluated oldest first and evaluation stops at the
+// first segment which must be kept, so that the remaining history is always
+// contiguous.
+func (db *DB) applyRetention(dir string, policy RetentionPolicy, now time.Time) (RetentionStats, error) {
+	db.maintMu.Lock()
+	defer db.maintMu.Unlock()
+
+	return db.applyRetentionLocked(dir, policy, now)
+}
+
+// applyRetentionLocked is [DB.applyRetention] for callers which already hold
+// maintMu.
+func (db *DB) applyRetentionLocked(dir string, policy RetentionPolicy, now time.Time) (RetentionStats, error) {
+	var stats RetentionStats
+
+	segments, err := listSegments(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return stats, nil
+		}
+		return stats, err
+	}
+
+	// only finalized segments are candidates, the pending one is always kept
+	var finalized []segmentFile
+	for _, seg := range segments {
+		if !seg.isPending() {
+			finalized = append(finalized, seg)
+		}
+	}
+
+	if len(finalized) == 0 {
+		return stats, nil
+	}
+
+	// the figures of unchanged segments are cached, thus only new or rewritten
+	// segments are scanned
+	cached := db.segStats[dir]
+	current := make(map[string]segmentStats, len(segments))
+	db.segStats[dir] = current
+
+	sizes := make([]int64, len(segments))
+	var totalBytes int64
+	for i, seg := range segments {
+		if fi, err := os.Stat(seg.path); err == nil {
+			sizes[i] = fi.Size()
+			totalBytes += sizes[i]
+		}
+	}
+
+	var counts []uint64
+	var totalCount uint64
+	if policy.MaxCount > 0 {
+		counts = make([]uint64, len(segments))
+		for i, seg := range segments {
+			counts[i] = db.statSegment(cached, current, seg.path).live
+			totalCount += counts[i]
+		}
+	}
+
+	floor := Seq(math.MaxUint64)
+	if policy.Floor != nil {
+		floor, err = policy.Floor()
+		if err != nil {
+			return stats, fmt.Errorf("retention floor: %w", err)
+		}
+	}
+
+	var cutoff int64
+	if policy.MaxAge > 0 {
+		cutoff = now.Add(-policy.MaxAge).UnixNano()
+	}
+
+	// segments is sorted by minSeq, and the pending segment is always the newest,
+	// so the finalized segments are exactly the prefix segments[:len(finalized)]
+	for i, seg := range finalized {
+		drop := false
+
+		if policy.MaxBytes > 0 && totalBytes-sizes[i] >= policy.MaxBytes {
+			drop = true
+		}
+
+		if policy.MaxCount > 0 && totalCount-counts[i] >= policy.MaxCount {
+			drop = true
+		}
+
+		if !drop && cutoff > 0 {
+			st := db.statSegment(cached, current, seg.path)
+			drop = st.found && st.newest < cutoff
+		}
+
+		if !drop {
+			break
+		}
+
+		if Seq(seg.maxSeq) > floor {
+			// the consumer is behind, e.g. after a long outage, and dropping would
+			// lose messages it has never seen
+			slog.Warn("msgstore: retention held back by unconsumed segment", "file", seg.path, "maxSeq", seg.maxSeq, "floor", floor)
+			break
+		}
+
+		db.pool.Evict(seg.path)
+		delete(current, seg.path)
+		if err := os.Remove(seg.path); err != nil {
+			return stats, fmt.Errorf("remove segment: %w", err)
+		}
+
+		slog.Info("msgstore: retention dropped segment", "file", seg.path, "bytes", sizes[i])
+		stats.Segments++
+		stats.Bytes += sizes[i]
+		totalBytes -= sizes[i]
+		if counts != nil {
+			totalCount -= counts[i]
+		}
+	}
+
+	return stats, nil
+}
+
+// segmentStats are the figures of a segment which retention evaluates. They
+// are only valid as long as the file keeps its size and modification time.
+type segmentStats struct {
+	size    int64
+	modTime time.Time
+	live    uint64 // number of non-tombstone messages
+	newest  int64  // largest append timestamp, including tombstones
+	found   bool   // false if the segment holds no readable frame
+}
+
+// statSegment returns the figures of the segment at path from cached, if the
+// file has not changed since, and scans it otherwise. The result is stored in
+// current. Caller must hold maintMu.
+func (db *DB) statSegment(cached, current map[string]segmentStats, path string) segmentStats {
+	if st, ok := current[path]; ok {
+		return st
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		return segmentStats{}
+	}
+
+	st, ok := cached[path]
+	if !ok || st.size != fi.Size() || !st.modTime.Equal(fi.ModTime()) {
+		st = scanSegmentStats(db.pool, path, db.opts.MaxMessageSize)
+		st.size = fi.Size()
+		st.modTime = fi.ModTime()
+	}
+
+	current[path] = st
+	return st
+}
+
+// scanSegmentStats reads all frames of a segment. Tombstones are included in
+// the newest timestamp, because they keep the timestamp of the deleted message.
+func scanSegmentStats(pool *FilePool, path string, maxMsgSize int64) segmentStats {
+	var st segmentStats
+	for msg, err := range readMessages(pool, path, maxMsgSize) {
+		if err != nil {
+			break
+		}
+		if !msg.IsTombstone() {
+			st.live++
+		}
+		if !st.found || msg.TimeNano > st.newest {
+			st.newest = msg.TimeNano
+			st.found = true
+		}
+	}
+	return st
+}
@@ -0,0 +1,190 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package msgstore
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"go.wdy.de/nago/pkg/ndb"
+)
+
+// rewriteTmpSuffix is appended to a segment path while its rewritten
+// replacement is written. Like a compaction file, it is ignored by
+// listSegments.
+const rewriteTmpSuffix = ".rewrite"
+
+var _ ndb.Rewriter = (*DB)(nil)
+
+// Rewrite replaces the payload of every message of typeID for which fn reports
+// a change. Seq, append time and trace id are kept, tombstones are copied as is
+// and new payloads are compressed like regular appends. Each affected segment,
+// including the pending one, is written to a temporary file and atomically
+// renamed over the original, so a crash leaves either the old or the new
+// segment. Schema validation is not applied to rewritten payloads.
+//
+// Appends to typeID are blocked for the duration of the rewrite. It is
+// serialized with [DB.DeleteSeq], [DB.Compact] and retention and shares their
+// caveat regarding concurrent replays.
+//
+// This implements [ndb.Rewriter].
+func (db *DB) Rewrite(typeID TypeID, fn ndb.RewriteFunc) (int, error) {
+	if !ndb.ValidTypeID(string(typeID)) {
+		return 0, fmt.Errorf("msgstore: invalid type id %q", string(typeID))
+	}
+
+	db.maintMu.Lock()
+	defer db.maintMu.Unlock()
+
+	if _, err := os.Stat(filepath.Join(db.dir, "events", string(typeID))); err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("msgstore: stat type dir: %w", err)
+	}
+
+	ts, err := db.getTypeState(typeID)
+	if err != nil {
+		return 0, err
+	}
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	segments, err := listSegments(ts.dir)
+	if err != nil {
+		return 0, fmt.Errorf("msgstore: list segments: %w", err)
+	}
+
+	var total int
+	for _, seg := range segments {
+		res, err := db.rewriteSegment(typeID, seg.path, fn)
+		total += res.changed
+		if err != nil {
+			return total, fmt.Errorf("msgstore: rewrite type %q: %w", typeID, err)
+		}
+
+		if res.changed > 0 && seg.isPending() {
+			ts.writeOffset = res.size
+			ts.seg.info.ByteSize = res.size
+			ts.lastMsgOffset = res.lastMsgOffset
+		}
+	}
+
+	if total > 0 {
+		slog.Info("msgstore: rewrite complete", "type", typeID, "messages", total)
+	}
+
+	return total, nil
+}
+
+type rewriteResult struct {
+	changed       int
+	size          int64
+	lastMsgOffset int64
+}
+
+// rewriteSegment writes the rewritten copy of the segment at path and replaces
+// the original, if at least one message has changed.
+func (db *DB) rewriteSegment(typeID TypeID, path string, fn ndb.RewriteFunc) (rewriteResult, error) {
+	var res rewriteResult
+
+	tmpPath := path + rewriteTmpSuffix
+	_ = os.Remove(tmpPath) // leftover of an interrupted rewrite
+
+	tmp, err := os.Create(tmpPath)
+	if err != nil {
+		return res, fmt.Errorf("create rewrite file: %w", err)
+	}
+
+	res, err = db.writeRewritten(tmp, typeID, path, fn)
+	if err != nil || res.changed == 0 {
+		discardReplacement(tmp)
+		return res, err
+	}
+
+	if err := replaceSegment(db.pool, tmp, path); err != nil {
+		return rewriteResult{}, err
+	}
+
+	return res, nil
+}
+
+func (db *DB) writeRewritten(dst *os.File, typeID TypeID, path string, fn ndb.RewriteFunc) (rewriteResult, error) {
+	var res rewriteResult
+
+	n, err := dst.Write(marshalSegHeader())
+	if err != nil {
+		return res, err
+	}
+
+	res.size = int64(n)
+	read := int64(segHeaderSize)
+	var buf []byte
+	for msg, err := range readMessages(db.pool, path, db.opts.MaxMessageSize) {
+		if err != nil {
+			return res, err
+		}
+
+		read += frameSize(&msg)
+
+		if !msg.IsTombstone() {
+			payload, err := ndb.Decompress(msg.Encoding, msg.Payload, msg.UncompressedLen)
+			if err != nil {
+				return res, fmt.Errorf("decompress seq %d: %w", msg.Seq, err)
+			}
+
+			next, changed, err := fn(Message{
+				Type:            typeID,
+				Seq:             msg.Seq,
+				TimeNano:        msg.TimeNano,
+				TraceID:         msg.TraceID,
+				Encoding:        ndb.EncodingRaw,
+				UncompressedLen: uint32(len(payload)),
+				Payload:         payload,
+			})
+			if err != nil {
+				return res, fmt.Errorf("rewrite seq %d: %w", msg.Seq, err)
+			}
+
+			if changed {
+				if int64(len(next)) > db.opts.MaxMessageSize {
+					return res, fmt.Errorf("rewrite seq %d: %w: %d bytes", msg.Seq, ErrPayloadTooLarge, len(next))
+				}
+
+				msg.Encoding, msg.Payload = db.opts.Compress(typeID, next)
+				msg.UncompressedLen = uint32(len(next))
+				res.changed++
+			}
+		}
+
+		buf = MarshalInto(&msg, buf)
+		n, err := dst.Write(buf)
+		if err != nil {
+			return res, err
+		}
+
+		res.lastMsgOffset = res.size
+		res.size += int64(n)
+	}
+
+	// like a compaction, the rewrite must not silently drop what readMessages
+	// has skipped or could not read
+	fi, err := os.Stat(path)
+	if err != nil {
+		return res, fmt.Errorf("stat segment: %w", err)
+	}
+
+	if read != fi.Size() {
+		return res, fmt.Errorf("segment %s is only readable up to %d of %d bytes, refusing to rewrite", path, read, fi.Size())
+	}
+
+	return res, nil
+}
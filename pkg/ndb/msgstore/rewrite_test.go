@@ -0,0 +1,130 @@
+package msgstore_test
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/worldiety/option"
+	"go.wdy.de/nago/pkg/ndb"
+	"go.wdy.de/nago/pkg/ndb/msgstore"
+)
+
+func TestRewritePreservesSeqAndTombstones(t *testing.T) {
+	dir := t.TempDir()
+	db := option.Must(msgstore.Open(dir, msgstore.Options{
+		Compress:    msgstore.AlwaysS2,
+		ShouldSplit: msgstore.SplitByCount(3),
+	}))
+
+	var traceID [16]byte
+	const typeID msgstore.TypeID = "1"
+
+	var seqs []msgstore.Seq
+	for i := range 8 {
+		seqs = append(seqs, option.Must(db.Append(typeID, traceID, []byte("old-"+strconv.Itoa(i)))))
+	}
+	option.MustZero(db.DeleteSeq(typeID, seqs[1]))
+
+	var seen int
+	changed := option.Must(db.Rewrite(typeID, func(msg ndb.Message) ([]byte, bool, error) {
+		seen++
+		if msg.Type != typeID || msg.Encoding != ndb.EncodingRaw {
+			t.Fatalf("unexpected message passed to rewrite: %+v", msg)
+		}
+		if msg.Seq == seqs[0] {
+			return nil, false, nil
+		}
+		return bytes.Replace(msg.Payload, []byte("old"), []byte("new"), 1), true, nil
+	}))
+
+	if seen != 7 {
+		t.Fatalf("expected 7 live messages to be visited, got %d", seen)
+	}
+	if changed != 6 {
+		t.Fatalf("expected 6 rewritten messages, got %d", changed)
+	}
+
+	// the pending segment stays appendable and Get sees the rewritten tail
+	last := option.Must(db.Get(typeID)).Unwrap()
+	if last.Seq != seqs[7] || string(option.Must(msgstore.Decompress(last.Encoding, last.Payload, last.UncompressedLen))) != "new-7" {
+		t.Fatalf("unexpected last message after rewrite: %d", last.Seq)
+	}
+	seqs = append(seqs, option.Must(db.Append(typeID, traceID, []byte("old-8"))))
+	option.MustZero(db.Close())
+
+	db = option.Must(msgstore.Open(dir, msgstore.Options{}))
+	defer func() { option.MustZero(db.Close()) }()
+
+	var got []string
+	var gotSeqs []msgstore.Seq
+	for _, msg := range db.Replay([]msgstore.TypeID{typeID}, 1, math.MaxUint64) {
+		got = append(got, string(option.Must(msgstore.Decompress(msg.Encoding, msg.Payload, msg.UncompressedLen))))
+		gotSeqs = append(gotSeqs, msg.Seq)
+	}
+
+	want := []string{"old-0", "new-2", "new-3", "new-4", "new-5", "new-6", "new-7", "old-8"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+
+	wantSeqs := append([]msgstore.Seq{seqs[0]}, seqs[2:]...)
+	for i := range wantSeqs {
+		if gotSeqs[i] != wantSeqs[i] {
+			t.Fatalf("expected seqs %v, got %v", wantSeqs, gotSeqs)
+		}
+	}
+
+	// rewriting an unknown type is a no-op
+	if n := option.Must(db.Rewrite("unknown", func(ndb.Message) ([]byte, bool, error) { return nil, true, nil })); n != 0 {
+		t.Fatalf("expected no rewrites for an unknown type, got %d", n)
+	}
+}
+
+func TestRewriteKeepsUnreadableSegment(t *testing.T) {
+	dir := t.TempDir()
+	db := option.Must(msgstore.Open(dir, msgstore.Options{
+		Compress:    msgstore.NoCompression,
+		ShouldSplit: msgstore.SplitByCount(3),
+	}))
+	defer func() { option.MustZero(db.Close()) }()
+
+	var traceID [16]byte
+	const typeID msgstore.TypeID = "1"
+
+	for i := range 4 {
+		option.Must(db.Append(typeID, traceID, []byte("old-"+strconv.Itoa(i))))
+	}
+
+	// corrupt a message of the finalized segment, so that it is skipped while reading
+	var path string
+	for _, e := range option.Must(os.ReadDir(filepath.Join(dir, "events", string(typeID)))) {
+		if !strings.HasSuffix(e.Name(), "_.bin") {
+			path = filepath.Join(dir, "events", string(typeID), e.Name())
+		}
+	}
+
+	buf := option.Must(os.ReadFile(path))
+	buf[bytes.Index(buf, []byte("old-1"))] ^= 0xff
+	option.MustZero(os.WriteFile(path, buf, 0600))
+
+	_, err := db.Rewrite(typeID, func(msg ndb.Message) ([]byte, bool, error) {
+		return bytes.Replace(msg.Payload, []byte("old"), []byte("new"), 1), true, nil
+	})
+	if err == nil {
+		t.Fatal("expected rewrite of an unreadable segment to fail")
+	}
+
+	if got := option.Must(os.ReadFile(path)); !bytes.Equal(got, buf) {
+		t.Fatal("expected the unreadable segment to be left untouched")
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package msgstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.wdy.de/nago/pkg/ndb"
+)
+
+// schemaFile holds the ascending list of schema versions inside an event type
+// directory. Like the acl file, it is never mistaken for a segment.
+const schemaFile = "schema.json"
+
+var _ ndb.Schemas = (*DB)(nil)
+
+// Schemas returns the registered schema versions of the type in ascending
+// order.
+//
+// This implements [ndb.Schemas].
+func (db *DB) Schemas(typeID TypeID) ([]ndb.SchemaVersion, error) {
+	if !ndb.ValidTypeID(string(typeID)) {
+		return nil, fmt.Errorf("msgstore: invalid type id %q", string(typeID))
+	}
+
+	db.schemaMu.Lock()
+	defer db.schemaMu.Unlock()
+
+	return db.readSchemas(typeID)
+}
+
+// AddSchema appends a new version to the schema.json of the type directory,
+// creating the directory if the type has no messages yet. The file is replaced
+// atomically. Use [ndb.RegisterSchema] to reject incompatible changes.
+//
+// This implements [ndb.Schemas].
+func (db *DB) AddSchema(typeID TypeID, schema *ndb.Schema) (ndb.SchemaVersion, error) {
+	if !ndb.ValidTypeID(string(typeID)) {
+		return ndb.SchemaVersion{}, fmt.Errorf("msgstore: invalid type id %q", string(typeID))
+	}
+
+	db.schemaMu.Lock()
+	defer db.schemaMu.Unlock()
+
+	versions, err := db.readSchemas(typeID)
+	if err != nil {
+		return ndb.SchemaVersion{}, err
+	}
+
+	v := ndb.SchemaVersion{
+		Version:   len(versions) + 1,
+		CreatedAt: time.Now().UnixMilli(),
+		Schema:    schema,
+	}
+	versions = append(versions, v)
+
+	buf, err := json.MarshalIndent(versions, "", "  ")
+	if err != nil {
+		return ndb.SchemaVersion{}, fmt.Errorf("msgstore: encode schema: %w", err)
+	}
+
+	path := db.schemaPath(typeID)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return ndb.SchemaVersion{}, fmt.Errorf("msgstore: create type dir: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf, 0644); err != nil {
+		return ndb.SchemaVersion{}, fmt.Errorf("msgstore: write schema: %w", err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return ndb.SchemaVersion{}, fmt.Errorf("msgstore: replace schema: %w", err)
+	}
+
+	db.schemas[typeID] = v.Schema
+	return v, nil
+}
+
+// readSchemas must be called while holding schemaMu.
+func (db *DB) readSchemas(typeID TypeID) ([]ndb.SchemaVersion, error) {
+	buf, err := os.ReadFile(db.schemaPath(typeID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("msgstore: read schema: %w", err)
+	}
+
+	var versions []ndb.SchemaVersion
+	if err := json.Unmarshal(buf, &versions); err != nil {
+		return nil, fmt.Errorf("msgstore: decode schema of type %q: %w", typeID, err)
+	}
+
+	return versions, nil
+}
+
+// validate checks the uncompressed payload against the latest registered
+// schema of the type. It is only called if [Options.ValidateSchemas] is set.
+// Types without a schema are accepted as is. The latest schema is cached, so
+// the schema file is read at most once per type.
+func (db *DB) validate(typeID TypeID, payload []byte) error {
+	db.schemaMu.Lock()
+	schema, ok := db.schemas[typeID]
+	if !ok {
+		versions, err := db.readSchemas(typeID)
+		if err != nil {
+			db.schemaMu.Unlock()
+			return err
+		}
+
+		if len(versions) > 0 {
+			schema = versions[len(versions)-1].Schema
+		}
+
+		db.schemas[typeID] = schema
+	}
+	db.schemaMu.Unlock()
+
+	if schema == nil {
+		return nil
+	}
+
+	if err := schema.Validate(payload); err != nil {
+		return fmt.Errorf("%w: type %q: %w", ErrSchemaViolation, typeID, err)
+	}
+
+	return nil
+}
+
+func (db *DB) schemaPath(typeID TypeID) string {
+	return filepath.Join(db.dir, "events", string(typeID), schemaFile)
+}
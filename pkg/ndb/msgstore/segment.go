@@ -460,9 +460,17 @@ func findMaxSeqInDir(pool *FilePool, dir string, maxMsgSize int64) uint64 {
 	if maxSeq == 0 && len(segments) > 1 {
 		secondLast := segments[len(segments)-2]
 		if !secondLast.isPending() {
-			return secondLast.maxSeq
+			maxSeq = secondLast.maxSeq
 		}
 	}
 
+	// The pending segment is always named after the successor of the last seq
+	// assigned to this type. This keeps the seq space anchored even if the
+	// preceding finalized segments have been removed by compaction or
+	// retention, so that dropped sequence numbers are never reissued.
+	if last.minSeq > 0 && last.minSeq-1 > maxSeq {
+		maxSeq = last.minSeq - 1
+	}
+
 	return maxSeq
 }
@@ -0,0 +1,264 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package ndb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// JSON schema types used by [Schema.Type]. An empty type accepts any value.
+const (
+	SchemaObject  = "object"
+	SchemaArray   = "array"
+	SchemaString  = "string"
+	SchemaNumber  = "number"
+	SchemaInteger = "integer"
+	SchemaBoolean = "boolean"
+)
+
+// Schema is the subset of JSON Schema which is required to describe the JSON
+// encoding of a Go type, as derived by [SchemaOf]. It is intentionally small:
+// the registry only has to answer whether two versions of an event type can
+// still read each other's payloads, and whether a payload roughly fits.
+//
+// An object with Properties describes a struct, an object with
+// AdditionalProperties describes a map. A nil or empty Schema accepts anything,
+// which is used for types with a custom JSON encoding. JSON null is always
+// accepted, just like encoding/json does.
+//
+// Revision is only meaningful on the root schema of an event type. It is
+// increased by the writer whenever stored payloads are migrated to a new shape
+// on read (e.g. by an upcaster), which allows [RegisterSchema] to accept an
+// otherwise breaking change.
+type Schema struct {
+	Revision             int                `json:"revision,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// Equal reports whether both schemas describe the same encoding.
+func (s *Schema) Equal(o *Schema) bool {
+	// the JSON encoding is canonical, because encoding/json sorts map keys
+	a, errA := json.Marshal(s)
+	b, errB := json.Marshal(o)
+	return errA == nil && errB == nil && bytes.Equal(a, b)
+}
+
+// SchemaVersion is a single, immutable registered version of the schema of an
+// event type. Versions start at 1 and increase by one with each incompatible-free
+// change.
+type SchemaVersion struct {
+	Version   int     `json:"version"`
+	CreatedAt int64   `json:"createdAt"` // unix milliseconds
+	Schema    *Schema `json:"schema"`
+}
+
+// Schemas is the optional capability to keep a versioned schema history per
+// event type alongside the messages. The engine only persists versions; the
+// compatibility policy lives in [RegisterSchema].
+type Schemas interface {
+	// Schemas returns all registered versions of typeID in ascending order, or
+	// an empty slice if the type has no schema yet.
+	Schemas(typeID TypeID) ([]SchemaVersion, error)
+
+	// AddSchema unconditionally appends a new version for typeID and returns it.
+	// Use [RegisterSchema] to only add compatible changes. Calling AddSchema
+	// directly is the escape hatch to accept a deliberate breaking change, e.g.
+	// after all stored payloads have been migrated.
+	AddSchema(typeID TypeID, schema *Schema) (SchemaVersion, error)
+}
+
+// IncompatibleSchemaError is returned by [RegisterSchema] if a new schema can
+// no longer decode payloads written with the latest registered version.
+type IncompatibleSchemaError struct {
+	Type    TypeID
+	Version int      // the latest registered version
+	Changes []string // human readable, one per incompatible path
+}
+
+func (e *IncompatibleSchemaError) Error() string {
+	return fmt.Sprintf("ndb: schema of type %q is incompatible with version %d: %s", e.Type, e.Version, strings.Join(e.Changes, "; "))
+}
+
+// RegisterSchema registers schema as the current schema of typeID. It is a
+// no-op if the latest version is equal. Otherwise, the change must be
+// compatible with the latest version (see [CompatibilityChanges]), or an
+// [*IncompatibleSchemaError] is returned and nothing is stored. Because only
+// compatible versions are ever appended this way, being compatible with the
+// latest version means being compatible with all of them.
+//
+// A schema with a higher [Schema.Revision] than the latest version is accepted
+// without a compatibility check, because the writer declares that older
+// payloads are migrated before they are decoded.
+func RegisterSchema(schemas Schemas, typeID TypeID, schema *Schema) (SchemaVersion, error) {
+	versions, err := schemas.Schemas(typeID)
+	if err != nil {
+		return SchemaVersion{}, err
+	}
+
+	if len(versions) > 0 {
+		latest := versions[len(versions)-1]
+		if latest.Schema.Equal(schema) {
+			return latest, nil
+		}
+
+		migrated := schema.Revision > latest.Schema.Revision
+		if changes := CompatibilityChanges(latest.Schema, schema); len(changes) > 0 && !migrated {
+			return latest, &IncompatibleSchemaError{Type: typeID, Version: latest.Version, Changes: changes}
+		}
+	}
+
+	return schemas.AddSchema(typeID, schema)
+}
+
+// CompatibilityChanges returns the changes which prevent a reader using schema
+// next from decoding payloads written with schema prev. An empty result means
+// the schemas are compatible. Adding properties is always compatible, as is
+// widening an integer to a number. Removing or renaming a property, or changing
+// its type, is not: encoding/json would silently drop the stored value or fail
+// on replay.
+func CompatibilityChanges(prev, next *Schema) []string {
+	var changes []string
+	compatibilityChanges("$", prev, next, &changes)
+	return changes
+}
+
+func compatibilityChanges(path string, prev, next *Schema, changes *[]string) {
+	switch {
+	case next.typ() == "":
+		// the reader accepts anything
+		return
+	case prev.typ() == "":
+		// the writer may have written anything
+		*changes = append(*changes, fmt.Sprintf("%s: changed type from any to %s", path, next.Type))
+		return
+	}
+
+	if prev.Type != next.Type {
+		if prev.Type == SchemaInteger && next.Type == SchemaNumber {
+			return
+		}
+
+		*changes = append(*changes, fmt.Sprintf("%s: changed type from %s to %s", path, prev.Type, next.Type))
+		return
+	}
+
+	switch prev.Type {
+	case SchemaArray:
+		compatibilityChanges(path+"[]", prev.Items, next.Items, changes)
+	case SchemaObject:
+		if prev.AdditionalProperties != nil {
+			compatibilityChanges(path+"{}", prev.AdditionalProperties, next.AdditionalProperties, changes)
+		}
+
+		for name, p := range prev.Properties {
+			n, ok := next.Properties[name]
+			if !ok {
+				if next.AdditionalProperties != nil {
+					compatibilityChanges(path+"."+name, p, next.AdditionalProperties, changes)
+					continue
+				}
+
+				*changes = append(*changes, fmt.Sprintf("%s.%s: removed or renamed", path, name))
+				continue
+			}
+
+			compatibilityChanges(path+"."+name, p, n, changes)
+		}
+	}
+}
+
+func (s *Schema) typ() string {
+	if s == nil {
+		return ""
+	}
+	return s.Type
+}
+
+// Validate checks whether payload is a JSON document matching the schema. For
+// objects with declared properties, unknown properties are rejected, because
+// they usually indicate a renamed field on the writer side.
+func (s *Schema) Validate(payload []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return fmt.Errorf("invalid json: %w", err)
+	}
+
+	return s.validate("$", v)
+}
+
+func (s *Schema) validate(path string, v any) error {
+	if s == nil || s.Type == "" || v == nil {
+		return nil
+	}
+
+	switch s.Type {
+	case SchemaObject:
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: expected object", path)
+		}
+
+		for name, value := range obj {
+			ps, ok := s.Properties[name]
+			if !ok {
+				ps = s.AdditionalProperties
+			}
+
+			if ps == nil {
+				if len(s.Properties) > 0 {
+					return fmt.Errorf("%s.%s: unknown property", path, name)
+				}
+				continue
+			}
+
+			if err := ps.validate(path+"."+name, value); err != nil {
+				return err
+			}
+		}
+	case SchemaArray:
+		arr, ok := v.([]any)
+		if !ok {
+			return fmt.Errorf("%s: expected array", path)
+		}
+
+		for i, value := range arr {
+			if err := s.Items.validate(fmt.Sprintf("%s[%d]", path, i), value); err != nil {
+				return err
+			}
+		}
+	case SchemaString:
+		if _, ok := v.(string); !ok {
+			return fmt.Errorf("%s: expected string", path)
+		}
+	case SchemaBoolean:
+		if _, ok := v.(bool); !ok {
+			return fmt.Errorf("%s: expected boolean", path)
+		}
+	case SchemaNumber:
+		if _, ok := v.(json.Number); !ok {
+			return fmt.Errorf("%s: expected number", path)
+		}
+	case SchemaInteger:
+		n, ok := v.(json.Number)
+		if !ok || strings.ContainsAny(string(n), ".eE") {
+			return fmt.Errorf("%s: expected integer", path)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package ndb
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	typeTime          = reflect.TypeFor[time.Time]()
+	typeJSONMarshaler = reflect.TypeFor[json.Marshaler]()
+	typeTextMarshaler = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// SchemaOf derives the [Schema] of the JSON encoding which encoding/json
+// produces for t. It follows the encoding/json rules for field names, the "-"
+// and ",string" tag options and embedded structs. Types with a custom
+// json.Marshaler cannot be inspected and accept anything, while
+// encoding.TextMarshaler implementations are strings. Recursive types are
+// described up to their first recursion.
+func SchemaOf(t reflect.Type) *Schema {
+	return schemaOf(t, map[reflect.Type]bool{})
+}
+
+func schemaOf(t reflect.Type, visiting map[reflect.Type]bool) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == typeTime:
+		return &Schema{Type: SchemaString, Format: "date-time"}
+	case t.Implements(typeJSONMarshaler) || reflect.PointerTo(t).Implements(typeJSONMarshaler):
+		return &Schema{}
+	case t.Implements(typeTextMarshaler) || reflect.PointerTo(t).Implements(typeTextMarshaler):
+		return &Schema{Type: SchemaString}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: SchemaString}
+	case reflect.Bool:
+		return &Schema{Type: SchemaBoolean}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: SchemaInteger}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: SchemaNumber}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: SchemaString, Format: "byte"} // base64
+		}
+		return &Schema{Type: SchemaArray, Items: schemaOf(t.Elem(), visiting)}
+	case reflect.Array:
+		return &Schema{Type: SchemaArray, Items: schemaOf(t.Elem(), visiting)}
+	case reflect.Map:
+		return &Schema{Type: SchemaObject, AdditionalProperties: schemaOf(t.Elem(), visiting)}
+	case reflect.Struct:
+		if visiting[t] {
+			return &Schema{Type: SchemaObject}
+		}
+
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		s := &Schema{Type: SchemaObject, Properties: map[string]*Schema{}}
+		addFields(s, t, visiting)
+		return s
+	default:
+		// interfaces and everything encoding/json cannot handle anyway
+		return &Schema{}
+	}
+}
+
+// addFields adds the properties of the struct t to s. Fields of embedded
+// structs without a json name are promoted, but never shadow a field declared
+// on a shallower level.
+func addFields(s *Schema, t reflect.Type, visiting map[reflect.Type]bool) {
+	var embedded []reflect.Type
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+
+			if ft.Kind() == reflect.Struct {
+				embedded = append(embedded, ft)
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		fs := schemaOf(field.Type, visiting)
+		if hasOption(opts, "string") && fs.Type != "" && fs.Type != SchemaObject && fs.Type != SchemaArray {
+			fs = &Schema{Type: SchemaString}
+		}
+
+		s.Properties[name] = fs
+	}
+
+	for _, et := range embedded {
+		promoted := &Schema{Properties: map[string]*Schema{}}
+		addFields(promoted, et, visiting)
+		for name, ps := range promoted.Properties {
+			if _, ok := s.Properties[name]; !ok {
+				s.Properties[name] = ps
+			}
+		}
+	}
+}
+
+func hasOption(opts, opt string) bool {
+	for o := range strings.SplitSeq(opts, ",") {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+package ndb_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/worldiety/option"
+	"go.wdy.de/nago/pkg/ndb"
+	"go.wdy.de/nago/pkg/ndb/msgstore"
+)
+
+type schemaBase struct {
+	ID      string    `json:"id"`
+	Created time.Time `json:"created"`
+}
+
+type schemaV1 struct {
+	schemaBase
+	Name   string            `json:"name"`
+	Amount int64             `json:"amount"`
+	Tags   []string          `json:"tags,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Secret string            `json:"-"`
+	hidden string
+}
+
+type schemaV2Added struct {
+	schemaBase
+	Name   string            `json:"name"`
+	Amount float64           `json:"amount"` // widened
+	Tags   []string          `json:"tags,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Note   string            `json:"note,omitempty"`
+}
+
+type schemaV2Renamed struct {
+	schemaBase
+	Title  string            `json:"title"`
+	Amount string            `json:"amount"`
+	Tags   []string          `json:"tags,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+func TestSchemaOf(t *testing.T) {
+	s := ndb.SchemaOf(reflect.TypeFor[schemaV1]())
+	if s.Type != ndb.SchemaObject {
+		t.Fatalf("expected object, got %q", s.Type)
+	}
+
+	want := map[string]string{
+		"id":      ndb.SchemaString,
+		"created": ndb.SchemaString,
+		"name":    ndb.SchemaString,
+		"amount":  ndb.SchemaInteger,
+		"tags":    ndb.SchemaArray,
+		"labels":  ndb.SchemaObject,
+	}
+	if len(s.Properties) != len(want) {
+		t.Fatalf("expected %d properties, got %v", len(want), s.Properties)
+	}
+	for name, typ := range want {
+		if p := s.Properties[name]; p == nil || p.Type != typ {
+			t.Fatalf("property %q: expected %s, got %+v", name, typ, p)
+		}
+	}
+}
+
+func TestSchemaCompatibility(t *testing.T) {
+	v1 := ndb.SchemaOf(reflect.TypeFor[schemaV1]())
+
+	if changes := ndb.CompatibilityChanges(v1, ndb.SchemaOf(reflect.TypeFor[schemaV2Added]())); len(changes) != 0 {
+		t.Fatalf("expected added field and widening to be compatible, got %v", changes)
+	}
+
+	changes := ndb.CompatibilityChanges(v1, ndb.SchemaOf(reflect.TypeFor[schemaV2Renamed]()))
+	if len(changes) != 2 {
+		t.Fatalf("expected rename and type change to be reported, got %v", changes)
+	}
+}
+
+func TestSchemaValidate(t *testing.T) {
+	s := ndb.SchemaOf(reflect.TypeFor[schemaV1]())
+
+	for _, ok := range []string{
+		`{"id":"1","name":"a","amount":3}`,
+		`{"id":"1","tags":["x"],"labels":{"k":"v"},"created":null}`,
+	} {
+		if err := s.Validate([]byte(ok)); err != nil {
+			t.Fatalf("expected %s to be valid: %v", ok, err)
+		}
+	}
+
+	for _, bad := range []string{
+		`{"id":1}`,
+		`{"amount":1.5}`,
+		`{"title":"renamed"}`,
+		`{"tags":[1]}`,
+		`[]`,
+		`{`,
+	} {
+		if err := s.Validate([]byte(bad)); err == nil {
+			t.Fatalf("expected %s to be rejected", bad)
+		}
+	}
+}
+
+func TestRegisterSchemaAndValidateOnAppend(t *testing.T) {
+	db := option.Must(ndb.Open(t.TempDir(), ndb.Options{}))
+	defer func() { option.MustZero(db.Close()) }()
+
+	eng := option.Must(db.Engine("events", ndb.EngineOptions{Kind: msgstore.EngineKind, Config: msgstore.Options{ValidateSchemas: true}}))
+	msgs := eng.(ndb.MessageEngine).Messages()
+	schemas := msgs.(ndb.Schemas)
+
+	const typeID ndb.TypeID = "Booked"
+	var tr ndb.TraceID
+
+	// without a schema, everything is accepted
+	option.Must(msgs.Append(typeID, tr, []byte(`{"title":"x"}`)))
+
+	v1 := option.Must(ndb.RegisterSchema(schemas, typeID, ndb.SchemaOf(reflect.TypeFor[schemaV1]())))
+	if v1.Version != 1 {
+		t.Fatalf("expected version 1, got %d", v1.Version)
+	}
+
+	// registering the same schema again is a no-op
+	if v := option.Must(ndb.RegisterSchema(schemas, typeID, ndb.SchemaOf(reflect.TypeFor[schemaV1]()))); v.Version != 1 {
+		t.Fatalf("expected idempotent registration, got version %d", v.Version)
+	}
+
+	option.Must(msgs.Append(typeID, tr, []byte(`{"id":"1","name":"a","amount":3}`)))
+	if _, err := msgs.Append(typeID, tr, []byte(`{"title":"x"}`)); !errors.Is(err, msgstore.ErrSchemaViolation) {
+		t.Fatalf("expected schema violation, got %v", err)
+	}
+
+	_, err := ndb.RegisterSchema(schemas, typeID, ndb.SchemaOf(reflect.TypeFor[schemaV2Renamed]()))
+	var incompatible *ndb.IncompatibleSchemaError
+	if !errors.As(err, &incompatible) || incompatible.Version != 1 {
+		t.Fatalf("expected incompatible schema error, got %v", err)
+	}
+
+	v2 := option.Must(ndb.RegisterSchema(schemas, typeID, ndb.SchemaOf(reflect.TypeFor[schemaV2Added]())))
+	if v2.Version != 2 {
+		t.Fatalf("expected version 2, got %d", v2.Version)
+	}
+
+	// the validation follows the latest version
+	option.Must(msgs.Append(typeID, tr, []byte(`{"id":"1","amount":1.5,"note":"n"}`)))
+
+	// a breaking change is accepted once the writer declares a new revision
+	renamed := ndb.SchemaOf(reflect.TypeFor[schemaV2Renamed]())
+	renamed.Revision = 1
+	if v3 := option.Must(ndb.RegisterSchema(schemas, typeID, renamed)); v3.Version != 3 {
+		t.Fatalf("expected version 3, got %d", v3.Version)
+	}
+
+	versions := option.Must(schemas.Schemas(typeID))
+	if len(versions) != 3 {
+		t.Fatalf("expected 3 persisted versions, got %d", len(versions))
+	}
+}
@@ -0,0 +1,257 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"go.wdy.de/nago/pkg/jwt"
+)
+
+var ErrInvalidIDToken = errors.New("oidc: invalid id token")
+
+// Config describes a registered client at an identity provider.
+type Config struct {
+	Issuer   string
+	ClientID string
+	// ClientSecret is empty for public clients, which only rely on PKCE.
+	ClientSecret string
+	RedirectURL  string
+	// Scopes are requested in addition to openid.
+	Scopes []string
+}
+
+// Client is a relying party for a single provider. It is safe for concurrent use.
+type Client struct {
+	cfg    Config
+	meta   Metadata
+	http   *http.Client
+	keys   *RemoteKeySet
+	now    func() time.Time
+	leeway time.Duration
+}
+
+// NewClient discovers the provider and returns a ready to use client.
+func NewClient(ctx context.Context, httpClient *http.Client, cfg Config) (*Client, error) {
+	if cfg.ClientID == "" {
+		return nil, fmt.Errorf("client id must not be empty")
+	}
+
+	meta, err := Discover(ctx, httpClient, cfg.Issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		cfg:    cfg,
+		meta:   meta,
+		http:   httpClient,
+		keys:   NewRemoteKeySet(httpClient, meta.JWKSURI),
+		now:    time.Now,
+		leeway: time.Minute,
+	}, nil
+}
+
+func (c *Client) Metadata() Metadata {
+	return c.meta
+}
+
+// AuthCodeURL returns the uri of the authorization endpoint, to which the user agent must be sent. State,
+// nonce and verifier must be fresh random values, e.g. from [RandomString], which are remembered by the
+// caller until the redirect returns.
+func (c *Client) AuthCodeURL(state, nonce, verifier string) string {
+	scopes := []string{"openid"}
+	for _, scope := range c.cfg.Scopes {
+		if scope != "" && !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {c.cfg.ClientID},
+		"redirect_uri":          {c.cfg.RedirectURL},
+		"scope":                 {strings.Join(scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {CodeChallenge(verifier)},
+		"code_challenge_method": {"S256"},
+	}
+
+	sep := "?"
+	if strings.Contains(c.meta.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+
+	return c.meta.AuthorizationEndpoint + sep + params.Encode()
+}
+
+// TokenResponse is the successful answer of the token endpoint.
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresIn    int    `json:"expires_in,omitempty"`
+	IDToken      string `json:"id_token"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+// Exchange redeems the authorization code at the token endpoint.
+func (c *Client) Exchange(ctx context.Context, code, verifier string) (TokenResponse, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {c.cfg.RedirectURL},
+		"code_verifier": {verifier},
+	}
+
+	secretPost := c.cfg.ClientSecret != "" &&
+		len(c.meta.TokenEndpointAuthMethodsSupported) > 0 &&
+		!slices.Contains(c.meta.TokenEndpointAuthMethodsSupported, "client_secret_basic") &&
+		slices.Contains(c.meta.TokenEndpointAuthMethodsSupported, "client_secret_post")
+
+	if c.cfg.ClientSecret == "" || secretPost {
+		form.Set("client_id", c.cfg.ClientID)
+	}
+
+	if secretPost {
+		form.Set("client_secret", c.cfg.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return TokenResponse{}, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if c.cfg.ClientSecret != "" && !secretPost {
+		// RFC 6749 section 2.3.1 requires the form encoding of both values
+		req.SetBasicAuth(url.QueryEscape(c.cfg.ClientID), url.QueryEscape(c.cfg.ClientSecret))
+	}
+
+	var res TokenResponse
+	if err := doJSON(c.http, req, &res); err != nil {
+		return TokenResponse{}, fmt.Errorf("cannot exchange code: %w", err)
+	}
+
+	if res.IDToken == "" {
+		return TokenResponse{}, fmt.Errorf("token response contains no id token, is the openid scope allowed?")
+	}
+
+	return res, nil
+}
+
+// IDToken is a validated id token.
+type IDToken struct {
+	Issuer   string
+	Subject  string
+	Audience []string
+	Expiry   time.Time
+	IssuedAt time.Time
+	Nonce    string
+	Claims   jwt.Claims
+}
+
+// VerifyIDToken checks the signature and the claims of the given id token according to OpenID Connect Core
+// 1.0 section 3.1.3.7. The nonce must be the one, which has been sent with [Client.AuthCodeURL].
+func (c *Client) VerifyIDToken(ctx context.Context, raw string, nonce string) (IDToken, error) {
+	tok, err := jwt.Verify(raw, func(hdr jwt.Header) (crypto.PublicKey, error) {
+		if algs := c.meta.IDTokenSigningAlgValuesSupported; len(algs) > 0 && !slices.Contains(algs, hdr.Alg) {
+			return nil, fmt.Errorf("%w: %q is not announced by the provider", jwt.ErrAlgorithm, hdr.Alg)
+		}
+
+		return c.keys.PublicKey(ctx, hdr)
+	})
+
+	if err != nil {
+		return IDToken{}, fmt.Errorf("%w: %w", ErrInvalidIDToken, err)
+	}
+
+	claims := tok.Claims
+	idt := IDToken{
+		Issuer:   claims.String("iss"),
+		Subject:  claims.String("sub"),
+		Audience: claims.Strings("aud"),
+		Nonce:    claims.String("nonce"),
+		Claims:   claims,
+	}
+
+	if idt.Issuer != c.meta.Issuer {
+		return IDToken{}, fmt.Errorf("%w: unexpected issuer %q", ErrInvalidIDToken, idt.Issuer)
+	}
+
+	if idt.Subject == "" {
+		return IDToken{}, fmt.Errorf("%w: missing subject", ErrInvalidIDToken)
+	}
+
+	if !slices.Contains(idt.Audience, c.cfg.ClientID) {
+		return IDToken{}, fmt.Errorf("%w: not issued for this client", ErrInvalidIDToken)
+	}
+
+	if azp := claims.String("azp"); (len(idt.Audience) > 1 || azp != "") && azp != c.cfg.ClientID {
+		return IDToken{}, fmt.Errorf("%w: unexpected authorized party %q", ErrInvalidIDToken, azp)
+	}
+
+	now := c.now()
+	exp, ok := claims.Time("exp")
+	if !ok || !now.Before(exp.Add(c.leeway)) {
+		return IDToken{}, fmt.Errorf("%w: expired", ErrInvalidIDToken)
+	}
+
+	idt.Expiry = exp
+
+	iat, ok := claims.Time("iat")
+	if !ok || iat.After(now.Add(c.leeway)) {
+		return IDToken{}, fmt.Errorf("%w: invalid issued at", ErrInvalidIDToken)
+	}
+
+	idt.IssuedAt = iat
+
+	if nonce == "" || idt.Nonce != nonce {
+		return IDToken{}, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+
+	return idt, nil
+}
+
+// UserInfo requests the userinfo endpoint, which some providers require to obtain claims like groups or
+// the mail address, because they are not put into the id token. The subject must equal the one of the id
+// token, otherwise the response is refused, see OpenID Connect Core 1.0 section 5.3.2.
+func (c *Client) UserInfo(ctx context.Context, accessToken string, subject string) (jwt.Claims, error) {
+	if c.meta.UserinfoEndpoint == "" {
+		return nil, fmt.Errorf("provider has no userinfo endpoint")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.meta.UserinfoEndpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
+
+	var claims jwt.Claims
+	if err := doJSON(c.http, req, &claims); err != nil {
+		return nil, fmt.Errorf("cannot get userinfo: %w", err)
+	}
+
+	if claims.String("sub") != subject {
+		return nil, fmt.Errorf("userinfo subject does not match id token")
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+// Package oidc implements an OpenID Connect relying party for the authorization code flow with PKCE.
+//
+// It covers the provider discovery, the cached retrieval of the signing keys, the code exchange and the
+// validation of id tokens as specified by OpenID Connect Core 1.0. Only the mandatory parts of the
+// specification are used, which are supported by common providers like Keycloak, Entra ID or Authentik.
+package oidc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// maxResponseSize limits what we read from an identity provider.
+const maxResponseSize = 1024 * 1024
+
+// Metadata is the subset of the provider configuration which is used by the relying party,
+// see OpenID Connect Discovery 1.0 section 3.
+type Metadata struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserinfoEndpoint                  string   `json:"userinfo_endpoint,omitempty"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	EndSessionEndpoint                string   `json:"end_session_endpoint,omitempty"`
+	ScopesSupported                   []string `json:"scopes_supported,omitempty"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported,omitempty"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported,omitempty"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported,omitempty"`
+}
+
+// Discover loads the provider configuration from the well-known location below the issuer. The issuer within
+// the document must match the requested one exactly, otherwise a compromised or misconfigured endpoint could
+// introduce a foreign issuer.
+func Discover(ctx context.Context, client *http.Client, issuer string) (Metadata, error) {
+	uri := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+
+	var meta Metadata
+	if err := getJSON(ctx, client, uri, &meta); err != nil {
+		return Metadata{}, fmt.Errorf("cannot discover %s: %w", issuer, err)
+	}
+
+	if meta.Issuer != issuer {
+		return Metadata{}, fmt.Errorf("issuer mismatch: expected %q but provider reports %q", issuer, meta.Issuer)
+	}
+
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" || meta.JWKSURI == "" {
+		return Metadata{}, fmt.Errorf("incomplete provider configuration of %s", issuer)
+	}
+
+	if len(meta.CodeChallengeMethodsSupported) > 0 && !slices.Contains(meta.CodeChallengeMethodsSupported, "S256") {
+		return Metadata{}, fmt.Errorf("provider %s does not support the S256 code challenge method", issuer)
+	}
+
+	return meta, nil
+}
+
+func getJSON(ctx context.Context, client *http.Client, uri string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	return doJSON(client, req, v)
+}
+
+func doJSON(client *http.Client, req *http.Request, v any) error {
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	buf, err := io.ReadAll(io.LimitReader(res.Body, maxResponseSize))
+	if err != nil {
+		return fmt.Errorf("cannot read response: %w", err)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		var oerr Error
+		if json.Unmarshal(buf, &oerr) == nil && oerr.Code != "" {
+			return oerr
+		}
+
+		return fmt.Errorf("unexpected status code %d from %s", res.StatusCode, req.URL.Redacted())
+	}
+
+	// keep numbers as json.Number, which is what jwt.Claims expects
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("cannot decode response from %s: %w", req.URL.Redacted(), err)
+	}
+
+	return nil
+}
+
+// Error is the error response of the token endpoint or of the authorization endpoint, see RFC 6749
+// section 5.2 and 4.1.2.1.
+type Error struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+func (e Error) Error() string {
+	if e.Description == "" {
+		return "oidc: " + e.Code
+	}
+
+	return "oidc: " + e.Code + ": " + e.Description
+}
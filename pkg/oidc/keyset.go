@@ -0,0 +1,86 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.wdy.de/nago/pkg/jwt"
+)
+
+// RemoteKeySet caches the signing keys published at the jwks_uri of a provider. Providers rotate their
+// keys by publishing the new one in advance, thus an unknown kid triggers a refetch, which is throttled
+// to protect the provider from being hammered by forged tokens.
+type RemoteKeySet struct {
+	uri    string
+	client *http.Client
+
+	// MaxAge defines how long the keys are used without asking the provider again.
+	MaxAge time.Duration
+	// MinRefresh defines how long at least to wait between two fetches caused by unknown keys.
+	MinRefresh time.Duration
+
+	mutex     sync.Mutex
+	keys      jwt.KeySet
+	fetchedAt time.Time
+	now       func() time.Time
+}
+
+func NewRemoteKeySet(client *http.Client, uri string) *RemoteKeySet {
+	return &RemoteKeySet{
+		uri:        uri,
+		client:     client,
+		MaxAge:     time.Hour,
+		MinRefresh: time.Minute,
+		now:        time.Now,
+	}
+}
+
+// PublicKey resolves the key for the given header and is suitable as [jwt.KeyFunc].
+func (s *RemoteKeySet) PublicKey(ctx context.Context, hdr jwt.Header) (crypto.PublicKey, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+	if s.fetchedAt.IsZero() || now.Sub(s.fetchedAt) > s.MaxAge {
+		if err := s.fetch(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	key, ok := s.keys.Find(hdr)
+	if !ok && now.Sub(s.fetchedAt) > s.MinRefresh {
+		if err := s.fetch(ctx); err != nil {
+			return nil, err
+		}
+
+		key, ok = s.keys.Find(hdr)
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("no signing key found for kid %q and alg %q", hdr.Kid, hdr.Alg)
+	}
+
+	return key.PublicKey()
+}
+
+func (s *RemoteKeySet) fetch(ctx context.Context) error {
+	var keys jwt.KeySet
+	if err := getJSON(ctx, s.client, s.uri, &keys); err != nil {
+		return fmt.Errorf("cannot fetch signing keys: %w", err)
+	}
+
+	s.keys = keys
+	s.fetchedAt = s.now()
+	return nil
+}
@@ -0,0 +1,332 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"go.wdy.de/nago/pkg/jwt"
+)
+
+// stubIdP is a minimal identity provider, which issues a single code per authorization request.
+type stubIdP struct {
+	srv     *httptest.Server
+	key     *rsa.PrivateKey
+	kid     string
+	mutex   sync.Mutex
+	codes   map[string]url.Values // code => authorization request
+	claims  map[string]any        // additional id token claims
+	fetches int
+}
+
+func newStubIdP(t *testing.T) *stubIdP {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idp := &stubIdP{key: key, kid: "k1", codes: map[string]url.Values{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(Metadata{
+			Issuer:                            idp.srv.URL,
+			AuthorizationEndpoint:             idp.srv.URL + "/auth",
+			TokenEndpoint:                     idp.srv.URL + "/token",
+			UserinfoEndpoint:                  idp.srv.URL + "/userinfo",
+			JWKSURI:                           idp.srv.URL + "/jwks",
+			CodeChallengeMethodsSupported:     []string{"S256"},
+			TokenEndpointAuthMethodsSupported: []string{"client_secret_basic"},
+			IDTokenSigningAlgValuesSupported:  []string{jwt.RS256},
+		})
+	})
+
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		idp.mutex.Lock()
+		defer idp.mutex.Unlock()
+		idp.fetches++
+
+		pub := idp.key.PublicKey
+		_ = json.NewEncoder(w).Encode(jwt.KeySet{Keys: []jwt.JWK{{
+			Kty: "RSA",
+			Kid: idp.kid,
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}}})
+	})
+
+	mux.HandleFunc("GET /auth", func(w http.ResponseWriter, r *http.Request) {
+		idp.mutex.Lock()
+		defer idp.mutex.Unlock()
+
+		code := RandomString()
+		idp.codes[code] = r.URL.Query()
+		http.Redirect(w, r, r.URL.Query().Get("redirect_uri")+"?"+url.Values{"code": {code}, "state": {r.URL.Query().Get("state")}}.Encode(), http.StatusFound)
+	})
+
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		idp.mutex.Lock()
+		defer idp.mutex.Unlock()
+
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != "nago" || secret != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(Error{Code: "invalid_client"})
+			return
+		}
+
+		authReq, ok := idp.codes[r.FormValue("code")]
+		delete(idp.codes, r.FormValue("code"))
+		if !ok || CodeChallenge(r.FormValue("code_verifier")) != authReq.Get("code_challenge") || r.FormValue("redirect_uri") != authReq.Get("redirect_uri") {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(Error{Code: "invalid_grant", Description: "code or verifier mismatch"})
+			return
+		}
+
+		claims := map[string]any{
+			"iss":   idp.srv.URL,
+			"sub":   "4711",
+			"aud":   authReq.Get("client_id"),
+			"exp":   time.Now().Add(5 * time.Minute).Unix(),
+			"iat":   time.Now().Unix(),
+			"nonce": authReq.Get("nonce"),
+		}
+
+		for k, v := range idp.claims {
+			claims[k] = v
+		}
+
+		_ = json.NewEncoder(w).Encode(TokenResponse{
+			AccessToken: "at-4711",
+			TokenType:   "Bearer",
+			IDToken:     idp.sign(claims),
+		})
+	})
+
+	mux.HandleFunc("GET /userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer at-4711" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"sub": "4711", "groups": []string{"staff"}})
+	})
+
+	idp.srv = httptest.NewServer(mux)
+	t.Cleanup(idp.srv.Close)
+
+	return idp
+}
+
+func (idp *stubIdP) sign(claims map[string]any) string {
+	hdr, _ := json.Marshal(jwt.Header{Alg: jwt.RS256, Kid: idp.kid, Typ: "JWT"})
+	body, _ := json.Marshal(claims)
+	input := base64.RawURLEncoding.EncodeToString(hdr) + "." + base64.RawURLEncoding.EncodeToString(body)
+	sum := sha256.Sum256([]byte(input))
+	sig, _ := rsa.SignPKCS1v15(rand.Reader, idp.key, crypto.SHA256, sum[:])
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+// authorize plays the user agent and returns the code from the redirect.
+func authorize(t *testing.T, uri string) (code, state string) {
+	t.Helper()
+
+	client := &http.Client{CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+
+	res, err := client.Get(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer res.Body.Close()
+
+	loc, err := url.Parse(res.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return loc.Query().Get("code"), loc.Query().Get("state")
+}
+
+func newTestClient(t *testing.T, idp *stubIdP) *Client {
+	t.Helper()
+
+	client, err := NewClient(context.Background(), idp.srv.Client(), Config{
+		Issuer:       idp.srv.URL,
+		ClientID:     "nago",
+		ClientSecret: "s3cret",
+		RedirectURL:  "https://app.example/account/oidc/callback",
+		Scopes:       []string{"profile", "email", "openid"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return client
+}
+
+func TestCodeFlow(t *testing.T) {
+	idp := newStubIdP(t)
+	idp.claims = map[string]any{"email": "alice@example.com", "email_verified": true}
+	client := newTestClient(t, idp)
+
+	state, nonce, verifier := RandomString(), RandomString(), RandomString()
+	uri := client.AuthCodeURL(state, nonce, verifier)
+
+	authReq, _ := url.Parse(uri)
+	if got := authReq.Query().Get("scope"); got != "openid profile email" {
+		t.Fatalf("unexpected scope: %q", got)
+	}
+
+	code, returnedState := authorize(t, uri)
+	if returnedState != state {
+		t.Fatalf("state not returned")
+	}
+
+	tokens, err := client.Exchange(context.Background(), code, verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idt, err := client.VerifyIDToken(context.Background(), tokens.IDToken, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if idt.Subject != "4711" || idt.Claims.String("email") != "alice@example.com" {
+		t.Fatalf("unexpected id token: %+v", idt)
+	}
+
+	info, err := client.UserInfo(context.Background(), tokens.AccessToken, idt.Subject)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if groups := info.Strings("groups"); len(groups) != 1 || groups[0] != "staff" {
+		t.Fatalf("unexpected groups: %v", groups)
+	}
+
+	// a code is redeemable only once
+	if _, err := client.Exchange(context.Background(), code, verifier); err == nil {
+		t.Fatal("expected code reuse to fail")
+	}
+}
+
+func TestExchangeRequiresVerifier(t *testing.T) {
+	idp := newStubIdP(t)
+	client := newTestClient(t, idp)
+
+	code, _ := authorize(t, client.AuthCodeURL(RandomString(), RandomString(), RandomString()))
+	_, err := client.Exchange(context.Background(), code, RandomString())
+
+	var oerr Error
+	if !errors.As(err, &oerr) || oerr.Code != "invalid_grant" {
+		t.Fatalf("expected invalid_grant, got %v", err)
+	}
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	idp := newStubIdP(t)
+	client := newTestClient(t, idp)
+
+	valid := func() map[string]any {
+		return map[string]any{
+			"iss":   idp.srv.URL,
+			"sub":   "4711",
+			"aud":   "nago",
+			"exp":   time.Now().Add(time.Minute).Unix(),
+			"iat":   time.Now().Unix(),
+			"nonce": "n-0",
+		}
+	}
+
+	if _, err := client.VerifyIDToken(context.Background(), idp.sign(valid()), "n-0"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]func(c map[string]any){
+		"issuer":   func(c map[string]any) { c["iss"] = "https://evil.example" },
+		"audience": func(c map[string]any) { c["aud"] = "other" },
+		"azp":      func(c map[string]any) { c["aud"] = []string{"nago", "other"} },
+		"expired":  func(c map[string]any) { c["exp"] = time.Now().Add(-time.Hour).Unix() },
+		"future":   func(c map[string]any) { c["iat"] = time.Now().Add(time.Hour).Unix() },
+		"nonce":    func(c map[string]any) { c["nonce"] = "n-1" },
+		"subject":  func(c map[string]any) { delete(c, "sub") },
+	}
+
+	for name, mutate := range tests {
+		t.Run(name, func(t *testing.T) {
+			claims := valid()
+			mutate(claims)
+			if _, err := client.VerifyIDToken(context.Background(), idp.sign(claims), "n-0"); !errors.Is(err, ErrInvalidIDToken) {
+				t.Fatalf("expected invalid id token, got %v", err)
+			}
+		})
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	idp := newStubIdP(t)
+	client := newTestClient(t, idp)
+
+	claims := map[string]any{
+		"iss":   idp.srv.URL,
+		"sub":   "4711",
+		"aud":   "nago",
+		"exp":   time.Now().Add(time.Minute).Unix(),
+		"iat":   time.Now().Unix(),
+		"nonce": "n",
+	}
+
+	if _, err := client.VerifyIDToken(context.Background(), idp.sign(claims), "n"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the provider rotates its key
+	newKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	idp.mutex.Lock()
+	idp.key = newKey
+	idp.kid = "k2"
+	idp.mutex.Unlock()
+
+	// an unknown kid within the throttle window must not cause a fetch
+	if _, err := client.VerifyIDToken(context.Background(), idp.sign(claims), "n"); err == nil {
+		t.Fatal("expected unknown key to fail")
+	}
+
+	if idp.fetches != 1 {
+		t.Fatalf("expected 1 fetch, got %d", idp.fetches)
+	}
+
+	client.keys.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	if _, err := client.VerifyIDToken(context.Background(), idp.sign(claims), "n"); err != nil {
+		t.Fatal(err)
+	}
+
+	if idp.fetches != 2 {
+		t.Fatalf("expected 2 fetches, got %d", idp.fetches)
+	}
+}
@@ -0,0 +1,28 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// RandomString returns 32 random bytes in the unpadded base64url alphabet. It is used for the state, the
+// nonce and the PKCE code verifier, which results in 43 characters as recommended by RFC 7636.
+func RandomString() string {
+	var buf [32]byte
+	_, _ = rand.Read(buf[:]) // never fails, see docs
+	return base64.RawURLEncoding.EncodeToString(buf[:])
+}
+
+// CodeChallenge derives the S256 code challenge from the given verifier, see RFC 7636 section 4.2.
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
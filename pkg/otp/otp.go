@@ -0,0 +1,167 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+// Package otp implements time-based one-time passwords (TOTP) as specified by RFC 6238 and RFC 4226, which
+// are supported by all common authenticator apps.
+package otp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Secret is the shared key between server and authenticator.
+type Secret []byte
+
+var b32 = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// NewSecret returns a random 160 bit secret, as recommended by RFC 4226.
+func NewSecret() (Secret, error) {
+	buf := make([]byte, 20)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("otp: cannot read random bytes: %w", err)
+	}
+
+	return buf, nil
+}
+
+// ParseSecret decodes the base32 representation, ignoring spaces and case.
+func ParseSecret(s string) (Secret, error) {
+	s = strings.ToUpper(strings.ReplaceAll(s, " ", ""))
+	s = strings.TrimRight(s, "=")
+	buf, err := b32.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("otp: invalid secret: %w", err)
+	}
+
+	return buf, nil
+}
+
+// String returns the unpadded base32 representation, which users can type into their authenticator.
+func (s Secret) String() string {
+	return b32.EncodeToString(s)
+}
+
+// TOTP describes the parameters of the generator. The zero value uses the defaults of all common
+// authenticator apps, which is 6 digits, 30 second periods and SHA1.
+type TOTP struct {
+	Digits int
+	Period time.Duration
+	// Skew is the amount of periods before and after the current one, which are still accepted to compensate
+	// clock drift and typing delays. Defaults to 1.
+	Skew int
+}
+
+func (t TOTP) digits() int {
+	if t.Digits <= 0 {
+		return 6
+	}
+
+	return t.Digits
+}
+
+func (t TOTP) period() time.Duration {
+	if t.Period <= 0 {
+		return 30 * time.Second
+	}
+
+	return t.Period
+}
+
+func (t TOTP) skew() int {
+	if t.Skew <= 0 {
+		return 1
+	}
+
+	return t.Skew
+}
+
+// Counter returns the moving factor for the given point in time.
+func (t TOTP) Counter(now time.Time) uint64 {
+	return uint64(now.Unix() / int64(t.period()/time.Second))
+}
+
+// Code returns the one-time password for the given point in time.
+func (t TOTP) Code(secret Secret, now time.Time) string {
+	return HOTP(secret, t.Counter(now), t.digits())
+}
+
+// Validate checks the code against the current period and its neighbours. On success, the matching counter is
+// returned, which must be stored by the caller to reject replays, see [TOTP.ValidateAfter].
+func (t TOTP) Validate(secret Secret, code string, now time.Time) (counter uint64, ok bool) {
+	return t.ValidateAfter(secret, code, now, 0)
+}
+
+// ValidateAfter is like [TOTP.Validate] but only accepts counters which are larger than lastCounter, so that a
+// code can be used only once.
+func (t TOTP) ValidateAfter(secret Secret, code string, now time.Time, lastCounter uint64) (counter uint64, ok bool) {
+	code = strings.ReplaceAll(code, " ", "")
+	if len(code) != t.digits() {
+		return 0, false
+	}
+
+	current := t.Counter(now)
+	for i := -t.skew(); i <= t.skew(); i++ {
+		c := uint64(int64(current) + int64(i))
+		if c <= lastCounter {
+			continue
+		}
+
+		if subtle.ConstantTimeCompare([]byte(HOTP(secret, c, t.digits())), []byte(code)) == 1 {
+			return c, true
+		}
+	}
+
+	return 0, false
+}
+
+// KeyURI returns the otpauth URI, which is usually presented as QR code to enroll an authenticator app.
+func (t TOTP) KeyURI(secret Secret, issuer, account string) string {
+	label := url.PathEscape(account)
+	if issuer != "" {
+		label = url.PathEscape(issuer) + ":" + label
+	}
+
+	q := url.Values{}
+	q.Set("secret", secret.String())
+	if issuer != "" {
+		q.Set("issuer", issuer)
+	}
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", fmt.Sprint(t.digits()))
+	q.Set("period", fmt.Sprint(int(t.period()/time.Second)))
+
+	return "otpauth://totp/" + label + "?" + q.Encode()
+}
+
+// HOTP calculates the HMAC-based one-time password as specified by RFC 4226.
+func HOTP(secret Secret, counter uint64, digits int) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for range digits {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", digits, value%mod)
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package otp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHOTP(t *testing.T) {
+	// test vectors of RFC 4226 appendix D
+	secret := Secret("12345678901234567890")
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+	for i, code := range want {
+		if got := HOTP(secret, uint64(i), 6); got != code {
+			t.Errorf("counter %d: expected %s, got %s", i, code, got)
+		}
+	}
+}
+
+func TestTOTP(t *testing.T) {
+	// test vectors of RFC 6238 appendix B for SHA1
+	secret := Secret("12345678901234567890")
+	gen := TOTP{Digits: 8}
+	tests := map[int64]string{
+		59:         "94287082",
+		1111111109: "07081804",
+		1234567890: "89005924",
+		2000000000: "69279037",
+	}
+
+	for unix, code := range tests {
+		if got := gen.Code(secret, time.Unix(unix, 0)); got != code {
+			t.Errorf("%d: expected %s, got %s", unix, code, got)
+		}
+	}
+
+	now := time.Unix(1234567890, 0)
+	counter, ok := gen.Validate(secret, "89005924", now.Add(25*time.Second))
+	if !ok {
+		t.Fatal("expected code of previous period to be accepted")
+	}
+
+	if _, ok := gen.ValidateAfter(secret, "89005924", now, counter); ok {
+		t.Fatal("expected replay to be rejected")
+	}
+
+	if _, ok := gen.Validate(secret, "89005924", now.Add(2*time.Minute)); ok {
+		t.Fatal("expected outdated code to be rejected")
+	}
+}
+
+func TestSecret(t *testing.T) {
+	secret, err := NewSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := ParseSecret(strings.ToLower(secret.String()))
+	if err != nil || parsed.String() != secret.String() {
+		t.Fatalf("round trip failed: %v", err)
+	}
+
+	uri := TOTP{}.KeyURI(secret, "ACME", "a@example.com")
+	if !strings.HasPrefix(uri, "otpauth://totp/ACME:a@example.com?") || !strings.Contains(uri, "secret="+secret.String()) {
+		t.Fatalf("unexpected uri: %s", uri)
+	}
+}
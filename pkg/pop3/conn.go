@@ -0,0 +1,278 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+// Package pop3 contains a minimal POP3 client (RFC 1939) with STLS support (RFC 2595), which is just enough to
+// download and delete all messages of a maildrop.
+package pop3
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Options configure a connection.
+type Options struct {
+	// TLSConfig is used for pop3s and StartTLS. If nil, the system roots are used and the server name is taken
+	// from the url.
+	TLSConfig *tls.Config
+	// StartTLS upgrades a plain pop3 connection using STLS before anything else is sent.
+	StartTLS bool
+	// Timeout limits each command. Defaults to 30 seconds.
+	Timeout time.Duration
+	// MaxMessageSize limits the size of a retrieved message. The remainder of a larger message is skipped
+	// without buffering it. Defaults to [DefaultMaxMessageSize].
+	MaxMessageSize int
+}
+
+// DefaultMaxMessageSize is used, if [Options.MaxMessageSize] is not set.
+const DefaultMaxMessageSize = 32 << 20
+
+// ErrMessageTooLarge is returned by [Conn.Retr], if the message exceeds [Options.MaxMessageSize]. The
+// connection can still be used.
+var ErrMessageTooLarge = errors.New("pop3 message too large")
+
+// Error is returned, if the server answers a command with -ERR.
+type Error struct {
+	Message string
+}
+
+func (e *Error) Error() string {
+	return "pop3 -ERR: " + e.Message
+}
+
+// Conn is a synchronous connection to a POP3 server. It is safe for concurrent use, but commands are
+// serialized. Deletions are only committed by [Conn.Quit].
+type Conn struct {
+	mutex   sync.Mutex
+	conn    net.Conn
+	text    *textproto.Conn
+	timeout time.Duration
+	maxSize int
+}
+
+// Dial connects to a pop3:// or pop3s:// url and reads the greeting. The default ports are 110 and 995.
+func Dial(ctx context.Context, rawURL string, opts Options) (*Conn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pop3 url: %w", err)
+	}
+
+	if opts.Timeout == 0 {
+		opts.Timeout = 30 * time.Second
+	}
+
+	if opts.MaxMessageSize <= 0 {
+		opts.MaxMessageSize = DefaultMaxMessageSize
+	}
+
+	tlsConfig := opts.TLSConfig
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	}
+
+	if tlsConfig.ServerName == "" {
+		tlsConfig = tlsConfig.Clone()
+		tlsConfig.ServerName = u.Hostname()
+	}
+
+	host := u.Host
+	var useTLS bool
+	switch u.Scheme {
+	case "pop3":
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "110")
+		}
+	case "pop3s":
+		useTLS = true
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "995")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported pop3 url scheme: %s", u.Scheme)
+	}
+
+	if useTLS && opts.StartTLS {
+		return nil, fmt.Errorf("StartTLS cannot be used with pop3s")
+	}
+
+	dialer := &net.Dialer{Timeout: opts.Timeout}
+	var conn net.Conn
+	if useTLS {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", host)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", host)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to %s: %w", host, err)
+	}
+
+	c := &Conn{conn: conn, text: textproto.NewConn(conn), timeout: opts.Timeout, maxSize: opts.MaxMessageSize}
+	_ = conn.SetDeadline(time.Now().Add(c.timeout))
+	if _, err := c.status(); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("cannot read greeting: %w", err)
+	}
+
+	if opts.StartTLS {
+		if err := c.startTLS(ctx, tlsConfig); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+func (c *Conn) startTLS(ctx context.Context, config *tls.Config) error {
+	if _, err := c.command("STLS", false); err != nil {
+		return fmt.Errorf("cannot start tls: %w", err)
+	}
+
+	tlsConn := tls.Client(c.conn, config)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return fmt.Errorf("cannot start tls: %w", err)
+	}
+
+	c.conn = tlsConn
+	c.text = textproto.NewConn(tlsConn)
+	return nil
+}
+
+// Auth authenticates with USER and PASS, which should only be used on a TLS connection.
+func (c *Conn) Auth(username, password string) error {
+	if strings.ContainsAny(username+password, "\r\n") {
+		return fmt.Errorf("credentials must not contain line breaks")
+	}
+
+	if _, err := c.command("USER "+username, false); err != nil {
+		return err
+	}
+
+	_, err := c.command("PASS "+password, false)
+	return err
+}
+
+// List returns the numbers of all messages in the maildrop.
+func (c *Conn) List() ([]int, error) {
+	buf, err := c.command("LIST", true)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []int
+	for _, line := range strings.Split(strings.TrimSpace(string(buf)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		n, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid scan listing: %s", line)
+		}
+
+		res = append(res, n)
+	}
+
+	return res, nil
+}
+
+// Retr returns the complete raw message. Line endings are normalized to LF.
+func (c *Conn) Retr(n int) ([]byte, error) {
+	return c.command("RETR "+strconv.Itoa(n), true)
+}
+
+// Dele marks the message as deleted. The deletion is applied by [Conn.Quit].
+func (c *Conn) Dele(n int) error {
+	_, err := c.command("DELE "+strconv.Itoa(n), false)
+	return err
+}
+
+// Quit commits all deletions and closes the connection.
+func (c *Conn) Quit() error {
+	_, err := c.command("QUIT", false)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if closeErr := c.conn.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+// Close closes the connection without committing any deletions.
+func (c *Conn) Close() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.conn.Close()
+}
+
+// command sends the command and reads the status line. If multiline is true, the dot-encoded data after a
+// positive status is returned.
+func (c *Conn) command(cmd string, multiline bool) ([]byte, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	_ = c.conn.SetDeadline(time.Now().Add(c.timeout))
+	if err := c.text.PrintfLine("%s", cmd); err != nil {
+		return nil, err
+	}
+
+	msg, err := c.status()
+	if err != nil || !multiline {
+		return []byte(msg), err
+	}
+
+	return c.readDotBytes()
+}
+
+// readDotBytes is like [textproto.Reader.ReadDotBytes] but limited to the maximum message size. The
+// remainder of a larger response is skipped, so that the next command can be issued.
+func (c *Conn) readDotBytes() ([]byte, error) {
+	r := c.text.DotReader()
+	buf, err := io.ReadAll(io.LimitReader(r, int64(c.maxSize)+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(buf) > c.maxSize {
+		if _, err := io.Copy(io.Discard, r); err != nil {
+			return nil, err
+		}
+
+		return nil, ErrMessageTooLarge
+	}
+
+	return buf, nil
+}
+
+func (c *Conn) status() (string, error) {
+	line, err := c.text.ReadLine()
+	if err != nil {
+		return "", err
+	}
+
+	if msg, ok := strings.CutPrefix(line, "+OK"); ok {
+		return strings.TrimSpace(msg), nil
+	}
+
+	return "", &Error{Message: strings.TrimSpace(strings.TrimPrefix(line, "-ERR"))}
+}
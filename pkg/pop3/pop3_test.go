@@ -0,0 +1,123 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package pop3_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"go.wdy.de/nago/pkg/pop3"
+	"go.wdy.de/nago/pkg/pop3/pop3test"
+)
+
+func TestConn(t *testing.T) {
+	srv, err := pop3test.NewServer("alice", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer srv.Close()
+
+	srv.Deliver([]byte("Subject: first\r\n\r\nhello\r\n"))
+	srv.Deliver([]byte("Subject: second\r\n\r\n.leading dot\r\n"))
+
+	conn, err := pop3.Dial(context.Background(), srv.URL(), pop3.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := conn.Auth("alice", "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	ids, err := conn.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(ids, []int{1, 2}) {
+		t.Fatalf("unexpected messages: %v", ids)
+	}
+
+	body, err := conn.Retr(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(body) != "Subject: second\n\n.leading dot\n" {
+		t.Fatalf("unexpected body: %q", body)
+	}
+
+	if err := conn.Dele(2); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := conn.Quit(); err != nil {
+		t.Fatal(err)
+	}
+
+	if srv.Len() != 1 {
+		t.Fatalf("expected one remaining message but got %d", srv.Len())
+	}
+}
+
+func TestAuthFailed(t *testing.T) {
+	srv, err := pop3test.NewServer("alice", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer srv.Close()
+
+	conn, err := pop3.Dial(context.Background(), srv.URL(), pop3.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer conn.Close()
+
+	var popErr *pop3.Error
+	if err := conn.Auth("alice", "wrong"); !errors.As(err, &popErr) {
+		t.Fatalf("expected -ERR but got %v", err)
+	}
+}
+
+func TestRetrTooLarge(t *testing.T) {
+	srv, err := pop3test.NewServer("alice", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer srv.Close()
+
+	srv.Deliver([]byte("Subject: large\r\n\r\n0123456789\r\n"))
+	srv.Deliver([]byte("Subject: small\r\n\r\n"))
+
+	conn, err := pop3.Dial(context.Background(), srv.URL(), pop3.Options{MaxMessageSize: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer conn.Close()
+
+	if err := conn.Auth("alice", "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := conn.Retr(1); !errors.Is(err, pop3.ErrMessageTooLarge) {
+		t.Fatalf("expected ErrMessageTooLarge but got %v", err)
+	}
+
+	// the connection must still be in sync
+	body, err := conn.Retr(2)
+	if err != nil || string(body) != "Subject: small\n\n" {
+		t.Fatalf("unexpected body: %q %v", body, err)
+	}
+}
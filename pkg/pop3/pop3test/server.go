@@ -0,0 +1,169 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+// Package pop3test provides an in-memory POP3 server to test mail clients against.
+package pop3test
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/textproto"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Server holds a single maildrop and understands just the commands used by [go.wdy.de/nago/pkg/pop3.Conn].
+type Server struct {
+	listener net.Listener
+	username string
+	password string
+	mutex    sync.Mutex
+	messages [][]byte
+	wg       sync.WaitGroup
+}
+
+// NewServer starts a server on a random local port, which accepts the given credentials. Use [Server.URL] to
+// connect.
+func NewServer(username, password string) (*Server, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{listener: l, username: username, password: password}
+	s.wg.Add(1)
+	go s.serve()
+
+	return s, nil
+}
+
+// URL returns the pop3:// url of the server.
+func (s *Server) URL() string {
+	return "pop3://" + s.listener.Addr().String()
+}
+
+// Deliver appends a new message.
+func (s *Server) Deliver(body []byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.messages = append(s.messages, body)
+}
+
+// Len returns the number of messages in the maildrop.
+func (s *Server) Len() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return len(s.messages)
+}
+
+// Close stops listening. Open connections are served until the clients disconnect.
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				slog.Error("pop3 test server cannot accept", "err", err.Error())
+			}
+
+			return
+		}
+
+		go s.handle(conn)
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+
+	text := textproto.NewConn(conn)
+	_ = text.PrintfLine("+OK nago test server ready")
+
+	var username string
+	authenticated := false
+	deleted := map[int]bool{}
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				slog.Error("pop3 test server cannot read", "err", err.Error())
+			}
+
+			return
+		}
+
+		cmd, arg, _ := strings.Cut(line, " ")
+		cmd = strings.ToUpper(cmd)
+		n, _ := strconv.Atoi(arg)
+
+		s.mutex.Lock()
+		valid := n > 0 && n <= len(s.messages) && !deleted[n]
+		s.mutex.Unlock()
+
+		switch {
+		case cmd == "QUIT":
+			s.mutex.Lock()
+			var keep [][]byte
+			for i, m := range s.messages {
+				if !deleted[i+1] {
+					keep = append(keep, m)
+				}
+			}
+			s.messages = keep
+			s.mutex.Unlock()
+			_ = text.PrintfLine("+OK bye")
+			return
+		case cmd == "USER":
+			username = arg
+			_ = text.PrintfLine("+OK")
+		case cmd == "PASS":
+			if username == s.username && arg == s.password {
+				authenticated = true
+				_ = text.PrintfLine("+OK logged in")
+			} else {
+				_ = text.PrintfLine("-ERR invalid credentials")
+			}
+		case !authenticated:
+			_ = text.PrintfLine("-ERR not authenticated")
+		case cmd == "LIST":
+			_ = text.PrintfLine("+OK scan listing follows")
+			w := text.DotWriter()
+			s.mutex.Lock()
+			for i, m := range s.messages {
+				if !deleted[i+1] {
+					_, _ = io.WriteString(w, strconv.Itoa(i+1)+" "+strconv.Itoa(len(m))+"\r\n")
+				}
+			}
+			s.mutex.Unlock()
+			_ = w.Close()
+		case cmd == "RETR" && valid:
+			_ = text.PrintfLine("+OK message follows")
+			w := text.DotWriter()
+			s.mutex.Lock()
+			_, _ = w.Write(slices.Clone(s.messages[n-1]))
+			s.mutex.Unlock()
+			_ = w.Close()
+		case cmd == "DELE" && valid:
+			deleted[n] = true
+			_ = text.PrintfLine("+OK message deleted")
+		default:
+			_ = text.PrintfLine("-ERR unsupported command")
+		}
+	}
+}
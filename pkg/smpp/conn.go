@@ -0,0 +1,463 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+// Package smpp contains a minimal SMPP 3.4 client, which binds as transceiver to submit short messages and to
+// receive mobile originated messages and delivery receipts over the same connection.
+package smpp
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.wdy.de/nago/pkg/smpp/internal/pdu"
+)
+
+// Options configure a connection.
+type Options struct {
+	// TLSConfig is used for smpps. If nil, the system roots are used and the server name is taken from the url.
+	TLSConfig *tls.Config
+	// Timeout limits each request. Defaults to 30 seconds.
+	Timeout time.Duration
+	// EnquireLink is the interval of the keep alive requests. Defaults to 30 seconds.
+	EnquireLink time.Duration
+	// OnDeliver is invoked for each mobile originated message or delivery receipt. It is called from the read
+	// loop, thus the next message is not read before it returns. The message is acknowledged afterwards.
+	OnDeliver func(Deliver)
+}
+
+// Error is returned, if the server answers a request with a non-zero command status.
+type Error struct {
+	Command string
+	Status  uint32
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("smpp %s failed with status 0x%08x", e.Command, e.Status)
+}
+
+// ShortMessage is submitted to the SMSC.
+type ShortMessage struct {
+	// Source is either a number or up to 11 alphanumeric characters. Empty lets the SMSC decide.
+	Source string
+	// Destination is the number in international format without leading plus.
+	Destination string
+	Text        string
+	// RegisteredDelivery requests a delivery receipt.
+	RegisteredDelivery bool
+}
+
+// Deliver is a mobile originated message or, if Receipt is not nil, a delivery receipt.
+type Deliver struct {
+	Source      string
+	Destination string
+	Text        string
+	Receipt     *Receipt
+}
+
+// Receipt is the delivery status of a previously submitted message.
+type Receipt struct {
+	// MessageID as returned by [Conn.Submit].
+	MessageID string
+	// Stat is the final or intermediate state like DELIVRD, UNDELIV, EXPIRED, REJECTD or ENROUTE.
+	Stat string
+	// Err is the network specific error code.
+	Err string
+}
+
+// Delivered returns true, if the message has reached the handset.
+func (r Receipt) Delivered() bool {
+	return r.Stat == "DELIVRD"
+}
+
+// Final returns true, if the state will not change anymore.
+func (r Receipt) Final() bool {
+	return r.Stat != "ENROUTE" && r.Stat != "ACCEPTD" && r.Stat != ""
+}
+
+// messageStates maps the message_state parameter to the stat values of the textual receipt.
+var messageStates = map[byte]string{
+	1: "ENROUTE",
+	2: "DELIVRD",
+	3: "EXPIRED",
+	4: "DELETED",
+	5: "UNDELIV",
+	6: "ACCEPTD",
+	7: "UNKNOWN",
+	8: "REJECTD",
+}
+
+// ParseReceipt reads the de-facto standard receipt text like
+// "id:123 sub:001 dlvrd:001 submit date:2601011200 done date:2601011201 stat:DELIVRD err:000 text:...".
+func ParseReceipt(text string) (Receipt, error) {
+	var r Receipt
+	fields := strings.Fields(text)
+	for _, field := range fields {
+		key, value, ok := strings.Cut(field, ":")
+		if !ok {
+			continue
+		}
+
+		switch strings.ToLower(key) {
+		case "id":
+			r.MessageID = value
+		case "stat":
+			r.Stat = strings.ToUpper(value)
+		case "err":
+			r.Err = value
+		}
+	}
+
+	if r.MessageID == "" || r.Stat == "" {
+		return r, fmt.Errorf("not a delivery receipt: %q", text)
+	}
+
+	return r, nil
+}
+
+// Conn is a bound transceiver session. It is safe for concurrent use and requests are pipelined.
+type Conn struct {
+	conn      net.Conn
+	timeout   time.Duration
+	onDeliver func(Deliver)
+	sequence  atomic.Uint32
+
+	wmutex sync.Mutex
+
+	mutex   sync.Mutex
+	pending map[uint32]chan pdu.PDU
+	err     error
+	done    chan struct{}
+}
+
+// Dial connects to a smpp:// or smpps:// url and starts the read loop. The default ports are the common 2775
+// and 3550. Use [Conn.BindTransceiver] before submitting anything.
+func Dial(ctx context.Context, rawURL string, opts Options) (*Conn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid smpp url: %w", err)
+	}
+
+	if opts.Timeout == 0 {
+		opts.Timeout = 30 * time.Second
+	}
+
+	if opts.EnquireLink == 0 {
+		opts.EnquireLink = 30 * time.Second
+	}
+
+	host := u.Host
+	var useTLS bool
+	switch u.Scheme {
+	case "smpp":
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "2775")
+		}
+	case "smpps":
+		useTLS = true
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "3550")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported smpp url scheme: %s", u.Scheme)
+	}
+
+	dialer := &net.Dialer{Timeout: opts.Timeout}
+	var conn net.Conn
+	if useTLS {
+		tlsConfig := opts.TLSConfig
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{}
+		}
+
+		if tlsConfig.ServerName == "" {
+			tlsConfig = tlsConfig.Clone()
+			tlsConfig.ServerName = u.Hostname()
+		}
+
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", host)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", host)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to %s: %w", host, err)
+	}
+
+	c := &Conn{
+		conn:      conn,
+		timeout:   opts.Timeout,
+		onDeliver: opts.OnDeliver,
+		pending:   map[uint32]chan pdu.PDU{},
+		done:      make(chan struct{}),
+	}
+
+	go c.readLoop()
+	go c.keepAlive(opts.EnquireLink)
+
+	return c, nil
+}
+
+// BindTransceiver authenticates the session for sending and receiving.
+func (c *Conn) BindTransceiver(systemID, password, systemType string) error {
+	var w pdu.Writer
+	w.CString(systemID)
+	w.CString(password)
+	w.CString(systemType)
+	w.U8(0x34) // interface_version 3.4
+	w.U8(0)    // addr_ton
+	w.U8(0)    // addr_npi
+	w.CString("")
+
+	_, err := c.request(pdu.CmdBindTransceiver, w.Bytes())
+	return err
+}
+
+// Submit sends the message and returns the id assigned by the SMSC, which is referred to by a [Receipt].
+func (c *Conn) Submit(sm ShortMessage) (string, error) {
+	coding, text := pdu.EncodeText(sm.Text)
+	msg := pdu.ShortMessage{
+		DestTON:     1, // international
+		DestNPI:     1, // E.164
+		Destination: strings.TrimPrefix(sm.Destination, "+"),
+		DataCoding:  coding,
+		Message:     text,
+	}
+
+	if sm.Source != "" {
+		msg.Source = strings.TrimPrefix(sm.Source, "+")
+		if isNumber(msg.Source) {
+			msg.SourceTON, msg.SourceNPI = 1, 1
+		} else {
+			msg.SourceTON = 5 // alphanumeric
+		}
+	}
+
+	if sm.RegisteredDelivery {
+		msg.RegisteredDelivery = 1
+	}
+
+	res, err := c.request(pdu.CmdSubmitSM, msg.Encode())
+	if err != nil {
+		return "", err
+	}
+
+	r := &pdu.Reader{Buf: res.Body}
+	id := r.CString()
+	return id, r.Err
+}
+
+// Unbind ends the session gracefully. The connection must be closed afterwards.
+func (c *Conn) Unbind() error {
+	_, err := c.request(pdu.CmdUnbind, nil)
+	return err
+}
+
+// Done is closed, when the connection has been lost or closed. See [Conn.Err].
+func (c *Conn) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns the reason, why the connection is done.
+func (c *Conn) Err() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.err
+}
+
+// Close closes the connection without unbinding.
+func (c *Conn) Close() error {
+	err := c.conn.Close()
+	c.fail(net.ErrClosed)
+	return err
+}
+
+func (c *Conn) request(command uint32, body []byte) (pdu.PDU, error) {
+	seq := c.sequence.Add(1)
+	ch := make(chan pdu.PDU, 1)
+
+	c.mutex.Lock()
+	if c.err != nil {
+		err := c.err
+		c.mutex.Unlock()
+		return pdu.PDU{}, err
+	}
+
+	c.pending[seq] = ch
+	c.mutex.Unlock()
+
+	defer func() {
+		c.mutex.Lock()
+		delete(c.pending, seq)
+		c.mutex.Unlock()
+	}()
+
+	if err := c.write(pdu.PDU{Command: command, Sequence: seq, Body: body}); err != nil {
+		return pdu.PDU{}, err
+	}
+
+	select {
+	case res := <-ch:
+		if res.Command == pdu.CmdGenericNack {
+			return res, &Error{Command: pdu.CommandName(command), Status: res.Status}
+		}
+
+		if res.Status != 0 {
+			return res, &Error{Command: pdu.CommandName(command), Status: res.Status}
+		}
+
+		return res, nil
+	case <-c.done:
+		return pdu.PDU{}, c.Err()
+	case <-time.After(c.timeout):
+		return pdu.PDU{}, fmt.Errorf("smpp %s timed out", pdu.CommandName(command))
+	}
+}
+
+func (c *Conn) write(p pdu.PDU) error {
+	c.wmutex.Lock()
+	defer c.wmutex.Unlock()
+
+	_ = c.conn.SetWriteDeadline(time.Now().Add(c.timeout))
+	if _, err := c.conn.Write(p.Bytes()); err != nil {
+		return fmt.Errorf("cannot write smpp %s: %w", pdu.CommandName(p.Command), err)
+	}
+
+	return nil
+}
+
+func (c *Conn) fail(err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.err != nil {
+		return
+	}
+
+	c.err = err
+	close(c.done)
+}
+
+func (c *Conn) readLoop() {
+	for {
+		p, err := pdu.Read(c.conn)
+		if err != nil {
+			_ = c.conn.Close()
+			c.fail(fmt.Errorf("smpp connection lost: %w", err))
+			return
+		}
+
+		switch {
+		case p.Command&0x80000000 != 0:
+			c.mutex.Lock()
+			ch := c.pending[p.Sequence]
+			c.mutex.Unlock()
+
+			if ch != nil {
+				ch <- p
+			}
+		case p.Command == pdu.CmdEnquireLink:
+			_ = c.write(pdu.PDU{Command: pdu.CmdEnquireLinkResp, Sequence: p.Sequence})
+		case p.Command == pdu.CmdUnbind:
+			_ = c.write(pdu.PDU{Command: pdu.CmdUnbindResp, Sequence: p.Sequence})
+			_ = c.conn.Close()
+			c.fail(errors.New("smpp session unbound by server"))
+			return
+		case p.Command == pdu.CmdDeliverSM:
+			status := uint32(0)
+			if err := c.deliver(p); err != nil {
+				slog.Error("cannot process smpp deliver_sm", "err", err.Error())
+				status = pdu.StatusSysErr
+			}
+
+			// the message_id of a deliver_sm_resp is unused and must be empty
+			_ = c.write(pdu.PDU{Command: pdu.CmdDeliverSMResp, Status: status, Sequence: p.Sequence, Body: []byte{0}})
+		default:
+			_ = c.write(pdu.PDU{Command: pdu.CmdGenericNack, Status: 0x00000003, Sequence: p.Sequence}) // invalid command id
+		}
+	}
+}
+
+func (c *Conn) deliver(p pdu.PDU) error {
+	msg, err := pdu.DecodeShortMessage(p.Body)
+	if err != nil {
+		return err
+	}
+
+	d := Deliver{
+		Source:      msg.Source,
+		Destination: msg.Destination,
+		Text:        pdu.DecodeText(msg.DataCoding, msg.Message),
+	}
+
+	if msg.EsmClass&0x3C == pdu.EsmClassReceipt {
+		receipt, err := ParseReceipt(d.Text)
+
+		// the optional parameters are more reliable than the text, if available
+		if id, ok := msg.TLVs[pdu.TagReceiptedMessageID]; ok {
+			receipt.MessageID = strings.TrimRight(string(id), "\x00")
+			err = nil
+		}
+
+		if state, ok := msg.TLVs[pdu.TagMessageState]; ok && len(state) == 1 {
+			receipt.Stat = messageStates[state[0]]
+		}
+
+		if err != nil {
+			return err
+		}
+
+		d.Receipt = &receipt
+	}
+
+	if c.onDeliver != nil {
+		c.onDeliver(d)
+	}
+
+	return nil
+}
+
+func (c *Conn) keepAlive(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			if _, err := c.request(pdu.CmdEnquireLink, nil); err != nil {
+				_ = c.conn.Close()
+				c.fail(fmt.Errorf("smpp keep alive failed: %w", err))
+				return
+			}
+		}
+	}
+}
+
+func isNumber(s string) bool {
+	if s == "" || len(s) > 15 {
+		return false
+	}
+
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,327 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+// Package pdu contains the encoding of the SMPP 3.4 protocol data units, which is shared by the client in
+// package smpp and the server in package smpptest.
+package pdu
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+)
+
+const (
+	CmdGenericNack         uint32 = 0x80000000
+	CmdBindTransceiver     uint32 = 0x00000009
+	CmdBindTransceiverResp uint32 = 0x80000009
+	CmdSubmitSM            uint32 = 0x00000004
+	CmdSubmitSMResp        uint32 = 0x80000004
+	CmdDeliverSM           uint32 = 0x00000005
+	CmdDeliverSMResp       uint32 = 0x80000005
+	CmdUnbind              uint32 = 0x00000006
+	CmdUnbindResp          uint32 = 0x80000006
+	CmdEnquireLink         uint32 = 0x00000015
+	CmdEnquireLinkResp     uint32 = 0x80000015
+)
+
+const (
+	TagReceiptedMessageID uint16 = 0x001E
+	TagMessagePayload     uint16 = 0x0424
+	TagMessageState       uint16 = 0x0427
+)
+
+const (
+	codingDefault uint8 = 0x00
+	codingLatin1  uint8 = 0x03
+	codingUCS2    uint8 = 0x08
+)
+
+// EsmClassReceipt marks a deliver_sm as delivery receipt instead of a mobile originated message.
+const EsmClassReceipt uint8 = 0x04
+
+// maxPDU protects against garbage or hostile peers. Real PDUs are far below 64KiB.
+const maxPDU = 64 * 1024
+
+const StatusSysErr uint32 = 0x00000008
+
+func CommandName(id uint32) string {
+	switch id {
+	case CmdBindTransceiver, CmdBindTransceiverResp:
+		return "bind_transceiver"
+	case CmdSubmitSM, CmdSubmitSMResp:
+		return "submit_sm"
+	case CmdDeliverSM, CmdDeliverSMResp:
+		return "deliver_sm"
+	case CmdUnbind, CmdUnbindResp:
+		return "unbind"
+	case CmdEnquireLink, CmdEnquireLinkResp:
+		return "enquire_link"
+	case CmdGenericNack:
+		return "generic_nack"
+	default:
+		return fmt.Sprintf("0x%08x", id)
+	}
+}
+
+type PDU struct {
+	Command  uint32
+	Status   uint32
+	Sequence uint32
+	Body     []byte
+}
+
+func Read(r io.Reader) (PDU, error) {
+	var hdr [16]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return PDU{}, err
+	}
+
+	length := binary.BigEndian.Uint32(hdr[0:4])
+	if length < 16 || length > maxPDU {
+		return PDU{}, fmt.Errorf("invalid pdu length: %d", length)
+	}
+
+	p := PDU{
+		Command:  binary.BigEndian.Uint32(hdr[4:8]),
+		Status:   binary.BigEndian.Uint32(hdr[8:12]),
+		Sequence: binary.BigEndian.Uint32(hdr[12:16]),
+		Body:     make([]byte, length-16),
+	}
+
+	if _, err := io.ReadFull(r, p.Body); err != nil {
+		return PDU{}, err
+	}
+
+	return p, nil
+}
+
+func (p PDU) Bytes() []byte {
+	buf := make([]byte, 16, 16+len(p.Body))
+	binary.BigEndian.PutUint32(buf[0:4], uint32(16+len(p.Body)))
+	binary.BigEndian.PutUint32(buf[4:8], p.Command)
+	binary.BigEndian.PutUint32(buf[8:12], p.Status)
+	binary.BigEndian.PutUint32(buf[12:16], p.Sequence)
+	return append(buf, p.Body...)
+}
+
+// Writer appends the primitive SMPP types.
+type Writer struct {
+	bytes.Buffer
+}
+
+func (w *Writer) CString(s string) {
+	w.WriteString(s)
+	w.WriteByte(0)
+}
+
+func (w *Writer) U8(v uint8) {
+	w.WriteByte(v)
+}
+
+func (w *Writer) TLV(tag uint16, value []byte) {
+	var hdr [4]byte
+	binary.BigEndian.PutUint16(hdr[0:2], tag)
+	binary.BigEndian.PutUint16(hdr[2:4], uint16(len(value)))
+	w.Write(hdr[:])
+	w.Write(value)
+}
+
+// Reader consumes the primitive SMPP types and remembers the first error.
+type Reader struct {
+	Buf []byte
+	Err error
+}
+
+var ErrShortPDU = errors.New("pdu body too short")
+
+func (r *Reader) CString() string {
+	if r.Err != nil {
+		return ""
+	}
+
+	idx := bytes.IndexByte(r.Buf, 0)
+	if idx < 0 {
+		r.Err = ErrShortPDU
+		return ""
+	}
+
+	s := string(r.Buf[:idx])
+	r.Buf = r.Buf[idx+1:]
+	return s
+}
+
+func (r *Reader) U8() uint8 {
+	if r.Err != nil {
+		return 0
+	}
+
+	if len(r.Buf) < 1 {
+		r.Err = ErrShortPDU
+		return 0
+	}
+
+	v := r.Buf[0]
+	r.Buf = r.Buf[1:]
+	return v
+}
+
+func (r *Reader) Octets(n int) []byte {
+	if r.Err != nil {
+		return nil
+	}
+
+	if len(r.Buf) < n {
+		r.Err = ErrShortPDU
+		return nil
+	}
+
+	v := r.Buf[:n]
+	r.Buf = r.Buf[n:]
+	return v
+}
+
+// tlvs consumes the remaining optional parameters.
+func (r *Reader) TLVs() map[uint16][]byte {
+	res := map[uint16][]byte{}
+	for r.Err == nil && len(r.Buf) >= 4 {
+		tag := binary.BigEndian.Uint16(r.Buf[0:2])
+		length := int(binary.BigEndian.Uint16(r.Buf[2:4]))
+		r.Buf = r.Buf[4:]
+		res[tag] = r.Octets(length)
+	}
+
+	return res
+}
+
+// ShortMessage is the common body of submit_sm and deliver_sm.
+type ShortMessage struct {
+	SourceTON          uint8
+	SourceNPI          uint8
+	Source             string
+	DestTON            uint8
+	DestNPI            uint8
+	Destination        string
+	EsmClass           uint8
+	RegisteredDelivery uint8
+	DataCoding         uint8
+	Message            []byte
+	TLVs               map[uint16][]byte
+}
+
+func (m ShortMessage) Encode() []byte {
+	var w Writer
+	w.CString("") // service_type
+	w.U8(m.SourceTON)
+	w.U8(m.SourceNPI)
+	w.CString(m.Source)
+	w.U8(m.DestTON)
+	w.U8(m.DestNPI)
+	w.CString(m.Destination)
+	w.U8(m.EsmClass)
+	w.U8(0)       // protocol_id
+	w.U8(0)       // priority_flag
+	w.CString("") // schedule_delivery_time
+	w.CString("") // validity_period
+	w.U8(m.RegisteredDelivery)
+	w.U8(0) // replace_if_present_flag
+	w.U8(m.DataCoding)
+	w.U8(0) // sm_default_msg_id
+
+	// the short_message field is limited to 254 octets, anything longer must use the payload parameter
+	if len(m.Message) > 254 {
+		w.U8(0)
+		w.TLV(TagMessagePayload, m.Message)
+	} else {
+		w.U8(uint8(len(m.Message)))
+		w.Write(m.Message)
+	}
+
+	for tag, value := range m.TLVs {
+		w.TLV(tag, value)
+	}
+
+	return w.Bytes()
+}
+
+func DecodeShortMessage(body []byte) (ShortMessage, error) {
+	r := &Reader{Buf: body}
+	var m ShortMessage
+	r.CString() // service_type
+	m.SourceTON = r.U8()
+	m.SourceNPI = r.U8()
+	m.Source = r.CString()
+	m.DestTON = r.U8()
+	m.DestNPI = r.U8()
+	m.Destination = r.CString()
+	m.EsmClass = r.U8()
+	r.U8()      // protocol_id
+	r.U8()      // priority_flag
+	r.CString() // schedule_delivery_time
+	r.CString() // validity_period
+	m.RegisteredDelivery = r.U8()
+	r.U8() // replace_if_present_flag
+	m.DataCoding = r.U8()
+	r.U8() // sm_default_msg_id
+	m.Message = r.Octets(int(r.U8()))
+	m.TLVs = r.TLVs()
+
+	if payload, ok := m.TLVs[TagMessagePayload]; ok && len(m.Message) == 0 {
+		m.Message = payload
+	}
+
+	return m, r.Err
+}
+
+// EncodeText uses the default alphabet for plain ASCII and UCS-2 for everything else. Characters outside
+// the basic multilingual plane are sent as surrogate pairs, which most handsets render correctly.
+func EncodeText(text string) (uint8, []byte) {
+	ascii := true
+	for _, r := range text {
+		if r > 0x7F {
+			ascii = false
+			break
+		}
+	}
+
+	if ascii {
+		return codingDefault, []byte(text)
+	}
+
+	units := utf16.Encode([]rune(text))
+	buf := make([]byte, 2*len(units))
+	for i, u := range units {
+		binary.BigEndian.PutUint16(buf[2*i:], u)
+	}
+
+	return codingUCS2, buf
+}
+
+func DecodeText(coding uint8, buf []byte) string {
+	switch coding {
+	case codingUCS2:
+		units := make([]uint16, len(buf)/2)
+		for i := range units {
+			units[i] = binary.BigEndian.Uint16(buf[2*i:])
+		}
+
+		return string(utf16.Decode(units))
+	case codingLatin1:
+		var sb strings.Builder
+		for _, b := range buf {
+			sb.WriteRune(rune(b))
+		}
+
+		return sb.String()
+	default:
+		return string(buf)
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package smpp_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.wdy.de/nago/pkg/smpp"
+	"go.wdy.de/nago/pkg/smpp/smpptest"
+)
+
+func TestConn(t *testing.T) {
+	srv, err := smpptest.NewServer("nago", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer srv.Close()
+
+	delivered := make(chan smpp.Deliver, 2)
+	conn, err := smpp.Dial(context.Background(), srv.URL(), smpp.Options{OnDeliver: func(d smpp.Deliver) {
+		delivered <- d
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer conn.Close()
+
+	if err := conn.BindTransceiver("nago", "secret", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	long := strings.Repeat("Grüße ", 50)
+	for _, text := range []string{"hello", long} {
+		if _, err := conn.Submit(smpp.ShortMessage{Source: "Nago", Destination: "+49179555111", Text: text, RegisteredDelivery: true}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	submitted := srv.Submitted()
+	if len(submitted) != 2 {
+		t.Fatalf("expected 2 messages but got %d", len(submitted))
+	}
+
+	want := smpp.ShortMessage{Source: "Nago", Destination: "49179555111", Text: "hello", RegisteredDelivery: true}
+	if submitted[0].ShortMessage != want {
+		t.Fatalf("unexpected message: %+v", submitted[0])
+	}
+
+	if submitted[1].ShortMessage.Text != long {
+		t.Fatalf("unexpected long message: %q", submitted[1].ShortMessage.Text)
+	}
+
+	if err := srv.DeliverReceipt(submitted[0].MessageID, "DELIVRD"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := srv.Deliver("49179555222", "4930123", "Ja ✓"); err != nil {
+		t.Fatal(err)
+	}
+
+	receipt := receive(t, delivered)
+	if receipt.Receipt == nil || receipt.Receipt.MessageID != submitted[0].MessageID || !receipt.Receipt.Delivered() {
+		t.Fatalf("unexpected receipt: %+v", receipt)
+	}
+
+	mo := receive(t, delivered)
+	if mo.Receipt != nil || mo.Source != "49179555222" || mo.Destination != "4930123" || mo.Text != "Ja ✓" {
+		t.Fatalf("unexpected message: %+v", mo)
+	}
+
+	if err := conn.Unbind(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBindInvalidCredentials(t *testing.T) {
+	srv, err := smpptest.NewServer("nago", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer srv.Close()
+
+	conn, err := smpp.Dial(context.Background(), srv.URL(), smpp.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer conn.Close()
+
+	err = conn.BindTransceiver("nago", "wrong", "")
+	var smppErr *smpp.Error
+	if !errors.As(err, &smppErr) || smppErr.Status != 0x0000000E {
+		t.Fatalf("expected invalid password but got %v", err)
+	}
+}
+
+func TestParseReceipt(t *testing.T) {
+	r, err := smpp.ParseReceipt("id:0123456789 sub:001 dlvrd:000 submit date:2601011200 done date:2601011300 stat:UNDELIV err:001 Text:hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.MessageID != "0123456789" || r.Stat != "UNDELIV" || r.Err != "001" || r.Delivered() || !r.Final() {
+		t.Fatalf("unexpected receipt: %+v", r)
+	}
+
+	if _, err := smpp.ParseReceipt("hello world"); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func receive(t *testing.T, ch chan smpp.Deliver) smpp.Deliver {
+	t.Helper()
+
+	select {
+	case d := <-ch:
+		return d
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+		return smpp.Deliver{}
+	}
+}
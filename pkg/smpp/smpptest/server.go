@@ -0,0 +1,247 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+// Package smpptest provides an in-memory SMSC to test SMPP clients against.
+package smpptest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+
+	"go.wdy.de/nago/pkg/smpp"
+	"go.wdy.de/nago/pkg/smpp/internal/pdu"
+)
+
+// Submitted is a message, which has been submitted to a [Server].
+type Submitted struct {
+	MessageID    string
+	ShortMessage smpp.ShortMessage
+}
+
+// Server is an in-memory SMSC, which understands just the requests used by [go.wdy.de/nago/pkg/smpp.Conn].
+type Server struct {
+	listener  net.Listener
+	systemID  string
+	password  string
+	mutex     sync.Mutex
+	submitted []Submitted
+	sessions  map[net.Conn]*session
+	sequence  uint32
+	wg        sync.WaitGroup
+}
+
+// NewServer starts a server on a random local port, which accepts the given credentials. Use [Server.URL] to
+// connect.
+func NewServer(systemID, password string) (*Server, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{listener: l, systemID: systemID, password: password, sessions: map[net.Conn]*session{}}
+	s.wg.Add(1)
+	go s.serve()
+
+	return s, nil
+}
+
+// URL returns the smpp:// url of the server.
+func (s *Server) URL() string {
+	return "smpp://" + s.listener.Addr().String()
+}
+
+// Submitted returns a copy of all submitted messages.
+func (s *Server) Submitted() []Submitted {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]Submitted(nil), s.submitted...)
+}
+
+// Deliver sends a mobile originated message to all bound sessions.
+func (s *Server) Deliver(source, destination, text string) error {
+	coding, buf := pdu.EncodeText(text)
+	return s.broadcast(pdu.ShortMessage{
+		SourceTON:   1,
+		SourceNPI:   1,
+		Source:      source,
+		DestTON:     1,
+		DestNPI:     1,
+		Destination: destination,
+		DataCoding:  coding,
+		Message:     buf,
+	})
+}
+
+// DeliverReceipt sends a delivery receipt in the de-facto standard text format to all bound sessions.
+func (s *Server) DeliverReceipt(messageID, stat string) error {
+	now := time.Now().Format("0601021504")
+	text := fmt.Sprintf("id:%s sub:001 dlvrd:001 submit date:%s done date:%s stat:%s Err:000 text:", messageID, now, now, stat)
+	return s.broadcast(pdu.ShortMessage{EsmClass: pdu.EsmClassReceipt, Message: []byte(text)})
+}
+
+func (s *Server) broadcast(msg pdu.ShortMessage) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var delivered bool
+	for _, sess := range s.sessions {
+		if !sess.bound {
+			continue
+		}
+
+		s.sequence++
+		if err := sess.write(pdu.PDU{Command: pdu.CmdDeliverSM, Sequence: s.sequence, Body: msg.Encode()}); err != nil {
+			return err
+		}
+
+		delivered = true
+	}
+
+	if !delivered {
+		return fmt.Errorf("no bound session")
+	}
+
+	return nil
+}
+
+// Close stops listening and closes all sessions.
+func (s *Server) Close() error {
+	err := s.listener.Close()
+
+	s.mutex.Lock()
+	for conn := range s.sessions {
+		_ = conn.Close()
+	}
+	s.mutex.Unlock()
+
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				slog.Error("smpp test server cannot accept", "err", err.Error())
+			}
+
+			return
+		}
+
+		s.mutex.Lock()
+		sess := &session{conn: conn}
+		s.sessions[conn] = sess
+		s.mutex.Unlock()
+
+		s.wg.Add(1)
+		go s.handle(sess)
+	}
+}
+
+// session is a single client connection of a [Server].
+type session struct {
+	conn  net.Conn
+	mutex sync.Mutex
+	bound bool // guarded by the server mutex
+}
+
+func (s *session) write(p pdu.PDU) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err := s.conn.Write(p.Bytes())
+	return err
+}
+
+func (s *Server) handle(sess *session) {
+	defer s.wg.Done()
+	defer sess.conn.Close()
+
+	defer func() {
+		s.mutex.Lock()
+		delete(s.sessions, sess.conn)
+		s.mutex.Unlock()
+	}()
+
+	respond := func(p pdu.PDU) {
+		_ = sess.write(p)
+	}
+
+	for {
+		p, err := pdu.Read(sess.conn)
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				slog.Error("smpp test server cannot read", "err", err.Error())
+			}
+
+			return
+		}
+
+		switch p.Command {
+		case pdu.CmdBindTransceiver:
+			r := &pdu.Reader{Buf: p.Body}
+			systemID := r.CString()
+			password := r.CString()
+			if r.Err != nil || systemID != s.systemID || password != s.password {
+				respond(pdu.PDU{Command: pdu.CmdBindTransceiverResp, Status: 0x0000000E, Sequence: p.Sequence}) // invalid password
+				continue
+			}
+
+			s.mutex.Lock()
+			sess.bound = true
+			s.mutex.Unlock()
+
+			var w pdu.Writer
+			w.CString("nago")
+			respond(pdu.PDU{Command: pdu.CmdBindTransceiverResp, Sequence: p.Sequence, Body: w.Bytes()})
+		case pdu.CmdSubmitSM:
+			s.mutex.Lock()
+			bound := sess.bound
+			s.mutex.Unlock()
+
+			msg, err := pdu.DecodeShortMessage(p.Body)
+			if !bound || err != nil {
+				respond(pdu.PDU{Command: pdu.CmdSubmitSMResp, Status: 0x00000004, Sequence: p.Sequence}) // incorrect bind status
+				continue
+			}
+
+			s.mutex.Lock()
+			id := fmt.Sprintf("%08x", len(s.submitted)+1)
+			s.submitted = append(s.submitted, Submitted{
+				MessageID: id,
+				ShortMessage: smpp.ShortMessage{
+					Source:             msg.Source,
+					Destination:        msg.Destination,
+					Text:               pdu.DecodeText(msg.DataCoding, msg.Message),
+					RegisteredDelivery: msg.RegisteredDelivery&0x03 != 0,
+				},
+			})
+			s.mutex.Unlock()
+
+			var w pdu.Writer
+			w.CString(id)
+			respond(pdu.PDU{Command: pdu.CmdSubmitSMResp, Sequence: p.Sequence, Body: w.Bytes()})
+		case pdu.CmdEnquireLink:
+			respond(pdu.PDU{Command: pdu.CmdEnquireLinkResp, Sequence: p.Sequence})
+		case pdu.CmdUnbind:
+			respond(pdu.PDU{Command: pdu.CmdUnbindResp, Sequence: p.Sequence})
+			return
+		case pdu.CmdDeliverSMResp, pdu.CmdEnquireLinkResp:
+			// nothing to do
+		default:
+			respond(pdu.PDU{Command: pdu.CmdGenericNack, Status: 0x00000003, Sequence: p.Sequence})
+		}
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package webauthn
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// maxCBORDepth limits the nesting of the untrusted input.
+const maxCBORDepth = 16
+
+var errCBORTruncated = errors.New("webauthn: truncated cbor data")
+
+// decodeCBOR decodes the first data item of the given buffer and returns the remaining bytes. It supports the subset
+// of RFC 8949, which is required for attestation objects and COSE keys: integers, byte and text strings, arrays,
+// maps, tags (which are ignored) and simple values. Integer map keys are returned as int64, maps as map[any]any.
+func decodeCBOR(buf []byte) (any, []byte, error) {
+	return decodeCBORItem(buf, 0)
+}
+
+func decodeCBORItem(buf []byte, depth int) (any, []byte, error) {
+	if depth > maxCBORDepth {
+		return nil, nil, errors.New("webauthn: cbor nesting too deep")
+	}
+
+	if len(buf) == 0 {
+		return nil, nil, errCBORTruncated
+	}
+
+	major := buf[0] >> 5
+	info := buf[0] & 0x1f
+	buf = buf[1:]
+
+	var arg uint64
+	switch {
+	case info < 24:
+		arg = uint64(info)
+	case info == 24:
+		if len(buf) < 1 {
+			return nil, nil, errCBORTruncated
+		}
+		arg = uint64(buf[0])
+		buf = buf[1:]
+	case info == 25:
+		if len(buf) < 2 {
+			return nil, nil, errCBORTruncated
+		}
+		arg = uint64(binary.BigEndian.Uint16(buf))
+		buf = buf[2:]
+	case info == 26:
+		if len(buf) < 4 {
+			return nil, nil, errCBORTruncated
+		}
+		arg = uint64(binary.BigEndian.Uint32(buf))
+		buf = buf[4:]
+	case info == 27:
+		if len(buf) < 8 {
+			return nil, nil, errCBORTruncated
+		}
+		arg = binary.BigEndian.Uint64(buf)
+		buf = buf[8:]
+	default:
+		return nil, nil, fmt.Errorf("webauthn: unsupported cbor additional info %d", info)
+	}
+
+	switch major {
+	case 0:
+		if arg > 1<<63-1 {
+			return nil, nil, errors.New("webauthn: cbor integer overflow")
+		}
+		return int64(arg), buf, nil
+	case 1:
+		if arg > 1<<63-1 {
+			return nil, nil, errors.New("webauthn: cbor integer overflow")
+		}
+		return -1 - int64(arg), buf, nil
+	case 2, 3:
+		if arg > uint64(len(buf)) {
+			return nil, nil, errCBORTruncated
+		}
+		v := buf[:arg]
+		if major == 3 {
+			return string(v), buf[arg:], nil
+		}
+		return append([]byte(nil), v...), buf[arg:], nil
+	case 4:
+		// each item requires at least a single byte, which protects against huge allocations
+		if arg > uint64(len(buf)) {
+			return nil, nil, errCBORTruncated
+		}
+		arr := make([]any, 0, arg)
+		for range arg {
+			var v any
+			var err error
+			v, buf, err = decodeCBORItem(buf, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			arr = append(arr, v)
+		}
+		return arr, buf, nil
+	case 5:
+		if arg > uint64(len(buf)) {
+			return nil, nil, errCBORTruncated
+		}
+		m := make(map[any]any, arg)
+		for range arg {
+			var k, v any
+			var err error
+			k, buf, err = decodeCBORItem(buf, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			switch k.(type) {
+			case int64, string:
+			default:
+				return nil, nil, fmt.Errorf("webauthn: unsupported cbor map key type %T", k)
+			}
+
+			v, buf, err = decodeCBORItem(buf, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			m[k] = v
+		}
+		return m, buf, nil
+	case 6:
+		return decodeCBORItem(buf, depth+1)
+	default:
+		switch arg {
+		case 20:
+			return false, buf, nil
+		case 21:
+			return true, buf, nil
+		case 22, 23:
+			return nil, buf, nil
+		default:
+			return nil, nil, fmt.Errorf("webauthn: unsupported cbor simple value %d", arg)
+		}
+	}
+}
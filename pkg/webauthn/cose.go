@@ -0,0 +1,133 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// COSE algorithm identifiers as registered at IANA, which are supported by this package.
+const (
+	AlgES256 = -7
+	AlgEdDSA = -8
+	AlgRS256 = -257
+)
+
+// SupportedAlgorithms is announced to the authenticator during registration in order of preference.
+var SupportedAlgorithms = []int{AlgES256, AlgEdDSA, AlgRS256}
+
+// COSE key parameters, see RFC 9053.
+const (
+	coseKty   = 1
+	coseAlg   = 3
+	coseCrv   = -1
+	coseX     = -2
+	coseY     = -3
+	coseRSA_N = -1
+	coseRSA_E = -2
+
+	ktyOKP = 1
+	ktyEC2 = 2
+	ktyRSA = 3
+
+	crvP256    = 1
+	crvEd25519 = 6
+)
+
+// parsePublicKey decodes a COSE_Key and returns the algorithm and the according go public key.
+func parsePublicKey(coseKey []byte) (int, any, error) {
+	v, rest, err := decodeCBOR(coseKey)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if len(rest) != 0 {
+		return 0, nil, errors.New("webauthn: trailing bytes after cose key")
+	}
+
+	m, ok := v.(map[any]any)
+	if !ok {
+		return 0, nil, errors.New("webauthn: cose key is not a map")
+	}
+
+	kty, _ := m[int64(coseKty)].(int64)
+	alg, _ := m[int64(coseAlg)].(int64)
+
+	switch {
+	case kty == ktyEC2 && alg == AlgES256:
+		crv, _ := m[int64(coseCrv)].(int64)
+		x, _ := m[int64(coseX)].([]byte)
+		y, _ := m[int64(coseY)].([]byte)
+		if crv != crvP256 || len(x) != 32 || len(y) != 32 {
+			return 0, nil, errors.New("webauthn: invalid ES256 cose key")
+		}
+
+		// uncompressed point encoding, which also validates that the point is on the curve
+		point := append(append([]byte{4}, x...), y...)
+		pub, err := ecdsa.ParseUncompressedPublicKey(elliptic.P256(), point)
+		if err != nil {
+			return 0, nil, fmt.Errorf("webauthn: invalid ES256 point: %w", err)
+		}
+
+		return AlgES256, pub, nil
+	case kty == ktyOKP && alg == AlgEdDSA:
+		crv, _ := m[int64(coseCrv)].(int64)
+		x, _ := m[int64(coseX)].([]byte)
+		if crv != crvEd25519 || len(x) != ed25519.PublicKeySize {
+			return 0, nil, errors.New("webauthn: invalid EdDSA cose key")
+		}
+
+		return AlgEdDSA, ed25519.PublicKey(x), nil
+	case kty == ktyRSA && alg == AlgRS256:
+		n, _ := m[int64(coseRSA_N)].([]byte)
+		e, _ := m[int64(coseRSA_E)].([]byte)
+		if len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return 0, nil, errors.New("webauthn: invalid RS256 cose key")
+		}
+
+		exp := new(big.Int).SetBytes(e)
+		return AlgRS256, &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}, nil
+	default:
+		return 0, nil, fmt.Errorf("webauthn: unsupported cose key type %d with algorithm %d", kty, alg)
+	}
+}
+
+// verifySignature checks the signature of the given data using the COSE encoded public key.
+func verifySignature(coseKey []byte, data, sig []byte) error {
+	alg, pub, err := parsePublicKey(coseKey)
+	if err != nil {
+		return err
+	}
+
+	switch alg {
+	case AlgES256:
+		sum := sha256.Sum256(data)
+		if !ecdsa.VerifyASN1(pub.(*ecdsa.PublicKey), sum[:], sig) {
+			return ErrInvalidSignature
+		}
+	case AlgEdDSA:
+		if !ed25519.Verify(pub.(ed25519.PublicKey), data, sig) {
+			return ErrInvalidSignature
+		}
+	case AlgRS256:
+		sum := sha256.Sum256(data)
+		if err := rsa.VerifyPKCS1v15(pub.(*rsa.PublicKey), crypto.SHA256, sum[:], sig); err != nil {
+			return ErrInvalidSignature
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,384 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+// Package webauthn implements the relying party side of the W3C Web Authentication API, which is required to
+// register and use passkeys and security keys. It intentionally supports only what a login needs: the
+// credential options for the browser, the registration of a credential and the verification of an assertion.
+//
+// Attestation statements are not verified, which is equivalent to the "none" attestation conveyance that
+// browsers use by default. Thus, the authenticator model is not trusted, but the key pair is bound to the
+// relying party and proven by each assertion.
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrInvalidSignature = errors.New("webauthn: invalid signature")
+	ErrChallenge        = errors.New("webauthn: challenge mismatch")
+	ErrOrigin           = errors.New("webauthn: origin not allowed")
+	ErrRelyingParty     = errors.New("webauthn: relying party id mismatch")
+	ErrUserPresence     = errors.New("webauthn: user not present")
+	// ErrSignCount indicates a cloned authenticator, because the signature counter did not increase.
+	ErrSignCount = errors.New("webauthn: signature counter did not increase")
+)
+
+// authenticator data flags
+const (
+	flagUserPresent  = 0x01
+	flagUserVerified = 0x04
+	flagAttested     = 0x40
+)
+
+// Bytes is a byte slice which is marshalled as unpadded base64url, just like the browser encodes binary data.
+type Bytes []byte
+
+func (b Bytes) String() string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func (b Bytes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(b.String())
+}
+
+func (b *Bytes) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	// be lenient and accept also padded and standard encodings
+	s = strings.TrimRight(s, "=")
+	s = strings.NewReplacer("+", "-", "/", "_").Replace(s)
+	buf, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return fmt.Errorf("webauthn: invalid base64url: %w", err)
+	}
+
+	*b = buf
+	return nil
+}
+
+// RelyingParty identifies the server. The ID is the effective domain, e.g. example.com, and must be a
+// registrable suffix of the origins host. Origins contains all allowed origins like https://www.example.com.
+type RelyingParty struct {
+	ID      string
+	Name    string
+	Origins []string
+}
+
+// Credential is a registered public key credential, which must be stored by the relying party.
+type Credential struct {
+	ID        Bytes  `json:"id"`
+	PublicKey Bytes  `json:"publicKey"` // COSE encoded
+	SignCount uint32 `json:"signCount,omitempty"`
+	AAGUID    Bytes  `json:"aaguid,omitempty"`
+	// UserVerified is true, if the authenticator verified the user, e.g. by PIN or biometrics.
+	UserVerified bool `json:"userVerified,omitempty"`
+}
+
+// NewChallenge returns 32 random bytes.
+func NewChallenge() (Bytes, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("webauthn: cannot read random bytes: %w", err)
+	}
+
+	return buf, nil
+}
+
+type RelyingPartyEntity struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type UserEntity struct {
+	ID          Bytes  `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+type CredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int    `json:"alg"`
+}
+
+type CredentialDescriptor struct {
+	Type string `json:"type"`
+	ID   Bytes  `json:"id"`
+}
+
+type AuthenticatorSelection struct {
+	ResidentKey      string `json:"residentKey,omitempty"`
+	UserVerification string `json:"userVerification,omitempty"`
+}
+
+// CreationOptions is the JSON form of PublicKeyCredentialCreationOptions, which the browser script passes into
+// navigator.credentials.create after decoding the base64url fields.
+type CreationOptions struct {
+	Challenge              Bytes                  `json:"challenge"`
+	RP                     RelyingPartyEntity     `json:"rp"`
+	User                   UserEntity             `json:"user"`
+	PubKeyCredParams       []CredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int                    `json:"timeout,omitempty"`
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials,omitempty"`
+	AuthenticatorSelection AuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                 `json:"attestation"`
+}
+
+// RequestOptions is the JSON form of PublicKeyCredentialRequestOptions for navigator.credentials.get.
+type RequestOptions struct {
+	Challenge        Bytes                  `json:"challenge"`
+	RPID             string                 `json:"rpId"`
+	Timeout          int                    `json:"timeout,omitempty"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials,omitempty"`
+	UserVerification string                 `json:"userVerification,omitempty"`
+}
+
+func descriptors(ids []Bytes) []CredentialDescriptor {
+	res := make([]CredentialDescriptor, 0, len(ids))
+	for _, id := range ids {
+		res = append(res, CredentialDescriptor{Type: "public-key", ID: id})
+	}
+
+	return res
+}
+
+// NewCreationOptions prepares the registration of a new credential. The user handle must be stable and
+// must not contain personal information. Already registered credentials are excluded, so that the same
+// authenticator is not registered twice.
+func NewCreationOptions(rp RelyingParty, challenge Bytes, userHandle []byte, name, displayName string, exclude []Bytes) CreationOptions {
+	params := make([]CredentialParameter, 0, len(SupportedAlgorithms))
+	for _, alg := range SupportedAlgorithms {
+		params = append(params, CredentialParameter{Type: "public-key", Alg: alg})
+	}
+
+	return CreationOptions{
+		Challenge:          challenge,
+		RP:                 RelyingPartyEntity{ID: rp.ID, Name: rp.Name},
+		User:               UserEntity{ID: userHandle, Name: name, DisplayName: displayName},
+		PubKeyCredParams:   params,
+		Timeout:            120_000,
+		ExcludeCredentials: descriptors(exclude),
+		AuthenticatorSelection: AuthenticatorSelection{
+			ResidentKey:      "preferred",
+			UserVerification: "preferred",
+		},
+		Attestation: "none",
+	}
+}
+
+// NewRequestOptions prepares an assertion for the given credentials.
+func NewRequestOptions(rp RelyingParty, challenge Bytes, allow []Bytes) RequestOptions {
+	return RequestOptions{
+		Challenge:        challenge,
+		RPID:             rp.ID,
+		Timeout:          120_000,
+		AllowCredentials: descriptors(allow),
+		UserVerification: "preferred",
+	}
+}
+
+// RegistrationResponse contains the fields of an AuthenticatorAttestationResponse.
+type RegistrationResponse struct {
+	ID                Bytes `json:"id"`
+	ClientDataJSON    Bytes `json:"clientDataJSON"`
+	AttestationObject Bytes `json:"attestationObject"`
+}
+
+// AssertionResponse contains the fields of an AuthenticatorAssertionResponse.
+type AssertionResponse struct {
+	ID                Bytes `json:"id"`
+	ClientDataJSON    Bytes `json:"clientDataJSON"`
+	AuthenticatorData Bytes `json:"authenticatorData"`
+	Signature         Bytes `json:"signature"`
+	UserHandle        Bytes `json:"userHandle,omitempty"`
+}
+
+type clientData struct {
+	Type      string `json:"type"`
+	Challenge Bytes  `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+func verifyClientData(rp RelyingParty, raw []byte, typ string, challenge []byte) error {
+	var cd clientData
+	if err := json.Unmarshal(raw, &cd); err != nil {
+		return fmt.Errorf("webauthn: invalid client data: %w", err)
+	}
+
+	if cd.Type != typ {
+		return fmt.Errorf("webauthn: unexpected client data type '%s'", cd.Type)
+	}
+
+	if len(challenge) == 0 || subtle.ConstantTimeCompare(cd.Challenge, challenge) != 1 {
+		return ErrChallenge
+	}
+
+	if !slices.Contains(rp.Origins, cd.Origin) {
+		return fmt.Errorf("%w: %s", ErrOrigin, cd.Origin)
+	}
+
+	return nil
+}
+
+type authenticatorData struct {
+	rpIDHash  []byte
+	flags     byte
+	signCount uint32
+	aaguid    []byte
+	credID    []byte
+	coseKey   []byte
+}
+
+func parseAuthenticatorData(buf []byte) (authenticatorData, error) {
+	var ad authenticatorData
+	if len(buf) < 37 {
+		return ad, errors.New("webauthn: authenticator data too short")
+	}
+
+	ad.rpIDHash = buf[:32]
+	ad.flags = buf[32]
+	ad.signCount = binary.BigEndian.Uint32(buf[33:37])
+	buf = buf[37:]
+
+	if ad.flags&flagAttested == 0 {
+		return ad, nil
+	}
+
+	if len(buf) < 18 {
+		return ad, errors.New("webauthn: attested credential data too short")
+	}
+
+	ad.aaguid = buf[:16]
+	idLen := int(binary.BigEndian.Uint16(buf[16:18]))
+	buf = buf[18:]
+	if idLen > 1023 || len(buf) < idLen {
+		return ad, errors.New("webauthn: invalid credential id length")
+	}
+
+	ad.credID = buf[:idLen]
+	buf = buf[idLen:]
+
+	// the key is followed by optional extensions, thus we must find its end by decoding it
+	_, rest, err := decodeCBOR(buf)
+	if err != nil {
+		return ad, fmt.Errorf("webauthn: invalid credential public key: %w", err)
+	}
+
+	ad.coseKey = buf[:len(buf)-len(rest)]
+	return ad, nil
+}
+
+func (ad authenticatorData) verify(rp RelyingParty) error {
+	sum := sha256.Sum256([]byte(rp.ID))
+	if !bytes.Equal(ad.rpIDHash, sum[:]) {
+		return ErrRelyingParty
+	}
+
+	if ad.flags&flagUserPresent == 0 {
+		return ErrUserPresence
+	}
+
+	return nil
+}
+
+// VerifyRegistration validates the response of navigator.credentials.create against the issued challenge and
+// returns the new credential.
+func VerifyRegistration(rp RelyingParty, challenge []byte, resp RegistrationResponse) (Credential, error) {
+	if err := verifyClientData(rp, resp.ClientDataJSON, "webauthn.create", challenge); err != nil {
+		return Credential{}, err
+	}
+
+	v, _, err := decodeCBOR(resp.AttestationObject)
+	if err != nil {
+		return Credential{}, fmt.Errorf("webauthn: invalid attestation object: %w", err)
+	}
+
+	att, ok := v.(map[any]any)
+	if !ok {
+		return Credential{}, errors.New("webauthn: attestation object is not a map")
+	}
+
+	rawAuthData, ok := att["authData"].([]byte)
+	if !ok {
+		return Credential{}, errors.New("webauthn: attestation object has no authData")
+	}
+
+	ad, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return Credential{}, err
+	}
+
+	if err := ad.verify(rp); err != nil {
+		return Credential{}, err
+	}
+
+	if ad.flags&flagAttested == 0 || len(ad.credID) == 0 {
+		return Credential{}, errors.New("webauthn: no attested credential data")
+	}
+
+	if len(resp.ID) > 0 && !bytes.Equal(resp.ID, ad.credID) {
+		return Credential{}, errors.New("webauthn: credential id mismatch")
+	}
+
+	if _, _, err := parsePublicKey(ad.coseKey); err != nil {
+		return Credential{}, err
+	}
+
+	return Credential{
+		ID:           bytes.Clone(ad.credID),
+		PublicKey:    bytes.Clone(ad.coseKey),
+		SignCount:    ad.signCount,
+		AAGUID:       bytes.Clone(ad.aaguid),
+		UserVerified: ad.flags&flagUserVerified != 0,
+	}, nil
+}
+
+// VerifyAssertion validates the response of navigator.credentials.get for the given credential and returns the
+// new signature counter, which must be stored. Authenticators which do not implement a counter always report 0.
+func VerifyAssertion(rp RelyingParty, challenge []byte, cred Credential, resp AssertionResponse) (uint32, error) {
+	if len(resp.ID) > 0 && !bytes.Equal(resp.ID, cred.ID) {
+		return 0, errors.New("webauthn: credential id mismatch")
+	}
+
+	if err := verifyClientData(rp, resp.ClientDataJSON, "webauthn.get", challenge); err != nil {
+		return 0, err
+	}
+
+	ad, err := parseAuthenticatorData(resp.AuthenticatorData)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := ad.verify(rp); err != nil {
+		return 0, err
+	}
+
+	clientDataHash := sha256.Sum256(resp.ClientDataJSON)
+	signed := append(bytes.Clone(resp.AuthenticatorData), clientDataHash[:]...)
+	if err := verifySignature(cred.PublicKey, signed, resp.Signature); err != nil {
+		return 0, err
+	}
+
+	if (ad.signCount != 0 || cred.SignCount != 0) && ad.signCount <= cred.SignCount {
+		return 0, ErrSignCount
+	}
+
+	return ad.signCount, nil
+}
@@ -0,0 +1,237 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package webauthn
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// cborHead encodes the major type and argument in the shortest form.
+func cborHead(major byte, arg uint64) []byte {
+	switch {
+	case arg < 24:
+		return []byte{major<<5 | byte(arg)}
+	case arg <= 0xff:
+		return []byte{major<<5 | 24, byte(arg)}
+	default:
+		return []byte{major<<5 | 25, byte(arg >> 8), byte(arg)}
+	}
+}
+
+func cborInt(v int64) []byte {
+	if v < 0 {
+		return cborHead(1, uint64(-1-v))
+	}
+	return cborHead(0, uint64(v))
+}
+
+func cborBytes(v []byte) []byte {
+	return append(cborHead(2, uint64(len(v))), v...)
+}
+
+func cborText(v string) []byte {
+	return append(cborHead(3, uint64(len(v))), v...)
+}
+
+// authenticator simulates a platform authenticator with a single credential.
+type authenticator struct {
+	credID  []byte
+	coseKey []byte
+	sign    func(data []byte) []byte
+	counter uint32
+}
+
+func newES256Authenticator(t *testing.T) *authenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	x := key.X.FillBytes(make([]byte, 32))
+	y := key.Y.FillBytes(make([]byte, 32))
+
+	var cose []byte
+	cose = append(cose, cborHead(5, 5)...)
+	cose = append(cose, cborInt(coseKty)...)
+	cose = append(cose, cborInt(ktyEC2)...)
+	cose = append(cose, cborInt(coseAlg)...)
+	cose = append(cose, cborInt(AlgES256)...)
+	cose = append(cose, cborInt(coseCrv)...)
+	cose = append(cose, cborInt(crvP256)...)
+	cose = append(cose, cborInt(coseX)...)
+	cose = append(cose, cborBytes(x)...)
+	cose = append(cose, cborInt(coseY)...)
+	cose = append(cose, cborBytes(y)...)
+
+	return &authenticator{
+		credID:  []byte("credential-1"),
+		coseKey: cose,
+		sign: func(data []byte) []byte {
+			sum := sha256.Sum256(data)
+			sig, err := ecdsa.SignASN1(rand.Reader, key, sum[:])
+			if err != nil {
+				t.Fatal(err)
+			}
+			return sig
+		},
+	}
+}
+
+func newEdDSAAuthenticator(t *testing.T) *authenticator {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var cose []byte
+	cose = append(cose, cborHead(5, 4)...)
+	cose = append(cose, cborInt(coseKty)...)
+	cose = append(cose, cborInt(ktyOKP)...)
+	cose = append(cose, cborInt(coseAlg)...)
+	cose = append(cose, cborInt(AlgEdDSA)...)
+	cose = append(cose, cborInt(coseCrv)...)
+	cose = append(cose, cborInt(crvEd25519)...)
+	cose = append(cose, cborInt(coseX)...)
+	cose = append(cose, cborBytes(pub)...)
+
+	return &authenticator{
+		credID:  []byte("credential-2"),
+		coseKey: cose,
+		sign: func(data []byte) []byte {
+			return ed25519.Sign(priv, data)
+		},
+	}
+}
+
+func (a *authenticator) authData(rpID string, attested bool) []byte {
+	sum := sha256.Sum256([]byte(rpID))
+	buf := append([]byte(nil), sum[:]...)
+	flags := byte(flagUserPresent | flagUserVerified)
+	if attested {
+		flags |= flagAttested
+	}
+	buf = append(buf, flags)
+	buf = binary.BigEndian.AppendUint32(buf, a.counter)
+	if attested {
+		buf = append(buf, make([]byte, 16)...) // aaguid
+		buf = binary.BigEndian.AppendUint16(buf, uint16(len(a.credID)))
+		buf = append(buf, a.credID...)
+		buf = append(buf, a.coseKey...)
+	}
+
+	return buf
+}
+
+func clientDataJSON(t *testing.T, typ string, challenge Bytes, origin string) []byte {
+	buf, err := json.Marshal(map[string]any{"type": typ, "challenge": challenge, "origin": origin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf
+}
+
+func (a *authenticator) create(t *testing.T, rpID, origin string, challenge Bytes) RegistrationResponse {
+	var att []byte
+	att = append(att, cborHead(5, 3)...)
+	att = append(att, cborText("fmt")...)
+	att = append(att, cborText("none")...)
+	att = append(att, cborText("attStmt")...)
+	att = append(att, cborHead(5, 0)...)
+	att = append(att, cborText("authData")...)
+	att = append(att, cborBytes(a.authData(rpID, true))...)
+
+	return RegistrationResponse{
+		ID:                a.credID,
+		ClientDataJSON:    clientDataJSON(t, "webauthn.create", challenge, origin),
+		AttestationObject: att,
+	}
+}
+
+func (a *authenticator) get(t *testing.T, rpID, origin string, challenge Bytes) AssertionResponse {
+	a.counter++
+	authData := a.authData(rpID, false)
+	cd := clientDataJSON(t, "webauthn.get", challenge, origin)
+	hash := sha256.Sum256(cd)
+
+	return AssertionResponse{
+		ID:                a.credID,
+		ClientDataJSON:    cd,
+		AuthenticatorData: authData,
+		Signature:         a.sign(append(authData, hash[:]...)),
+	}
+}
+
+func TestRegistrationAndAssertion(t *testing.T) {
+	rp := RelyingParty{ID: "example.com", Name: "Example", Origins: []string{"https://example.com"}}
+
+	for name, auth := range map[string]*authenticator{"ES256": newES256Authenticator(t), "EdDSA": newEdDSAAuthenticator(t)} {
+		t.Run(name, func(t *testing.T) {
+			challenge, err := NewChallenge()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := VerifyRegistration(rp, challenge, auth.create(t, rp.ID, "https://evil.com", challenge)); !errors.Is(err, ErrOrigin) {
+				t.Fatalf("expected origin error, got %v", err)
+			}
+
+			cred, err := VerifyRegistration(rp, challenge, auth.create(t, rp.ID, rp.Origins[0], challenge))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(cred.ID) != string(auth.credID) || !cred.UserVerified {
+				t.Fatalf("unexpected credential: %+v", cred)
+			}
+
+			challenge, _ = NewChallenge()
+			resp := auth.get(t, rp.ID, rp.Origins[0], challenge)
+			count, err := VerifyAssertion(rp, challenge, cred, resp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cred.SignCount = count
+
+			// replaying the same assertion must fail due to the counter
+			if _, err := VerifyAssertion(rp, challenge, cred, resp); !errors.Is(err, ErrSignCount) {
+				t.Fatalf("expected sign count error, got %v", err)
+			}
+
+			other, _ := NewChallenge()
+			if _, err := VerifyAssertion(rp, other, cred, auth.get(t, rp.ID, rp.Origins[0], challenge)); !errors.Is(err, ErrChallenge) {
+				t.Fatalf("expected challenge error, got %v", err)
+			}
+
+			if _, err := VerifyAssertion(rp, challenge, cred, auth.get(t, "evil.com", rp.Origins[0], challenge)); !errors.Is(err, ErrRelyingParty) {
+				t.Fatalf("expected rp error, got %v", err)
+			}
+
+			resp = auth.get(t, rp.ID, rp.Origins[0], challenge)
+			resp.Signature[len(resp.Signature)-1] ^= 0xff
+			if _, err := VerifyAssertion(rp, challenge, cred, resp); !errors.Is(err, ErrInvalidSignature) {
+				t.Fatalf("expected signature error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDecodeCBOR(t *testing.T) {
+	for _, buf := range [][]byte{{}, {0x5a, 0xff, 0xff, 0xff, 0xff}, {0x9b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, {0xbf}} {
+		if _, _, err := decodeCBOR(buf); err == nil {
+			t.Fatalf("expected error for %x", buf)
+		}
+	}
+}
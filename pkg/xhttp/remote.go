@@ -0,0 +1,132 @@
+// Copyright (c) 2026 worldiety GmbH
+//
+// This file is part of the NAGO Low-Code Platform.
+// Licensed under the terms specified in the LICENSE file.
+//
+// SPDX-License-Identifier: Custom-License
+
+package xhttp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// TrustedProxies contains the addresses of the reverse proxies, whose X-Forwarded-For and X-Real-IP headers are
+// trusted. Otherwise, a client could just claim any address.
+type TrustedProxies []netip.Prefix
+
+// ParseTrustedProxies parses a comma separated list of addresses or prefixes in CIDR notation, like
+// "10.0.0.0/8, ::1".
+func ParseTrustedProxies(s string) (TrustedProxies, error) {
+	var res TrustedProxies
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if !strings.Contains(entry, "/") {
+			addr, err := netip.ParseAddr(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+			}
+
+			addr = addr.Unmap()
+			res = append(res, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+		}
+
+		res = append(res, prefix.Masked())
+	}
+
+	return res, nil
+}
+
+// Contains returns true, if the address belongs to a trusted proxy.
+func (t TrustedProxies) Contains(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range t {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type remoteIPKey struct{}
+
+// WithRemoteIP determines the ip address of the client once and provides it to [RemoteIP]. The headers of a
+// request are only evaluated, if the direct peer is one of the trusted proxies. Of a forwarding chain, the
+// entries are inspected from the end and the first address which is not a trusted proxy is used, because all
+// entries before may have been forged by the client.
+func WithRemoteIP(trusted TrustedProxies, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := remoteIP(trusted, r)
+		h.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), remoteIPKey{}, ip)))
+	})
+}
+
+// RemoteIP returns the ip address of the client, as determined by [WithRemoteIP]. Without that middleware, the
+// address of the direct peer is returned and all headers are ignored. If no address can be determined, the
+// empty string is returned.
+func RemoteIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(remoteIPKey{}).(string); ok {
+		return ip
+	}
+
+	return remoteIP(nil, r)
+}
+
+func remoteIP(trusted TrustedProxies, r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	peer, err := netip.ParseAddr(host)
+	if err != nil {
+		return ""
+	}
+
+	peer = peer.Unmap()
+	if !trusted.Contains(peer) {
+		return peer.String()
+	}
+
+	if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+		entries := strings.Split(strings.Join(forwarded, ","), ",")
+		for i := len(entries) - 1; i >= 0; i-- {
+			addr, err := netip.ParseAddr(strings.TrimSpace(entries[i]))
+			if err != nil {
+				// a malformed entry cannot be skipped safely, thus stick to the last trusted hop
+				return peer.String()
+			}
+
+			addr = addr.Unmap()
+			if !trusted.Contains(addr) {
+				return addr.String()
+			}
+
+			peer = addr
+		}
+
+		return peer.String()
+	}
+
+	if addr, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP"))); err == nil {
+		return addr.Unmap().String()
+	}
+
+	return peer.String()
+}
@@ -43,6 +43,10 @@ func (a ApplicationID) Last() string {
 
 type OnWindowCreatedObserver func(wnd Window)
 
+// OnWindowInteractionObserver is notified when the user interacts with a window, e.g. by clicking a button or by
+// typing into a field. To keep the event loop fast, a window notifies at most once per minute.
+type OnWindowInteractionObserver func(wnd Window)
+
 type Application struct {
 	id                       ApplicationID
 	name                     string
@@ -58,6 +62,7 @@ type Application struct {
 	onSendFiles              func(scope *Scope, options ExportFilesOptions) error
 	onShareStream            func(*Scope, func() (io.Reader, error)) (URI, error)
 	onWindowCreatedObservers []OnWindowCreatedObserver
+	onInteractionObservers   []OnWindowInteractionObserver
 	destructors              *concurrent.LinkedList[func()]
 	colorSets                map[ColorScheme]map[NamespaceName]ColorSet
 
@@ -107,6 +112,12 @@ func NewApplication(
 		instance:      data.RandIdent[string](),
 	}
 
+	if bus != nil {
+		a.AddDestructor(events.SubscribeFor[session.LoggedOut](bus, func(evt session.LoggedOut) {
+			a.scopes.logout(evt.Session)
+		}))
+	}
+
 	return a
 }
 
@@ -194,6 +205,12 @@ func (a *Application) SetOnShareStream(onShareStream func(*Scope, func() (io.Rea
 	a.onShareStream = onShareStream
 }
 
+// SetOnWindowInteractionObservers sets the observers which are notified about user interactions. It must be called
+// before the first window is created.
+func (a *Application) SetOnWindowInteractionObservers(observers []OnWindowInteractionObserver) {
+	a.onInteractionObservers = observers
+}
+
 func (a *Application) Scope(id proto.ScopeID) (*Scope, bool) {
 	return a.scopes.Get(id)
 }
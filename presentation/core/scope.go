@@ -64,6 +64,7 @@ type Scope struct {
 	tempDir            string
 	nextFileSeqNo      int64
 	windowInfo         WindowInfo
+	remoteAddr         concurrent.Value[string]
 	onDestroyObservers concurrent.Slice[func()]
 	location           *time.Location
 	subject            concurrent.Value[auth.Subject]
@@ -76,8 +77,12 @@ type Scope struct {
 	virtualSession         atomic.Pointer[session.UserSession]
 	ignoreNextInvalidation atomic.Bool
 	dirty                  bool
+	lastInteraction        time.Time // only for event loop, see interacted
 }
 
+// interactionNotifyInterval throttles the [OnWindowInteractionObserver] notifications of a scope.
+const interactionNotifyInterval = time.Minute
+
 func NewScope(ctx context.Context, app *Application, tempRootDir string, id proto.ScopeID, lifetime time.Duration, factories map[proto.RootViewID]ComponentFactory, sessionByID session.FindUserSessionByID) *Scope {
 
 	defaultLang := language.English
@@ -203,6 +208,12 @@ func (s *Scope) getTempDir() (string, error) {
 	return path, nil
 }
 
+// SetRemoteAddr updates the ip address of the client, which is connected through the current channel.
+// See also [WindowInfo.RemoteAddr].
+func (s *Scope) SetRemoteAddr(addr string) {
+	s.remoteAddr.SetValue(addr)
+}
+
 func (s *Scope) updateWindowInfo(winfo WindowInfo) {
 	s.windowInfo = winfo
 	if s.allocatedRootView.IsSome() {
@@ -488,6 +499,21 @@ func (s *Scope) handleSessionAssigned(evt *proto.SessionAssigned) {
 	s.virtualSession.Store(&tmp)
 }
 
+// only for event loop
+func (s *Scope) handleLoggedOut(id session.ID) {
+	if s.sessionID != id {
+		return
+	}
+
+	if s.allocatedRootView.IsNone() {
+		s.subject.SetValue(s.app.getAnonUser())
+		return
+	}
+
+	s.allocatedRootView.Unwrap().UpdateSubject(nil)
+	s.forceRender(0)
+}
+
 type subjectLanguageSetter interface {
 	SetLanguage(tag language.Tag)
 	SetBundle(bundle *i18n.Bundle)
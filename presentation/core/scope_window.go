@@ -393,7 +393,9 @@ func (s *scopeWindow) Execute(task func()) {
 }
 
 func (s *scopeWindow) Info() WindowInfo {
-	return s.parent.windowInfo
+	info := s.parent.windowInfo
+	info.RemoteAddr = s.parent.remoteAddr.Value()
+	return info
 }
 
 func (s *scopeWindow) Navigation() Navigation {
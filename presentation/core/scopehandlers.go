@@ -11,6 +11,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"go.wdy.de/nago/pkg/std"
 	"go.wdy.de/nago/presentation/proto"
@@ -21,10 +22,13 @@ func (s *Scope) handleEvent(t proto.NagoEvent) {
 
 	switch evt := t.(type) {
 	case *proto.UpdateStateValueRequested:
+		s.interacted()
 		s.handleSetPropertyValueRequested(evt)
 	case *proto.UpdateStateValues2Requested:
+		s.interacted()
 		s.handleSetPropertyValues2Requested(evt)
 	case *proto.FunctionCallRequested:
+		s.interacted()
 		s.handleFunctionCallRequested(evt)
 	case *proto.RootViewAllocationRequested:
 		s.handleNewComponentRequested(evt)
@@ -49,6 +53,25 @@ func (s *Scope) handleEvent(t proto.NagoEvent) {
 	}
 }
 
+// interacted notifies the interaction observers of the application, but at most once per
+// [interactionNotifyInterval]. Only for event loop.
+func (s *Scope) interacted() {
+	if s.allocatedRootView.IsNone() || len(s.app.onInteractionObservers) == 0 {
+		return
+	}
+
+	now := time.Now()
+	if now.Sub(s.lastInteraction) < interactionNotifyInterval {
+		return
+	}
+
+	s.lastInteraction = now
+	wnd := s.allocatedRootView.Unwrap()
+	for _, observer := range s.app.onInteractionObservers {
+		observer(wnd)
+	}
+}
+
 func (s *Scope) handleWindowInfoChanged(evt *proto.WindowInfoChanged) {
 	winfo := evt.WindowInfo
 	s.updateWindowInfo(WindowInfo{
@@ -308,6 +331,7 @@ func convertColorSetToMap(colorSet ColorSet) proto.NamedColors {
 func (s *Scope) handleConfigurationRequested(evt *proto.ScopeConfigurationChangeRequested) {
 	winfo := evt.WindowInfo
 	s.windowInfo = WindowInfo{
+		UserAgent:         UserAgent(winfo.UserAgent),
 		Width:             DP(winfo.Width),
 		Height:            DP(winfo.Height),
 		Density:           Density(winfo.Density),
@@ -11,6 +11,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"go.wdy.de/nago/application/session"
 	"go.wdy.de/nago/pkg/std/concurrent"
 	"go.wdy.de/nago/presentation/proto"
 )
@@ -88,6 +89,16 @@ func (s *Scopes) updateTick(now time.Time) {
 
 }
 
+// logout drops the subject of all scopes which belong to the given session.
+func (s *Scopes) logout(id session.ID) {
+	s.scopes.Each(func(key proto.ScopeID, scope *Scope) bool {
+		scope.eventLoop.Post(func() {
+			scope.handleLoggedOut(id)
+		})
+		return true
+	})
+}
+
 // Destroy stops the internal timer and frees all contained scopes.
 func (s *Scopes) Destroy() {
 	if !s.destroyed.CompareAndSwap(false, true) {
@@ -76,6 +76,7 @@ type WindowInfo struct {
 	ColorScheme       ColorScheme // The ColorScheme which the frontend currently uses, like auto, light, dark, high contrast, etc. See also PrefersDark() and PrefersLight().
 	SystemColorScheme ColorScheme // If the ColorScheme is set to system/auto, this attribute tells the preferred color scheme. It allows the frontend to provide the systems current light/dark mode setting. See also PrefersDark() and PrefersLight().
 	UserAgent         UserAgent
+	RemoteAddr        string // The ip address of the client or empty if unknown. Behind a reverse proxy, this is the forwarded address.
 }
 
 // PrefersDark returns true if the current ColorScheme is set to a dark-like scheme. This semantic
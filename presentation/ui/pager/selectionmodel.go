@@ -30,12 +30,19 @@ var (
 type ModelOptions struct {
 	StatePrefix string
 	PageSize    int // Defaults to whatever [data.PaginateOptions] defines.
+
+	// StructuredQuery interprets the [Model.Query] with the structured query language of [rquery.Parse],
+	// including field filters, boolean operators and sort terms. Sorting loads every entity of the subset on each
+	// render. By default, the query is a plain [rquery.SimplePredicate] search.
+	StructuredQuery bool
 }
 
 // Model is a simplified aggregate of combining paging, filtering and selection all at once.
 type Model[E data.Aggregate[ID], ID ~string] struct {
 	Window core.Window
-	// Query represents the build-in rquery.SimplePredicate filter input to create a visible subset.
+	// Query represents the build-in rquery.SimplePredicate filter input to create a visible subset. If
+	// [ModelOptions.StructuredQuery] is set, it accepts the structured query language of [rquery.Parse] instead,
+	// including sort terms, and falls back to [rquery.SimplePredicate] for malformed input.
 	Query *core.State[string]
 
 	// PageIdx is the active 0-based page offset. Use this state as the page index state for [Pager].
@@ -88,12 +95,19 @@ func NewModel[E data.Aggregate[ID], ID ~string](wnd core.Window, findByID data.B
 	}
 
 	filterOpts := data.FilterOptions[E, ID]{}
+	var sortKeys []data.SortKey
 	allEntityIdentsInSubset := core.StateOf[*tableHolder](wnd, opts.StatePrefix+"-allEntityIdentsInSubset").Init(func() *tableHolder {
 		return &tableHolder{}
 	})
 	allEntityIdentsInSubset.Get().idents = allEntityIdentsInSubset.Get().idents[:0]
 	if model.Query.Get() != "" {
 		p := rquery.SimplePredicate[E](model.Query.Get())
+		if opts.StructuredQuery {
+			p = rquery.QueryPredicate[E](model.Query.Get())
+			if q, err := rquery.Parse(model.Query.Get()); err == nil {
+				sortKeys = q.Sort
+			}
+		}
 		filterOpts.Accept = func(u E) bool {
 			if p(u) {
 				s := allEntityIdentsInSubset.Get()
@@ -114,6 +128,7 @@ func NewModel[E data.Aggregate[ID], ID ~string](wnd core.Window, findByID data.B
 		data.PaginateOptions{
 			PageIdx:  model.PageIdx.Get(),
 			PageSize: opts.PageSize,
+			Sort:     sortKeys,
 		},
 	)
 